@@ -2,71 +2,126 @@ package main
 
 import (
 	"context"
-	"log"
+	"io/fs"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"sonarscope/backend/internal/alert"
 	"sonarscope/backend/internal/api"
 	"sonarscope/backend/internal/config"
 	"sonarscope/backend/internal/db"
+	"sonarscope/backend/internal/logging"
 	"sonarscope/backend/internal/model"
 	"sonarscope/backend/internal/probe"
 	"sonarscope/backend/internal/store"
 	"sonarscope/backend/internal/telemetry"
+	"sonarscope/backend/migrations"
 )
 
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("load config: %v", err)
+		fatal("load config", err)
 	}
+	// The default logger also backs the standard log package, so libraries
+	// that write through it share the configured format.
+	slog.SetDefault(logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
 	pool, err := db.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
-		log.Fatalf("connect database: %v", err)
+		fatal("connect database", err)
 	}
 	defer pool.Close()
 
-	migrationsDir := os.Getenv("MIGRATIONS_DIR")
-	if migrationsDir == "" {
-		migrationsDir = "migrations"
+	var migrationsFS fs.FS = migrations.FS
+	if migrationsDir := os.Getenv("MIGRATIONS_DIR"); migrationsDir != "" {
+		migrationsFS, err = db.MigrationsDirFS(migrationsDir)
+		if err != nil {
+			fatal("apply migrations", err)
+		}
+		slog.Info("applying migrations", "dir", migrationsDir)
 	}
-	if err := db.ApplyMigrations(ctx, pool, migrationsDir); err != nil {
-		log.Fatalf("apply migrations: %v", err)
+	if err := db.ApplyMigrations(ctx, pool, migrationsFS); err != nil {
+		fatal("apply migrations", err)
 	}
 
 	st := store.New(pool)
+	st.SetStatusStaleAfter(time.Duration(cfg.StatusStaleAfterSec) * time.Second)
+	st.SetOmitSettingsPayloadBytes(cfg.PingRawOmitPayload)
+	st.SetDownGrace(time.Duration(cfg.AlertGraceSec)*time.Second, cfg.AlertGraceMinSamples)
 	defaults := model.Settings{
 		PingIntervalSec: cfg.DefaultInterval,
 		ICMPPayloadSize: cfg.DefaultPayload,
 		ICMPTimeoutMs:   cfg.DefaultTimeoutMs,
 		AutoRefreshSec:  cfg.DefaultRefresh,
+		ProbeMode:       cfg.DefaultProbeMode,
+		TCPPort:         cfg.DefaultTCPPort,
+		PayloadPattern:  cfg.DefaultPayloadPattern,
 	}
 	if err := st.EnsureDefaultSettings(ctx, defaults); err != nil {
-		log.Fatalf("seed settings: %v", err)
+		fatal("seed settings", err)
+	}
+	if err := st.ApplyRetentionPolicies(ctx); err != nil {
+		fatal("apply retention policies", err)
+	}
+	// Delete jobs live in memory, so any job still recorded as paused was left
+	// behind by a process that died mid-delete.
+	if resumed, err := st.ResumePausedMaintenanceJobs(ctx); err != nil {
+		slog.Warn("failed to resume paused maintenance jobs", "error", err)
+	} else if len(resumed) > 0 {
+		slog.Warn("resumed maintenance jobs left paused by an interrupted delete", "job_ids", resumed)
 	}
 
 	settings, err := st.GetSettings(ctx)
 	if err != nil {
-		log.Printf("failed to load settings, using defaults: %v", err)
+		slog.Warn("failed to load settings, using defaults", "error", err)
 		settings = defaults
 	}
 
-	hub := telemetry.NewHub()
+	alerts := alert.NewWebhookNotifier(alert.GracePolicy{
+		Period:     time.Duration(cfg.AlertGraceSec) * time.Second,
+		MinSamples: cfg.AlertGraceMinSamples,
+	}, time.Duration(cfg.AlertCooldownSec)*time.Second)
+	alerts.Configure(settings.AlertWebhookURL)
+	alerts.SetFailingSource(st)
+	st.SetAlertSink(alerts)
+	alertCtx, stopAlerts := context.WithCancel(context.Background())
+	defer stopAlerts()
+	go alerts.Run(alertCtx)
+
+	hub := telemetry.NewHub(cfg.AllowedOrigins)
 	probeEngine := probe.NewEngine(st, hub, probe.Options{
-		ProbeWorkers:        cfg.ProbeWorkers,
-		ResultWorkers:       cfg.ProbeResultWorkers,
-		ResultQueueSize:     cfg.ProbeResultQueueSize,
-		ResultBatchSize:     cfg.ProbeResultBatchSize,
-		ResultFlushInterval: time.Duration(cfg.ProbeResultFlushMs) * time.Millisecond,
+		ProbeWorkers:           cfg.ProbeWorkers,
+		ResultWorkers:          cfg.ProbeResultWorkers,
+		ResultQueueSize:        cfg.ProbeResultQueueSize,
+		ResultBatchSize:        cfg.ProbeResultBatchSize,
+		ResultFlushInterval:    time.Duration(cfg.ProbeResultFlushMs) * time.Millisecond,
+		ResultLogSample:        cfg.ProbeResultLogSample,
+		ResultLogTransition:    cfg.ProbeLogTransitions,
+		LogLinesPerSec:         cfg.ProbeLogLinesPerSec,
+		MultiAddressPolicy:     cfg.ProbeAddressPolicy,
+		AdaptiveTimeout:        cfg.ProbeAdaptiveTimeout,
+		AdaptiveTimeoutStddevK: cfg.ProbeAdaptiveK,
+		AdaptiveTimeoutMinMs:   cfg.ProbeAdaptiveMinMs,
+		SendPacing:             cfg.ProbeSendPacing,
+		SendWindowPct:          cfg.ProbeSendWindowPct,
+		TCPSourcePortMin:       cfg.ProbeTCPSourcePortMin,
+		TCPSourcePortMax:       cfg.ProbeTCPSourcePortMax,
+		ICMPBaseID:             cfg.ProbeICMPBaseID,
 	}, settings)
 	apiServer := api.NewServer(cfg, st, probeEngine, hub)
+	apiServer.SetAlertNotifier(alerts)
+	janitorCtx, stopJanitor := context.WithCancel(context.Background())
+	defer stopJanitor()
+	go apiServer.RunPreviewJanitor(janitorCtx)
+	go apiServer.RunSnapshotJanitor(janitorCtx)
 
 	httpServer := &http.Server{
 		Addr:         cfg.HTTPAddr,
@@ -77,9 +132,9 @@ func main() {
 	}
 
 	go func() {
-		log.Printf("SonarScope API listening on %s", cfg.HTTPAddr)
+		slog.Info("SonarScope API listening", "addr", cfg.HTTPAddr)
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen and serve: %v", err)
+			fatal("listen and serve", err)
 		}
 	}()
 
@@ -93,6 +148,12 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer shutdownCancel()
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		log.Printf("shutdown error: %v", err)
+		slog.Error("shutdown error", "error", err)
 	}
 }
+
+// fatal logs err under msg at error level and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,127 @@
+package alert
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Cooldown suppresses repeated notifications for the same endpoint inside a
+// window. Suppressed alerts are counted and reported once the endpoint has
+// been quiet for a full window.
+type Cooldown struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	endpoints map[int64]*cooldownState
+}
+
+type cooldownState struct {
+	lastNotified    time.Time
+	lastAlert       time.Time
+	lastState       string
+	suppressed      int
+	firstSuppressed time.Time
+}
+
+type Decision struct {
+	Notify bool
+	// Suppressed is the number of alerts held back since the previous
+	// notification for this endpoint.
+	Suppressed int
+}
+
+type FlapSummary struct {
+	EndpointID      int64     `json:"endpoint_id"`
+	Suppressed      int       `json:"suppressed"`
+	FirstSuppressed time.Time `json:"first_suppressed"`
+	LastSuppressed  time.Time `json:"last_suppressed"`
+	FinalState      string    `json:"final_state"`
+}
+
+// NewCooldown returns a cooldown with the given window. A zero window never
+// suppresses.
+func NewCooldown(window time.Duration) *Cooldown {
+	if window < 0 {
+		window = 0
+	}
+	return &Cooldown{
+		window:    window,
+		endpoints: map[int64]*cooldownState{},
+	}
+}
+
+func (c *Cooldown) Observe(endpointID int64, state string, now time.Time) Decision {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current, ok := c.endpoints[endpointID]
+	if !ok {
+		c.endpoints[endpointID] = &cooldownState{lastNotified: now, lastAlert: now, lastState: state}
+		return Decision{Notify: true}
+	}
+
+	current.lastAlert = now
+	current.lastState = state
+	if c.window > 0 && now.Sub(current.lastNotified) < c.window {
+		if current.suppressed == 0 {
+			current.firstSuppressed = now
+		}
+		current.suppressed++
+		return Decision{}
+	}
+
+	decision := Decision{Notify: true, Suppressed: current.suppressed}
+	current.lastNotified = now
+	current.suppressed = 0
+	current.firstSuppressed = time.Time{}
+	return decision
+}
+
+// Record notes an alert the caller is holding back without counting it
+// against the window, such as the recovery of a suppressed down, so the
+// settled summary reports the endpoint's final state. Endpoints without
+// cooldown state are ignored.
+func (c *Cooldown) Record(endpointID int64, state string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current, ok := c.endpoints[endpointID]; ok {
+		current.lastAlert = now
+		current.lastState = state
+	}
+}
+
+// Settled returns a summary for each endpoint that had suppressed alerts and
+// has not alerted for a full window, then clears its suppressed count.
+func (c *Cooldown) Settled(now time.Time) []FlapSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	summaries := []FlapSummary{}
+	for endpointID, current := range c.endpoints {
+		if now.Sub(current.lastAlert) < c.window {
+			continue
+		}
+		if current.suppressed > 0 {
+			summaries = append(summaries, FlapSummary{
+				EndpointID:      endpointID,
+				Suppressed:      current.suppressed,
+				FirstSuppressed: current.firstSuppressed,
+				LastSuppressed:  current.lastAlert,
+				FinalState:      current.lastState,
+			})
+		}
+		delete(c.endpoints, endpointID)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].EndpointID < summaries[j].EndpointID
+	})
+	return summaries
+}
+
+// Forget drops the cooldown state of a deleted endpoint.
+func (c *Cooldown) Forget(endpointID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.endpoints, endpointID)
+}
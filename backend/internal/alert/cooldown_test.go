@@ -0,0 +1,85 @@
+package alert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCooldownSuppressesFlapsAndSummarizesWhenSettled(t *testing.T) {
+	cooldown := NewCooldown(time.Minute)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if decision := cooldown.Observe(7, "down", start); !decision.Notify {
+		t.Fatalf("first alert should notify")
+	}
+	for i, state := range []string{"up", "down", "up"} {
+		if decision := cooldown.Observe(7, state, start.Add(time.Duration(i+1)*10*time.Second)); decision.Notify {
+			t.Fatalf("alert %d inside the window should be suppressed", i)
+		}
+	}
+	if decision := cooldown.Observe(8, "down", start.Add(20*time.Second)); !decision.Notify {
+		t.Fatalf("a different endpoint should not share the cooldown")
+	}
+
+	if summaries := cooldown.Settled(start.Add(70 * time.Second)); len(summaries) != 0 {
+		t.Fatalf("endpoint still flapping should not be settled: %+v", summaries)
+	}
+
+	summaries := cooldown.Settled(start.Add(2 * time.Minute))
+	if len(summaries) != 1 {
+		t.Fatalf("Settled() = %+v, want one summary", summaries)
+	}
+	summary := summaries[0]
+	if summary.EndpointID != 7 || summary.Suppressed != 3 || summary.FinalState != "up" {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if !summary.FirstSuppressed.Equal(start.Add(10*time.Second)) || !summary.LastSuppressed.Equal(start.Add(30*time.Second)) {
+		t.Fatalf("unexpected summary window: %+v", summary)
+	}
+
+	if decision := cooldown.Observe(7, "down", start.Add(3*time.Minute)); !decision.Notify || decision.Suppressed != 0 {
+		t.Fatalf("settled endpoint should notify fresh, got %+v", decision)
+	}
+}
+
+func TestCooldownReportsSuppressedCountOnNextNotification(t *testing.T) {
+	cooldown := NewCooldown(time.Minute)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cooldown.Observe(1, "down", start)
+	cooldown.Observe(1, "up", start.Add(10*time.Second))
+	cooldown.Observe(1, "down", start.Add(20*time.Second))
+
+	decision := cooldown.Observe(1, "up", start.Add(61*time.Second))
+	if !decision.Notify || decision.Suppressed != 2 {
+		t.Fatalf("Observe() after window = %+v, want notify with 2 suppressed", decision)
+	}
+}
+
+func TestCooldownZeroWindowNeverSuppresses(t *testing.T) {
+	cooldown := NewCooldown(0)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if decision := cooldown.Observe(1, "down", now); !decision.Notify {
+			t.Fatalf("zero window suppressed alert %d", i)
+		}
+	}
+}
+
+func TestCooldownRecordUpdatesFinalStateWithoutCounting(t *testing.T) {
+	cooldown := NewCooldown(time.Minute)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cooldown.Observe(1, "down", start)
+	cooldown.Observe(1, "down", start.Add(10*time.Second))
+	cooldown.Record(1, "recovered", start.Add(20*time.Second))
+	cooldown.Record(2, "recovered", start.Add(20*time.Second))
+
+	if summaries := cooldown.Settled(start.Add(70 * time.Second)); len(summaries) != 0 {
+		t.Fatalf("Record should keep the endpoint active: %+v", summaries)
+	}
+	summaries := cooldown.Settled(start.Add(90 * time.Second))
+	if len(summaries) != 1 || summaries[0].Suppressed != 1 || summaries[0].FinalState != "recovered" {
+		t.Fatalf("Settled() = %+v, want one suppressed alert ending recovered", summaries)
+	}
+}
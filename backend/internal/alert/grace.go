@@ -0,0 +1,20 @@
+package alert
+
+import "time"
+
+// GracePolicy keeps newly imported endpoints from alerting until they are old
+// enough and have enough samples. Zero values disable each rule.
+type GracePolicy struct {
+	Period     time.Duration
+	MinSamples int
+}
+
+func (p GracePolicy) Eligible(createdAt time.Time, totalSent int64, now time.Time) bool {
+	if p.Period > 0 && now.Sub(createdAt) < p.Period {
+		return false
+	}
+	if p.MinSamples > 0 && totalSent < int64(p.MinSamples) {
+		return false
+	}
+	return true
+}
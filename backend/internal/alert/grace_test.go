@@ -0,0 +1,30 @@
+package alert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGracePolicyEligible(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy := GracePolicy{Period: 2 * time.Minute, MinSamples: 3}
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		totalSent int64
+		want      bool
+	}{
+		{name: "new endpoint", createdAt: now.Add(-time.Minute), totalSent: 10, want: false},
+		{name: "too few samples", createdAt: now.Add(-time.Hour), totalSent: 2, want: false},
+		{name: "eligible", createdAt: now.Add(-time.Hour), totalSent: 3, want: true},
+	}
+	for _, tc := range tests {
+		if got := policy.Eligible(tc.createdAt, tc.totalSent, now); got != tc.want {
+			t.Fatalf("%s: Eligible() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if !(GracePolicy{}).Eligible(now, 0, now) {
+		t.Fatalf("zero policy should always be eligible")
+	}
+}
@@ -0,0 +1,52 @@
+package alert
+
+import (
+	"sonarscope/backend/internal/model"
+)
+
+const ThresholdSourceGlobal = "global"
+
+// ResolveThresholds applies group overrides on top of the global settings.
+// When an endpoint belongs to several groups with overrides, the strictest
+// (lowest) value wins for each threshold independently. A zero override is
+// treated as unset, so one group cannot switch a check off for the others.
+func ResolveThresholds(settings model.Settings, groups []model.Group) model.EffectiveAlertThresholds {
+	effective := model.EffectiveAlertThresholds{
+		ConsecutiveFailed:       settings.AlertConsecutiveFailed,
+		ConsecutiveFailedSource: ThresholdSourceGlobal,
+		FailedPct:               settings.AlertFailedPct,
+		FailedPctSource:         ThresholdSourceGlobal,
+	}
+
+	consecutiveOverridden := false
+	failedPctOverridden := false
+	for _, group := range groups {
+		if value := group.AlertThresholds.ConsecutiveFailed; value != nil && *value > 0 {
+			if !consecutiveOverridden || *value < effective.ConsecutiveFailed {
+				effective.ConsecutiveFailed = *value
+				effective.ConsecutiveFailedSource = group.Name
+				consecutiveOverridden = true
+			}
+		}
+		if value := group.AlertThresholds.FailedPct; value != nil && *value > 0 {
+			if !failedPctOverridden || *value < effective.FailedPct {
+				effective.FailedPct = *value
+				effective.FailedPctSource = group.Name
+				failedPctOverridden = true
+			}
+		}
+	}
+	return effective
+}
+
+// Breached reports whether an endpoint's current counters cross either
+// threshold. A zero threshold disables that check.
+func Breached(thresholds model.EffectiveAlertThresholds, consecutiveFailed int64, failedPct float64) bool {
+	if thresholds.ConsecutiveFailed > 0 && consecutiveFailed >= int64(thresholds.ConsecutiveFailed) {
+		return true
+	}
+	if thresholds.FailedPct > 0 && failedPct >= thresholds.FailedPct {
+		return true
+	}
+	return false
+}
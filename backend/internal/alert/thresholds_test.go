@@ -0,0 +1,86 @@
+package alert
+
+import (
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func TestResolveThresholdsFallsBackToGlobal(t *testing.T) {
+	settings := model.Settings{AlertConsecutiveFailed: 3, AlertFailedPct: 50}
+
+	got := ResolveThresholds(settings, []model.Group{{ID: 1, Name: "core"}})
+	want := model.EffectiveAlertThresholds{
+		ConsecutiveFailed:       3,
+		ConsecutiveFailedSource: ThresholdSourceGlobal,
+		FailedPct:               50,
+		FailedPctSource:         ThresholdSourceGlobal,
+	}
+	if got != want {
+		t.Fatalf("ResolveThresholds() = %+v, want %+v", got, want)
+	}
+}
+
+func TestResolveThresholdsUsesStrictestOverride(t *testing.T) {
+	settings := model.Settings{AlertConsecutiveFailed: 3, AlertFailedPct: 50}
+	groups := []model.Group{
+		{ID: 1, Name: "lab", AlertThresholds: model.AlertThresholds{ConsecutiveFailed: intPtr(20), FailedPct: floatPtr(90)}},
+		{ID: 2, Name: "core", AlertThresholds: model.AlertThresholds{ConsecutiveFailed: intPtr(10)}},
+	}
+
+	got := ResolveThresholds(settings, groups)
+	if got.ConsecutiveFailed != 10 || got.ConsecutiveFailedSource != "core" {
+		t.Fatalf("consecutive = %d from %q, want 10 from core", got.ConsecutiveFailed, got.ConsecutiveFailedSource)
+	}
+	// A single group override replaces the global value even when it is looser.
+	if got.FailedPct != 90 || got.FailedPctSource != "lab" {
+		t.Fatalf("failed pct = %v from %q, want 90 from lab", got.FailedPct, got.FailedPctSource)
+	}
+}
+
+func TestResolveThresholdsIgnoresZeroOverrides(t *testing.T) {
+	settings := model.Settings{AlertConsecutiveFailed: 3, AlertFailedPct: 50}
+	groups := []model.Group{
+		{ID: 1, Name: "quiet", AlertThresholds: model.AlertThresholds{ConsecutiveFailed: intPtr(0), FailedPct: floatPtr(0)}},
+		{ID: 2, Name: "core", AlertThresholds: model.AlertThresholds{FailedPct: floatPtr(30)}},
+	}
+
+	got := ResolveThresholds(settings, groups)
+	if got.ConsecutiveFailed != 3 || got.ConsecutiveFailedSource != ThresholdSourceGlobal {
+		t.Fatalf("consecutive = %d from %q, want the global 3", got.ConsecutiveFailed, got.ConsecutiveFailedSource)
+	}
+	if got.FailedPct != 30 || got.FailedPctSource != "core" {
+		t.Fatalf("failed pct = %v from %q, want 30 from core", got.FailedPct, got.FailedPctSource)
+	}
+}
+
+func TestBreached(t *testing.T) {
+	thresholds := model.EffectiveAlertThresholds{ConsecutiveFailed: 5, FailedPct: 40}
+	tests := []struct {
+		name        string
+		consecutive int64
+		failedPct   float64
+		want        bool
+	}{
+		{name: "below both", consecutive: 4, failedPct: 39.9, want: false},
+		{name: "consecutive reached", consecutive: 5, failedPct: 0, want: true},
+		{name: "failed pct reached", consecutive: 0, failedPct: 40, want: true},
+	}
+	for _, tc := range tests {
+		if got := Breached(thresholds, tc.consecutive, tc.failedPct); got != tc.want {
+			t.Fatalf("%s: Breached() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	if Breached(model.EffectiveAlertThresholds{}, 1000, 100) {
+		t.Fatalf("zero thresholds should disable alerting")
+	}
+}
@@ -0,0 +1,352 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+const (
+	webhookQueueSize   = 256
+	webhookSendTimeout = 10 * time.Second
+	// webhookSweepInterval is how often down alerts held back by the grace
+	// policy or the cooldown are re-checked.
+	webhookSweepInterval = 15 * time.Second
+
+	// webhookEventFlapSummary reports the alerts the cooldown held back for
+	// an endpoint once it has been quiet for a full window.
+	webhookEventFlapSummary = "flap_summary"
+)
+
+// FailingSource reports which of the given endpoints are still failing,
+// with each one's current total_sent_ping. Endpoints that recovered, were
+// deleted or had monitoring disabled are left out.
+type FailingSource interface {
+	StillFailingSampleCounts(ctx context.Context, endpointIDs []int64) (map[int64]int64, error)
+}
+
+// WebhookEvent is the JSON body POSTed to the alert webhook URL.
+type WebhookEvent struct {
+	Event             string    `json:"event"`
+	EndpointID        int64     `json:"endpoint_id"`
+	Hostname          string    `json:"hostname"`
+	IPAddress         string    `json:"ip_address"`
+	Timestamp         time.Time `json:"timestamp"`
+	ConsecutiveFailed int64     `json:"consecutive_failed"`
+	Threshold         int       `json:"threshold"`
+	// FailedPct and FailedPctThreshold are present when a failed_pct
+	// threshold applies to the endpoint.
+	FailedPct          float64 `json:"failed_pct,omitempty"`
+	FailedPctThreshold float64 `json:"failed_pct_threshold,omitempty"`
+	ErrorCode          string  `json:"error_code,omitempty"`
+	// Suppressed counts down alerts held back by the cooldown since the last
+	// one sent for this endpoint.
+	Suppressed int `json:"suppressed,omitempty"`
+	// Flap is set on flap_summary events.
+	Flap *FlapSummary `json:"flap,omitempty"`
+}
+
+// WebhookNotifier turns endpoint transitions into webhook POSTs. Down alerts
+// respect the grace policy and a per-endpoint cooldown; a recovered alert is
+// sent unless the down alert it pairs with was held back, so receivers never
+// see a recovery for an outage they were not told about. A down alert held
+// back by the grace policy is kept and sent by the sweep once the endpoint
+// leaves grace, if it is still failing by then. Once the cooldown settles,
+// the sweep sends a flap summary and, if the endpoint's last held-back alert
+// was a down, that down alert. Deliveries run on a single background worker
+// and never block the caller.
+type WebhookNotifier struct {
+	client *http.Client
+	grace  GracePolicy
+	queue  chan WebhookEvent
+
+	cooldown *Cooldown
+	failing  FailingSource
+
+	mu         sync.Mutex
+	url        string
+	suppressed map[int64]struct{}
+	inGrace    map[int64]model.EndpointTransition
+	// cooledDown is the latest transition the cooldown held back per
+	// endpoint, sent or summarized when the cooldown settles.
+	cooledDown map[int64]model.EndpointTransition
+}
+
+// NewWebhookNotifier returns a disabled notifier; Configure sets the URL.
+// cooldown is the ALERT_COOLDOWN_SEC window for repeat down alerts.
+func NewWebhookNotifier(grace GracePolicy, cooldown time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		client:     &http.Client{Timeout: webhookSendTimeout},
+		grace:      grace,
+		queue:      make(chan WebhookEvent, webhookQueueSize),
+		cooldown:   NewCooldown(cooldown),
+		suppressed: map[int64]struct{}{},
+		inGrace:    map[int64]model.EndpointTransition{},
+		cooledDown: map[int64]model.EndpointTransition{},
+	}
+}
+
+// SetFailingSource lets the sweep release down alerts held back by the
+// grace policy. Without one they are only dropped by a recovery.
+func (n *WebhookNotifier) SetFailingSource(source FailingSource) {
+	n.failing = source
+}
+
+// Configure sets the webhook URL. An empty url disables alerting.
+func (n *WebhookNotifier) Configure(url string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.url = url
+	if url == "" {
+		clear(n.suppressed)
+		clear(n.inGrace)
+		clear(n.cooledDown)
+	}
+}
+
+// Forget drops all alert state of deleted endpoints.
+func (n *WebhookNotifier) Forget(endpointIDs ...int64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, endpointID := range endpointIDs {
+		delete(n.suppressed, endpointID)
+		delete(n.inGrace, endpointID)
+		delete(n.cooledDown, endpointID)
+		n.cooldown.Forget(endpointID)
+	}
+}
+
+func (n *WebhookNotifier) Enabled() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.url != ""
+}
+
+func (n *WebhookNotifier) Observe(transition model.EndpointTransition) {
+	event, ok := n.decide(transition)
+	if !ok {
+		return
+	}
+	n.enqueue(event)
+}
+
+func (n *WebhookNotifier) enqueue(event WebhookEvent) {
+	select {
+	case n.queue <- event:
+	default:
+		slog.Warn("alert webhook queue full; event dropped", "event", event.Event, "endpoint_id", event.EndpointID)
+	}
+}
+
+func (n *WebhookNotifier) decide(transition model.EndpointTransition) (WebhookEvent, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.url == "" {
+		return WebhookEvent{}, false
+	}
+
+	switch transition.State {
+	case model.AlertStateDown:
+		if !n.grace.Eligible(transition.CreatedAt, transition.TotalSentPing, transition.Timestamp) {
+			n.suppressed[transition.EndpointID] = struct{}{}
+			n.inGrace[transition.EndpointID] = transition
+			return WebhookEvent{}, false
+		}
+		return n.notifyDown(transition, transition.Timestamp)
+	case model.AlertStateRecovered:
+		if _, held := n.suppressed[transition.EndpointID]; held {
+			delete(n.suppressed, transition.EndpointID)
+			if _, inGrace := n.inGrace[transition.EndpointID]; inGrace {
+				delete(n.inGrace, transition.EndpointID)
+			} else {
+				n.cooldown.Record(transition.EndpointID, transition.State, transition.Timestamp)
+				n.cooledDown[transition.EndpointID] = transition
+			}
+			return WebhookEvent{}, false
+		}
+		return webhookEventFor(transition), true
+	}
+	return WebhookEvent{}, false
+}
+
+// notifyDown applies the cooldown to a down transition that is past grace.
+// The caller holds n.mu.
+func (n *WebhookNotifier) notifyDown(transition model.EndpointTransition, now time.Time) (WebhookEvent, bool) {
+	decision := n.cooldown.Observe(transition.EndpointID, transition.State, now)
+	if !decision.Notify {
+		n.suppressed[transition.EndpointID] = struct{}{}
+		n.cooledDown[transition.EndpointID] = transition
+		return WebhookEvent{}, false
+	}
+	delete(n.suppressed, transition.EndpointID)
+	delete(n.cooledDown, transition.EndpointID)
+	event := webhookEventFor(transition)
+	event.Suppressed = decision.Suppressed
+	return event, true
+}
+
+func webhookEventFor(transition model.EndpointTransition) WebhookEvent {
+	event := WebhookEvent{
+		Event:             transition.State,
+		EndpointID:        transition.EndpointID,
+		Hostname:          transition.Hostname,
+		IPAddress:         transition.IPAddress,
+		Timestamp:         transition.Timestamp,
+		ConsecutiveFailed: transition.ConsecutiveFailed,
+		Threshold:         transition.Threshold,
+		ErrorCode:         transition.ErrorCode,
+	}
+	if transition.FailedPctThreshold > 0 {
+		event.FailedPct = transition.FailedPct
+		event.FailedPctThreshold = transition.FailedPctThreshold
+	}
+	return event
+}
+
+// sweep sends what the cooldown held back for endpoints that have settled,
+// and the down alerts held back by the grace policy for endpoints that have
+// since left grace and are still failing.
+func (n *WebhookNotifier) sweep(ctx context.Context, now time.Time) {
+	for _, event := range n.settle(now) {
+		n.enqueue(event)
+	}
+	n.sweepGrace(ctx, now)
+}
+
+// settle builds a flap summary for each endpoint whose cooldown has settled.
+// When the last alert held back was a down, the endpoint is still down and
+// that down alert is sent too, so its recovery pairs with it.
+func (n *WebhookNotifier) settle(now time.Time) []WebhookEvent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	events := []WebhookEvent{}
+	for _, summary := range n.cooldown.Settled(now) {
+		transition, ok := n.cooledDown[summary.EndpointID]
+		delete(n.cooledDown, summary.EndpointID)
+		if !ok || n.url == "" {
+			continue
+		}
+		flap := summary
+		events = append(events, WebhookEvent{
+			Event:      webhookEventFlapSummary,
+			EndpointID: summary.EndpointID,
+			Hostname:   transition.Hostname,
+			IPAddress:  transition.IPAddress,
+			Timestamp:  now,
+			Suppressed: summary.Suppressed,
+			Flap:       &flap,
+		})
+		if transition.State == model.AlertStateDown {
+			delete(n.suppressed, summary.EndpointID)
+			event := webhookEventFor(transition)
+			event.Suppressed = summary.Suppressed - 1
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// sweepGrace sends the down alerts held back by the grace policy for
+// endpoints that have since left grace and are still failing. Endpoints the
+// source no longer reports as failing are dropped; their recovery stays
+// skipped.
+func (n *WebhookNotifier) sweepGrace(ctx context.Context, now time.Time) {
+	if n.failing == nil {
+		return
+	}
+	n.mu.Lock()
+	ids := make([]int64, 0, len(n.inGrace))
+	for endpointID := range n.inGrace {
+		ids = append(ids, endpointID)
+	}
+	n.mu.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+
+	samples, err := n.failing.StillFailingSampleCounts(ctx, ids)
+	if err != nil {
+		slog.Warn("alert grace sweep failed", "error", err)
+		return
+	}
+
+	n.mu.Lock()
+	events := []WebhookEvent{}
+	for _, endpointID := range ids {
+		transition, ok := n.inGrace[endpointID]
+		if !ok {
+			continue
+		}
+		totalSent, failing := samples[endpointID]
+		if !failing {
+			delete(n.inGrace, endpointID)
+			continue
+		}
+		if !n.grace.Eligible(transition.CreatedAt, totalSent, now) {
+			continue
+		}
+		delete(n.inGrace, endpointID)
+		transition.TotalSentPing = totalSent
+		if event, ok := n.notifyDown(transition, now); ok {
+			events = append(events, event)
+		}
+	}
+	n.mu.Unlock()
+
+	for _, event := range events {
+		n.enqueue(event)
+	}
+}
+
+// Run delivers queued events and sweeps held-back alerts until ctx is done.
+func (n *WebhookNotifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n.sweep(ctx, now)
+		case event := <-n.queue:
+			n.mu.Lock()
+			url := n.url
+			n.mu.Unlock()
+			if url == "" {
+				continue
+			}
+			if err := n.send(ctx, url, event); err != nil {
+				slog.Warn("alert webhook failed", "event", event.Event, "endpoint_id", event.EndpointID, "error", err)
+			}
+		}
+	}
+}
+
+func (n *WebhookNotifier) send(ctx context.Context, url string, event WebhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestWebhookNotifierPairsDownAndRecoveredAcrossCooldown(t *testing.T) {
+	received := make(chan WebhookEvent, 8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event WebhookEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		received <- event
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(GracePolicy{}, time.Minute)
+	if notifier.Enabled() {
+		t.Fatalf("expected notifier without url to be disabled")
+	}
+	notifier.Configure(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go notifier.Run(ctx)
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	transition := func(state string, offset time.Duration) model.EndpointTransition {
+		return model.EndpointTransition{EndpointID: 7, Hostname: "core-sw", State: state, Timestamp: start.Add(offset), Threshold: 3, ConsecutiveFailed: 3}
+	}
+	notifier.Observe(transition(model.AlertStateDown, 0))
+	notifier.Observe(transition(model.AlertStateRecovered, 10*time.Second))
+	notifier.Observe(transition(model.AlertStateDown, 20*time.Second))
+	notifier.Observe(transition(model.AlertStateRecovered, 30*time.Second))
+	notifier.Observe(transition(model.AlertStateDown, 2*time.Minute))
+
+	want := []struct {
+		event      string
+		suppressed int
+	}{{model.AlertStateDown, 0}, {model.AlertStateRecovered, 0}, {model.AlertStateDown, 1}}
+	for i, expected := range want {
+		select {
+		case event := <-received:
+			if event.Event != expected.event || event.Suppressed != expected.suppressed || event.Hostname != "core-sw" {
+				t.Fatalf("event %d = %+v, want %s with suppressed=%d", i, event, expected.event, expected.suppressed)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+	select {
+	case event := <-received:
+		t.Fatalf("unexpected extra event %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWebhookNotifierHoldsBackEndpointsInGrace(t *testing.T) {
+	notifier := NewWebhookNotifier(GracePolicy{MinSamples: 10}, 0)
+	notifier.Configure("http://127.0.0.1:1/hook")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if _, ok := notifier.decide(model.EndpointTransition{EndpointID: 1, State: model.AlertStateDown, Timestamp: now, TotalSentPing: 3}); ok {
+		t.Fatalf("expected down alert inside grace to be held back")
+	}
+	if _, ok := notifier.decide(model.EndpointTransition{EndpointID: 1, State: model.AlertStateRecovered, Timestamp: now}); ok {
+		t.Fatalf("expected recovery of a held-back outage to be skipped")
+	}
+	if _, ok := notifier.decide(model.EndpointTransition{EndpointID: 2, State: model.AlertStateRecovered, Timestamp: now}); !ok {
+		t.Fatalf("expected recovery with no held-back outage to be sent")
+	}
+}
+
+type fakeFailingSource map[int64]int64
+
+func (f fakeFailingSource) StillFailingSampleCounts(_ context.Context, endpointIDs []int64) (map[int64]int64, error) {
+	counts := map[int64]int64{}
+	for _, id := range endpointIDs {
+		if total, ok := f[id]; ok {
+			counts[id] = total
+		}
+	}
+	return counts, nil
+}
+
+func TestWebhookNotifierSendsDownHeldInGraceOnceGraceEnds(t *testing.T) {
+	received := make(chan WebhookEvent, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event WebhookEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		received <- event
+	}))
+	defer server.Close()
+
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	notifier := NewWebhookNotifier(GracePolicy{Period: 10 * time.Minute}, 0)
+	notifier.SetFailingSource(fakeFailingSource{1: 40})
+	notifier.Configure(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go notifier.Run(ctx)
+
+	notifier.Observe(model.EndpointTransition{EndpointID: 1, Hostname: "edge-1", State: model.AlertStateDown, Timestamp: created.Add(5 * time.Minute), CreatedAt: created, Threshold: 3, ConsecutiveFailed: 3})
+	notifier.Observe(model.EndpointTransition{EndpointID: 2, State: model.AlertStateDown, Timestamp: created.Add(5 * time.Minute), CreatedAt: created})
+	notifier.sweep(ctx, created.Add(8*time.Minute))
+	select {
+	case event := <-received:
+		t.Fatalf("unexpected event while still in grace: %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	notifier.sweep(ctx, created.Add(11*time.Minute))
+	select {
+	case event := <-received:
+		if event.Event != model.AlertStateDown || event.EndpointID != 1 || event.Hostname != "edge-1" {
+			t.Fatalf("event = %+v, want down for endpoint 1", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for the held-back down alert")
+	}
+	select {
+	case event := <-received:
+		t.Fatalf("unexpected extra event %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, ok := notifier.decide(model.EndpointTransition{EndpointID: 1, State: model.AlertStateRecovered, Timestamp: created.Add(12 * time.Minute)}); !ok {
+		t.Fatalf("expected recovery after the released down alert to be sent")
+	}
+	if _, ok := notifier.decide(model.EndpointTransition{EndpointID: 2, State: model.AlertStateRecovered, Timestamp: created.Add(12 * time.Minute)}); ok {
+		t.Fatalf("expected recovery of the endpoint that stopped failing in grace to be skipped")
+	}
+}
+
+func TestWebhookNotifierSettlesCooldownHeldAlerts(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	transition := func(endpointID int64, state string, offset time.Duration) model.EndpointTransition {
+		return model.EndpointTransition{EndpointID: endpointID, Hostname: "core-sw", State: state, Timestamp: start.Add(offset)}
+	}
+	notifier := NewWebhookNotifier(GracePolicy{}, time.Minute)
+	notifier.Configure("http://127.0.0.1:1/hook")
+
+	// Endpoint 1 goes down again inside the cooldown and stays down.
+	// Endpoint 2 flaps inside the cooldown and ends up recovered.
+	for _, id := range []int64{1, 2} {
+		notifier.decide(transition(id, model.AlertStateDown, 0))
+		notifier.decide(transition(id, model.AlertStateRecovered, 10*time.Second))
+		if _, ok := notifier.decide(transition(id, model.AlertStateDown, 20*time.Second)); ok {
+			t.Fatalf("endpoint %d: expected down inside the cooldown to be held back", id)
+		}
+	}
+	notifier.decide(transition(2, model.AlertStateRecovered, 30*time.Second))
+
+	if events := notifier.settle(start.Add(70 * time.Second)); len(events) != 0 {
+		t.Fatalf("settle() inside the window = %+v, want none", events)
+	}
+	events := notifier.settle(start.Add(2 * time.Minute))
+	if len(events) != 3 {
+		t.Fatalf("settle() = %+v, want two summaries and one down", events)
+	}
+	if event := events[0]; event.Event != webhookEventFlapSummary || event.EndpointID != 1 || event.Flap == nil || event.Flap.FinalState != model.AlertStateDown || event.Suppressed != 1 {
+		t.Fatalf("events[0] = %+v, want endpoint 1 summary ending down", event)
+	}
+	if event := events[1]; event.Event != model.AlertStateDown || event.EndpointID != 1 || !event.Timestamp.Equal(start.Add(20*time.Second)) {
+		t.Fatalf("events[1] = %+v, want the held-back down for endpoint 1", event)
+	}
+	if event := events[2]; event.Event != webhookEventFlapSummary || event.EndpointID != 2 || event.Flap == nil || event.Flap.FinalState != model.AlertStateRecovered {
+		t.Fatalf("events[2] = %+v, want endpoint 2 summary ending recovered", event)
+	}
+
+	if _, ok := notifier.decide(transition(1, model.AlertStateRecovered, 3*time.Minute)); !ok {
+		t.Fatalf("expected recovery after the released down alert to be sent")
+	}
+	if len(notifier.cooldown.endpoints) != 0 {
+		t.Fatalf("cooldown kept %d settled endpoints", len(notifier.cooldown.endpoints))
+	}
+}
+
+func TestWebhookNotifierForgetDropsEndpointState(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	notifier := NewWebhookNotifier(GracePolicy{}, time.Minute)
+	notifier.Configure("http://127.0.0.1:1/hook")
+	notifier.decide(model.EndpointTransition{EndpointID: 1, State: model.AlertStateDown, Timestamp: start})
+	notifier.decide(model.EndpointTransition{EndpointID: 1, State: model.AlertStateRecovered, Timestamp: start.Add(10 * time.Second)})
+	notifier.decide(model.EndpointTransition{EndpointID: 1, State: model.AlertStateDown, Timestamp: start.Add(20 * time.Second)})
+
+	notifier.Forget(1)
+	if events := notifier.settle(start.Add(5 * time.Minute)); len(events) != 0 {
+		t.Fatalf("settle() after Forget = %+v, want none", events)
+	}
+	if len(notifier.suppressed) != 0 || len(notifier.cooledDown) != 0 || len(notifier.cooldown.endpoints) != 0 {
+		t.Fatalf("Forget left state behind")
+	}
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"sonarscope/backend/internal/util"
+)
+
+// handleAdminResumeMaintenance re-enables the TimescaleDB policy jobs a delete
+// paused and never resumed. It refuses while a delete job is running, since
+// that job resumes its own jobs when it finishes.
+func (s *Server) handleAdminResumeMaintenance(w http.ResponseWriter, r *http.Request) {
+	if s.isDeleteJobRunning() {
+		util.WriteError(w, http.StatusConflict, "inventory deletion in progress")
+		return
+	}
+	jobIDs, err := s.store.ResumePausedMaintenanceJobs(r.Context())
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"resumed_job_ids": jobIDs})
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/alert"
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/util"
+)
+
+func (s *Server) handleGroupAlertThresholdsUpdate(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "groupID"), 10, 64)
+	if err != nil || groupID < 1 {
+		util.WriteError(w, http.StatusBadRequest, "invalid group id")
+		return
+	}
+
+	var req model.AlertThresholds
+	if err := util.DecodeJSON(r, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	if req.ConsecutiveFailed != nil && (*req.ConsecutiveFailed < 1 || *req.ConsecutiveFailed > 100000) {
+		util.WriteError(w, http.StatusBadRequest, "consecutive_failed must be between 1 and 100000, or null")
+		return
+	}
+	if req.FailedPct != nil && (*req.FailedPct <= 0 || *req.FailedPct > 100) {
+		util.WriteError(w, http.StatusBadRequest, "failed_pct must be greater than 0 and at most 100, or null")
+		return
+	}
+
+	group, err := s.store.SetGroupAlertThresholds(r.Context(), groupID, req)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.WriteError(w, http.StatusNotFound, "group not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, group)
+}
+
+func (s *Server) handleInventoryEndpointAlertThresholds(w http.ResponseWriter, r *http.Request) {
+	endpointID, err := strconv.ParseInt(chi.URLParam(r, "endpointID"), 10, 64)
+	if err != nil || endpointID < 1 {
+		util.WriteError(w, http.StatusBadRequest, "invalid endpoint id")
+		return
+	}
+
+	groups, err := s.store.ListEndpointAlertGroups(r.Context(), endpointID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.WriteError(w, http.StatusNotFound, "inventory endpoint not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	settings, err := s.store.GetSettings(r.Context())
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"endpoint_id": endpointID,
+		"thresholds":  alert.ResolveThresholds(settings, groups),
+	})
+}
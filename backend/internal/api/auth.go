@@ -0,0 +1,218 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"strings"
+	"time"
+
+	"sonarscope/backend/internal/util"
+)
+
+const (
+	sessionCookieName = "sonarscope_session"
+	sessionTTL        = 12 * time.Hour
+	sessionNonceSize  = 16
+)
+
+// apiKeyMiddleware requires one of the configured API_KEYS, or a session
+// cookie issued for one, on mutating /api requests. Reads stay open so
+// dashboards keep working, except routes wrapped in requireAPIKey. With no
+// keys configured every request passes through. CORS preflights never reach
+// this handler because corsMiddleware answers OPTIONS first.
+func (s *Server) apiKeyMiddleware(next http.Handler) http.Handler {
+	return s.apiKeyCheck(next, false)
+}
+
+// requireAPIKey is apiKeyMiddleware for reads too, for GET routes that have
+// side effects or expose configuration.
+func (s *Server) requireAPIKey(next http.Handler) http.Handler {
+	return s.apiKeyCheck(next, true)
+}
+
+func (s *Server) apiKeyCheck(next http.Handler, reads bool) http.Handler {
+	keys := s.apiKeyBytes()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(keys) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !reads {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		presented := requestAPIKey(r)
+		if presented == "" {
+			if validSessionCookie(keys, r, time.Now()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			util.WriteError(w, http.StatusUnauthorized, "missing API key")
+			return
+		}
+		if !validAPIKey(keys, []byte(presented)) {
+			util.WriteError(w, http.StatusUnauthorized, "invalid API key")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) apiKeyBytes() [][]byte {
+	keys := make([][]byte, 0, len(s.cfg.APIKeys))
+	for _, key := range s.cfg.APIKeys {
+		keys = append(keys, []byte(key))
+	}
+	return keys
+}
+
+// requestAPIKey reads the key from "Authorization: Bearer <key>" or, failing
+// that, the X-API-Key header.
+func requestAPIKey(r *http.Request) string {
+	if auth := strings.TrimSpace(r.Header.Get("Authorization")); auth != "" {
+		scheme, token, ok := strings.Cut(auth, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+func validAPIKey(keys [][]byte, presented []byte) bool {
+	match := 0
+	for _, key := range keys {
+		match |= subtle.ConstantTimeCompare(key, presented)
+	}
+	return match == 1
+}
+
+// handleGetAuthSession tells the UI whether it has to ask for a key.
+func (s *Server) handleGetAuthSession(w http.ResponseWriter, r *http.Request) {
+	keys := s.apiKeyBytes()
+	authenticated := len(keys) == 0 || validSessionCookie(keys, r, time.Now())
+	if !authenticated {
+		if presented := requestAPIKey(r); presented != "" {
+			authenticated = validAPIKey(keys, []byte(presented))
+		}
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"auth_required": len(keys) > 0,
+		"authenticated": authenticated,
+	})
+}
+
+// handleCreateAuthSession trades an API key for an HttpOnly session cookie,
+// so the browser UI never holds the key itself. The cookie is signed with
+// the key it was issued for and stops working when that key is removed.
+func (s *Server) handleCreateAuthSession(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		APIKey string `json:"api_key"`
+	}
+	if err := util.DecodeJSON(r, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	keys := s.apiKeyBytes()
+	if len(keys) == 0 {
+		util.WriteJSON(w, http.StatusOK, map[string]any{"auth_required": false, "authenticated": true})
+		return
+	}
+	presented := []byte(strings.TrimSpace(req.APIKey))
+	if !validAPIKey(keys, presented) {
+		util.WriteError(w, http.StatusUnauthorized, "invalid API key")
+		return
+	}
+
+	expiresAt := time.Now().Add(sessionTTL).UTC()
+	token, err := newSessionToken(presented, expiresAt)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   requestIsHTTPS(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"auth_required": true,
+		"authenticated": true,
+		"expires_at":    expiresAt,
+	})
+}
+
+func (s *Server) handleDeleteAuthSession(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   requestIsHTTPS(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+	util.WriteJSON(w, http.StatusOK, map[string]any{"authenticated": false})
+}
+
+func requestIsHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// newSessionToken encodes the expiry and a random nonce, followed by their
+// HMAC under key.
+func newSessionToken(key []byte, expiresAt time.Time) (string, error) {
+	payload := make([]byte, 8+sessionNonceSize)
+	binary.BigEndian.PutUint64(payload, uint64(expiresAt.Unix()))
+	if _, err := rand.Read(payload[8:]); err != nil {
+		return "", err
+	}
+	encoding := base64.RawURLEncoding
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(sessionMAC(key, payload)), nil
+}
+
+func sessionMAC(key []byte, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func validSessionCookie(keys [][]byte, r *http.Request, now time.Time) bool {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return false
+	}
+	rawPayload, rawMAC, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return false
+	}
+	encoding := base64.RawURLEncoding
+	payload, err := encoding.DecodeString(rawPayload)
+	if err != nil || len(payload) != 8+sessionNonceSize {
+		return false
+	}
+	presentedMAC, err := encoding.DecodeString(rawMAC)
+	if err != nil {
+		return false
+	}
+	if expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0); !now.Before(expiresAt) {
+		return false
+	}
+	match := 0
+	for _, key := range keys {
+		match |= subtle.ConstantTimeCompare(sessionMAC(key, payload), presentedMAC)
+	}
+	return match == 1
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/config"
+)
+
+func newAPIKeyTestServer() *Server {
+	return newServerWithStore(config.Config{APIKeys: []string{"alpha", "bravo"}}, newMemoryStore(), nil, nil)
+}
+
+func serveAPIKeyRequest(srv *Server, method string, target string, header string, value string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(`{"name":"core"}`))
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	rec := httptest.NewRecorder()
+	srv.Routes().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIKeyMiddlewareRejectsMissingOrInvalidKey(t *testing.T) {
+	srv := newAPIKeyTestServer()
+
+	rec := serveAPIKeyRequest(srv, http.MethodPost, "/api/groups/", "", "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("missing key status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	rec = serveAPIKeyRequest(srv, http.MethodPost, "/api/groups/", "Authorization", "Bearer charlie")
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("invalid key status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	rec = serveAPIKeyRequest(srv, http.MethodGet, "/api/monitor/incidents", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("read without key status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	rec = serveAPIKeyRequest(srv, http.MethodGet, "/healthz", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("healthz status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestAPIKeyMiddlewareAcceptsBearerAndHeaderKeys(t *testing.T) {
+	srv := newAPIKeyTestServer()
+
+	for _, tc := range []struct{ header, value string }{
+		{"Authorization", "Bearer alpha"},
+		{"Authorization", "bearer bravo"},
+		{"X-API-Key", "bravo"},
+	} {
+		rec := serveAPIKeyRequest(srv, http.MethodPost, "/api/groups/", tc.header, tc.value)
+		if rec.Code == http.StatusUnauthorized {
+			t.Fatalf("%s %q rejected: %s", tc.header, tc.value, rec.Body.String())
+		}
+	}
+}
+
+func TestAPIKeyMiddlewareLetsPreflightThrough(t *testing.T) {
+	srv := newAPIKeyTestServer()
+
+	rec := serveAPIKeyRequest(srv, http.MethodOptions, "/api/inventory/endpoints/delete-all", "", "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("preflight status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
+
+func TestAPIKeyMiddlewareDisabledWithoutKeys(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+
+	rec := serveAPIKeyRequest(srv, http.MethodPost, "/api/groups/", "", "")
+	if rec.Code == http.StatusUnauthorized {
+		t.Fatalf("expected open API without configured keys: %s", rec.Body.String())
+	}
+}
+
+func TestAPIKeyRequiredOnSensitiveReads(t *testing.T) {
+	srv := newAPIKeyTestServer()
+
+	for _, target := range []string{"/api/settings/", "/api/settings/retention"} {
+		rec := serveAPIKeyRequest(srv, http.MethodGet, target, "", "")
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("GET %s without key status = %d, want %d", target, rec.Code, http.StatusUnauthorized)
+		}
+	}
+	if rec := serveAPIKeyRequest(srv, http.MethodPost, "/api/monitor/snapshot", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("POST snapshot without key status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	rec := serveAPIKeyRequest(srv, http.MethodGet, "/api/settings/", "X-API-Key", "alpha")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET settings with key status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestAuthSessionCookieStandsInForKey(t *testing.T) {
+	srv := newAPIKeyTestServer()
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/auth/session", map[string]any{"api_key": "charlie"})
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("invalid key session status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	rec = serveTestJSONRequest(t, srv, http.MethodPost, "/api/auth/session", map[string]any{"api_key": "bravo"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("session status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != sessionCookieName || !cookies[0].HttpOnly || strings.Contains(cookies[0].Value, "bravo") {
+		t.Fatalf("unexpected session cookies: %+v", cookies)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/settings/", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	srv.Routes().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET settings with session status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	// A session signed by a key that has since been removed is rejected.
+	rotated := newServerWithStore(config.Config{APIKeys: []string{"alpha"}}, newMemoryStore(), nil, nil)
+	req = httptest.NewRequest(http.MethodGet, "/api/settings/", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	rotated.Routes().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("GET settings with rotated-out session status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	expired, err := newSessionToken([]byte("alpha"), time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("newSessionToken: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/settings/", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: expired})
+	if validSessionCookie([][]byte{[]byte("alpha")}, req, time.Now()) {
+		t.Fatalf("expired session accepted")
+	}
+}
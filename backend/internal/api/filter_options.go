@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"sonarscope/backend/internal/util"
+)
+
+type filterOptionsCacheEntry struct {
+	filters   map[string][]string
+	expiresAt time.Time
+}
+
+// filterOptionsCache holds the distinct filter values for the inventory
+// (all endpoints) and monitor (active only) views. Writes that race with an
+// invalidation are discarded by comparing generations, so a slow read that
+// started before a mutation can never repopulate the cache with stale data.
+type filterOptionsCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	now        func() time.Time
+	generation uint64
+	entries    map[bool]filterOptionsCacheEntry
+}
+
+func newFilterOptionsCache(ttl time.Duration) *filterOptionsCache {
+	return &filterOptionsCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[bool]filterOptionsCacheEntry{},
+	}
+}
+
+func (c *filterOptionsCache) get(activeOnly bool) (map[string][]string, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[activeOnly]
+	if !ok || !c.now().Before(entry.expiresAt) {
+		return nil, c.generation, false
+	}
+	return entry.filters, c.generation, true
+}
+
+func (c *filterOptionsCache) put(activeOnly bool, generation uint64, filters map[string][]string) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.entries[activeOnly] = filterOptionsCacheEntry{filters: filters, expiresAt: c.now().Add(c.ttl)}
+}
+
+func (c *filterOptionsCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	clear(c.entries)
+}
+
+func (s *Server) listFilterOptions(r *http.Request, activeOnly bool) (map[string][]string, error) {
+	filters, generation, ok := s.filterCache.get(activeOnly)
+	if ok {
+		return filters, nil
+	}
+	filters, err := s.store.ListDistinctFilters(r.Context(), activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	s.filterCache.put(activeOnly, generation, filters)
+	return filters, nil
+}
+
+// handleMonitorFilterCounts serves filter-options?with_counts=true. Counts
+// depend on the applied filters, so they bypass filterCache.
+func (s *Server) handleMonitorFilterCounts(w http.ResponseWriter, r *http.Request) {
+	query, parseErr := s.monitorPageQueryFromRequest(r, monitorRequestOptions{})
+	if parseErr != nil {
+		util.WriteError(w, parseErr.Status, parseErr.Message)
+		return
+	}
+	counts, err := s.store.ListFilterCounts(r.Context(), query)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, counts)
+}
+
+// invalidateFilterOptionsOnWrite drops cached filter options after any
+// mutating request on routes that can change VLAN, switch, port, or group
+// values.
+func (s *Server) invalidateFilterOptionsOnWrite(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			s.filterCache.invalidate()
+		}
+	})
+}
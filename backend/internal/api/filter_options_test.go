@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestFilterOptionsCacheExpiresAfterTTL(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	cache := newFilterOptionsCache(30 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	_, generation, ok := cache.get(false)
+	if ok {
+		t.Fatalf("expected empty cache miss")
+	}
+	cache.put(false, generation, map[string][]string{"vlan": {"10"}})
+
+	filters, _, ok := cache.get(false)
+	if !ok || len(filters["vlan"]) != 1 {
+		t.Fatalf("expected cache hit, got ok=%v filters=%v", ok, filters)
+	}
+	if _, _, ok := cache.get(true); ok {
+		t.Fatalf("expected active-only entry to be cached separately")
+	}
+
+	now = now.Add(30 * time.Second)
+	if _, _, ok := cache.get(false); ok {
+		t.Fatalf("expected entry to expire at TTL")
+	}
+}
+
+func TestFilterOptionsCacheDropsPutAfterInvalidate(t *testing.T) {
+	cache := newFilterOptionsCache(time.Minute)
+
+	_, generation, _ := cache.get(true)
+	cache.invalidate()
+	cache.put(true, generation, map[string][]string{"switch": {"sw-old"}})
+
+	if _, _, ok := cache.get(true); ok {
+		t.Fatalf("expected stale put from before invalidation to be discarded")
+	}
+}
+
+func TestFilterOptionsCacheDisabledWithZeroTTL(t *testing.T) {
+	cache := newFilterOptionsCache(0)
+	_, generation, _ := cache.get(false)
+	cache.put(false, generation, map[string][]string{"vlan": {"10"}})
+	if _, _, ok := cache.get(false); ok {
+		t.Fatalf("expected zero TTL to disable caching")
+	}
+}
+
+func TestInventoryFiltersServedFromCacheUntilWrite(t *testing.T) {
+	st := newMemoryStore()
+	st.filters = map[string][]string{"vlan": {"10"}}
+	srv := newTestServer(st)
+	srv.filterCache = newFilterOptionsCache(time.Minute)
+
+	for i := 0; i < 2; i++ {
+		rec := serveTestRequest(t, srv, http.MethodGet, "/api/inventory/filter-options")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if st.filterHits != 1 {
+		t.Fatalf("expected one store read for repeated GETs, got %d", st.filterHits)
+	}
+
+	serveTestJSONRequest(t, srv, http.MethodPost, "/api/groups/", map[string]any{})
+	serveTestRequest(t, srv, http.MethodGet, "/api/inventory/filter-options")
+	if st.filterHits != 2 {
+		t.Fatalf("expected write to invalidate cache, got %d store reads", st.filterHits)
+	}
+}
+
+func TestMonitorFilterOptionsWithCountsAppliesFilters(t *testing.T) {
+	st := newMemoryStore()
+	st.filters = map[string][]string{"vlan": {"10", "20"}}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/filter-options?with_counts=true&switch=sw-a&hostname=web")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var counts map[string][]model.FilterValueCount
+	decodeTestResponse(t, rec, &counts)
+	if len(counts["vlan"]) != 2 || counts["vlan"][1].Value != "20" || counts["vlan"][1].Count != 1 {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
+	if len(st.lastQuery.Filters.Switches) != 1 || st.lastQuery.Hostname != "web" {
+		t.Fatalf("expected applied filters to reach the store, got %+v", st.lastQuery)
+	}
+	if st.filterHits != 0 {
+		t.Fatalf("expected counts to bypass the distinct-values read, got %d", st.filterHits)
+	}
+
+	if rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/filter-options?with_counts=maybe"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad with_counts status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
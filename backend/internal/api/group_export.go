@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuri/excelize/v2"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/util"
+)
+
+var groupExportFilenameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// handleGroupExport writes the group's members with the same columns as the
+// inventory export. The first row is a comment carrying the group name and
+// description, so the file can be handed off as a group definition.
+func (s *Server) handleGroupExport(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "groupID"), 10, 64)
+	if err != nil || groupID < 1 {
+		util.WriteError(w, http.StatusBadRequest, "invalid group id")
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		util.WriteError(w, http.StatusBadRequest, "format must be csv or xlsx")
+		return
+	}
+	redact, err := parseBoolQuery(r, "redact")
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	group, err := s.store.GetGroupByID(r.Context(), groupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.WriteError(w, http.StatusNotFound, "group not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	endpointIDs, err := s.store.ListEndpointIDsByGroup(r.Context(), groupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.WriteError(w, http.StatusNotFound, "group not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	items, err := s.store.ListInventoryEndpointsByIDs(r.Context(), endpointIDs, len(endpointIDs))
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	settings, err := s.store.GetSettings(r.Context())
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	customFields := enabledInventoryExportCustomFields(normalizeCustomFieldConfigs(settings.CustomFields))
+
+	rows := make([][]string, 0, len(items)+2)
+	rows = append(rows, groupExportCommentRow(group), inventoryExportHeader(customFields))
+	var redactor *exportRedactor
+	if redact {
+		redactor = newExportRedactor()
+	}
+	for _, item := range items {
+		if redactor != nil {
+			item.Hostname = redactor.Hostname(item.Hostname)
+			item.IPAddress = redactor.IP(item.IPAddress)
+			item.Switch = redactor.Hostname(item.Switch)
+			item.Gateway = redactor.IP(item.Gateway)
+			item.MgmtIP = redactor.IP(item.MgmtIP)
+		}
+		rows = append(rows, inventoryExportRecord(item, customFields))
+	}
+
+	var (
+		body        bytes.Buffer
+		contentType string
+	)
+	if format == "xlsx" {
+		if err := writeGroupExportXLSX(&body, rows); err != nil {
+			util.WriteError(w, http.StatusInternalServerError, fmt.Sprintf("write xlsx: %v", err))
+			return
+		}
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	} else {
+		csvWriter := csv.NewWriter(&body)
+		if err := csvWriter.WriteAll(rows); err != nil {
+			util.WriteError(w, http.StatusInternalServerError, fmt.Sprintf("write csv: %v", err))
+			return
+		}
+		contentType = "text/csv; charset=utf-8"
+	}
+
+	filename := fmt.Sprintf("group-%s-%s.%s", groupExportFilenamePart(group), time.Now().UTC().Format("20060102-150405"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body.Bytes()); err != nil {
+		slog.Warn("group export write response", "group_id", groupID, "error", err)
+	}
+}
+
+func groupExportCommentRow(group model.Group) []string {
+	row := []string{"# Group: " + group.Name}
+	if description := strings.TrimSpace(group.Description); description != "" {
+		row = append(row, "Description: "+description)
+	}
+	return row
+}
+
+func groupExportFilenamePart(group model.Group) string {
+	name := strings.Trim(groupExportFilenameUnsafe.ReplaceAllString(group.Name, "-"), "-")
+	if name == "" {
+		return strconv.FormatInt(group.ID, 10)
+	}
+	return name
+}
+
+func writeGroupExportXLSX(w *bytes.Buffer, rows [][]string) error {
+	book := excelize.NewFile()
+	defer book.Close()
+
+	sheet := book.GetSheetName(0)
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		values := make([]any, len(row))
+		for j, value := range row {
+			values[j] = value
+		}
+		if err := book.SetSheetRow(sheet, cell, &values); err != nil {
+			return err
+		}
+	}
+	return book.Write(w)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"sonarscope/backend/internal/model"
+)
+
+func newGroupExportTestStore() *memoryStore {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{
+		{EndpointID: 1, Hostname: "db-1", IPAddress: "10.0.0.1", Active: true, Groups: []string{"DB-Core"}},
+		{EndpointID: 2, Hostname: "web-1", IPAddress: "10.0.0.2", Active: true},
+		{EndpointID: 3, Hostname: "db-2", IPAddress: "10.0.0.3", Groups: []string{"DB-Core"}},
+	}
+	st.groupScope = map[int64][]int64{7: {1, 3}}
+	return st
+}
+
+func TestHandleGroupExportCSV(t *testing.T) {
+	srv := newTestServer(newGroupExportTestStore())
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/groups/7/export")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Fatalf("content type = %q, want text/csv", got)
+	}
+	reader := csv.NewReader(rec.Body)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("rows = %d, want comment, header, and 2 members: %v", len(rows), rows)
+	}
+	if !strings.HasPrefix(rows[0][0], "# Group:") || rows[1][0] != "Hostname" {
+		t.Fatalf("unexpected leading rows: %v", rows[:2])
+	}
+	if rows[2][0] != "db-1" || rows[3][0] != "db-2" || rows[3][2] != "Inactive" {
+		t.Fatalf("unexpected member rows: %v", rows[2:])
+	}
+}
+
+func TestHandleGroupExportXLSX(t *testing.T) {
+	srv := newTestServer(newGroupExportTestStore())
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/groups/7/export?format=xlsx")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	book, err := excelize.OpenReader(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	defer book.Close()
+	rows, err := book.GetRows(book.GetSheetName(0))
+	if err != nil {
+		t.Fatalf("read xlsx rows: %v", err)
+	}
+	if len(rows) != 4 || rows[1][1] != "IP Address" || rows[2][1] != "10.0.0.1" {
+		t.Fatalf("unexpected xlsx rows: %v", rows)
+	}
+}
+
+func TestHandleGroupExportRedacts(t *testing.T) {
+	srv := newTestServer(newGroupExportTestStore())
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/groups/7/export?redact=true")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	reader := csv.NewReader(rec.Body)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("rows = %d, want comment, header, and 2 members: %v", len(rows), rows)
+	}
+	for _, row := range rows[2:] {
+		if !strings.HasPrefix(row[0], "host-") || row[0] == "db-1" || row[0] == "db-2" {
+			t.Fatalf("hostname not redacted: %v", row)
+		}
+		if !strings.HasPrefix(row[1], "10.0.") || row[1] == "10.0.0.1" || row[1] == "10.0.0.3" {
+			t.Fatalf("ip not redacted to its /16: %v", row)
+		}
+	}
+	if !strings.HasPrefix(rows[0][0], "# Group:") {
+		t.Fatalf("group comment row should be kept: %v", rows[0])
+	}
+}
+
+func TestHandleGroupExportRejectsBadRequests(t *testing.T) {
+	srv := newTestServer(newGroupExportTestStore())
+
+	if rec := serveTestRequest(t, srv, http.MethodGet, "/api/groups/7/export?format=pdf"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad format status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := serveTestRequest(t, srv, http.MethodGet, "/api/groups/7/export?redact=maybe"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad redact status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := serveTestRequest(t, srv, http.MethodGet, "/api/groups/99/export"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown group status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/util"
+)
+
+func (s *Server) handleGroupProbeIntervalUpdate(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "groupID"), 10, 64)
+	if err != nil || groupID < 1 {
+		util.WriteError(w, http.StatusBadRequest, "invalid group id")
+		return
+	}
+
+	var req struct {
+		ProbeIntervalSec *int `json:"probe_interval_sec"`
+	}
+	if err := util.DecodeJSON(r, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	if req.ProbeIntervalSec != nil && (*req.ProbeIntervalSec < 1 || *req.ProbeIntervalSec > 3600) {
+		util.WriteError(w, http.StatusBadRequest, "probe_interval_sec must be between 1 and 3600, or null")
+		return
+	}
+
+	group, err := s.store.SetGroupProbeInterval(r.Context(), groupID, req.ProbeIntervalSec)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.WriteError(w, http.StatusNotFound, "group not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, group)
+}
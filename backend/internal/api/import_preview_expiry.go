@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+const (
+	defaultImportPreviewTTL      = 30 * time.Minute
+	defaultImportPreviewMax      = 50
+	importPreviewJanitorInterval = time.Minute
+)
+
+// previewTombstones remembers the IDs of previews and retries that were
+// evicted, so a late apply gets 410 instead of 404. A tombstone is kept for
+// one more TTL after the eviction.
+type previewTombstones struct {
+	mu  sync.Mutex
+	ids map[string]time.Time
+}
+
+func newPreviewTombstones() *previewTombstones {
+	return &previewTombstones{ids: map[string]time.Time{}}
+}
+
+func (t *previewTombstones) add(id string, at time.Time) {
+	t.mu.Lock()
+	t.ids[id] = at
+	t.mu.Unlock()
+}
+
+func (t *previewTombstones) has(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.ids[id]
+	return ok
+}
+
+func (t *previewTombstones) prune(before time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, at := range t.ids {
+		if at.Before(before) {
+			delete(t.ids, id)
+		}
+	}
+}
+
+// expirePreviewEntries drops entries created before cutoff, then the oldest
+// ones until at most limit remain (limit <= 0 skips the cap).
+func expirePreviewEntries[T any](entries map[string]T, createdAt func(T) time.Time, cutoff time.Time, limit int, tombstones *previewTombstones, now time.Time) {
+	for id, entry := range entries {
+		if createdAt(entry).Before(cutoff) {
+			delete(entries, id)
+			tombstones.add(id, now)
+		}
+	}
+	for limit > 0 && len(entries) > limit {
+		oldestID := ""
+		var oldest time.Time
+		for id, entry := range entries {
+			if created := createdAt(entry); oldestID == "" || created.Before(oldest) {
+				oldestID, oldest = id, created
+			}
+		}
+		delete(entries, oldestID)
+		tombstones.add(oldestID, now)
+	}
+}
+
+func importPreviewCreatedAt(preview model.ImportPreview) time.Time { return preview.CreatedAt }
+
+func importRetryCreatedAt(retry importRetry) time.Time { return retry.CreatedAt }
+
+func switchPreviewCreatedAt(preview model.SwitchDirectoryImportPreview) time.Time {
+	return preview.CreatedAt
+}
+
+func (s *Server) previewExpired(createdAt time.Time) bool {
+	return time.Since(createdAt) > s.previewTTL
+}
+
+// storeImportPreview keeps preview, evicting expired previews and, past
+// previewMax, the oldest ones.
+func (s *Server) storeImportPreview(preview model.ImportPreview) {
+	now := time.Now().UTC()
+	s.previewMu.Lock()
+	defer s.previewMu.Unlock()
+	expirePreviewEntries(s.previews, importPreviewCreatedAt, now.Add(-s.previewTTL), s.previewMax-1, s.expiredPreviews, now)
+	s.previews[preview.PreviewID] = preview
+}
+
+func (s *Server) storeImportRetry(retryID string, retry importRetry) {
+	now := time.Now().UTC()
+	s.previewMu.Lock()
+	defer s.previewMu.Unlock()
+	expirePreviewEntries(s.importRetries, importRetryCreatedAt, now.Add(-s.previewTTL), s.previewMax-1, s.expiredPreviews, now)
+	s.importRetries[retryID] = retry
+}
+
+func (s *Server) storeSwitchPreview(preview model.SwitchDirectoryImportPreview) {
+	now := time.Now().UTC()
+	s.switchPreviewMu.Lock()
+	defer s.switchPreviewMu.Unlock()
+	expirePreviewEntries(s.switchPreviews, switchPreviewCreatedAt, now.Add(-s.previewTTL), s.previewMax-1, s.expiredPreviews, now)
+	s.switchPreviews[preview.PreviewID] = preview
+}
+
+// sweepExpiredPreviews evicts every preview and retry older than previewTTL
+// and forgets tombstones older than another TTL.
+func (s *Server) sweepExpiredPreviews(now time.Time) {
+	cutoff := now.Add(-s.previewTTL)
+
+	s.previewMu.Lock()
+	expirePreviewEntries(s.previews, importPreviewCreatedAt, cutoff, 0, s.expiredPreviews, now)
+	expirePreviewEntries(s.importRetries, importRetryCreatedAt, cutoff, 0, s.expiredPreviews, now)
+	s.previewMu.Unlock()
+
+	s.switchPreviewMu.Lock()
+	expirePreviewEntries(s.switchPreviews, switchPreviewCreatedAt, cutoff, 0, s.expiredPreviews, now)
+	s.switchPreviewMu.Unlock()
+
+	s.expiredPreviews.prune(cutoff)
+}
+
+// RunPreviewJanitor evicts expired import previews and retries until ctx is
+// done.
+func (s *Server) RunPreviewJanitor(ctx context.Context) {
+	ticker := time.NewTicker(importPreviewJanitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sweepExpiredPreviews(now.UTC())
+		}
+	}
+}
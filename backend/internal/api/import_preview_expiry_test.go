@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/probe"
+)
+
+func TestImportApplyExpiredPreviewIsGone(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+	srv.probe = probe.NewEngine(nil, nil, probe.Options{}, model.Settings{})
+	srv.previews["stale"] = model.ImportPreview{PreviewID: "stale", CreatedAt: time.Now().UTC().Add(-2 * defaultImportPreviewTTL)}
+
+	for i := 0; i < 2; i++ {
+		rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/import-apply", map[string]any{"preview_id": "stale"})
+		if rec.Code != http.StatusGone {
+			t.Fatalf("attempt %d: status = %d, want %d: %s", i+1, rec.Code, http.StatusGone, rec.Body.String())
+		}
+	}
+	if _, ok := srv.previews["stale"]; ok {
+		t.Fatalf("expired preview should be evicted on apply")
+	}
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/import-apply", map[string]any{"preview_id": "never-existed"})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown preview status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestStoreImportPreviewEvictsOldestPastCap(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+	srv.previewMax = 2
+	start := time.Now().UTC()
+	for i, id := range []string{"first", "second", "third"} {
+		srv.storeImportPreview(model.ImportPreview{PreviewID: id, CreatedAt: start.Add(time.Duration(i) * time.Second)})
+	}
+
+	if len(srv.previews) != 2 {
+		t.Fatalf("stored previews = %d, want 2", len(srv.previews))
+	}
+	if _, ok := srv.previews["first"]; ok || !srv.expiredPreviews.has("first") {
+		t.Fatalf("oldest preview should be evicted and tombstoned")
+	}
+}
+
+func TestSweepExpiredPreviews(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+	now := time.Now().UTC()
+	old := now.Add(-defaultImportPreviewTTL - time.Minute)
+	srv.previews["old"] = model.ImportPreview{PreviewID: "old", CreatedAt: old}
+	srv.previews["fresh"] = model.ImportPreview{PreviewID: "fresh", CreatedAt: now}
+	srv.importRetries["old-retry"] = importRetry{CreatedAt: old}
+	srv.switchPreviews["old-switch"] = model.SwitchDirectoryImportPreview{PreviewID: "old-switch", CreatedAt: old}
+
+	srv.sweepExpiredPreviews(now)
+	if len(srv.previews) != 1 || len(srv.importRetries) != 0 || len(srv.switchPreviews) != 0 {
+		t.Fatalf("sweep left previews %v, retries %v, switch previews %v", srv.previews, srv.importRetries, srv.switchPreviews)
+	}
+	if !srv.expiredPreviews.has("old-retry") {
+		t.Fatalf("expired retry should be tombstoned")
+	}
+
+	srv.sweepExpiredPreviews(now.Add(defaultImportPreviewTTL + time.Minute))
+	if srv.expiredPreviews.has("old") {
+		t.Fatalf("tombstones should be forgotten one TTL after eviction")
+	}
+}
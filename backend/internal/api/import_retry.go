@@ -0,0 +1,176 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"sonarscope/backend/internal/importer"
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/util"
+)
+
+// importRetry keeps the rows that failed during an import apply so they can
+// be re-attempted after the preview itself has been discarded. GroupID is the
+// group the original apply assigned endpoints to, or 0 for none.
+type importRetry struct {
+	CreatedAt time.Time
+	Rows      []model.ImportCandidate
+	GroupID   int64
+	GroupName string
+}
+
+// retainFailedImportRows stores the failed subset of rows under a new retry
+// ID and returns it, or returns "" when nothing failed.
+func (s *Server) retainFailedImportRows(rows []model.ImportCandidate, failedRowIDs []string, assignment *model.ImportGroupAssignmentResult) string {
+	failedRows := filterImportRows(rows, failedRowIDs, true)
+	if len(failedRows) == 0 {
+		return ""
+	}
+
+	retry := importRetry{
+		CreatedAt: time.Now().UTC(),
+		Rows:      failedRows,
+	}
+	if assignment != nil {
+		retry.GroupID = assignment.GroupID
+		retry.GroupName = assignment.GroupName
+	}
+
+	retryID := newPreviewID()
+	s.storeImportRetry(retryID, retry)
+	return retryID
+}
+
+// filterImportRows keeps (keep=true) or drops (keep=false) the rows whose
+// RowID is listed.
+func filterImportRows(rows []model.ImportCandidate, rowIDs []string, keep bool) []model.ImportCandidate {
+	listed := make(map[string]struct{}, len(rowIDs))
+	for _, rowID := range rowIDs {
+		listed[rowID] = struct{}{}
+	}
+	filtered := make([]model.ImportCandidate, 0, len(rows))
+	for _, row := range rows {
+		if _, ok := listed[row.RowID]; ok == keep {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+func (s *Server) handleInventoryImportRetry(w http.ResponseWriter, r *http.Request) {
+	if s.probe.IsRunning() {
+		util.WriteError(w, http.StatusConflict, "probing is running; stop probing before import retry")
+		return
+	}
+
+	var req model.ImportRetryRequest
+	if err := util.DecodeJSON(r, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	if req.RetryID == "" {
+		util.WriteError(w, http.StatusBadRequest, "retry_id is required")
+		return
+	}
+
+	// Take the entry out while retrying so two concurrent retries of the same
+	// ID cannot both apply the rows.
+	s.previewMu.Lock()
+	retry, ok := s.importRetries[req.RetryID]
+	delete(s.importRetries, req.RetryID)
+	if ok && s.previewExpired(retry.CreatedAt) {
+		s.expiredPreviews.add(req.RetryID, time.Now().UTC())
+		ok = false
+	}
+	s.previewMu.Unlock()
+	if !ok {
+		if s.expiredPreviews.has(req.RetryID) {
+			util.WriteError(w, http.StatusGone, "import retry expired")
+			return
+		}
+		util.WriteError(w, http.StatusNotFound, "import retry not found")
+		return
+	}
+
+	// The inventory may have changed since the rows were classified: an add
+	// can now collide with an endpoint created in the meantime, or an update
+	// target can be gone. Classify them again so they apply as what they are
+	// now; rows that became unchanged need no write.
+	existing, err := s.store.InventoryByIP(r.Context())
+	if err != nil {
+		s.storeImportRetry(req.RetryID, retry)
+		util.WriteError(w, http.StatusInternalServerError, fmt.Sprintf("inventory lookup failed: %v", err))
+		return
+	}
+	retry.Rows = importer.Classify(retry.Rows, existing)
+	rowsToApply := make([]model.ImportCandidate, 0, len(retry.Rows))
+	for _, row := range retry.Rows {
+		if row.Action == model.ImportAdd || row.Action == model.ImportUpdate {
+			rowsToApply = append(rowsToApply, row)
+		}
+	}
+
+	added, updated, applyErrors, failedRowIDs := s.store.ApplyImport(r.Context(), rowsToApply)
+
+	var assignmentResult *model.ImportGroupAssignmentResult
+	if retry.GroupID > 0 {
+		succeeded := filterImportRows(retry.Rows, failedRowIDs, false)
+		ips := make([]string, 0, len(succeeded))
+		for _, row := range succeeded {
+			ips = append(ips, row.IP)
+		}
+		ips = uniqueStrings(ips)
+
+		resolvedEndpointIDs, err := s.store.ResolveEndpointIDsByIPs(r.Context(), ips)
+		if err != nil {
+			s.restoreImportRetry(req.RetryID, retry, failedRowIDs)
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		assignedAdded, err := s.store.AddEndpointsToGroup(r.Context(), retry.GroupID, resolvedEndpointIDs)
+		if err != nil {
+			s.restoreImportRetry(req.RetryID, retry, failedRowIDs)
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		unresolved := len(ips) - len(resolvedEndpointIDs)
+		if unresolved < 0 {
+			unresolved = 0
+		}
+		assignmentResult = &model.ImportGroupAssignmentResult{
+			Applied:           true,
+			GroupID:           retry.GroupID,
+			GroupName:         retry.GroupName,
+			ValidUploadIPs:    len(ips),
+			ResolvedEndpoints: len(resolvedEndpointIDs),
+			AssignedAdded:     int(assignedAdded),
+			UnresolvedIPs:     unresolved,
+		}
+	}
+
+	retryID := ""
+	if len(failedRowIDs) > 0 {
+		retryID = req.RetryID
+		s.restoreImportRetry(retryID, retry, failedRowIDs)
+	}
+
+	util.WriteJSON(w, http.StatusOK, model.ImportApplyResponse{
+		Added:           added,
+		Updated:         updated,
+		Errors:          applyErrors,
+		GroupAssignment: assignmentResult,
+		RetryID:         retryID,
+		FailedRowIDs:    failedRowIDs,
+	})
+}
+
+// restoreImportRetry puts the still-failing rows back under the same retry ID
+// so the client can keep retrying with the token it already has.
+func (s *Server) restoreImportRetry(retryID string, retry importRetry, failedRowIDs []string) {
+	retry.Rows = filterImportRows(retry.Rows, failedRowIDs, true)
+	if len(retry.Rows) == 0 {
+		return
+	}
+	s.storeImportRetry(retryID, retry)
+}
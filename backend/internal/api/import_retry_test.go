@@ -0,0 +1,190 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/probe"
+)
+
+func TestImportRetryReappliesOnlyFailedRows(t *testing.T) {
+	st := newMemoryStore()
+	st.applyFail = map[string]int{"row-2": 2}
+	srv := newTestServer(st)
+	srv.probe = probe.NewEngine(nil, nil, probe.Options{}, model.Settings{})
+	srv.previews["preview-1"] = model.ImportPreview{
+		PreviewID: "preview-1",
+		CreatedAt: time.Now().UTC(),
+		Candidates: []model.ImportCandidate{
+			{RowID: "row-1", IP: "10.0.0.1", Action: model.ImportAdd},
+			{RowID: "row-2", IP: "10.0.0.2", Action: model.ImportAdd},
+			{RowID: "row-3", IP: "10.0.0.3", Action: model.ImportUnchanged},
+		},
+	}
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/import-apply", map[string]any{"preview_id": "preview-1"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var applied model.ImportApplyResponse
+	decodeTestResponse(t, rec, &applied)
+	if applied.Added != 1 || applied.RetryID == "" || len(applied.FailedRowIDs) != 1 || applied.FailedRowIDs[0] != "row-2" {
+		t.Fatalf("unexpected apply response: %+v", applied)
+	}
+
+	rec = serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/import-retry", map[string]any{"retry_id": applied.RetryID})
+	var retried model.ImportApplyResponse
+	decodeTestResponse(t, rec, &retried)
+	if retried.Added != 0 || retried.RetryID != applied.RetryID || len(retried.FailedRowIDs) != 1 {
+		t.Fatalf("expected still-failing row to keep its retry id, got %+v", retried)
+	}
+
+	rec = serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/import-retry", map[string]any{"retry_id": applied.RetryID})
+	retried = model.ImportApplyResponse{}
+	decodeTestResponse(t, rec, &retried)
+	if retried.Added != 1 || retried.RetryID != "" || len(retried.FailedRowIDs) != 0 {
+		t.Fatalf("expected retry to succeed and clear retry id, got %+v", retried)
+	}
+	if len(st.applied) != 2 || st.applied[0] != "row-1" || st.applied[1] != "row-2" {
+		t.Fatalf("expected each row applied once, got %v", st.applied)
+	}
+
+	rec = serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/import-retry", map[string]any{"retry_id": applied.RetryID})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected finished retry to be gone, got %d", rec.Code)
+	}
+}
+
+func TestImportRetryReclassifiesAgainstCurrentInventory(t *testing.T) {
+	st := newMemoryStore()
+	srv := newTestServer(st)
+	srv.probe = probe.NewEngine(nil, nil, probe.Options{}, model.Settings{})
+	srv.importRetries["retry-1"] = importRetry{
+		CreatedAt: time.Now().UTC(),
+		Rows: []model.ImportCandidate{
+			{RowID: "row-1", IP: "10.0.0.1", VLAN: "20", Action: model.ImportAdd},
+			{RowID: "row-2", IP: "10.0.0.2", Action: model.ImportAdd},
+			{RowID: "row-3", IP: "10.0.0.3", Action: model.ImportAdd},
+		},
+	}
+	// 10.0.0.1 and 10.0.0.2 were created by someone else after the failed apply.
+	st.endpoints = []model.InventoryEndpointView{
+		{EndpointID: 1, IPAddress: "10.0.0.1"},
+		{EndpointID: 2, IPAddress: "10.0.0.2"},
+	}
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/import-retry", map[string]any{"retry_id": "retry-1"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var retried model.ImportApplyResponse
+	decodeTestResponse(t, rec, &retried)
+	if retried.Added != 1 || retried.Updated != 1 || len(retried.FailedRowIDs) != 0 || retried.RetryID != "" {
+		t.Fatalf("unexpected retry response: %+v", retried)
+	}
+	if len(st.applied) != 2 || st.applied[0] != "row-1" || st.applied[1] != "row-3" {
+		t.Fatalf("expected the changed row as an update and the new row as an add, got %v", st.applied)
+	}
+}
+
+func newImportGroupAssignmentTestServer() (*Server, *memoryStore) {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{
+		{EndpointID: 1, IPAddress: "10.0.0.1"},
+		{EndpointID: 2, IPAddress: "10.0.0.2"},
+	}
+	st.namedGroup = []model.Group{{ID: 1, Name: "no group", IsSystem: true}}
+	srv := newTestServer(st)
+	srv.probe = probe.NewEngine(nil, nil, probe.Options{}, model.Settings{})
+	srv.previews["preview-1"] = model.ImportPreview{
+		PreviewID: "preview-1",
+		CreatedAt: time.Now().UTC(),
+		Candidates: []model.ImportCandidate{
+			{RowID: "row-1", IP: "10.0.0.1", Action: model.ImportAdd},
+			{RowID: "row-2", IP: "10.0.0.2", Action: model.ImportUnchanged},
+			{RowID: "row-3", IP: "10.0.0.3", Action: model.ImportAdd},
+			{RowID: "row-4", IP: "bad", Action: model.ImportInvalid},
+		},
+	}
+	return srv, st
+}
+
+func TestImportApplyCreatesAndAssignsGroup(t *testing.T) {
+	srv, st := newImportGroupAssignmentTestServer()
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/import-apply", map[string]any{
+		"preview_id":       "preview-1",
+		"group_assignment": map[string]any{"mode": "create", "group_name": "Edge"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var applied model.ImportApplyResponse
+	decodeTestResponse(t, rec, &applied)
+	result := applied.GroupAssignment
+	if result == nil || !result.Applied || result.GroupName != "Edge" || result.UsedExistingByName {
+		t.Fatalf("unexpected group assignment: %+v", result)
+	}
+	if result.ValidUploadIPs != 3 || result.ResolvedEndpoints != 2 || result.UnresolvedIPs != 1 || result.AssignedAdded != 2 {
+		t.Fatalf("unexpected assignment counts: %+v", result)
+	}
+	if got := st.groupScope[result.GroupID]; len(got) != 2 {
+		t.Fatalf("group members = %v, want both resolved endpoints", got)
+	}
+}
+
+func TestImportApplyRejectsNoGroupAssignment(t *testing.T) {
+	for name, assignment := range map[string]map[string]any{
+		"reserved name": {"mode": "create", "group_name": "No Group"},
+		"system group":  {"mode": "existing", "group_id": 1},
+	} {
+		srv, st := newImportGroupAssignmentTestServer()
+		rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/import-apply", map[string]any{
+			"preview_id":       "preview-1",
+			"group_assignment": assignment,
+		})
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", name, rec.Code, rec.Body.String())
+		}
+		if len(st.applied) != 0 {
+			t.Fatalf("%s: rows were applied before the assignment was rejected: %v", name, st.applied)
+		}
+	}
+}
+
+func TestImportApplyDryRunKeepsPreviewAndWritesNothing(t *testing.T) {
+	srv, st := newImportGroupAssignmentTestServer()
+	st.applyFail = map[string]int{"row-3": 1}
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/import-apply?dry_run=true", map[string]any{
+		"preview_id":       "preview-1",
+		"group_assignment": map[string]any{"mode": "create", "group_name": "Edge"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var projected model.ImportApplyResponse
+	decodeTestResponse(t, rec, &projected)
+	if !projected.DryRun || projected.Added != 1 || len(projected.Errors) != 1 || projected.RetryID != "" {
+		t.Fatalf("unexpected dry run response: %+v", projected)
+	}
+	if projected.GroupAssignment == nil || projected.GroupAssignment.Applied || projected.GroupAssignment.ValidUploadIPs != 3 {
+		t.Fatalf("unexpected dry run group assignment: %+v", projected.GroupAssignment)
+	}
+	if len(st.applied) != 0 || len(st.namedGroup) != 1 || len(st.groupScope) != 0 {
+		t.Fatalf("dry run wrote: applied %v, groups %v, members %v", st.applied, st.namedGroup, st.groupScope)
+	}
+	if _, ok := srv.previews["preview-1"]; !ok {
+		t.Fatalf("dry run should keep the preview for the real apply")
+	}
+
+	rec = serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/import-apply", map[string]any{"preview_id": "preview-1", "dry_run": true})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected body dry_run to be accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(st.applied) != 0 {
+		t.Fatalf("body dry_run wrote rows: %v", st.applied)
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+	"sonarscope/backend/internal/util"
+)
+
+const inventoryBulkCreateMaxRows = 1000
+
+// handleInventoryEndpointBulkCreate creates an array of endpoints in one
+// transaction. Rows that fail validation, name a missing group, or reuse an
+// IP are reported in results and skipped; the rest are created.
+func (s *Server) handleInventoryEndpointBulkCreate(w http.ResponseWriter, r *http.Request) {
+	var rows []model.InventoryEndpointCreate
+	if err := util.DecodeJSON(r, &rows); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	if len(rows) == 0 {
+		util.WriteError(w, http.StatusBadRequest, "at least one endpoint is required")
+		return
+	}
+	if len(rows) > inventoryBulkCreateMaxRows {
+		util.WriteError(w, http.StatusBadRequest, fmt.Sprintf("at most %d endpoints can be created per request", inventoryBulkCreateMaxRows))
+		return
+	}
+
+	results := make([]model.InventoryEndpointBulkCreateResult, len(rows))
+	groupErrs := map[int64]error{}
+	valid := make([]model.InventoryEndpointCreate, 0, len(rows))
+	validIndexes := make([]int, 0, len(rows))
+	for i := range rows {
+		results[i].Index = i
+		if err := normalizeInventoryEndpointCreate(&rows[i]); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if groupID := rows[i].GroupID; groupID != nil {
+			groupErr, checked := groupErrs[*groupID]
+			if !checked {
+				status, err := s.checkInventoryCreateGroup(r.Context(), *groupID)
+				if status == http.StatusInternalServerError {
+					util.WriteError(w, status, err.Error())
+					return
+				}
+				groupErr = err
+				groupErrs[*groupID] = err
+			}
+			if groupErr != nil {
+				results[i].Error = groupErr.Error()
+				continue
+			}
+		}
+		valid = append(valid, rows[i])
+		validIndexes = append(validIndexes, i)
+	}
+
+	createdIDs := []int64{}
+	if len(valid) > 0 {
+		ids, rowErrs, err := s.store.CreateInventoryEndpoints(r.Context(), valid)
+		if err != nil {
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for j, index := range validIndexes {
+			switch {
+			case errors.Is(rowErrs[j], store.ErrEndpointIPExists):
+				results[index].Error = "inventory endpoint with this IP already exists"
+			case rowErrs[j] != nil:
+				results[index].Error = rowErrs[j].Error()
+			default:
+				results[index].EndpointID = ids[j]
+				createdIDs = append(createdIDs, ids[j])
+			}
+		}
+	}
+
+	items, err := s.store.ListInventoryEndpointsByIDs(r.Context(), createdIDs, len(createdIDs))
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, model.InventoryEndpointBulkCreateResponse{
+		Items:        items,
+		Results:      results,
+		CreatedCount: len(createdIDs),
+		FailedCount:  len(rows) - len(createdIDs),
+	})
+}
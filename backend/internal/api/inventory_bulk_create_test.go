@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestHandleInventoryEndpointBulkCreateReportsEachRow(t *testing.T) {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{{EndpointID: 1, Hostname: "db-1", IPAddress: "10.0.0.1"}}
+	st.groupScope = map[int64][]int64{7: {1}}
+	srv := newTestServer(st)
+
+	missingGroup := int64(99)
+	existingGroup := int64(7)
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/endpoints/bulk", []model.InventoryEndpointCreate{
+		{IPAddress: " 10.0.0.2 ", Hostname: "web-1", GroupID: &existingGroup},
+		{IPAddress: "10.0.0.1"},
+		{IPAddress: "not-an-ip"},
+		{IPAddress: "10.0.0.3", GroupID: &missingGroup},
+		{IPAddress: "10.0.0.4"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp model.InventoryEndpointBulkCreateResponse
+	decodeTestResponse(t, rec, &resp)
+	if resp.CreatedCount != 2 || resp.FailedCount != 3 || len(resp.Items) != 2 || len(resp.Results) != 5 {
+		t.Fatalf("unexpected bulk response: %+v", resp)
+	}
+	wantErrors := []string{
+		"",
+		"inventory endpoint with this IP already exists",
+		"ip_address must be a valid IPv4 or IPv6 address",
+		"group not found",
+		"",
+	}
+	for i, want := range wantErrors {
+		result := resp.Results[i]
+		if result.Index != i || result.Error != want || (want == "") != (result.EndpointID != 0) {
+			t.Fatalf("result %d = %+v, want error %q", i, result, want)
+		}
+	}
+	if resp.Items[0].IPAddress != "10.0.0.2" || resp.Items[1].Hostname != "10.0.0.4" {
+		t.Fatalf("unexpected created items: %+v", resp.Items)
+	}
+}
+
+func TestHandleInventoryEndpointBulkCreateRejectsBadPayloads(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+
+	if rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/endpoints/bulk", []model.InventoryEndpointCreate{}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty array status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/endpoints/bulk", map[string]any{"ip_address": "10.0.0.1"}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("object payload status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	tooMany := make([]model.InventoryEndpointCreate, inventoryBulkCreateMaxRows+1)
+	if rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/endpoints/bulk", tooMany); rec.Code != http.StatusBadRequest {
+		t.Fatalf("oversized status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
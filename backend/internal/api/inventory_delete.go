@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/util"
+)
+
+const (
+	inventoryDeleteMaxIDs    = 50000
+	inventoryDeleteSyncLimit = 500
+)
+
+// handleInventoryDeleteByIDs deletes an explicit selection. Small selections
+// are deleted inline when no probe session is running; anything else goes
+// through the background delete job, which stops probing first.
+func (s *Server) handleInventoryDeleteByIDs(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		EndpointIDs []int64 `json:"endpoint_ids"`
+		Async       bool    `json:"async"`
+	}
+	var req request
+	if err := util.DecodeJSON(r, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	if len(req.EndpointIDs) == 0 {
+		util.WriteError(w, http.StatusBadRequest, "endpoint_ids is required")
+		return
+	}
+	if len(req.EndpointIDs) > inventoryDeleteMaxIDs {
+		util.WriteError(w, http.StatusBadRequest, "endpoint_ids exceeds the maximum of 50000")
+		return
+	}
+	if s.isDeleteJobRunning() {
+		util.WriteError(w, http.StatusConflict, "inventory deletion already in progress")
+		return
+	}
+
+	endpointIDs, err := s.store.ResolveExistingInventoryEndpointIDs(r.Context(), req.EndpointIDs)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	probing := s.probe != nil && s.probe.IsRunning()
+	if req.Async || probing || len(endpointIDs) > inventoryDeleteSyncLimit {
+		job, err := s.beginDeleteJob(model.InventoryDeleteJobModeMatch, nil, "Selected endpoints")
+		if err != nil {
+			util.WriteError(w, http.StatusConflict, err.Error())
+			return
+		}
+		go s.runDeleteJob(job, endpointIDs)
+		util.WriteJSON(w, http.StatusAccepted, map[string]any{
+			"requested": len(req.EndpointIDs),
+			"matched":   len(endpointIDs),
+			"async":     true,
+			"job":       s.deleteJobSnapshot(),
+		})
+		return
+	}
+
+	deleted := int64(0)
+	if len(endpointIDs) > 0 {
+		deleted, _, err = s.store.DeleteInventoryEndpointsByIDsWithProgress(context.WithoutCancel(r.Context()), endpointIDs, deleteJobBatchSize, deleteJobPingRowBatch, nil)
+		s.forgetAlerts(endpointIDs)
+		if err != nil {
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"requested": len(req.EndpointIDs),
+		"matched":   len(endpointIDs),
+		"deleted":   deleted,
+		"async":     false,
+	})
+}
+
+// handleInventoryDeleteJobCancel aborts the running delete job. The job stops
+// at its next batch boundary, resumes the maintenance jobs it paused, and
+// reports state canceled with the counts deleted so far.
+func (s *Server) handleInventoryDeleteJobCancel(w http.ResponseWriter, _ *http.Request) {
+	if !s.cancelDeleteJob() {
+		util.WriteError(w, http.StatusNotFound, "no inventory delete job is running")
+		return
+	}
+	util.WriteJSON(w, http.StatusAccepted, s.deleteJobSnapshot())
+}
+
+func (s *Server) cancelDeleteJob() bool {
+	s.deleteJobMu.Lock()
+	defer s.deleteJobMu.Unlock()
+
+	job := s.deleteJob
+	if job == nil || !job.Active || job.State != model.InventoryDeleteJobStateRunning || job.cancel == nil {
+		return false
+	}
+	job.Phase = "canceling"
+	job.cancel()
+	return true
+}
+
+// interruptDeleteJob finishes a job whose delete returned err, as canceled
+// when the operator aborted it and as failed otherwise.
+func (s *Server) interruptDeleteJob(ctx context.Context, jobID string, deletedEndpoints, deletedPingRows int64, err error) {
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		s.completeDeleteJob(jobID, model.InventoryDeleteJobStateFailed, err.Error())
+		return
+	}
+	s.updateDeleteJob(jobID, func(current *inventoryDeleteJobState) {
+		current.DeletedEndpoints = deletedEndpoints
+		current.DeletedPingRows = deletedPingRows
+		current.Phase = "canceled"
+	})
+	s.completeDeleteJob(jobID, model.InventoryDeleteJobStateCanceled, "")
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/probe"
+	"sonarscope/backend/internal/store"
+)
+
+func TestHandleInventoryDeleteByIDsDeletesMatchedEndpoints(t *testing.T) {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{{EndpointID: 1}, {EndpointID: 2}, {EndpointID: 3}}
+	srv := newTestServer(st)
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/delete", map[string]any{
+		"endpoint_ids": []int64{1, 3, 99},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp struct {
+		Requested int   `json:"requested"`
+		Matched   int   `json:"matched"`
+		Deleted   int64 `json:"deleted"`
+		Async     bool  `json:"async"`
+	}
+	decodeTestResponse(t, rec, &resp)
+	if resp.Requested != 3 || resp.Matched != 2 || resp.Deleted != 2 || resp.Async {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if len(st.endpoints) != 1 || st.endpoints[0].EndpointID != 2 {
+		t.Fatalf("remaining endpoints = %+v, want only endpoint 2", st.endpoints)
+	}
+}
+
+func TestHandleInventoryDeleteByIDsValidatesSelection(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+
+	tooMany := make([]int64, inventoryDeleteMaxIDs+1)
+	for i := range tooMany {
+		tooMany[i] = int64(i + 1)
+	}
+	for name, payload := range map[string]any{
+		"empty":    map[string]any{"endpoint_ids": []int64{}},
+		"too many": map[string]any{"endpoint_ids": tooMany},
+	} {
+		rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/delete", payload)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want %d", name, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestHandleInventoryDeleteAllRequiresConfirmPhrase(t *testing.T) {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{{EndpointID: 1}}
+	srv := newTestServer(st)
+
+	for _, path := range []string{"/api/inventory/delete-jobs/all", "/api/inventory/endpoints/delete-all"} {
+		for _, phrase := range []string{"", "DELETE ALL", "delete all endpoints"} {
+			rec := serveTestJSONRequest(t, srv, http.MethodPost, path, map[string]any{"confirm_phrase": phrase})
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("%s with %q: status = %d, want %d", path, phrase, rec.Code, http.StatusBadRequest)
+			}
+		}
+	}
+	if srv.deleteJobSnapshot().Active || len(st.endpoints) != 1 {
+		t.Fatalf("rejected confirm phrase must not start a delete job")
+	}
+}
+
+// blockingDeleteStore deletes the first endpoint of a job and then blocks
+// until the job context is canceled, or panics when panicMsg is set.
+type blockingDeleteStore struct {
+	*memoryStore
+	started  chan struct{}
+	panicMsg string
+
+	resumeMu sync.Mutex
+	resumed  []int64
+}
+
+func (b *blockingDeleteStore) PauseMaintenanceJobs(context.Context) ([]int64, error) {
+	return []int64{7}, nil
+}
+
+func (b *blockingDeleteStore) ResumeJobs(_ context.Context, jobIDs []int64) error {
+	b.resumeMu.Lock()
+	defer b.resumeMu.Unlock()
+	b.resumed = append(b.resumed, jobIDs...)
+	return nil
+}
+
+func (b *blockingDeleteStore) ResumePausedMaintenanceJobs(context.Context) ([]int64, error) {
+	return []int64{7}, nil
+}
+
+func (b *blockingDeleteStore) DeleteInventoryEndpointsByIDsWithProgress(ctx context.Context, endpointIDs []int64, _ int, _ int, onProgress func(store.InventoryDeleteProgress)) (int64, int64, error) {
+	onProgress(store.InventoryDeleteProgress{
+		Phase:              "deleting endpoints",
+		MatchedEndpoints:   int64(len(endpointIDs)),
+		ProcessedEndpoints: 1,
+		DeletedEndpoints:   1,
+		DeletedPingRows:    10,
+	})
+	close(b.started)
+	if b.panicMsg != "" {
+		panic(b.panicMsg)
+	}
+	<-ctx.Done()
+	return 1, 10, ctx.Err()
+}
+
+func TestHandleInventoryDeleteJobCancelKeepsPartialProgress(t *testing.T) {
+	st := &blockingDeleteStore{memoryStore: newMemoryStore(), started: make(chan struct{})}
+	st.endpoints = []model.InventoryEndpointView{{EndpointID: 1}, {EndpointID: 2}, {EndpointID: 3}}
+	srv := newTestServer(st)
+	srv.probe = probe.NewEngine(nil, nil, probe.Options{}, model.Settings{})
+
+	if rec := serveTestRequest(t, srv, http.MethodPost, "/api/inventory/delete-jobs/cancel"); rec.Code != http.StatusNotFound {
+		t.Fatalf("cancel without a job: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/inventory/delete", map[string]any{
+		"endpoint_ids": []int64{1, 2, 3},
+		"async":        true,
+	})
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+	<-st.started
+
+	if rec := serveTestRequest(t, srv, http.MethodPost, "/api/inventory/delete-jobs/cancel"); rec.Code != http.StatusAccepted {
+		t.Fatalf("cancel: status = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for srv.isDeleteJobRunning() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	job := srv.deleteJobSnapshot()
+	if job.Active || job.State != model.InventoryDeleteJobStateCanceled {
+		t.Fatalf("job = %+v, want inactive and canceled", job)
+	}
+	if job.DeletedEndpoints != 1 || job.DeletedPingRows != 10 || job.ProgressPct >= 100 || job.CompletedAt == nil {
+		t.Fatalf("job = %+v, want partial progress kept", job)
+	}
+
+	st.resumeMu.Lock()
+	defer st.resumeMu.Unlock()
+	if len(st.resumed) != 1 || st.resumed[0] != 7 {
+		t.Fatalf("resumed maintenance jobs = %v, want [7]", st.resumed)
+	}
+}
+
+func TestDeleteJobPanicResumesMaintenanceJobs(t *testing.T) {
+	st := &blockingDeleteStore{memoryStore: newMemoryStore(), started: make(chan struct{}), panicMsg: "connection lost"}
+	srv := newTestServer(st)
+	srv.probe = probe.NewEngine(nil, nil, probe.Options{}, model.Settings{})
+
+	job, err := srv.beginDeleteJob(model.InventoryDeleteJobModeMatch, nil, "")
+	if err != nil {
+		t.Fatalf("beginDeleteJob() error = %v", err)
+	}
+	srv.runDeleteJob(job, []int64{1, 2})
+
+	snapshot := srv.deleteJobSnapshot()
+	if snapshot.Active || snapshot.State != model.InventoryDeleteJobStateFailed || snapshot.Error != "delete job panicked: connection lost" {
+		t.Fatalf("job = %+v, want failed with the panic", snapshot)
+	}
+	if len(st.resumed) != 1 || st.resumed[0] != 7 {
+		t.Fatalf("resumed maintenance jobs = %v, want [7]", st.resumed)
+	}
+}
+
+func TestHandleAdminResumeMaintenance(t *testing.T) {
+	st := &blockingDeleteStore{memoryStore: newMemoryStore(), started: make(chan struct{})}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodPost, "/api/admin/resume-maintenance")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp struct {
+		ResumedJobIDs []int64 `json:"resumed_job_ids"`
+	}
+	decodeTestResponse(t, rec, &resp)
+	if len(resp.ResumedJobIDs) != 1 || resp.ResumedJobIDs[0] != 7 {
+		t.Fatalf("resumed_job_ids = %v, want [7]", resp.ResumedJobIDs)
+	}
+
+	if _, err := srv.beginDeleteJob(model.InventoryDeleteJobModeAll, nil, ""); err != nil {
+		t.Fatalf("beginDeleteJob() error = %v", err)
+	}
+	if rec := serveTestRequest(t, srv, http.MethodPost, "/api/admin/resume-maintenance"); rec.Code != http.StatusConflict {
+		t.Fatalf("status during delete = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
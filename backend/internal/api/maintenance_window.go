@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/maintenance"
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+	"sonarscope/backend/internal/util"
+)
+
+func (s *Server) handleListMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	items, err := s.store.ListMaintenanceWindows(r.Context())
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, items)
+}
+
+func (s *Server) handleGetMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	windowID, ok := parseMaintenanceWindowID(w, r)
+	if !ok {
+		return
+	}
+	item, err := s.store.GetMaintenanceWindow(r.Context(), windowID)
+	if err != nil {
+		writeMaintenanceWindowError(w, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, item)
+}
+
+func (s *Server) handleCreateMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	input, ok := s.decodeMaintenanceWindowInput(w, r)
+	if !ok {
+		return
+	}
+	item, err := s.store.CreateMaintenanceWindow(r.Context(), input)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusCreated, item)
+}
+
+func (s *Server) handleUpdateMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	windowID, ok := parseMaintenanceWindowID(w, r)
+	if !ok {
+		return
+	}
+	input, ok := s.decodeMaintenanceWindowInput(w, r)
+	if !ok {
+		return
+	}
+	item, err := s.store.UpdateMaintenanceWindow(r.Context(), windowID, input)
+	if err != nil {
+		writeMaintenanceWindowError(w, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, item)
+}
+
+func (s *Server) handleDeleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	windowID, ok := parseMaintenanceWindowID(w, r)
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteMaintenanceWindow(r.Context(), windowID); err != nil {
+		writeMaintenanceWindowError(w, err)
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"deleted": true})
+}
+
+func parseMaintenanceWindowID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	windowID, err := strconv.ParseInt(chi.URLParam(r, "windowID"), 10, 64)
+	if err != nil || windowID < 1 {
+		util.WriteError(w, http.StatusBadRequest, "invalid maintenance window id")
+		return 0, false
+	}
+	return windowID, true
+}
+
+func writeMaintenanceWindowError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrMaintenanceWindowNotFound) {
+		util.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	util.WriteError(w, http.StatusInternalServerError, err.Error())
+}
+
+// decodeMaintenanceWindowInput reads and validates a create/update payload,
+// writing the error response itself when it returns false.
+func (s *Server) decodeMaintenanceWindowInput(w http.ResponseWriter, r *http.Request) (model.MaintenanceWindowInput, bool) {
+	var input model.MaintenanceWindowInput
+	if err := util.DecodeJSON(r, &input); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return model.MaintenanceWindowInput{}, false
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	input.Scope = strings.ToLower(strings.TrimSpace(input.Scope))
+	input.Recurrence = strings.Join(strings.Fields(input.Recurrence), " ")
+	input.Timezone = strings.TrimSpace(input.Timezone)
+	if input.Timezone == "" {
+		input.Timezone = "UTC"
+	}
+
+	status, err := s.validateMaintenanceWindowInput(r.Context(), &input)
+	if err != nil {
+		util.WriteError(w, status, err.Error())
+		return model.MaintenanceWindowInput{}, false
+	}
+	return input, true
+}
+
+func (s *Server) validateMaintenanceWindowInput(ctx context.Context, input *model.MaintenanceWindowInput) (int, error) {
+	if input.StartsAt.IsZero() || input.EndsAt.IsZero() {
+		return http.StatusBadRequest, errors.New("starts_at and ends_at are required")
+	}
+	if _, err := maintenance.NewWindow(input.StartsAt, input.EndsAt, input.Recurrence, input.Timezone); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	switch input.Scope {
+	case model.MaintenanceScopeAll:
+		input.EndpointID = nil
+		input.GroupID = nil
+	case model.MaintenanceScopeEndpoint:
+		if input.EndpointID == nil || *input.EndpointID < 1 {
+			return http.StatusBadRequest, errors.New("endpoint_id is required for endpoint scope")
+		}
+		input.GroupID = nil
+		existing, err := s.store.ResolveExistingInventoryEndpointIDs(ctx, []int64{*input.EndpointID})
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if len(existing) == 0 {
+			return http.StatusBadRequest, errors.New("endpoint not found")
+		}
+	case model.MaintenanceScopeGroup:
+		if input.GroupID == nil || *input.GroupID < 1 {
+			return http.StatusBadRequest, errors.New("group_id is required for group scope")
+		}
+		input.EndpointID = nil
+		if _, err := s.store.GetGroupByID(ctx, *input.GroupID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return http.StatusBadRequest, errors.New("group not found")
+			}
+			return http.StatusInternalServerError, err
+		}
+	default:
+		return http.StatusBadRequest, errors.New("scope must be all, group, or endpoint")
+	}
+	return http.StatusOK, nil
+}
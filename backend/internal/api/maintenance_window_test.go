@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestMaintenanceWindowCRUD(t *testing.T) {
+	st := newMemoryStore()
+	st.groupScope = map[int64][]int64{4: {1, 2}}
+	srv := newTestServer(st)
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/maintenance-windows", map[string]any{
+		"name":        "nightly reboot",
+		"scope":       "Group",
+		"group_id":    4,
+		"endpoint_id": 9,
+		"starts_at":   "2026-03-01T02:00:00Z",
+		"ends_at":     "2026-03-01T02:30:00Z",
+		"recurrence":  " 0  2 * * * ",
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	var created model.MaintenanceWindow
+	decodeTestResponse(t, rec, &created)
+	if created.Scope != model.MaintenanceScopeGroup || created.EndpointID != nil || created.Recurrence != "0 2 * * *" || created.Timezone != "UTC" {
+		t.Fatalf("unexpected created window: %+v", created)
+	}
+
+	rec = serveTestJSONRequest(t, srv, http.MethodPut, "/api/maintenance-windows/1", map[string]any{
+		"scope":     "all",
+		"starts_at": "2026-03-02T00:00:00Z",
+		"ends_at":   "2026-03-02T04:00:00Z",
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/maintenance-windows/1")
+	var fetched model.MaintenanceWindow
+	decodeTestResponse(t, rec, &fetched)
+	if fetched.Scope != model.MaintenanceScopeAll || fetched.GroupID != nil {
+		t.Fatalf("unexpected updated window: %+v", fetched)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodDelete, "/api/maintenance-windows/1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/maintenance-windows/1")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("get deleted status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestMaintenanceWindowValidation(t *testing.T) {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{{EndpointID: 1}}
+	srv := newTestServer(st)
+
+	tests := []struct {
+		name    string
+		payload map[string]any
+	}{
+		{name: "unknown scope", payload: map[string]any{"scope": "site", "starts_at": "2026-03-01T02:00:00Z", "ends_at": "2026-03-01T03:00:00Z"}},
+		{name: "missing times", payload: map[string]any{"scope": "all"}},
+		{name: "inverted window", payload: map[string]any{"scope": "all", "starts_at": "2026-03-01T03:00:00Z", "ends_at": "2026-03-01T02:00:00Z"}},
+		{name: "bad cron", payload: map[string]any{"scope": "all", "starts_at": "2026-03-01T02:00:00Z", "ends_at": "2026-03-01T03:00:00Z", "recurrence": "0 2 * *"}},
+		{name: "missing endpoint", payload: map[string]any{"scope": "endpoint", "starts_at": "2026-03-01T02:00:00Z", "ends_at": "2026-03-01T03:00:00Z"}},
+		{name: "unknown endpoint", payload: map[string]any{"scope": "endpoint", "endpoint_id": 7, "starts_at": "2026-03-01T02:00:00Z", "ends_at": "2026-03-01T03:00:00Z"}},
+		{name: "unknown group", payload: map[string]any{"scope": "group", "group_id": 7, "starts_at": "2026-03-01T02:00:00Z", "ends_at": "2026-03-01T03:00:00Z"}},
+	}
+	for _, tc := range tests {
+		rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/maintenance-windows", tc.payload)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want %d: %s", tc.name, rec.Code, http.StatusBadRequest, rec.Body.String())
+		}
+	}
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPut, "/api/maintenance-windows/5", map[string]any{
+		"scope": "endpoint", "endpoint_id": 1, "starts_at": "2026-03-01T02:00:00Z", "ends_at": "2026-03-01T03:00:00Z",
+	})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("update missing status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestMonitorExcludeMaintenanceRequiresRangeScope(t *testing.T) {
+	st := newMemoryStore()
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints-page?exclude_maintenance=true")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("live status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints-page?stats_scope=range&start=2026-03-01T00:00:00Z&end=2026-03-02T00:00:00Z&exclude_maintenance=true")
+	if rec.Code != http.StatusOK || !st.lastQuery.ExcludeMaintenance {
+		t.Fatalf("range status = %d, exclude = %v: %s", rec.Code, st.lastQuery.ExcludeMaintenance, rec.Body.String())
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/monitor/sla?endpoint_ids=1&exclude_maintenance=true")
+	if rec.Code != http.StatusOK || !st.slaExclude {
+		t.Fatalf("sla status = %d, exclude = %v", rec.Code, st.slaExclude)
+	}
+}
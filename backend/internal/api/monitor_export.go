@@ -0,0 +1,207 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/util"
+)
+
+// handleMonitorExportCSV streams every monitor row matching the grid's
+// filters, sort, and stats scope as CSV. Rows are written as the store yields
+// them and flushed every statsExportFlushEvery rows, so large exports are not
+// held in memory.
+func (s *Server) handleMonitorExportCSV(w http.ResponseWriter, r *http.Request) {
+	query, parseErr := s.monitorPageQueryFromRequest(r, monitorRequestOptions{includeSort: true})
+	if parseErr != nil {
+		util.WriteError(w, parseErr.Status, parseErr.Message)
+		return
+	}
+	redact, err := parseBoolQuery(r, "redact")
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	settings, err := s.store.GetSettings(r.Context())
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	customFields := make([]model.CustomFieldConfig, 0, len(settings.CustomFields))
+	for _, field := range normalizeCustomFieldConfigs(settings.CustomFields) {
+		if field.Enabled && strings.TrimSpace(field.Name) != "" {
+			customFields = append(customFields, field)
+		}
+	}
+	var redactor *exportRedactor
+	if redact {
+		redactor = newExportRedactor()
+	}
+
+	flusher, _ := w.(http.Flusher)
+	csvWriter := csv.NewWriter(w)
+	written := 0
+	started := false
+	start := func() error {
+		started = true
+		filename := fmt.Sprintf("monitor-export-%s.csv", time.Now().UTC().Format("20060102-150405"))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.WriteHeader(http.StatusOK)
+		return csvWriter.Write(monitorExportHeader(customFields))
+	}
+
+	err = s.store.StreamMonitorEndpoints(r.Context(), query, func(item model.MonitorEndpoint) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if redactor != nil {
+			item.Hostname = redactor.Hostname(item.Hostname)
+			item.IPAddress = redactor.IP(item.IPAddress)
+			item.Switch = redactor.Hostname(item.Switch)
+			item.Gateway = redactor.IP(item.Gateway)
+			item.MgmtIP = redactor.IP(item.MgmtIP)
+			if item.ReplyIPAddress != nil {
+				replyIP := redactor.IP(*item.ReplyIPAddress)
+				item.ReplyIPAddress = &replyIP
+			}
+		}
+		if err := csvWriter.Write(monitorExportRecord(item, customFields)); err != nil {
+			return err
+		}
+		written++
+		if written%statsExportFlushEvery == 0 {
+			csvWriter.Flush()
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			if err.Error() == "invalid sort_by" {
+				util.WriteError(w, http.StatusBadRequest, "invalid sort_by")
+				return
+			}
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		csvWriter.Flush()
+		slog.Warn("monitor export aborted", "rows", written, "error", err)
+		return
+	}
+	if !started {
+		if err := start(); err != nil {
+			slog.Warn("monitor export write header", "error", err)
+			return
+		}
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		slog.Warn("monitor export flush", "error", err)
+	}
+}
+
+func monitorExportHeader(customFields []model.CustomFieldConfig) []string {
+	header := []string{
+		"Hostname",
+		"IP Address",
+		"MAC",
+		"Reply IP",
+		"Last Ping Status",
+		"Last Ping Latency (ms)",
+		"Average Latency (ms)",
+		"P95 Latency (ms)",
+		"P99 Latency (ms)",
+		"Success Count",
+		"Failed Count",
+		"Failed %",
+		"Consecutive Failed",
+		"Max Consecutive Failed",
+		"Max Consecutive Failed At",
+		"Total Sent",
+		"Last Success On",
+		"Last Failed On",
+		"VLAN",
+		"Zone",
+		"Switch",
+		"Port",
+		"Port Type",
+		"Gateway",
+		"Mgmt IP",
+		"Speed",
+		"Duplex",
+		"Group",
+	}
+	for _, field := range customFields {
+		header = append(header, field.Name)
+	}
+	return header
+}
+
+func monitorExportRecord(item model.MonitorEndpoint, customFields []model.CustomFieldConfig) []string {
+	record := []string{
+		item.Hostname,
+		item.IPAddress,
+		item.MACAddress,
+		optionalExportString(item.ReplyIPAddress),
+		item.LastPingStatus,
+		optionalExportFloat(item.LastPingLatency),
+		optionalExportFloat(item.AverageLatency),
+		optionalExportFloat(item.P95LatencyMs),
+		optionalExportFloat(item.P99LatencyMs),
+		strconv.FormatInt(item.SuccessCount, 10),
+		strconv.FormatInt(item.FailedCount, 10),
+		strconv.FormatFloat(item.FailedPct, 'f', 2, 64),
+		strconv.FormatInt(item.ConsecutiveFailedCount, 10),
+		strconv.FormatInt(item.MaxConsecutiveFailed, 10),
+		optionalExportTime(item.MaxConsecutiveFailedAt),
+		strconv.FormatInt(item.TotalSentPing, 10),
+		optionalExportTime(item.LastSuccessOn),
+		optionalExportTime(item.LastFailedOn),
+		item.VLAN,
+		item.Zone,
+		item.Switch,
+		item.Port,
+		item.PortType,
+		item.Gateway,
+		item.MgmtIP,
+		item.Speed,
+		item.Duplex,
+		strings.Join(item.Groups, ", "),
+	}
+	for _, field := range customFields {
+		record = append(record, model.MonitorEndpointCustomFieldValue(item, field.Slot))
+	}
+	return record
+}
+
+func optionalExportString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func optionalExportFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', 2, 64)
+}
+
+func optionalExportTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
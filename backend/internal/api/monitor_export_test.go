@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestHandleMonitorExportCSVStreamsAllRows(t *testing.T) {
+	p95 := 12.5
+	st := newMemoryStore()
+	st.settings.CustomFields = []model.CustomFieldConfig{{Slot: 2, Enabled: true, Name: "Rack"}}
+	st.pages = []model.MonitorEndpoint{
+		{EndpointID: 1, Hostname: "edge-1", IPAddress: "10.0.0.1", LastPingStatus: "Range Aggregate", SuccessCount: 9, FailedCount: 1, FailedPct: 10, P95LatencyMs: &p95, CustomField2Value: "r1", Groups: []string{"core", "dc1"}},
+		{EndpointID: 2, Hostname: "edge-2", IPAddress: "10.0.0.2", LastPingStatus: "No Data"},
+	}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/export?stats_scope=range&start=2026-02-08-00-00-00&end=2026-02-08-01-00-00&sort_by=p95_latency&sort_dir=desc&vlan=10")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Fatalf("content type = %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, "attachment; filename=") {
+		t.Fatalf("content disposition = %q", got)
+	}
+	if st.lastQuery.StatsScope != "range" || st.lastQuery.SortBy != "p95_latency" || len(st.lastQuery.Filters.VLANs) != 1 {
+		t.Fatalf("unexpected query forwarded to store: %+v", st.lastQuery)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d", len(records))
+	}
+	header := records[0]
+	if header[len(header)-1] != "Rack" {
+		t.Fatalf("expected enabled custom field column last, got %v", header)
+	}
+	row := map[string]string{}
+	for i, name := range header {
+		row[name] = records[1][i]
+	}
+	if row["Hostname"] != "edge-1" || row["P95 Latency (ms)"] != "12.50" || row["Failed %"] != "10.00" || row["Group"] != "core, dc1" || row["Rack"] != "r1" {
+		t.Fatalf("unexpected first row: %v", row)
+	}
+}
+
+func TestHandleMonitorExportCSVWritesHeaderWithoutRows(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/export")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	if err != nil || len(records) != 1 || records[0][0] != "Hostname" {
+		t.Fatalf("expected header-only csv, got %v (err=%v)", records, err)
+	}
+}
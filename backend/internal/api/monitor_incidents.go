@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+	"sonarscope/backend/internal/util"
+)
+
+const (
+	incidentListDefaultLimit = 1000
+	incidentListMaxLimit     = 10000
+	incidentCauseMaxLength   = 200
+	incidentNoteMaxLength    = 4000
+)
+
+func (s *Server) handleMonitorIncidents(w http.ResponseWriter, r *http.Request) {
+	endpointIDs := parseInt64CSVQuery(r, "endpoint_ids")
+	end := parseTimeQuery(r, "end", time.Now().UTC())
+	start := parseTimeQuery(r, "start", end.Add(-24*time.Hour))
+	if !start.Before(end) {
+		util.WriteError(w, http.StatusBadRequest, "start must be before end")
+		return
+	}
+	limit, err := parsePositiveIntQuery(r, "limit", incidentListDefaultLimit)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit > incidentListMaxLimit {
+		limit = incidentListMaxLimit
+	}
+
+	incidents, err := s.store.ListDowntimeIncidents(r.Context(), endpointIDs, start, end, limit)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, incidents)
+}
+
+// handleAnnotateMonitorIncident sets the cause, note, and severity operators
+// record on an incident for post-incident reports.
+func (s *Server) handleAnnotateMonitorIncident(w http.ResponseWriter, r *http.Request) {
+	incidentID, err := strconv.ParseInt(chi.URLParam(r, "incidentID"), 10, 64)
+	if err != nil || incidentID < 1 {
+		util.WriteError(w, http.StatusBadRequest, "invalid incident id")
+		return
+	}
+
+	var annotation model.DowntimeIncidentAnnotation
+	if err := util.DecodeJSON(r, &annotation); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	if annotation.Cause == nil && annotation.Note == nil && annotation.Severity == nil {
+		util.WriteError(w, http.StatusBadRequest, "cause, note, or severity is required")
+		return
+	}
+	if annotation.Cause != nil {
+		cause := strings.TrimSpace(*annotation.Cause)
+		if len(cause) > incidentCauseMaxLength {
+			util.WriteError(w, http.StatusBadRequest, "cause must be at most 200 characters")
+			return
+		}
+		annotation.Cause = &cause
+	}
+	if annotation.Note != nil {
+		note := strings.TrimSpace(*annotation.Note)
+		if len(note) > incidentNoteMaxLength {
+			util.WriteError(w, http.StatusBadRequest, "note must be at most 4000 characters")
+			return
+		}
+		annotation.Note = &note
+	}
+	if annotation.Severity != nil {
+		severity := strings.ToLower(strings.TrimSpace(*annotation.Severity))
+		switch severity {
+		case "", model.IncidentSeverityMinor, model.IncidentSeverityMajor, model.IncidentSeverityCritical:
+		default:
+			util.WriteError(w, http.StatusBadRequest, "severity must be minor, major, critical, or empty")
+			return
+		}
+		annotation.Severity = &severity
+	}
+
+	incident, err := s.store.AnnotateDowntimeIncident(r.Context(), incidentID, annotation)
+	if err != nil {
+		if errors.Is(err, store.ErrDowntimeIncidentNotFound) {
+			util.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, incident)
+}
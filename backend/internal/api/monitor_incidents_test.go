@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestHandleMonitorIncidentsFiltersWindow(t *testing.T) {
+	started := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	ended := started.Add(5 * time.Minute)
+	duration := ended.Sub(started).Seconds()
+	st := newMemoryStore()
+	st.incidents = []model.DowntimeIncident{
+		{ID: 1, EndpointID: 7, StartedAt: started, EndedAt: &ended, DurationSec: &duration},
+		{ID: 2, EndpointID: 7, StartedAt: started.Add(2 * time.Hour), Ongoing: true},
+	}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/incidents?endpoint_ids=7,9&start=2026-03-01T09:00:00Z&end=2026-03-01T11:00:00Z")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var items []model.DowntimeIncident
+	decodeTestResponse(t, rec, &items)
+	if len(items) != 1 || items[0].ID != 1 || items[0].DurationSec == nil || *items[0].DurationSec != 300 {
+		t.Fatalf("unexpected incidents: %+v", items)
+	}
+	if len(st.lastIDs) != 2 || st.lastIDs[0] != 7 || st.lastIDs[1] != 9 {
+		t.Fatalf("endpoint ids = %v, want [7 9]", st.lastIDs)
+	}
+}
+
+func TestHandleMonitorIncidentsRejectsInvertedWindow(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/incidents?start=2026-03-02T00:00:00Z&end=2026-03-01T00:00:00Z")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleAnnotateMonitorIncident(t *testing.T) {
+	started := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	ended := started.Add(5 * time.Minute)
+	st := newMemoryStore()
+	st.incidents = []model.DowntimeIncident{
+		{ID: 1, EndpointID: 7, StartedAt: started, EndedAt: &ended, Note: "keep"},
+	}
+	srv := newTestServer(st)
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPatch, "/api/monitor/incidents/1", map[string]any{"cause": " upstream fiber cut ", "severity": "Major"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var incident model.DowntimeIncident
+	decodeTestResponse(t, rec, &incident)
+	if incident.Cause != "upstream fiber cut" || incident.Severity != model.IncidentSeverityMajor || incident.Note != "keep" {
+		t.Fatalf("unexpected incident: %+v", incident)
+	}
+
+	for name, tc := range map[string]struct {
+		target  string
+		payload map[string]any
+		want    int
+	}{
+		"bad severity": {target: "/api/monitor/incidents/1", payload: map[string]any{"severity": "sev1"}, want: http.StatusBadRequest},
+		"empty patch":  {target: "/api/monitor/incidents/1", payload: map[string]any{}, want: http.StatusBadRequest},
+		"bad id":       {target: "/api/monitor/incidents/x", payload: map[string]any{"note": "n"}, want: http.StatusBadRequest},
+		"missing":      {target: "/api/monitor/incidents/99", payload: map[string]any{"note": "n"}, want: http.StatusNotFound},
+	} {
+		rec := serveTestJSONRequest(t, srv, http.MethodPatch, tc.target, tc.payload)
+		if rec.Code != tc.want {
+			t.Fatalf("%s: status = %d, want %d: %s", name, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+}
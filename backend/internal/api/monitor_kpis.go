@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/util"
+)
+
+const monitorKPICacheTTL = 2 * time.Second
+
+type monitorKPICacheEntry struct {
+	kpis      model.MonitorFleetKPIs
+	expiresAt time.Time
+}
+
+type monitorKPICache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]monitorKPICacheEntry
+}
+
+func newMonitorKPICache(ttl time.Duration) *monitorKPICache {
+	return &monitorKPICache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]monitorKPICacheEntry{},
+	}
+}
+
+func (c *monitorKPICache) get(key string) (model.MonitorFleetKPIs, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expiresAt) {
+		return model.MonitorFleetKPIs{}, false
+	}
+	return entry.kpis, true
+}
+
+func (c *monitorKPICache) put(key string, kpis model.MonitorFleetKPIs) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for existingKey, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, existingKey)
+		}
+	}
+	c.entries[key] = monitorKPICacheEntry{kpis: kpis, expiresAt: now.Add(c.ttl)}
+}
+
+func (s *Server) handleMonitorKPIs(w http.ResponseWriter, r *http.Request) {
+	cacheKey := r.URL.Query().Encode()
+	if kpis, ok := s.kpiCache.get(cacheKey); ok {
+		util.WriteJSON(w, http.StatusOK, kpis)
+		return
+	}
+
+	query, parseErr := s.monitorPageQueryFromRequest(r, monitorRequestOptions{})
+	if parseErr != nil {
+		util.WriteError(w, parseErr.Status, parseErr.Message)
+		return
+	}
+
+	kpis, err := s.store.MonitorFleetKPIs(r.Context(), query)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.kpiCache.put(cacheKey, kpis)
+	util.WriteJSON(w, http.StatusOK, kpis)
+}
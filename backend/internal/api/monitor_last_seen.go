@@ -0,0 +1,60 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/util"
+)
+
+const (
+	lastSeenListDefaultLimit = 500
+	lastSeenListMaxLimit     = 5000
+)
+
+type lastSeenResponse struct {
+	Buckets     []model.LastSeenBucketCount `json:"buckets"`
+	Bucket      string                      `json:"bucket,omitempty"`
+	Items       []model.LastSeenEndpoint    `json:"items,omitempty"`
+	GeneratedAt time.Time                   `json:"generated_at"`
+}
+
+func (s *Server) handleMonitorLastSeen(w http.ResponseWriter, r *http.Request) {
+	query, parseErr := s.monitorPageQueryFromRequest(r, monitorRequestOptions{})
+	if parseErr != nil {
+		util.WriteError(w, parseErr.Status, parseErr.Message)
+		return
+	}
+	bucket := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("bucket")))
+	if bucket != "" && !model.IsLastSeenBucket(bucket) {
+		util.WriteError(w, http.StatusBadRequest, fmt.Sprintf("bucket must be one of %s", strings.Join(model.LastSeenBuckets, ", ")))
+		return
+	}
+	limit, err := parsePositiveIntQuery(r, "limit", lastSeenListDefaultLimit)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit > lastSeenListMaxLimit {
+		limit = lastSeenListMaxLimit
+	}
+
+	counts, err := s.store.LastSeenBucketCounts(r.Context(), query)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response := lastSeenResponse{Buckets: counts, Bucket: bucket, GeneratedAt: time.Now().UTC()}
+	if bucket != "" {
+		items, err := s.store.ListLastSeenBucket(r.Context(), query, bucket, limit)
+		if err != nil {
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		response.Items = items
+	}
+	util.WriteJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestHandleMonitorLastSeenCountsAndListsBucket(t *testing.T) {
+	st := newMemoryStore()
+	st.lastSeen = []model.LastSeenEndpoint{
+		{EndpointID: 7, Hostname: "old-printer", IPAddress: "10.0.0.7", LastPingStatus: "Request Timeout"},
+		{EndpointID: 9, Hostname: "lab-ap", IPAddress: "10.0.0.9", LastPingStatus: model.PingStatusNeverProbed},
+	}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/last-seen?vlan=30")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var counts lastSeenResponse
+	decodeTestResponse(t, rec, &counts)
+	if len(counts.Buckets) != len(model.LastSeenBuckets) || counts.Items != nil {
+		t.Fatalf("unexpected counts-only response: %+v", counts)
+	}
+	if len(st.lastQuery.Filters.VLANs) != 1 || st.lastQuery.Filters.VLANs[0] != "30" {
+		t.Fatalf("vlan filter = %v, want [30]", st.lastQuery.Filters.VLANs)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/monitor/last-seen?bucket=never&limit=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var listed lastSeenResponse
+	decodeTestResponse(t, rec, &listed)
+	if listed.Bucket != model.LastSeenBucketNever || len(listed.Items) != 1 || listed.Items[0].EndpointID != 7 {
+		t.Fatalf("unexpected bucket listing: %+v", listed)
+	}
+}
+
+func TestHandleMonitorLastSeenRejectsUnknownBucket(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/last-seen?bucket=2d")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
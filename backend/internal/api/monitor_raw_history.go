@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/util"
+)
+
+const (
+	rawPingHistoryDefaultLimit = 1000
+	rawPingHistoryMaxLimit     = 10000
+)
+
+// handleMonitorEndpointRawHistory returns individual ping_raw rows for one
+// endpoint, for spikes that the 1m/1h aggregates average away.
+func (s *Server) handleMonitorEndpointRawHistory(w http.ResponseWriter, r *http.Request) {
+	endpointID, err := strconv.ParseInt(chi.URLParam(r, "endpointID"), 10, 64)
+	if err != nil || endpointID < 1 {
+		util.WriteError(w, http.StatusBadRequest, "invalid endpoint id")
+		return
+	}
+	end := parseTimeQuery(r, "end", time.Now().UTC())
+	start := parseTimeQuery(r, "start", end.Add(-time.Hour))
+	if !start.Before(end) {
+		util.WriteError(w, http.StatusBadRequest, "start must be before end")
+		return
+	}
+	limit, err := parsePositiveIntQuery(r, "limit", rawPingHistoryDefaultLimit)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit > rawPingHistoryMaxLimit {
+		limit = rawPingHistoryMaxLimit
+	}
+
+	if _, err := s.store.GetInventoryEndpointByID(r.Context(), endpointID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.WriteError(w, http.StatusNotFound, "endpoint not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// One extra row tells whether the window held more than limit probes.
+	items, err := s.store.ListEndpointRawPings(r.Context(), endpointID, start, end, limit+1)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	truncated := len(items) > limit
+	if truncated {
+		items = items[:limit]
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"endpoint_id": endpointID,
+		"start":       start,
+		"end":         end,
+		"limit":       limit,
+		"truncated":   truncated,
+		"items":       items,
+	})
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestHandleMonitorEndpointRawHistory(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	latency := 12.5
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{{EndpointID: 7}}
+	st.rawPings = []model.RawPingSample{
+		{Timestamp: at.Add(2 * time.Second), Success: true, LatencyMs: &latency},
+		{Timestamp: at.Add(time.Second), ErrorCode: "Request Timeout"},
+		{Timestamp: at, Success: true, LatencyMs: &latency},
+		{Timestamp: at.Add(-2 * time.Hour), Success: true},
+	}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints/7/raw?start=2026-03-01T09:30:00Z&end=2026-03-01T10:30:00Z&limit=2")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var body struct {
+		EndpointID int64                 `json:"endpoint_id"`
+		Limit      int                   `json:"limit"`
+		Truncated  bool                  `json:"truncated"`
+		Items      []model.RawPingSample `json:"items"`
+	}
+	decodeTestResponse(t, rec, &body)
+	if body.EndpointID != 7 || body.Limit != 2 || !body.Truncated || len(body.Items) != 2 {
+		t.Fatalf("unexpected response: %+v", body)
+	}
+	if body.Items[1].ErrorCode != "Request Timeout" || body.Items[0].LatencyMs == nil {
+		t.Fatalf("unexpected items: %+v", body.Items)
+	}
+}
+
+func TestHandleMonitorEndpointRawHistoryRejectsBadRequests(t *testing.T) {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{{EndpointID: 7}}
+	srv := newTestServer(st)
+
+	for _, path := range []string{
+		"/api/monitor/endpoints/x/raw",
+		"/api/monitor/endpoints/7/raw?limit=0",
+		"/api/monitor/endpoints/7/raw?start=2026-03-02T00:00:00Z&end=2026-03-01T00:00:00Z",
+	} {
+		if rec := serveTestRequest(t, srv, http.MethodGet, path); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want %d", path, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints/9/raw"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown endpoint status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/util"
+)
+
+type slaResponse struct {
+	Rollup string              `json:"rollup"`
+	Start  time.Time           `json:"start"`
+	End    time.Time           `json:"end"`
+	Items  []model.EndpointSLA `json:"items"`
+}
+
+func (s *Server) handleMonitorSLA(w http.ResponseWriter, r *http.Request) {
+	endpointIDs := parseInt64CSVQuery(r, "endpoint_ids")
+	end := parseTimeQuery(r, "end", time.Now().UTC())
+	start := parseTimeQuery(r, "start", end.Add(-24*time.Hour))
+	if !start.Before(end) {
+		util.WriteError(w, http.StatusBadRequest, "start must be before end")
+		return
+	}
+	excludeMaintenance, err := parseBoolQuery(r, "exclude_maintenance")
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, viewName, err := s.store.EndpointSLA(r.Context(), endpointIDs, start, end, excludeMaintenance)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, slaResponse{
+		Rollup: strings.TrimPrefix(viewName, "ping_"),
+		Start:  start,
+		End:    end,
+		Items:  items,
+	})
+}
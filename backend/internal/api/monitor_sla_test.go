@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHandleMonitorSLAReportsRollupForWindow(t *testing.T) {
+	st := newMemoryStore()
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/sla?endpoint_ids=3,4&start=2026-02-01T00:00:00Z&end=2026-03-01T00:00:00Z")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var response slaResponse
+	decodeTestResponse(t, rec, &response)
+	if response.Rollup != "1h" || len(response.Items) != 2 || response.Items[0].EndpointID != 3 {
+		t.Fatalf("unexpected sla response: %+v", response)
+	}
+	if len(st.lastIDs) != 2 {
+		t.Fatalf("endpoint ids = %v, want [3 4]", st.lastIDs)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/monitor/sla?endpoint_ids=3&start=2026-03-01T00:00:00Z&end=2026-02-01T00:00:00Z")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
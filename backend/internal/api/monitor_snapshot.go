@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+	"sonarscope/backend/internal/util"
+)
+
+const snapshotJanitorInterval = time.Hour
+
+// handleMonitorSnapshot captures the requested monitor page. It is a POST
+// because every call stores a row; reopening one is the GET below.
+func (s *Server) handleMonitorSnapshot(w http.ResponseWriter, r *http.Request) {
+	query, parseErr := s.monitorPageQueryFromRequest(r, monitorRequestOptions{
+		includePagination: true,
+		includeSort:       true,
+	})
+	if parseErr != nil {
+		util.WriteError(w, parseErr.Status, parseErr.Message)
+		return
+	}
+
+	items, totalItems, err := s.store.ListMonitorEndpointsPage(r.Context(), query)
+	if err != nil {
+		if err.Error() == "invalid sort_by" {
+			util.WriteError(w, http.StatusBadRequest, "invalid sort_by")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	snapshot := buildMonitorSnapshot(newPreviewID(), time.Now().UTC(), r.URL.Query(), query, items, totalItems)
+	if err := s.store.SaveMonitorSnapshot(r.Context(), snapshot); err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, snapshot)
+}
+
+func (s *Server) handleMonitorSnapshotGet(w http.ResponseWriter, r *http.Request) {
+	snapshotID := strings.TrimSpace(chi.URLParam(r, "snapshotID"))
+	if snapshotID == "" {
+		util.WriteError(w, http.StatusBadRequest, "snapshot id is required")
+		return
+	}
+
+	snapshot, err := s.store.GetMonitorSnapshot(r.Context(), snapshotID)
+	if err != nil {
+		if errors.Is(err, store.ErrMonitorSnapshotNotFound) {
+			util.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, snapshot)
+}
+
+func buildMonitorSnapshot(
+	snapshotID string,
+	capturedAt time.Time,
+	rawQuery map[string][]string,
+	query store.MonitorPageQuery,
+	items []model.MonitorEndpoint,
+	totalItems int64,
+) model.MonitorSnapshot {
+	queryParams := make(map[string][]string, len(rawQuery))
+	for key, values := range rawQuery {
+		queryParams[key] = append([]string{}, values...)
+	}
+	if items == nil {
+		items = []model.MonitorEndpoint{}
+	}
+
+	snapshot := model.MonitorSnapshot{
+		Version:    model.MonitorSnapshotVersion,
+		SnapshotID: snapshotID,
+		CapturedAt: capturedAt,
+		Query:      queryParams,
+		StatsScope: query.StatsScope,
+		SortBy:     query.SortBy,
+		SortDir:    query.SortDir,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalItems: totalItems,
+		TotalPages: monitorTotalPages(totalItems, query.PageSize),
+		Items:      items,
+	}
+	if query.StatsScope == "range" {
+		start := query.Start
+		end := query.End
+		snapshot.RangeStart = &start
+		snapshot.RangeEnd = &end
+		snapshot.RangeRollup = monitorRangeRollup(query)
+	}
+	return snapshot
+}
+
+// RunSnapshotJanitor deletes snapshots older than
+// MONITOR_SNAPSHOT_RETENTION_DAYS every hour until ctx is done. A retention
+// of 0 keeps them forever.
+func (s *Server) RunSnapshotJanitor(ctx context.Context) {
+	if s.cfg.SnapshotRetentionDays <= 0 {
+		return
+	}
+	ticker := time.NewTicker(snapshotJanitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sweepExpiredSnapshots(ctx, now.UTC())
+		}
+	}
+}
+
+func (s *Server) sweepExpiredSnapshots(ctx context.Context, now time.Time) {
+	cutoff := now.AddDate(0, 0, -s.cfg.SnapshotRetentionDays)
+	deleted, err := s.store.DeleteMonitorSnapshotsBefore(ctx, cutoff)
+	if err != nil {
+		slog.Warn("monitor snapshot cleanup failed", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("monitor snapshots expired", "deleted", deleted, "before", cutoff)
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+func TestBuildMonitorSnapshotRangeScopeRecordsWindow(t *testing.T) {
+	t.Parallel()
+
+	capturedAt := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	start := capturedAt.Add(-72 * time.Hour)
+	rawQuery := map[string][]string{"stats_scope": {"range"}, "vlan": {"10,20"}}
+	query := store.MonitorPageQuery{
+		Page:       2,
+		PageSize:   50,
+		StatsScope: "range",
+		SortBy:     "failed_count",
+		SortDir:    "desc",
+		Start:      start,
+		End:        capturedAt,
+	}
+
+	snapshot := buildMonitorSnapshot("snap-1", capturedAt, rawQuery, query, nil, 101)
+	rawQuery["vlan"][0] = "mutated"
+
+	if snapshot.Version != model.MonitorSnapshotVersion {
+		t.Fatalf("expected version %d, got %d", model.MonitorSnapshotVersion, snapshot.Version)
+	}
+	if snapshot.RangeStart == nil || !snapshot.RangeStart.Equal(start) || snapshot.RangeEnd == nil || !snapshot.RangeEnd.Equal(capturedAt) {
+		t.Fatalf("unexpected range window: %+v - %+v", snapshot.RangeStart, snapshot.RangeEnd)
+	}
+	if snapshot.RangeRollup != "1h" {
+		t.Fatalf("expected 1h rollup, got %q", snapshot.RangeRollup)
+	}
+	if snapshot.TotalPages != 3 {
+		t.Fatalf("expected 3 pages, got %d", snapshot.TotalPages)
+	}
+	if snapshot.Items == nil {
+		t.Fatalf("expected non-nil items")
+	}
+	if snapshot.Query["vlan"][0] != "10,20" {
+		t.Fatalf("expected query params to be copied, got %v", snapshot.Query["vlan"])
+	}
+}
+
+func TestBuildMonitorSnapshotLiveScopeOmitsWindow(t *testing.T) {
+	t.Parallel()
+
+	items := []model.MonitorEndpoint{{EndpointID: 7, IPAddress: "10.0.0.7"}}
+	snapshot := buildMonitorSnapshot("snap-2", time.Now().UTC(), nil, store.MonitorPageQuery{
+		Page:       1,
+		PageSize:   100,
+		StatsScope: "live",
+	}, items, 1)
+
+	if snapshot.RangeStart != nil || snapshot.RangeEnd != nil || snapshot.RangeRollup != "" {
+		t.Fatalf("expected live snapshot to omit range window, got %+v", snapshot)
+	}
+	if len(snapshot.Items) != 1 || snapshot.Items[0].EndpointID != 7 {
+		t.Fatalf("expected captured rows to be embedded, got %+v", snapshot.Items)
+	}
+}
+
+func TestSweepExpiredSnapshotsKeepsRetentionWindow(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	st := newMemoryStore()
+	st.snapshots["old"] = model.MonitorSnapshot{SnapshotID: "old", CapturedAt: now.AddDate(0, 0, -31)}
+	st.snapshots["recent"] = model.MonitorSnapshot{SnapshotID: "recent", CapturedAt: now.AddDate(0, 0, -29)}
+	srv := newTestServer(st)
+	srv.cfg.SnapshotRetentionDays = 30
+
+	srv.sweepExpiredSnapshots(context.Background(), now)
+
+	if _, ok := st.snapshots["old"]; ok {
+		t.Fatalf("snapshot past retention was kept")
+	}
+	if _, ok := st.snapshots["recent"]; !ok {
+		t.Fatalf("snapshot inside retention was deleted")
+	}
+}
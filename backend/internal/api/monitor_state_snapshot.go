@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"sonarscope/backend/internal/store"
+)
+
+type monitorStateItem struct {
+	EndpointID             int64      `json:"endpoint_id"`
+	Status                 string     `json:"status"`
+	LatencyMs              *float64   `json:"latency_ms"`
+	ConsecutiveFailedCount int64      `json:"consecutive_failed_count"`
+	LastSuccessOn          *time.Time `json:"last_success_on"`
+	LastFailedOn           *time.Time `json:"last_failed_on"`
+}
+
+func (s *Server) monitorStateSnapshot(ctx context.Context) (any, error) {
+	endpoints, err := s.store.ListMonitorEndpoints(ctx, store.MonitorFilters{})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]monitorStateItem, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		items = append(items, monitorStateItem{
+			EndpointID:             endpoint.EndpointID,
+			Status:                 endpoint.LastPingStatus,
+			LatencyMs:              endpoint.LastPingLatency,
+			ConsecutiveFailedCount: endpoint.ConsecutiveFailedCount,
+			LastSuccessOn:          endpoint.LastSuccessOn,
+			LastFailedOn:           endpoint.LastFailedOn,
+		})
+	}
+	return map[string]any{
+		"type":      "state_snapshot",
+		"timestamp": time.Now().UTC(),
+		"count":     len(items),
+		"items":     items,
+	}, nil
+}
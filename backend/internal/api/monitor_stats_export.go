@@ -0,0 +1,195 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/util"
+)
+
+const (
+	statsExportFlushEvery  = 500
+	statsDiffChangedLimit  = 5000
+	statsDiffMaxSnapshotMB = 256
+)
+
+type endpointStatsChange struct {
+	EndpointID                  int64  `json:"endpoint_id"`
+	Hostname                    string `json:"hostname"`
+	IPAddress                   string `json:"ip_address"`
+	StatusBefore                string `json:"status_before"`
+	StatusAfter                 string `json:"status_after"`
+	SuccessCountDelta           int64  `json:"success_count_delta"`
+	FailedCountDelta            int64  `json:"failed_count_delta"`
+	TotalSentPingDelta          int64  `json:"total_sent_ping_delta"`
+	ConsecutiveFailedCountDelta int64  `json:"consecutive_failed_count_delta"`
+}
+
+type endpointStatsDiff struct {
+	Compared         int64                 `json:"compared"`
+	Unchanged        int64                 `json:"unchanged"`
+	ChangedCount     int64                 `json:"changed_count"`
+	Changed          []endpointStatsChange `json:"changed"`
+	Truncated        bool                  `json:"truncated"`
+	MissingInCurrent []int64               `json:"missing_in_current"`
+	NewInCurrent     []int64               `json:"new_in_current"`
+	GeneratedAt      time.Time             `json:"generated_at"`
+}
+
+func (s *Server) handleMonitorStatsExport(w http.ResponseWriter, r *http.Request) {
+	query, parseErr := s.monitorPageQueryFromRequest(r, monitorRequestOptions{})
+	if parseErr != nil {
+		util.WriteError(w, parseErr.Status, parseErr.Message)
+		return
+	}
+	redact, err := parseBoolQuery(r, "redact")
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var redactor *exportRedactor
+	if redact {
+		redactor = newExportRedactor()
+	}
+
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	written := 0
+	err = s.store.StreamEndpointStats(r.Context(), query, func(item model.EndpointStatsSnapshot) error {
+		if redactor != nil {
+			item.Hostname = redactor.Hostname(item.Hostname)
+			item.IPAddress = redactor.IP(item.IPAddress)
+			if item.ReplyIPAddress != nil {
+				replyIP := redactor.IP(*item.ReplyIPAddress)
+				item.ReplyIPAddress = &replyIP
+			}
+		}
+		if written == 0 {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=endpoint-stats-%s.ndjson", time.Now().UTC().Format("20060102-150405")))
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+		written++
+		if flusher != nil && written%statsExportFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if written == 0 {
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		slog.Warn("stats export aborted", "rows", written, "error", err)
+		return
+	}
+	if written == 0 {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (s *Server) handleMonitorStatsDiff(w http.ResponseWriter, r *http.Request) {
+	query, parseErr := s.monitorPageQueryFromRequest(r, monitorRequestOptions{})
+	if parseErr != nil {
+		util.WriteError(w, parseErr.Status, parseErr.Message)
+		return
+	}
+
+	prior, err := decodeStatsSnapshot(http.MaxBytesReader(w, r.Body, statsDiffMaxSnapshotMB<<20))
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	diff := endpointStatsDiff{
+		Changed:          []endpointStatsChange{},
+		MissingInCurrent: []int64{},
+		NewInCurrent:     []int64{},
+	}
+	seen := make(map[int64]struct{}, len(prior))
+	err = s.store.StreamEndpointStats(r.Context(), query, func(current model.EndpointStatsSnapshot) error {
+		before, ok := prior[current.EndpointID]
+		if !ok {
+			diff.NewInCurrent = append(diff.NewInCurrent, current.EndpointID)
+			return nil
+		}
+		seen[current.EndpointID] = struct{}{}
+		diff.add(before, current)
+		return nil
+	})
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for endpointID := range prior {
+		if _, ok := seen[endpointID]; !ok {
+			diff.MissingInCurrent = append(diff.MissingInCurrent, endpointID)
+		}
+	}
+	sort.Slice(diff.MissingInCurrent, func(i, j int) bool {
+		return diff.MissingInCurrent[i] < diff.MissingInCurrent[j]
+	})
+	diff.GeneratedAt = time.Now().UTC()
+	util.WriteJSON(w, http.StatusOK, diff)
+}
+
+func (d *endpointStatsDiff) add(before, after model.EndpointStatsSnapshot) {
+	d.Compared++
+	change := endpointStatsChange{
+		EndpointID:                  after.EndpointID,
+		Hostname:                    after.Hostname,
+		IPAddress:                   after.IPAddress,
+		StatusBefore:                before.LastPingStatus,
+		StatusAfter:                 after.LastPingStatus,
+		SuccessCountDelta:           after.SuccessCount - before.SuccessCount,
+		FailedCountDelta:            after.FailedCount - before.FailedCount,
+		TotalSentPingDelta:          after.TotalSentPing - before.TotalSentPing,
+		ConsecutiveFailedCountDelta: after.ConsecutiveFailedCount - before.ConsecutiveFailedCount,
+	}
+	if change.StatusBefore == change.StatusAfter &&
+		change.SuccessCountDelta == 0 &&
+		change.FailedCountDelta == 0 &&
+		change.TotalSentPingDelta == 0 &&
+		change.ConsecutiveFailedCountDelta == 0 {
+		d.Unchanged++
+		return
+	}
+
+	d.ChangedCount++
+	if len(d.Changed) >= statsDiffChangedLimit {
+		d.Truncated = true
+		return
+	}
+	d.Changed = append(d.Changed, change)
+}
+
+func decodeStatsSnapshot(body io.Reader) (map[int64]model.EndpointStatsSnapshot, error) {
+	decoder := json.NewDecoder(body)
+	prior := map[int64]model.EndpointStatsSnapshot{}
+	for line := 1; ; line++ {
+		var item model.EndpointStatsSnapshot
+		if err := decoder.Decode(&item); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("invalid snapshot record %d: %v", line, err)
+		}
+		if item.EndpointID <= 0 {
+			return nil, fmt.Errorf("invalid snapshot record %d: endpoint_id is required", line)
+		}
+		prior[item.EndpointID] = item
+	}
+	return prior, nil
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestHandleMonitorStatsExportStreamsJSONLines(t *testing.T) {
+	st := newMemoryStore()
+	st.stats = []model.EndpointStatsSnapshot{
+		{EndpointID: 1, Hostname: "edge-1", IPAddress: "10.0.0.1", SuccessCount: 10, TotalSentPing: 10, LastPingStatus: "Succeeded"},
+		{EndpointID: 2, Hostname: "edge-2", IPAddress: "10.0.0.2", FailedCount: 3, TotalSentPing: 3, LastPingStatus: "Timeout"},
+	}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/stats-export?hostname=edge")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Fatalf("content type = %q, want application/x-ndjson", got)
+	}
+	if st.lastQuery.Hostname != "edge" {
+		t.Fatalf("hostname filter = %q, want edge", st.lastQuery.Hostname)
+	}
+
+	var lines []model.EndpointStatsSnapshot
+	scanner := bufio.NewScanner(bytes.NewReader(rec.Body.Bytes()))
+	for scanner.Scan() {
+		var item model.EndpointStatsSnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			t.Fatalf("decode line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, item)
+	}
+	if len(lines) != 2 || lines[1].EndpointID != 2 || lines[1].LastPingStatus != "Timeout" {
+		t.Fatalf("unexpected export lines: %+v", lines)
+	}
+}
+
+func TestHandleMonitorStatsExportRedactsAddresses(t *testing.T) {
+	replyIP := "10.0.0.1"
+	st := newMemoryStore()
+	st.stats = []model.EndpointStatsSnapshot{
+		{EndpointID: 1, Hostname: "edge-1", IPAddress: "10.0.0.1", ReplyIPAddress: &replyIP, TotalSentPing: 10},
+		{EndpointID: 2, Hostname: "10.0.0.2", IPAddress: "10.0.0.2", TotalSentPing: 3},
+	}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/stats-export?redact=true")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if bytes.Contains(rec.Body.Bytes(), []byte("edge-1")) {
+		t.Fatalf("export leaked hostname: %s", rec.Body.String())
+	}
+
+	var lines []model.EndpointStatsSnapshot
+	scanner := bufio.NewScanner(bytes.NewReader(rec.Body.Bytes()))
+	for scanner.Scan() {
+		var item model.EndpointStatsSnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			t.Fatalf("decode line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, item)
+	}
+	if len(lines) != 2 || lines[0].ReplyIPAddress == nil || *lines[0].ReplyIPAddress != lines[0].IPAddress {
+		t.Fatalf("expected reply ip to redact like the endpoint ip: %+v", lines)
+	}
+	if lines[0].IPAddress == "10.0.0.1" || lines[1].IPAddress == "10.0.0.2" {
+		t.Fatalf("export leaked ip addresses: %+v", lines)
+	}
+	if lines[1].Hostname != lines[1].IPAddress {
+		t.Fatalf("ip-valued hostname = %q, want %q", lines[1].Hostname, lines[1].IPAddress)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/monitor/stats-export?redact=maybe")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid redact status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleMonitorStatsDiffComparesPriorSnapshot(t *testing.T) {
+	st := newMemoryStore()
+	st.stats = []model.EndpointStatsSnapshot{
+		{EndpointID: 1, SuccessCount: 10, TotalSentPing: 10, LastPingStatus: "Succeeded"},
+		{EndpointID: 2, SuccessCount: 5, FailedCount: 4, TotalSentPing: 9, ConsecutiveFailedCount: 4, LastPingStatus: "Timeout"},
+		{EndpointID: 4, TotalSentPing: 1, LastPingStatus: "Succeeded"},
+	}
+	srv := newTestServer(st)
+
+	var prior bytes.Buffer
+	encoder := json.NewEncoder(&prior)
+	for _, item := range []model.EndpointStatsSnapshot{
+		{EndpointID: 1, SuccessCount: 10, TotalSentPing: 10, LastPingStatus: "Succeeded"},
+		{EndpointID: 2, SuccessCount: 5, FailedCount: 1, TotalSentPing: 6, ConsecutiveFailedCount: 1, LastPingStatus: "Succeeded"},
+		{EndpointID: 3, SuccessCount: 2, TotalSentPing: 2, LastPingStatus: "Succeeded"},
+	} {
+		if err := encoder.Encode(item); err != nil {
+			t.Fatalf("encode prior snapshot: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/monitor/stats-diff", &prior)
+	rec := httptest.NewRecorder()
+	srv.Routes().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var diff endpointStatsDiff
+	decodeTestResponse(t, rec, &diff)
+	if diff.Compared != 2 || diff.Unchanged != 1 || diff.ChangedCount != 1 {
+		t.Fatalf("unexpected diff counts: %+v", diff)
+	}
+	change := diff.Changed[0]
+	if change.EndpointID != 2 || change.StatusBefore != "Succeeded" || change.StatusAfter != "Timeout" || change.FailedCountDelta != 3 {
+		t.Fatalf("unexpected change: %+v", change)
+	}
+	if len(diff.MissingInCurrent) != 1 || diff.MissingInCurrent[0] != 3 {
+		t.Fatalf("missing_in_current = %v, want [3]", diff.MissingInCurrent)
+	}
+	if len(diff.NewInCurrent) != 1 || diff.NewInCurrent[0] != 4 {
+		t.Fatalf("new_in_current = %v, want [4]", diff.NewInCurrent)
+	}
+
+	bad := httptest.NewRequest(http.MethodPost, "/api/monitor/stats-diff", bytes.NewBufferString("{\"hostname\":\"x\"}\n"))
+	rec = httptest.NewRecorder()
+	srv.Routes().ServeHTTP(rec, bad)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d for record without endpoint_id", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/util"
+)
+
+const statsResetMaxIDs = 50000
+
+type statsResetEvent struct {
+	Type        string    `json:"type"`
+	EndpointIDs []int64   `json:"endpoint_ids"`
+	Count       int       `json:"count"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+func (s *Server) handleMonitorEndpointResetStats(w http.ResponseWriter, r *http.Request) {
+	endpointID, err := strconv.ParseInt(chi.URLParam(r, "endpointID"), 10, 64)
+	if err != nil || endpointID < 1 {
+		util.WriteError(w, http.StatusBadRequest, "invalid endpoint id")
+		return
+	}
+
+	endpointIDs, err := s.store.ResolveExistingInventoryEndpointIDs(r.Context(), []int64{endpointID})
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(endpointIDs) == 0 {
+		util.WriteError(w, http.StatusNotFound, "endpoint not found")
+		return
+	}
+	s.resetEndpointStats(w, r, endpointIDs)
+}
+
+// handleMonitorResetStats resets every endpoint listed in endpoint_ids plus
+// every member of group_id.
+func (s *Server) handleMonitorResetStats(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		EndpointIDs []int64 `json:"endpoint_ids"`
+		GroupID     *int64  `json:"group_id"`
+	}
+	var req request
+	if err := util.DecodeJSON(r, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	if len(req.EndpointIDs) == 0 && req.GroupID == nil {
+		util.WriteError(w, http.StatusBadRequest, "endpoint_ids or group_id is required")
+		return
+	}
+	if len(req.EndpointIDs) > statsResetMaxIDs {
+		util.WriteError(w, http.StatusBadRequest, "endpoint_ids exceeds the maximum of 50000")
+		return
+	}
+
+	endpointIDs, err := s.store.ResolveExistingInventoryEndpointIDs(r.Context(), req.EndpointIDs)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if req.GroupID != nil {
+		if _, err := s.store.GetGroupByID(r.Context(), *req.GroupID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				util.WriteError(w, http.StatusNotFound, "group not found")
+				return
+			}
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		groupEndpointIDs, err := s.store.ListEndpointIDsByGroup(r.Context(), *req.GroupID)
+		if err != nil {
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		endpointIDs = uniqueInt64(append(endpointIDs, groupEndpointIDs...))
+	}
+	s.resetEndpointStats(w, r, endpointIDs)
+}
+
+func (s *Server) resetEndpointStats(w http.ResponseWriter, r *http.Request, endpointIDs []int64) {
+	reset, err := s.store.ResetEndpointStats(r.Context(), endpointIDs)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if s.hub != nil && len(endpointIDs) > 0 {
+		s.hub.Broadcast(statsResetEvent{
+			Type:        "stats_reset",
+			EndpointIDs: endpointIDs,
+			Count:       len(endpointIDs),
+			Timestamp:   time.Now().UTC(),
+		})
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"matched": len(endpointIDs),
+		"reset":   reset,
+	})
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestHandleMonitorEndpointResetStats(t *testing.T) {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{{EndpointID: 1}, {EndpointID: 2}}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodPost, "/api/monitor/endpoints/2/reset-stats")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if len(st.resetIDs) != 1 || st.resetIDs[0] != 2 {
+		t.Fatalf("unexpected reset ids: %v", st.resetIDs)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodPost, "/api/monitor/endpoints/9/reset-stats")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown endpoint status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleMonitorResetStatsMergesIDsAndGroup(t *testing.T) {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{{EndpointID: 1}, {EndpointID: 2}, {EndpointID: 3}}
+	st.groupScope = map[int64][]int64{7: {2, 3}}
+	srv := newTestServer(st)
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/monitor/reset-stats", map[string]any{
+		"endpoint_ids": []int64{1, 2, 99},
+		"group_id":     7,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var body struct {
+		Matched int `json:"matched"`
+		Reset   int `json:"reset"`
+	}
+	decodeTestResponse(t, rec, &body)
+	if body.Matched != 3 || body.Reset != 3 || len(st.resetIDs) != 3 {
+		t.Fatalf("unexpected reset result %+v ids=%v", body, st.resetIDs)
+	}
+
+	rec = serveTestJSONRequest(t, srv, http.MethodPost, "/api/monitor/reset-stats", map[string]any{"group_id": 8})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown group status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	rec = serveTestJSONRequest(t, srv, http.MethodPost, "/api/monitor/reset-stats", map[string]any{})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty request status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
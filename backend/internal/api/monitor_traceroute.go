@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/probe"
+	"sonarscope/backend/internal/store"
+	"sonarscope/backend/internal/util"
+)
+
+// handleMonitorEndpointTraceroute runs a traceroute to the endpoint and
+// returns the hops once it finishes. It is not part of the probe loop and
+// nothing is stored.
+func (s *Server) handleMonitorEndpointTraceroute(w http.ResponseWriter, r *http.Request) {
+	endpointID, err := strconv.ParseInt(chi.URLParam(r, "endpointID"), 10, 64)
+	if err != nil || endpointID < 1 {
+		util.WriteError(w, http.StatusBadRequest, "invalid endpoint id")
+		return
+	}
+
+	options := probe.TracerouteOptions{
+		MaxHops:    probe.TracerouteDefaultMaxHops,
+		HopTimeout: probe.TracerouteDefaultHopTimeout,
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("max_hops")); raw != "" {
+		maxHops, err := strconv.Atoi(raw)
+		if err != nil || maxHops < 1 || maxHops > probe.TracerouteMaxHopsLimit {
+			util.WriteError(w, http.StatusBadRequest, "max_hops must be between 1 and 64")
+			return
+		}
+		options.MaxHops = maxHops
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("timeout_ms")); raw != "" {
+		timeoutMs, err := strconv.Atoi(raw)
+		if err != nil || timeoutMs < 100 || timeoutMs > int(probe.TracerouteMaxHopTimeout/time.Millisecond) {
+			util.WriteError(w, http.StatusBadRequest, "timeout_ms must be between 100 and 5000")
+			return
+		}
+		options.HopTimeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+
+	endpoint, err := s.store.GetInventoryEndpointByID(r.Context(), endpointID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.WriteError(w, http.StatusNotFound, "endpoint not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result, err := s.probe.Traceroute(r.Context(), store.ProbeTarget{
+		EndpointID: endpoint.EndpointID,
+		IP:         endpoint.IPAddress,
+		Hostname:   endpoint.Hostname,
+	}, options)
+	if err != nil {
+		if errors.Is(err, probe.ErrTracerouteBusy) {
+			util.WriteError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		util.WriteError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestHandleMonitorEndpointTracerouteRejectsBadRequests(t *testing.T) {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{{EndpointID: 1, IPAddress: "10.0.0.1"}}
+	srv := newTestServer(st)
+
+	for _, path := range []string{
+		"/api/monitor/endpoints/abc/traceroute",
+		"/api/monitor/endpoints/1/traceroute?max_hops=0",
+		"/api/monitor/endpoints/1/traceroute?max_hops=65",
+		"/api/monitor/endpoints/1/traceroute?timeout_ms=50",
+		"/api/monitor/endpoints/1/traceroute?timeout_ms=6000",
+	} {
+		if rec := serveTestRequest(t, srv, http.MethodGet, path); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want %d", path, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints/9/traceroute"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown endpoint status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/probe"
+	"sonarscope/backend/internal/store"
+	"sonarscope/backend/internal/util"
+)
+
+func (s *Server) handleProbeOnce(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		EndpointID int64 `json:"endpoint_id"`
+	}
+	var req request
+	if err := util.DecodeJSON(r, &req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	if req.EndpointID <= 0 {
+		util.WriteError(w, http.StatusBadRequest, "endpoint_id is required")
+		return
+	}
+
+	endpoint, err := s.store.GetInventoryEndpointByID(r.Context(), req.EndpointID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.WriteError(w, http.StatusNotFound, "endpoint not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	op, err := s.probe.StartProbeOnce(store.ProbeTarget{
+		EndpointID: endpoint.EndpointID,
+		IP:         endpoint.IPAddress,
+		Hostname:   endpoint.Hostname,
+		ProbeMode:  endpoint.ProbeMode,
+		HTTPTarget: endpoint.ProbeHTTPTarget,
+		TCPPort:    endpoint.ProbeTCPPort,
+	})
+	if err != nil {
+		if errors.Is(err, probe.ErrProbeSocketUnavailable) {
+			util.WriteError(w, http.StatusConflict, "ICMP probe-once needs a running probe session; start probing or use a tcp/http probe mode")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusAccepted, op)
+}
+
+func (s *Server) handleProbeOperations(w http.ResponseWriter, _ *http.Request) {
+	items := s.probe.AdhocOperations()
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"count": len(items),
+		"items": items,
+	})
+}
+
+func (s *Server) handleProbeOperation(w http.ResponseWriter, r *http.Request) {
+	op, err := s.probe.AdhocOperation(chi.URLParam(r, "opID"))
+	if err != nil {
+		if errors.Is(err, probe.ErrAdhocOperationNotFound) {
+			util.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, op)
+}
+
+func (s *Server) handleProbeCancel(w http.ResponseWriter, r *http.Request) {
+	opID := chi.URLParam(r, "opID")
+	canceled, err := s.probe.CancelAdhoc(opID)
+	if err != nil {
+		if errors.Is(err, probe.ErrAdhocOperationNotFound) {
+			util.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"op_id":    opID,
+		"canceled": canceled,
+	})
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"sonarscope/backend/internal/util"
+)
+
+const probeCoverageSampleLimit = 50
+
+func (s *Server) handleProbeCoverage(w http.ResponseWriter, r *http.Request) {
+	scope := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("scope")))
+	groupIDs := parseInt64CSVQuery(r, "group_ids")
+	running := false
+	if scope == "" && s.probe != nil {
+		status := s.probe.Status()
+		scope = status.Scope
+		groupIDs = status.GroupIDs
+		running = status.Running
+	}
+	if scope == "" {
+		scope = "all"
+	}
+	if scope != "all" && scope != "groups" {
+		util.WriteError(w, http.StatusBadRequest, "scope must be all or groups")
+		return
+	}
+	if scope == "groups" && len(groupIDs) == 0 {
+		util.WriteError(w, http.StatusBadRequest, "group_ids required for groups scope")
+		return
+	}
+
+	targets, err := s.store.ListProbeTargets(r.Context(), scope, groupIDs)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	allEndpointIDs, err := s.store.ListAllEndpointIDs(r.Context())
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	inScope := make(map[int64]struct{}, len(targets))
+	for _, target := range targets {
+		inScope[target.EndpointID] = struct{}{}
+	}
+	uncoveredIDs := make([]int64, 0)
+	for _, endpointID := range allEndpointIDs {
+		if _, ok := inScope[endpointID]; !ok {
+			uncoveredIDs = append(uncoveredIDs, endpointID)
+		}
+	}
+
+	sample, err := s.store.ListInventoryEndpointsByIDs(r.Context(), uncoveredIDs, probeCoverageSampleLimit)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if groupIDs == nil {
+		groupIDs = []int64{}
+	}
+
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"running":          running,
+		"scope":            scope,
+		"group_ids":        groupIDs,
+		"total_endpoints":  len(allEndpointIDs),
+		"in_scope":         len(inScope),
+		"out_of_scope":     len(uncoveredIDs),
+		"uncovered_sample": sample,
+	})
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"sonarscope/backend/internal/util"
+)
+
+type rateLimitBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a per-client token bucket. Each client IP may burst up to
+// perMinute requests and then refills at perMinute/60 tokens per second. A
+// zero perMinute disables limiting.
+type rateLimiter struct {
+	mu        sync.Mutex
+	perMinute int
+	now       func() time.Time
+	buckets   map[string]*rateLimitBucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	return &rateLimiter{
+		perMinute: perMinute,
+		now:       time.Now,
+		buckets:   map[string]*rateLimitBucket{},
+	}
+}
+
+// allow takes a token for key. When none is left it returns how long until
+// the next token is available.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	if l.perMinute <= 0 {
+		return true, 0
+	}
+	capacity := float64(l.perMinute)
+	ratePerSec := capacity / 60
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now, capacity, ratePerSec)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{tokens: capacity, last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.last).Seconds()*ratePerSec)
+	bucket.last = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - bucket.tokens) / ratePerSec * float64(time.Second))
+	return false, wait
+}
+
+// sweep drops buckets that have refilled completely, which behave the same as
+// a missing bucket, so idle clients do not accumulate. Runs at most once a
+// minute.
+func (l *rateLimiter) sweep(now time.Time, capacity float64, ratePerSec float64) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*ratePerSec >= capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimitMiddleware answers 429 with Retry-After once a client IP exhausts
+// the limiter. It relies on middleware.RealIP having already rewritten
+// RemoteAddr.
+func rateLimitMiddleware(limiter *rateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, wait := limiter.allow(clientIP(r))
+			if !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				util.WriteError(w, http.StatusTooManyRequests, "rate limit exceeded; retry later")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/config"
+)
+
+func TestRateLimiterRefillsPerClient(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limiter := newRateLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.allow("10.0.0.1"); !ok {
+			t.Fatalf("request %d rejected inside burst", i+1)
+		}
+	}
+	ok, wait := limiter.allow("10.0.0.1")
+	if ok || wait != 30*time.Second {
+		t.Fatalf("expected third request rejected with 30s wait, got ok=%v wait=%s", ok, wait)
+	}
+	if ok, _ := limiter.allow("10.0.0.2"); !ok {
+		t.Fatalf("expected other client to have its own bucket")
+	}
+
+	now = now.Add(30 * time.Second)
+	if ok, _ := limiter.allow("10.0.0.1"); !ok {
+		t.Fatalf("expected one token after refill")
+	}
+}
+
+func TestRateLimiterDisabledWithZeroLimit(t *testing.T) {
+	limiter := newRateLimiter(0)
+	for i := 0; i < 100; i++ {
+		if ok, _ := limiter.allow("10.0.0.1"); !ok {
+			t.Fatalf("expected zero limit to disable limiting")
+		}
+	}
+}
+
+func TestExportRouteReturns429WithRetryAfter(t *testing.T) {
+	srv := newServerWithStore(config.Config{ExportRateLimitPerMin: 1}, newMemoryStore(), nil, nil)
+
+	serve := func(forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/monitor/stats-export", nil)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		srv.Routes().ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("192.0.2.10"); rec.Code == http.StatusTooManyRequests {
+		t.Fatalf("first request limited")
+	}
+	rec := serve("192.0.2.10")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Fatalf("Retry-After = %q, want 60", got)
+	}
+	if rec := serve("192.0.2.11"); rec.Code == http.StatusTooManyRequests {
+		t.Fatalf("expected a different client IP to be allowed")
+	}
+}
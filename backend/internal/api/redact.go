@@ -0,0 +1,98 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"strings"
+)
+
+const redactedHostnameHashLen = 12
+
+// exportRedactor pseudonymizes addresses and hostnames for one export. The
+// key is random per export, so output is stable within a file but cannot be
+// joined across files.
+//
+// IPv4 addresses keep their /16 and IPv6 addresses their /48; the host part is
+// replaced by a keyed sequence in first-seen order, so distinct inputs stay
+// distinct and subnet grouping is preserved. An address is never mapped to
+// itself.
+type exportRedactor struct {
+	key      []byte
+	ips      map[string]string
+	nextHost map[string]uint64
+}
+
+func newExportRedactor() *exportRedactor {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return &exportRedactor{
+		key:      key,
+		ips:      map[string]string{},
+		nextHost: map[string]uint64{},
+	}
+}
+
+// IP redacts a textual address. Values that do not parse as an IP are treated
+// as hostnames; empty values are returned unchanged.
+func (r *exportRedactor) IP(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return r.Hostname(value)
+	}
+	canonical := ip.String()
+	if redacted, ok := r.ips[canonical]; ok {
+		return redacted
+	}
+
+	redacted := canonical
+	for redacted == canonical {
+		if v4 := ip.To4(); v4 != nil {
+			// Host parts are 1..65535 so the result never ends in .0.0.
+			host := r.nextHostIndex(net.IPv4(v4[0], v4[1], 0, 0).String())%65535 + 1
+			redacted = net.IPv4(v4[0], v4[1], byte(host>>8), byte(host)).String()
+		} else {
+			masked := make(net.IP, net.IPv6len)
+			copy(masked, ip.To16()[:6])
+			binary.BigEndian.PutUint64(masked[8:], r.nextHostIndex(masked.String()))
+			redacted = masked.String()
+		}
+	}
+	r.ips[canonical] = redacted
+	return redacted
+}
+
+// Hostname replaces a name with a keyed hash. Names that are IP addresses
+// (the default when inventory has no hostname) are redacted as IPs.
+func (r *exportRedactor) Hostname(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	if net.ParseIP(value) != nil {
+		return r.IP(value)
+	}
+	mac := hmac.New(sha256.New, r.key)
+	_, _ = mac.Write([]byte(strings.ToLower(value)))
+	return "host-" + hex.EncodeToString(mac.Sum(nil))[:redactedHostnameHashLen]
+}
+
+// nextHostIndex returns successive values for prefix starting from a keyed
+// offset, so the first host in a subnet does not always become .0.1.
+func (r *exportRedactor) nextHostIndex(prefix string) uint64 {
+	next, ok := r.nextHost[prefix]
+	if !ok {
+		mac := hmac.New(sha256.New, r.key)
+		_, _ = mac.Write([]byte(prefix))
+		next = binary.BigEndian.Uint64(mac.Sum(nil))
+	}
+	r.nextHost[prefix] = next + 1
+	return next
+}
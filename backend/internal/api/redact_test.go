@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestExportRedactorIPPreservesPrefixAndConsistency(t *testing.T) {
+	redactor := newExportRedactor()
+
+	first := redactor.IP("10.20.30.40")
+	second := redactor.IP("10.20.99.7")
+	if !strings.HasPrefix(first, "10.20.") || !strings.HasPrefix(second, "10.20.") {
+		t.Fatalf("redacted ipv4 lost /16 prefix: %q %q", first, second)
+	}
+	if first == "10.20.30.40" || first == second {
+		t.Fatalf("expected distinct masked addresses, got %q and %q", first, second)
+	}
+	if again := redactor.IP(" 10.20.30.40 "); again != first {
+		t.Fatalf("redaction not stable: %q then %q", first, again)
+	}
+
+	v6 := redactor.IP("2001:db8:1:2::99")
+	_, prefix, _ := net.ParseCIDR("2001:db8:1::/48")
+	if parsed := net.ParseIP(v6); parsed == nil || !prefix.Contains(parsed) || v6 == "2001:db8:1:2::99" {
+		t.Fatalf("redacted ipv6 = %q, want 2001:db8:1::/48 pseudonym", v6)
+	}
+	if redactor.IP("") != "" {
+		t.Fatalf("expected empty input to stay empty")
+	}
+}
+
+func TestExportRedactorHostname(t *testing.T) {
+	redactor := newExportRedactor()
+
+	name := redactor.Hostname("db-core-01.corp.example")
+	if !strings.HasPrefix(name, "host-") || len(name) != len("host-")+redactedHostnameHashLen || strings.Contains(name, "corp") {
+		t.Fatalf("unexpected redacted hostname %q", name)
+	}
+	if again := redactor.Hostname("DB-CORE-01.corp.example"); again != name {
+		t.Fatalf("hostname redaction not stable: %q then %q", name, again)
+	}
+	if other := redactor.Hostname("db-core-02.corp.example"); other == name {
+		t.Fatalf("distinct hostnames collided: %q", other)
+	}
+	if ipName := redactor.Hostname("10.0.0.1"); ipName != redactor.IP("10.0.0.1") {
+		t.Fatalf("ip-valued hostname = %q, want ip redaction", ipName)
+	}
+	if fresh := newExportRedactor().Hostname("db-core-01.corp.example"); fresh == name {
+		t.Fatalf("expected a new export to use a new key")
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestRequestLoggerEmitsStructuredRecord(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	handler := middleware.RequestID(requestLogger(logger)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("upstream"))
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/monitor/kpis?vlan=10", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("request log is not one JSON record: %v: %q", err, buf.String())
+	}
+	if record["msg"] != "http request" || record["level"] != "ERROR" || record["method"] != "GET" || record["path"] != "/api/monitor/kpis" {
+		t.Fatalf("unexpected request record: %v", record)
+	}
+	if record["status"] != float64(http.StatusBadGateway) || record["bytes"] != float64(len("upstream")) || record["request_id"] == "" {
+		t.Fatalf("unexpected request record: %v", record)
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// requestClientContextKey holds the request context as it was before
+// requestTimeout bounded it, so exportTimeout can swap the deadline while
+// still following the client connection.
+type requestClientContextKey struct{}
+
+// requestTimeout bounds the request context so store queries are canceled at
+// the deadline; net/http already cancels it when the client disconnects. A
+// zero duration leaves requests unbounded. Routes that stream whole tables
+// replace the deadline with exportTimeout. Background jobs do not run on the
+// request context and set their own deadline.
+func requestTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), requestClientContextKey{}, r.Context())
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// exportTimeout replaces the requestTimeout deadline on the routes it wraps,
+// which stream whole tables or long ranges. The client disconnecting still
+// cancels the request. A zero duration leaves those routes unbounded.
+func exportTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client, ok := r.Context().Value(requestClientContextKey{}).(context.Context)
+			if !ok {
+				client = r.Context()
+			}
+			ctx := context.WithoutCancel(r.Context())
+			var cancel context.CancelFunc
+			if timeout > 0 {
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+			} else {
+				ctx, cancel = context.WithCancel(ctx)
+			}
+			defer cancel()
+			stop := context.AfterFunc(client, cancel)
+			defer stop()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeoutSetsDeadlinePerRoute(t *testing.T) {
+	var remaining time.Duration
+	var hasDeadline bool
+	record := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var deadline time.Time
+		deadline, hasDeadline = r.Context().Deadline()
+		remaining = time.Until(deadline)
+	})
+	handler := requestTimeout(30 * time.Second)(record)
+	export := requestTimeout(30 * time.Second)(exportTimeout(5 * time.Minute)(record))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/monitor/kpis", nil))
+	if !hasDeadline || remaining <= 0 || remaining > 30*time.Second {
+		t.Fatalf("default route deadline = %v (set %v), want within 30s", remaining, hasDeadline)
+	}
+
+	export.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/groups/7/export", nil))
+	if !hasDeadline || remaining <= 30*time.Second || remaining > 5*time.Minute {
+		t.Fatalf("export route deadline = %v (set %v), want the export timeout", remaining, hasDeadline)
+	}
+}
+
+func TestRequestTimeoutZeroLeavesRequestUnbounded(t *testing.T) {
+	check := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); ok {
+			t.Fatalf("zero timeout should not set a deadline")
+		}
+	})
+	requestTimeout(0)(check).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/monitor/kpis", nil))
+	requestTimeout(30*time.Second)(exportTimeout(0)(check)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/monitor/export", nil))
+}
+
+func TestExportTimeoutFollowsClientDisconnect(t *testing.T) {
+	client, disconnect := context.WithCancel(context.Background())
+	canceled := make(chan struct{})
+	handler := requestTimeout(30 * time.Second)(exportTimeout(5 * time.Minute)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		disconnect()
+		select {
+		case <-r.Context().Done():
+			close(canceled)
+		case <-time.After(time.Second):
+		}
+	})))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/monitor/export", nil).WithContext(client))
+	select {
+	case <-canceled:
+	default:
+		t.Fatalf("export request context was not canceled when the client went away")
+	}
+}
@@ -9,10 +9,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"math"
 	"net"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,6 +24,7 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5"
 
+	"sonarscope/backend/internal/alert"
 	"sonarscope/backend/internal/config"
 	"sonarscope/backend/internal/importer"
 	"sonarscope/backend/internal/model"
@@ -34,33 +36,86 @@ import (
 
 type Server struct {
 	cfg   config.Config
-	store *store.Store
+	store serverStore
 	probe *probe.Engine
 	hub   *telemetry.Hub
 
 	previewMu       sync.RWMutex
 	previews        map[string]model.ImportPreview
+	importRetries   map[string]importRetry
 	switchPreviewMu sync.RWMutex
 	switchPreviews  map[string]model.SwitchDirectoryImportPreview
+	expiredPreviews *previewTombstones
+	previewTTL      time.Duration
+	previewMax      int
 
 	deleteJobMu sync.RWMutex
 	deleteJob   *inventoryDeleteJobState
+
+	recomputeMu  sync.Mutex
+	recomputeJob *model.StatsRecomputeJobStatus
+
+	kpiCache      *monitorKPICache
+	filterCache   *filterOptionsCache
+	alerts        *alert.WebhookNotifier
+	importLimiter *rateLimiter
+	exportLimiter *rateLimiter
 }
 
 func NewServer(cfg config.Config, st *store.Store, p *probe.Engine, hub *telemetry.Hub) *Server {
-	return &Server{
-		cfg:            cfg,
-		store:          st,
-		probe:          p,
-		hub:            hub,
-		previews:       map[string]model.ImportPreview{},
-		switchPreviews: map[string]model.SwitchDirectoryImportPreview{},
+	return newServerWithStore(cfg, st, p, hub)
+}
+
+func newServerWithStore(cfg config.Config, st serverStore, p *probe.Engine, hub *telemetry.Hub) *Server {
+	srv := &Server{
+		cfg:             cfg,
+		store:           st,
+		probe:           p,
+		hub:             hub,
+		previews:        map[string]model.ImportPreview{},
+		importRetries:   map[string]importRetry{},
+		switchPreviews:  map[string]model.SwitchDirectoryImportPreview{},
+		expiredPreviews: newPreviewTombstones(),
+		previewTTL:      time.Duration(cfg.ImportPreviewTTLSec) * time.Second,
+		previewMax:      cfg.ImportPreviewMax,
+		kpiCache:        newMonitorKPICache(monitorKPICacheTTL),
+		filterCache:     newFilterOptionsCache(time.Duration(cfg.FilterOptionsCacheSec) * time.Second),
+		importLimiter:   newRateLimiter(cfg.ImportRateLimitPerMin),
+		exportLimiter:   newRateLimiter(cfg.ExportRateLimitPerMin),
+	}
+	if srv.previewTTL <= 0 {
+		srv.previewTTL = defaultImportPreviewTTL
+	}
+	if srv.previewMax <= 0 {
+		srv.previewMax = defaultImportPreviewMax
+	}
+	if hub != nil {
+		hub.SetConnectSnapshot(srv.monitorStateSnapshot)
+	}
+	return srv
+}
+
+// SetAlertNotifier lets settings updates reconfigure the webhook notifier.
+func (s *Server) SetAlertNotifier(n *alert.WebhookNotifier) {
+	s.alerts = n
+}
+
+// forgetAlerts drops the notifier's state for deleted endpoints.
+func (s *Server) forgetAlerts(endpointIDs []int64) {
+	if s.alerts != nil {
+		s.alerts.Forget(endpointIDs...)
 	}
 }
 
 const (
 	deleteJobBatchSize    = 500
 	deleteJobPingRowBatch = 25000
+	healthPingTimeout     = 2 * time.Second
+	// backgroundJobTimeout bounds delete and recompute jobs, which outlive the
+	// request that started them and so are not covered by requestTimeout.
+	backgroundJobTimeout = 6 * time.Hour
+	defaultPageSize      = 100
+	maxPageSize          = 1000
 )
 
 var reservedCustomFieldNames = map[string]struct{}{
@@ -122,6 +177,11 @@ type inventoryDeleteJobState struct {
 	StartedAt          *time.Time
 	UpdatedAt          *time.Time
 	CompletedAt        *time.Time
+
+	// ctx is canceled by POST /delete-jobs/cancel and bounded by
+	// backgroundJobTimeout.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func cloneInt64Ptr(value *int64) *int64 {
@@ -186,6 +246,7 @@ func (s *Server) beginDeleteJob(mode model.InventoryDeleteJobMode, groupID *int6
 	}
 
 	now := time.Now().UTC()
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundJobTimeout)
 	job := &inventoryDeleteJobState{
 		Active:             true,
 		JobID:              newPreviewID(),
@@ -203,6 +264,8 @@ func (s *Server) beginDeleteJob(mode model.InventoryDeleteJobMode, groupID *int6
 		Phase:              "initializing",
 		StartedAt:          cloneTimePtr(&now),
 		UpdatedAt:          cloneTimePtr(&now),
+		ctx:                ctx,
+		cancel:             cancel,
 	}
 	s.deleteJob = job
 	return job, nil
@@ -223,11 +286,15 @@ func (s *Server) updateDeleteJob(jobID string, updateFn func(job *inventoryDelet
 }
 
 func (s *Server) completeDeleteJob(jobID string, state model.InventoryDeleteJobState, errMsg string) {
+	s.filterCache.invalidate()
 	s.updateDeleteJob(jobID, func(job *inventoryDeleteJobState) {
 		job.Active = false
 		job.State = state
 		job.Error = strings.TrimSpace(errMsg)
-		if job.MatchedEndpoints > 0 && job.ProgressPct < 100 {
+		if job.cancel != nil {
+			job.cancel()
+		}
+		if state != model.InventoryDeleteJobStateCanceled && job.MatchedEndpoints > 0 && job.ProgressPct < 100 {
 			job.ProgressPct = 100
 		}
 		if state == model.InventoryDeleteJobStateCompleted {
@@ -265,22 +332,35 @@ func (s *Server) runDeleteJob(job *inventoryDeleteJobState, endpointIDs []int64)
 
 	pausedJobs, err := s.store.PauseMaintenanceJobs(context.Background())
 	if err != nil {
-		log.Printf("delete job %s: failed to pause maintenance jobs: %v", jobID, err)
+		slog.Warn("delete job: failed to pause maintenance jobs", "job_id", jobID, "error", err)
 		pausedJobs = nil
 	}
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		slog.Error("delete job panicked", "job_id", jobID, "panic", recovered)
+		if len(pausedJobs) > 0 {
+			if err := s.store.ResumeJobs(context.Background(), pausedJobs); err != nil {
+				slog.Warn("delete job: failed to resume maintenance jobs after panic", "job_id", jobID, "error", err)
+			}
+		}
+		s.completeDeleteJob(jobID, model.InventoryDeleteJobStateFailed, fmt.Sprintf("delete job panicked: %v", recovered))
+	}()
 
 	if len(endpointIDs) == 0 {
 		s.completeDeleteJob(jobID, model.InventoryDeleteJobStateCompleted, "")
 		if len(pausedJobs) > 0 {
 			if err := s.store.ResumeJobs(context.Background(), pausedJobs); err != nil {
-				log.Printf("delete job %s: failed to resume maintenance jobs: %v", jobID, err)
+				slog.Warn("delete job: failed to resume maintenance jobs", "job_id", jobID, "error", err)
 			}
 		}
 		return
 	}
 
 	if job.Mode == model.InventoryDeleteJobModeAll {
-		s.runDeleteAllFastPath(jobID, endpointIDs, pausedJobs)
+		s.runDeleteAllFastPath(job.ctx, jobID, endpointIDs, pausedJobs)
 		return
 	}
 
@@ -289,7 +369,7 @@ func (s *Server) runDeleteJob(job *inventoryDeleteJobState, endpointIDs []int64)
 	})
 
 	deletedCount, totalPingRows, err := s.store.DeleteInventoryEndpointsByIDsWithProgress(
-		context.Background(),
+		job.ctx,
 		endpointIDs,
 		deleteJobBatchSize,
 		deleteJobPingRowBatch,
@@ -306,13 +386,14 @@ func (s *Server) runDeleteJob(job *inventoryDeleteJobState, endpointIDs []int64)
 			})
 		},
 	)
+	s.forgetAlerts(endpointIDs)
 	if err != nil {
 		if len(pausedJobs) > 0 {
 			if resumeErr := s.store.ResumeJobs(context.Background(), pausedJobs); resumeErr != nil {
-				log.Printf("delete job %s: failed to resume maintenance jobs after delete failure: %v", jobID, resumeErr)
+				slog.Warn("delete job: failed to resume maintenance jobs after delete failure", "job_id", jobID, "error", resumeErr)
 			}
 		}
-		s.completeDeleteJob(jobID, model.InventoryDeleteJobStateFailed, err.Error())
+		s.interruptDeleteJob(job.ctx, jobID, deletedCount, totalPingRows, err)
 		return
 	}
 
@@ -321,7 +402,7 @@ func (s *Server) runDeleteJob(job *inventoryDeleteJobState, endpointIDs []int64)
 			current.Phase = "resuming maintenance jobs"
 		})
 		if err := s.store.ResumeJobs(context.Background(), pausedJobs); err != nil {
-			log.Printf("delete job %s: failed to resume maintenance jobs: %v", jobID, err)
+			slog.Warn("delete job: failed to resume maintenance jobs", "job_id", jobID, "error", err)
 		}
 	}
 
@@ -338,7 +419,7 @@ func (s *Server) runDeleteJob(job *inventoryDeleteJobState, endpointIDs []int64)
 	s.completeDeleteJob(jobID, model.InventoryDeleteJobStateCompleted, "")
 }
 
-func (s *Server) runDeleteAllFastPath(jobID string, endpointIDs []int64, pausedJobs []int64) {
+func (s *Server) runDeleteAllFastPath(ctx context.Context, jobID string, endpointIDs []int64, pausedJobs []int64) {
 	matchedEndpoints := int64(len(endpointIDs))
 	s.updateDeleteJob(jobID, func(current *inventoryDeleteJobState) {
 		current.Phase = "purging all probe history"
@@ -351,14 +432,15 @@ func (s *Server) runDeleteAllFastPath(jobID string, endpointIDs []int64, pausedJ
 		current.EtaSeconds = estimateDeleteJobETAFromProgress(current.ProgressPct, current.StartedAt)
 	})
 
-	deletedCount, err := s.store.DeleteAllInventoryEndpointsFast(context.Background())
+	deletedCount, err := s.store.DeleteAllInventoryEndpointsFast(ctx)
+	s.forgetAlerts(endpointIDs)
 	if err != nil {
 		if len(pausedJobs) > 0 {
 			if resumeErr := s.store.ResumeJobs(context.Background(), pausedJobs); resumeErr != nil {
-				log.Printf("delete job %s: failed to resume maintenance jobs after delete-all failure: %v", jobID, resumeErr)
+				slog.Warn("delete job: failed to resume maintenance jobs after delete-all failure", "job_id", jobID, "error", resumeErr)
 			}
 		}
-		s.completeDeleteJob(jobID, model.InventoryDeleteJobStateFailed, err.Error())
+		s.interruptDeleteJob(ctx, jobID, 0, 0, err)
 		return
 	}
 
@@ -378,7 +460,7 @@ func (s *Server) runDeleteAllFastPath(jobID string, endpointIDs []int64, pausedJ
 			current.Phase = "resuming maintenance jobs"
 		})
 		if err := s.store.ResumeJobs(context.Background(), pausedJobs); err != nil {
-			log.Printf("delete job %s: failed to resume maintenance jobs: %v", jobID, err)
+			slog.Warn("delete job: failed to resume maintenance jobs", "job_id", jobID, "error", err)
 		}
 	}
 
@@ -458,61 +540,98 @@ func (s *Server) Routes() http.Handler {
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Logger)
+	r.Use(requestLogger(slog.Default()))
 	r.Use(s.corsMiddleware)
 
 	r.Get("/healthz", s.handleHealth)
 	r.Get("/ws/monitor", s.handleWSMonitor)
 
+	// Session routes sit outside the /api key check: POST is how the browser
+	// UI presents a key in the first place.
+	r.Route("/api/auth", func(r chi.Router) {
+		r.Get("/session", s.handleGetAuthSession)
+		r.Post("/session", s.handleCreateAuthSession)
+		r.Delete("/session", s.handleDeleteAuthSession)
+	})
+
 	r.Route("/api", func(r chi.Router) {
+		r.Use(s.apiKeyMiddleware)
+		r.Use(requestTimeout(time.Duration(s.cfg.RequestTimeoutSec) * time.Second))
+		limitImports := rateLimitMiddleware(s.importLimiter)
+		limitExports := rateLimitMiddleware(s.exportLimiter)
+		exportDeadline := exportTimeout(time.Duration(s.cfg.ExportTimeoutSec) * time.Second)
+
 		r.Route("/inventory", func(r chi.Router) {
+			r.Use(s.invalidateFilterOptionsOnWrite)
 			r.Post("/endpoints", s.handleInventoryEndpointCreate)
+			r.Post("/endpoints/bulk", s.handleInventoryEndpointBulkCreate)
 			r.Get("/endpoints", s.handleInventoryEndpoints)
-			r.Get("/endpoints/export.csv", s.handleInventoryEndpointsExportCSV)
+			r.With(limitExports, exportDeadline).Get("/endpoints/export.csv", s.handleInventoryEndpointsExportCSV)
 			r.Post("/endpoints/activity", s.handleInventoryEndpointActivityUpdate)
 			r.Get("/import-template.csv", s.handleInventoryImportTemplateCSV)
 			r.Post("/batch/group/preview", s.handleInventoryBatchGroupPreview)
 			r.Post("/batch/group/apply", s.handleInventoryBatchGroupApply)
 			r.Post("/batch/delete/preview", s.handleInventoryBatchDeletePreview)
 			r.Put("/endpoints/{endpointID}", s.handleInventoryEndpointUpdate)
+			r.Get("/endpoints/{endpointID}/history", s.handleInventoryEndpointHistory)
+			r.Get("/endpoints/{endpointID}/alert-thresholds", s.handleInventoryEndpointAlertThresholds)
 			r.Delete("/endpoints/{endpointID}", s.handleInventoryEndpointDelete)
 			r.Delete("/endpoints/by-group/{groupID}", s.handleInventoryDeleteByGroup)
 			r.Post("/endpoints/delete-all", s.handleInventoryDeleteAll)
+			r.Post("/delete", s.handleInventoryDeleteByIDs)
 			r.Post("/delete-jobs/by-endpoint/{endpointID}", s.handleInventoryDeleteJobByEndpoint)
 			r.Post("/delete-jobs/by-group/{groupID}", s.handleInventoryDeleteJobByGroup)
 			r.Post("/delete-jobs/all", s.handleInventoryDeleteJobAll)
 			r.Post("/delete-jobs/match", s.handleInventoryDeleteJobMatch)
 			r.Get("/delete-jobs/current", s.handleInventoryDeleteJobCurrent)
+			r.Post("/delete-jobs/cancel", s.handleInventoryDeleteJobCancel)
 			r.Get("/filter-options", s.handleInventoryFilters)
-			r.Post("/import-preview", s.handleInventoryImportPreview)
+			r.With(limitImports).Post("/import-preview", s.handleInventoryImportPreview)
 			r.Delete("/import-preview/{previewID}", s.handleInventoryImportPreviewDelete)
-			r.Post("/import-apply", s.handleInventoryImportApply)
+			r.With(limitImports).Post("/import-apply", s.handleInventoryImportApply)
+			r.With(limitImports).Post("/import-retry", s.handleInventoryImportRetry)
 		})
 
 		r.Route("/groups", func(r chi.Router) {
+			r.Use(s.invalidateFilterOptionsOnWrite)
 			r.Get("/", s.handleListGroups)
 			r.Post("/", s.handleCreateGroup)
 			r.Put("/{groupID}", s.handleUpdateGroup)
 			r.Delete("/{groupID}", s.handleDeleteGroup)
 			r.Post("/{groupID}/membership/remove-preview", s.handleGroupMembershipRemovePreview)
+			r.Put("/{groupID}/alert-thresholds", s.handleGroupAlertThresholdsUpdate)
+			r.Put("/{groupID}/probe-interval", s.handleGroupProbeIntervalUpdate)
+			r.With(limitExports, exportDeadline).Get("/{groupID}/export", s.handleGroupExport)
 		})
 
 		r.Route("/probes", func(r chi.Router) {
 			r.Get("/status", s.handleProbeStatus)
+			r.Get("/coverage", s.handleProbeCoverage)
 			r.Post("/start", s.handleProbeStart)
 			r.Post("/stop", s.handleProbeStop)
+			r.Post("/once", s.handleProbeOnce)
+			r.Get("/ops", s.handleProbeOperations)
+			r.Get("/ops/{opID}", s.handleProbeOperation)
+			r.Post("/cancel/{opID}", s.handleProbeCancel)
+		})
+
+		r.Route("/telemetry", func(r chi.Router) {
+			r.Get("/clients", s.handleTelemetryClients)
 		})
 
 		r.Route("/settings", func(r chi.Router) {
+			r.Use(s.requireAPIKey)
 			r.Get("/", s.handleGetSettings)
 			r.Put("/", s.handleUpdateSettings)
+			r.Get("/retention", s.handleGetRetentionSettings)
+			r.Put("/retention", s.handleUpdateRetentionSettings)
 		})
 
 		r.Route("/switches", func(r chi.Router) {
 			r.Get("/", s.handleListSwitchDirectory)
 			r.Post("/", s.handleUpsertSwitchDirectoryEntry)
 			r.Delete("/{switchID}", s.handleDeleteSwitchDirectoryEntry)
-			r.Get("/export.csv", s.handleSwitchDirectoryExportCSV)
+			r.With(exportDeadline).Get("/export.csv", s.handleSwitchDirectoryExportCSV)
 			r.Get("/import-template.csv", s.handleSwitchDirectoryImportTemplateCSV)
 			r.Post("/import-preview", s.handleSwitchDirectoryImportPreview)
 			r.Delete("/import-preview/{previewID}", s.handleSwitchDirectoryImportPreviewDelete)
@@ -521,19 +640,57 @@ func (s *Server) Routes() http.Handler {
 
 		r.Route("/monitor", func(r chi.Router) {
 			r.Get("/endpoints", s.handleMonitorEndpoints)
+			r.Post("/endpoints/{endpointID}/reset-stats", s.handleMonitorEndpointResetStats)
+			r.Get("/endpoints/{endpointID}/traceroute", s.handleMonitorEndpointTraceroute)
+			r.Get("/endpoints/{endpointID}/raw", s.handleMonitorEndpointRawHistory)
+			r.Post("/reset-stats", s.handleMonitorResetStats)
 			r.Get("/endpoints-page", s.handleMonitorEndpointsPage)
 			r.Get("/timeseries", s.handleMonitorTimeSeries)
 			r.Get("/filter-options", s.handleMonitorFilters)
 			r.Get("/switch-ips", s.handleMonitorSwitchIPs)
 			r.Get("/dashboard-summary", s.handleMonitorDashboardSummary)
+			r.Get("/groups/summary", s.handleMonitorGroupSummaries)
+			r.With(limitExports, exportDeadline).Get("/export", s.handleMonitorExportCSV)
+			r.With(limitExports, exportDeadline).Get("/stats-export", s.handleMonitorStatsExport)
+			r.Post("/stats-diff", s.handleMonitorStatsDiff)
+			r.Get("/kpis", s.handleMonitorKPIs)
+			r.Get("/last-seen", s.handleMonitorLastSeen)
+			r.Get("/incidents", s.handleMonitorIncidents)
+			r.Patch("/incidents/{incidentID}", s.handleAnnotateMonitorIncident)
+			r.Get("/sla", s.handleMonitorSLA)
+			r.Post("/snapshot", s.handleMonitorSnapshot)
+			r.Get("/snapshots/{snapshotID}", s.handleMonitorSnapshotGet)
+		})
+
+		r.Route("/maintenance-windows", func(r chi.Router) {
+			r.Get("/", s.handleListMaintenanceWindows)
+			r.Post("/", s.handleCreateMaintenanceWindow)
+			r.Get("/{windowID}", s.handleGetMaintenanceWindow)
+			r.Put("/{windowID}", s.handleUpdateMaintenanceWindow)
+			r.Delete("/{windowID}", s.handleDeleteMaintenanceWindow)
+		})
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Post("/recompute-stats", s.handleAdminRecomputeStats)
+			r.Get("/recompute-stats/current", s.handleAdminRecomputeStatsCurrent)
+			r.Post("/resume-maintenance", s.handleAdminResumeMaintenance)
 		})
 	})
 
 	return r
 }
 
-func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
-	util.WriteJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthPingTimeout)
+	defer cancel()
+
+	stats := s.store.PoolStats()
+	if err := s.store.Ping(ctx); err != nil {
+		slog.Warn("healthz: database ping failed", "error", err)
+		util.WriteJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "degraded", "db": "unreachable", "pool": stats})
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{"status": "ok", "db": "ok", "pool": stats})
 }
 
 func (s *Server) handleWSMonitor(w http.ResponseWriter, r *http.Request) {
@@ -559,7 +716,12 @@ func (s *Server) handleInventoryImportPreview(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	rows, err := importer.Parse(header.Filename, raw)
+	settings, err := s.store.GetSettings(r.Context())
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	rows, err := importer.Parse(header.Filename, raw, normalizeCustomFieldConfigs(settings.CustomFields))
 	if err != nil {
 		util.WriteError(w, http.StatusBadRequest, err.Error())
 		return
@@ -578,9 +740,7 @@ func (s *Server) handleInventoryImportPreview(w http.ResponseWriter, r *http.Req
 		Candidates: classified,
 	}
 
-	s.previewMu.Lock()
-	s.previews[preview.PreviewID] = preview
-	s.previewMu.Unlock()
+	s.storeImportPreview(preview)
 
 	util.WriteJSON(w, http.StatusOK, preview)
 }
@@ -649,13 +809,14 @@ func (s *Server) handleInventoryImportTemplateCSV(w http.ResponseWriter, _ *http
 	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
 	w.Header().Set("Content-Disposition", `attachment; filename="inventory-import-template.csv"`)
 	if _, err := w.Write(csvBuffer.Bytes()); err != nil {
-		log.Printf("write import template csv response: %v", err)
+		slog.Warn("write import template csv response", "error", err)
 	}
 }
 
 func (s *Server) handleInventoryImportApply(w http.ResponseWriter, r *http.Request) {
-	if s.probe.IsRunning() {
-		util.WriteError(w, http.StatusConflict, "probing is running; stop probing before import apply")
+	dryRunQuery, err := parseBoolQuery(r, "dry_run")
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -664,15 +825,29 @@ func (s *Server) handleInventoryImportApply(w http.ResponseWriter, r *http.Reque
 		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
 		return
 	}
+	dryRun := dryRunQuery || req.DryRun
+	if !dryRun && s.probe.IsRunning() {
+		util.WriteError(w, http.StatusConflict, "probing is running; stop probing before import apply")
+		return
+	}
 	if req.PreviewID == "" {
 		util.WriteError(w, http.StatusBadRequest, "preview_id is required")
 		return
 	}
 
-	s.previewMu.RLock()
+	s.previewMu.Lock()
 	preview, ok := s.previews[req.PreviewID]
-	s.previewMu.RUnlock()
+	if ok && s.previewExpired(preview.CreatedAt) {
+		delete(s.previews, req.PreviewID)
+		s.expiredPreviews.add(req.PreviewID, time.Now().UTC())
+		ok = false
+	}
+	s.previewMu.Unlock()
 	if !ok {
+		if s.expiredPreviews.has(req.PreviewID) {
+			util.WriteError(w, http.StatusGone, "preview expired; upload the file again")
+			return
+		}
 		util.WriteError(w, http.StatusNotFound, "preview not found")
 		return
 	}
@@ -733,6 +908,10 @@ func (s *Server) handleInventoryImportApply(w http.ResponseWriter, r *http.Reque
 				util.WriteError(w, http.StatusInternalServerError, err.Error())
 				return
 			}
+			if group.IsSystem {
+				util.WriteError(w, http.StatusBadRequest, `system group "no group" cannot be targeted`)
+				return
+			}
 			assignmentGroupName = group.Name
 			assignmentRequested = true
 		case model.ImportGroupAssignmentCreate:
@@ -740,6 +919,10 @@ func (s *Server) handleInventoryImportApply(w http.ResponseWriter, r *http.Reque
 				util.WriteError(w, http.StatusBadRequest, "invalid group_assignment for create mode")
 				return
 			}
+			if strings.EqualFold(assignmentGroupName, "no group") {
+				util.WriteError(w, http.StatusBadRequest, `group name "no group" is reserved`)
+				return
+			}
 			group, err := s.store.GetGroupByNameCI(r.Context(), assignmentGroupName)
 			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 				util.WriteError(w, http.StatusInternalServerError, err.Error())
@@ -757,18 +940,45 @@ func (s *Server) handleInventoryImportApply(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	added, updated, applyErrors := s.store.ApplyImport(r.Context(), rowsToApply)
+	validUploadIPs := make([]string, 0, len(preview.Candidates))
+	for _, candidate := range preview.Candidates {
+		if candidate.Action == model.ImportInvalid {
+			continue
+		}
+		validUploadIPs = append(validUploadIPs, candidate.IP)
+	}
+	validUploadIPs = uniqueStrings(validUploadIPs)
 
-	var assignmentResult *model.ImportGroupAssignmentResult
-	if assignmentRequested {
-		validUploadIPs := make([]string, 0, len(preview.Candidates))
-		for _, candidate := range preview.Candidates {
-			if candidate.Action == model.ImportInvalid {
-				continue
+	if dryRun {
+		added, updated, applyErrors, failedRowIDs, err := s.store.DryRunImport(r.Context(), rowsToApply)
+		if err != nil {
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		var assignmentResult *model.ImportGroupAssignmentResult
+		if assignmentRequested {
+			assignmentResult = &model.ImportGroupAssignmentResult{
+				GroupID:            assignmentGroupID,
+				GroupName:          assignmentGroupName,
+				ValidUploadIPs:     len(validUploadIPs),
+				UsedExistingByName: usedExistingByName,
 			}
-			validUploadIPs = append(validUploadIPs, candidate.IP)
 		}
-		validUploadIPs = uniqueStrings(validUploadIPs)
+		util.WriteJSON(w, http.StatusOK, model.ImportApplyResponse{
+			Added:           added,
+			Updated:         updated,
+			Errors:          applyErrors,
+			GroupAssignment: assignmentResult,
+			FailedRowIDs:    failedRowIDs,
+			DryRun:          true,
+		})
+		return
+	}
+
+	added, updated, applyErrors, failedRowIDs := s.store.ApplyImport(r.Context(), rowsToApply)
+
+	var assignmentResult *model.ImportGroupAssignmentResult
+	if assignmentRequested {
 
 		if assignmentMode == model.ImportGroupAssignmentCreate && assignmentGroupID == 0 {
 			created, err := s.store.CreateGroup(r.Context(), assignmentGroupName, "", []int64{})
@@ -814,6 +1024,8 @@ func (s *Server) handleInventoryImportApply(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
+	retryID := s.retainFailedImportRows(rowsToApply, failedRowIDs, assignmentResult)
+
 	s.previewMu.Lock()
 	delete(s.previews, req.PreviewID)
 	s.previewMu.Unlock()
@@ -823,6 +1035,8 @@ func (s *Server) handleInventoryImportApply(w http.ResponseWriter, r *http.Reque
 		Updated:         updated,
 		Errors:          applyErrors,
 		GroupAssignment: assignmentResult,
+		RetryID:         retryID,
+		FailedRowIDs:    failedRowIDs,
 	})
 }
 
@@ -832,7 +1046,33 @@ func (s *Server) handleInventoryEndpointCreate(w http.ResponseWriter, r *http.Re
 		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
 		return
 	}
+	if err := normalizeInventoryEndpointCreate(&req); err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.GroupID != nil {
+		if status, err := s.checkInventoryCreateGroup(r.Context(), *req.GroupID); err != nil {
+			util.WriteError(w, status, err.Error())
+			return
+		}
+	}
+
+	item, err := s.store.CreateInventoryEndpoint(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, store.ErrEndpointIPExists) {
+			util.WriteError(w, http.StatusConflict, "inventory endpoint with this IP already exists")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSON(w, http.StatusCreated, item)
+}
 
+// normalizeInventoryEndpointCreate trims req and fills its defaults, or
+// reports the first field that is invalid.
+func normalizeInventoryEndpointCreate(req *model.InventoryEndpointCreate) error {
 	req.IPAddress = strings.TrimSpace(req.IPAddress)
 	req.Hostname = strings.TrimSpace(req.Hostname)
 	req.MACAddress = strings.TrimSpace(req.MACAddress)
@@ -856,56 +1096,54 @@ func (s *Server) handleInventoryEndpointCreate(w http.ResponseWriter, r *http.Re
 	req.CustomField8Value = strings.TrimSpace(req.CustomField8Value)
 	req.CustomField9Value = strings.TrimSpace(req.CustomField9Value)
 	req.CustomField10Value = strings.TrimSpace(req.CustomField10Value)
+	req.ProbeMode = strings.ToLower(strings.TrimSpace(req.ProbeMode))
+	req.ProbeHTTPTarget = strings.TrimSpace(req.ProbeHTTPTarget)
 
-	if req.IPAddress == "" {
-		util.WriteError(w, http.StatusBadRequest, "ip_address is required")
-		return
+	// A blank ip_address is allowed when a hostname is given; the engine
+	// resolves the hostname each round instead.
+	if req.IPAddress == "" && req.Hostname == "" {
+		return errors.New("ip_address or hostname is required")
 	}
-	if net.ParseIP(req.IPAddress) == nil {
-		util.WriteError(w, http.StatusBadRequest, "ip_address must be a valid IPv4 or IPv6 address")
-		return
+	if req.IPAddress != "" && net.ParseIP(req.IPAddress) == nil {
+		return errors.New("ip_address must be a valid IPv4 or IPv6 address")
 	}
 	if req.Hostname == "" {
 		req.Hostname = req.IPAddress
 	}
 	if req.PortType != "" && req.PortType != "access" && req.PortType != "trunk" {
-		util.WriteError(w, http.StatusBadRequest, "port_type must be access, trunk, or empty")
-		return
+		return errors.New("port_type must be access, trunk, or empty")
 	}
 	if req.Gateway != "" && net.ParseIP(req.Gateway) == nil {
-		util.WriteError(w, http.StatusBadRequest, "gateway must be a valid IPv4 or IPv6 address")
-		return
+		return errors.New("gateway must be a valid IPv4 or IPv6 address")
 	}
 	if req.MgmtIP != "" && net.ParseIP(req.MgmtIP) == nil {
-		util.WriteError(w, http.StatusBadRequest, "mgmt_ip must be a valid IPv4 or IPv6 address")
-		return
+		return errors.New("mgmt_ip must be a valid IPv4 or IPv6 address")
 	}
-	if req.GroupID != nil {
-		if *req.GroupID < 1 {
-			util.WriteError(w, http.StatusBadRequest, "group_id must be a positive integer")
-			return
-		}
-		if _, err := s.store.GetGroupByID(r.Context(), *req.GroupID); err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				util.WriteError(w, http.StatusNotFound, "group not found")
-				return
-			}
-			util.WriteError(w, http.StatusInternalServerError, err.Error())
-			return
-		}
+	if req.ProbeMode == "" {
+		req.ProbeMode = model.ProbeModeICMP
+	}
+	if err := validateProbeConfig(req.ProbeMode, req.ProbeHTTPTarget); err != nil {
+		return err
+	}
+	if err := validateProbeTCPPort(req.ProbeTCPPort); err != nil {
+		return err
+	}
+	if req.GroupID != nil && *req.GroupID < 1 {
+		return errors.New("group_id must be a positive integer")
 	}
+	return nil
+}
 
-	item, err := s.store.CreateInventoryEndpoint(r.Context(), req)
-	if err != nil {
-		if errors.Is(err, store.ErrEndpointIPExists) {
-			util.WriteError(w, http.StatusConflict, "inventory endpoint with this IP already exists")
-			return
+// checkInventoryCreateGroup confirms that a new endpoint's group exists and
+// returns the status to report when it does not.
+func (s *Server) checkInventoryCreateGroup(ctx context.Context, groupID int64) (int, error) {
+	if _, err := s.store.GetGroupByID(ctx, groupID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return http.StatusNotFound, errors.New("group not found")
 		}
-		util.WriteError(w, http.StatusInternalServerError, err.Error())
-		return
+		return http.StatusInternalServerError, err
 	}
-
-	util.WriteJSON(w, http.StatusCreated, item)
+	return http.StatusOK, nil
 }
 
 func inventoryCustomFieldValueBySlot(item model.InventoryEndpointView, slot int) string {
@@ -920,11 +1158,16 @@ func (s *Server) inventoryListQueryFromRequest(
 	if err != nil {
 		return store.InventoryListQuery{}, nil, err
 	}
+	cidrs, err := parseCIDRListQuery(r, "cidr")
+	if err != nil {
+		return store.InventoryListQuery{}, nil, err
+	}
 	filters := store.MonitorFilters{
 		VLANs:      parseCSVQuery(r, "vlan"),
 		Switches:   parseCSVQuery(r, "switch"),
 		Ports:      parseCSVQuery(r, "port"),
 		GroupNames: parseCSVQuery(r, "group"),
+		CIDRs:      cidrs,
 	}
 
 	customSearches := parseCustomSearchQuery(r)
@@ -939,6 +1182,7 @@ func (s *Server) inventoryListQueryFromRequest(
 		Filters:        filters,
 		ActivityStates: activityStates,
 		CustomSearches: customSearches,
+		Search:         strings.TrimSpace(r.URL.Query().Get("q")),
 	}, normalizeCustomFieldConfigs(settings.CustomFields), nil
 }
 
@@ -946,26 +1190,105 @@ func (s *Server) handleInventoryEndpoints(w http.ResponseWriter, r *http.Request
 	listQuery, _, err := s.inventoryListQueryFromRequest(r.Context(), r)
 	if err != nil {
 		status := http.StatusInternalServerError
-		if strings.Contains(err.Error(), "activity") {
+		if strings.Contains(err.Error(), "activity") || strings.Contains(err.Error(), "cidr") {
 			status = http.StatusBadRequest
 		}
 		util.WriteError(w, status, err.Error())
 		return
 	}
 
-	items, err := s.store.ListInventoryEndpoints(r.Context(), listQuery)
+	if !inventoryPageRequested(r) {
+		items, err := s.store.ListInventoryEndpoints(r.Context(), listQuery)
+		if err != nil {
+			util.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		util.WriteJSON(w, http.StatusOK, items)
+		return
+	}
+
+	if err := parseInventoryPageQuery(r, &listQuery); err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, totalItems, err := s.store.ListInventoryEndpointsPage(r.Context(), listQuery)
 	if err != nil {
+		if err.Error() == "invalid sort_by" {
+			util.WriteError(w, http.StatusBadRequest, "invalid sort_by")
+			return
+		}
 		util.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	util.WriteJSON(w, http.StatusOK, items)
+	util.WriteJSON(w, http.StatusOK, model.InventoryEndpointsPageResponse{
+		Items:      items,
+		Page:       listQuery.Page,
+		PageSize:   listQuery.PageSize,
+		TotalItems: totalItems,
+		TotalPages: monitorTotalPages(totalItems, listQuery.PageSize),
+		SortBy:     listQuery.SortBy,
+		SortDir:    listQuery.SortDir,
+	})
+}
+
+// inventoryPageRequested reports whether the caller asked for the paginated
+// envelope. Requests without page, page_size, sort_by, or sort_dir keep the
+// full unpaginated array, which the import diff and older clients rely on.
+func inventoryPageRequested(r *http.Request) bool {
+	query := r.URL.Query()
+	for _, key := range []string{"page", "page_size", "sort_by", "sort_dir"} {
+		if strings.TrimSpace(query.Get(key)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func parseInventoryPageQuery(r *http.Request, listQuery *store.InventoryListQuery) error {
+	page, err := parsePositiveIntQuery(r, "page", 1)
+	if err != nil {
+		return err
+	}
+	pageSize, err := parsePageSizeQuery(r)
+	if err != nil {
+		return err
+	}
+
+	sortBy := strings.TrimSpace(r.URL.Query().Get("sort_by"))
+	sortDir := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("sort_dir")))
+	switch sortBy {
+	case "", "hostname", "ip", "vlan", "switch", "updated_at":
+	default:
+		return fmt.Errorf("invalid sort_by")
+	}
+	if sortBy != "" {
+		if sortDir == "" {
+			sortDir = "asc"
+		}
+		if sortDir != "asc" && sortDir != "desc" {
+			return fmt.Errorf("sort_dir must be asc or desc")
+		}
+	} else if sortDir != "" {
+		return fmt.Errorf("sort_dir requires sort_by")
+	}
+
+	listQuery.Page = page
+	listQuery.PageSize = pageSize
+	listQuery.SortBy = sortBy
+	listQuery.SortDir = sortDir
+	return nil
 }
 
 func (s *Server) handleInventoryEndpointsExportCSV(w http.ResponseWriter, r *http.Request) {
+	redact, err := parseBoolQuery(r, "redact")
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	listQuery, customFields, err := s.inventoryListQueryFromRequest(r.Context(), r)
 	if err != nil {
 		status := http.StatusInternalServerError
-		if strings.Contains(err.Error(), "activity") {
+		if strings.Contains(err.Error(), "activity") || strings.Contains(err.Error(), "cidr") {
 			status = http.StatusBadRequest
 		}
 		util.WriteError(w, status, err.Error())
@@ -978,22 +1301,66 @@ func (s *Server) handleInventoryEndpointsExportCSV(w http.ResponseWriter, r *htt
 		return
 	}
 
-	enabledCustomFields := make([]model.CustomFieldConfig, 0, 3)
+	enabledCustomFields := enabledInventoryExportCustomFields(customFields)
+
+	var csvBuffer bytes.Buffer
+	csvWriter := csv.NewWriter(&csvBuffer)
+
+	if err := csvWriter.Write(inventoryExportHeader(enabledCustomFields)); err != nil {
+		util.WriteError(w, http.StatusInternalServerError, fmt.Sprintf("write csv header: %v", err))
+		return
+	}
+
+	var redactor *exportRedactor
+	if redact {
+		redactor = newExportRedactor()
+	}
+	for _, item := range items {
+		if redactor != nil {
+			item.Hostname = redactor.Hostname(item.Hostname)
+			item.IPAddress = redactor.IP(item.IPAddress)
+			item.Switch = redactor.Hostname(item.Switch)
+			item.Gateway = redactor.IP(item.Gateway)
+			item.MgmtIP = redactor.IP(item.MgmtIP)
+		}
+		if err := csvWriter.Write(inventoryExportRecord(item, enabledCustomFields)); err != nil {
+			util.WriteError(w, http.StatusInternalServerError, fmt.Sprintf("write csv row: %v", err))
+			return
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		util.WriteError(w, http.StatusInternalServerError, fmt.Sprintf("flush csv: %v", err))
+		return
+	}
+
+	filename := fmt.Sprintf("inventory-export-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(csvBuffer.Bytes()); err != nil {
+		slog.Warn("inventory export write response", "error", err)
+	}
+}
+
+func enabledInventoryExportCustomFields(customFields []model.CustomFieldConfig) []model.CustomFieldConfig {
+	enabled := make([]model.CustomFieldConfig, 0, 3)
 	for _, field := range customFields {
 		name := strings.TrimSpace(field.Name)
 		if !field.Enabled || name == "" {
 			continue
 		}
-		enabledCustomFields = append(enabledCustomFields, model.CustomFieldConfig{
+		enabled = append(enabled, model.CustomFieldConfig{
 			Slot:    field.Slot,
 			Enabled: true,
 			Name:    name,
 		})
 	}
+	return enabled
+}
 
-	var csvBuffer bytes.Buffer
-	csvWriter := csv.NewWriter(&csvBuffer)
-
+func inventoryExportHeader(customFields []model.CustomFieldConfig) []string {
 	header := []string{
 		"Hostname",
 		"IP Address",
@@ -1011,63 +1378,38 @@ func (s *Server) handleInventoryEndpointsExportCSV(w http.ResponseWriter, r *htt
 		"Description",
 		"Group",
 	}
-	for _, field := range enabledCustomFields {
+	for _, field := range customFields {
 		header = append(header, field.Name)
 	}
-	header = append(header, "Updated At")
-
-	if err := csvWriter.Write(header); err != nil {
-		util.WriteError(w, http.StatusInternalServerError, fmt.Sprintf("write csv header: %v", err))
-		return
-	}
-
-	for _, item := range items {
-		record := []string{
-			item.Hostname,
-			item.IPAddress,
-			func() string {
-				if item.Active {
-					return "Active"
-				}
-				return "Inactive"
-			}(),
-			item.MACAddress,
-			item.VLAN,
-			item.Zone,
-			item.Switch,
-			item.Port,
-			item.PortType,
-			item.Gateway,
-			item.MgmtIP,
-			item.Speed,
-			item.Duplex,
-			item.Description,
-			strings.Join(item.Groups, ", "),
-		}
-		for _, field := range enabledCustomFields {
-			record = append(record, inventoryCustomFieldValueBySlot(item, field.Slot))
-		}
-		record = append(record, item.UpdatedAt.UTC().Format(time.RFC3339))
-
-		if err := csvWriter.Write(record); err != nil {
-			util.WriteError(w, http.StatusInternalServerError, fmt.Sprintf("write csv row: %v", err))
-			return
-		}
-	}
-
-	csvWriter.Flush()
-	if err := csvWriter.Error(); err != nil {
-		util.WriteError(w, http.StatusInternalServerError, fmt.Sprintf("flush csv: %v", err))
-		return
+	return append(header, "Updated At")
+}
+
+func inventoryExportRecord(item model.InventoryEndpointView, customFields []model.CustomFieldConfig) []string {
+	state := "Inactive"
+	if item.Active {
+		state = "Active"
+	}
+	record := []string{
+		item.Hostname,
+		item.IPAddress,
+		state,
+		item.MACAddress,
+		item.VLAN,
+		item.Zone,
+		item.Switch,
+		item.Port,
+		item.PortType,
+		item.Gateway,
+		item.MgmtIP,
+		item.Speed,
+		item.Duplex,
+		item.Description,
+		strings.Join(item.Groups, ", "),
 	}
-
-	filename := fmt.Sprintf("inventory-export-%s.csv", time.Now().UTC().Format("20060102-150405"))
-	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
-	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write(csvBuffer.Bytes()); err != nil {
-		log.Printf("inventory export write response: %v", err)
+	for _, field := range customFields {
+		record = append(record, inventoryCustomFieldValueBySlot(item, field.Slot))
 	}
+	return append(record, item.UpdatedAt.UTC().Format(time.RFC3339))
 }
 
 func (s *Server) handleInventoryEndpointActivityUpdate(w http.ResponseWriter, r *http.Request) {
@@ -1144,6 +1486,34 @@ func (s *Server) handleInventoryEndpointUpdate(w http.ResponseWriter, r *http.Re
 		util.WriteError(w, http.StatusBadRequest, "mgmt_ip must be a valid IPv4 or IPv6 address")
 		return
 	}
+	if patch.ProbeMode != nil {
+		mode := strings.ToLower(strings.TrimSpace(*patch.ProbeMode))
+		patch.ProbeMode = &mode
+		if err := validateProbeConfig(mode, ""); err != nil {
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if patch.ProbeHTTPTarget != nil {
+		target := strings.TrimSpace(*patch.ProbeHTTPTarget)
+		patch.ProbeHTTPTarget = &target
+		if err := validateProbeConfig(model.ProbeModeHTTP, target); err != nil {
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if patch.ProbeTCPPort != nil {
+		if err := validateProbeTCPPort(*patch.ProbeTCPPort); err != nil {
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if patch.ProbeIntervalSec != nil {
+		if err := validateProbeIntervalSec(*patch.ProbeIntervalSec); err != nil {
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	item, err := s.store.UpdateInventoryEndpoint(r.Context(), endpointID, patch)
 	if err != nil {
@@ -1158,6 +1528,39 @@ func (s *Server) handleInventoryEndpointUpdate(w http.ResponseWriter, r *http.Re
 	util.WriteJSON(w, http.StatusOK, item)
 }
 
+func validateProbeConfig(mode, httpTarget string) error {
+	switch mode {
+	case model.ProbeModeICMP, model.ProbeModeHTTP, model.ProbeModeHTTPS, model.ProbeModeTCP, model.ProbeModeICMPTCP:
+	default:
+		return errors.New("probe_mode must be icmp, http, https, tcp, or icmp_tcp")
+	}
+	if !strings.Contains(httpTarget, "://") {
+		return nil
+	}
+	parsed, err := url.Parse(httpTarget)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("probe_http_target must be a path or an absolute http(s) URL")
+	}
+	return nil
+}
+
+// validateProbeTCPPort accepts 0, meaning the global tcp_port setting applies.
+func validateProbeTCPPort(port int) error {
+	if port < 0 || port > 65535 {
+		return errors.New("probe_tcp_port must be between 0 and 65535")
+	}
+	return nil
+}
+
+// validateProbeIntervalSec accepts 0, which clears the override so the global
+// ping_interval_sec applies again.
+func validateProbeIntervalSec(intervalSec int) error {
+	if intervalSec < 0 || intervalSec > 3600 {
+		return errors.New("probe_interval_sec must be between 1 and 3600, or 0 to clear")
+	}
+	return nil
+}
+
 func buildEndpointDeleteTargetSummary(endpoint model.InventoryEndpointView) string {
 	ipAddress := strings.TrimSpace(endpoint.IPAddress)
 	hostname := strings.TrimSpace(endpoint.Hostname)
@@ -1170,6 +1573,32 @@ func buildEndpointDeleteTargetSummary(endpoint model.InventoryEndpointView) stri
 	return fmt.Sprintf("Endpoint %d", endpoint.EndpointID)
 }
 
+func (s *Server) handleInventoryEndpointHistory(w http.ResponseWriter, r *http.Request) {
+	endpointID, err := strconv.ParseInt(chi.URLParam(r, "endpointID"), 10, 64)
+	if err != nil || endpointID < 1 {
+		util.WriteError(w, http.StatusBadRequest, "invalid endpoint id")
+		return
+	}
+	limit, err := parsePositiveIntQuery(r, "limit", 200)
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	items, err := s.store.ListInventoryEndpointHistory(r.Context(), endpointID, limit)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"endpoint_id": endpointID,
+		"items":       items,
+	})
+}
+
 func (s *Server) handleInventoryEndpointDelete(w http.ResponseWriter, r *http.Request) {
 	s.handleInventoryDeleteJobByEndpoint(w, r)
 }
@@ -1275,7 +1704,7 @@ func (s *Server) handleInventoryDeleteJobCurrent(w http.ResponseWriter, _ *http.
 }
 
 func (s *Server) handleInventoryFilters(w http.ResponseWriter, r *http.Request) {
-	filters, err := s.store.ListDistinctFilters(r.Context(), false)
+	filters, err := s.listFilterOptions(r, false)
 	if err != nil {
 		util.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -1388,6 +1817,7 @@ func (s *Server) handleProbeStart(w http.ResponseWriter, r *http.Request) {
 	type request struct {
 		Scope    string  `json:"scope"`
 		GroupIDs []int64 `json:"group_ids"`
+		GroupID  *int64  `json:"group_id"`
 	}
 	var req request
 	if err := util.DecodeJSON(r, &req); err != nil {
@@ -1403,6 +1833,10 @@ func (s *Server) handleProbeStart(w http.ResponseWriter, r *http.Request) {
 		util.WriteError(w, http.StatusConflict, "inventory deletion in progress; probing is temporarily disabled")
 		return
 	}
+	if req.GroupID != nil {
+		s.startGroupSchedule(w, r, *req.GroupID)
+		return
+	}
 	if err := s.probe.Start(req.Scope, req.GroupIDs); err != nil {
 		util.WriteError(w, http.StatusBadRequest, err.Error())
 		return
@@ -1420,36 +1854,120 @@ func (s *Server) handleProbeStart(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (s *Server) handleProbeStatus(w http.ResponseWriter, _ *http.Request) {
+// startGroupSchedule adds a schedule for one group without touching the
+// ones already running.
+func (s *Server) startGroupSchedule(w http.ResponseWriter, r *http.Request, groupID int64) {
+	if groupID < 1 {
+		util.WriteError(w, http.StatusBadRequest, "group_id must be positive")
+		return
+	}
+	if _, err := s.store.GetGroupByID(r.Context(), groupID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.WriteError(w, http.StatusNotFound, "group not found")
+			return
+		}
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := s.probe.StartGroup(groupID); err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	status := s.probe.Status()
 	util.WriteJSON(w, http.StatusOK, map[string]any{
 		"running":   status.Running,
 		"scope":     status.Scope,
 		"group_ids": status.GroupIDs,
+		"schedules": probeScheduleResponses(status.Schedules),
+	})
+}
+
+func probeScheduleResponses(schedules []probe.ScheduleStatus) []map[string]any {
+	items := make([]map[string]any, 0, len(schedules))
+	for _, schedule := range schedules {
+		items = append(items, map[string]any{
+			"scope":                  schedule.Scope,
+			"group_ids":              schedule.GroupIDs,
+			"started_at":             schedule.StartedAt,
+			"total_rounds":           schedule.TotalRounds,
+			"overrun_rounds":         schedule.OverrunRounds,
+			"last_round_duration_ms": schedule.LastRoundDuration.Milliseconds(),
+			"last_round_targets":     schedule.LastRoundTargets,
+			"avg_round_duration_ms":  schedule.AvgRoundDuration.Milliseconds(),
+		})
+	}
+	return items
+}
+
+func (s *Server) handleProbeStatus(w http.ResponseWriter, _ *http.Request) {
+	status := s.probe.Status()
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"running":                status.Running,
+		"scope":                  status.Scope,
+		"group_ids":              status.GroupIDs,
+		"schedules":              probeScheduleResponses(status.Schedules),
+		"total_rounds":           status.TotalRounds,
+		"overrun_rounds":         status.OverrunRounds,
+		"last_round_duration_ms": status.LastRoundDuration.Milliseconds(),
+		"last_round_targets":     status.LastRoundTargets,
+		"avg_round_duration_ms":  status.AvgRoundDuration.Milliseconds(),
+		"effective_timeout_ms":   status.EffectiveTimeoutMs,
 	})
 }
 
-func (s *Server) handleProbeStop(w http.ResponseWriter, _ *http.Request) {
+func (s *Server) handleProbeStop(w http.ResponseWriter, r *http.Request) {
+	if raw := strings.TrimSpace(r.URL.Query().Get("group_id")); raw != "" {
+		groupID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || groupID < 1 {
+			util.WriteError(w, http.StatusBadRequest, "invalid group_id")
+			return
+		}
+		stopped := s.probe.StopGroup(groupID)
+		util.WriteJSON(w, http.StatusOK, map[string]any{"running": s.probe.IsRunning(), "stopped": stopped})
+		return
+	}
+
 	stopped := s.probe.Stop()
 	util.WriteJSON(w, http.StatusOK, map[string]any{"running": false, "stopped": stopped})
 }
 
+func (s *Server) handleTelemetryClients(w http.ResponseWriter, _ *http.Request) {
+	clients := s.hub.Clients()
+	util.WriteJSON(w, http.StatusOK, map[string]any{
+		"count":            len(clients),
+		"clients":          clients,
+		"dropped_messages": s.hub.DroppedMessages(),
+		"evicted_clients":  s.hub.EvictedClients(),
+	})
+}
+
 func (s *Server) handleGetSettings(w http.ResponseWriter, r *http.Request) {
 	settings, err := s.store.GetSettings(r.Context())
 	if err != nil {
 		util.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	settings.AlertWebhookConfigured = settings.AlertWebhookURL != ""
 	util.WriteJSON(w, http.StatusOK, settings)
 }
 
 func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 	type settingsPatch struct {
-		PingIntervalSec *int                `json:"ping_interval_sec"`
-		ICMPPayloadSize *int                `json:"icmp_payload_bytes"`
-		ICMPTimeoutMs   *int                `json:"icmp_timeout_ms"`
-		AutoRefreshSec  *int                `json:"auto_refresh_sec"`
-		CustomFields    *[]customFieldPatch `json:"custom_fields"`
+		PingIntervalSec        *int                `json:"ping_interval_sec"`
+		ICMPPayloadSize        *int                `json:"icmp_payload_bytes"`
+		ICMPTimeoutMs          *int                `json:"icmp_timeout_ms"`
+		AutoRefreshSec         *int                `json:"auto_refresh_sec"`
+		CustomFields           *[]customFieldPatch `json:"custom_fields"`
+		AlertConsecutiveFailed *int                `json:"alert_consecutive_failed"`
+		AlertFailedPct         *float64            `json:"alert_failed_pct"`
+		AlertWebhookURL        *string             `json:"alert_webhook_url"`
+		ProbeMode              *string             `json:"probe_mode"`
+		TCPPort                *int                `json:"tcp_port"`
+		ProbesPerRound         *int                `json:"probes_per_round"`
+		DownThreshold          *int                `json:"down_threshold"`
+		PayloadPattern         *string             `json:"payload_pattern"`
+		ProbeRetries           *int                `json:"probe_retries"`
 	}
 
 	var patch settingsPatch
@@ -1475,6 +1993,33 @@ func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 	if patch.AutoRefreshSec != nil {
 		settings.AutoRefreshSec = *patch.AutoRefreshSec
 	}
+	if patch.AlertConsecutiveFailed != nil {
+		settings.AlertConsecutiveFailed = *patch.AlertConsecutiveFailed
+	}
+	if patch.AlertFailedPct != nil {
+		settings.AlertFailedPct = *patch.AlertFailedPct
+	}
+	if patch.AlertWebhookURL != nil {
+		settings.AlertWebhookURL = strings.TrimSpace(*patch.AlertWebhookURL)
+	}
+	if patch.ProbeMode != nil {
+		settings.ProbeMode = strings.ToLower(strings.TrimSpace(*patch.ProbeMode))
+	}
+	if patch.TCPPort != nil {
+		settings.TCPPort = *patch.TCPPort
+	}
+	if patch.ProbesPerRound != nil {
+		settings.ProbesPerRound = *patch.ProbesPerRound
+	}
+	if patch.DownThreshold != nil {
+		settings.DownThreshold = *patch.DownThreshold
+	}
+	if patch.ProbeRetries != nil {
+		settings.ProbeRetries = *patch.ProbeRetries
+	}
+	if patch.PayloadPattern != nil {
+		settings.PayloadPattern = strings.ToLower(strings.TrimSpace(*patch.PayloadPattern))
+	}
 	settings.CustomFields = normalizeCustomFieldConfigs(settings.CustomFields)
 	if patch.CustomFields != nil {
 		mergedCustomFields, err := mergeCustomFieldPatch(settings.CustomFields, *patch.CustomFields)
@@ -1494,10 +2039,35 @@ func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 		settings.ICMPPayloadSize,
 		settings.AutoRefreshSec,
 		settings.ICMPTimeoutMs,
+		settings.PayloadPattern,
 	); err != nil {
 		util.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := config.ValidateAlertThresholds(settings.AlertConsecutiveFailed, settings.AlertFailedPct); err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := config.ValidateAlertWebhook(settings.AlertWebhookURL); err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := config.ValidateProbeSettings(settings.ProbeMode, settings.TCPPort); err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := config.ValidateProbesPerRound(settings.ProbesPerRound, settings.ICMPTimeoutMs, settings.PingIntervalSec); err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := config.ValidateProbeRetries(settings.ProbeRetries, settings.ProbesPerRound, settings.ICMPTimeoutMs, settings.PingIntervalSec); err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := config.ValidateDownThreshold(settings.DownThreshold); err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	if err := s.store.UpdateSettings(r.Context(), settings); err != nil {
 		util.WriteError(w, http.StatusInternalServerError, err.Error())
@@ -1505,6 +2075,10 @@ func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.probe.UpdateSettings(settings)
+	if s.alerts != nil {
+		s.alerts.Configure(settings.AlertWebhookURL)
+	}
+	settings.AlertWebhookConfigured = settings.AlertWebhookURL != ""
 	util.WriteJSON(w, http.StatusOK, settings)
 }
 
@@ -1589,11 +2163,16 @@ func (s *Server) handleSwitchDirectoryImportTemplateCSV(w http.ResponseWriter, _
 	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
 	w.Header().Set("Content-Disposition", `attachment; filename="switch-directory-import-template.csv"`)
 	if _, err := w.Write(csvBuffer.Bytes()); err != nil {
-		log.Printf("write switch directory import template response: %v", err)
+		slog.Warn("write switch directory import template response", "error", err)
 	}
 }
 
 func (s *Server) handleSwitchDirectoryExportCSV(w http.ResponseWriter, r *http.Request) {
+	redact, err := parseBoolQuery(r, "redact")
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	items, err := s.store.ListSwitchDirectory(r.Context())
 	if err != nil {
 		util.WriteError(w, http.StatusInternalServerError, err.Error())
@@ -1608,7 +2187,15 @@ func (s *Server) handleSwitchDirectoryExportCSV(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	var redactor *exportRedactor
+	if redact {
+		redactor = newExportRedactor()
+	}
 	for _, item := range items {
+		if redactor != nil {
+			item.Name = redactor.Hostname(item.Name)
+			item.IPAddress = redactor.IP(item.IPAddress)
+		}
 		if err := csvWriter.Write([]string{item.Name, item.IPAddress}); err != nil {
 			util.WriteError(w, http.StatusInternalServerError, fmt.Sprintf("write csv row: %v", err))
 			return
@@ -1626,7 +2213,7 @@ func (s *Server) handleSwitchDirectoryExportCSV(w http.ResponseWriter, r *http.R
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
 	w.WriteHeader(http.StatusOK)
 	if _, err := w.Write(csvBuffer.Bytes()); err != nil {
-		log.Printf("switch directory export write response: %v", err)
+		slog.Warn("switch directory export write response", "error", err)
 	}
 }
 
@@ -1672,9 +2259,7 @@ func (s *Server) handleSwitchDirectoryImportPreview(w http.ResponseWriter, r *ht
 		Candidates: importer.ClassifySwitchDirectoryImport(rows, existing),
 	}
 
-	s.switchPreviewMu.Lock()
-	s.switchPreviews[preview.PreviewID] = preview
-	s.switchPreviewMu.Unlock()
+	s.storeSwitchPreview(preview)
 
 	util.WriteJSON(w, http.StatusOK, preview)
 }
@@ -1712,10 +2297,19 @@ func (s *Server) handleSwitchDirectoryImportApply(w http.ResponseWriter, r *http
 		return
 	}
 
-	s.switchPreviewMu.RLock()
+	s.switchPreviewMu.Lock()
 	preview, ok := s.switchPreviews[req.PreviewID]
-	s.switchPreviewMu.RUnlock()
+	if ok && s.previewExpired(preview.CreatedAt) {
+		delete(s.switchPreviews, req.PreviewID)
+		s.expiredPreviews.add(req.PreviewID, time.Now().UTC())
+		ok = false
+	}
+	s.switchPreviewMu.Unlock()
 	if !ok {
+		if s.expiredPreviews.has(req.PreviewID) {
+			util.WriteError(w, http.StatusGone, "preview expired; upload the file again")
+			return
+		}
 		util.WriteError(w, http.StatusNotFound, "preview not found")
 		return
 	}
@@ -1771,6 +2365,8 @@ func (s *Server) handleSwitchDirectoryImportApply(w http.ResponseWriter, r *http
 type monitorRequestOptions struct {
 	includePagination bool
 	includeSort       bool
+	// includeCursor accepts pagination=cursor and cursor for keyset pages.
+	includeCursor bool
 }
 
 type monitorRequestParseError struct {
@@ -1819,6 +2415,15 @@ func (s *Server) monitorPageQueryFromRequest(
 		Switches:   parseCSVQuery(r, "switch"),
 		Ports:      parseCSVQuery(r, "port"),
 		GroupNames: parseCSVQuery(r, "group"),
+		Status:     strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status"))),
+	}
+	switch query.Filters.Status {
+	case "", store.MonitorStatusUp, store.MonitorStatusDown, store.MonitorStatusFlapping:
+	default:
+		return store.MonitorPageQuery{}, &monitorRequestParseError{
+			Status:  http.StatusBadRequest,
+			Message: "status must be up, down, or flapping",
+		}
 	}
 
 	if options.includePagination {
@@ -1826,16 +2431,10 @@ func (s *Server) monitorPageQueryFromRequest(
 		if err != nil {
 			return store.MonitorPageQuery{}, &monitorRequestParseError{Status: http.StatusBadRequest, Message: err.Error()}
 		}
-		pageSize, err := parsePositiveIntQuery(r, "page_size", 100)
+		pageSize, err := parsePageSizeQuery(r)
 		if err != nil {
 			return store.MonitorPageQuery{}, &monitorRequestParseError{Status: http.StatusBadRequest, Message: err.Error()}
 		}
-		if pageSize != 50 && pageSize != 100 && pageSize != 200 {
-			return store.MonitorPageQuery{}, &monitorRequestParseError{
-				Status:  http.StatusBadRequest,
-				Message: "page_size must be one of 50, 100, 200",
-			}
-		}
 		query.Page = page
 		query.PageSize = pageSize
 	}
@@ -1909,6 +2508,7 @@ func (s *Server) monitorPageQueryFromRequest(
 
 	query.Hostname = strings.TrimSpace(r.URL.Query().Get("hostname"))
 	query.MAC = strings.TrimSpace(r.URL.Query().Get("mac"))
+	query.Search = strings.TrimSpace(r.URL.Query().Get("q"))
 	query.CustomSearches = parseCustomSearchQuery(r)
 
 	ipList, err := parseIPListQuery(r, "ip_list")
@@ -1919,6 +2519,13 @@ func (s *Server) monitorPageQueryFromRequest(
 		}
 	}
 	query.IPList = ipList
+	query.Filters.CIDRs, err = parseCIDRListQuery(r, "cidr")
+	if err != nil {
+		return store.MonitorPageQuery{}, &monitorRequestParseError{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	}
 	query.ExcludeEndpointIDs = uniqueInt64(parseInt64CSVQuery(r, "exclude_endpoint_ids"))
 
 	settings, err := s.store.GetSettings(r.Context())
@@ -1965,15 +2572,65 @@ func (s *Server) monitorPageQueryFromRequest(
 		query.End = end
 	}
 
+	excludeMaintenance, err := parseBoolQuery(r, "exclude_maintenance")
+	if err != nil {
+		return store.MonitorPageQuery{}, &monitorRequestParseError{Status: http.StatusBadRequest, Message: err.Error()}
+	}
+	if excludeMaintenance && statsScope != "range" {
+		return store.MonitorPageQuery{}, &monitorRequestParseError{
+			Status:  http.StatusBadRequest,
+			Message: "exclude_maintenance is only supported when stats_scope=range",
+		}
+	}
+	query.ExcludeMaintenance = excludeMaintenance
+
+	if options.includeCursor {
+		pagination := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("pagination")))
+		cursor := strings.TrimSpace(r.URL.Query().Get("cursor"))
+		if pagination != "" && pagination != "offset" && pagination != "cursor" {
+			return store.MonitorPageQuery{}, &monitorRequestParseError{
+				Status:  http.StatusBadRequest,
+				Message: "pagination must be offset or cursor",
+			}
+		}
+		if pagination == "cursor" || (pagination == "" && cursor != "") {
+			if statsScope != "live" {
+				return store.MonitorPageQuery{}, &monitorRequestParseError{
+					Status:  http.StatusBadRequest,
+					Message: "cursor pagination is only supported when stats_scope=live",
+				}
+			}
+			if len(query.SortCriteria) > 1 {
+				return store.MonitorPageQuery{}, &monitorRequestParseError{
+					Status:  http.StatusBadRequest,
+					Message: "cursor pagination supports at most one sort column",
+				}
+			}
+			query.CursorMode = true
+			query.Cursor = cursor
+		} else if cursor != "" {
+			return store.MonitorPageQuery{}, &monitorRequestParseError{
+				Status:  http.StatusBadRequest,
+				Message: "cursor requires pagination=cursor",
+			}
+		}
+	}
+
 	return query, nil
 }
 
 func (s *Server) handleMonitorEndpoints(w http.ResponseWriter, r *http.Request) {
+	cidrs, err := parseCIDRListQuery(r, "cidr")
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	filters := store.MonitorFilters{
 		VLANs:      parseCSVQuery(r, "vlan"),
 		Switches:   parseCSVQuery(r, "switch"),
 		Ports:      parseCSVQuery(r, "port"),
 		GroupNames: parseCSVQuery(r, "group"),
+		CIDRs:      cidrs,
 	}
 
 	items, err := s.store.ListMonitorEndpoints(r.Context(), filters)
@@ -1988,34 +2645,39 @@ func (s *Server) handleMonitorEndpointsPage(w http.ResponseWriter, r *http.Reque
 	query, parseErr := s.monitorPageQueryFromRequest(r, monitorRequestOptions{
 		includePagination: true,
 		includeSort:       true,
+		includeCursor:     true,
 	})
 	if parseErr != nil {
 		util.WriteError(w, parseErr.Status, parseErr.Message)
 		return
 	}
 
-	items, totalItems, err := s.store.ListMonitorEndpointsPage(r.Context(), query)
+	var (
+		items      []model.MonitorEndpoint
+		totalItems int64
+		nextCursor string
+		err        error
+	)
+	if query.CursorMode {
+		items, totalItems, nextCursor, err = s.store.ListMonitorEndpointsPageCursor(r.Context(), query)
+	} else {
+		items, totalItems, err = s.store.ListMonitorEndpointsPage(r.Context(), query)
+	}
 	if err != nil {
 		if err.Error() == "invalid sort_by" {
 			util.WriteError(w, http.StatusBadRequest, "invalid sort_by")
 			return
 		}
+		if errors.Is(err, store.ErrInvalidMonitorCursor) {
+			util.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		util.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-
-	totalPages := int((totalItems + int64(query.PageSize) - 1) / int64(query.PageSize))
-	if totalItems == 0 {
-		totalPages = 0
-	}
-
-	rangeRollup := ""
-	if query.StatsScope == "range" {
-		if query.End.Sub(query.Start) > 48*time.Hour {
-			rangeRollup = "1h"
-		} else {
-			rangeRollup = "1m"
-		}
+	if query.CursorMode {
+		// Page numbers do not apply to keyset pages.
+		query.Page = 0
 	}
 
 	util.WriteJSON(w, http.StatusOK, model.MonitorEndpointsPageResponse{
@@ -2023,14 +2685,30 @@ func (s *Server) handleMonitorEndpointsPage(w http.ResponseWriter, r *http.Reque
 		Page:        query.Page,
 		PageSize:    query.PageSize,
 		TotalItems:  totalItems,
-		TotalPages:  totalPages,
+		TotalPages:  monitorTotalPages(totalItems, query.PageSize),
 		SortBy:      query.SortBy,
 		SortDir:     query.SortDir,
 		StatsScope:  query.StatsScope,
-		RangeRollup: rangeRollup,
+		RangeRollup: monitorRangeRollup(query),
+		NextCursor:  nextCursor,
 	})
 }
 
+func monitorTotalPages(totalItems int64, pageSize int) int {
+	if totalItems == 0 || pageSize < 1 {
+		return 0
+	}
+	return int((totalItems + int64(pageSize) - 1) / int64(pageSize))
+}
+
+func monitorRangeRollup(query store.MonitorPageQuery) string {
+	if query.StatsScope != "range" {
+		return ""
+	}
+	_, rollup := store.RollupForRange(query.Start, query.End)
+	return rollup
+}
+
 func (s *Server) handleMonitorSwitchIPs(w http.ResponseWriter, r *http.Request) {
 	items, err := s.store.GetSwitchIPMap(r.Context())
 	if err != nil {
@@ -2055,6 +2733,21 @@ func (s *Server) handleMonitorDashboardSummary(w http.ResponseWriter, r *http.Re
 	util.WriteJSON(w, http.StatusOK, summary)
 }
 
+func (s *Server) handleMonitorGroupSummaries(w http.ResponseWriter, r *http.Request) {
+	query, parseErr := s.monitorPageQueryFromRequest(r, monitorRequestOptions{})
+	if parseErr != nil {
+		util.WriteError(w, parseErr.Status, parseErr.Message)
+		return
+	}
+
+	items, err := s.store.MonitorGroupSummaries(r.Context(), query)
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, items)
+}
+
 func (s *Server) handleMonitorTimeSeries(w http.ResponseWriter, r *http.Request) {
 	endpointIDs := parseInt64CSVQuery(r, "endpoint_ids")
 	if len(endpointIDs) == 0 {
@@ -2069,12 +2762,7 @@ func (s *Server) handleMonitorTimeSeries(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	rollup := "1m"
-	if end.Sub(start) > 48*time.Hour {
-		rollup = "1h"
-	}
-
-	series, err := s.store.QueryTimeSeries(r.Context(), endpointIDs, start, end, rollup)
+	series, rollup, err := s.store.QueryTimeSeries(r.Context(), endpointIDs, start, end)
 	if err != nil {
 		util.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -2086,7 +2774,16 @@ func (s *Server) handleMonitorTimeSeries(w http.ResponseWriter, r *http.Request)
 }
 
 func (s *Server) handleMonitorFilters(w http.ResponseWriter, r *http.Request) {
-	filters, err := s.store.ListDistinctFilters(r.Context(), true)
+	withCounts, err := parseBoolQuery(r, "with_counts")
+	if err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if withCounts {
+		s.handleMonitorFilterCounts(w, r)
+		return
+	}
+	filters, err := s.listFilterOptions(r, true)
 	if err != nil {
 		util.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -2110,8 +2807,8 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 			}
 		}
 
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -2191,6 +2888,28 @@ func parsePositiveIntQuery(r *http.Request, key string, fallback int) (int, erro
 	return value, nil
 }
 
+// parsePageSizeQuery reads page_size for the paginated monitor and inventory
+// lists: defaultPageSize when absent, otherwise 1 to maxPageSize.
+func parsePageSizeQuery(r *http.Request) (int, error) {
+	pageSize, err := parsePositiveIntQuery(r, "page_size", defaultPageSize)
+	if err != nil || pageSize > maxPageSize {
+		return 0, fmt.Errorf("page_size must be between 1 and %d", maxPageSize)
+	}
+	return pageSize, nil
+}
+
+func parseBoolQuery(r *http.Request, key string) (bool, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", key)
+	}
+	return value, nil
+}
+
 func parseIPListQuery(r *http.Request, key string) ([]string, error) {
 	raw := strings.TrimSpace(r.URL.Query().Get(key))
 	if raw == "" {
@@ -2224,6 +2943,40 @@ func parseIPListQuery(r *http.Request, key string) ([]string, error) {
 	return out, nil
 }
 
+// parseCIDRListQuery reads comma or whitespace separated prefixes such as
+// 10.20.0.0/16. Host bits are masked off, since Postgres rejects them in a
+// cidr value.
+func parseCIDRListQuery(r *http.Request, key string) ([]string, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	if raw == "" {
+		return nil, nil
+	}
+
+	parts := strings.FieldsFunc(raw, func(ch rune) bool {
+		return ch == ',' || ch == '\n' || ch == '\r' || ch == '\t' || ch == ' '
+	})
+
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		_, network, err := net.ParseCIDR(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr: %s", part)
+		}
+		prefix := network.String()
+		if _, ok := seen[prefix]; ok {
+			continue
+		}
+		seen[prefix] = struct{}{}
+		out = append(out, prefix)
+	}
+
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
 func storeMonitorSortExpression(sortBy string) (string, error) {
 	switch sortBy {
 	case "",
@@ -2237,7 +2990,13 @@ func storeMonitorSortExpression(sortBy string) (string, error) {
 		"failed_pct",
 		"last_ping_status",
 		"last_ping_latency",
-		"average_latency":
+		"average_latency",
+		"hostname",
+		"ip",
+		"vlan",
+		"switch",
+		"port",
+		"mac":
 		return sortBy, nil
 	default:
 		return "", fmt.Errorf("invalid sort_by")
@@ -2253,7 +3012,16 @@ func storeMonitorRangeSortExpression(sortBy string) (string, error) {
 		"failed_count",
 		"last_ping_status",
 		"failed_pct",
-		"average_latency":
+		"average_latency",
+		"p95_latency",
+		"p99_latency",
+		"ip_mismatch_count",
+		"hostname",
+		"ip",
+		"vlan",
+		"switch",
+		"port",
+		"mac":
 		return sortBy, nil
 	default:
 		return "", fmt.Errorf("invalid sort_by")
@@ -0,0 +1,1244 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/config"
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/probe"
+	"sonarscope/backend/internal/store"
+)
+
+// memoryStore is an in-memory serverStore for handler tests. Methods that a
+// test does not override panic through the nil embedded interface.
+type memoryStore struct {
+	serverStore
+
+	mu         sync.Mutex
+	settings   model.Settings
+	pages      []model.MonitorEndpoint
+	snapshots  map[string]model.MonitorSnapshot
+	history    map[int64][]model.InventoryAuditEntry
+	groups     map[int64][]model.Group
+	kpis       model.MonitorFleetKPIs
+	groupStats []model.MonitorGroupSummary
+	kpiCalls   int
+	endpoints  []model.InventoryEndpointView
+	groupScope map[int64][]int64
+	namedGroup []model.Group
+	intervals  map[int64]*int
+	stats      []model.EndpointStatsSnapshot
+	lastSeen   []model.LastSeenEndpoint
+	incidents  []model.DowntimeIncident
+	rawPings   []model.RawPingSample
+	lastIDs    []int64
+	resetIDs   []int64
+	recomputed [][]int64
+	retention  model.RetentionSettings
+	windows    []model.MaintenanceWindow
+	slaExclude bool
+	lastQuery  store.MonitorPageQuery
+	lastFilter store.MonitorFilters
+	lastList   store.InventoryListQuery
+	filters    map[string][]string
+	filterHits int
+	applyFail  map[string]int
+	applied    []string
+	pageErr    error
+	settingErr error
+	pingErr    error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		settings: model.Settings{
+			PingIntervalSec: 1,
+			ICMPPayloadSize: 56,
+			ICMPTimeoutMs:   500,
+			AutoRefreshSec:  30,
+		},
+		retention: model.RetentionSettings{PingRawDays: 30, Ping1mDays: 365, Ping1hDays: 730},
+		snapshots: map[string]model.MonitorSnapshot{},
+		history:   map[int64][]model.InventoryAuditEntry{},
+		groups:    map[int64][]model.Group{},
+	}
+}
+
+func (m *memoryStore) GetSettings(context.Context) (model.Settings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.settings, m.settingErr
+}
+
+func (m *memoryStore) GetRetentionSettings(context.Context) (model.RetentionSettings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.retention, nil
+}
+
+func (m *memoryStore) UpdateRetentionSettings(_ context.Context, settings model.RetentionSettings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retention = settings
+	return nil
+}
+
+func (m *memoryStore) ListMaintenanceWindows(context.Context) ([]model.MaintenanceWindow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]model.MaintenanceWindow{}, m.windows...), nil
+}
+
+func (m *memoryStore) GetMaintenanceWindow(_ context.Context, id int64) (model.MaintenanceWindow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, window := range m.windows {
+		if window.ID == id {
+			return window, nil
+		}
+	}
+	return model.MaintenanceWindow{}, store.ErrMaintenanceWindowNotFound
+}
+
+func maintenanceWindowFromInput(id int64, input model.MaintenanceWindowInput) model.MaintenanceWindow {
+	return model.MaintenanceWindow{
+		ID:         id,
+		Name:       input.Name,
+		Scope:      input.Scope,
+		EndpointID: input.EndpointID,
+		GroupID:    input.GroupID,
+		StartsAt:   input.StartsAt,
+		EndsAt:     input.EndsAt,
+		Recurrence: input.Recurrence,
+		Timezone:   input.Timezone,
+	}
+}
+
+func (m *memoryStore) CreateMaintenanceWindow(_ context.Context, input model.MaintenanceWindowInput) (model.MaintenanceWindow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	window := maintenanceWindowFromInput(int64(len(m.windows)+1), input)
+	m.windows = append(m.windows, window)
+	return window, nil
+}
+
+func (m *memoryStore) UpdateMaintenanceWindow(_ context.Context, id int64, input model.MaintenanceWindowInput) (model.MaintenanceWindow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.windows {
+		if m.windows[i].ID == id {
+			m.windows[i] = maintenanceWindowFromInput(id, input)
+			return m.windows[i], nil
+		}
+	}
+	return model.MaintenanceWindow{}, store.ErrMaintenanceWindowNotFound
+}
+
+func (m *memoryStore) DeleteMaintenanceWindow(_ context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.windows {
+		if m.windows[i].ID == id {
+			m.windows = append(m.windows[:i], m.windows[i+1:]...)
+			return nil
+		}
+	}
+	return store.ErrMaintenanceWindowNotFound
+}
+
+func (m *memoryStore) Ping(context.Context) error {
+	return m.pingErr
+}
+
+func (m *memoryStore) PoolStats() model.DBPoolStats {
+	return model.DBPoolStats{TotalConns: 2, IdleConns: 2, MaxConns: 4}
+}
+
+func (m *memoryStore) ListMonitorEndpointsPage(_ context.Context, query store.MonitorPageQuery) ([]model.MonitorEndpoint, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastQuery = query
+	if m.pageErr != nil {
+		return nil, 0, m.pageErr
+	}
+	return append([]model.MonitorEndpoint{}, m.pages...), int64(len(m.pages)), nil
+}
+
+func (m *memoryStore) ListMonitorEndpointsPageCursor(_ context.Context, query store.MonitorPageQuery) ([]model.MonitorEndpoint, int64, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastQuery = query
+	if m.pageErr != nil {
+		return nil, 0, "", m.pageErr
+	}
+	nextCursor := ""
+	if len(m.pages) > 0 {
+		nextCursor = "next"
+	}
+	return append([]model.MonitorEndpoint{}, m.pages...), int64(len(m.pages)), nextCursor, nil
+}
+
+func (m *memoryStore) ListInventoryEndpoints(_ context.Context, listQuery store.InventoryListQuery) ([]model.InventoryEndpointView, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastList = listQuery
+	return append([]model.InventoryEndpointView{}, m.endpoints...), nil
+}
+
+func (m *memoryStore) ListInventoryEndpointsPage(_ context.Context, listQuery store.InventoryListQuery) ([]model.InventoryEndpointView, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastList = listQuery
+	start := (listQuery.Page - 1) * listQuery.PageSize
+	if start > len(m.endpoints) {
+		start = len(m.endpoints)
+	}
+	end := start + listQuery.PageSize
+	if end > len(m.endpoints) {
+		end = len(m.endpoints)
+	}
+	return append([]model.InventoryEndpointView{}, m.endpoints[start:end]...), int64(len(m.endpoints)), nil
+}
+
+func (m *memoryStore) SaveMonitorSnapshot(_ context.Context, snapshot model.MonitorSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshots[snapshot.SnapshotID] = snapshot
+	return nil
+}
+
+func (m *memoryStore) GetMonitorSnapshot(_ context.Context, snapshotID string) (model.MonitorSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot, ok := m.snapshots[snapshotID]
+	if !ok {
+		return model.MonitorSnapshot{}, store.ErrMonitorSnapshotNotFound
+	}
+	return snapshot, nil
+}
+
+func (m *memoryStore) DeleteMonitorSnapshotsBefore(_ context.Context, cutoff time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for id, snapshot := range m.snapshots {
+		if snapshot.CapturedAt.Before(cutoff) {
+			delete(m.snapshots, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *memoryStore) ListInventoryEndpointHistory(_ context.Context, endpointID int64, limit int) ([]model.InventoryAuditEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	items := append([]model.InventoryAuditEntry{}, m.history[endpointID]...)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (m *memoryStore) ListEndpointAlertGroups(_ context.Context, endpointID int64) ([]model.Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	groups, ok := m.groups[endpointID]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return append([]model.Group{}, groups...), nil
+}
+
+func (m *memoryStore) MonitorFleetKPIs(_ context.Context, query store.MonitorPageQuery) (model.MonitorFleetKPIs, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.kpiCalls++
+	m.lastQuery = query
+	return m.kpis, nil
+}
+
+func (m *memoryStore) MonitorGroupSummaries(_ context.Context, query store.MonitorPageQuery) ([]model.MonitorGroupSummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastQuery = query
+	return append([]model.MonitorGroupSummary{}, m.groupStats...), nil
+}
+
+func (m *memoryStore) LastSeenBucketCounts(_ context.Context, query store.MonitorPageQuery) ([]model.LastSeenBucketCount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastQuery = query
+	counts := make([]model.LastSeenBucketCount, 0, len(model.LastSeenBuckets))
+	for _, bucket := range model.LastSeenBuckets {
+		counts = append(counts, model.LastSeenBucketCount{Bucket: bucket})
+	}
+	counts[len(counts)-1].Endpoints = int64(len(m.lastSeen))
+	return counts, nil
+}
+
+func (m *memoryStore) ListLastSeenBucket(_ context.Context, _ store.MonitorPageQuery, _ string, limit int) ([]model.LastSeenEndpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	items := append([]model.LastSeenEndpoint{}, m.lastSeen...)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (m *memoryStore) EndpointSLA(_ context.Context, endpointIDs []int64, start, end time.Time, excludeMaintenance bool) ([]model.EndpointSLA, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastIDs = append([]int64(nil), endpointIDs...)
+	m.slaExclude = excludeMaintenance
+	view, _ := store.RollupForRange(start, end)
+	items := []model.EndpointSLA{}
+	for _, id := range endpointIDs {
+		items = append(items, model.EndpointSLA{EndpointID: id})
+	}
+	return items, view, nil
+}
+
+func (m *memoryStore) QueryTimeSeries(_ context.Context, endpointIDs []int64, start, end time.Time) ([]model.TimeSeriesPoint, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastIDs = append([]int64(nil), endpointIDs...)
+	_, rollup := store.RollupForRange(start, end)
+	return []model.TimeSeriesPoint{}, rollup, nil
+}
+
+func (m *memoryStore) ListDowntimeIncidents(_ context.Context, endpointIDs []int64, start, end time.Time, limit int) ([]model.DowntimeIncident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastIDs = append([]int64(nil), endpointIDs...)
+	items := []model.DowntimeIncident{}
+	for _, incident := range m.incidents {
+		if incident.StartedAt.After(end) || (incident.EndedAt != nil && incident.EndedAt.Before(start)) {
+			continue
+		}
+		items = append(items, incident)
+	}
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (m *memoryStore) AnnotateDowntimeIncident(_ context.Context, id int64, annotation model.DowntimeIncidentAnnotation) (model.DowntimeIncident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.incidents {
+		incident := &m.incidents[i]
+		if incident.ID != id {
+			continue
+		}
+		if annotation.Cause != nil {
+			incident.Cause = *annotation.Cause
+		}
+		if annotation.Note != nil {
+			incident.Note = *annotation.Note
+		}
+		if annotation.Severity != nil {
+			incident.Severity = *annotation.Severity
+		}
+		return *incident, nil
+	}
+	return model.DowntimeIncident{}, store.ErrDowntimeIncidentNotFound
+}
+
+func (m *memoryStore) ListEndpointRawPings(_ context.Context, endpointID int64, start, end time.Time, limit int) ([]model.RawPingSample, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastIDs = []int64{endpointID}
+	items := []model.RawPingSample{}
+	for _, sample := range m.rawPings {
+		if sample.Timestamp.Before(start) || sample.Timestamp.After(end) {
+			continue
+		}
+		items = append(items, sample)
+	}
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (m *memoryStore) ListDistinctFilters(context.Context, bool) (map[string][]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.filterHits++
+	return m.filters, nil
+}
+
+func (m *memoryStore) ListFilterCounts(_ context.Context, query store.MonitorPageQuery) (map[string][]model.FilterValueCount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastQuery = query
+	counts := map[string][]model.FilterValueCount{}
+	for key, values := range m.filters {
+		counts[key] = []model.FilterValueCount{}
+		for _, value := range values {
+			counts[key] = append(counts[key], model.FilterValueCount{Value: value, Count: 1})
+		}
+	}
+	return counts, nil
+}
+
+// ApplyImport fails each row listed in applyFail that many times before
+// letting it through.
+func (m *memoryStore) ApplyImport(_ context.Context, rows []model.ImportCandidate) (int, int, []string, []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	added, updated := 0, 0
+	errs := []string{}
+	failed := []string{}
+	for _, row := range rows {
+		if m.applyFail[row.RowID] > 0 {
+			m.applyFail[row.RowID]--
+			errs = append(errs, row.RowID+": transient failure")
+			failed = append(failed, row.RowID)
+			continue
+		}
+		m.applied = append(m.applied, row.RowID)
+		if row.Action == model.ImportAdd {
+			added++
+		} else {
+			updated++
+		}
+	}
+	return added, updated, errs, failed
+}
+
+func (m *memoryStore) DryRunImport(_ context.Context, rows []model.ImportCandidate) (int, int, []string, []string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	added, updated := 0, 0
+	errs := []string{}
+	failed := []string{}
+	for _, row := range rows {
+		if m.applyFail[row.RowID] > 0 {
+			errs = append(errs, row.RowID+": transient failure")
+			failed = append(failed, row.RowID)
+			continue
+		}
+		if row.Action == model.ImportAdd {
+			added++
+		} else {
+			updated++
+		}
+	}
+	return added, updated, errs, failed, nil
+}
+
+func (m *memoryStore) ListAllEndpointIDs(context.Context) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]int64, 0, len(m.endpoints))
+	for _, endpoint := range m.endpoints {
+		ids = append(ids, endpoint.EndpointID)
+	}
+	return ids, nil
+}
+
+func (m *memoryStore) InventoryByIP(_ context.Context) (map[string]model.InventoryEndpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing := make(map[string]model.InventoryEndpoint, len(m.endpoints))
+	for _, endpoint := range m.endpoints {
+		existing[endpoint.IPAddress] = model.InventoryEndpoint{ID: endpoint.EndpointID, IP: endpoint.IPAddress, Hostname: endpoint.Hostname}
+	}
+	return existing, nil
+}
+
+func (m *memoryStore) GetInventoryEndpointByID(_ context.Context, endpointID int64) (model.InventoryEndpointView, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, endpoint := range m.endpoints {
+		if endpoint.EndpointID == endpointID {
+			return endpoint, nil
+		}
+	}
+	return model.InventoryEndpointView{}, pgx.ErrNoRows
+}
+
+func (m *memoryStore) GetGroupByID(_ context.Context, id int64) (model.Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, group := range m.namedGroup {
+		if group.ID == id {
+			return group, nil
+		}
+	}
+	if _, ok := m.groupScope[id]; !ok {
+		return model.Group{}, pgx.ErrNoRows
+	}
+	return model.Group{ID: id}, nil
+}
+
+func (m *memoryStore) GetGroupByNameCI(_ context.Context, name string) (model.Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, group := range m.namedGroup {
+		if strings.EqualFold(group.Name, name) {
+			return group, nil
+		}
+	}
+	return model.Group{}, pgx.ErrNoRows
+}
+
+func (m *memoryStore) CreateGroup(_ context.Context, name string, description string, _ []int64) (model.Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	group := model.Group{ID: int64(100 + len(m.namedGroup)), Name: name, Description: description}
+	m.namedGroup = append(m.namedGroup, group)
+	return group, nil
+}
+
+func (m *memoryStore) ResolveEndpointIDsByIPs(_ context.Context, ips []string) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := []int64{}
+	for _, ip := range ips {
+		for _, endpoint := range m.endpoints {
+			if endpoint.IPAddress == ip {
+				ids = append(ids, endpoint.EndpointID)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (m *memoryStore) AddEndpointsToGroup(_ context.Context, groupID int64, endpointIDs []int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.groupScope == nil {
+		m.groupScope = map[int64][]int64{}
+	}
+	m.groupScope[groupID] = append(m.groupScope[groupID], endpointIDs...)
+	return int64(len(endpointIDs)), nil
+}
+
+func (m *memoryStore) SetGroupProbeInterval(_ context.Context, groupID int64, intervalSec *int) (model.Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.groupScope[groupID]; !ok {
+		return model.Group{}, pgx.ErrNoRows
+	}
+	if m.intervals == nil {
+		m.intervals = map[int64]*int{}
+	}
+	m.intervals[groupID] = intervalSec
+	return model.Group{ID: groupID, ProbeIntervalSec: intervalSec}, nil
+}
+
+func (m *memoryStore) ListEndpointIDsByGroup(_ context.Context, groupID int64) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int64{}, m.groupScope[groupID]...), nil
+}
+
+func (m *memoryStore) ResetEndpointStats(_ context.Context, endpointIDs []int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resetIDs = append([]int64{}, endpointIDs...)
+	return int64(len(endpointIDs)), nil
+}
+
+func (m *memoryStore) RecomputeEndpointStats(_ context.Context, endpointIDs []int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recomputed = append(m.recomputed, append([]int64{}, endpointIDs...))
+	return int64(len(endpointIDs)), nil
+}
+
+func (m *memoryStore) ListProbeTargets(_ context.Context, scope string, groupIDs []int64) ([]store.ProbeTarget, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wanted := map[int64]struct{}{}
+	for _, groupID := range groupIDs {
+		for _, endpointID := range m.groupScope[groupID] {
+			wanted[endpointID] = struct{}{}
+		}
+	}
+	targets := []store.ProbeTarget{}
+	for _, endpoint := range m.endpoints {
+		if !endpoint.Active {
+			continue
+		}
+		if _, ok := wanted[endpoint.EndpointID]; scope == "groups" && !ok {
+			continue
+		}
+		targets = append(targets, store.ProbeTarget{EndpointID: endpoint.EndpointID, IP: endpoint.IPAddress})
+	}
+	return targets, nil
+}
+
+func (m *memoryStore) CreateInventoryEndpoints(_ context.Context, payloads []model.InventoryEndpointCreate) ([]int64, []error, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]int64, len(payloads))
+	rowErrs := make([]error, len(payloads))
+	for i, payload := range payloads {
+		for _, endpoint := range m.endpoints {
+			if payload.IPAddress != "" && endpoint.IPAddress == payload.IPAddress {
+				rowErrs[i] = store.ErrEndpointIPExists
+			}
+		}
+		if rowErrs[i] != nil {
+			continue
+		}
+		ids[i] = int64(len(m.endpoints) + 1)
+		m.endpoints = append(m.endpoints, model.InventoryEndpointView{EndpointID: ids[i], Hostname: payload.Hostname, IPAddress: payload.IPAddress})
+	}
+	return ids, rowErrs, nil
+}
+
+func (m *memoryStore) ListInventoryEndpointsByIDs(_ context.Context, endpointIDs []int64, limit int) ([]model.InventoryEndpointView, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wanted := map[int64]struct{}{}
+	for _, endpointID := range endpointIDs {
+		wanted[endpointID] = struct{}{}
+	}
+	items := []model.InventoryEndpointView{}
+	for _, endpoint := range m.endpoints {
+		if _, ok := wanted[endpoint.EndpointID]; ok && len(items) < limit {
+			items = append(items, endpoint)
+		}
+	}
+	return items, nil
+}
+
+func (m *memoryStore) ListMonitorEndpoints(_ context.Context, filters store.MonitorFilters) ([]model.MonitorEndpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastFilter = filters
+	return append([]model.MonitorEndpoint{}, m.pages...), nil
+}
+
+func (m *memoryStore) StreamEndpointStats(_ context.Context, query store.MonitorPageQuery, fn func(model.EndpointStatsSnapshot) error) error {
+	m.mu.Lock()
+	m.lastQuery = query
+	items := append([]model.EndpointStatsSnapshot{}, m.stats...)
+	m.mu.Unlock()
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) StreamMonitorEndpoints(_ context.Context, query store.MonitorPageQuery, fn func(model.MonitorEndpoint) error) error {
+	m.mu.Lock()
+	m.lastQuery = query
+	items := append([]model.MonitorEndpoint{}, m.pages...)
+	m.mu.Unlock()
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) ResolveExistingInventoryEndpointIDs(_ context.Context, endpointIDs []int64) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing := map[int64]struct{}{}
+	for _, endpoint := range m.endpoints {
+		existing[endpoint.EndpointID] = struct{}{}
+	}
+	items := []int64{}
+	for _, endpointID := range endpointIDs {
+		if _, ok := existing[endpointID]; ok {
+			items = append(items, endpointID)
+		}
+	}
+	return items, nil
+}
+
+func (m *memoryStore) DeleteInventoryEndpointsByIDsWithProgress(_ context.Context, endpointIDs []int64, _ int, _ int, _ func(store.InventoryDeleteProgress)) (int64, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	remove := map[int64]struct{}{}
+	for _, endpointID := range endpointIDs {
+		remove[endpointID] = struct{}{}
+	}
+	kept := m.endpoints[:0]
+	deleted := int64(0)
+	for _, endpoint := range m.endpoints {
+		if _, ok := remove[endpoint.EndpointID]; ok {
+			deleted++
+			continue
+		}
+		kept = append(kept, endpoint)
+	}
+	m.endpoints = kept
+	return deleted, 0, nil
+}
+
+func newTestServer(st serverStore) *Server {
+	return newServerWithStore(config.Config{}, st, nil, nil)
+}
+
+func serveTestRequest(t *testing.T, srv *Server, method string, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	rec := httptest.NewRecorder()
+	srv.Routes().ServeHTTP(rec, req)
+	return rec
+}
+
+func serveTestJSONRequest(t *testing.T, srv *Server, method string, target string, payload any) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("encode request: %v", err)
+	}
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	srv.Routes().ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeTestResponse(t *testing.T, rec *httptest.ResponseRecorder, dst any) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), dst); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+}
+
+func TestHandleGetSettingsReturnsStoreSettings(t *testing.T) {
+	st := newMemoryStore()
+	st.settings.AlertWebhookURL = "https://hooks.example.com/services/secret-token"
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/settings/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var settings model.Settings
+	decodeTestResponse(t, rec, &settings)
+	if settings.PingIntervalSec != 1 || settings.ICMPPayloadSize != 56 || settings.ICMPTimeoutMs != 500 {
+		t.Fatalf("unexpected settings payload: %+v", settings)
+	}
+	if !settings.AlertWebhookConfigured || strings.Contains(rec.Body.String(), "secret-token") {
+		t.Fatalf("expected the webhook URL to be redacted: %s", rec.Body.String())
+	}
+}
+
+func TestHandleHealthReportsDatabaseReachability(t *testing.T) {
+	st := newMemoryStore()
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/healthz")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var body struct {
+		Status string            `json:"status"`
+		DB     string            `json:"db"`
+		Pool   model.DBPoolStats `json:"pool"`
+	}
+	decodeTestResponse(t, rec, &body)
+	if body.Status != "ok" || body.DB != "ok" || body.Pool.TotalConns != 2 || body.Pool.MaxConns != 4 {
+		t.Fatalf("unexpected healthy body: %+v", body)
+	}
+
+	st.pingErr = errors.New("connection refused")
+	rec = serveTestRequest(t, srv, http.MethodGet, "/healthz")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	decodeTestResponse(t, rec, &body)
+	if body.Status != "degraded" || body.DB != "unreachable" {
+		t.Fatalf("unexpected degraded body: %+v", body)
+	}
+}
+
+func TestHandleMonitorEndpointsPageRejectsInvalidPageSize(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+
+	for _, target := range []string{
+		"/api/monitor/endpoints-page?page_size=1001",
+		"/api/monitor/endpoints-page?page_size=0",
+	} {
+		rec := serveTestRequest(t, srv, http.MethodGet, target)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+		var body map[string]string
+		decodeTestResponse(t, rec, &body)
+		if body["error"] != "page_size must be between 1 and 1000" {
+			t.Fatalf("%s: unexpected error body: %v", target, body)
+		}
+	}
+}
+
+func TestHandleMonitorEndpointsPageAcceptsArbitraryPageSize(t *testing.T) {
+	st := newMemoryStore()
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints-page?page_size=500")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if st.lastQuery.PageSize != 500 {
+		t.Fatalf("store page size = %d, want 500", st.lastQuery.PageSize)
+	}
+}
+
+func TestHandleMonitorEndpointsPageReturnsPagedShape(t *testing.T) {
+	st := newMemoryStore()
+	st.pages = []model.MonitorEndpoint{{EndpointID: 1, IPAddress: "10.0.0.1"}, {EndpointID: 2, IPAddress: "10.0.0.2"}}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints-page?page_size=50&vlan=10&sort_by=failed_count&sort_dir=asc")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var page model.MonitorEndpointsPageResponse
+	decodeTestResponse(t, rec, &page)
+	if page.TotalItems != 2 || page.TotalPages != 1 || page.PageSize != 50 || len(page.Items) != 2 {
+		t.Fatalf("unexpected page payload: %+v", page)
+	}
+	if page.SortBy != "failed_count" || page.SortDir != "asc" || page.StatsScope != "live" {
+		t.Fatalf("unexpected page sort/scope: %+v", page)
+	}
+	if len(st.lastQuery.Filters.VLANs) != 1 || st.lastQuery.Filters.VLANs[0] != "10" {
+		t.Fatalf("expected vlan filter to reach the store, got %+v", st.lastQuery.Filters)
+	}
+}
+
+func TestHandleMonitorEndpointsPageCursorMode(t *testing.T) {
+	st := newMemoryStore()
+	st.pages = []model.MonitorEndpoint{{EndpointID: 1, IPAddress: "10.0.0.1"}}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints-page?page_size=50&pagination=cursor&sort_by=failed_count&sort_dir=desc")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var page model.MonitorEndpointsPageResponse
+	decodeTestResponse(t, rec, &page)
+	if page.NextCursor != "next" || page.Page != 0 || len(page.Items) != 1 {
+		t.Fatalf("unexpected cursor page payload: %+v", page)
+	}
+	if !st.lastQuery.CursorMode || st.lastQuery.Cursor != "" {
+		t.Fatalf("expected first cursor page to reach the store, got %+v", st.lastQuery)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints-page?page_size=50&cursor=abc")
+	if rec.Code != http.StatusOK || !st.lastQuery.CursorMode || st.lastQuery.Cursor != "abc" {
+		t.Fatalf("cursor alone should select cursor mode, status=%d query=%+v", rec.Code, st.lastQuery)
+	}
+}
+
+func TestHandleMonitorEndpointsPageCursorModeValidation(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+
+	for path, want := range map[string]string{
+		"/api/monitor/endpoints-page?pagination=pages":                                                                        "pagination must be offset or cursor",
+		"/api/monitor/endpoints-page?pagination=offset&cursor=abc":                                                            "cursor requires pagination=cursor",
+		"/api/monitor/endpoints-page?pagination=cursor&sort=failed_count:desc,success_count:asc":                              "cursor pagination supports at most one sort column",
+		"/api/monitor/endpoints-page?pagination=cursor&stats_scope=range&start=2026-03-01T00:00:00Z&end=2026-03-02T00:00:00Z": "cursor pagination is only supported when stats_scope=live",
+	} {
+		rec := serveTestRequest(t, srv, http.MethodGet, path)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want %d", path, rec.Code, http.StatusBadRequest)
+		}
+		var body map[string]string
+		decodeTestResponse(t, rec, &body)
+		if body["error"] != want {
+			t.Fatalf("%s: unexpected error body: %v", path, body)
+		}
+	}
+
+	st := newMemoryStore()
+	st.pageErr = store.ErrInvalidMonitorCursor
+	rec := serveTestRequest(t, newTestServer(st), http.MethodGet, "/api/monitor/endpoints-page?cursor=stale")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("stale cursor status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleMonitorSnapshotRoundTrip(t *testing.T) {
+	st := newMemoryStore()
+	st.pages = []model.MonitorEndpoint{{EndpointID: 9, IPAddress: "10.0.0.9"}}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/snapshot?page_size=100")
+	if rec.Code != http.StatusMethodNotAllowed || len(st.snapshots) != 0 {
+		t.Fatalf("GET snapshot status = %d with %d stored, want %d and none stored", rec.Code, len(st.snapshots), http.StatusMethodNotAllowed)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodPost, "/api/monitor/snapshot?page_size=100")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var captured model.MonitorSnapshot
+	decodeTestResponse(t, rec, &captured)
+	if captured.SnapshotID == "" || len(captured.Items) != 1 {
+		t.Fatalf("unexpected captured snapshot: %+v", captured)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/monitor/snapshots/"+captured.SnapshotID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var reopened model.MonitorSnapshot
+	decodeTestResponse(t, rec, &reopened)
+	if reopened.SnapshotID != captured.SnapshotID || len(reopened.Items) != 1 || reopened.Items[0].EndpointID != 9 {
+		t.Fatalf("unexpected reopened snapshot: %+v", reopened)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/monitor/snapshots/missing")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleInventoryEndpointHistory(t *testing.T) {
+	st := newMemoryStore()
+	st.history[5] = []model.InventoryAuditEntry{
+		{ID: 3, EndpointID: 5, Action: "update", ChangedFields: []string{"vlan"}, Before: map[string]any{"vlan": "10"}, After: map[string]any{"vlan": "20"}},
+		{ID: 1, EndpointID: 5, Action: "create"},
+	}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/inventory/endpoints/5/history?limit=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var body struct {
+		EndpointID int64                       `json:"endpoint_id"`
+		Items      []model.InventoryAuditEntry `json:"items"`
+	}
+	decodeTestResponse(t, rec, &body)
+	if body.EndpointID != 5 || len(body.Items) != 1 || body.Items[0].ID != 3 {
+		t.Fatalf("unexpected history payload: %+v", body)
+	}
+	if body.Items[0].After["vlan"] != "20" {
+		t.Fatalf("expected after details to round-trip, got %+v", body.Items[0].After)
+	}
+
+	for _, target := range []string{"/api/inventory/endpoints/abc/history", "/api/inventory/endpoints/5/history?limit=0"} {
+		rec = serveTestRequest(t, srv, http.MethodGet, target)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestHandleInventoryEndpointAlertThresholdsResolvesGroupOverrides(t *testing.T) {
+	st := newMemoryStore()
+	st.settings.AlertConsecutiveFailed = 3
+	st.settings.AlertFailedPct = 50
+	consecutive := 12
+	st.groups[7] = []model.Group{{ID: 2, Name: "lab", AlertThresholds: model.AlertThresholds{ConsecutiveFailed: &consecutive}}}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/inventory/endpoints/7/alert-thresholds")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var body struct {
+		EndpointID int64                          `json:"endpoint_id"`
+		Thresholds model.EffectiveAlertThresholds `json:"thresholds"`
+	}
+	decodeTestResponse(t, rec, &body)
+	if body.Thresholds.ConsecutiveFailed != 12 || body.Thresholds.ConsecutiveFailedSource != "lab" {
+		t.Fatalf("unexpected consecutive threshold: %+v", body.Thresholds)
+	}
+	if body.Thresholds.FailedPct != 50 || body.Thresholds.FailedPctSource != "global" {
+		t.Fatalf("unexpected failed pct threshold: %+v", body.Thresholds)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/inventory/endpoints/8/alert-thresholds")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing endpoint status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleGroupProbeIntervalUpdate(t *testing.T) {
+	st := newMemoryStore()
+	st.groupScope = map[int64][]int64{4: {1}}
+	srv := newTestServer(st)
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPut, "/api/groups/4/probe-interval", map[string]any{"probe_interval_sec": 30})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var group model.Group
+	decodeTestResponse(t, rec, &group)
+	if group.ProbeIntervalSec == nil || *group.ProbeIntervalSec != 30 {
+		t.Fatalf("probe_interval_sec = %v, want 30", group.ProbeIntervalSec)
+	}
+
+	rec = serveTestJSONRequest(t, srv, http.MethodPut, "/api/groups/4/probe-interval", map[string]any{"probe_interval_sec": nil})
+	if rec.Code != http.StatusOK || st.intervals[4] != nil {
+		t.Fatalf("clearing status = %d, stored %v", rec.Code, st.intervals[4])
+	}
+
+	for _, value := range []int{0, 3601} {
+		rec = serveTestJSONRequest(t, srv, http.MethodPut, "/api/groups/4/probe-interval", map[string]any{"probe_interval_sec": value})
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("probe_interval_sec %d status = %d, want %d", value, rec.Code, http.StatusBadRequest)
+		}
+	}
+	rec = serveTestJSONRequest(t, srv, http.MethodPut, "/api/groups/9/probe-interval", map[string]any{"probe_interval_sec": 30})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing group status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleProbeGroupScheduleValidation(t *testing.T) {
+	st := newMemoryStore()
+	st.groupScope = map[int64][]int64{4: {1}}
+	srv := newTestServer(st)
+	srv.probe = probe.NewEngine(nil, nil, probe.Options{}, model.Settings{})
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/probes/start", map[string]any{"group_id": 9})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown group start status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	rec = serveTestJSONRequest(t, srv, http.MethodPost, "/api/probes/start", map[string]any{"group_id": 0})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("zero group start status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodPost, "/api/probes/stop?group_id=abc")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid group stop status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	rec = serveTestRequest(t, srv, http.MethodPost, "/api/probes/stop?group_id=4")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("group stop status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var stopped struct {
+		Running bool `json:"running"`
+		Stopped bool `json:"stopped"`
+	}
+	decodeTestResponse(t, rec, &stopped)
+	if stopped.Running || stopped.Stopped {
+		t.Fatalf("stopping an idle group = %+v, want nothing stopped", stopped)
+	}
+}
+
+func TestHandleMonitorKPIsCachesPerQuery(t *testing.T) {
+	st := newMemoryStore()
+	st.kpis = model.MonitorFleetKPIs{TotalEndpoints: 4, MonitoredEndpoints: 3, DownEndpoints: 1, StatusCounts: map[string]int64{"Succeeded": 2}}
+	srv := newTestServer(st)
+
+	for i := 0; i < 2; i++ {
+		rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/kpis?vlan=100")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+		var kpis model.MonitorFleetKPIs
+		decodeTestResponse(t, rec, &kpis)
+		if kpis.TotalEndpoints != 4 || kpis.DownEndpoints != 1 {
+			t.Fatalf("unexpected kpis payload: %+v", kpis)
+		}
+	}
+	if st.kpiCalls != 1 {
+		t.Fatalf("store calls = %d, want 1 for repeated query", st.kpiCalls)
+	}
+	if len(st.lastQuery.Filters.VLANs) != 1 || st.lastQuery.Filters.VLANs[0] != "100" {
+		t.Fatalf("expected vlan filter to reach store, got %+v", st.lastQuery.Filters)
+	}
+
+	serveTestRequest(t, srv, http.MethodGet, "/api/monitor/kpis?vlan=200")
+	if st.kpiCalls != 2 {
+		t.Fatalf("store calls = %d, want 2 after filter change", st.kpiCalls)
+	}
+}
+
+func TestHandleMonitorGroupSummariesPassesFilters(t *testing.T) {
+	st := newMemoryStore()
+	st.groupStats = []model.MonitorGroupSummary{
+		{GroupID: 3, GroupName: "DB-Core", TotalEndpoints: 4, UpEndpoints: 3, DownEndpoints: 1, LossPct: 12.5,
+			WorstOffender: &model.GroupWorstOffender{EndpointID: 9, IPAddress: "10.0.0.9", FailedPct: 50}},
+	}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/groups/summary?vlan=100&switch=sw-1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var items []model.MonitorGroupSummary
+	decodeTestResponse(t, rec, &items)
+	if len(items) != 1 || items[0].DownEndpoints != 1 || items[0].WorstOffender == nil || items[0].WorstOffender.EndpointID != 9 {
+		t.Fatalf("unexpected group summary payload: %+v", items)
+	}
+	filters := st.lastQuery.Filters
+	if len(filters.VLANs) != 1 || filters.VLANs[0] != "100" || len(filters.Switches) != 1 || filters.Switches[0] != "sw-1" {
+		t.Fatalf("expected vlan and switch filters to reach store, got %+v", filters)
+	}
+}
+
+func TestHandleProbeCoverageReportsUncoveredEndpoints(t *testing.T) {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{
+		{EndpointID: 1, IPAddress: "10.0.0.1", Active: true},
+		{EndpointID: 2, IPAddress: "10.0.0.2", Active: true},
+		{EndpointID: 3, IPAddress: "10.0.0.3", Active: true},
+		{EndpointID: 4, IPAddress: "10.0.0.4", Active: false},
+	}
+	st.groupScope = map[int64][]int64{7: {1, 4}}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/probes/coverage?scope=groups&group_ids=7")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var body struct {
+		Scope           string                        `json:"scope"`
+		TotalEndpoints  int                           `json:"total_endpoints"`
+		InScope         int                           `json:"in_scope"`
+		OutOfScope      int                           `json:"out_of_scope"`
+		UncoveredSample []model.InventoryEndpointView `json:"uncovered_sample"`
+	}
+	decodeTestResponse(t, rec, &body)
+	if body.Scope != "groups" || body.TotalEndpoints != 4 || body.InScope != 1 || body.OutOfScope != 3 {
+		t.Fatalf("unexpected coverage: %+v", body)
+	}
+	if len(body.UncoveredSample) != 3 || body.UncoveredSample[0].EndpointID != 2 {
+		t.Fatalf("unexpected uncovered sample: %+v", body.UncoveredSample)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/probes/coverage?scope=groups")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("missing group_ids status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMonitorStateSnapshotCarriesCurrentStatus(t *testing.T) {
+	st := newMemoryStore()
+	latency := 2.5
+	st.pages = []model.MonitorEndpoint{
+		{EndpointID: 11, LastPingStatus: "Succeeded", LastPingLatency: &latency},
+		{EndpointID: 12, LastPingStatus: "Request Timeout", ConsecutiveFailedCount: 4},
+	}
+	srv := newTestServer(st)
+
+	event, err := srv.monitorStateSnapshot(context.Background())
+	if err != nil {
+		t.Fatalf("monitorStateSnapshot() error: %v", err)
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("marshal snapshot: %v", err)
+	}
+	var frame struct {
+		Type  string             `json:"type"`
+		Count int                `json:"count"`
+		Items []monitorStateItem `json:"items"`
+	}
+	if err := json.Unmarshal(payload, &frame); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	if frame.Type != "state_snapshot" || frame.Count != 2 || len(frame.Items) != 2 {
+		t.Fatalf("unexpected snapshot frame: %s", payload)
+	}
+	if frame.Items[1].EndpointID != 12 || frame.Items[1].Status != "Request Timeout" || frame.Items[1].ConsecutiveFailedCount != 4 {
+		t.Fatalf("unexpected snapshot item: %+v", frame.Items[1])
+	}
+}
+
+func TestHandleInventoryEndpointsPaginatesWhenRequested(t *testing.T) {
+	st := newMemoryStore()
+	for i := 1; i <= 120; i++ {
+		st.endpoints = append(st.endpoints, model.InventoryEndpointView{EndpointID: int64(i)})
+	}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/inventory/endpoints?page=3&page_size=50&sort_by=hostname&sort_dir=desc&vlan=10")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp model.InventoryEndpointsPageResponse
+	decodeTestResponse(t, rec, &resp)
+	if len(resp.Items) != 20 || resp.TotalItems != 120 || resp.TotalPages != 3 || resp.Page != 3 {
+		t.Fatalf("unexpected page envelope: items=%d total=%d pages=%d page=%d", len(resp.Items), resp.TotalItems, resp.TotalPages, resp.Page)
+	}
+	if st.lastList.SortBy != "hostname" || st.lastList.SortDir != "desc" || len(st.lastList.Filters.VLANs) != 1 {
+		t.Fatalf("unexpected list query: %+v", st.lastList)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/inventory/endpoints?page=18&page_size=7")
+	resp = model.InventoryEndpointsPageResponse{}
+	decodeTestResponse(t, rec, &resp)
+	if len(resp.Items) != 1 || resp.PageSize != 7 || resp.TotalPages != 18 {
+		t.Fatalf("unexpected odd-size page envelope: items=%d size=%d pages=%d", len(resp.Items), resp.PageSize, resp.TotalPages)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/inventory/endpoints")
+	var all []model.InventoryEndpointView
+	decodeTestResponse(t, rec, &all)
+	if len(all) != 120 {
+		t.Fatalf("expected unpaginated array without page params, got %d items", len(all))
+	}
+}
+
+func TestHandleInventoryEndpointsRejectsInvalidPageParams(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+	for _, target := range []string{
+		"/api/inventory/endpoints?page_size=1001",
+		"/api/inventory/endpoints?page=0",
+		"/api/inventory/endpoints?sort_by=mac",
+		"/api/inventory/endpoints?sort_by=ip&sort_dir=up",
+		"/api/inventory/endpoints?sort_dir=asc",
+	} {
+		rec := serveTestRequest(t, srv, http.MethodGet, target)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestFreeTextSearchForwardedToStore(t *testing.T) {
+	st := newMemoryStore()
+	srv := newTestServer(st)
+
+	serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints-page?q=%20core-sw%20")
+	if st.lastQuery.Search != "core-sw" {
+		t.Fatalf("monitor search = %q, want core-sw", st.lastQuery.Search)
+	}
+	serveTestRequest(t, srv, http.MethodGet, "/api/inventory/endpoints?q=10.0.0")
+	if st.lastList.Search != "10.0.0" {
+		t.Fatalf("inventory search = %q, want 10.0.0", st.lastList.Search)
+	}
+}
+
+func TestHandleMonitorEndpointsParsesCIDR(t *testing.T) {
+	st := newMemoryStore()
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints?vlan=10&cidr=10.20.5.1/16")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if !reflect.DeepEqual(st.lastFilter.CIDRs, []string{"10.20.0.0/16"}) || !reflect.DeepEqual(st.lastFilter.VLANs, []string{"10"}) {
+		t.Fatalf("unexpected filters: %+v", st.lastFilter)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints?cidr=10.20.0.0")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid cidr status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -73,3 +74,70 @@ func TestParseDashboardLookbackRejectsRangeScope(t *testing.T) {
 		t.Fatalf("unexpected message %q", parseErr.Message)
 	}
 }
+
+func TestMonitorPageQueryParsesStatusFilter(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/monitor/endpoints-page?status=Down", nil)
+	query, parseErr := srv.monitorPageQueryFromRequest(req, monitorRequestOptions{})
+	if parseErr != nil || query.Filters.Status != "down" {
+		t.Fatalf("status filter = %q, %+v; want down", query.Filters.Status, parseErr)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/monitor/endpoints-page?status=degraded", nil)
+	if _, parseErr := srv.monitorPageQueryFromRequest(req, monitorRequestOptions{}); parseErr == nil || parseErr.Status != http.StatusBadRequest {
+		t.Fatalf("invalid status parse error = %+v, want 400", parseErr)
+	}
+}
+
+func TestParseCIDRListQueryNormalizesPrefixes(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/monitor/endpoints-page?cidr=10.20.5.1/16,10.20.0.0/16%202001:db8::1/32", nil)
+	got, err := parseCIDRListQuery(req, "cidr")
+	if err != nil {
+		t.Fatalf("parseCIDRListQuery() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != "10.20.0.0/16" || got[1] != "2001:db8::/32" {
+		t.Fatalf("cidrs = %v, want [10.20.0.0/16 2001:db8::/32]", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/inventory/endpoints?cidr=10.20.0.0", nil)
+	if _, err := parseCIDRListQuery(req, "cidr"); err == nil {
+		t.Fatalf("bare address should be rejected")
+	}
+}
+
+func TestMonitorRollupMatchesTimeSeriesAroundBoundary(t *testing.T) {
+	srv := newTestServer(newMemoryStore())
+
+	for _, tc := range []struct {
+		end  string
+		want string
+	}{
+		{end: "2026-03-03T00:00:00Z", want: "1m"},
+		{end: "2026-03-03T00:00:01Z", want: "1h"},
+	} {
+		window := "start=2026-03-01T00:00:00Z&end=" + tc.end
+
+		rec := serveTestRequest(t, srv, http.MethodGet, "/api/monitor/endpoints-page?stats_scope=range&"+window)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("page status = %d: %s", rec.Code, rec.Body.String())
+		}
+		var page struct {
+			RangeRollup string `json:"range_rollup"`
+		}
+		decodeTestResponse(t, rec, &page)
+
+		rec = serveTestRequest(t, srv, http.MethodGet, "/api/monitor/timeseries?endpoint_ids=1&"+window)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("timeseries status = %d: %s", rec.Code, rec.Body.String())
+		}
+		var series struct {
+			Rollup string `json:"rollup"`
+		}
+		decodeTestResponse(t, rec, &series)
+
+		if page.RangeRollup != tc.want || series.Rollup != tc.want {
+			t.Fatalf("end %s: range_rollup = %q, timeseries rollup = %q, want %q", tc.end, page.RangeRollup, series.Rollup, tc.want)
+		}
+	}
+}
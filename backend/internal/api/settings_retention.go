@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"sonarscope/backend/internal/config"
+	"sonarscope/backend/internal/util"
+)
+
+func (s *Server) handleGetRetentionSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := s.store.GetRetentionSettings(r.Context())
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, settings)
+}
+
+// handleUpdateRetentionSettings applies a partial update; omitted windows keep
+// their current value.
+func (s *Server) handleUpdateRetentionSettings(w http.ResponseWriter, r *http.Request) {
+	type retentionPatch struct {
+		PingRawDays *int `json:"ping_raw_days"`
+		Ping1mDays  *int `json:"ping_1m_days"`
+		Ping1hDays  *int `json:"ping_1h_days"`
+	}
+	var patch retentionPatch
+	if err := util.DecodeJSON(r, &patch); err != nil {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+
+	settings, err := s.store.GetRetentionSettings(r.Context())
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if patch.PingRawDays != nil {
+		settings.PingRawDays = *patch.PingRawDays
+	}
+	if patch.Ping1mDays != nil {
+		settings.Ping1mDays = *patch.Ping1mDays
+	}
+	if patch.Ping1hDays != nil {
+		settings.Ping1hDays = *patch.Ping1hDays
+	}
+	if err := config.ValidateRetention(settings.PingRawDays, settings.Ping1mDays, settings.Ping1hDays); err != nil {
+		util.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.UpdateRetentionSettings(r.Context(), settings); err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	util.WriteJSON(w, http.StatusOK, settings)
+}
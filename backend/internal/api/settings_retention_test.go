@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestHandleUpdateRetentionSettingsMergesPatch(t *testing.T) {
+	st := newMemoryStore()
+	srv := newTestServer(st)
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPut, "/api/settings/retention", map[string]int{"ping_raw_days": 14})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var settings model.RetentionSettings
+	decodeTestResponse(t, rec, &settings)
+	want := model.RetentionSettings{PingRawDays: 14, Ping1mDays: 365, Ping1hDays: 730}
+	if settings != want || st.retention != want {
+		t.Fatalf("unexpected retention %+v stored %+v", settings, st.retention)
+	}
+
+	rec = serveTestRequest(t, srv, http.MethodGet, "/api/settings/retention")
+	decodeTestResponse(t, rec, &settings)
+	if settings != want {
+		t.Fatalf("unexpected GET retention %+v", settings)
+	}
+}
+
+func TestHandleUpdateRetentionSettingsRejectsOutOfBounds(t *testing.T) {
+	st := newMemoryStore()
+	srv := newTestServer(st)
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPut, "/api/settings/retention", map[string]int{"ping_raw_days": 120})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if st.retention.PingRawDays != 30 {
+		t.Fatalf("expected stored retention untouched, got %+v", st.retention)
+	}
+}
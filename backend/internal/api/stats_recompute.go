@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/util"
+)
+
+const (
+	statsRecomputeBatchSize = 100
+	statsRecomputeMaxIDs    = 50000
+)
+
+type statsRecomputeEvent struct {
+	Type string `json:"type"`
+	model.StatsRecomputeJobStatus
+}
+
+func (s *Server) statsRecomputeSnapshot() model.StatsRecomputeJobStatus {
+	s.recomputeMu.Lock()
+	defer s.recomputeMu.Unlock()
+	if s.recomputeJob == nil {
+		return model.StatsRecomputeJobStatus{Active: false}
+	}
+	job := *s.recomputeJob
+	job.StartedAt = cloneTimePtr(job.StartedAt)
+	job.UpdatedAt = cloneTimePtr(job.UpdatedAt)
+	job.CompletedAt = cloneTimePtr(job.CompletedAt)
+	return job
+}
+
+// updateStatsRecomputeJob applies updateFn to the current job and broadcasts
+// the result so open dashboards can show progress.
+func (s *Server) updateStatsRecomputeJob(jobID string, updateFn func(job *model.StatsRecomputeJobStatus)) {
+	s.recomputeMu.Lock()
+	if s.recomputeJob == nil || s.recomputeJob.JobID != jobID {
+		s.recomputeMu.Unlock()
+		return
+	}
+	updateFn(s.recomputeJob)
+	now := time.Now().UTC()
+	s.recomputeJob.UpdatedAt = &now
+	s.recomputeMu.Unlock()
+
+	if s.hub != nil {
+		s.hub.Broadcast(statsRecomputeEvent{Type: "stats_recompute", StatsRecomputeJobStatus: s.statsRecomputeSnapshot()})
+	}
+}
+
+func (s *Server) handleAdminRecomputeStats(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		EndpointIDs []int64 `json:"endpoint_ids"`
+	}
+	var req request
+	if err := util.DecodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		util.WriteError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	if len(req.EndpointIDs) > statsRecomputeMaxIDs {
+		util.WriteError(w, http.StatusBadRequest, "endpoint_ids exceeds the maximum of 50000")
+		return
+	}
+
+	var endpointIDs []int64
+	var err error
+	if len(req.EndpointIDs) > 0 {
+		endpointIDs, err = s.store.ResolveExistingInventoryEndpointIDs(r.Context(), req.EndpointIDs)
+	} else {
+		endpointIDs, err = s.store.ListAllEndpointIDs(r.Context())
+	}
+	if err != nil {
+		util.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.recomputeMu.Lock()
+	if s.recomputeJob != nil && s.recomputeJob.Active {
+		s.recomputeMu.Unlock()
+		util.WriteError(w, http.StatusConflict, "stats recompute already in progress")
+		return
+	}
+	now := time.Now().UTC()
+	s.recomputeJob = &model.StatsRecomputeJobStatus{
+		Active:           true,
+		JobID:            newPreviewID(),
+		State:            model.StatsRecomputeJobStateRunning,
+		MatchedEndpoints: int64(len(endpointIDs)),
+		StartedAt:        &now,
+		UpdatedAt:        &now,
+	}
+	jobID := s.recomputeJob.JobID
+	s.recomputeMu.Unlock()
+
+	go s.runStatsRecompute(jobID, endpointIDs)
+	util.WriteJSON(w, http.StatusAccepted, s.statsRecomputeSnapshot())
+}
+
+func (s *Server) handleAdminRecomputeStatsCurrent(w http.ResponseWriter, _ *http.Request) {
+	util.WriteJSON(w, http.StatusOK, s.statsRecomputeSnapshot())
+}
+
+func (s *Server) runStatsRecompute(jobID string, endpointIDs []int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundJobTimeout)
+	defer cancel()
+
+	var processed, recomputed int64
+	for start := 0; start < len(endpointIDs); start += statsRecomputeBatchSize {
+		end := start + statsRecomputeBatchSize
+		if end > len(endpointIDs) {
+			end = len(endpointIDs)
+		}
+		written, err := s.store.RecomputeEndpointStats(ctx, endpointIDs[start:end])
+		if err != nil {
+			slog.Warn("stats recompute batch failed", "job_id", jobID, "offset", start, "error", err)
+			s.completeStatsRecompute(jobID, model.StatsRecomputeJobStateFailed, err.Error())
+			return
+		}
+		processed = int64(end)
+		recomputed += written
+		s.updateStatsRecomputeJob(jobID, func(job *model.StatsRecomputeJobStatus) {
+			job.ProcessedEndpoints = processed
+			job.RecomputedEndpoints = recomputed
+			job.ProgressPct = float64(processed) / float64(len(endpointIDs)) * 100
+		})
+	}
+	s.completeStatsRecompute(jobID, model.StatsRecomputeJobStateCompleted, "")
+}
+
+func (s *Server) completeStatsRecompute(jobID string, state model.StatsRecomputeJobState, errMsg string) {
+	s.updateStatsRecomputeJob(jobID, func(job *model.StatsRecomputeJobStatus) {
+		job.Active = false
+		job.State = state
+		job.Error = errMsg
+		if state == model.StatsRecomputeJobStateCompleted {
+			job.ProgressPct = 100
+		}
+		now := time.Now().UTC()
+		job.CompletedAt = &now
+	})
+}
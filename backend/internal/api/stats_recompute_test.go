@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+func waitForStatsRecompute(t *testing.T, srv *Server) model.StatsRecomputeJobStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		rec := serveTestRequest(t, srv, http.MethodGet, "/api/admin/recompute-stats/current")
+		var status model.StatsRecomputeJobStatus
+		decodeTestResponse(t, rec, &status)
+		if !status.Active {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("stats recompute did not finish")
+	return model.StatsRecomputeJobStatus{}
+}
+
+func TestAdminRecomputeStatsRunsInBatches(t *testing.T) {
+	st := newMemoryStore()
+	for id := int64(1); id <= statsRecomputeBatchSize+5; id++ {
+		st.endpoints = append(st.endpoints, model.InventoryEndpointView{EndpointID: id})
+	}
+	srv := newTestServer(st)
+
+	rec := serveTestRequest(t, srv, http.MethodPost, "/api/admin/recompute-stats")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+
+	status := waitForStatsRecompute(t, srv)
+	if status.State != model.StatsRecomputeJobStateCompleted || status.ProcessedEndpoints != statsRecomputeBatchSize+5 || status.ProgressPct != 100 {
+		t.Fatalf("unexpected final status: %+v", status)
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if len(st.recomputed) != 2 || len(st.recomputed[0]) != statsRecomputeBatchSize || len(st.recomputed[1]) != 5 {
+		t.Fatalf("unexpected batches: %d", len(st.recomputed))
+	}
+}
+
+func TestAdminRecomputeStatsScopedToEndpointIDs(t *testing.T) {
+	st := newMemoryStore()
+	st.endpoints = []model.InventoryEndpointView{{EndpointID: 1}, {EndpointID: 2}, {EndpointID: 3}}
+	srv := newTestServer(st)
+
+	rec := serveTestJSONRequest(t, srv, http.MethodPost, "/api/admin/recompute-stats", map[string]any{"endpoint_ids": []int64{3, 42}})
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+	status := waitForStatsRecompute(t, srv)
+	if status.MatchedEndpoints != 1 || status.RecomputedEndpoints != 1 {
+		t.Fatalf("unexpected final status: %+v", status)
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+var _ serverStore = (*store.Store)(nil)
+
+type serverStore interface {
+	Ping(ctx context.Context) error
+	PoolStats() model.DBPoolStats
+
+	GetSettings(ctx context.Context) (model.Settings, error)
+	UpdateSettings(ctx context.Context, settings model.Settings) error
+	GetRetentionSettings(ctx context.Context) (model.RetentionSettings, error)
+	UpdateRetentionSettings(ctx context.Context, settings model.RetentionSettings) error
+
+	InventoryByIP(ctx context.Context) (map[string]model.InventoryEndpoint, error)
+	ApplyImport(ctx context.Context, rows []model.ImportCandidate) (int, int, []string, []string)
+	DryRunImport(ctx context.Context, rows []model.ImportCandidate) (int, int, []string, []string, error)
+	ListInventoryEndpoints(ctx context.Context, listQuery store.InventoryListQuery) ([]model.InventoryEndpointView, error)
+	ListInventoryEndpointsPage(ctx context.Context, listQuery store.InventoryListQuery) ([]model.InventoryEndpointView, int64, error)
+	ListInventoryEndpointsByIDs(ctx context.Context, endpointIDs []int64, limit int) ([]model.InventoryEndpointView, error)
+	GetInventoryEndpointByID(ctx context.Context, endpointID int64) (model.InventoryEndpointView, error)
+	CreateInventoryEndpoint(ctx context.Context, payload model.InventoryEndpointCreate) (model.InventoryEndpointView, error)
+	CreateInventoryEndpoints(ctx context.Context, payloads []model.InventoryEndpointCreate) ([]int64, []error, error)
+	UpdateInventoryEndpoint(ctx context.Context, endpointID int64, patch model.InventoryEndpointUpdate) (model.InventoryEndpointView, error)
+	SetInventoryEndpointActivity(ctx context.Context, endpointIDs []int64, active bool) (int64, error)
+	ListAllEndpointIDs(ctx context.Context) ([]int64, error)
+	ListProbeTargets(ctx context.Context, scope string, groupIDs []int64) ([]store.ProbeTarget, error)
+	ListEndpointIDsByGroup(ctx context.Context, groupID int64) ([]int64, error)
+	ResolveEndpointIDsByIPs(ctx context.Context, ips []string) ([]int64, error)
+	ResolveExistingInventoryEndpointIDs(ctx context.Context, endpointIDs []int64) ([]int64, error)
+	ResolveInventoryBatchMatch(ctx context.Context, spec model.InventoryBatchMatchSpec) (model.InventoryBatchMatchStats, []int64, error)
+	ResolveGroupInventoryBatchMatch(ctx context.Context, groupID int64, spec model.InventoryBatchMatchSpec) (model.InventoryBatchMatchStats, []int64, error)
+	DeleteInventoryEndpointsByIDsWithProgress(
+		ctx context.Context,
+		endpointIDs []int64,
+		endpointBatchSize int,
+		pingRowBatchSize int,
+		onProgress func(progress store.InventoryDeleteProgress),
+	) (int64, int64, error)
+	DeleteAllInventoryEndpointsFast(ctx context.Context) (int64, error)
+	PauseMaintenanceJobs(ctx context.Context) ([]int64, error)
+	ResumeJobs(ctx context.Context, jobIDs []int64) error
+	ResumePausedMaintenanceJobs(ctx context.Context) ([]int64, error)
+	ListDistinctFilters(ctx context.Context, activeOnly bool) (map[string][]string, error)
+	ListFilterCounts(ctx context.Context, query store.MonitorPageQuery) (map[string][]model.FilterValueCount, error)
+	ListInventoryEndpointHistory(ctx context.Context, endpointID int64, limit int) ([]model.InventoryAuditEntry, error)
+
+	ListGroups(ctx context.Context) ([]model.Group, error)
+	GetGroupByID(ctx context.Context, id int64) (model.Group, error)
+	GetGroupByNameCI(ctx context.Context, name string) (model.Group, error)
+	CreateGroup(ctx context.Context, name string, description string, endpointIDs []int64) (model.Group, error)
+	UpdateGroup(ctx context.Context, id int64, name string, description string, endpointIDs []int64) (model.Group, error)
+	DeleteGroup(ctx context.Context, id int64) error
+	AddEndpointsToGroup(ctx context.Context, groupID int64, endpointIDs []int64) (int64, error)
+	CountInventoryEndpointsInGroup(ctx context.Context, endpointIDs []int64, groupID int64) (int64, error)
+	SetGroupAlertThresholds(ctx context.Context, groupID int64, thresholds model.AlertThresholds) (model.Group, error)
+	SetGroupProbeInterval(ctx context.Context, groupID int64, intervalSec *int) (model.Group, error)
+	ListEndpointAlertGroups(ctx context.Context, endpointID int64) ([]model.Group, error)
+
+	ListSwitchDirectory(ctx context.Context) ([]model.SwitchDirectoryEntry, error)
+	SwitchDirectoryByName(ctx context.Context) (map[string]model.SwitchDirectoryEntry, error)
+	UpsertSwitchDirectoryEntry(ctx context.Context, name string, ipAddress string) (model.SwitchDirectoryEntry, error)
+	ApplySwitchDirectoryImport(ctx context.Context, rows []model.SwitchDirectoryImportCandidate) (int, int, error)
+	DeleteSwitchDirectoryEntry(ctx context.Context, id int64) error
+	GetSwitchIPMap(ctx context.Context) (map[string]string, error)
+
+	ListMaintenanceWindows(ctx context.Context) ([]model.MaintenanceWindow, error)
+	GetMaintenanceWindow(ctx context.Context, id int64) (model.MaintenanceWindow, error)
+	CreateMaintenanceWindow(ctx context.Context, input model.MaintenanceWindowInput) (model.MaintenanceWindow, error)
+	UpdateMaintenanceWindow(ctx context.Context, id int64, input model.MaintenanceWindowInput) (model.MaintenanceWindow, error)
+	DeleteMaintenanceWindow(ctx context.Context, id int64) error
+
+	ListMonitorEndpoints(ctx context.Context, filters store.MonitorFilters) ([]model.MonitorEndpoint, error)
+	ListMonitorEndpointsPage(ctx context.Context, query store.MonitorPageQuery) ([]model.MonitorEndpoint, int64, error)
+	ListMonitorEndpointsPageCursor(ctx context.Context, query store.MonitorPageQuery) ([]model.MonitorEndpoint, int64, string, error)
+	DashboardUnreachableSummary(ctx context.Context, query store.MonitorPageQuery) (model.DashboardUnreachableSummary, error)
+	MonitorFleetKPIs(ctx context.Context, query store.MonitorPageQuery) (model.MonitorFleetKPIs, error)
+	MonitorGroupSummaries(ctx context.Context, query store.MonitorPageQuery) ([]model.MonitorGroupSummary, error)
+	LastSeenBucketCounts(ctx context.Context, query store.MonitorPageQuery) ([]model.LastSeenBucketCount, error)
+	ListLastSeenBucket(ctx context.Context, query store.MonitorPageQuery, bucket string, limit int) ([]model.LastSeenEndpoint, error)
+	EndpointSLA(ctx context.Context, endpointIDs []int64, start, end time.Time, excludeMaintenance bool) ([]model.EndpointSLA, string, error)
+	ListDowntimeIncidents(ctx context.Context, endpointIDs []int64, start, end time.Time, limit int) ([]model.DowntimeIncident, error)
+	AnnotateDowntimeIncident(ctx context.Context, id int64, annotation model.DowntimeIncidentAnnotation) (model.DowntimeIncident, error)
+	ListEndpointRawPings(ctx context.Context, endpointID int64, start, end time.Time, limit int) ([]model.RawPingSample, error)
+	ResetEndpointStats(ctx context.Context, endpointIDs []int64) (int64, error)
+	RecomputeEndpointStats(ctx context.Context, endpointIDs []int64) (int64, error)
+	StreamEndpointStats(ctx context.Context, query store.MonitorPageQuery, fn func(model.EndpointStatsSnapshot) error) error
+	StreamMonitorEndpoints(ctx context.Context, query store.MonitorPageQuery, fn func(model.MonitorEndpoint) error) error
+	QueryTimeSeries(ctx context.Context, endpointIDs []int64, start time.Time, end time.Time) ([]model.TimeSeriesPoint, string, error)
+	SaveMonitorSnapshot(ctx context.Context, snapshot model.MonitorSnapshot) error
+	GetMonitorSnapshot(ctx context.Context, snapshotID string) (model.MonitorSnapshot, error)
+	DeleteMonitorSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
@@ -9,6 +9,7 @@ func TestValidateSettings(t *testing.T) {
 		payload     int
 		autoRefresh int
 		timeoutMs   int
+		pattern     string
 		wantErr     bool
 	}{
 		{name: "valid defaults", intervalSec: 1, payload: 56, autoRefresh: 30, timeoutMs: 500, wantErr: false},
@@ -18,13 +19,24 @@ func TestValidateSettings(t *testing.T) {
 		{name: "payload too large", intervalSec: 1, payload: 2000, autoRefresh: 30, timeoutMs: 500, wantErr: true},
 		{name: "timeout too small", intervalSec: 1, payload: 56, autoRefresh: 30, timeoutMs: 19, wantErr: true},
 		{name: "timeout too large", intervalSec: 1, payload: 56, autoRefresh: 30, timeoutMs: 1001, wantErr: true},
+		{name: "timeout equal to interval", intervalSec: 1, payload: 56, autoRefresh: 30, timeoutMs: 1000, wantErr: false},
 		{name: "auto refresh too small", intervalSec: 1, payload: 56, autoRefresh: 0, timeoutMs: 500, wantErr: true},
 		{name: "auto refresh too large", intervalSec: 1, payload: 56, autoRefresh: 61, timeoutMs: 500, wantErr: true},
+		{name: "incrementing pattern", intervalSec: 1, payload: 56, autoRefresh: 30, timeoutMs: 500, pattern: "incrementing", wantErr: false},
+		{name: "zeros pattern", intervalSec: 1, payload: 56, autoRefresh: 30, timeoutMs: 500, pattern: "zeros", wantErr: false},
+		{name: "random pattern", intervalSec: 1, payload: 8, autoRefresh: 30, timeoutMs: 500, pattern: "random", wantErr: false},
+		{name: "timestamp pattern", intervalSec: 1, payload: 16, autoRefresh: 30, timeoutMs: 500, pattern: "timestamp", wantErr: false},
+		{name: "timestamp pattern payload too small", intervalSec: 1, payload: 15, autoRefresh: 30, timeoutMs: 500, pattern: "timestamp", wantErr: true},
+		{name: "unknown pattern", intervalSec: 1, payload: 56, autoRefresh: 30, timeoutMs: 500, pattern: "0x42", wantErr: true},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := ValidateSettings(tc.intervalSec, tc.payload, tc.autoRefresh, tc.timeoutMs)
+			pattern := tc.pattern
+			if pattern == "" {
+				pattern = "filler"
+			}
+			err := ValidateSettings(tc.intervalSec, tc.payload, tc.autoRefresh, tc.timeoutMs, pattern)
 			if tc.wantErr && err == nil {
 				t.Fatalf("expected error, got nil")
 			}
@@ -34,3 +46,173 @@ func TestValidateSettings(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateProbeSettings(t *testing.T) {
+	tests := []struct {
+		mode    string
+		port    int
+		wantErr bool
+	}{
+		{mode: "icmp", port: 443},
+		{mode: "tcp", port: 22},
+		{mode: "tcp", port: 65535},
+		{mode: "http", port: 443, wantErr: true},
+		{mode: "", port: 443, wantErr: true},
+		{mode: "tcp", port: 0, wantErr: true},
+		{mode: "tcp", port: 65536, wantErr: true},
+	}
+	for _, tc := range tests {
+		err := ValidateProbeSettings(tc.mode, tc.port)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("ValidateProbeSettings(%q, %d) error = %v, wantErr %v", tc.mode, tc.port, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateTCPSourcePortRange(t *testing.T) {
+	tests := []struct {
+		min     int
+		max     int
+		wantErr bool
+	}{
+		{min: 0, max: 0},
+		{min: 40000, max: 40999},
+		{min: 50000, max: 50000},
+		{min: 0, max: 40000, wantErr: true},
+		{min: 80, max: 90, wantErr: true},
+		{min: 41000, max: 40000, wantErr: true},
+		{min: 60000, max: 70000, wantErr: true},
+	}
+	for _, tc := range tests {
+		err := ValidateTCPSourcePortRange(tc.min, tc.max)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("ValidateTCPSourcePortRange(%d, %d) error = %v, wantErr %v", tc.min, tc.max, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateICMPBaseID(t *testing.T) {
+	for _, baseID := range []int{0, 1, 255} {
+		if err := ValidateICMPBaseID(baseID); err != nil {
+			t.Fatalf("ValidateICMPBaseID(%d) error = %v", baseID, err)
+		}
+	}
+	for _, baseID := range []int{-1, 256, 0x5a00} {
+		if err := ValidateICMPBaseID(baseID); err == nil {
+			t.Fatalf("ValidateICMPBaseID(%d) accepted an id outside the high byte", baseID)
+		}
+	}
+}
+
+func TestValidateProbesPerRound(t *testing.T) {
+	tests := []struct {
+		probes      int
+		timeoutMs   int
+		intervalSec int
+		wantErr     bool
+	}{
+		{probes: 1, timeoutMs: 500, intervalSec: 1},
+		{probes: 2, timeoutMs: 500, intervalSec: 1},
+		{probes: 3, timeoutMs: 500, intervalSec: 1, wantErr: true},
+		{probes: 10, timeoutMs: 1000, intervalSec: 10},
+		{probes: 0, timeoutMs: 500, intervalSec: 1, wantErr: true},
+		{probes: 11, timeoutMs: 20, intervalSec: 30, wantErr: true},
+	}
+	for _, tc := range tests {
+		err := ValidateProbesPerRound(tc.probes, tc.timeoutMs, tc.intervalSec)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("ValidateProbesPerRound(%d, %d, %d) error = %v, wantErr %v", tc.probes, tc.timeoutMs, tc.intervalSec, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateProbeRetries(t *testing.T) {
+	tests := []struct {
+		retries     int
+		probes      int
+		timeoutMs   int
+		intervalSec int
+		wantErr     bool
+	}{
+		{retries: 0, probes: 1, timeoutMs: 1000, intervalSec: 1},
+		{retries: 1, probes: 1, timeoutMs: 500, intervalSec: 1},
+		{retries: 1, probes: 1, timeoutMs: 600, intervalSec: 1, wantErr: true},
+		{retries: 1, probes: 1, timeoutMs: 1000, intervalSec: 1, wantErr: true},
+		{retries: 1, probes: 1, timeoutMs: 1000, intervalSec: 2},
+		{retries: 2, probes: 2, timeoutMs: 500, intervalSec: 3},
+		{retries: 5, probes: 1, timeoutMs: 100, intervalSec: 1},
+		{retries: 6, probes: 1, timeoutMs: 100, intervalSec: 10, wantErr: true},
+		{retries: -1, probes: 1, timeoutMs: 100, intervalSec: 1, wantErr: true},
+	}
+	for _, tc := range tests {
+		err := ValidateProbeRetries(tc.retries, tc.probes, tc.timeoutMs, tc.intervalSec)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("ValidateProbeRetries(%d, %d, %d, %d) error = %v, wantErr %v", tc.retries, tc.probes, tc.timeoutMs, tc.intervalSec, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateDownThreshold(t *testing.T) {
+	for threshold, wantErr := range map[int]bool{0: true, 1: false, 3: false, 1000: false, 1001: true} {
+		if err := ValidateDownThreshold(threshold); (err != nil) != wantErr {
+			t.Fatalf("ValidateDownThreshold(%d) error = %v, wantErr %v", threshold, err, wantErr)
+		}
+	}
+}
+
+func TestGetEnvBool(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		set      bool
+		fallback bool
+		want     bool
+	}{
+		{name: "unset uses fallback", set: false, fallback: true, want: true},
+		{name: "false value", value: "false", set: true, fallback: true, want: false},
+		{name: "numeric true", value: " 1 ", set: true, fallback: false, want: true},
+		{name: "invalid uses fallback", value: "maybe", set: true, fallback: true, want: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.set {
+				t.Setenv("SONARSCOPE_TEST_BOOL", tc.value)
+			}
+			if got := getEnvBool("SONARSCOPE_TEST_BOOL", tc.fallback); got != tc.want {
+				t.Fatalf("getEnvBool() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateRetention(t *testing.T) {
+	if err := ValidateRetention(30, 365, 730); err != nil {
+		t.Fatalf("expected defaults to validate: %v", err)
+	}
+	for _, tc := range [][3]int{{0, 365, 730}, {91, 365, 730}, {30, 6, 730}, {30, 365, 731}, {30, 20, 730}, {30, 365, 300}} {
+		if err := ValidateRetention(tc[0], tc[1], tc[2]); err == nil {
+			t.Fatalf("expected %v to be rejected", tc)
+		}
+	}
+}
+
+func TestValidateLogging(t *testing.T) {
+	tests := []struct {
+		format  string
+		level   string
+		wantErr bool
+	}{
+		{format: "text", level: "info"},
+		{format: "json", level: "debug"},
+		{format: "json", level: "warn"},
+		{format: "logfmt", level: "info", wantErr: true},
+		{format: "json", level: "trace", wantErr: true},
+	}
+	for _, tc := range tests {
+		err := ValidateLogging(tc.format, tc.level)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("ValidateLogging(%q, %q) error = %v, wantErr %v", tc.format, tc.level, err, tc.wantErr)
+		}
+	}
+}
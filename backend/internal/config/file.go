@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fileValues holds settings loaded from CONFIG_FILE, keyed by env var name.
+// Environment variables always take precedence over file values.
+var fileValues map[string]string
+
+// fileKeysRead records which file keys Load asked for, so a key it never
+// reads can be reported instead of silently ignored.
+var fileKeysRead map[string]struct{}
+
+func lookupEnv(key string) (string, bool) {
+	if _, ok := fileValues[key]; ok {
+		if fileKeysRead == nil {
+			fileKeysRead = map[string]struct{}{}
+		}
+		fileKeysRead[key] = struct{}{}
+	}
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	value, ok := fileValues[key]
+	return value, ok
+}
+
+func loadConfigFile(path string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read CONFIG_FILE: %w", err)
+	}
+
+	var values map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		values, err = parseJSONConfig(raw)
+	case ".yaml", ".yml":
+		values, err = parseYAMLConfig(raw)
+	default:
+		return nil, fmt.Errorf("CONFIG_FILE must end in .json, .yaml, or .yml")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse CONFIG_FILE %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// checkConfigFileKeys fails Load when CONFIG_FILE sets keys no setting reads,
+// which is almost always a typo that would otherwise leave the default in
+// place. It must run after every setting has been looked up.
+func checkConfigFileKeys() error {
+	unknown := []string{}
+	for key := range fileValues {
+		if _, ok := fileKeysRead[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("CONFIG_FILE has unknown keys: %s", strings.Join(unknown, ", "))
+}
+
+func parseJSONConfig(raw []byte) (map[string]string, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(doc))
+	for key, rawValue := range doc {
+		value, err := jsonConfigValue(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		values[normalizeConfigKey(key)] = value
+	}
+	return values, nil
+}
+
+func jsonConfigValue(raw json.RawMessage) (string, error) {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+
+	switch typed := value.(type) {
+	case string:
+		return typed, nil
+	case json.Number:
+		return typed.String(), nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case []any:
+		items := make([]string, 0, len(typed))
+		for _, item := range typed {
+			text, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("list values must be strings")
+			}
+			items = append(items, text)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value type")
+	}
+}
+
+// parseYAMLConfig accepts a flat mapping of scalar values and inline string
+// lists, which covers every setting Load understands.
+func parseYAMLConfig(raw []byte) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := stripYAMLComment(scanner.Text())
+		if strings.TrimSpace(line) == "" || strings.TrimSpace(line) == "---" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("line %d: nested values are not supported", lineNo)
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("line %d: expected key: value", lineNo)
+		}
+		parsed, err := yamlConfigValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		values[normalizeConfigKey(key)] = parsed
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func yamlConfigValue(value string) (string, error) {
+	if strings.HasPrefix(value, "[") {
+		if !strings.HasSuffix(value, "]") {
+			return "", fmt.Errorf("unterminated list")
+		}
+		items := splitCSV(value[1 : len(value)-1])
+		for i, item := range items {
+			unquoted, err := unquoteYAMLScalar(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = unquoted
+		}
+		return strings.Join(items, ","), nil
+	}
+	return unquoteYAMLScalar(value)
+}
+
+func unquoteYAMLScalar(value string) (string, error) {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return strconv.Unquote(value)
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+	return value, nil
+}
+
+func stripYAMLComment(line string) string {
+	inSingle, inDouble := false, false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\'':
+			if !inDouble {
+				inSingle = !inSingle
+			}
+		case '"':
+			if !inSingle {
+				inDouble = !inDouble
+			}
+		case '#':
+			if !inSingle && !inDouble && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+				return strings.TrimRight(line[:i], " \t")
+			}
+		}
+	}
+	return strings.TrimRight(line, " \t\r")
+}
+
+func normalizeConfigKey(key string) string {
+	return strings.ToUpper(strings.TrimSpace(key))
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadReadsConfigFileWithEnvOverrides(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+	}{
+		{
+			name:     "json",
+			fileName: "sonarscope.json",
+			content: `{
+  "PROBE_WORKERS": 32,
+  "probe_adaptive_timeout": true,
+  "DEFAULT_ICMP_TIMEOUT_MS": "250",
+  "CORS_ALLOWED_ORIGINS": ["https://a.example", "https://b.example"]
+}`,
+		},
+		{
+			name:     "yaml",
+			fileName: "sonarscope.yaml",
+			content: `# probe settings
+PROBE_WORKERS: 32
+probe_adaptive_timeout: true # lowercase keys are accepted
+DEFAULT_ICMP_TIMEOUT_MS: "250"
+CORS_ALLOWED_ORIGINS: [https://a.example, 'https://b.example']
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.fileName)
+			if err := os.WriteFile(path, []byte(tc.content), 0o600); err != nil {
+				t.Fatalf("write config file: %v", err)
+			}
+			t.Setenv("CONFIG_FILE", path)
+			t.Setenv("PROBE_WORKERS", "64")
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.ProbeWorkers != 64 {
+				t.Fatalf("ProbeWorkers = %d, want env override 64", cfg.ProbeWorkers)
+			}
+			if !cfg.ProbeAdaptiveTimeout {
+				t.Fatalf("ProbeAdaptiveTimeout = false, want true from file")
+			}
+			if cfg.DefaultTimeoutMs != 250 {
+				t.Fatalf("DefaultTimeoutMs = %d, want 250", cfg.DefaultTimeoutMs)
+			}
+			wantOrigins := []string{"https://a.example", "https://b.example"}
+			if !reflect.DeepEqual(cfg.AllowedOrigins, wantOrigins) {
+				t.Fatalf("AllowedOrigins = %v, want %v", cfg.AllowedOrigins, wantOrigins)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsInvalidConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+	}{
+		{name: "unknown extension", fileName: "sonarscope.toml", content: "PROBE_WORKERS = 1"},
+		{name: "malformed json", fileName: "sonarscope.json", content: `{"PROBE_WORKERS":`},
+		{name: "nested yaml", fileName: "sonarscope.yml", content: "probe:\n  workers: 1\n"},
+		{name: "invalid settings", fileName: "sonarscope.json", content: `{"DEFAULT_PING_INTERVAL_SEC": 90}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.fileName)
+			if err := os.WriteFile(path, []byte(tc.content), 0o600); err != nil {
+				t.Fatalf("write config file: %v", err)
+			}
+			t.Setenv("CONFIG_FILE", path)
+			if _, err := Load(); err == nil {
+				t.Fatalf("expected Load() to fail")
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownConfigFileKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sonarscope.yaml")
+	content := "PROBE_WORKERS: 8\nprobe_workres: 16\nDEFAULT_ICMP_TIMEOUT_MS: 250\nPING_TIMEOUT_SEC: 1\nCONFIG_FILE: other.yaml\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("PROBE_WORKERS", "64")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatalf("expected Load() to fail on unknown keys")
+	}
+	if want := "CONFIG_FILE has unknown keys: CONFIG_FILE, PROBE_WORKRES"; err.Error() != want {
+		t.Fatalf("Load() error = %q, want %q", err, want)
+	}
+}
@@ -2,7 +2,10 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
@@ -11,8 +14,55 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func ApplyMigrations(ctx context.Context, pool *pgxpool.Pool, migrationsDir string) error {
-	if _, err := pool.Exec(ctx, `
+// migrationLockKey serializes ApplyMigrations across API replicas sharing a
+// database.
+const migrationLockKey int64 = 0x536f6e617253636f
+
+var ErrMigrationsDirNotFound = errors.New("migrations directory not found")
+
+// MigrationsDirFS opens an on-disk migrations directory, used to override the
+// embedded migrations during development.
+func MigrationsDirFS(migrationsDir string) (fs.FS, error) {
+	absDir, err := filepath.Abs(migrationsDir)
+	if err != nil {
+		absDir = migrationsDir
+	}
+	info, err := os.Stat(migrationsDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s (unset MIGRATIONS_DIR to use the embedded migrations)", ErrMigrationsDirNotFound, absDir)
+		}
+		return nil, fmt.Errorf("read migrations dir %s: %w", absDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("migrations path %s is not a directory", absDir)
+	}
+	return os.DirFS(migrationsDir), nil
+}
+
+func ApplyMigrations(ctx context.Context, pool *pgxpool.Pool, migrationsFS fs.FS) error {
+	versions, err := migrationVersions(migrationsFS)
+	if err != nil {
+		return err
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire migration connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+			// Closing the session drops the lock when the unlock itself fails.
+			_ = conn.Conn().Close(context.Background())
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version TEXT PRIMARY KEY,
 			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
@@ -21,35 +71,26 @@ func ApplyMigrations(ctx context.Context, pool *pgxpool.Pool, migrationsDir stri
 		return fmt.Errorf("create schema_migrations: %w", err)
 	}
 
-	entries, err := os.ReadDir(migrationsDir)
-	if err != nil {
-		return fmt.Errorf("read migrations dir: %w", err)
+	if len(versions) == 0 {
+		slog.Warn("no .sql migrations found; assuming schema is current")
+		return nil
 	}
-	versions := make([]string, 0, len(entries))
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
-			continue
-		}
-		versions = append(versions, entry.Name())
-	}
-	sort.Strings(versions)
 
 	for _, version := range versions {
 		var exists bool
-		if err := pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version=$1)", version).Scan(&exists); err != nil {
+		if err := conn.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version=$1)", version).Scan(&exists); err != nil {
 			return fmt.Errorf("check migration %s: %w", version, err)
 		}
 		if exists {
 			continue
 		}
 
-		path := filepath.Join(migrationsDir, version)
-		raw, err := os.ReadFile(path)
+		raw, err := fs.ReadFile(migrationsFS, version)
 		if err != nil {
 			return fmt.Errorf("read migration %s: %w", version, err)
 		}
 
-		tx, err := pool.Begin(ctx)
+		tx, err := conn.Begin(ctx)
 		if err != nil {
 			return fmt.Errorf("begin migration %s: %w", version, err)
 		}
@@ -68,3 +109,19 @@ func ApplyMigrations(ctx context.Context, pool *pgxpool.Pool, migrationsDir stri
 
 	return nil
 }
+
+func migrationVersions(migrationsFS fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(migrationsFS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	versions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		versions = append(versions, entry.Name())
+	}
+	sort.Strings(versions)
+	return versions, nil
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"sonarscope/backend/migrations"
+)
+
+func TestMigrationVersionsSortsSQLFilesOnly(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_later.sql":       {Data: []byte("SELECT 10;")},
+		"002_second.sql":      {Data: []byte("SELECT 2;")},
+		"001_first.sql":       {Data: []byte("SELECT 1;")},
+		"README.md":           {Data: []byte("notes")},
+		"embed.go":            {Data: []byte("package migrations")},
+		"archive/000_old.sql": {Data: []byte("SELECT 0;")},
+	}
+
+	got, err := migrationVersions(fsys)
+	if err != nil {
+		t.Fatalf("migrationVersions() error = %v", err)
+	}
+	want := []string{"001_first.sql", "002_second.sql", "010_later.sql"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("migrationVersions() = %v, want %v", got, want)
+	}
+
+	empty, err := migrationVersions(fstest.MapFS{})
+	if err != nil || len(empty) != 0 {
+		t.Fatalf("migrationVersions(empty) = %v, %v; want no versions", empty, err)
+	}
+}
+
+func TestEmbeddedMigrationsStartAtInitialSchema(t *testing.T) {
+	versions, err := migrationVersions(migrations.FS)
+	if err != nil {
+		t.Fatalf("migrationVersions(embedded) error = %v", err)
+	}
+	if len(versions) == 0 || versions[0] != "001_init.sql" {
+		t.Fatalf("embedded migrations = %v, want 001_init.sql first", versions)
+	}
+}
+
+func TestMigrationsDirFSReportsMissingDirectory(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	if _, err := MigrationsDirFS(missing); !errors.Is(err, ErrMigrationsDirNotFound) {
+		t.Fatalf("MigrationsDirFS(missing) error = %v, want ErrMigrationsDirNotFound", err)
+	}
+}
+
+func TestApplyMigrationsConcurrentCallersApplyOnce(t *testing.T) {
+	databaseURL := os.Getenv("SONARSCOPE_TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("SONARSCOPE_TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	pool, err := Connect(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer pool.Close()
+
+	version := fmt.Sprintf("999_lock_test_%d.sql", time.Now().UnixNano())
+	table := fmt.Sprintf("migration_lock_test_%d", time.Now().UnixNano())
+	fsys := fstest.MapFS{
+		// Without the advisory lock the second caller fails on the duplicate
+		// CREATE TABLE or the schema_migrations primary key.
+		version: {Data: []byte(fmt.Sprintf("SELECT pg_sleep(0.2); CREATE TABLE %s (id INT);", table))},
+	}
+	defer func() {
+		_, _ = pool.Exec(ctx, "DROP TABLE IF EXISTS "+table)
+		_, _ = pool.Exec(ctx, "DELETE FROM schema_migrations WHERE version=$1", version)
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- ApplyMigrations(ctx, pool, fsys)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("ApplyMigrations() error = %v", err)
+		}
+	}
+
+	var applied int
+	if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM schema_migrations WHERE version=$1", version).Scan(&applied); err != nil {
+		t.Fatalf("count applied migrations: %v", err)
+	}
+	if applied != 1 {
+		t.Fatalf("migration recorded %d times, want 1", applied)
+	}
+}
@@ -75,13 +75,17 @@ func init() {
 	}
 }
 
-func Parse(fileName string, raw []byte) ([]model.ImportCandidate, error) {
+// Parse reads an inventory upload. Each enabled custom field in customFields
+// also accepts its configured name as a column header, so exported files
+// import back unchanged.
+func Parse(fileName string, raw []byte, customFields []model.CustomFieldConfig) ([]model.ImportCandidate, error) {
+	aliases := customFieldHeaderAliases(customFields)
 	ext := strings.ToLower(filepath.Ext(fileName))
 	switch ext {
 	case ".csv":
-		return parseCSV(raw)
+		return parseCSV(raw, aliases)
 	case ".xlsx", ".xlsm", ".xls":
-		return parseXLSX(raw)
+		return parseXLSX(raw, aliases)
 	default:
 		return nil, fmt.Errorf("unsupported file extension %q", ext)
 	}
@@ -127,8 +131,45 @@ func Classify(candidates []model.ImportCandidate, existing map[string]model.Inve
 	return result
 }
 
-func parseCSV(raw []byte) ([]model.ImportCandidate, error) {
+// customFieldHeaderAliases maps the normalized name of each enabled custom
+// field to its slot column. Names that collide with a built-in header keep
+// the built-in meaning.
+func customFieldHeaderAliases(customFields []model.CustomFieldConfig) map[string]string {
+	aliases := map[string]string{}
+	for _, field := range customFields {
+		if !field.Enabled || field.Slot < 1 || field.Slot > model.MaxCustomFieldSlots {
+			continue
+		}
+		normalized := normalizeHeader(field.Name)
+		if normalized == "" {
+			continue
+		}
+		if _, builtIn := headerAliases[normalized]; builtIn {
+			continue
+		}
+		aliases[normalized] = fmt.Sprintf("custom_field_%d_value", field.Slot)
+	}
+	return aliases
+}
+
+func parseCSV(raw []byte, aliases map[string]string) ([]model.ImportCandidate, error) {
+	rows, err := readCSVRows(raw)
+	if err != nil {
+		return nil, err
+	}
+	return parseRows(rows, aliases)
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// readCSVRows reads every record of a CSV upload. A leading UTF-8 BOM, as
+// written by Excel, is stripped, and the delimiter is sniffed from the header
+// line so semicolon and tab separated exports parse too.
+func readCSVRows(raw []byte) ([][]string, error) {
+	raw = bytes.TrimPrefix(raw, utf8BOM)
+
 	reader := csv.NewReader(bytes.NewReader(raw))
+	reader.Comma = sniffCSVDelimiter(raw)
 	reader.TrimLeadingSpace = true
 	reader.FieldsPerRecord = -1
 
@@ -143,11 +184,42 @@ func parseCSV(raw []byte) ([]model.ImportCandidate, error) {
 		}
 		rows = append(rows, record)
 	}
+	return rows, nil
+}
 
-	return parseRows(rows)
+// sniffCSVDelimiter picks whichever of comma, semicolon, or tab occurs most
+// often outside quotes on the first non-blank, non-comment line. Comma wins
+// ties and lines with none of them.
+func sniffCSVDelimiter(raw []byte) rune {
+	for _, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		counts := map[rune]int{}
+		inQuotes := false
+		for _, r := range line {
+			switch r {
+			case '"':
+				inQuotes = !inQuotes
+			case ',', ';', '\t':
+				if !inQuotes {
+					counts[r]++
+				}
+			}
+		}
+		best := ','
+		for _, candidate := range []rune{';', '\t'} {
+			if counts[candidate] > counts[best] {
+				best = candidate
+			}
+		}
+		return best
+	}
+	return ','
 }
 
-func parseXLSX(raw []byte) ([]model.ImportCandidate, error) {
+func parseXLSX(raw []byte, aliases map[string]string) ([]model.ImportCandidate, error) {
 	book, err := excelize.OpenReader(bytes.NewReader(raw))
 	if err != nil {
 		return nil, fmt.Errorf("open workbook: %w", err)
@@ -164,10 +236,12 @@ func parseXLSX(raw []byte) ([]model.ImportCandidate, error) {
 		return nil, fmt.Errorf("read worksheet rows: %w", err)
 	}
 
-	return parseRows(rows)
+	return parseRows(rows, aliases)
 }
 
-func parseRows(rows [][]string) ([]model.ImportCandidate, error) {
+// parseRows maps the header row through headerAliases and then through
+// aliases, the per-upload custom field names.
+func parseRows(rows [][]string, aliases map[string]string) ([]model.ImportCandidate, error) {
 	if len(rows) == 0 {
 		return nil, fmt.Errorf("input is empty")
 	}
@@ -178,7 +252,7 @@ func parseRows(rows [][]string) ([]model.ImportCandidate, error) {
 		if isCommentOrEmptyRow(row) {
 			continue
 		}
-		mapped, err := mapHeaders(row)
+		mapped, err := mapHeaders(row, aliases)
 		if err != nil {
 			return nil, err
 		}
@@ -205,7 +279,9 @@ func parseRows(rows [][]string) ([]model.ImportCandidate, error) {
 
 		candidate.IP = cellByKey(row, headerMap, "ip")
 		candidate.Hostname = cellByKey(row, headerMap, "hostname")
-		candidate.MAC = normalizeMAC(cellByKey(row, headerMap, "mac"))
+		rawMAC := cellByKey(row, headerMap, "mac")
+		mac, macOK := normalizeMAC(rawMAC)
+		candidate.MAC = mac
 		candidate.CustomField1Value = cellByKey(row, headerMap, "custom_field_1_value")
 		candidate.CustomField2Value = cellByKey(row, headerMap, "custom_field_2_value")
 		candidate.CustomField3Value = cellByKey(row, headerMap, "custom_field_3_value")
@@ -248,6 +324,12 @@ func parseRows(rows [][]string) ([]model.ImportCandidate, error) {
 			result = append(result, candidate)
 			continue
 		}
+		if !macOK {
+			candidate.MAC = rawMAC
+			candidate.Message = "invalid MAC format (expected 6 hex octets, e.g. AA:BB:CC:DD:EE:FF or aabb.ccdd.eeff)"
+			result = append(result, candidate)
+			continue
+		}
 		candidate.Action = model.ImportAdd
 		result = append(result, candidate)
 	}
@@ -255,11 +337,15 @@ func parseRows(rows [][]string) ([]model.ImportCandidate, error) {
 	return result, nil
 }
 
-func mapHeaders(headers []string) (map[string]int, error) {
+func mapHeaders(headers []string, aliases map[string]string) (map[string]int, error) {
 	mapped := map[string]int{}
 	for idx, header := range headers {
 		normalized := normalizeHeader(header)
-		if key, ok := headerAliases[normalized]; ok {
+		key, ok := headerAliases[normalized]
+		if !ok {
+			key, ok = aliases[normalized]
+		}
+		if ok {
 			if _, exists := mapped[key]; exists {
 				continue
 			}
@@ -325,13 +411,60 @@ func cellByKey(row []string, headerMap map[string]int, key string) string {
 	return cell(row, idx)
 }
 
-func normalizeMAC(mac string) string {
-	mac = strings.TrimSpace(strings.ToUpper(mac))
+// normalizeMAC returns mac in canonical AA:BB:CC:DD:EE:FF form. It accepts
+// colon or hyphen separated octets, Cisco dotted aabb.ccdd.eeff, and bare
+// 12-digit hex. A blank value is valid and stays blank; anything else that is
+// not exactly 6 hex octets reports false.
+func normalizeMAC(mac string) (string, bool) {
+	mac = strings.TrimSpace(mac)
 	if mac == "" {
+		return "", true
+	}
+
+	var digits string
+	switch {
+	case strings.Contains(mac, "."):
+		digits = joinMACGroups(strings.Split(mac, "."), 3, 4)
+	case strings.ContainsAny(mac, ":-"):
+		digits = joinMACGroups(strings.FieldsFunc(mac, func(r rune) bool { return r == ':' || r == '-' }), 6, 2)
+		if strings.Count(mac, ":")+strings.Count(mac, "-") != 5 {
+			digits = ""
+		}
+	default:
+		digits = mac
+	}
+	if len(digits) != 12 {
+		return "", false
+	}
+	for _, r := range digits {
+		if !unicode.Is(unicode.ASCII_Hex_Digit, r) {
+			return "", false
+		}
+	}
+
+	digits = strings.ToUpper(digits)
+	var b strings.Builder
+	for i := 0; i < 12; i += 2 {
+		if i > 0 {
+			b.WriteByte(':')
+		}
+		b.WriteString(digits[i : i+2])
+	}
+	return b.String(), true
+}
+
+// joinMACGroups concatenates groups when there are exactly count of them and
+// each has width characters, otherwise it returns "".
+func joinMACGroups(groups []string, count int, width int) string {
+	if len(groups) != count {
 		return ""
 	}
-	mac = strings.ReplaceAll(mac, "-", ":")
-	return mac
+	for _, group := range groups {
+		if len(group) != width {
+			return ""
+		}
+	}
+	return strings.Join(groups, "")
 }
 
 func normalizePortType(value string) string {
@@ -25,7 +25,7 @@ func TestClassify(t *testing.T) {
 	}
 
 	input := []model.ImportCandidate{
-		{RowID: "row-2", IP: "10.0.0.2", MAC: "11:22", VLAN: "200", SwitchName: "sw2", Port: "1/2", Action: model.ImportAdd},
+		{RowID: "row-2", IP: "10.0.0.2", MAC: "11:22:33:44:55:66", VLAN: "200", SwitchName: "sw2", Port: "1/2", Action: model.ImportAdd},
 		{RowID: "row-3", IP: "10.0.0.1", MAC: "AA:BB:CC:DD:EE:FF", VLAN: "100", SwitchName: "sw1", Port: "1/1", PortType: "access", Description: "db", Hostname: "db1", Action: model.ImportAdd},
 		{RowID: "row-4", IP: "10.0.0.1", MAC: "AA:BB:CC:DD:EE:01", VLAN: "100", SwitchName: "sw1", Port: "1/1", PortType: "access", Description: "db", Hostname: "db1", Action: model.ImportAdd},
 	}
@@ -82,7 +82,7 @@ func TestParseRowsMissingIP(t *testing.T) {
 		{"sw", "1/1", "", "", "", "", "", ""},
 	}
 
-	candidates, err := parseRows(rows)
+	candidates, err := parseRows(rows, nil)
 	if err != nil {
 		t.Fatalf("unexpected parse error: %v", err)
 	}
@@ -100,7 +100,7 @@ func TestParseRowsIPOnlyHeader(t *testing.T) {
 		{"10.0.0.2"},
 	}
 
-	candidates, err := parseRows(rows)
+	candidates, err := parseRows(rows, nil)
 	if err != nil {
 		t.Fatalf("unexpected parse error: %v", err)
 	}
@@ -124,7 +124,7 @@ func TestParseRowsSkipsCommentAndBlankRows(t *testing.T) {
 		{"10.0.0.3", "edge-1"},
 	}
 
-	candidates, err := parseRows(rows)
+	candidates, err := parseRows(rows, nil)
 	if err != nil {
 		t.Fatalf("unexpected parse error: %v", err)
 	}
@@ -135,3 +135,118 @@ func TestParseRowsSkipsCommentAndBlankRows(t *testing.T) {
 		t.Fatalf("unexpected parsed row: %#v", candidates[0])
 	}
 }
+
+func TestNormalizeMAC(t *testing.T) {
+	cases := []struct {
+		in    string
+		want  string
+		valid bool
+	}{
+		{"", "", true},
+		{"aa:bb:cc:dd:ee:ff", "AA:BB:CC:DD:EE:FF", true},
+		{"aa-bb-cc-dd-ee-01", "AA:BB:CC:DD:EE:01", true},
+		{" aabb.ccdd.eeff ", "AA:BB:CC:DD:EE:FF", true},
+		{"aabbccddeeff", "AA:BB:CC:DD:EE:FF", true},
+		{"11:22", "", false},
+		{"aa:bb:cc:dd:ee:gg", "", false},
+		{"a:b:c:d:e:f", "", false},
+		{"aa:bb:cc:dd:ee:ff:", "", false},
+		{"aabb.ccdd.ee", "", false},
+	}
+	for _, tc := range cases {
+		got, ok := normalizeMAC(tc.in)
+		if got != tc.want || ok != tc.valid {
+			t.Fatalf("normalizeMAC(%q) = %q, %v; want %q, %v", tc.in, got, ok, tc.want, tc.valid)
+		}
+	}
+}
+
+func TestParseRowsRejectsMalformedMAC(t *testing.T) {
+	rows := [][]string{
+		{"ip_address", "mac"},
+		{"10.0.0.1", "11:22"},
+		{"10.0.0.2", "0011.2233.4455"},
+		{"10.0.0.3", ""},
+	}
+
+	candidates, err := parseRows(rows, nil)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if len(candidates) != 3 {
+		t.Fatalf("expected 3 candidates, got %d", len(candidates))
+	}
+	if candidates[0].Action != model.ImportInvalid || candidates[0].MAC != "11:22" {
+		t.Fatalf("expected malformed MAC row invalid, got %#v", candidates[0])
+	}
+	if candidates[1].Action != model.ImportAdd || candidates[1].MAC != "00:11:22:33:44:55" {
+		t.Fatalf("expected dotted MAC normalized, got %#v", candidates[1])
+	}
+	if candidates[2].Action != model.ImportAdd || candidates[2].MAC != "" {
+		t.Fatalf("expected blank MAC allowed, got %#v", candidates[2])
+	}
+}
+
+func TestParseCSVStripsUTF8BOM(t *testing.T) {
+	raw := []byte("\xEF\xBB\xBFip_address,hostname\n10.0.0.4,edge-4\n")
+
+	candidates, err := Parse("export.csv", raw, nil)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].IP != "10.0.0.4" || candidates[0].Hostname != "edge-4" {
+		t.Fatalf("unexpected parsed rows: %#v", candidates)
+	}
+}
+
+func TestParseCSVSniffsSemicolonAndTabDelimiters(t *testing.T) {
+	files := map[string]string{
+		"semicolon": "# Required: ip_address\nip_address;hostname;description\n10.0.0.5;edge-5;\"rack 1, row 2\"\n",
+		"tab":       "ip_address\thostname\n10.0.0.5\tedge-5\n",
+	}
+	for name, raw := range files {
+		candidates, err := Parse("export.csv", []byte(raw), nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected parse error: %v", name, err)
+		}
+		if len(candidates) != 1 || candidates[0].IP != "10.0.0.5" || candidates[0].Hostname != "edge-5" {
+			t.Fatalf("%s: unexpected parsed rows: %#v", name, candidates)
+		}
+	}
+}
+
+func TestSniffCSVDelimiterIgnoresQuotedSeparators(t *testing.T) {
+	if got := sniffCSVDelimiter([]byte("\"a;b;c\",ip\n")); got != ',' {
+		t.Fatalf("expected comma, got %q", got)
+	}
+	if got := sniffCSVDelimiter([]byte("ip_address\n")); got != ',' {
+		t.Fatalf("expected comma default, got %q", got)
+	}
+}
+
+func TestParseAcceptsConfiguredCustomFieldNames(t *testing.T) {
+	raw := []byte("IP Address,Owner,Rack Unit,Zone\n10.0.0.6,alice,r12,dmz\n")
+	customFields := []model.CustomFieldConfig{
+		{Slot: 1, Enabled: true, Name: "Owner"},
+		{Slot: 2, Enabled: false, Name: "Rack Unit"},
+		{Slot: 3, Enabled: true, Name: "Zone"},
+	}
+
+	candidates, err := Parse("export.csv", raw, customFields)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if len(candidates) != 1 {
+		t.Fatalf("unexpected parsed rows: %#v", candidates)
+	}
+	got := candidates[0]
+	if got.CustomField1Value != "alice" {
+		t.Fatalf("expected Owner column in custom field 1, got %#v", got)
+	}
+	if got.CustomField2Value != "" {
+		t.Fatalf("disabled custom field name should not be mapped, got %q", got.CustomField2Value)
+	}
+	if got.Zone != "dmz" || got.CustomField3Value != "" {
+		t.Fatalf("built-in header should win over a custom field name, got zone %q custom 3 %q", got.Zone, got.CustomField3Value)
+	}
+}
@@ -1,10 +1,7 @@
 package importer
 
 import (
-	"bytes"
-	"encoding/csv"
 	"fmt"
-	"io"
 	"net"
 	"strconv"
 
@@ -22,22 +19,10 @@ var switchDirectoryHeaderAliases = map[string]string{
 }
 
 func ParseSwitchDirectoryCSV(raw []byte) ([]model.SwitchDirectoryImportCandidate, error) {
-	reader := csv.NewReader(bytes.NewReader(raw))
-	reader.TrimLeadingSpace = true
-	reader.FieldsPerRecord = -1
-
-	rows := [][]string{}
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("read csv: %w", err)
-		}
-		rows = append(rows, record)
+	rows, err := readCSVRows(raw)
+	if err != nil {
+		return nil, err
 	}
-
 	return parseSwitchDirectoryRows(rows)
 }
 
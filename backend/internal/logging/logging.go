@@ -0,0 +1,35 @@
+// Package logging builds the process-wide slog logger.
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// ParseLevel maps LOG_LEVEL values onto slog levels; empty means info.
+func ParseLevel(level string) (slog.Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, true
+	case "", "info":
+		return slog.LevelInfo, true
+	case "warn", "warning":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	default:
+		return slog.LevelInfo, false
+	}
+}
+
+// New returns a JSON logger for format "json" and a text logger otherwise.
+// Unknown levels fall back to info; config.Load rejects them first.
+func New(w io.Writer, format, level string) *slog.Logger {
+	parsed, _ := ParseLevel(level)
+	options := &slog.HandlerOptions{Level: parsed}
+	if strings.EqualFold(strings.TrimSpace(format), "json") {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
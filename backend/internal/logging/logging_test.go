@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewJSONLoggerEmitsFieldsAndHonorsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "json", "warn")
+	logger.Info("dropped")
+	logger.Warn("probe round finished", "round_id", 7, "overrun", true)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("lines = %q, want only the warn line", lines)
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("line is not JSON: %v", err)
+	}
+	if record["msg"] != "probe round finished" || record["round_id"] != float64(7) || record["overrun"] != true {
+		t.Fatalf("unexpected record: %v", record)
+	}
+}
+
+func TestNewDefaultsToText(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf, "", "").Info("listening", "addr", ":8080")
+	if !strings.Contains(buf.String(), `msg=listening addr=:8080`) {
+		t.Fatalf("unexpected text output: %q", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range []string{"", "debug", "INFO", "warn", "error"} {
+		if _, ok := ParseLevel(level); !ok {
+			t.Fatalf("ParseLevel(%q) should be accepted", level)
+		}
+	}
+	if _, ok := ParseLevel("verbose"); ok {
+		t.Fatalf("ParseLevel(verbose) should be rejected")
+	}
+}
@@ -0,0 +1,153 @@
+package maintenance
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed five-field cron expression: minute, hour, day of
+// month, month, and day of week. Each field accepts *, a value, a range
+// (a-b), a step (*/n or a-b/n), or a comma-separated list of those. Day of
+// week runs 0-6 from Sunday, and 7 is also Sunday. As in cron, when both day
+// fields are restricted a day matches if either one does.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+func ParseSchedule(expr string) (Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return Schedule{}, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	var bits [5]uint64
+	for i, raw := range fields {
+		mask, err := parseCronField(raw, cronFields[i])
+		if err != nil {
+			return Schedule{}, err
+		}
+		bits[i] = mask
+	}
+	// Fold 7 onto 0 so Sunday has one bit.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+	return Schedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(raw string, field cronField) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(raw, ",") {
+		rangePart, step := part, 1
+		if head, tail, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(tail)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field", tail, field.name)
+			}
+			rangePart, step = head, n
+		}
+
+		lo, hi := field.min, field.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			head, tail, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseCronValue(head, field); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(tail, field); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangePart, field.name)
+			}
+		default:
+			value, err := parseCronValue(rangePart, field)
+			if err != nil {
+				return 0, err
+			}
+			lo = value
+			if step == 1 {
+				hi = value
+			}
+		}
+		for value := lo; value <= hi; value += step {
+			mask |= 1 << uint(value)
+		}
+	}
+	return mask, nil
+}
+
+func parseCronValue(raw string, field cronField) (int, error) {
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < field.min || value > field.max {
+		return 0, fmt.Errorf("%s value %q must be between %d and %d", field.name, raw, field.min, field.max)
+	}
+	return value, nil
+}
+
+// Matches reports whether the minute containing t is a scheduled time. t is
+// read in its own location.
+func (s Schedule) Matches(t time.Time) bool {
+	return s.minutesBack(t) == 0
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	if s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// minutesBack returns 0 when the minute containing t is scheduled, and
+// otherwise how many minutes back on t's wall clock the latest minute that
+// can still be scheduled is: the last matching minute earlier in the hour,
+// the end of the previous hour, or the end of the previous day.
+func (s Schedule) minutesBack(t time.Time) int {
+	hour, minute := t.Hour(), t.Minute()
+	if !s.dayMatches(t) {
+		return hour*60 + minute + 1
+	}
+	if s.hour&(1<<uint(hour)) == 0 {
+		return minute + 1
+	}
+	if s.minute&(1<<uint(minute)) != 0 {
+		return 0
+	}
+	earlier := s.minute & (1<<uint(minute) - 1)
+	if earlier == 0 {
+		return minute + 1
+	}
+	return minute - (bits.Len64(earlier) - 1)
+}
@@ -0,0 +1,42 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleMatches(t *testing.T) {
+	tests := []struct {
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{expr: "0 2 * * *", at: time.Date(2026, 3, 4, 2, 0, 30, 0, time.UTC), want: true},
+		{expr: "0 2 * * *", at: time.Date(2026, 3, 4, 2, 1, 0, 0, time.UTC), want: false},
+		{expr: "*/15 * * * *", at: time.Date(2026, 3, 4, 9, 45, 0, 0, time.UTC), want: true},
+		{expr: "*/15 * * * *", at: time.Date(2026, 3, 4, 9, 50, 0, 0, time.UTC), want: false},
+		{expr: "30 1 * * 1-5", at: time.Date(2026, 3, 6, 1, 30, 0, 0, time.UTC), want: true},  // Friday
+		{expr: "30 1 * * 1-5", at: time.Date(2026, 3, 7, 1, 30, 0, 0, time.UTC), want: false}, // Saturday
+		{expr: "0 0 * * 7", at: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC), want: true},      // Sunday
+		{expr: "0 3 1,15 * *", at: time.Date(2026, 3, 15, 3, 0, 0, 0, time.UTC), want: true},
+		// Both day fields restricted: either one matching is enough.
+		{expr: "0 3 1 * 6", at: time.Date(2026, 3, 7, 3, 0, 0, 0, time.UTC), want: true},
+	}
+	for _, tc := range tests {
+		schedule, err := ParseSchedule(tc.expr)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) error: %v", tc.expr, err)
+		}
+		if got := schedule.Matches(tc.at); got != tc.want {
+			t.Fatalf("%q.Matches(%s) = %v, want %v", tc.expr, tc.at, got, tc.want)
+		}
+	}
+}
+
+func TestParseScheduleRejectsInvalid(t *testing.T) {
+	for _, expr := range []string{"", "0 2 * *", "60 * * * *", "0 24 * * *", "0 0 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Fatalf("ParseSchedule(%q) should fail", expr)
+		}
+	}
+}
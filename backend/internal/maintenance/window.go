@@ -0,0 +1,135 @@
+package maintenance
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// MaxRecurringDuration bounds a single occurrence of a recurring window, which
+// also bounds how far back ActiveAt has to look for the occurrence start.
+const MaxRecurringDuration = 7 * 24 * time.Hour
+
+// Window is one maintenance window. Without a schedule it covers
+// [StartsAt, EndsAt). With one, StartsAt to EndsAt is the first occurrence:
+// every minute on or after StartsAt that matches the schedule starts another
+// occurrence of the same length, evaluated in Location.
+type Window struct {
+	StartsAt time.Time
+	EndsAt   time.Time
+	Schedule *Schedule
+	Location *time.Location
+}
+
+// NewWindow validates the stored form of a window. An empty recurrence is a
+// one-off window and an empty timezone means UTC.
+func NewWindow(startsAt, endsAt time.Time, recurrence, timezone string) (Window, error) {
+	if !startsAt.Before(endsAt) {
+		return Window{}, errors.New("starts_at must be before ends_at")
+	}
+	window := Window{StartsAt: startsAt, EndsAt: endsAt, Location: time.UTC}
+	if tz := strings.TrimSpace(timezone); tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			return Window{}, errors.New("timezone must be an IANA zone name")
+		}
+		window.Location = location
+	}
+	if expr := strings.TrimSpace(recurrence); expr != "" {
+		if endsAt.Sub(startsAt) > MaxRecurringDuration {
+			return Window{}, errors.New("a recurring window may last at most 7 days")
+		}
+		schedule, err := ParseSchedule(expr)
+		if err != nil {
+			return Window{}, err
+		}
+		window.Schedule = &schedule
+	}
+	return window, nil
+}
+
+func (w Window) ActiveAt(t time.Time) bool {
+	if t.Before(w.StartsAt) {
+		return false
+	}
+	if w.Schedule == nil {
+		return t.Before(w.EndsAt)
+	}
+	start, ok := w.latestStart(t)
+	return ok && t.Sub(start) < w.EndsAt.Sub(w.StartsAt)
+}
+
+// latestStart returns the latest occurrence of a recurring window that
+// starts at or before t, looking back no further than one occurrence length.
+// It jumps over whole days, hours and minute runs the schedule rules out
+// instead of testing every minute. A jump that would cross a UTC offset
+// change steps one minute instead, so wall-clock arithmetic never skips a
+// minute the zone change made scheduled.
+func (w Window) latestStart(t time.Time) (time.Time, bool) {
+	duration := w.EndsAt.Sub(w.StartsAt)
+	first := w.StartsAt.Truncate(time.Minute)
+	location := w.Location
+	if location == nil {
+		location = time.UTC
+	}
+	for start := t.Truncate(time.Minute); !start.Before(first) && t.Sub(start) < duration; {
+		local := start.In(location)
+		back := w.Schedule.minutesBack(local)
+		if back == 0 {
+			return start, true
+		}
+		previous := start.Add(-time.Duration(back) * time.Minute)
+		if _, offset := local.Zone(); back > 1 {
+			if _, previousOffset := previous.In(location).Zone(); previousOffset != offset {
+				previous = start.Add(-time.Minute)
+			}
+		}
+		start = previous
+	}
+	return time.Time{}, false
+}
+
+// Set answers which endpoints are under maintenance. It is built once from
+// the stored windows and is not safe for concurrent Add calls.
+type Set struct {
+	all        []Window
+	byEndpoint map[int64][]Window
+}
+
+func NewSet() *Set {
+	return &Set{byEndpoint: map[int64][]Window{}}
+}
+
+// AddAll registers a window that covers every endpoint.
+func (s *Set) AddAll(window Window) {
+	s.all = append(s.all, window)
+}
+
+// AddEndpoints registers a window for the given endpoints only.
+func (s *Set) AddEndpoints(window Window, endpointIDs []int64) {
+	for _, endpointID := range endpointIDs {
+		s.byEndpoint[endpointID] = append(s.byEndpoint[endpointID], window)
+	}
+}
+
+func (s *Set) Empty() bool {
+	return s == nil || (len(s.all) == 0 && len(s.byEndpoint) == 0)
+}
+
+// Covers reports whether a probe of endpointID at t falls inside any window.
+func (s *Set) Covers(endpointID int64, t time.Time) bool {
+	if s == nil {
+		return false
+	}
+	for _, window := range s.all {
+		if window.ActiveAt(t) {
+			return true
+		}
+	}
+	for _, window := range s.byEndpoint[endpointID] {
+		if window.ActiveAt(t) {
+			return true
+		}
+	}
+	return false
+}
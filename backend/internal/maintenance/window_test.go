@@ -0,0 +1,136 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowActiveAtOneOff(t *testing.T) {
+	start := time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC)
+	window, err := NewWindow(start, start.Add(30*time.Minute), "", "")
+	if err != nil {
+		t.Fatalf("NewWindow error: %v", err)
+	}
+	if window.ActiveAt(start.Add(-time.Second)) || !window.ActiveAt(start) || !window.ActiveAt(start.Add(29*time.Minute)) || window.ActiveAt(start.Add(30*time.Minute)) {
+		t.Fatalf("one-off window boundaries are wrong")
+	}
+}
+
+func TestWindowActiveAtRecurringInLocation(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	start := time.Date(2026, 3, 2, 2, 0, 0, 0, location)
+	window, err := NewWindow(start, start.Add(time.Hour), "0 2 * * *", "America/New_York")
+	if err != nil {
+		t.Fatalf("NewWindow error: %v", err)
+	}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{at: time.Date(2026, 3, 1, 2, 30, 0, 0, location), want: false}, // before the first occurrence
+		{at: time.Date(2026, 3, 5, 2, 30, 0, 0, location), want: true},
+		{at: time.Date(2026, 3, 5, 3, 0, 0, 0, location), want: false},
+		{at: time.Date(2026, 3, 5, 7, 30, 0, 0, time.UTC), want: true}, // 02:30 EST
+		{at: time.Date(2026, 3, 5, 2, 30, 0, 0, time.UTC), want: false},
+	}
+	for _, tc := range tests {
+		if got := window.ActiveAt(tc.at); got != tc.want {
+			t.Fatalf("ActiveAt(%s) = %v, want %v", tc.at, got, tc.want)
+		}
+	}
+}
+
+// TestWindowActiveAtMatchesMinuteWalk checks the jumping search against a
+// plain minute-by-minute walk, across DST changes and a half-hour shift.
+func TestWindowActiveAtMatchesMinuteWalk(t *testing.T) {
+	schedules := []string{"0 2 * * *", "*/15 9-17 * * 1-5", "30 1 * * 0", "45 23 31 * *", "0,50 * 1,15 * 6", "*/7 2 * 3,11 *"}
+	zones := []string{"UTC", "America/New_York", "Australia/Lord_Howe"}
+	durations := []time.Duration{time.Minute, 47 * time.Minute, 3 * time.Hour, 2 * 24 * time.Hour}
+	for _, zone := range zones {
+		location, err := time.LoadLocation(zone)
+		if err != nil {
+			t.Skipf("tzdata unavailable: %v", err)
+		}
+		for _, expr := range schedules {
+			for _, duration := range durations {
+				start := time.Date(2026, 2, 27, 0, 0, 0, 0, location)
+				window, err := NewWindow(start, start.Add(duration), expr, zone)
+				if err != nil {
+					t.Fatalf("NewWindow(%q) error: %v", expr, err)
+				}
+				// Sample every 41 minutes over spring and autumn changes in both hemispheres.
+				for _, from := range []time.Time{time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)} {
+					for at := from; at.Before(from.AddDate(0, 0, 4)); at = at.Add(41*time.Minute + 7*time.Second) {
+						if got, want := window.ActiveAt(at), activeByMinuteWalk(window, at); got != want {
+							t.Fatalf("%s %q for %s: ActiveAt(%s) = %v, want %v", zone, expr, duration, at.In(location), got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func activeByMinuteWalk(w Window, t time.Time) bool {
+	if t.Before(w.StartsAt) {
+		return false
+	}
+	first := w.StartsAt.Truncate(time.Minute)
+	for start := t.Truncate(time.Minute); !start.Before(first) && t.Sub(start) < w.EndsAt.Sub(w.StartsAt); start = start.Add(-time.Minute) {
+		if w.Schedule.Matches(start.In(w.Location)) {
+			return true
+		}
+	}
+	return false
+}
+
+func BenchmarkWindowActiveAtRecurring(b *testing.B) {
+	start := time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC)
+	window, err := NewWindow(start, start.Add(7*24*time.Hour-time.Minute), "0 2 * * 1", "")
+	if err != nil {
+		b.Fatalf("NewWindow error: %v", err)
+	}
+	at := time.Date(2026, 3, 8, 23, 59, 0, 0, time.UTC)
+	for i := 0; i < b.N; i++ {
+		window.ActiveAt(at)
+	}
+}
+
+func TestNewWindowValidation(t *testing.T) {
+	start := time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC)
+	if _, err := NewWindow(start, start, "", ""); err == nil {
+		t.Fatalf("empty window should fail")
+	}
+	if _, err := NewWindow(start, start.Add(8*24*time.Hour), "0 2 * * *", ""); err == nil {
+		t.Fatalf("recurring window longer than 7 days should fail")
+	}
+	if _, err := NewWindow(start, start.Add(time.Hour), "0 2 * *", ""); err == nil {
+		t.Fatalf("bad cron should fail")
+	}
+	if _, err := NewWindow(start, start.Add(time.Hour), "", "Mars/Olympus"); err == nil {
+		t.Fatalf("bad timezone should fail")
+	}
+}
+
+func TestSetCovers(t *testing.T) {
+	start := time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC)
+	window, _ := NewWindow(start, start.Add(time.Hour), "", "")
+	set := NewSet()
+	set.AddEndpoints(window, []int64{7, 8})
+
+	if !set.Covers(7, start.Add(time.Minute)) || set.Covers(9, start.Add(time.Minute)) || set.Covers(7, start.Add(2*time.Hour)) {
+		t.Fatalf("endpoint-scoped coverage is wrong")
+	}
+	set.AddAll(window)
+	if !set.Covers(9, start.Add(time.Minute)) {
+		t.Fatalf("fleet-wide window should cover every endpoint")
+	}
+	var empty *Set
+	if !empty.Empty() || empty.Covers(7, start) {
+		t.Fatalf("nil set should cover nothing")
+	}
+}
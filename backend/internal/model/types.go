@@ -13,6 +13,31 @@ const (
 	ImportInvalid   ImportClassification = "invalid"
 )
 
+const (
+	PingStatusNeverProbed = "never_probed"
+	PingStatusStale       = "stale"
+	PingStatusUnknown     = "unknown"
+	PingStatusWarmingUp   = "warming_up"
+	PingStatusDisabled    = "disabled"
+	PingStatusMaintenance = "Maintenance"
+)
+
+const (
+	ProbeModeICMP    = "icmp"
+	ProbeModeHTTP    = "http"
+	ProbeModeHTTPS   = "https"
+	ProbeModeTCP     = "tcp"
+	ProbeModeICMPTCP = "icmp_tcp"
+)
+
+const (
+	PayloadPatternFiller       = "filler"
+	PayloadPatternZeros        = "zeros"
+	PayloadPatternRandom       = "random"
+	PayloadPatternIncrementing = "incrementing"
+	PayloadPatternTimestamp    = "timestamp"
+)
+
 type InventoryEndpoint struct {
 	ID                 int64     `json:"id"`
 	IP                 string    `json:"ip"`
@@ -75,6 +100,7 @@ type MonitorEndpoint struct {
 	CustomField9Value      string     `json:"custom_field_9_value"`
 	CustomField10Value     string     `json:"custom_field_10_value"`
 	ReplyIPAddress         *string    `json:"reply_ip_address"`
+	LastTTL                *int       `json:"last_ttl"`
 	LastSuccessOn          *time.Time `json:"last_success_on"`
 	SuccessCount           int64      `json:"success_count"`
 	FailedCount            int64      `json:"failed_count"`
@@ -84,8 +110,17 @@ type MonitorEndpoint struct {
 	FailedPct              float64    `json:"failed_pct"`
 	TotalSentPing          int64      `json:"total_sent_ping"`
 	LastPingStatus         string     `json:"last_ping_status"`
+	LastErrorDetail        string     `json:"last_error_detail,omitempty"`
+	IsDown                 bool       `json:"is_down"`
+	DuplicateReplyCount    int64      `json:"duplicate_reply_count"`
+	IPMismatch             bool       `json:"ip_mismatch"`
+	FallbackProbe          string     `json:"fallback_probe"`
+	IPMismatchCount        *int64     `json:"ip_mismatch_count,omitempty"`
 	LastPingLatency        *float64   `json:"last_ping_latency"`
 	AverageLatency         *float64   `json:"average_latency"`
+	P50LatencyMs           *float64   `json:"p50_latency,omitempty"`
+	P95LatencyMs           *float64   `json:"p95_latency,omitempty"`
+	P99LatencyMs           *float64   `json:"p99_latency,omitempty"`
 	VLAN                   string     `json:"vlan"`
 	Zone                   string     `json:"zone"`
 	Switch                 string     `json:"switch"`
@@ -99,6 +134,13 @@ type MonitorEndpoint struct {
 	EndpointID             int64      `json:"endpoint_id"`
 }
 
+type DBPoolStats struct {
+	AcquiredConns int32 `json:"acquired_conns"`
+	IdleConns     int32 `json:"idle_conns"`
+	TotalConns    int32 `json:"total_conns"`
+	MaxConns      int32 `json:"max_conns"`
+}
+
 type MonitorEndpointsPageResponse struct {
 	Items       []MonitorEndpoint `json:"items"`
 	Page        int               `json:"page"`
@@ -109,6 +151,37 @@ type MonitorEndpointsPageResponse struct {
 	SortDir     string            `json:"sort_dir,omitempty"`
 	StatsScope  string            `json:"stats_scope,omitempty"`
 	RangeRollup string            `json:"range_rollup,omitempty"`
+	NextCursor  string            `json:"next_cursor,omitempty"`
+}
+
+type InventoryEndpointsPageResponse struct {
+	Items      []InventoryEndpointView `json:"items"`
+	Page       int                     `json:"page"`
+	PageSize   int                     `json:"page_size"`
+	TotalItems int64                   `json:"total_items"`
+	TotalPages int                     `json:"total_pages"`
+	SortBy     string                  `json:"sort_by,omitempty"`
+	SortDir    string                  `json:"sort_dir,omitempty"`
+}
+
+const MonitorSnapshotVersion = 1
+
+type MonitorSnapshot struct {
+	Version     int                 `json:"version"`
+	SnapshotID  string              `json:"snapshot_id"`
+	CapturedAt  time.Time           `json:"captured_at"`
+	Query       map[string][]string `json:"query"`
+	StatsScope  string              `json:"stats_scope"`
+	RangeStart  *time.Time          `json:"range_start,omitempty"`
+	RangeEnd    *time.Time          `json:"range_end,omitempty"`
+	RangeRollup string              `json:"range_rollup,omitempty"`
+	SortBy      string              `json:"sort_by,omitempty"`
+	SortDir     string              `json:"sort_dir,omitempty"`
+	Page        int                 `json:"page"`
+	PageSize    int                 `json:"page_size"`
+	TotalItems  int64               `json:"total_items"`
+	TotalPages  int                 `json:"total_pages"`
+	Items       []MonitorEndpoint   `json:"items"`
 }
 
 type InventoryEndpointView struct {
@@ -136,34 +209,54 @@ type InventoryEndpointView struct {
 	Speed              string    `json:"speed"`
 	Duplex             string    `json:"duplex"`
 	Description        string    `json:"description"`
+	ProbeMode          string    `json:"probe_mode"`
+	ProbeHTTPTarget    string    `json:"probe_http_target"`
+	ProbeTCPPort       int       `json:"probe_tcp_port"`
+	ProbeIntervalSec   *int      `json:"probe_interval_sec"`
 	Groups             []string  `json:"group"`
 	Active             bool      `json:"active"`
+	MonitoringEnabled  bool      `json:"monitoring_enabled"`
 	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 type InventoryEndpointUpdate struct {
-	Hostname           string `json:"hostname"`
-	MACAddress         string `json:"mac_address"`
-	CustomField1Value  string `json:"custom_field_1_value"`
-	CustomField2Value  string `json:"custom_field_2_value"`
-	CustomField3Value  string `json:"custom_field_3_value"`
-	CustomField4Value  string `json:"custom_field_4_value"`
-	CustomField5Value  string `json:"custom_field_5_value"`
-	CustomField6Value  string `json:"custom_field_6_value"`
-	CustomField7Value  string `json:"custom_field_7_value"`
-	CustomField8Value  string `json:"custom_field_8_value"`
-	CustomField9Value  string `json:"custom_field_9_value"`
-	CustomField10Value string `json:"custom_field_10_value"`
-	VLAN               string `json:"vlan"`
-	Zone               string `json:"zone"`
-	Switch             string `json:"switch"`
-	Port               string `json:"port"`
-	PortType           string `json:"port_type"`
-	Gateway            string `json:"gateway"`
-	MgmtIP             string `json:"mgmt_ip"`
-	Speed              string `json:"speed"`
-	Duplex             string `json:"duplex"`
-	Description        string `json:"description"`
+	Hostname           string  `json:"hostname"`
+	MACAddress         string  `json:"mac_address"`
+	CustomField1Value  string  `json:"custom_field_1_value"`
+	CustomField2Value  string  `json:"custom_field_2_value"`
+	CustomField3Value  string  `json:"custom_field_3_value"`
+	CustomField4Value  string  `json:"custom_field_4_value"`
+	CustomField5Value  string  `json:"custom_field_5_value"`
+	CustomField6Value  string  `json:"custom_field_6_value"`
+	CustomField7Value  string  `json:"custom_field_7_value"`
+	CustomField8Value  string  `json:"custom_field_8_value"`
+	CustomField9Value  string  `json:"custom_field_9_value"`
+	CustomField10Value string  `json:"custom_field_10_value"`
+	VLAN               string  `json:"vlan"`
+	Zone               string  `json:"zone"`
+	Switch             string  `json:"switch"`
+	Port               string  `json:"port"`
+	PortType           string  `json:"port_type"`
+	Gateway            string  `json:"gateway"`
+	MgmtIP             string  `json:"mgmt_ip"`
+	Speed              string  `json:"speed"`
+	Duplex             string  `json:"duplex"`
+	Description        string  `json:"description"`
+	ProbeMode          *string `json:"probe_mode,omitempty"`
+	ProbeHTTPTarget    *string `json:"probe_http_target,omitempty"`
+	ProbeTCPPort       *int    `json:"probe_tcp_port,omitempty"`
+	ProbeIntervalSec   *int    `json:"probe_interval_sec,omitempty"`
+	MonitoringEnabled  *bool   `json:"monitoring_enabled,omitempty"`
+}
+
+type InventoryAuditEntry struct {
+	ID            int64          `json:"id"`
+	EndpointID    int64          `json:"endpoint_id"`
+	Action        string         `json:"action"`
+	ChangedAt     time.Time      `json:"changed_at"`
+	ChangedFields []string       `json:"changed_fields"`
+	Before        map[string]any `json:"before,omitempty"`
+	After         map[string]any `json:"after,omitempty"`
 }
 
 type InventoryEndpointCreate struct {
@@ -190,9 +283,27 @@ type InventoryEndpointCreate struct {
 	Speed              string `json:"speed"`
 	Duplex             string `json:"duplex"`
 	Description        string `json:"description"`
+	ProbeMode          string `json:"probe_mode"`
+	ProbeHTTPTarget    string `json:"probe_http_target"`
+	ProbeTCPPort       int    `json:"probe_tcp_port"`
 	GroupID            *int64 `json:"group_id,omitempty"`
 }
 
+// InventoryEndpointBulkCreateResult reports one row of a bulk create by its
+// index in the request.
+type InventoryEndpointBulkCreateResult struct {
+	Index      int    `json:"index"`
+	EndpointID int64  `json:"endpoint_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type InventoryEndpointBulkCreateResponse struct {
+	Items        []InventoryEndpointView             `json:"items"`
+	Results      []InventoryEndpointBulkCreateResult `json:"results"`
+	CreatedCount int                                 `json:"created_count"`
+	FailedCount  int                                 `json:"failed_count"`
+}
+
 type InventoryEndpointActivityUpdateRequest struct {
 	EndpointIDs []int64 `json:"endpoint_ids"`
 	Active      bool    `json:"active"`
@@ -204,14 +315,28 @@ type InventoryEndpointActivityUpdateResponse struct {
 }
 
 type Group struct {
-	ID                  int64     `json:"id"`
-	Name                string    `json:"name"`
-	Description         string    `json:"description"`
-	IsSystem            bool      `json:"is_system"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
-	EndpointIDs         []int64   `json:"endpoint_ids,omitempty"`
-	ActiveEndpointCount int64     `json:"active_endpoint_count"`
+	ID                  int64           `json:"id"`
+	Name                string          `json:"name"`
+	Description         string          `json:"description"`
+	IsSystem            bool            `json:"is_system"`
+	CreatedAt           time.Time       `json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+	EndpointIDs         []int64         `json:"endpoint_ids,omitempty"`
+	ActiveEndpointCount int64           `json:"active_endpoint_count"`
+	AlertThresholds     AlertThresholds `json:"alert_thresholds"`
+	ProbeIntervalSec    *int            `json:"probe_interval_sec"`
+}
+
+type AlertThresholds struct {
+	ConsecutiveFailed *int     `json:"consecutive_failed"`
+	FailedPct         *float64 `json:"failed_pct"`
+}
+
+type EffectiveAlertThresholds struct {
+	ConsecutiveFailed       int     `json:"consecutive_failed"`
+	ConsecutiveFailedSource string  `json:"consecutive_failed_source"`
+	FailedPct               float64 `json:"failed_pct"`
+	FailedPctSource         string  `json:"failed_pct_source"`
 }
 
 type CustomFieldConfig struct {
@@ -221,11 +346,49 @@ type CustomFieldConfig struct {
 }
 
 type Settings struct {
-	PingIntervalSec int                 `json:"ping_interval_sec"`
-	ICMPPayloadSize int                 `json:"icmp_payload_bytes"`
-	ICMPTimeoutMs   int                 `json:"icmp_timeout_ms"`
-	AutoRefreshSec  int                 `json:"auto_refresh_sec"`
-	CustomFields    []CustomFieldConfig `json:"custom_fields"`
+	PingIntervalSec        int                 `json:"ping_interval_sec"`
+	ICMPPayloadSize        int                 `json:"icmp_payload_bytes"`
+	ICMPTimeoutMs          int                 `json:"icmp_timeout_ms"`
+	AutoRefreshSec         int                 `json:"auto_refresh_sec"`
+	CustomFields           []CustomFieldConfig `json:"custom_fields"`
+	AlertConsecutiveFailed int                 `json:"alert_consecutive_failed"`
+	AlertFailedPct         float64             `json:"alert_failed_pct"`
+	// AlertWebhookURL can carry a secret token, so it is write-only over the
+	// API; responses report AlertWebhookConfigured instead.
+	AlertWebhookURL        string `json:"-"`
+	AlertWebhookConfigured bool   `json:"alert_webhook_configured"`
+	ProbeMode              string `json:"probe_mode"`
+	TCPPort                int    `json:"tcp_port"`
+	ProbesPerRound         int    `json:"probes_per_round"`
+	ProbeRetries           int    `json:"probe_retries"`
+	DownThreshold          int    `json:"down_threshold"`
+	PayloadPattern         string `json:"payload_pattern"`
+}
+
+const (
+	AlertStateDown      = "down"
+	AlertStateRecovered = "recovered"
+)
+
+// EndpointTransition is an endpoint reaching its consecutive-failure alert
+// threshold (State down) or succeeding again after it had (State recovered).
+// ConsecutiveFailed is the streak that triggered down, or the streak that
+// just ended for recovered.
+type EndpointTransition struct {
+	EndpointID        int64
+	Hostname          string
+	IPAddress         string
+	State             string
+	Timestamp         time.Time
+	ConsecutiveFailed int64
+	Threshold         int
+	// FailedPct is the endpoint's failed percentage after the probe;
+	// FailedPctThreshold is the effective failed_pct threshold (0 = off).
+	FailedPct          float64
+	FailedPctThreshold float64
+	ErrorCode          string
+	CreatedAt          time.Time
+	TotalSentPing      int64
 }
 
 type SwitchDirectoryEntry struct {
@@ -279,27 +442,203 @@ type DashboardUnreachableSummary struct {
 	TotalSwitchCount int64                    `json:"total_switch_count"`
 }
 
+type MonitorFleetKPIs struct {
+	TotalEndpoints     int64            `json:"total_endpoints"`
+	MonitoredEndpoints int64            `json:"monitored_endpoints"`
+	MonitoredRatio     float64          `json:"monitored_ratio"`
+	DownEndpoints      int64            `json:"down_endpoints"`
+	FleetLossPct       float64          `json:"fleet_loss_pct"`
+	AvgLatencyMs       *float64         `json:"avg_latency_ms"`
+	StatusCounts       map[string]int64 `json:"status_counts"`
+	GeneratedAt        time.Time        `json:"generated_at"`
+}
+
+type GroupWorstOffender struct {
+	EndpointID int64   `json:"endpoint_id"`
+	Hostname   string  `json:"hostname"`
+	IPAddress  string  `json:"ip_address"`
+	FailedPct  float64 `json:"failed_pct"`
+}
+
+type MonitorGroupSummary struct {
+	GroupID        int64               `json:"group_id"`
+	GroupName      string              `json:"group_name"`
+	IsSystem       bool                `json:"is_system"`
+	TotalEndpoints int64               `json:"total_endpoints"`
+	UpEndpoints    int64               `json:"up_endpoints"`
+	DownEndpoints  int64               `json:"down_endpoints"`
+	LossPct        float64             `json:"loss_pct"`
+	AvgLatencyMs   *float64            `json:"avg_latency_ms"`
+	WorstOffender  *GroupWorstOffender `json:"worst_offender"`
+}
+
+const (
+	LastSeenBucketUnder1h = "lt_1h"
+	LastSeenBucket1hTo1d  = "1h_1d"
+	LastSeenBucket1dTo1w  = "1d_1w"
+	LastSeenBucketOver1w  = "gt_1w"
+	LastSeenBucketNever   = "never"
+)
+
+// LastSeenBuckets lists every bucket, including empty ones, in age order.
+var LastSeenBuckets = []string{
+	LastSeenBucketUnder1h,
+	LastSeenBucket1hTo1d,
+	LastSeenBucket1dTo1w,
+	LastSeenBucketOver1w,
+	LastSeenBucketNever,
+}
+
+func IsLastSeenBucket(bucket string) bool {
+	for _, candidate := range LastSeenBuckets {
+		if bucket == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+type LastSeenBucketCount struct {
+	Bucket    string `json:"bucket"`
+	Endpoints int64  `json:"endpoints"`
+}
+
+type LastSeenEndpoint struct {
+	EndpointID     int64      `json:"endpoint_id"`
+	Hostname       string     `json:"hostname"`
+	IPAddress      string     `json:"ip_address"`
+	LastSuccessOn  *time.Time `json:"last_success_on"`
+	LastFailedOn   *time.Time `json:"last_failed_on"`
+	LastPingStatus string     `json:"last_ping_status"`
+	TotalSentPing  int64      `json:"total_sent_ping"`
+}
+
+// DowntimeIncident is one contiguous failure run for an endpoint. EndedAt and
+// DurationSec stay nil while the endpoint is still down.
+type DowntimeIncident struct {
+	ID          int64      `json:"id"`
+	EndpointID  int64      `json:"endpoint_id"`
+	Hostname    string     `json:"hostname"`
+	IPAddress   string     `json:"ip_address"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at"`
+	DurationSec *float64   `json:"duration_sec"`
+	ErrorCode   string     `json:"error_code"`
+	Ongoing     bool       `json:"ongoing"`
+	Cause       string     `json:"cause"`
+	Note        string     `json:"note"`
+	Severity    string     `json:"severity"`
+}
+
+const (
+	IncidentSeverityMinor    = "minor"
+	IncidentSeverityMajor    = "major"
+	IncidentSeverityCritical = "critical"
+)
+
+// DowntimeIncidentAnnotation is a partial update of an incident's cause,
+// note, and severity. Nil fields are left unchanged; "" clears one.
+type DowntimeIncidentAnnotation struct {
+	Cause    *string `json:"cause"`
+	Note     *string `json:"note"`
+	Severity *string `json:"severity"`
+}
+
+// FilterValueCount is one filter option and how many endpoints carry it.
+type FilterValueCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// RawPingSample is one stored probe result from ping_raw.
+type RawPingSample struct {
+	Timestamp   time.Time `json:"ts"`
+	Success     bool      `json:"success"`
+	LatencyMs   *float64  `json:"latency_ms"`
+	ReplyIP     *string   `json:"reply_ip"`
+	TTL         *int      `json:"ttl"`
+	ErrorCode   string    `json:"error_code"`
+	Maintenance bool      `json:"maintenance"`
+	// FallbackProbe is "tcp" when an icmp_tcp round's ICMP failure went on to
+	// a TCP connect.
+	FallbackProbe string `json:"fallback_probe"`
+	// OutOfOrderReplies and DriftMs are only set for the timestamp payload
+	// pattern.
+	OutOfOrderReplies int      `json:"out_of_order_replies"`
+	DriftMs           *float64 `json:"drift_ms"`
+}
+
+// EndpointSLA is availability over a window, computed from the continuous
+// aggregates. UptimePct is nil when the endpoint has no samples in the window.
+type EndpointSLA struct {
+	EndpointID             int64      `json:"endpoint_id"`
+	Hostname               string     `json:"hostname"`
+	IPAddress              string     `json:"ip_address"`
+	TotalSentPing          int64      `json:"total_sent_ping"`
+	FailedCount            int64      `json:"failed_count"`
+	UptimePct              *float64   `json:"uptime_pct"`
+	LongestOutageSec       float64    `json:"longest_outage_sec"`
+	LongestOutageStartedAt *time.Time `json:"longest_outage_started_at"`
+}
+
+type EndpointStatsSnapshot struct {
+	EndpointID                    int64      `json:"endpoint_id"`
+	Hostname                      string     `json:"hostname"`
+	IPAddress                     string     `json:"ip_address"`
+	LastFailedOn                  *time.Time `json:"last_failed_on"`
+	LastSuccessOn                 *time.Time `json:"last_success_on"`
+	SuccessCount                  int64      `json:"success_count"`
+	FailedCount                   int64      `json:"failed_count"`
+	ConsecutiveFailedCount        int64      `json:"consecutive_failed_count"`
+	MaxConsecutiveFailedCount     int64      `json:"max_consecutive_failed_count"`
+	MaxConsecutiveFailedCountTime *time.Time `json:"max_consecutive_failed_count_time"`
+	FailedPct                     float64    `json:"failed_pct"`
+	TotalSentPing                 int64      `json:"total_sent_ping"`
+	LastPingStatus                string     `json:"last_ping_status"`
+	LastPingLatency               *float64   `json:"last_ping_latency"`
+	AverageLatency                *float64   `json:"average_latency"`
+	ReplyIPAddress                *string    `json:"reply_ip_address"`
+	LastTTL                       *int       `json:"last_ttl"`
+	UpdatedAt                     time.Time  `json:"updated_at"`
+}
+
 type TimeSeriesPoint struct {
 	EndpointID   int64     `json:"endpoint_id"`
 	Bucket       time.Time `json:"bucket"`
 	LossRate     float64   `json:"loss_rate"`
 	AvgLatencyMs *float64  `json:"avg_latency_ms"`
 	MaxLatencyMs *float64  `json:"max_latency_ms"`
+	JitterMs     *float64  `json:"jitter_ms"`
 	SentCount    int64     `json:"sent_count"`
 	FailCount    int64     `json:"fail_count"`
 }
 
 type PingResult struct {
-	EndpointID    int64
-	Timestamp     time.Time
-	Success       bool
-	LatencyMs     *float64
-	ReplyIP       *string
-	TTL           *int
-	ErrorCode     string
-	PayloadBytes  int
-	IntervalSec   int
-	RoundGroupIDs []int64
+	EndpointID       int64
+	Timestamp        time.Time
+	Success          bool
+	LatencyMs        *float64
+	ReplyIP          *string
+	TTL              *int
+	HTTPStatus       *int
+	TargetIP         *string
+	MinLatencyMs     *float64
+	MaxLatencyMs     *float64
+	JitterMs         *float64
+	LossPct          *float64
+	DuplicateReplies int
+	// OutOfOrderReplies and DriftMs come from timestamp payload replies; see
+	// probe.echoClock.
+	OutOfOrderReplies int
+	DriftMs           *float64
+	IPMismatch        bool
+	Retries           int
+	FallbackProbe     string
+	ErrorCode         string
+	ErrorDetail       string
+	PayloadBytes      int
+	IntervalSec       int
+	RoundGroupIDs     []int64
 }
 
 type ImportCandidate struct {
@@ -394,6 +733,33 @@ func InventoryEndpointViewCustomFieldValue(endpoint InventoryEndpointView, slot
 	}
 }
 
+func MonitorEndpointCustomFieldValue(endpoint MonitorEndpoint, slot int) string {
+	switch slot {
+	case 1:
+		return endpoint.CustomField1Value
+	case 2:
+		return endpoint.CustomField2Value
+	case 3:
+		return endpoint.CustomField3Value
+	case 4:
+		return endpoint.CustomField4Value
+	case 5:
+		return endpoint.CustomField5Value
+	case 6:
+		return endpoint.CustomField6Value
+	case 7:
+		return endpoint.CustomField7Value
+	case 8:
+		return endpoint.CustomField8Value
+	case 9:
+		return endpoint.CustomField9Value
+	case 10:
+		return endpoint.CustomField10Value
+	default:
+		return ""
+	}
+}
+
 func ImportCandidateCustomFieldValue(candidate ImportCandidate, slot int) string {
 	switch slot {
 	case 1:
@@ -444,6 +810,8 @@ type ImportApplyRequest struct {
 	PreviewID       string                        `json:"preview_id"`
 	Selections      []ImportApplySelection        `json:"selections"`
 	GroupAssignment *ImportGroupAssignmentRequest `json:"group_assignment,omitempty"`
+	// DryRun projects the outcome without keeping any write.
+	DryRun bool `json:"dry_run,omitempty"`
 }
 
 type ImportGroupAssignmentResult struct {
@@ -462,6 +830,13 @@ type ImportApplyResponse struct {
 	Updated         int                          `json:"updated"`
 	Errors          []string                     `json:"errors"`
 	GroupAssignment *ImportGroupAssignmentResult `json:"group_assignment,omitempty"`
+	RetryID         string                       `json:"retry_id,omitempty"`
+	FailedRowIDs    []string                     `json:"failed_row_ids,omitempty"`
+	DryRun          bool                         `json:"dry_run,omitempty"`
+}
+
+type ImportRetryRequest struct {
+	RetryID string `json:"retry_id"`
 }
 
 type DeleteInventoryByGroupResponse struct {
@@ -619,6 +994,7 @@ const (
 	InventoryDeleteJobStateRunning   InventoryDeleteJobState = "running"
 	InventoryDeleteJobStateCompleted InventoryDeleteJobState = "completed"
 	InventoryDeleteJobStateFailed    InventoryDeleteJobState = "failed"
+	InventoryDeleteJobStateCanceled  InventoryDeleteJobState = "canceled"
 )
 
 type InventoryDeleteJobAllRequest struct {
@@ -649,3 +1025,67 @@ type InventoryDeleteJobStatusResponse struct {
 type InventoryDeleteJobStartResponse struct {
 	InventoryDeleteJobStatusResponse
 }
+
+type StatsRecomputeJobState string
+
+const (
+	StatsRecomputeJobStateRunning   StatsRecomputeJobState = "running"
+	StatsRecomputeJobStateCompleted StatsRecomputeJobState = "completed"
+	StatsRecomputeJobStateFailed    StatsRecomputeJobState = "failed"
+)
+
+type StatsRecomputeJobStatus struct {
+	Active              bool                   `json:"active"`
+	JobID               string                 `json:"job_id,omitempty"`
+	State               StatsRecomputeJobState `json:"state,omitempty"`
+	MatchedEndpoints    int64                  `json:"matched_endpoints"`
+	ProcessedEndpoints  int64                  `json:"processed_endpoints"`
+	RecomputedEndpoints int64                  `json:"recomputed_endpoints"`
+	ProgressPct         float64                `json:"progress_pct"`
+	Error               string                 `json:"error,omitempty"`
+	StartedAt           *time.Time             `json:"started_at,omitempty"`
+	UpdatedAt           *time.Time             `json:"updated_at,omitempty"`
+	CompletedAt         *time.Time             `json:"completed_at,omitempty"`
+}
+
+// RetentionSettings are the drop_after windows, in days, for raw samples and
+// the two rollups. The jitter companion views follow their rollup.
+type RetentionSettings struct {
+	PingRawDays int `json:"ping_raw_days"`
+	Ping1mDays  int `json:"ping_1m_days"`
+	Ping1hDays  int `json:"ping_1h_days"`
+}
+
+const (
+	MaintenanceScopeAll      = "all"
+	MaintenanceScopeGroup    = "group"
+	MaintenanceScopeEndpoint = "endpoint"
+)
+
+// MaintenanceWindow suppresses failures for its scope. With a cron
+// recurrence, starts_at to ends_at is the first occurrence and each later
+// match of the expression in timezone starts another one of the same length.
+type MaintenanceWindow struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Scope      string    `json:"scope"`
+	EndpointID *int64    `json:"endpoint_id"`
+	GroupID    *int64    `json:"group_id"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Recurrence string    `json:"recurrence"`
+	Timezone   string    `json:"timezone"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type MaintenanceWindowInput struct {
+	Name       string    `json:"name"`
+	Scope      string    `json:"scope"`
+	EndpointID *int64    `json:"endpoint_id"`
+	GroupID    *int64    `json:"group_id"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Recurrence string    `json:"recurrence"`
+	Timezone   string    `json:"timezone"`
+}
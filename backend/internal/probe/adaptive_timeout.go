@@ -0,0 +1,114 @@
+package probe
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	adaptiveTimeoutWindow     = 20
+	adaptiveTimeoutMinSamples = 5
+	defaultAdaptiveStddevK    = 4
+	defaultAdaptiveMinMs      = 20
+)
+
+type latencyWindow struct {
+	samples [adaptiveTimeoutWindow]float64
+	next    int
+	count   int
+}
+
+func (w *latencyWindow) add(latencyMs float64) {
+	w.samples[w.next] = latencyMs
+	w.next = (w.next + 1) % len(w.samples)
+	if w.count < len(w.samples) {
+		w.count++
+	}
+}
+
+func (w *latencyWindow) meanStddev() (float64, float64) {
+	if w.count == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for i := 0; i < w.count; i++ {
+		sum += w.samples[i]
+	}
+	mean := sum / float64(w.count)
+	variance := 0.0
+	for i := 0; i < w.count; i++ {
+		delta := w.samples[i] - mean
+		variance += delta * delta
+	}
+	return mean, math.Sqrt(variance / float64(w.count))
+}
+
+// adaptiveTimeouts derives a per-endpoint deadline from recent reply
+// latency (mean + k*stddev), bounded below by minMs and above by the
+// configured ICMP timeout.
+type adaptiveTimeouts struct {
+	mu       sync.Mutex
+	stddevK  float64
+	minMs    int
+	windows  map[int64]*latencyWindow
+	disabled bool
+}
+
+func newAdaptiveTimeouts(enabled bool, stddevK, minMs int) *adaptiveTimeouts {
+	if stddevK < 1 {
+		stddevK = defaultAdaptiveStddevK
+	}
+	if minMs < 1 {
+		minMs = defaultAdaptiveMinMs
+	}
+	return &adaptiveTimeouts{
+		stddevK:  float64(stddevK),
+		minMs:    minMs,
+		windows:  map[int64]*latencyWindow{},
+		disabled: !enabled,
+	}
+}
+
+func (a *adaptiveTimeouts) timeoutMs(endpointID int64, maxMs int) int {
+	if a == nil || a.disabled {
+		return maxMs
+	}
+	a.mu.Lock()
+	window := a.windows[endpointID]
+	if window == nil || window.count < adaptiveTimeoutMinSamples {
+		a.mu.Unlock()
+		return maxMs
+	}
+	mean, stddev := window.meanStddev()
+	a.mu.Unlock()
+
+	timeout := int(math.Ceil(mean + a.stddevK*stddev))
+	if timeout < a.minMs {
+		timeout = a.minMs
+	}
+	if timeout > maxMs {
+		timeout = maxMs
+	}
+	return timeout
+}
+
+// observe records a probe outcome. A timeout clears the endpoint's history so
+// the next probe falls back to the full configured timeout instead of
+// repeatedly timing out a host whose latency just rose.
+func (a *adaptiveTimeouts) observe(endpointID int64, success bool, latencyMs *float64) {
+	if a == nil || a.disabled {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !success || latencyMs == nil {
+		delete(a.windows, endpointID)
+		return
+	}
+	window := a.windows[endpointID]
+	if window == nil {
+		window = &latencyWindow{}
+		a.windows[endpointID] = window
+	}
+	window.add(*latencyMs)
+}
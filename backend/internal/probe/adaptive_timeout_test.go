@@ -0,0 +1,55 @@
+package probe
+
+import "testing"
+
+func observeLatencies(a *adaptiveTimeouts, endpointID int64, latencies ...float64) {
+	for _, latency := range latencies {
+		value := latency
+		a.observe(endpointID, true, &value)
+	}
+}
+
+func TestAdaptiveTimeoutsDisabledUsesConfiguredMax(t *testing.T) {
+	a := newAdaptiveTimeouts(false, 4, 20)
+	observeLatencies(a, 1, 1, 1, 1, 1, 1, 1)
+	if got := a.timeoutMs(1, 500); got != 500 {
+		t.Fatalf("timeoutMs() = %d, want 500 when disabled", got)
+	}
+}
+
+func TestAdaptiveTimeoutsNeedMinimumSamples(t *testing.T) {
+	a := newAdaptiveTimeouts(true, 4, 20)
+	observeLatencies(a, 1, 1, 1, 1, 1)
+	if got := a.timeoutMs(1, 500); got != 500 {
+		t.Fatalf("timeoutMs() = %d, want 500 before %d samples", got, adaptiveTimeoutMinSamples)
+	}
+	observeLatencies(a, 1, 1)
+	if got := a.timeoutMs(1, 500); got != 20 {
+		t.Fatalf("timeoutMs() = %d, want floor of 20ms for a fast host", got)
+	}
+}
+
+func TestAdaptiveTimeoutsUsesMeanPlusStddevBoundedByMax(t *testing.T) {
+	a := newAdaptiveTimeouts(true, 2, 20)
+	// mean 100, population stddev 20 => 100 + 2*20 = 140
+	observeLatencies(a, 1, 80, 120, 80, 120, 80, 120)
+	if got := a.timeoutMs(1, 500); got != 140 {
+		t.Fatalf("timeoutMs() = %d, want 140", got)
+	}
+	if got := a.timeoutMs(1, 100); got != 100 {
+		t.Fatalf("timeoutMs() = %d, want configured max 100", got)
+	}
+}
+
+func TestAdaptiveTimeoutsResetAfterFailure(t *testing.T) {
+	a := newAdaptiveTimeouts(true, 4, 20)
+	observeLatencies(a, 1, 2, 2, 2, 2, 2, 2)
+	observeLatencies(a, 2, 2, 2, 2, 2, 2, 2)
+	a.observe(1, false, nil)
+	if got := a.timeoutMs(1, 500); got != 500 {
+		t.Fatalf("timeoutMs() = %d, want full timeout after a failure", got)
+	}
+	if got := a.timeoutMs(2, 500); got != 20 {
+		t.Fatalf("other endpoint timeout = %d, want 20", got)
+	}
+}
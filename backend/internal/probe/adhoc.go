@@ -0,0 +1,184 @@
+package probe
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+const (
+	AdhocKindProbeOnce = "probe_once"
+
+	AdhocStateRunning   = "running"
+	AdhocStateFinished  = "finished"
+	AdhocStateCanceled  = "canceled"
+	adhocRetention      = 10 * time.Minute
+	adhocMaxOperationMs = 60000
+)
+
+var (
+	ErrAdhocOperationNotFound = errors.New("ad-hoc operation not found")
+	// ErrProbeSocketUnavailable is returned when an ICMP probe-once is
+	// requested while no probe session holds the shared ICMP socket.
+	ErrProbeSocketUnavailable = errors.New("probe socket unavailable")
+)
+
+type AdhocResult struct {
+	Success    bool     `json:"success"`
+	LatencyMs  *float64 `json:"latency_ms"`
+	ReplyIP    *string  `json:"reply_ip,omitempty"`
+	TargetIP   *string  `json:"target_ip,omitempty"`
+	TTL        *int     `json:"ttl,omitempty"`
+	HTTPStatus *int     `json:"http_status,omitempty"`
+	Retries    int      `json:"retries"`
+	ErrorCode  string   `json:"error_code,omitempty"`
+}
+
+type AdhocOperation struct {
+	ID         string        `json:"op_id"`
+	Kind       string        `json:"kind"`
+	EndpointID int64         `json:"endpoint_id"`
+	State      string        `json:"state"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+	Results    []AdhocResult `json:"results"`
+}
+
+type adhocEntry struct {
+	op     AdhocOperation
+	cancel context.CancelFunc
+}
+
+// StartProbeOnce probes a single target outside the round scheduler. Results
+// are returned through the operation and are not persisted. ICMP targets
+// share the engine's socket, so they are rejected while probing is stopped.
+func (e *Engine) StartProbeOnce(target store.ProbeTarget) (AdhocOperation, error) {
+	settings := e.CurrentSettings()
+	if effectiveProbeMode(target, settings) == model.ProbeModeICMP && !e.hasICMPConn() {
+		return AdhocOperation{}, ErrProbeSocketUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), adhocMaxOperationMs*time.Millisecond)
+	entry := &adhocEntry{
+		op: AdhocOperation{
+			ID:         newAdhocOperationID(),
+			Kind:       AdhocKindProbeOnce,
+			EndpointID: target.EndpointID,
+			State:      AdhocStateRunning,
+			StartedAt:  time.Now().UTC(),
+			Results:    []AdhocResult{},
+		},
+		cancel: cancel,
+	}
+
+	e.adhocMu.Lock()
+	e.pruneAdhocLocked(entry.op.StartedAt)
+	e.adhocOps[entry.op.ID] = entry
+	op := entry.op
+	e.adhocMu.Unlock()
+
+	go func() {
+		defer cancel()
+		results, canceled := e.probeResolvedTarget(ctx, target, settings)
+		e.finishAdhoc(entry, results, canceled)
+	}()
+	return op, nil
+}
+
+func (e *Engine) hasICMPConn() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.conn != nil || e.conn6 != nil
+}
+
+func (e *Engine) AdhocOperation(id string) (AdhocOperation, error) {
+	e.adhocMu.Lock()
+	defer e.adhocMu.Unlock()
+	entry, ok := e.adhocOps[id]
+	if !ok {
+		return AdhocOperation{}, ErrAdhocOperationNotFound
+	}
+	return copyAdhocOperation(entry.op), nil
+}
+
+func (e *Engine) AdhocOperations() []AdhocOperation {
+	e.adhocMu.Lock()
+	defer e.adhocMu.Unlock()
+	items := make([]AdhocOperation, 0, len(e.adhocOps))
+	for _, entry := range e.adhocOps {
+		items = append(items, copyAdhocOperation(entry.op))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].StartedAt.Before(items[j].StartedAt)
+	})
+	return items
+}
+
+// CancelAdhoc cancels a running operation. It reports false when the
+// operation had already finished.
+func (e *Engine) CancelAdhoc(id string) (bool, error) {
+	e.adhocMu.Lock()
+	entry, ok := e.adhocOps[id]
+	if !ok {
+		e.adhocMu.Unlock()
+		return false, ErrAdhocOperationNotFound
+	}
+	running := entry.op.State == AdhocStateRunning
+	e.adhocMu.Unlock()
+
+	if running {
+		entry.cancel()
+	}
+	return running, nil
+}
+
+func (e *Engine) finishAdhoc(entry *adhocEntry, results []model.PingResult, canceled bool) {
+	finishedAt := time.Now().UTC()
+	e.adhocMu.Lock()
+	defer e.adhocMu.Unlock()
+	entry.op.FinishedAt = &finishedAt
+	entry.op.State = AdhocStateFinished
+	if canceled {
+		entry.op.State = AdhocStateCanceled
+	}
+	for _, result := range results {
+		entry.op.Results = append(entry.op.Results, AdhocResult{
+			Success:    result.Success,
+			LatencyMs:  result.LatencyMs,
+			ReplyIP:    result.ReplyIP,
+			TargetIP:   result.TargetIP,
+			TTL:        result.TTL,
+			HTTPStatus: result.HTTPStatus,
+			Retries:    result.Retries,
+			ErrorCode:  result.ErrorCode,
+		})
+	}
+}
+
+func (e *Engine) pruneAdhocLocked(now time.Time) {
+	for id, entry := range e.adhocOps {
+		if entry.op.FinishedAt != nil && now.Sub(*entry.op.FinishedAt) > adhocRetention {
+			delete(e.adhocOps, id)
+		}
+	}
+}
+
+func copyAdhocOperation(op AdhocOperation) AdhocOperation {
+	op.Results = append([]AdhocResult{}, op.Results...)
+	return op
+}
+
+func newAdhocOperationID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("op-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,85 @@
+package probe
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+func TestCancelAdhocStopsRunningProbeOnce(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{ICMPTimeoutMs: 30000}, newFakePacketConn())
+	op, err := engine.StartProbeOnce(store.ProbeTarget{EndpointID: 9, IP: "127.0.0.1", ProbeMode: model.ProbeModeHTTP, HTTPTarget: server.URL})
+	if err != nil {
+		t.Fatalf("StartProbeOnce() error = %v", err)
+	}
+	if op.State != AdhocStateRunning || op.Kind != AdhocKindProbeOnce {
+		t.Fatalf("unexpected initial op: %+v", op)
+	}
+
+	canceled, err := engine.CancelAdhoc(op.ID)
+	if err != nil || !canceled {
+		t.Fatalf("CancelAdhoc() = %v, %v; want true, nil", canceled, err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		current, err := engine.AdhocOperation(op.ID)
+		if err != nil {
+			t.Fatalf("AdhocOperation() error = %v", err)
+		}
+		if current.State != AdhocStateRunning {
+			if current.State != AdhocStateCanceled || current.FinishedAt == nil || len(current.Results) != 0 {
+				t.Fatalf("unexpected finished op: %+v", current)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("probe-once did not stop after cancel")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	canceled, err = engine.CancelAdhoc(op.ID)
+	if err != nil || canceled {
+		t.Fatalf("second CancelAdhoc() = %v, %v; want false, nil", canceled, err)
+	}
+	if _, err := engine.CancelAdhoc("missing"); !errors.Is(err, ErrAdhocOperationNotFound) {
+		t.Fatalf("CancelAdhoc(missing) error = %v, want ErrAdhocOperationNotFound", err)
+	}
+}
+
+func TestStartProbeOnceRejectsICMPWhileStopped(t *testing.T) {
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{ICMPTimeoutMs: 100}, newFakePacketConn())
+	engine.mu.Lock()
+	engine.conn = nil
+	engine.conn6 = nil
+	engine.mu.Unlock()
+
+	if _, err := engine.StartProbeOnce(store.ProbeTarget{EndpointID: 9, IP: "127.0.0.1"}); !errors.Is(err, ErrProbeSocketUnavailable) {
+		t.Fatalf("StartProbeOnce(icmp) error = %v, want ErrProbeSocketUnavailable", err)
+	}
+	if ops := engine.AdhocOperations(); len(ops) != 0 {
+		t.Fatalf("rejected probe-once left operations: %+v", ops)
+	}
+
+	op, err := engine.StartProbeOnce(store.ProbeTarget{EndpointID: 9, IP: "127.0.0.1", ProbeMode: model.ProbeModeTCP, TCPPort: 1})
+	if err != nil {
+		t.Fatalf("StartProbeOnce(tcp) error = %v", err)
+	}
+	engine.CancelAdhoc(op.ID)
+}
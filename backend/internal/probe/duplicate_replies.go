@@ -0,0 +1,68 @@
+package probe
+
+import (
+	"sync"
+	"time"
+)
+
+// lateDuplicates holds, per endpoint, duplicate replies counted after the
+// probe that drew them already returned. The endpoint's next ICMP result
+// takes them, so probe workers never wait for trailing duplicates.
+type lateDuplicates struct {
+	mu     sync.Mutex
+	counts map[int64]int
+}
+
+func (d *lateDuplicates) add(endpointID int64, n int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.counts == nil {
+		d.counts = map[int64]int{}
+	}
+	d.counts[endpointID] += n
+}
+
+func (d *lateDuplicates) take(endpointID int64) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := d.counts[endpointID]
+	delete(d.counts, endpointID)
+	return n
+}
+
+func (d *lateDuplicates) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.counts = nil
+}
+
+// duplicateReplyWait is how long an answered sequence stays registered: one
+// round trip, at least duplicateReplyMinWait, and no longer than the time
+// left before the probe deadline.
+func duplicateReplyWait(latencyMs float64, remaining time.Duration) time.Duration {
+	wait := time.Duration(latencyMs * float64(time.Millisecond))
+	if wait < duplicateReplyMinWait {
+		wait = duplicateReplyMinWait
+	}
+	if wait > remaining {
+		wait = remaining
+	}
+	return wait
+}
+
+// lingerPendingProbe leaves an answered sequence registered for wait while
+// the receive loop counts duplicates against it, then unregisters it and
+// hands the count to lateDuplicates.
+func (e *Engine) lingerPendingProbe(seq int, pending *pendingProbe, wait time.Duration) {
+	expire := func() {
+		e.unregisterPendingProbe(seq, pending)
+		if n := pending.duplicates.Load(); n > 0 {
+			e.lateDuplicates.add(pending.endpointID, int(n))
+		}
+	}
+	if wait <= 0 {
+		expire()
+		return
+	}
+	time.AfterFunc(wait, expire)
+}
@@ -0,0 +1,70 @@
+package probe
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// echoClock reads the send offset that timestamp-pattern replies echo back
+// and keeps, per target address, the newest answered offset and the fastest
+// round trip. Both clocks are the engine's own monotonic clock, so no
+// agreement with the far end is needed.
+type echoClock struct {
+	mu      sync.Mutex
+	targets map[string]echoClockTarget
+}
+
+type echoClockTarget struct {
+	lastSent time.Duration
+	minRTT   time.Duration
+}
+
+// echoedSendOffset returns the monotonic send offset carried after the send
+// stamp, or false when the payload is too short to hold one.
+func echoedSendOffset(data []byte) (time.Duration, bool) {
+	if len(data) < 2*probeStampSize {
+		return 0, false
+	}
+	return time.Duration(binary.BigEndian.Uint64(data[probeStampSize : 2*probeStampSize])), true
+}
+
+// observe records a reply to the probe sent at sent and received at received,
+// both offsets from the engine's clockEpoch. outOfOrder is true when a probe
+// sent later to the same target was already answered. drift is the one-way
+// delay above the target's baseline, estimated as half the round trip in
+// excess of the fastest one seen, since a single clock cannot split the
+// round trip into its two directions.
+func (c *echoClock) observe(target string, sent, received time.Duration) (outOfOrder bool, drift time.Duration) {
+	rtt := received - sent
+	if rtt < 0 {
+		rtt = 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.targets == nil {
+		c.targets = map[string]echoClockTarget{}
+	}
+	state, seen := c.targets[target]
+	if !seen {
+		c.targets[target] = echoClockTarget{lastSent: sent, minRTT: rtt}
+		return false, 0
+	}
+	if sent < state.lastSent {
+		outOfOrder = true
+	} else {
+		state.lastSent = sent
+	}
+	if rtt < state.minRTT {
+		state.minRTT = rtt
+	}
+	c.targets[target] = state
+	return outOfOrder, (rtt - state.minRTT) / 2
+}
+
+func (c *echoClock) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.targets = nil
+}
@@ -1,21 +1,23 @@
 package probe
 
 import (
-	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/binary"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"net"
-	"os"
+	"net/http"
 	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"golang.org/x/net/icmp"
-	"golang.org/x/net/ipv4"
 
 	"sonarscope/backend/internal/model"
 	"sonarscope/backend/internal/store"
@@ -40,12 +42,49 @@ type packetConnFactory func() (packetConn, error)
 type pendingProbe struct {
 	replyCh chan replyInfo
 	sentAt  time.Time
+	stamp   int64
+
+	// answered is set by the first matching echo reply; later matches for the
+	// same ID/sequence and stamp are counted in duplicates until the sequence
+	// is unregistered.
+	answered   atomic.Bool
+	duplicates atomic.Int32
+	endpointID int64
+
+	// tracker is the round that sent the probe, nil outside the scheduler.
+	tracker *roundTracker
+
+	// clockTarget is the address whose echoClock entry a timestamp-pattern
+	// reply updates; empty for the other patterns.
+	clockTarget string
 }
 
+// probeStampSize is the leading payload prefix that carries the send time so
+// late replies for a recycled sequence number can be told apart.
+const probeStampSize = 8
+
+// duplicateReplyMinWait is the shortest time a sequence stays registered
+// after its first reply so the receive loop can count trailing duplicates.
+// The wait is one round trip when that is longer, and never outlasts the
+// probe timeout.
+const duplicateReplyMinWait = 10 * time.Millisecond
+
 type replyInfo struct {
-	latencyMs float64
-	replyIP   string
-	ttl       *int
+	latencyMs  float64
+	replyIP    string
+	ttl        *int
+	outOfOrder bool
+	driftMs    *float64
+	err        error
+}
+
+// echoReply is one answered ICMP echo.
+type echoReply struct {
+	latencyMs  float64
+	replyIP    string
+	ttl        *int
+	outOfOrder bool
+	driftMs    *float64
 }
 
 type pacedProbeJob struct {
@@ -59,11 +98,25 @@ type resultEnvelope struct {
 }
 
 type Options struct {
-	ProbeWorkers        int
-	ResultWorkers       int
-	ResultQueueSize     int
-	ResultBatchSize     int
-	ResultFlushInterval time.Duration
+	ProbeWorkers           int
+	ResultWorkers          int
+	ResultQueueSize        int
+	ResultBatchSize        int
+	ResultFlushInterval    time.Duration
+	ResultLogSample        int
+	ResultLogTransition    bool
+	LogLinesPerSec         int
+	MultiAddressPolicy     string
+	AdaptiveTimeout        bool
+	AdaptiveTimeoutStddevK int
+	AdaptiveTimeoutMinMs   int
+	TCPSourcePortMin       int
+	TCPSourcePortMax       int
+	SendPacing             string
+	SendWindowPct          int
+	// ICMPBaseID is the instance number (1-255) placed in the high byte of
+	// the echo identifier; 0 picks a random one at engine construction.
+	ICMPBaseID int
 }
 
 type roundTracker struct {
@@ -77,6 +130,7 @@ type roundTracker struct {
 	failures     atomic.Int64
 	persistErrs  atomic.Int64
 	backpressure atomic.Int64
+	workerWaits  atomic.Int64
 	pendingPeak  atomic.Int64
 	queuePeak    atomic.Int64
 
@@ -84,6 +138,9 @@ type roundTracker struct {
 	lastDispatchNs  atomic.Int64
 	sendSlipMaxNs   atomic.Int64
 
+	staleReplies atomic.Int64
+	transitions  atomic.Int64
+
 	batchCount        atomic.Int64
 	batchMax          atomic.Int64
 	handledResults    atomic.Int64
@@ -104,43 +161,93 @@ type Engine struct {
 	resultBatchSize     int
 	resultFlushInterval time.Duration
 
-	settings          atomic.Value // model.Settings
-	seq               atomic.Uint32
-	roundSeq          atomic.Uint64
-	engineID          int
-	packetConnFactory packetConnFactory
+	settings           atomic.Value // model.Settings
+	seq                atomic.Uint32
+	roundSeq           atomic.Uint64
+	icmpBaseID         int
+	icmpID             atomic.Int32
+	packetConnFactory  packetConnFactory
+	packetConn6Factory packetConnFactory
 
 	lifecycleMu sync.Mutex
 	mu          sync.Mutex
 	running     bool
 	cancel      context.CancelFunc
-	scope       string
-	groupIDs    []int64
+	schedules   map[string]*probeSchedule
 	conn        packetConn
+	conn6       packetConn
 	recvDone    chan struct{}
-	loopDone    chan struct{}
+	recv6Done   chan struct{}
 	resultCh    chan resultEnvelope
 	resultDone  chan struct{}
 
 	pendingMu sync.Mutex
 	pending   map[int]*pendingProbe
 
-	roundMu     sync.Mutex
-	activeRound *roundTracker
+	owners targetOwners
 
 	payloadMu    sync.Mutex
-	payloadCache map[int][]byte
+	payloadCache map[payloadKey][]byte
+	// clockEpoch anchors the monotonic offset the timestamp payload pattern
+	// carries after the send stamp.
+	clockEpoch time.Time
+	echoClock  echoClock
+
+	lateDuplicates lateDuplicates
+
+	logSampleEvery int
+	logSampleSeq   atomic.Uint64
+	logTransitions bool
+	lastStateMu    sync.Mutex
+	lastState      map[int64]bool
+	resultLog      *logLimiter
+	transitionLog  *logLimiter
+	persistLog     *logLimiter
+
+	httpClient *http.Client
+
+	resolver           hostResolver
+	multiAddressPolicy string
+	sendPacing         string
+	sendWindowPct      int
+	roundRobinMu       sync.Mutex
+	roundRobinNext     map[int64]uint64
+	hostnameCacheMu    sync.Mutex
+	hostnameCache      map[hostnameCacheKey]hostnameCacheEntry
+
+	adaptiveTimeouts *adaptiveTimeouts
+	tcpSourcePorts   *tcpSourcePorts
+
+	adhocMu  sync.Mutex
+	adhocOps map[string]*adhocEntry
+
+	tracerouteSlots       chan struct{}
+	tracerouteConnFactory tracerouteConnFactory
+
+	cappedTimeoutMs atomic.Int64
 }
 
 type probeBroadcaster interface {
 	Broadcast(event any)
+	BroadcastProbeUpdate(endpointIDs []int64, timestamp time.Time)
 	ClientCount() int
 }
 
 type Status struct {
-	Running  bool
-	Scope    string
-	GroupIDs []int64
+	Running   bool
+	Scope     string
+	GroupIDs  []int64
+	Schedules []ScheduleStatus
+	// Round counters cover rounds since Start that probed at least one
+	// target. OverrunRounds ran past the tick, so the next round started late.
+	TotalRounds       uint64
+	OverrunRounds     uint64
+	LastRoundDuration time.Duration
+	LastRoundTargets  int
+	AvgRoundDuration  time.Duration
+	// EffectiveTimeoutMs is the per-probe deadline after capping the
+	// configured timeout to the current tick.
+	EffectiveTimeoutMs int
 }
 
 func NewEngine(st *store.Store, hub *telemetry.Hub, options Options, initialSettings model.Settings) *Engine {
@@ -153,19 +260,40 @@ func NewEngine(st *store.Store, hub *telemetry.Hub, options Options, initialSett
 func newEngineWithDeps(st probeStore, hub probeBroadcaster, options Options, initialSettings model.Settings, factory packetConnFactory) *Engine {
 	options = normalizeOptions(options)
 	engine := &Engine{
-		store:               st,
-		hub:                 hub,
-		probeWorkers:        options.ProbeWorkers,
-		resultWorkers:       options.ResultWorkers,
-		resultQueueSize:     options.ResultQueueSize,
-		resultBatchSize:     options.ResultBatchSize,
-		resultFlushInterval: options.ResultFlushInterval,
-		engineID:            os.Getpid() & 0xffff,
-		packetConnFactory:   factory,
-		pending:             map[int]*pendingProbe{},
-		payloadCache:        map[int][]byte{},
+		store:                 st,
+		hub:                   hub,
+		probeWorkers:          options.ProbeWorkers,
+		resultWorkers:         options.ResultWorkers,
+		resultQueueSize:       options.ResultQueueSize,
+		resultBatchSize:       options.ResultBatchSize,
+		resultFlushInterval:   options.ResultFlushInterval,
+		icmpBaseID:            resolveICMPBaseID(options.ICMPBaseID),
+		packetConnFactory:     factory,
+		packetConn6Factory:    defaultPacketConn6Factory,
+		pending:               map[int]*pendingProbe{},
+		payloadCache:          map[payloadKey][]byte{},
+		clockEpoch:            time.Now(),
+		logSampleEvery:        options.ResultLogSample,
+		logTransitions:        options.ResultLogTransition,
+		lastState:             map[int64]bool{},
+		resultLog:             newLogLimiter(options.LogLinesPerSec),
+		transitionLog:         newLogLimiter(options.LogLinesPerSec),
+		persistLog:            newLogLimiter(options.LogLinesPerSec),
+		httpClient:            newHTTPProbeClient(),
+		resolver:              net.DefaultResolver,
+		multiAddressPolicy:    normalizeMultiAddressPolicy(options.MultiAddressPolicy),
+		sendPacing:            normalizeSendPacing(options.SendPacing),
+		sendWindowPct:         options.SendWindowPct,
+		roundRobinNext:        map[int64]uint64{},
+		hostnameCache:         map[hostnameCacheKey]hostnameCacheEntry{},
+		adaptiveTimeouts:      newAdaptiveTimeouts(options.AdaptiveTimeout, options.AdaptiveTimeoutStddevK, options.AdaptiveTimeoutMinMs),
+		adhocOps:              map[string]*adhocEntry{},
+		tracerouteSlots:       make(chan struct{}, tracerouteMaxConcurrent),
+		tracerouteConnFactory: defaultTracerouteConnFactory,
+		tcpSourcePorts:        newTCPSourcePorts(options.TCPSourcePortMin, options.TCPSourcePortMax),
 	}
 	engine.settings.Store(initialSettings)
+	engine.rotateICMPID()
 	return engine
 }
 
@@ -185,13 +313,27 @@ func normalizeOptions(options Options) Options {
 	if options.ResultFlushInterval <= 0 {
 		options.ResultFlushInterval = 25 * time.Millisecond
 	}
+	if options.ResultLogSample < 0 {
+		options.ResultLogSample = 0
+	}
+	if options.SendWindowPct < 1 || options.SendWindowPct > 100 {
+		options.SendWindowPct = 100
+	}
+	if options.LogLinesPerSec < 1 {
+		options.LogLinesPerSec = defaultLogLinesPerSec
+	}
 	return options
 }
 
 func defaultPacketConnFactory() (packetConn, error) {
-	return icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return nil, err
+	}
+	return newTTLPacketConn(conn), nil
 }
 
+// Start replaces every running schedule with a single one over scope.
 func (e *Engine) Start(scope string, groupIDs []int64) error {
 	if scope != "all" && scope != "groups" {
 		return errors.New("scope must be all or groups")
@@ -204,39 +346,133 @@ func (e *Engine) Start(scope string, groupIDs []int64) error {
 	defer e.lifecycleMu.Unlock()
 
 	e.stopLocked()
+	if err := e.startSessionLocked(); err != nil {
+		return err
+	}
+	e.addScheduleLocked(newProbeSchedule(scope, groupIDs))
+	return nil
+}
+
+// StartGroup adds a schedule for one group next to any that are already
+// running, on the group's own probe interval. Starting a group that already
+// has its own schedule is a no-op.
+func (e *Engine) StartGroup(groupID int64) error {
+	if groupID < 1 {
+		return errors.New("group_id must be positive")
+	}
+
+	e.lifecycleMu.Lock()
+	defer e.lifecycleMu.Unlock()
 
+	schedule := newProbeSchedule("groups", []int64{groupID})
+	e.mu.Lock()
+	running := e.running
+	_, exists := e.schedules[schedule.key]
+	e.mu.Unlock()
+	if exists {
+		return nil
+	}
+	if !running {
+		if err := e.startSessionLocked(); err != nil {
+			return err
+		}
+	}
+	e.addScheduleLocked(schedule)
+	return nil
+}
+
+// StopGroup stops the schedule started for groupID, and the engine with it
+// when no other schedule is left. It reports whether such a schedule ran.
+func (e *Engine) StopGroup(groupID int64) bool {
+	e.lifecycleMu.Lock()
+	defer e.lifecycleMu.Unlock()
+
+	key := scheduleKey("groups", []int64{groupID})
+	e.mu.Lock()
+	schedule, ok := e.schedules[key]
+	if ok {
+		delete(e.schedules, key)
+	}
+	remaining := len(e.schedules)
+	e.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	schedule.cancel()
+	<-schedule.done
+	e.owners.release(schedule)
+	e.forgetUnownedStates()
+	if remaining == 0 {
+		e.stopLocked()
+	}
+	return true
+}
+
+// startSessionLocked opens the sockets and result workers shared by every
+// schedule. The caller holds lifecycleMu and has checked the engine is
+// stopped.
+func (e *Engine) startSessionLocked() error {
 	conn, err := e.packetConnFactory()
 	if err != nil {
 		return err
 	}
+	conn6, err := e.packetConn6Factory()
+	if err != nil {
+		slog.Warn("probe engine ipv6 socket unavailable, ipv6 targets will fail", "error", err)
+		conn6 = nil
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	recvDone := make(chan struct{})
-	loopDone := make(chan struct{})
+	var recv6Done chan struct{}
+	if conn6 != nil {
+		recv6Done = make(chan struct{})
+	}
 	resultCh := make(chan resultEnvelope, e.resultQueueSize)
 	resultDone := make(chan struct{})
 
 	e.clearPending()
+	e.owners.reset()
+	e.echoClock.reset()
+	e.lateDuplicates.reset()
+	e.cappedTimeoutMs.Store(0)
+	e.rotateICMPID()
 
 	e.mu.Lock()
 	e.cancel = cancel
-	e.scope = scope
-	e.groupIDs = append([]int64{}, groupIDs...)
 	e.running = true
+	e.schedules = map[string]*probeSchedule{}
 	e.conn = conn
+	e.conn6 = conn6
 	e.recvDone = recvDone
-	e.loopDone = loopDone
+	e.recv6Done = recv6Done
 	e.resultCh = resultCh
 	e.resultDone = resultDone
 	e.mu.Unlock()
 
-	log.Printf("probe engine start scope=%s group_ids=%v", scope, groupIDs)
 	go e.receiveLoop(ctx, conn, recvDone)
+	if conn6 != nil {
+		go e.receiveReplies(ctx, conn6, icmpV6, recv6Done)
+	}
 	go e.runResultWorkers(resultCh, resultDone)
-	go e.loop(ctx, loopDone)
 	return nil
 }
 
+func (e *Engine) addScheduleLocked(schedule *probeSchedule) {
+	ctx, cancel := context.WithCancel(context.Background())
+	schedule.cancel = cancel
+	schedule.done = make(chan struct{})
+	schedule.startedAt = time.Now().UTC()
+
+	e.mu.Lock()
+	e.schedules[schedule.key] = schedule
+	e.mu.Unlock()
+
+	slog.Info("probe engine start", "scope", schedule.scope, "group_ids", schedule.groupIDs, "schedules", len(e.schedules))
+	go e.loop(ctx, schedule)
+}
+
 func (e *Engine) Stop() bool {
 	e.lifecycleMu.Lock()
 	defer e.lifecycleMu.Unlock()
@@ -251,30 +487,40 @@ func (e *Engine) stopLocked() bool {
 	}
 
 	cancel := e.cancel
+	schedules := e.schedules
 	conn := e.conn
+	conn6 := e.conn6
 	recvDone := e.recvDone
-	loopDone := e.loopDone
+	recv6Done := e.recv6Done
 	resultCh := e.resultCh
 	resultDone := e.resultDone
 
 	e.running = false
 	e.cancel = nil
-	e.scope = ""
-	e.groupIDs = nil
+	e.schedules = nil
 	e.mu.Unlock()
 
+	for _, schedule := range schedules {
+		schedule.cancel()
+	}
 	if cancel != nil {
 		cancel()
 	}
 	if conn != nil {
 		_ = conn.Close()
 	}
-	if loopDone != nil {
-		<-loopDone
+	if conn6 != nil {
+		_ = conn6.Close()
+	}
+	for _, schedule := range schedules {
+		<-schedule.done
 	}
 	if recvDone != nil {
 		<-recvDone
 	}
+	if recv6Done != nil {
+		<-recv6Done
+	}
 	if resultCh != nil {
 		close(resultCh)
 	}
@@ -286,11 +532,14 @@ func (e *Engine) stopLocked() bool {
 	if e.conn == conn {
 		e.conn = nil
 	}
+	if e.conn6 == conn6 {
+		e.conn6 = nil
+	}
 	if e.recvDone == recvDone {
 		e.recvDone = nil
 	}
-	if e.loopDone == loopDone {
-		e.loopDone = nil
+	if e.recv6Done == recv6Done {
+		e.recv6Done = nil
 	}
 	if e.resultCh == resultCh {
 		e.resultCh = nil
@@ -301,8 +550,10 @@ func (e *Engine) stopLocked() bool {
 	e.mu.Unlock()
 
 	e.clearPending()
-	e.setActiveRound(nil)
-	log.Printf("probe engine stopped")
+	e.owners.reset()
+	e.echoClock.reset()
+	e.lateDuplicates.reset()
+	slog.Info("probe engine stopped")
 	return true
 }
 
@@ -312,20 +563,48 @@ func (e *Engine) IsRunning() bool {
 	return e.running
 }
 
+// Status reports the running schedules. Scope and GroupIDs summarize them:
+// "all" if any schedule covers the whole inventory, otherwise "groups" with
+// the union of scheduled groups.
 func (e *Engine) Status() Status {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	settings := e.CurrentSettings()
 	status := Status{
-		Running:  e.running,
-		Scope:    "",
-		GroupIDs: []int64{},
+		Running:   e.running,
+		Scope:     "",
+		GroupIDs:  []int64{},
+		Schedules: []ScheduleStatus{},
+	}
+
+	tick := time.Duration(settings.PingIntervalSec) * time.Second
+	groupIDs := []int64{}
+	var rounds roundCounts
+	for _, schedule := range e.schedules {
+		status.Schedules = append(status.Schedules, schedule.status())
+		rounds = rounds.merge(schedule.rounds.snapshot())
+		if schedule.scope == "all" {
+			status.Scope = "all"
+		} else {
+			groupIDs = append(groupIDs, schedule.groupIDs...)
+		}
+		if scheduleTick := schedule.tickInterval(settings); scheduleTick < tick {
+			tick = scheduleTick
+		}
 	}
+	sort.Slice(status.Schedules, func(i, j int) bool {
+		return status.Schedules[i].StartedAt.Before(status.Schedules[j].StartedAt)
+	})
+	rounds.fill(&status)
+	status.EffectiveTimeoutMs = effectiveTimeoutMs(settings, tick)
 	if !e.running {
 		return status
 	}
-	status.Scope = e.scope
-	status.GroupIDs = append(status.GroupIDs, e.groupIDs...)
+	if status.Scope == "" {
+		status.Scope = "groups"
+		status.GroupIDs = uniqueSortedInt64(groupIDs)
+	}
 	return status
 }
 
@@ -346,39 +625,39 @@ func (e *Engine) CurrentSettings() model.Settings {
 	return value.(model.Settings)
 }
 
-func (e *Engine) loop(ctx context.Context, done chan struct{}) {
-	defer close(done)
+func (e *Engine) loop(ctx context.Context, schedule *probeSchedule) {
+	defer close(schedule.done)
 
 	settings := e.CurrentSettings()
-	log.Printf(
-		"probe loop started interval_sec=%d payload_bytes=%d timeout_ms=%d probe_workers=%d result_workers=%d result_queue=%d result_batch=%d result_flush_ms=%d",
-		settings.PingIntervalSec,
-		settings.ICMPPayloadSize,
-		settings.ICMPTimeoutMs,
-		e.probeWorkers,
-		e.resultWorkers,
-		e.resultQueueSize,
-		e.resultBatchSize,
-		e.resultFlushInterval.Milliseconds(),
+	slog.Info(
+		"probe loop started",
+		"schedule", schedule.key,
+		"interval_sec", settings.PingIntervalSec,
+		"payload_bytes", settings.ICMPPayloadSize,
+		"timeout_ms", settings.ICMPTimeoutMs,
+		"probe_workers", e.probeWorkers,
+		"result_workers", e.resultWorkers,
+		"result_queue", e.resultQueueSize,
+		"result_batch", e.resultBatchSize,
+		"result_flush_ms", e.resultFlushInterval.Milliseconds(),
 	)
 
 	for {
 		if ctx.Err() != nil {
-			log.Printf("probe loop exited")
+			slog.Info("probe loop exited", "schedule", schedule.key)
 			return
 		}
 
 		settings = e.CurrentSettings()
-		interval := time.Duration(settings.PingIntervalSec) * time.Second
+		interval := schedule.tickInterval(settings)
 		roundID := e.roundSeq.Add(1)
 		roundStarted := time.Now()
 		tracker := newRoundTracker(roundID, roundStarted, interval)
-		e.setActiveRound(tracker)
 
-		dispatched := e.runRound(ctx, roundID, roundStarted, tracker, settings)
+		dispatched := e.runRound(ctx, schedule, roundID, roundStarted, tracker, settings)
 		duration := time.Since(roundStarted)
+		schedule.rounds.record(dispatched, duration, duration > interval)
 		tracker.finishProbePhase(dispatched, duration, duration > interval)
-		e.setActiveRound(nil)
 
 		wait := interval - duration
 		if wait < 0 {
@@ -389,7 +668,7 @@ func (e *Engine) loop(ctx context.Context, done chan struct{}) {
 		select {
 		case <-ctx.Done():
 			timer.Stop()
-			log.Printf("probe loop exited")
+			slog.Info("probe loop exited", "schedule", schedule.key)
 			return
 		case <-timer.C:
 		}
@@ -397,11 +676,15 @@ func (e *Engine) loop(ctx context.Context, done chan struct{}) {
 }
 
 func (e *Engine) receiveLoop(ctx context.Context, conn packetConn, done chan struct{}) {
+	e.receiveReplies(ctx, conn, icmpV4, done)
+}
+
+func (e *Engine) receiveReplies(ctx context.Context, conn packetConn, family icmpFamily, done chan struct{}) {
 	defer close(done)
 
 	buffer := make([]byte, 1500)
 	for {
-		n, peer, err := conn.ReadFrom(buffer)
+		n, peer, ttl, err := readFromWithTTL(conn, buffer)
 		if err != nil {
 			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
 				return
@@ -412,17 +695,28 @@ func (e *Engine) receiveLoop(ctx context.Context, conn packetConn, done chan str
 				continue
 			}
 
-			log.Printf("probe receive failed: %v", err)
+			slog.Warn("probe receive failed", "error", err)
 			continue
 		}
 
-		parsed, err := icmp.ParseMessage(ipv4.ICMPTypeEchoReply.Protocol(), buffer[:n])
-		if err != nil || parsed.Type != ipv4.ICMPTypeEchoReply {
+		parsed, err := icmp.ParseMessage(family.protocol, buffer[:n])
+		if err != nil {
+			continue
+		}
+		if parsed.Type == family.destUnreachable {
+			e.deliverDestinationUnreachable(family, parsed, peer)
+			continue
+		}
+		if parsed.Type != family.echoReply {
 			continue
 		}
 
 		echo, ok := parsed.Body.(*icmp.Echo)
-		if !ok || echo.ID != e.engineID {
+		if !ok {
+			continue
+		}
+		if echoID := e.echoID(); echo.ID != echoID {
+			slog.Debug("dropped foreign icmp echo reply", "id", echo.ID, "want_id", echoID, "seq", echo.Seq, "peer", peer)
 			continue
 		}
 
@@ -430,18 +724,37 @@ func (e *Engine) receiveLoop(ctx context.Context, conn packetConn, done chan str
 		if pending == nil {
 			continue
 		}
+		if !pending.matchesStamp(echo.Data) {
+			if pending.tracker != nil {
+				pending.tracker.noteStaleReply()
+			}
+			continue
+		}
 
 		replyIP := ""
 		if ipAddr, ok := peer.(*net.IPAddr); ok && ipAddr.IP != nil {
 			replyIP = ipAddr.IP.String()
 		}
 
+		receivedAt := time.Now()
 		reply := replyInfo{
-			latencyMs: time.Since(pending.sentAt).Seconds() * 1000,
+			latencyMs: receivedAt.Sub(pending.sentAt).Seconds() * 1000,
 			replyIP:   replyIP,
-			ttl:       nil,
+			ttl:       ttl,
 		}
 
+		if pending.answered.Swap(true) {
+			pending.duplicates.Add(1)
+			continue
+		}
+		if pending.clockTarget != "" {
+			if sent, ok := echoedSendOffset(echo.Data); ok {
+				outOfOrder, drift := e.echoClock.observe(pending.clockTarget, sent, receivedAt.Sub(e.clockEpoch))
+				driftMs := drift.Seconds() * 1000
+				reply.outOfOrder = outOfOrder
+				reply.driftMs = &driftMs
+			}
+		}
 		select {
 		case pending.replyCh <- reply:
 		default:
@@ -449,23 +762,71 @@ func (e *Engine) receiveLoop(ctx context.Context, conn packetConn, done chan str
 	}
 }
 
-func (e *Engine) runRound(ctx context.Context, roundID uint64, roundStarted time.Time, tracker *roundTracker, settings model.Settings) int {
-	e.mu.Lock()
-	scope := e.scope
-	groupIDs := append([]int64{}, e.groupIDs...)
-	e.mu.Unlock()
+// deliverDestinationUnreachable hands a Destination Unreachable to the probe
+// whose echo request it quotes. The quoted payload is usually long enough to
+// carry the send stamp; when a router truncates it, the ID/sequence match is
+// accepted on its own.
+func (e *Engine) deliverDestinationUnreachable(family icmpFamily, parsed *icmp.Message, peer net.Addr) {
+	body, ok := parsed.Body.(*icmp.DstUnreach)
+	if !ok {
+		return
+	}
+	id, seq, payload, ok := quotedEcho(family, body.Data)
+	if !ok {
+		return
+	}
+	if echoID := e.echoID(); id != echoID {
+		slog.Debug("dropped foreign icmp destination unreachable", "id", id, "want_id", echoID, "seq", seq)
+		return
+	}
+	pending := e.lookupPendingProbe(seq)
+	if pending == nil {
+		return
+	}
+	if len(payload) >= probeStampSize && !pending.matchesStamp(payload) {
+		return
+	}
+
+	from := ""
+	if ipAddr, ok := peer.(*net.IPAddr); ok && ipAddr.IP != nil {
+		from = ipAddr.IP.String()
+	}
+	reply := replyInfo{
+		replyIP: from,
+		err: &destinationUnreachableError{
+			family: family.name,
+			code:   parsed.Code,
+			reason: unreachableReason(family, parsed.Code),
+			from:   from,
+		},
+	}
+	select {
+	case pending.replyCh <- reply:
+	default:
+	}
+}
+
+func (e *Engine) runRound(ctx context.Context, schedule *probeSchedule, roundID uint64, roundStarted time.Time, tracker *roundTracker, settings model.Settings) int {
+	scope := schedule.scope
+	groupIDs := schedule.groupIDs
 
 	targets, err := e.store.ListProbeTargets(ctx, scope, groupIDs)
 	if err != nil {
 		if ctx.Err() != nil {
 			return 0
 		}
-		log.Printf("probe round target lookup failed round_id=%d: %v", roundID, err)
+		slog.Error("probe round target lookup failed", "round_id", roundID, "error", err)
 		e.broadcastProbeError(0, fmt.Sprintf("failed to list probe targets: %v", err))
 		return 0
 	}
 	if len(targets) == 0 {
-		log.Printf("probe round skipped round_id=%d: no targets (scope=%s)", roundID, scope)
+		slog.Info("probe round skipped: no targets", "round_id", roundID, "scope", scope)
+		return 0
+	}
+	targets = e.owners.claim(schedule, targets)
+	e.forgetUnownedStates()
+	targets = schedule.dueTargets(targets, settings, roundStarted, tracker.interval)
+	if len(targets) == 0 {
 		return 0
 	}
 
@@ -473,18 +834,21 @@ func (e *Engine) runRound(ctx context.Context, roundID uint64, roundStarted time
 		return targets[i].EndpointID < targets[j].EndpointID
 	})
 	tracker.setTargetCount(len(targets))
-
-	log.Printf(
-		"probe round started round_id=%d targets=%d scope=%s timeout_ms=%d probe_workers=%d",
-		roundID,
-		len(targets),
-		scope,
-		settings.ICMPTimeoutMs,
-		e.workerCount(len(targets)),
+	settings.ICMPTimeoutMs = e.roundTimeoutMs(settings, tracker.interval)
+
+	slog.Info(
+		"probe round started",
+		"round_id", roundID,
+		"targets", len(targets),
+		"scope", scope,
+		"timeout_ms", settings.ICMPTimeoutMs,
+		"probe_workers", e.workerCount(len(targets)),
 	)
 
+	ctx = withRoundTracker(ctx, tracker)
 	jobs := make(chan pacedProbeJob)
 	workerCount := e.workerCount(len(targets))
+	var inFlight atomic.Int64
 	wg := sync.WaitGroup{}
 	for i := 0; i < workerCount; i++ {
 		wg.Add(1)
@@ -500,31 +864,29 @@ func (e *Engine) runRound(ctx context.Context, roundID uint64, roundStarted time
 						return
 					}
 
-					result, canceled := e.probeTarget(ctx, job.target, settings)
+					results, canceled := e.probeResolvedTarget(ctx, job.target, settings)
+					for _, result := range results {
+						tracker.noteProbeResult(result.Success)
+						e.logProbeResult(tracker, job.target, result)
+						targetIP := job.target.IP
+						if result.TargetIP != nil {
+							targetIP = *result.TargetIP
+						}
+						e.enqueueResult(ctx, tracker, targetIP, result)
+					}
+					inFlight.Add(-1)
 					if canceled {
 						return
 					}
-
-					tracker.noteProbeResult(result.Success)
-					e.enqueueResult(ctx, tracker, job.target.IP, result)
 				}
 			}
 		}()
 	}
 
-	guard := time.Duration(0)
-	if intervalGuard := tracker.interval / 10; intervalGuard > 0 {
-		guard = intervalGuard
-	}
-	if guard > 100*time.Millisecond {
-		guard = 100 * time.Millisecond
-	}
-	sendWindow := tracker.interval - guard
-	if sendWindow < 0 {
-		sendWindow = 0
-	}
+	sendWindow := e.roundSendWindow(tracker.interval, settings)
 
 	dispatched := 0
+	saturatedWaits := 0
 	for i, target := range targets {
 		scheduledAt := roundStarted
 		if len(targets) > 1 && sendWindow > 0 {
@@ -544,12 +906,18 @@ func (e *Engine) runRound(ctx context.Context, roundID uint64, roundStarted time
 			}
 		}
 
+		if int(inFlight.Load()) >= workerCount {
+			saturatedWaits++
+			tracker.noteWorkerWait()
+		}
+
 		select {
 		case <-ctx.Done():
 			close(jobs)
 			wg.Wait()
 			return dispatched
 		case jobs <- pacedProbeJob{target: target}:
+			inFlight.Add(1)
 			dispatched++
 			tracker.noteDispatch(time.Now(), scheduledAt)
 		}
@@ -557,9 +925,39 @@ func (e *Engine) runRound(ctx context.Context, roundID uint64, roundStarted time
 
 	close(jobs)
 	wg.Wait()
+	if saturatedWaits > 0 {
+		slog.Warn(
+			"probe worker pool saturated; raise PROBE_WORKERS if rounds overrun",
+			"round_id", roundID,
+			"probe_workers", workerCount,
+			"targets", len(targets),
+			"waits", saturatedWaits,
+		)
+	}
 	return dispatched
 }
 
+// roundTimeoutMs returns the deadline for this round's probes and warns once
+// each time capping starts applying or the capped value changes.
+func (e *Engine) roundTimeoutMs(settings model.Settings, tick time.Duration) int {
+	effective := effectiveTimeoutMs(settings, tick)
+	capped := int64(0)
+	if effective < settings.ICMPTimeoutMs {
+		capped = int64(effective)
+	}
+	if previous := e.cappedTimeoutMs.Swap(capped); capped > 0 && previous != capped {
+		slog.Warn(
+			"probe timeout capped to fit the interval; lower icmp_timeout_ms, probes_per_round, or probe_retries",
+			"timeout_ms", settings.ICMPTimeoutMs,
+			"effective_timeout_ms", effective,
+			"probes_per_round", probesPerRound(settings),
+			"probe_retries", probeRetries(settings),
+			"tick_ms", tick.Milliseconds(),
+		)
+	}
+	return effective
+}
+
 func (e *Engine) enqueueResult(ctx context.Context, tracker *roundTracker, targetIP string, result model.PingResult) {
 	env := resultEnvelope{
 		targetIP: targetIP,
@@ -674,7 +1072,7 @@ func (e *Engine) processResultEnvelopes(batch []resultEnvelope) {
 		e.noteBatchSuccess(batch, time.Since(started))
 		return
 	} else {
-		log.Printf("probe batch persist failed batch_size=%d err=%v", len(batch), err)
+		slog.Warn("probe batch persist failed", "batch_size", len(batch), "error", err)
 	}
 	for _, env := range batch {
 		singleStarted := time.Now()
@@ -690,7 +1088,7 @@ func (e *Engine) processResultEnvelopes(batch []resultEnvelope) {
 		if env.tracker != nil {
 			env.tracker.markResultsHandled(1)
 		}
-		e.broadcastProbeUpdate(1, env.result.Timestamp)
+		e.broadcastProbeUpdate([]int64{env.result.EndpointID}, env.result.Timestamp)
 	}
 }
 
@@ -708,7 +1106,11 @@ func (e *Engine) noteBatchSuccess(batch []resultEnvelope, duration time.Duration
 		tracker.markResultsHandled(count)
 	}
 
-	e.broadcastProbeUpdate(len(batch), batch[len(batch)-1].result.Timestamp)
+	endpointIDs := make([]int64, 0, len(batch))
+	for _, env := range batch {
+		endpointIDs = append(endpointIDs, env.result.EndpointID)
+	}
+	e.broadcastProbeUpdate(endpointIDs, batch[len(batch)-1].result.Timestamp)
 }
 
 func apportionedDuration(total time.Duration, part, whole int) time.Duration {
@@ -725,19 +1127,15 @@ func (e *Engine) processFailedPersistence(env resultEnvelope, err error) {
 		env.tracker.markResultsHandled(1)
 		roundID = env.tracker.roundID
 	}
-	log.Printf("probe persist failed round_id=%d endpoint_id=%d ip=%s err=%v", roundID, env.result.EndpointID, env.targetIP, err)
+	e.persistLog.Log(slog.LevelError, "probe persist failed", "round_id", roundID, "endpoint_id", env.result.EndpointID, "ip", env.targetIP, "error", err)
 	e.broadcastProbeError(env.result.EndpointID, fmt.Sprintf("persist ping failed: %v", err))
 }
 
-func (e *Engine) broadcastProbeUpdate(count int, timestamp time.Time) {
-	if count <= 0 || e.hub == nil || e.hub.ClientCount() == 0 {
+func (e *Engine) broadcastProbeUpdate(endpointIDs []int64, timestamp time.Time) {
+	if len(endpointIDs) == 0 || e.hub == nil || e.hub.ClientCount() == 0 {
 		return
 	}
-	e.hub.Broadcast(map[string]any{
-		"type":      "probe_update",
-		"count":     count,
-		"timestamp": timestamp,
-	})
+	e.hub.BroadcastProbeUpdate(endpointIDs, timestamp)
 }
 
 func (e *Engine) broadcastProbeError(endpointID int64, message string) {
@@ -765,57 +1163,164 @@ func (e *Engine) workerCount(targetCount int) int {
 	return e.probeWorkers
 }
 
+// effectiveProbeMode applies the global probe_mode setting to endpoints that
+// have not opted into an explicit non-ICMP mode.
+func effectiveProbeMode(target store.ProbeTarget, settings model.Settings) string {
+	switch target.ProbeMode {
+	case model.ProbeModeHTTP, model.ProbeModeHTTPS, model.ProbeModeTCP, model.ProbeModeICMPTCP:
+		return target.ProbeMode
+	}
+	if settings.ProbeMode == model.ProbeModeTCP {
+		return model.ProbeModeTCP
+	}
+	return model.ProbeModeICMP
+}
+
+// probeTarget runs one round against target. A round that timed out is
+// re-sent up to probe_retries times; the first round that gets an answer, or
+// fails another way, is the result. It keeps the first round's timestamp and
+// records how many retries were sent.
 func (e *Engine) probeTarget(ctx context.Context, target store.ProbeTarget, settings model.Settings) (model.PingResult, bool) {
+	var (
+		result   model.PingResult
+		canceled bool
+	)
+	retries := probeRetries(settings)
+	startedAt := time.Now().UTC()
+	for attempt := 0; ; attempt++ {
+		result, canceled = e.probeTargetOnce(ctx, target, settings)
+		if canceled {
+			return result, true
+		}
+		result.Retries = attempt
+		if result.Success || result.ErrorCode != "Request Timeout" || attempt == retries {
+			break
+		}
+	}
+	result.Timestamp = startedAt
+	result.IPMismatch = result.Success && replyIPMismatch(target.IP, result.ReplyIP)
+	return result, false
+}
+
+func (e *Engine) probeTargetOnce(ctx context.Context, target store.ProbeTarget, settings model.Settings) (model.PingResult, bool) {
+	switch effectiveProbeMode(target, settings) {
+	case model.ProbeModeHTTP, model.ProbeModeHTTPS:
+		return e.probeHTTPTarget(ctx, target, settings)
+	case model.ProbeModeTCP:
+		return e.probeTCPTarget(ctx, target, settings)
+	case model.ProbeModeICMPTCP:
+		return e.probeICMPWithTCPFallback(ctx, target, settings)
+	}
+	return e.probeICMPTarget(ctx, target, settings)
+}
+
+// replyIPMismatch reports whether a reply came from an address other than
+// the probed one, e.g. a firewall or NAT device answering on its behalf.
+func replyIPMismatch(targetIP string, replyIP *string) bool {
+	if replyIP == nil || *replyIP == "" {
+		return false
+	}
+	target := net.ParseIP(targetIP)
+	reply := net.ParseIP(*replyIP)
+	return target != nil && reply != nil && !target.Equal(reply)
+}
+
+func (e *Engine) probeICMPTarget(ctx context.Context, target store.ProbeTarget, settings model.Settings) (model.PingResult, bool) {
 	now := time.Now().UTC()
-	latency, replyIP, ttl, err := e.sendICMPEcho(ctx, target.IP, settings.ICMPPayloadSize, settings.ICMPTimeoutMs)
-	if err != nil && errors.Is(err, context.Canceled) {
-		return model.PingResult{}, true
+	timeoutMs := e.adaptiveTimeouts.timeoutMs(target.EndpointID, settings.ICMPTimeoutMs)
+	probes := probesPerRound(settings)
+	latencies := make([]float64, 0, probes)
+	var replyIP *string
+	var ttl *int
+	var lastErr error
+	outOfOrder := 0
+	var driftSum float64
+	drifts := 0
+	for i := 0; i < probes; i++ {
+		reply, err := e.sendICMPEcho(ctx, target.EndpointID, target.IP, settings.ICMPPayloadSize, settings.PayloadPattern, timeoutMs)
+		if err != nil && errors.Is(err, context.Canceled) {
+			return model.PingResult{}, true
+		}
+		if err != nil {
+			e.adaptiveTimeouts.observe(target.EndpointID, false, nil)
+			lastErr = err
+			continue
+		}
+		e.adaptiveTimeouts.observe(target.EndpointID, true, &reply.latencyMs)
+		latencies = append(latencies, reply.latencyMs)
+		replyIP = &reply.replyIP
+		ttl = reply.ttl
+		if reply.outOfOrder {
+			outOfOrder++
+		}
+		if reply.driftMs != nil {
+			driftSum += *reply.driftMs
+			drifts++
+		}
 	}
 
 	result := model.PingResult{
-		EndpointID:   target.EndpointID,
-		Timestamp:    now,
-		Success:      err == nil,
-		LatencyMs:    latency,
-		ReplyIP:      replyIP,
-		TTL:          ttl,
-		PayloadBytes: settings.ICMPPayloadSize,
-	}
-	if err != nil {
-		result.ErrorCode = mapProbeError(err)
+		EndpointID:        target.EndpointID,
+		Timestamp:         now,
+		Success:           len(latencies) > 0,
+		ReplyIP:           replyIP,
+		TTL:               ttl,
+		PayloadBytes:      settings.ICMPPayloadSize,
+		DuplicateReplies:  e.lateDuplicates.take(target.EndpointID),
+		OutOfOrderReplies: outOfOrder,
+	}
+	if drifts > 0 {
+		drift := driftSum / float64(drifts)
+		result.DriftMs = &drift
+	}
+	applyEchoRoundStats(&result, latencies, probes)
+	if !result.Success {
+		result.ErrorCode = mapProbeError(lastErr)
+		result.ErrorDetail = probeErrorDetail(lastErr)
 	}
 	return result, false
 }
 
-func (e *Engine) sendICMPEcho(ctx context.Context, ip string, payloadSize, timeoutMs int) (*float64, *string, *int, error) {
+func (e *Engine) sendICMPEcho(ctx context.Context, endpointID int64, ip string, payloadSize int, payloadPattern string, timeoutMs int) (echoReply, error) {
 	parsedIP := net.ParseIP(ip)
 	if parsedIP == nil {
-		return nil, nil, nil, fmt.Errorf("invalid target ip")
+		return echoReply{}, fmt.Errorf("invalid target ip")
 	}
 	if ctx.Err() != nil {
-		return nil, nil, nil, context.Canceled
+		return echoReply{}, context.Canceled
 	}
 
-	conn := e.currentConn()
+	family := icmpFamilyFor(parsedIP)
+	conn := e.currentConnFor(family)
 	if conn == nil {
 		if ctx.Err() != nil {
-			return nil, nil, nil, context.Canceled
+			return echoReply{}, context.Canceled
 		}
-		return nil, nil, nil, fmt.Errorf("probe socket unavailable")
+		return echoReply{}, fmt.Errorf("probe socket unavailable")
 	}
 
-	seq, pending, err := e.registerPendingProbe()
+	pattern := normalizePayloadPattern(payloadPattern)
+	clockTarget := ""
+	if pattern == model.PayloadPatternTimestamp && payloadSize >= 2*probeStampSize {
+		clockTarget = ip
+	}
+	seq, pending, err := e.registerPendingProbe(roundTrackerFrom(ctx), endpointID, clockTarget)
 	if err != nil {
-		return nil, nil, nil, err
+		return echoReply{}, err
 	}
-	defer e.unregisterPendingProbe(seq, pending)
+	answered := false
+	defer func() {
+		if !answered {
+			e.unregisterPendingProbe(seq, pending)
+		}
+	}()
 
-	payload := e.payloadBytes(payloadSize)
+	payload := e.probePayload(payloadSize, pattern, pending)
 	msg := icmp.Message{
-		Type: ipv4.ICMPTypeEcho,
+		Type: family.echoRequest,
 		Code: 0,
 		Body: &icmp.Echo{
-			ID:   e.engineID,
+			ID:   e.echoID(),
 			Seq:  seq,
 			Data: payload,
 		},
@@ -823,50 +1328,130 @@ func (e *Engine) sendICMPEcho(ctx context.Context, ip string, payloadSize, timeo
 
 	wire, err := msg.Marshal(nil)
 	if err != nil {
-		return nil, nil, nil, err
+		return echoReply{}, err
 	}
 
 	if _, err := conn.WriteTo(wire, &net.IPAddr{IP: parsedIP}); err != nil {
 		if ctx.Err() != nil {
-			return nil, nil, nil, context.Canceled
+			return echoReply{}, context.Canceled
 		}
-		return nil, nil, nil, err
+		return echoReply{}, err
 	}
 
-	timer := time.NewTimer(time.Duration(timeoutMs) * time.Millisecond)
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+	deadline := time.Now().Add(timeout)
+	timer := time.NewTimer(timeout)
 	defer timer.Stop()
 
 	select {
 	case <-ctx.Done():
-		return nil, nil, nil, context.Canceled
+		return echoReply{}, context.Canceled
 	case <-timer.C:
-		return nil, nil, nil, context.DeadlineExceeded
+		return echoReply{}, context.DeadlineExceeded
 	case reply := <-pending.replyCh:
+		if reply.err != nil {
+			return echoReply{}, reply.err
+		}
 		replyIP := reply.replyIP
 		if replyIP == "" {
 			replyIP = ip
 		}
-		latency := reply.latencyMs
-		return &latency, &replyIP, reply.ttl, nil
+		answered = true
+		e.lingerPendingProbe(seq, pending, duplicateReplyWait(reply.latencyMs, time.Until(deadline)))
+		return echoReply{
+			latencyMs:  reply.latencyMs,
+			replyIP:    replyIP,
+			ttl:        reply.ttl,
+			outOfOrder: reply.outOfOrder,
+			driftMs:    reply.driftMs,
+		}, nil
 	}
 }
 
-func (e *Engine) payloadBytes(payloadSize int) []byte {
+type payloadKey struct {
+	size    int
+	pattern string
+}
+
+// normalizePayloadPattern falls back to the 0x42 filler, which the probe sent
+// before the pattern was configurable.
+func normalizePayloadPattern(pattern string) string {
+	switch pattern {
+	case model.PayloadPatternZeros, model.PayloadPatternRandom, model.PayloadPatternIncrementing, model.PayloadPatternTimestamp:
+		return pattern
+	}
+	return model.PayloadPatternFiller
+}
+
+// probePayload builds the echo data: the filler for pattern with the send
+// stamp over its first probeStampSize bytes. The timestamp pattern also
+// writes the monotonic send offset into the next probeStampSize bytes.
+func (e *Engine) probePayload(payloadSize int, pattern string, pending *pendingProbe) []byte {
+	pattern = normalizePayloadPattern(pattern)
+	var filler []byte
+	if pattern == model.PayloadPatternRandom {
+		filler = make([]byte, payloadSize)
+		_, _ = rand.Read(filler)
+	} else {
+		filler = e.payloadBytes(payloadSize, pattern)
+	}
+	payload := stampProbePayload(filler, pending.stamp)
+	if pattern == model.PayloadPatternTimestamp && len(payload) >= 2*probeStampSize {
+		offset := pending.sentAt.Sub(e.clockEpoch)
+		binary.BigEndian.PutUint64(payload[probeStampSize:2*probeStampSize], uint64(offset))
+	}
+	return payload
+}
+
+// payloadBytes returns the cached filler for the fixed patterns. Callers
+// must copy it before writing.
+func (e *Engine) payloadBytes(payloadSize int, pattern string) []byte {
 	e.payloadMu.Lock()
 	defer e.payloadMu.Unlock()
 
-	if payload, ok := e.payloadCache[payloadSize]; ok {
+	key := payloadKey{size: payloadSize, pattern: pattern}
+	if payload, ok := e.payloadCache[key]; ok {
 		return payload
 	}
 
-	payload := bytes.Repeat([]byte{0x42}, payloadSize)
-	e.payloadCache[payloadSize] = payload
+	payload := make([]byte, payloadSize)
+	switch pattern {
+	case model.PayloadPatternFiller:
+		for i := range payload {
+			payload[i] = 0x42
+		}
+	case model.PayloadPatternIncrementing:
+		for i := range payload {
+			payload[i] = byte(i)
+		}
+	}
+	e.payloadCache[key] = payload
+	return payload
+}
+
+func stampProbePayload(filler []byte, stamp int64) []byte {
+	if len(filler) < probeStampSize {
+		return filler
+	}
+	payload := make([]byte, len(filler))
+	copy(payload, filler)
+	binary.BigEndian.PutUint64(payload[:probeStampSize], uint64(stamp))
 	return payload
 }
 
-func (e *Engine) currentConn() packetConn {
+func (p *pendingProbe) matchesStamp(data []byte) bool {
+	if len(data) < probeStampSize {
+		return false
+	}
+	return int64(binary.BigEndian.Uint64(data[:probeStampSize])) == p.stamp
+}
+
+func (e *Engine) currentConnFor(family icmpFamily) packetConn {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	if family.name == icmpV6.name {
+		return e.conn6
+	}
 	return e.conn
 }
 
@@ -876,7 +1461,7 @@ func (e *Engine) currentResultCh() chan resultEnvelope {
 	return e.resultCh
 }
 
-func (e *Engine) registerPendingProbe() (int, *pendingProbe, error) {
+func (e *Engine) registerPendingProbe(tracker *roundTracker, endpointID int64, clockTarget string) (int, *pendingProbe, error) {
 	e.pendingMu.Lock()
 	defer e.pendingMu.Unlock()
 
@@ -889,12 +1474,17 @@ func (e *Engine) registerPendingProbe() (int, *pendingProbe, error) {
 			continue
 		}
 
+		sentAt := time.Now()
 		pending := &pendingProbe{
-			replyCh: make(chan replyInfo, 1),
-			sentAt:  time.Now(),
+			replyCh:     make(chan replyInfo, 1),
+			sentAt:      sentAt,
+			stamp:       sentAt.UnixNano(),
+			endpointID:  endpointID,
+			tracker:     tracker,
+			clockTarget: clockTarget,
 		}
 		e.pending[seq] = pending
-		if tracker := e.currentActiveRound(); tracker != nil {
+		if tracker != nil {
 			tracker.notePendingCount(len(e.pending))
 		}
 		return seq, pending, nil
@@ -930,16 +1520,18 @@ func (e *Engine) pendingCount() int {
 	return len(e.pending)
 }
 
-func (e *Engine) setActiveRound(tracker *roundTracker) {
-	e.roundMu.Lock()
-	defer e.roundMu.Unlock()
-	e.activeRound = tracker
+type roundTrackerKey struct{}
+
+// withRoundTracker tags ctx with the round its probes belong to, so that
+// schedules running side by side each count their own pending probes and
+// stale replies.
+func withRoundTracker(ctx context.Context, tracker *roundTracker) context.Context {
+	return context.WithValue(ctx, roundTrackerKey{}, tracker)
 }
 
-func (e *Engine) currentActiveRound() *roundTracker {
-	e.roundMu.Lock()
-	defer e.roundMu.Unlock()
-	return e.activeRound
+func roundTrackerFrom(ctx context.Context) *roundTracker {
+	tracker, _ := ctx.Value(roundTrackerKey{}).(*roundTracker)
+	return tracker
 }
 
 func newRoundTracker(roundID uint64, startedAt time.Time, interval time.Duration) *roundTracker {
@@ -991,6 +1583,14 @@ func (t *roundTracker) noteProbeResult(success bool) {
 	t.failures.Add(1)
 }
 
+func (t *roundTracker) noteTransition() {
+	t.transitions.Add(1)
+}
+
+func (t *roundTracker) noteStaleReply() {
+	t.staleReplies.Add(1)
+}
+
 func (t *roundTracker) notePersistError(count int) {
 	t.persistErrs.Add(int64(count))
 }
@@ -999,6 +1599,11 @@ func (t *roundTracker) noteBackpressure() {
 	t.backpressure.Add(1)
 }
 
+// noteWorkerWait counts dispatches that found every probe worker busy.
+func (t *roundTracker) noteWorkerWait() {
+	t.workerWaits.Add(1)
+}
+
 func (t *roundTracker) notePendingCount(depth int) {
 	t.observeMax(&t.pendingPeak, int64(depth))
 }
@@ -1053,23 +1658,26 @@ func (t *roundTracker) logSummary() {
 		persistBatchAvg = float64(t.handledResults.Load()) / float64(batchCount)
 	}
 
-	log.Printf(
-		"probe round finished round_id=%d duration_ms=%d overrun=%t targets=%d successes=%d failures=%d persist_failures=%d backpressure=%d send_span_ms=%d send_slip_ms_max=%d pending_peak=%d result_queue_peak=%d persist_batch_avg=%.2f persist_batch_max=%d persist_duration_ms=%d",
-		t.roundID,
-		t.probeDurationNs.Load()/int64(time.Millisecond),
-		t.overrun.Load(),
-		t.targetCount.Load(),
-		t.successes.Load(),
-		t.failures.Load(),
-		t.persistErrs.Load(),
-		t.backpressure.Load(),
-		sendSpanMs,
-		t.sendSlipMaxNs.Load()/int64(time.Millisecond),
-		t.pendingPeak.Load(),
-		t.queuePeak.Load(),
-		persistBatchAvg,
-		t.batchMax.Load(),
-		t.persistDurationNs.Load()/int64(time.Millisecond),
+	slog.Info(
+		"probe round finished",
+		"round_id", t.roundID,
+		"duration_ms", t.probeDurationNs.Load()/int64(time.Millisecond),
+		"overrun", t.overrun.Load(),
+		"targets", t.targetCount.Load(),
+		"successes", t.successes.Load(),
+		"failures", t.failures.Load(),
+		"transitions", t.transitions.Load(),
+		"persist_failures", t.persistErrs.Load(),
+		"backpressure", t.backpressure.Load(),
+		"worker_waits", t.workerWaits.Load(),
+		"stale_replies", t.staleReplies.Load(),
+		"send_span_ms", sendSpanMs,
+		"send_slip_ms_max", t.sendSlipMaxNs.Load()/int64(time.Millisecond),
+		"pending_peak", t.pendingPeak.Load(),
+		"result_queue_peak", t.queuePeak.Load(),
+		"persist_batch_avg", math.Round(persistBatchAvg*100)/100,
+		"persist_batch_max", t.batchMax.Load(),
+		"persist_duration_ms", t.persistDurationNs.Load()/int64(time.Millisecond),
 	)
 }
 
@@ -1085,7 +1693,24 @@ func (t *roundTracker) observeMax(counter *atomic.Int64, value int64) {
 	}
 }
 
+// probeErrorDetail is the raw error text stored next to the categorized
+// ErrorCode.
+func probeErrorDetail(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
 func mapProbeError(err error) string {
+	var unreachErr *destinationUnreachableError
+	if errors.As(err, &unreachErr) {
+		return unreachErr.reason
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) || errors.Is(err, errNoResolvedAddress) {
+		return "DNS Failure"
+	}
 	if errors.Is(err, context.DeadlineExceeded) {
 		return "Request Timeout"
 	}
@@ -1093,6 +1718,19 @@ func mapProbeError(err error) string {
 	if errors.As(err, &netErr) && netErr.Timeout() {
 		return "Request Timeout"
 	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		switch errno {
+		case syscall.ECONNREFUSED:
+			return "Connection Refused"
+		case syscall.ECONNRESET:
+			return "Connection Reset"
+		case syscall.ENETUNREACH, syscall.EHOSTUNREACH:
+			return "No Route To Host"
+		case syscall.EPERM, syscall.EACCES:
+			return "Permission Denied"
+		}
+	}
 	errText := strings.ToLower(err.Error())
 	if strings.Contains(errText, "operation not permitted") || strings.Contains(errText, "permission") {
 		return "Permission Denied"
@@ -1,16 +1,23 @@
 package probe
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net"
+	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
 	"golang.org/x/net/icmp"
-	"golang.org/x/net/ipv4"
 
 	"sonarscope/backend/internal/model"
 	"sonarscope/backend/internal/store"
@@ -28,10 +35,14 @@ type fakeProbeStore struct {
 	batchGate        chan struct{}
 	batchDelay       time.Duration
 	singleDelay      time.Duration
+	listDelay        time.Duration
 	failBatchCount   int
 }
 
 func (s *fakeProbeStore) ListProbeTargets(ctx context.Context, scope string, groupIDs []int64) ([]store.ProbeTarget, error) {
+	if s.listDelay > 0 {
+		time.Sleep(s.listDelay)
+	}
 	items := make([]store.ProbeTarget, len(s.targets))
 	copy(items, s.targets)
 	return items, nil
@@ -105,6 +116,15 @@ func (b *fakeBroadcaster) Broadcast(event any) {
 	b.mu.Unlock()
 }
 
+func (b *fakeBroadcaster) BroadcastProbeUpdate(endpointIDs []int64, timestamp time.Time) {
+	b.Broadcast(map[string]any{
+		"type":         "probe_update",
+		"count":        len(endpointIDs),
+		"endpoint_ids": append([]int64(nil), endpointIDs...),
+		"timestamp":    timestamp,
+	})
+}
+
 func (b *fakeBroadcaster) ClientCount() int {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -134,6 +154,7 @@ type fakePacketConn struct {
 	closed         bool
 	autoReply      bool
 	autoReplyDelay time.Duration
+	family         icmpFamily
 }
 
 type fakeRead struct {
@@ -143,9 +164,14 @@ type fakeRead struct {
 }
 
 func newFakePacketConn() *fakePacketConn {
+	return newFakePacketConnFamily(icmpV4)
+}
+
+func newFakePacketConnFamily(family icmpFamily) *fakePacketConn {
 	return &fakePacketConn{
 		readCh:  make(chan fakeRead, 64),
 		closeCh: make(chan struct{}),
+		family:  family,
 	}
 }
 
@@ -191,7 +217,7 @@ func (c *fakePacketConn) WriteTo(b []byte, dst net.Addr) (int, error) {
 	c.mu.Unlock()
 
 	if autoReply {
-		echo := parseEchoRequestWire(wire)
+		echo := parseEchoRequestWireFamily(wire, c.family)
 		peerIP := ""
 		if ipAddr, ok := dst.(*net.IPAddr); ok && ipAddr.IP != nil {
 			peerIP = ipAddr.IP.String()
@@ -200,7 +226,7 @@ func (c *fakePacketConn) WriteTo(b []byte, dst net.Addr) (int, error) {
 			if autoReplyDelay > 0 {
 				time.Sleep(autoReplyDelay)
 			}
-			_ = c.InjectEchoReply(echo.ID, echo.Seq, peerIP)
+			_ = c.InjectEchoReplyData(echo.ID, echo.Seq, peerIP, echo.Data)
 		}()
 	}
 
@@ -239,14 +265,26 @@ func (c *fakePacketConn) Closed() bool {
 	return c.closed
 }
 
+// InjectEchoReply replies with the payload of the most recent request written
+// for seq, mirroring how a real host echoes the request data.
 func (c *fakePacketConn) InjectEchoReply(id, seq int, peerIP string) error {
+	data := []byte{0x42}
+	for _, wire := range c.Writes() {
+		if echo := parseEchoRequestWireFamily(wire, c.family); echo.Seq == seq {
+			data = echo.Data
+		}
+	}
+	return c.InjectEchoReplyData(id, seq, peerIP, data)
+}
+
+func (c *fakePacketConn) InjectEchoReplyData(id, seq int, peerIP string, data []byte) error {
 	msg := icmp.Message{
-		Type: ipv4.ICMPTypeEchoReply,
+		Type: c.family.echoReply,
 		Code: 0,
 		Body: &icmp.Echo{
 			ID:   id,
 			Seq:  seq,
-			Data: []byte{0x42},
+			Data: data,
 		},
 	}
 	wire, err := msg.Marshal(nil)
@@ -261,9 +299,12 @@ func (c *fakePacketConn) InjectEchoReply(id, seq int, peerIP string) error {
 }
 
 func newTestEngine(st probeStore, options Options, settings model.Settings, conn *fakePacketConn) *Engine {
-	engine := newEngineWithDeps(st, telemetry.NewHub(), options, settings, func() (packetConn, error) {
+	engine := newEngineWithDeps(st, telemetry.NewHub(nil), options, settings, func() (packetConn, error) {
 		return conn, nil
 	})
+	engine.packetConn6Factory = func() (packetConn, error) {
+		return newFakePacketConnFamily(icmpV6), nil
+	}
 	engine.mu.Lock()
 	engine.conn = conn
 	engine.mu.Unlock()
@@ -368,10 +409,17 @@ func parseEchoRequest(t *testing.T, wire []byte) *icmp.Echo {
 }
 
 func parseEchoRequestWire(wire []byte) *icmp.Echo {
-	msg, err := icmp.ParseMessage(ipv4.ICMPTypeEcho.Protocol(), wire)
+	return parseEchoRequestWireFamily(wire, icmpV4)
+}
+
+func parseEchoRequestWireFamily(wire []byte, family icmpFamily) *icmp.Echo {
+	msg, err := icmp.ParseMessage(family.protocol, wire)
 	if err != nil {
 		panic(err)
 	}
+	if msg.Type != family.echoRequest {
+		panic("unexpected message type")
+	}
 	echo, ok := msg.Body.(*icmp.Echo)
 	if !ok {
 		panic("unexpected message body")
@@ -399,8 +447,8 @@ func TestMatchingReplyWakesOnlyRegisteredWaiter(t *testing.T) {
 	secondResult := make(chan result, 1)
 
 	go func() {
-		_, replyIP, _, err := engine.sendICMPEcho(context.Background(), "10.0.0.1", 56, 500)
-		firstResult <- result{replyIP: derefString(replyIP), err: err}
+		reply, err := engine.sendICMPEcho(context.Background(), 0, "10.0.0.1", 56, "", 500)
+		firstResult <- result{replyIP: reply.replyIP, err: err}
 	}()
 	waitForWriteCount(t, conn, 1, time.Second)
 	firstEcho := parseEchoRequest(t, conn.Writes()[0])
@@ -408,8 +456,8 @@ func TestMatchingReplyWakesOnlyRegisteredWaiter(t *testing.T) {
 	ctxSecond, cancelSecond := context.WithCancel(context.Background())
 	defer cancelSecond()
 	go func() {
-		_, replyIP, _, err := engine.sendICMPEcho(ctxSecond, "10.0.0.2", 56, 500)
-		secondResult <- result{replyIP: derefString(replyIP), err: err}
+		reply, err := engine.sendICMPEcho(ctxSecond, 0, "10.0.0.2", 56, "", 500)
+		secondResult <- result{replyIP: reply.replyIP, err: err}
 	}()
 	waitForWriteCount(t, conn, 2, time.Second)
 	secondEcho := parseEchoRequest(t, conn.Writes()[1])
@@ -418,7 +466,7 @@ func TestMatchingReplyWakesOnlyRegisteredWaiter(t *testing.T) {
 		t.Fatal("expected unique sequence numbers")
 	}
 
-	if err := conn.InjectEchoReply(engine.engineID, firstEcho.Seq, "10.0.0.1"); err != nil {
+	if err := conn.InjectEchoReply(engine.echoID(), firstEcho.Seq, "10.0.0.1"); err != nil {
 		t.Fatalf("inject echo reply: %v", err)
 	}
 
@@ -467,14 +515,14 @@ func TestForeignAndLateRepliesDoNotLeakPendingEntries(t *testing.T) {
 
 	resultCh := make(chan error, 1)
 	go func() {
-		_, _, _, err := engine.sendICMPEcho(ctxProbe, "10.0.0.3", 56, 500)
+		_, err := engine.sendICMPEcho(ctxProbe, 0, "10.0.0.3", 56, "", 500)
 		resultCh <- err
 	}()
 
 	waitForWriteCount(t, conn, 1, time.Second)
 	echo := parseEchoRequest(t, conn.Writes()[0])
 
-	if err := conn.InjectEchoReply(engine.engineID+1, echo.Seq, "10.0.0.3"); err != nil {
+	if err := conn.InjectEchoReply(engine.echoID()+1, echo.Seq, "10.0.0.3"); err != nil {
 		t.Fatalf("inject foreign reply: %v", err)
 	}
 
@@ -500,7 +548,7 @@ func TestForeignAndLateRepliesDoNotLeakPendingEntries(t *testing.T) {
 
 	waitForPendingCount(t, engine, 0, time.Second)
 
-	if err := conn.InjectEchoReply(engine.engineID, echo.Seq, "10.0.0.3"); err != nil {
+	if err := conn.InjectEchoReply(engine.echoID(), echo.Seq, "10.0.0.3"); err != nil {
 		t.Fatalf("inject late reply: %v", err)
 	}
 	time.Sleep(20 * time.Millisecond)
@@ -509,6 +557,247 @@ func TestForeignAndLateRepliesDoNotLeakPendingEntries(t *testing.T) {
 	}
 }
 
+func TestReplyWithStaleStampForRecycledSeqIsDiscarded(t *testing.T) {
+	conn := newFakePacketConn()
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{
+		PingIntervalSec: 1,
+		ICMPPayloadSize: 56,
+		ICMPTimeoutMs:   500,
+	}, conn)
+
+	cancelReceiver, recvDone := startReceiver(t, engine, conn)
+	defer stopReceiver(t, cancelReceiver, conn, recvDone)
+
+	resultCh := make(chan error, 1)
+	go func() {
+		_, err := engine.sendICMPEcho(context.Background(), 0, "10.0.0.4", 56, "", 500)
+		resultCh <- err
+	}()
+
+	waitForWriteCount(t, conn, 1, time.Second)
+	echo := parseEchoRequest(t, conn.Writes()[0])
+	if len(echo.Data) != 56 {
+		t.Fatalf("payload len = %d, want 56", len(echo.Data))
+	}
+
+	staleData := append([]byte{}, echo.Data...)
+	binary.BigEndian.PutUint64(staleData[:probeStampSize], uint64(time.Now().Add(-time.Minute).UnixNano()))
+	if err := conn.InjectEchoReplyData(engine.echoID(), echo.Seq, "10.0.0.4", staleData); err != nil {
+		t.Fatalf("inject stale reply: %v", err)
+	}
+
+	select {
+	case err := <-resultCh:
+		t.Fatalf("probe completed from stale reply: %v", err)
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	if err := conn.InjectEchoReply(engine.echoID(), echo.Seq, "10.0.0.4"); err != nil {
+		t.Fatalf("inject matching reply: %v", err)
+	}
+	select {
+	case err := <-resultCh:
+		if err != nil {
+			t.Fatalf("probe failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("probe did not complete after matching reply")
+	}
+}
+
+func TestDuplicateEchoRepliesAreCounted(t *testing.T) {
+	conn := newFakePacketConn()
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{
+		PingIntervalSec: 1,
+		ICMPPayloadSize: 56,
+		ICMPTimeoutMs:   500,
+	}, conn)
+
+	cancelReceiver, recvDone := startReceiver(t, engine, conn)
+	defer stopReceiver(t, cancelReceiver, conn, recvDone)
+
+	resultCh := make(chan error, 1)
+	go func() {
+		_, err := engine.sendICMPEcho(context.Background(), 5, "10.0.0.5", 56, "", 500)
+		resultCh <- err
+	}()
+
+	waitForWriteCount(t, conn, 1, time.Second)
+	echo := parseEchoRequest(t, conn.Writes()[0])
+	for i := 0; i < 3; i++ {
+		if err := conn.InjectEchoReply(engine.echoID(), echo.Seq, "10.0.0.5"); err != nil {
+			t.Fatalf("inject reply %d: %v", i+1, err)
+		}
+	}
+
+	select {
+	case err := <-resultCh:
+		if err != nil {
+			t.Fatalf("probe failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("probe did not complete after matching reply")
+	}
+	waitForPendingCount(t, engine, 0, time.Second)
+	if got := engine.lateDuplicates.take(5); got != 2 {
+		t.Fatalf("duplicates = %d, want 2", got)
+	}
+	if got := engine.lateDuplicates.take(5); got != 0 {
+		t.Fatalf("duplicates taken twice: %d", got)
+	}
+}
+
+func TestProbeICMPTargetTakesLateDuplicates(t *testing.T) {
+	conn := newFakePacketConn()
+	conn.autoReply = true
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, conn)
+	cancelReceiver, recvDone := startReceiver(t, engine, conn)
+	defer stopReceiver(t, cancelReceiver, conn, recvDone)
+
+	engine.lateDuplicates.add(7, 3)
+	result, _ := engine.probeTarget(context.Background(), store.ProbeTarget{EndpointID: 7, IP: "10.0.0.7"}, model.Settings{ICMPTimeoutMs: 500, ICMPPayloadSize: 56})
+	if !result.Success || result.DuplicateReplies != 3 {
+		t.Fatalf("unexpected result: success=%v duplicates=%d", result.Success, result.DuplicateReplies)
+	}
+}
+
+func TestDuplicateReplyWait(t *testing.T) {
+	if got := duplicateReplyWait(2, time.Second); got != duplicateReplyMinWait {
+		t.Fatalf("fast reply wait = %s, want %s", got, duplicateReplyMinWait)
+	}
+	if got := duplicateReplyWait(40, time.Second); got != 40*time.Millisecond {
+		t.Fatalf("slow reply wait = %s, want 40ms", got)
+	}
+	if got := duplicateReplyWait(40, 5*time.Millisecond); got != 5*time.Millisecond {
+		t.Fatalf("wait past the deadline = %s, want 5ms", got)
+	}
+}
+
+func TestTimestampRepliesFlagOutOfOrderAndDrift(t *testing.T) {
+	conn := newFakePacketConn()
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, conn)
+	cancelReceiver, recvDone := startReceiver(t, engine, conn)
+	defer stopReceiver(t, cancelReceiver, conn, recvDone)
+
+	type result struct {
+		reply echoReply
+		err   error
+	}
+	firstResult := make(chan result, 1)
+	secondResult := make(chan result, 1)
+	go func() {
+		reply, err := engine.sendICMPEcho(context.Background(), 0, "10.0.0.6", 56, model.PayloadPatternTimestamp, 500)
+		firstResult <- result{reply: reply, err: err}
+	}()
+	waitForWriteCount(t, conn, 1, time.Second)
+	go func() {
+		reply, err := engine.sendICMPEcho(context.Background(), 0, "10.0.0.6", 56, model.PayloadPatternTimestamp, 500)
+		secondResult <- result{reply: reply, err: err}
+	}()
+	waitForWriteCount(t, conn, 2, time.Second)
+	writes := conn.Writes()
+	firstEcho := parseEchoRequest(t, writes[0])
+	secondEcho := parseEchoRequest(t, writes[1])
+
+	// The later probe is answered first, so the earlier one's reply is late.
+	if err := conn.InjectEchoReply(engine.echoID(), secondEcho.Seq, "10.0.0.6"); err != nil {
+		t.Fatalf("inject second reply: %v", err)
+	}
+	second := <-secondResult
+	if second.err != nil || second.reply.outOfOrder || second.reply.driftMs == nil || *second.reply.driftMs != 0 {
+		t.Fatalf("unexpected first answered reply: %+v err=%v", second.reply, second.err)
+	}
+	if err := conn.InjectEchoReply(engine.echoID(), firstEcho.Seq, "10.0.0.6"); err != nil {
+		t.Fatalf("inject first reply: %v", err)
+	}
+	first := <-firstResult
+	if first.err != nil || !first.reply.outOfOrder || first.reply.driftMs == nil {
+		t.Fatalf("expected the late reply to be flagged out of order: %+v err=%v", first.reply, first.err)
+	}
+}
+
+func TestEchoClockDrift(t *testing.T) {
+	var clock echoClock
+	if outOfOrder, drift := clock.observe("10.0.0.1", 0, 10*time.Millisecond); outOfOrder || drift != 0 {
+		t.Fatalf("first reply: outOfOrder=%v drift=%s", outOfOrder, drift)
+	}
+	if outOfOrder, drift := clock.observe("10.0.0.1", time.Second, time.Second+30*time.Millisecond); outOfOrder || drift != 10*time.Millisecond {
+		t.Fatalf("slower reply: outOfOrder=%v drift=%s, want 10ms", outOfOrder, drift)
+	}
+	if outOfOrder, _ := clock.observe("10.0.0.1", 500*time.Millisecond, 2*time.Second); !outOfOrder {
+		t.Fatal("expected a reply to an older probe to be out of order")
+	}
+	if outOfOrder, drift := clock.observe("10.0.0.2", 0, 50*time.Millisecond); outOfOrder || drift != 0 {
+		t.Fatalf("other target: outOfOrder=%v drift=%s", outOfOrder, drift)
+	}
+	clock.reset()
+	if outOfOrder, drift := clock.observe("10.0.0.1", 0, 40*time.Millisecond); outOfOrder || drift != 0 {
+		t.Fatalf("after reset: outOfOrder=%v drift=%s", outOfOrder, drift)
+	}
+}
+
+func TestProbeTargetFlagsReplyFromDifferentIP(t *testing.T) {
+	conn := newFakePacketConn()
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, conn)
+	cancelReceiver, recvDone := startReceiver(t, engine, conn)
+	defer stopReceiver(t, cancelReceiver, conn, recvDone)
+
+	resultCh := make(chan model.PingResult, 1)
+	go func() {
+		result, _ := engine.probeTarget(context.Background(), store.ProbeTarget{EndpointID: 1, IP: "10.0.0.5"}, model.Settings{ICMPTimeoutMs: 500, ICMPPayloadSize: 56})
+		resultCh <- result
+	}()
+
+	waitForWriteCount(t, conn, 1, time.Second)
+	echo := parseEchoRequest(t, conn.Writes()[0])
+	if err := conn.InjectEchoReply(engine.echoID(), echo.Seq, "10.0.0.254"); err != nil {
+		t.Fatalf("inject reply: %v", err)
+	}
+
+	select {
+	case result := <-resultCh:
+		if !result.Success || !result.IPMismatch || result.ReplyIP == nil || *result.ReplyIP != "10.0.0.254" {
+			t.Fatalf("unexpected result: success=%v mismatch=%v reply=%v", result.Success, result.IPMismatch, result.ReplyIP)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("probe did not complete after matching reply")
+	}
+}
+
+func TestReplyIPMismatch(t *testing.T) {
+	reply := func(ip string) *string { return &ip }
+	if replyIPMismatch("10.0.0.5", nil) || replyIPMismatch("10.0.0.5", reply("")) {
+		t.Fatal("a missing reply ip must not be flagged")
+	}
+	if replyIPMismatch("10.0.0.5", reply("::ffff:10.0.0.5")) || replyIPMismatch("2001:db8::1", reply("2001:DB8::1")) {
+		t.Fatal("equivalent addresses must not be flagged")
+	}
+	if !replyIPMismatch("10.0.0.5", reply("10.0.0.6")) {
+		t.Fatal("expected a different reply ip to be flagged")
+	}
+}
+
+func TestStampProbePayloadLeavesCachedFillerUntouched(t *testing.T) {
+	filler := []byte{0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42}
+	pending := &pendingProbe{stamp: 0x0102030405060708}
+
+	payload := stampProbePayload(filler, pending.stamp)
+	if !pending.matchesStamp(payload) {
+		t.Fatalf("expected stamped payload to match: %x", payload)
+	}
+	if payload[probeStampSize] != 0x42 || len(payload) != len(filler) {
+		t.Fatalf("expected filler after stamp, got %x", payload)
+	}
+	for i, b := range filler {
+		if b != 0x42 {
+			t.Fatalf("filler byte %d mutated to %x", i, b)
+		}
+	}
+	if pending.matchesStamp(filler) {
+		t.Fatal("expected unstamped filler not to match")
+	}
+}
+
 func TestRunRoundPacesDispatchAcrossSendWindow(t *testing.T) {
 	conn := newFakePacketConn()
 	conn.autoReply = true
@@ -538,10 +827,8 @@ func TestRunRoundPacesDispatchAcrossSendWindow(t *testing.T) {
 
 	roundStarted := time.Now()
 	tracker := newRoundTracker(1, roundStarted, time.Second)
-	engine.setActiveRound(tracker)
-	dispatched := engine.runRound(context.Background(), 1, roundStarted, tracker, engine.CurrentSettings())
+	dispatched := engine.runRound(context.Background(), newProbeSchedule("all", nil), 1, roundStarted, tracker, engine.CurrentSettings())
 	tracker.finishProbePhase(dispatched, time.Since(roundStarted), false)
-	engine.setActiveRound(nil)
 
 	writeTimes := conn.WriteTimes()
 	if len(writeTimes) != 5 {
@@ -585,10 +872,8 @@ func TestRunRoundHonorsConfiguredWorkerLimit(t *testing.T) {
 	go func() {
 		roundStarted := time.Now()
 		tracker := newRoundTracker(1, roundStarted, time.Second)
-		engine.setActiveRound(tracker)
-		dispatched := engine.runRound(ctx, 1, roundStarted, tracker, engine.CurrentSettings())
+		dispatched := engine.runRound(ctx, newProbeSchedule("all", nil), 1, roundStarted, tracker, engine.CurrentSettings())
 		tracker.finishProbePhase(dispatched, time.Since(roundStarted), false)
-		engine.setActiveRound(nil)
 		close(done)
 	}()
 
@@ -606,6 +891,37 @@ func TestRunRoundHonorsConfiguredWorkerLimit(t *testing.T) {
 	}
 }
 
+func TestRunRoundCountsWorkerSaturation(t *testing.T) {
+	targets := []store.ProbeTarget{
+		{EndpointID: 1, IP: "10.0.0.1"},
+		{EndpointID: 2, IP: "10.0.0.2"},
+		{EndpointID: 3, IP: "10.0.0.3"},
+		{EndpointID: 4, IP: "10.0.0.4"},
+	}
+	settings := model.Settings{PingIntervalSec: 1, ICMPPayloadSize: 56, ICMPTimeoutMs: 50}
+
+	run := func(workers int) int64 {
+		conn := newFakePacketConn()
+		options := defaultTestOptions()
+		options.ProbeWorkers = workers
+		engine := newTestEngine(&fakeProbeStore{targets: targets}, options, settings, conn)
+		_, stopResults := startResultPipeline(t, engine)
+		defer stopResults()
+
+		// A zero interval disables pacing, so every target is offered at once.
+		tracker := newRoundTracker(1, time.Now(), 0)
+		engine.runRound(context.Background(), newProbeSchedule("all", nil), 1, time.Now(), tracker, settings)
+		return tracker.workerWaits.Load()
+	}
+
+	if waits := run(1); waits != int64(len(targets)-1) {
+		t.Fatalf("expected %d saturated dispatches with one worker, got %d", len(targets)-1, waits)
+	}
+	if waits := run(len(targets)); waits != 0 {
+		t.Fatalf("expected no saturation with a worker per target, got %d", waits)
+	}
+}
+
 func TestProbeWorkersContinueWhenBatchPersistenceIsBlocked(t *testing.T) {
 	conn := newFakePacketConn()
 	conn.autoReply = true
@@ -641,10 +957,8 @@ func TestProbeWorkersContinueWhenBatchPersistenceIsBlocked(t *testing.T) {
 	go func() {
 		roundStarted := time.Now()
 		tracker := newRoundTracker(1, roundStarted, time.Second)
-		engine.setActiveRound(tracker)
-		dispatched := engine.runRound(context.Background(), 1, roundStarted, tracker, engine.CurrentSettings())
+		dispatched := engine.runRound(context.Background(), newProbeSchedule("all", nil), 1, roundStarted, tracker, engine.CurrentSettings())
 		tracker.finishProbePhase(dispatched, time.Since(roundStarted), false)
-		engine.setActiveRound(nil)
 		close(done)
 	}()
 
@@ -809,14 +1123,17 @@ func TestLoopDoesNotStartOverlappingRounds(t *testing.T) {
 		targets: []store.ProbeTarget{
 			{EndpointID: 1, IP: "10.0.0.1"},
 		},
+		// A slow listing plus the 900ms capped timeout makes every round
+		// run past the 1s tick.
+		listDelay: 700 * time.Millisecond,
 	}
 
 	options := defaultTestOptions()
 	options.ProbeWorkers = 1
-	engine := newEngineWithDeps(store, telemetry.NewHub(), options, model.Settings{
+	engine := newEngineWithDeps(store, telemetry.NewHub(nil), options, model.Settings{
 		PingIntervalSec: 1,
 		ICMPPayloadSize: 56,
-		ICMPTimeoutMs:   5000,
+		ICMPTimeoutMs:   1000,
 	}, func() (packetConn, error) {
 		return conn, nil
 	})
@@ -826,12 +1143,54 @@ func TestLoopDoesNotStartOverlappingRounds(t *testing.T) {
 	}
 	defer engine.Stop()
 
-	waitForWriteCount(t, conn, 1, time.Second)
+	waitForWriteCount(t, conn, 1, 2*time.Second)
 	time.Sleep(1200 * time.Millisecond)
 
 	if got := conn.WriteCount(); got != 1 {
 		t.Fatalf("expected a single in-flight round write, got %d", got)
 	}
+	if got := engine.Status().OverrunRounds; got != 1 {
+		t.Fatalf("OverrunRounds = %d, want 1", got)
+	}
+}
+
+func TestRoundsShareOneICMPSocketAcrossTargets(t *testing.T) {
+	conn := newFakePacketConn()
+	conn.autoReply = true
+
+	targets := make([]store.ProbeTarget, 0, 200)
+	for i := 1; i <= 200; i++ {
+		targets = append(targets, store.ProbeTarget{EndpointID: int64(i), IP: fmt.Sprintf("10.0.%d.%d", i/250, i%250+1)})
+	}
+	st := &fakeProbeStore{targets: targets}
+
+	options := defaultTestOptions()
+	options.ProbeWorkers = 64
+	var factoryCalls atomic.Int32
+	engine := newEngineWithDeps(st, telemetry.NewHub(nil), options, model.Settings{
+		PingIntervalSec: 1,
+		ICMPPayloadSize: 56,
+		ICMPTimeoutMs:   500,
+	}, func() (packetConn, error) {
+		factoryCalls.Add(1)
+		return conn, nil
+	})
+	engine.packetConn6Factory = func() (packetConn, error) {
+		return nil, errors.New("ipv6 disabled in test")
+	}
+
+	if err := engine.Start("all", nil); err != nil {
+		t.Fatalf("start engine: %v", err)
+	}
+	waitForWriteCount(t, conn, 2*len(targets), 3*time.Second)
+	engine.Stop()
+
+	if got := factoryCalls.Load(); got != 1 {
+		t.Fatalf("expected one socket for every round, factory called %d times", got)
+	}
+	if got := engine.pendingCount(); got != 0 {
+		t.Fatalf("expected no pending probes after stop, got %d", got)
+	}
 }
 
 func TestConcurrentStartSerializesLifecycle(t *testing.T) {
@@ -846,7 +1205,7 @@ func TestConcurrentStartSerializesLifecycle(t *testing.T) {
 	var connsMu sync.Mutex
 	conns := make([]*fakePacketConn, 0, 2)
 
-	engine := newEngineWithDeps(store, telemetry.NewHub(), options, model.Settings{
+	engine := newEngineWithDeps(store, telemetry.NewHub(nil), options, model.Settings{
 		PingIntervalSec: 1,
 		ICMPPayloadSize: 56,
 		ICMPTimeoutMs:   500,
@@ -931,16 +1290,22 @@ func TestConcurrentStartSerializesLifecycle(t *testing.T) {
 func TestPayloadBytesReusesCachedPayloadBySize(t *testing.T) {
 	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
 
-	first := engine.payloadBytes(56)
-	second := engine.payloadBytes(56)
-	other := engine.payloadBytes(64)
+	first := engine.payloadBytes(56, model.PayloadPatternIncrementing)
+	second := engine.payloadBytes(56, model.PayloadPatternIncrementing)
+	other := engine.payloadBytes(64, model.PayloadPatternIncrementing)
+	zeros := engine.payloadBytes(56, model.PayloadPatternZeros)
 
 	if len(first) != 56 {
 		t.Fatalf("first payload len = %d, want 56", len(first))
 	}
 	for i, b := range first {
-		if b != 0x42 {
-			t.Fatalf("first payload byte %d = %x, want 42", i, b)
+		if b != byte(i) {
+			t.Fatalf("first payload byte %d = %x, want %x", i, b, byte(i))
+		}
+	}
+	for i, b := range zeros {
+		if b != 0 {
+			t.Fatalf("zeros payload byte %d = %x, want 0", i, b)
 		}
 	}
 
@@ -950,6 +1315,113 @@ func TestPayloadBytesReusesCachedPayloadBySize(t *testing.T) {
 	if len(other) == 0 || &first[0] == &other[0] {
 		t.Fatal("expected different-size payload requests to use a distinct cached slice")
 	}
+	if &first[0] == &zeros[0] {
+		t.Fatal("expected different patterns to use a distinct cached slice")
+	}
+}
+
+func TestProbePayloadPatterns(t *testing.T) {
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	pending := &pendingProbe{sentAt: engine.clockEpoch.Add(1500 * time.Millisecond), stamp: 0x0102030405060708}
+
+	for _, pattern := range []string{"", model.PayloadPatternFiller, model.PayloadPatternZeros, model.PayloadPatternRandom, model.PayloadPatternIncrementing, model.PayloadPatternTimestamp} {
+		payload := engine.probePayload(56, pattern, pending)
+		if len(payload) != 56 || !pending.matchesStamp(payload) {
+			t.Fatalf("pattern %q: expected a stamped 56-byte payload, got %x", pattern, payload)
+		}
+	}
+
+	filler := engine.probePayload(56, "", pending)
+	if filler[probeStampSize] != 0x42 || filler[55] != 0x42 {
+		t.Fatalf("expected empty pattern to default to the 0x42 filler, got %x", filler)
+	}
+
+	incrementing := engine.probePayload(56, model.PayloadPatternIncrementing, pending)
+	if incrementing[probeStampSize] != probeStampSize || incrementing[55] != 55 {
+		t.Fatalf("expected incrementing bytes, got %x", incrementing)
+	}
+
+	timestamp := engine.probePayload(56, model.PayloadPatternTimestamp, pending)
+	offset := time.Duration(binary.BigEndian.Uint64(timestamp[probeStampSize : 2*probeStampSize]))
+	if offset != 1500*time.Millisecond {
+		t.Fatalf("timestamp offset = %s, want 1.5s", offset)
+	}
+	for i, b := range engine.payloadBytes(56, model.PayloadPatternTimestamp) {
+		if b != 0 {
+			t.Fatalf("cached timestamp filler byte %d = %x, want 0", i, b)
+		}
+	}
+
+	if short := engine.probePayload(8, model.PayloadPatternTimestamp, pending); len(short) != 8 || !pending.matchesStamp(short) {
+		t.Fatalf("expected an 8-byte timestamp payload to carry only the stamp, got %x", short)
+	}
+}
+
+func TestMapProbeErrorClassifiesSyscallErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "deadline exceeded is a timeout",
+			err:  context.DeadlineExceeded,
+			want: "Request Timeout",
+		},
+		{
+			name: "network unreachable from sendto",
+			err:  &net.OpError{Op: "write", Net: "ip4:icmp", Err: os.NewSyscallError("sendto", syscall.ENETUNREACH)},
+			want: "No Route To Host",
+		},
+		{
+			name: "host unreachable wrapped by fmt",
+			err:  fmt.Errorf("send echo: %w", os.NewSyscallError("sendto", syscall.EHOSTUNREACH)),
+			want: "No Route To Host",
+		},
+		{
+			name: "permission errno",
+			err:  &net.OpError{Op: "write", Net: "ip4:icmp", Err: os.NewSyscallError("sendto", syscall.EPERM)},
+			want: "Permission Denied",
+		},
+		{
+			name: "permission text without errno",
+			err:  errors.New("listen ip4:icmp: operation not permitted"),
+			want: "Permission Denied",
+		},
+		{
+			name: "dns lookup failure",
+			err:  &net.DNSError{Err: "no such host", Name: "gone.lab", IsNotFound: true},
+			want: "DNS Failure",
+		},
+		{
+			name: "hostname without usable address",
+			err:  errNoResolvedAddress,
+			want: "DNS Failure",
+		},
+		{
+			name: "tcp connection refused",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			want: "Connection Refused",
+		},
+		{
+			name: "tcp connect timeout",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: context.DeadlineExceeded},
+			want: "Request Timeout",
+		},
+		{
+			name: "other errno falls back to probe error",
+			err:  os.NewSyscallError("sendto", syscall.ENOBUFS),
+			want: "Probe Error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mapProbeError(tc.err); got != tc.want {
+				t.Fatalf("mapProbeError(%v) = %q, want %q", tc.err, got, tc.want)
+			}
+		})
+	}
 }
 
 func derefString(value *string) string {
@@ -958,3 +1430,139 @@ func derefString(value *string) string {
 	}
 	return *value
 }
+
+func TestLogLimiterCapsLinesAndReportsSuppressed(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	limiter := newLogLimiter(2)
+	limiter.now = func() time.Time { return now }
+	limiter.logger = newTestLogger(&buf)
+
+	for i := 0; i < 5; i++ {
+		limiter.Log(slog.LevelInfo, fmt.Sprintf("line %d", i))
+	}
+	if lines := testLogLines(&buf); len(lines) != 2 {
+		t.Fatalf("lines in first window = %d, want 2: %v", len(lines), lines)
+	}
+
+	now = now.Add(time.Second)
+	limiter.Log(slog.LevelInfo, "line next")
+	lines := testLogLines(&buf)
+	want := []string{
+		`level=INFO msg="line 0"`,
+		`level=INFO msg="line 1"`,
+		`level=WARN msg="probe log throttled" suppressed=3`,
+		`level=INFO msg="line next"`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %v, want %v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestLogProbeResultLogsTransitionsAndSamples(t *testing.T) {
+	options := defaultTestOptions()
+	options.ResultLogTransition = true
+	options.ResultLogSample = 3
+	engine := newTestEngine(&fakeProbeStore{}, options, model.Settings{}, newFakePacketConn())
+	var buf bytes.Buffer
+	engine.resultLog.logger = newTestLogger(&buf)
+	engine.transitionLog.logger = engine.resultLog.logger
+
+	tracker := newRoundTracker(1, time.Now(), time.Second)
+	target := store.ProbeTarget{EndpointID: 7, IP: "10.0.0.7"}
+	outcomes := []bool{true, true, false, false, false, false, true}
+	for _, success := range outcomes {
+		engine.logProbeResult(tracker, target, model.PingResult{EndpointID: 7, Success: success})
+	}
+
+	if got := tracker.transitions.Load(); got != 2 {
+		t.Fatalf("transitions = %d, want 2", got)
+	}
+	lines := testLogLines(&buf)
+	transitionLines := 0
+	for _, line := range lines {
+		if strings.Contains(line, "transition=true") {
+			transitionLines++
+		}
+	}
+	if transitionLines != 2 {
+		t.Fatalf("transition lines = %d, want 2: %v", transitionLines, lines)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected 2 transitions plus 1 extra sampled line, got %v", lines)
+	}
+}
+
+func TestLogProbeResultTransitionsBypassSampledBudget(t *testing.T) {
+	options := defaultTestOptions()
+	options.ResultLogTransition = true
+	options.ResultLogSample = 1
+	options.LogLinesPerSec = 1
+	engine := newTestEngine(&fakeProbeStore{}, options, model.Settings{}, newFakePacketConn())
+	var buf bytes.Buffer
+	engine.resultLog.logger = newTestLogger(&buf)
+	engine.transitionLog.logger = engine.resultLog.logger
+	now := time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC)
+	engine.resultLog.now = func() time.Time { return now }
+	engine.transitionLog.now = engine.resultLog.now
+
+	tracker := newRoundTracker(1, now, time.Second)
+	for _, success := range []bool{true, true, true, false} {
+		engine.logProbeResult(tracker, store.ProbeTarget{EndpointID: 7, IP: "10.0.0.7"}, model.PingResult{EndpointID: 7, Success: success})
+	}
+
+	lines := testLogLines(&buf)
+	if len(lines) != 2 || !strings.Contains(lines[1], "transition=true") {
+		t.Fatalf("lines = %v, want one sampled line and the transition", lines)
+	}
+}
+
+func TestForgetUnownedStatesDropsUnlistedEndpoints(t *testing.T) {
+	options := defaultTestOptions()
+	options.ResultLogTransition = true
+	engine := newTestEngine(&fakeProbeStore{}, options, model.Settings{}, newFakePacketConn())
+	schedule := &probeSchedule{}
+	engine.owners.claim(schedule, []store.ProbeTarget{{EndpointID: 1}, {EndpointID: 2}})
+
+	tracker := newRoundTracker(1, time.Now(), time.Second)
+	for _, endpointID := range []int64{1, 2} {
+		engine.logProbeResult(tracker, store.ProbeTarget{EndpointID: endpointID}, model.PingResult{EndpointID: endpointID, Success: true})
+	}
+	engine.owners.claim(schedule, []store.ProbeTarget{{EndpointID: 1}})
+	engine.forgetUnownedStates()
+
+	if _, ok := engine.lastState[2]; ok || len(engine.lastState) != 1 {
+		t.Fatalf("lastState = %v, want only endpoint 1", engine.lastState)
+	}
+	engine.owners.release(schedule)
+	engine.forgetUnownedStates()
+	if len(engine.lastState) != 0 {
+		t.Fatalf("lastState = %v, want empty after the schedule stops", engine.lastState)
+	}
+}
+
+// newTestLogger writes text records without timestamps so tests can compare
+// whole lines.
+func newTestLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
+}
+
+func testLogLines(buf *bytes.Buffer) []string {
+	text := strings.TrimSpace(buf.String())
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
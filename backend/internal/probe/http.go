@@ -0,0 +1,129 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+const httpProbeBodyDrainLimit = 64 << 10
+
+func newHTTPProbeClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (e *Engine) probeHTTPTarget(ctx context.Context, target store.ProbeTarget, settings model.Settings) (model.PingResult, bool) {
+	now := time.Now().UTC()
+	latency, statusCode, err := e.sendHTTPProbe(ctx, target, settings.ICMPTimeoutMs)
+	if err != nil && errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return model.PingResult{}, true
+	}
+
+	result := model.PingResult{
+		EndpointID: target.EndpointID,
+		Timestamp:  now,
+		LatencyMs:  latency,
+		HTTPStatus: statusCode,
+	}
+	switch {
+	case err != nil:
+		result.ErrorCode = mapHTTPProbeError(err)
+		result.ErrorDetail = probeErrorDetail(err)
+	case *statusCode < 200 || *statusCode > 299:
+		result.ErrorCode = mapHTTPStatus(*statusCode)
+	default:
+		result.Success = true
+	}
+	return result, false
+}
+
+func (e *Engine) sendHTTPProbe(ctx context.Context, target store.ProbeTarget, timeoutMs int) (*float64, *int, error) {
+	targetURL, err := httpProbeURL(target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", "SonarScope-Probe")
+
+	started := time.Now()
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	latency := float64(time.Since(started).Microseconds()) / 1000.0
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, httpProbeBodyDrainLimit))
+	_ = resp.Body.Close()
+
+	statusCode := resp.StatusCode
+	return &latency, &statusCode, nil
+}
+
+func httpProbeURL(target store.ProbeTarget) (string, error) {
+	configured := strings.TrimSpace(target.HTTPTarget)
+	if strings.Contains(configured, "://") {
+		return configured, nil
+	}
+
+	scheme := target.ProbeMode
+	if scheme != model.ProbeModeHTTP && scheme != model.ProbeModeHTTPS {
+		return "", fmt.Errorf("unsupported http probe mode %q", target.ProbeMode)
+	}
+	host := strings.TrimSpace(target.IP)
+	if host == "" {
+		return "", errors.New("http probe target has no ip")
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if !strings.HasPrefix(configured, "/") {
+		configured = "/" + configured
+	}
+	return scheme + "://" + host + configured, nil
+}
+
+func mapHTTPStatus(statusCode int) string {
+	switch {
+	case statusCode >= 500:
+		return "HTTP Server Error"
+	case statusCode >= 400:
+		return "HTTP Client Error"
+	case statusCode >= 300:
+		return "HTTP Redirect"
+	default:
+		return "HTTP Unexpected Status"
+	}
+}
+
+func mapHTTPProbeError(err error) string {
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuthority) || errors.As(err, &hostnameErr) || errors.As(err, &recordErr) {
+		return "TLS Error"
+	}
+	return mapProbeError(err)
+}
@@ -0,0 +1,99 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+func TestProbeTargetHTTPRecordsStatusAndClassifiesFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			w.WriteHeader(http.StatusNoContent)
+		case "/moved":
+			http.Redirect(w, r, "/healthz", http.StatusFound)
+		case "/broken":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	settings := model.Settings{ICMPTimeoutMs: 2000, ICMPPayloadSize: 56}
+
+	tests := []struct {
+		path        string
+		wantSuccess bool
+		wantStatus  int
+		wantCode    string
+	}{
+		{path: "/healthz", wantSuccess: true, wantStatus: http.StatusNoContent},
+		{path: "/moved", wantStatus: http.StatusFound, wantCode: "HTTP Redirect"},
+		{path: "/missing", wantStatus: http.StatusNotFound, wantCode: "HTTP Client Error"},
+		{path: "/broken", wantStatus: http.StatusServiceUnavailable, wantCode: "HTTP Server Error"},
+	}
+	for _, tc := range tests {
+		target := store.ProbeTarget{EndpointID: 3, IP: "127.0.0.1", ProbeMode: model.ProbeModeHTTP, HTTPTarget: server.URL + tc.path}
+		result, canceled := engine.probeTarget(context.Background(), target, settings)
+		if canceled {
+			t.Fatalf("%s: probe unexpectedly canceled", tc.path)
+		}
+		if result.Success != tc.wantSuccess || result.ErrorCode != tc.wantCode {
+			t.Fatalf("%s: success=%v code=%q, want success=%v code=%q", tc.path, result.Success, result.ErrorCode, tc.wantSuccess, tc.wantCode)
+		}
+		if result.HTTPStatus == nil || *result.HTTPStatus != tc.wantStatus {
+			t.Fatalf("%s: http status = %v, want %d", tc.path, result.HTTPStatus, tc.wantStatus)
+		}
+		if result.LatencyMs == nil {
+			t.Fatalf("%s: expected latency to be recorded", tc.path)
+		}
+	}
+}
+
+func TestProbeTargetHTTPConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	target := store.ProbeTarget{EndpointID: 4, IP: "127.0.0.1", ProbeMode: model.ProbeModeHTTP, HTTPTarget: "http://" + addr + "/"}
+	result, _ := engine.probeTarget(context.Background(), target, model.Settings{ICMPTimeoutMs: 2000})
+	if result.Success || result.ErrorCode != "Connection Refused" {
+		t.Fatalf("success=%v code=%q, want Connection Refused", result.Success, result.ErrorCode)
+	}
+	if result.HTTPStatus != nil {
+		t.Fatalf("expected no http status, got %d", *result.HTTPStatus)
+	}
+}
+
+func TestHTTPProbeURL(t *testing.T) {
+	tests := []struct {
+		target store.ProbeTarget
+		want   string
+	}{
+		{target: store.ProbeTarget{IP: "10.0.0.5", ProbeMode: model.ProbeModeHTTP}, want: "http://10.0.0.5/"},
+		{target: store.ProbeTarget{IP: "10.0.0.5", ProbeMode: model.ProbeModeHTTPS, HTTPTarget: "healthz?full=1"}, want: "https://10.0.0.5/healthz?full=1"},
+		{target: store.ProbeTarget{IP: "fd00::5", ProbeMode: model.ProbeModeHTTPS, HTTPTarget: "/status"}, want: "https://[fd00::5]/status"},
+		{target: store.ProbeTarget{IP: "10.0.0.5", ProbeMode: model.ProbeModeHTTP, HTTPTarget: "https://svc.example:8443/ready"}, want: "https://svc.example:8443/ready"},
+	}
+	for _, tc := range tests {
+		got, err := httpProbeURL(tc.target)
+		if err != nil {
+			t.Fatalf("httpProbeURL(%+v) error: %v", tc.target, err)
+		}
+		if got != tc.want {
+			t.Fatalf("httpProbeURL(%+v) = %q, want %q", tc.target, got, tc.want)
+		}
+	}
+}
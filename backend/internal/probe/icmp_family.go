@@ -0,0 +1,55 @@
+package probe
+
+import (
+	"net"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+type icmpFamily struct {
+	name            string
+	protocol        int
+	echoRequest     icmp.Type
+	echoReply       icmp.Type
+	destUnreachable icmp.Type
+	timeExceeded    icmp.Type
+}
+
+var (
+	icmpV4 = icmpFamily{
+		name:            "ipv4",
+		protocol:        ipv4.ICMPTypeEchoReply.Protocol(),
+		echoRequest:     ipv4.ICMPTypeEcho,
+		echoReply:       ipv4.ICMPTypeEchoReply,
+		destUnreachable: ipv4.ICMPTypeDestinationUnreachable,
+		timeExceeded:    ipv4.ICMPTypeTimeExceeded,
+	}
+	icmpV6 = icmpFamily{
+		name:            "ipv6",
+		protocol:        ipv6.ICMPTypeEchoReply.Protocol(),
+		echoRequest:     ipv6.ICMPTypeEchoRequest,
+		echoReply:       ipv6.ICMPTypeEchoReply,
+		destUnreachable: ipv6.ICMPTypeDestinationUnreachable,
+		timeExceeded:    ipv6.ICMPTypeTimeExceeded,
+	}
+)
+
+func icmpFamilyFor(ip net.IP) icmpFamily {
+	if ip.To4() != nil {
+		return icmpV4
+	}
+	return icmpV6
+}
+
+// defaultPacketConn6Factory opens the ICMPv6 socket. The kernel fills in the
+// ICMPv6 checksum on raw sockets, so messages are marshaled without a
+// pseudo-header.
+func defaultPacketConn6Factory() (packetConn, error) {
+	conn, err := icmp.ListenPacket("ip6:ipv6-icmp", "::")
+	if err != nil {
+		return nil, err
+	}
+	return newTTLPacketConn(conn), nil
+}
@@ -0,0 +1,111 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/icmp"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+func TestICMPFamilyFor(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "10.0.0.1", want: "ipv4"},
+		{ip: "::ffff:10.0.0.1", want: "ipv4"},
+		{ip: "::1", want: "ipv6"},
+		{ip: "2001:db8::10", want: "ipv6"},
+	}
+	for _, tc := range tests {
+		if got := icmpFamilyFor(net.ParseIP(tc.ip)).name; got != tc.want {
+			t.Fatalf("icmpFamilyFor(%s) = %s, want %s", tc.ip, got, tc.want)
+		}
+	}
+}
+
+func TestProbeTargetIPv6RoundTripUsesICMPv6Socket(t *testing.T) {
+	conn4 := newFakePacketConn()
+	conn6 := newFakePacketConnFamily(icmpV6)
+	conn6.autoReply = true
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, conn4)
+	engine.mu.Lock()
+	engine.conn6 = conn6
+	engine.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go engine.receiveReplies(ctx, conn6, icmpV6, done)
+	defer func() {
+		cancel()
+		_ = conn6.Close()
+		<-done
+	}()
+
+	settings := model.Settings{ICMPTimeoutMs: 1000, ICMPPayloadSize: 56}
+	result, canceled := engine.probeTarget(ctx, store.ProbeTarget{EndpointID: 6, IP: "2001:db8::10"}, settings)
+	if canceled {
+		t.Fatal("probe unexpectedly canceled")
+	}
+	if !result.Success || result.ReplyIP == nil || *result.ReplyIP != "2001:db8::10" {
+		t.Fatalf("unexpected result: success=%v reply_ip=%v error=%q", result.Success, result.ReplyIP, result.ErrorCode)
+	}
+	if conn4.WriteCount() != 0 || conn6.WriteCount() != 1 {
+		t.Fatalf("writes ipv4=%d ipv6=%d, want 0 and 1", conn4.WriteCount(), conn6.WriteCount())
+	}
+	echo := parseEchoRequestWireFamily(conn6.Writes()[0], icmpV6)
+	if echo.ID != engine.echoID() {
+		t.Fatalf("echo id = %d, want %d", echo.ID, engine.echoID())
+	}
+}
+
+func TestProbeTargetIPv6WithoutSocketFails(t *testing.T) {
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	settings := model.Settings{ICMPTimeoutMs: 100, ICMPPayloadSize: 56}
+
+	result, canceled := engine.probeTarget(context.Background(), store.ProbeTarget{EndpointID: 6, IP: "::1"}, settings)
+	if canceled || result.Success || result.ErrorCode == "" {
+		t.Fatalf("unexpected result without ipv6 socket: %+v canceled=%v", result, canceled)
+	}
+}
+
+func TestProbeTargetIPv6LoopbackRoundTrip(t *testing.T) {
+	conn6, err := icmp.ListenPacket("ip6:ipv6-icmp", "::")
+	if err != nil {
+		t.Skipf("raw ICMPv6 socket unavailable: %v", err)
+	}
+
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	engine.mu.Lock()
+	engine.conn6 = conn6
+	engine.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go engine.receiveReplies(ctx, conn6, icmpV6, done)
+	defer func() {
+		cancel()
+		_ = conn6.Close()
+		<-done
+	}()
+
+	settings := model.Settings{ICMPTimeoutMs: 1000, ICMPPayloadSize: 56}
+	result, canceled := engine.probeTarget(ctx, store.ProbeTarget{EndpointID: 1, IP: "::1"}, settings)
+	if canceled {
+		t.Fatal("probe unexpectedly canceled")
+	}
+	if !result.Success {
+		if result.ErrorCode == "Request Timeout" {
+			t.Skipf("no ICMPv6 echo reply from ::1 within %s", time.Second)
+		}
+		t.Fatalf("loopback probe failed: %+v", result)
+	}
+	if result.ReplyIP == nil || *result.ReplyIP != "::1" {
+		t.Fatalf("reply ip = %v, want ::1", result.ReplyIP)
+	}
+}
@@ -0,0 +1,51 @@
+package probe
+
+import (
+	"crypto/rand"
+	"os"
+)
+
+// Echo replies are matched on the ICMP identifier first, then on the sequence
+// number and the send stamp at the start of the payload. The identifier is
+// the configured instance number (PROBE_ICMP_BASE_ID, 1-255) or a random one
+// in the high byte and a per-socket nonce in the low byte, redrawn whenever
+// the probe sockets are opened. Two processes
+// on one host, or one process restarted with a recycled PID, therefore do not
+// share an identifier, and a reply that still slips through with the wrong
+// stamp is dropped as stale.
+
+// resolveICMPBaseID returns the identifier's high byte, in place, for an
+// instance number of 1-255; anything else draws a random one.
+func resolveICMPBaseID(configured int) int {
+	if configured > 0 && configured <= 0xff {
+		return configured << 8
+	}
+	var raw [1]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return os.Getpid() & 0xff00
+	}
+	return int(raw[0]) << 8
+}
+
+// rotateICMPID draws a new low-byte nonce, different from the current one,
+// and stores the resulting identifier.
+func (e *Engine) rotateICMPID() {
+	previous := e.icmpID.Load()
+	for {
+		var nonce [1]byte
+		if _, err := rand.Read(nonce[:]); err != nil {
+			nonce[0] = byte(previous) + 1
+		}
+		id := int32(e.icmpBaseID&0xff00 | int(nonce[0]))
+		if id != previous {
+			e.icmpID.Store(id)
+			return
+		}
+	}
+}
+
+// echoID is the identifier sent in, and expected on, every echo of the
+// current socket session.
+func (e *Engine) echoID() int {
+	return int(e.icmpID.Load())
+}
@@ -0,0 +1,35 @@
+package probe
+
+import (
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestEchoIDKeepsConfiguredBaseAndRotatesNonce(t *testing.T) {
+	options := defaultTestOptions()
+	options.ICMPBaseID = 0x5a
+	engine := newTestEngine(&fakeProbeStore{}, options, model.Settings{}, newFakePacketConn())
+
+	first := engine.echoID()
+	if first&0xff00 != 0x5a00 {
+		t.Fatalf("echo id = %#04x, want high byte 0x5a", first)
+	}
+	engine.rotateICMPID()
+	second := engine.echoID()
+	if second&0xff00 != 0x5a00 || second == first {
+		t.Fatalf("rotated echo id = %#04x from %#04x, want a new nonce under 0x5a", second, first)
+	}
+}
+
+func TestResolveICMPBaseIDShiftsInstanceNumber(t *testing.T) {
+	if got := resolveICMPBaseID(1); got != 0x0100 {
+		t.Fatalf("resolveICMPBaseID(1) = %#x, want 0x0100", got)
+	}
+	if got := resolveICMPBaseID(2); got != 0x0200 {
+		t.Fatalf("resolveICMPBaseID(2) = %#x, want 0x0200", got)
+	}
+	if got := resolveICMPBaseID(0); got&0xff != 0 || got > 0xff00 {
+		t.Fatalf("random base id %#x is not a high byte", got)
+	}
+}
@@ -0,0 +1,124 @@
+package probe
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+const defaultLogLinesPerSec = 50
+
+// logLimiter caps how many lines a noisy call site may emit per second and
+// reports how many were dropped once the next window opens.
+type logLimiter struct {
+	mu          sync.Mutex
+	limit       int
+	windowStart time.Time
+	count       int
+	suppressed  int64
+	now         func() time.Time
+	logger      *slog.Logger
+}
+
+func newLogLimiter(limitPerSec int) *logLimiter {
+	if limitPerSec < 1 {
+		limitPerSec = defaultLogLinesPerSec
+	}
+	return &logLimiter{
+		limit:  limitPerSec,
+		now:    time.Now,
+		logger: slog.Default(),
+	}
+}
+
+func (l *logLimiter) Log(level slog.Level, msg string, args ...any) bool {
+	l.mu.Lock()
+	now := l.now()
+	suppressed := int64(0)
+	if l.windowStart.IsZero() || now.Sub(l.windowStart) >= time.Second {
+		suppressed = l.suppressed
+		l.windowStart = now
+		l.count = 0
+		l.suppressed = 0
+	}
+	allowed := l.count < l.limit
+	if allowed {
+		l.count++
+	} else {
+		l.suppressed++
+	}
+	l.mu.Unlock()
+
+	if suppressed > 0 {
+		l.logger.Warn("probe log throttled", "suppressed", suppressed)
+	}
+	if allowed {
+		l.logger.Log(context.Background(), level, msg, args...)
+	}
+	return allowed
+}
+
+func (e *Engine) logProbeResult(tracker *roundTracker, target store.ProbeTarget, result model.PingResult) {
+	transition := false
+	if e.logTransitions {
+		e.lastStateMu.Lock()
+		previous, seen := e.lastState[target.EndpointID]
+		e.lastState[target.EndpointID] = result.Success
+		e.lastStateMu.Unlock()
+		transition = seen && previous != result.Success
+	}
+	if transition {
+		tracker.noteTransition()
+	}
+
+	sampled := false
+	if e.logSampleEvery > 0 {
+		sampled = e.logSampleSeq.Add(1)%uint64(e.logSampleEvery) == 0
+	}
+	if !transition && !sampled {
+		return
+	}
+
+	status := "succeeded"
+	if !result.Success {
+		status = "failed"
+	}
+	args := []any{
+		"round_id", tracker.roundID,
+		"endpoint_id", target.EndpointID,
+		"ip", target.IP,
+		"status", status,
+		"error_code", result.ErrorCode,
+		"transition", transition,
+	}
+	if result.LatencyMs != nil {
+		args = append(args, "latency_ms", *result.LatencyMs)
+	}
+	// Transitions get their own budget so a burst of sampled lines cannot
+	// crowd out the up/down changes operators grep for.
+	limiter := e.resultLog
+	if transition {
+		limiter = e.transitionLog
+	}
+	limiter.Log(slog.LevelInfo, "probe result", args...)
+}
+
+// forgetUnownedStates drops the last logged state of endpoints no running
+// schedule probes anymore, so deleted or unscheduled endpoints do not keep an
+// entry for the life of the process.
+func (e *Engine) forgetUnownedStates() {
+	if !e.logTransitions {
+		return
+	}
+	e.lastStateMu.Lock()
+	defer e.lastStateMu.Unlock()
+	for endpointID := range e.lastState {
+		if !e.owners.owns(endpointID) {
+			delete(e.lastState, endpointID)
+		}
+	}
+}
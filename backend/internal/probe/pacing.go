@@ -0,0 +1,36 @@
+package probe
+
+import (
+	"strings"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+const (
+	SendPacingSpread = "spread"
+	SendPacingBurst  = "burst"
+)
+
+func normalizeSendPacing(pacing string) string {
+	if strings.ToLower(strings.TrimSpace(pacing)) == SendPacingBurst {
+		return SendPacingBurst
+	}
+	return SendPacingSpread
+}
+
+// roundSendWindow is the span over which a round's sends are evenly spread.
+// It ends early enough for the last target's echoes and retries to time out
+// before the send guard, so a spread round still fits in the tick. settings must already
+// carry the round's effective timeout.
+func (e *Engine) roundSendWindow(tick time.Duration, settings model.Settings) time.Duration {
+	if e.sendPacing == SendPacingBurst || tick <= 0 {
+		return 0
+	}
+	probeTime := time.Duration(settings.ICMPTimeoutMs*probesPerRound(settings)*(probeRetries(settings)+1)) * time.Millisecond
+	window := tick - roundSendGuard(tick) - probeTime
+	if window <= 0 {
+		return 0
+	}
+	return window * time.Duration(e.sendWindowPct) / 100
+}
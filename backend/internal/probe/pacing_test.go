@@ -0,0 +1,74 @@
+package probe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+func TestRoundSendWindowLeavesRoomForTimeouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		settings model.Settings
+		tick     time.Duration
+		want     time.Duration
+	}{
+		{name: "spread", settings: model.Settings{ICMPTimeoutMs: 200}, tick: time.Second, want: 700 * time.Millisecond},
+		{name: "echoes per round", settings: model.Settings{ICMPTimeoutMs: 100, ProbesPerRound: 3}, tick: time.Second, want: 600 * time.Millisecond},
+		{name: "retries", settings: model.Settings{ICMPTimeoutMs: 100, ProbesPerRound: 2, ProbeRetries: 2}, tick: time.Second, want: 300 * time.Millisecond},
+		{name: "retries fill the tick", settings: model.Settings{ICMPTimeoutMs: 300, ProbeRetries: 2}, tick: time.Second, want: 0},
+		{name: "partial window", options: Options{SendWindowPct: 50}, settings: model.Settings{ICMPTimeoutMs: 200}, tick: time.Second, want: 350 * time.Millisecond},
+		{name: "timeout fills the tick", settings: model.Settings{ICMPTimeoutMs: 900}, tick: time.Second, want: 0},
+		{name: "burst", options: Options{SendPacing: SendPacingBurst}, settings: model.Settings{ICMPTimeoutMs: 200}, tick: time.Second, want: 0},
+		{name: "no tick", settings: model.Settings{ICMPTimeoutMs: 200}, tick: 0, want: 0},
+	}
+	for _, tc := range tests {
+		engine := newEngineWithDeps(&fakeProbeStore{}, nil, tc.options, tc.settings, nil)
+		if got := engine.roundSendWindow(tc.tick, tc.settings); got != tc.want {
+			t.Fatalf("%s: roundSendWindow = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRunRoundBurstPacingSendsAtRoundStart(t *testing.T) {
+	conn := newFakePacketConn()
+	conn.autoReply = true
+	st := &fakeProbeStore{
+		targets: []store.ProbeTarget{
+			{EndpointID: 1, IP: "10.0.0.1"},
+			{EndpointID: 2, IP: "10.0.0.2"},
+			{EndpointID: 3, IP: "10.0.0.3"},
+			{EndpointID: 4, IP: "10.0.0.4"},
+		},
+	}
+
+	options := defaultTestOptions()
+	options.SendPacing = SendPacingBurst
+	engine := newTestEngine(st, options, model.Settings{
+		PingIntervalSec: 1,
+		ICMPPayloadSize: 56,
+		ICMPTimeoutMs:   200,
+	}, conn)
+
+	cancelReceiver, recvDone := startReceiver(t, engine, conn)
+	defer stopReceiver(t, cancelReceiver, conn, recvDone)
+	_, stopResults := startResultPipeline(t, engine)
+	defer stopResults()
+
+	roundStarted := time.Now()
+	tracker := newRoundTracker(1, roundStarted, time.Second)
+	dispatched := engine.runRound(context.Background(), newProbeSchedule("all", nil), 1, roundStarted, tracker, engine.CurrentSettings())
+	tracker.finishProbePhase(dispatched, time.Since(roundStarted), false)
+
+	writeTimes := conn.WriteTimes()
+	if len(writeTimes) != 4 {
+		t.Fatalf("expected 4 writes, got %d", len(writeTimes))
+	}
+	if span := writeTimes[len(writeTimes)-1].Sub(writeTimes[0]); span > 100*time.Millisecond {
+		t.Fatalf("burst pacing should send without spacing, got span %v", span)
+	}
+}
@@ -0,0 +1,166 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+const (
+	MultiAddressPolicyFirst      = "first"
+	MultiAddressPolicyAll        = "all"
+	MultiAddressPolicyRoundRobin = "round_robin"
+)
+
+// hostnameCacheTTL bounds how long a successful lookup is reused across
+// rounds. Failed lookups are not cached.
+const hostnameCacheTTL = 30 * time.Second
+
+var errNoResolvedAddress = errors.New("hostname resolved to no usable address")
+
+type hostnameCacheKey struct {
+	network  string
+	hostname string
+}
+
+type hostnameCacheEntry struct {
+	ips       []net.IP
+	expiresAt time.Time
+}
+
+// lookupHost resolves a hostname through the engine resolver for network
+// ("ip", "ip4" or "ip6"), reusing a cached answer for up to hostnameCacheTTL.
+func (e *Engine) lookupHost(ctx context.Context, network, hostname string) ([]net.IP, error) {
+	key := hostnameCacheKey{network: network, hostname: hostname}
+	now := time.Now()
+	e.hostnameCacheMu.Lock()
+	entry, ok := e.hostnameCache[key]
+	e.hostnameCacheMu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.ips, nil
+	}
+
+	ips, err := e.resolver.LookupIP(ctx, network, hostname)
+	if err != nil {
+		return nil, err
+	}
+	e.hostnameCacheMu.Lock()
+	e.hostnameCache[key] = hostnameCacheEntry{ips: ips, expiresAt: now.Add(hostnameCacheTTL)}
+	e.hostnameCacheMu.Unlock()
+	return ips, nil
+}
+
+type hostResolver interface {
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+func normalizeMultiAddressPolicy(policy string) string {
+	switch strings.ToLower(strings.TrimSpace(policy)) {
+	case MultiAddressPolicyAll:
+		return MultiAddressPolicyAll
+	case MultiAddressPolicyRoundRobin:
+		return MultiAddressPolicyRoundRobin
+	default:
+		return MultiAddressPolicyFirst
+	}
+}
+
+// lookupNetwork picks the address families a hostname target can be probed
+// over. ICMP rounds go out on the engine's shared sockets, so when the IPv6
+// socket could not be opened only A records are asked for; otherwise an
+// AAAA answer sorted first would fail every round while the host is
+// reachable over IPv4.
+func (e *Engine) lookupNetwork(target store.ProbeTarget, settings model.Settings) string {
+	switch effectiveProbeMode(target, settings) {
+	case model.ProbeModeICMP, model.ProbeModeICMPTCP:
+		if e.currentConnFor(icmpV6) == nil {
+			return "ip4"
+		}
+	}
+	return "ip"
+}
+
+// resolveProbeAddresses returns the addresses to probe for a target. Literal
+// IPs are used as-is; hostnames are resolved for network and narrowed by the
+// engine's multi-address policy.
+func (e *Engine) resolveProbeAddresses(ctx context.Context, target store.ProbeTarget, network string) ([]string, bool, error) {
+	if ip := net.ParseIP(strings.TrimSpace(target.IP)); ip != nil {
+		return []string{ip.String()}, false, nil
+	}
+
+	ips, err := e.lookupHost(ctx, network, strings.TrimSpace(target.Hostname))
+	if err != nil {
+		return nil, true, err
+	}
+
+	addresses := make([]string, 0, len(ips))
+	seen := map[string]struct{}{}
+	for _, ip := range ips {
+		address := ip.String()
+		if _, ok := seen[address]; ok {
+			continue
+		}
+		seen[address] = struct{}{}
+		addresses = append(addresses, address)
+	}
+	if len(addresses) == 0 {
+		return nil, true, errNoResolvedAddress
+	}
+
+	switch e.multiAddressPolicy {
+	case MultiAddressPolicyAll:
+		return addresses, true, nil
+	case MultiAddressPolicyRoundRobin:
+		e.roundRobinMu.Lock()
+		next := e.roundRobinNext[target.EndpointID]
+		e.roundRobinNext[target.EndpointID] = next + 1
+		e.roundRobinMu.Unlock()
+		return []string{addresses[next%uint64(len(addresses))]}, true, nil
+	default:
+		return addresses[:1], true, nil
+	}
+}
+
+func (e *Engine) probeResolvedTarget(ctx context.Context, target store.ProbeTarget, settings model.Settings) ([]model.PingResult, bool) {
+	addresses, resolved, err := e.resolveProbeAddresses(ctx, target, e.lookupNetwork(target, settings))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, true
+		}
+		result := model.PingResult{
+			EndpointID:  target.EndpointID,
+			Timestamp:   time.Now().UTC(),
+			ErrorCode:   mapProbeError(err),
+			ErrorDetail: probeErrorDetail(err),
+		}
+		if effectiveProbeMode(target, settings) == model.ProbeModeICMP {
+			result.PayloadBytes = settings.ICMPPayloadSize
+		}
+		return []model.PingResult{result}, false
+	}
+
+	results := make([]model.PingResult, 0, len(addresses))
+	for _, address := range addresses {
+		addressTarget := target
+		addressTarget.IP = address
+		result, canceled := e.probeTarget(ctx, addressTarget, settings)
+		if canceled {
+			return results, true
+		}
+		if resolved {
+			targetIP := address
+			result.TargetIP = &targetIP
+			if result.ReplyIP == nil {
+				replyIP := address
+				result.ReplyIP = &replyIP
+			}
+		}
+		results = append(results, result)
+	}
+	return results, false
+}
@@ -0,0 +1,177 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+type fakeResolver struct {
+	addrs    map[string][]net.IP
+	calls    int
+	networks []string
+}
+
+func (r *fakeResolver) LookupIP(_ context.Context, network string, host string) ([]net.IP, error) {
+	r.calls++
+	r.networks = append(r.networks, network)
+	ips, ok := r.addrs[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return ips, nil
+}
+
+func TestResolveProbeAddressesAppliesPolicy(t *testing.T) {
+	resolver := &fakeResolver{addrs: map[string][]net.IP{
+		"web.lab": {net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.3")},
+	}}
+	target := store.ProbeTarget{EndpointID: 9, Hostname: "web.lab"}
+
+	tests := []struct {
+		policy string
+		want   [][]string
+	}{
+		{policy: MultiAddressPolicyFirst, want: [][]string{{"10.0.0.1"}, {"10.0.0.1"}}},
+		{policy: MultiAddressPolicyAll, want: [][]string{{"10.0.0.1", "10.0.0.2", "10.0.0.3"}}},
+		{policy: MultiAddressPolicyRoundRobin, want: [][]string{{"10.0.0.1"}, {"10.0.0.2"}, {"10.0.0.3"}, {"10.0.0.1"}}},
+	}
+	for _, tc := range tests {
+		options := defaultTestOptions()
+		options.MultiAddressPolicy = tc.policy
+		engine := newTestEngine(&fakeProbeStore{}, options, model.Settings{}, newFakePacketConn())
+		engine.resolver = resolver
+
+		for i, want := range tc.want {
+			got, resolved, err := engine.resolveProbeAddresses(context.Background(), target, "ip")
+			if err != nil {
+				t.Fatalf("%s[%d]: unexpected error: %v", tc.policy, i, err)
+			}
+			if !resolved {
+				t.Fatalf("%s[%d]: expected hostname target to be marked resolved", tc.policy, i)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("%s[%d]: addresses = %v, want %v", tc.policy, i, got, want)
+			}
+		}
+	}
+}
+
+func TestResolveProbeAddressesSkipsLookupForLiteralIP(t *testing.T) {
+	resolver := &fakeResolver{}
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	engine.resolver = resolver
+
+	got, resolved, err := engine.resolveProbeAddresses(context.Background(), store.ProbeTarget{IP: "10.1.1.1", Hostname: "ignored"}, "ip")
+	if err != nil || resolved || !reflect.DeepEqual(got, []string{"10.1.1.1"}) {
+		t.Fatalf("got=%v resolved=%v err=%v", got, resolved, err)
+	}
+	if resolver.calls != 0 {
+		t.Fatalf("resolver called %d times for literal ip", resolver.calls)
+	}
+}
+
+func TestProbeResolvedTargetReportsDNSFailure(t *testing.T) {
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{ICMPPayloadSize: 56}, newFakePacketConn())
+	engine.resolver = &fakeResolver{addrs: map[string][]net.IP{}}
+
+	results, canceled := engine.probeResolvedTarget(context.Background(), store.ProbeTarget{EndpointID: 5, Hostname: "gone.lab"}, model.Settings{ICMPPayloadSize: 56})
+	if canceled || len(results) != 1 {
+		t.Fatalf("results=%v canceled=%v", results, canceled)
+	}
+	if results[0].Success || results[0].ErrorCode != "DNS Failure" || results[0].EndpointID != 5 {
+		t.Fatalf("unexpected dns failure result: %+v", results[0])
+	}
+}
+
+func TestLookupHostCachesSuccessfulAnswers(t *testing.T) {
+	resolver := &fakeResolver{addrs: map[string][]net.IP{"db.lab": {net.ParseIP("10.2.0.1")}}}
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	engine.resolver = resolver
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := engine.resolveProbeAddresses(context.Background(), store.ProbeTarget{EndpointID: 1, Hostname: "db.lab"}, "ip"); err != nil {
+			t.Fatalf("resolve: %v", err)
+		}
+	}
+	if resolver.calls != 1 {
+		t.Fatalf("expected cached answer to be reused, resolver called %d times", resolver.calls)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, _, _ = engine.resolveProbeAddresses(context.Background(), store.ProbeTarget{EndpointID: 2, Hostname: "missing.lab"}, "ip")
+	}
+	if resolver.calls != 3 {
+		t.Fatalf("expected failed lookups to be retried, resolver called %d times", resolver.calls)
+	}
+
+	engine.hostnameCacheMu.Lock()
+	key := hostnameCacheKey{network: "ip", hostname: "db.lab"}
+	entry := engine.hostnameCache[key]
+	entry.expiresAt = time.Now().Add(-time.Second)
+	engine.hostnameCache[key] = entry
+	engine.hostnameCacheMu.Unlock()
+	if _, _, err := engine.resolveProbeAddresses(context.Background(), store.ProbeTarget{EndpointID: 1, Hostname: "db.lab"}, "ip"); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if resolver.calls != 4 {
+		t.Fatalf("expected expired entry to be looked up again, resolver called %d times", resolver.calls)
+	}
+}
+
+func TestProbeResolvedTargetRecordsResolvedAddressAsReplyIP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	engine.resolver = &fakeResolver{addrs: map[string][]net.IP{"app.lab": {net.ParseIP("127.0.0.1")}}}
+	target := store.ProbeTarget{EndpointID: 3, Hostname: "app.lab", ProbeMode: model.ProbeModeTCP, TCPPort: listener.Addr().(*net.TCPAddr).Port}
+	results, _ := engine.probeResolvedTarget(context.Background(), target, model.Settings{ICMPTimeoutMs: 2000})
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[0].ReplyIP == nil || *results[0].ReplyIP != "127.0.0.1" || results[0].TargetIP == nil {
+		t.Fatalf("expected resolved address in reply and target ip, got reply=%v target=%v", results[0].ReplyIP, results[0].TargetIP)
+	}
+}
+
+func TestProbeResolvedTargetAsksOnlyForFamiliesWithASocket(t *testing.T) {
+	resolver := &fakeResolver{addrs: map[string][]net.IP{"dual.lab": {net.ParseIP("10.4.0.1")}}}
+	settings := model.Settings{ICMPTimeoutMs: 20, ICMPPayloadSize: 56}
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), settings, newFakePacketConn())
+	engine.resolver = resolver
+
+	if got := engine.lookupNetwork(store.ProbeTarget{Hostname: "dual.lab"}, settings); got != "ip4" {
+		t.Fatalf("icmp lookup without an ipv6 socket = %q, want ip4", got)
+	}
+	if got := engine.lookupNetwork(store.ProbeTarget{Hostname: "dual.lab", ProbeMode: model.ProbeModeTCP}, settings); got != "ip" {
+		t.Fatalf("tcp lookup = %q, want ip", got)
+	}
+	engine.conn6 = newFakePacketConn()
+	if got := engine.lookupNetwork(store.ProbeTarget{Hostname: "dual.lab"}, settings); got != "ip" {
+		t.Fatalf("icmp lookup with an ipv6 socket = %q, want ip", got)
+	}
+	engine.conn6 = nil
+
+	_, _ = engine.probeResolvedTarget(context.Background(), store.ProbeTarget{EndpointID: 4, Hostname: "dual.lab"}, settings)
+	if !reflect.DeepEqual(resolver.networks, []string{"ip4"}) {
+		t.Fatalf("resolver networks = %v, want [ip4]", resolver.networks)
+	}
+}
@@ -0,0 +1,97 @@
+package probe
+
+import (
+	"math"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+const (
+	maxProbesPerRound = 10
+	maxProbeRetries   = 5
+)
+
+// roundSendGuard is the tail of each tick kept free of sends so the last
+// probes of a round can still be answered before the next tick.
+func roundSendGuard(interval time.Duration) time.Duration {
+	guard := interval / 10
+	if guard > 100*time.Millisecond {
+		guard = 100 * time.Millisecond
+	}
+	return guard
+}
+
+// effectiveTimeoutMs caps the configured timeout so a target's echoes and
+// retries, each waiting the full deadline, end before the send guard of the
+// tick. A 1s tick with a 1000ms timeout probes with 900ms.
+func effectiveTimeoutMs(settings model.Settings, tick time.Duration) int {
+	timeoutMs := settings.ICMPTimeoutMs
+	if tick <= 0 {
+		return timeoutMs
+	}
+	budgetMs := int((tick - roundSendGuard(tick)) / time.Millisecond / time.Duration(probesPerRound(settings)*(probeRetries(settings)+1)))
+	if budgetMs < 1 {
+		budgetMs = 1
+	}
+	if timeoutMs > budgetMs {
+		return budgetMs
+	}
+	return timeoutMs
+}
+
+func probesPerRound(settings model.Settings) int {
+	if settings.ProbesPerRound < 1 {
+		return 1
+	}
+	if settings.ProbesPerRound > maxProbesPerRound {
+		return maxProbesPerRound
+	}
+	return settings.ProbesPerRound
+}
+
+func probeRetries(settings model.Settings) int {
+	if settings.ProbeRetries < 0 {
+		return 0
+	}
+	if settings.ProbeRetries > maxProbeRetries {
+		return maxProbeRetries
+	}
+	return settings.ProbeRetries
+}
+
+// applyEchoRoundStats fills latency fields from the replies received in one
+// round, in arrival order. LatencyMs is the mean reply time. Min, max, and
+// loss are only set for multi-echo rounds, and jitter (mean absolute
+// difference between successive replies) needs at least two replies, so
+// single-echo rows keep their previous shape.
+func applyEchoRoundStats(result *model.PingResult, latencies []float64, sent int) {
+	if sent > 1 {
+		lossPct := float64(sent-len(latencies)) / float64(sent) * 100
+		result.LossPct = &lossPct
+	}
+	if len(latencies) == 0 {
+		return
+	}
+
+	sum, minLatency, maxLatency := 0.0, math.Inf(1), math.Inf(-1)
+	for _, latency := range latencies {
+		sum += latency
+		minLatency = math.Min(minLatency, latency)
+		maxLatency = math.Max(maxLatency, latency)
+	}
+	avg := sum / float64(len(latencies))
+	result.LatencyMs = &avg
+	if sent > 1 {
+		result.MinLatencyMs = &minLatency
+		result.MaxLatencyMs = &maxLatency
+	}
+	if len(latencies) > 1 {
+		diffSum := 0.0
+		for i := 1; i < len(latencies); i++ {
+			diffSum += math.Abs(latencies[i] - latencies[i-1])
+		}
+		jitter := diffSum / float64(len(latencies)-1)
+		result.JitterMs = &jitter
+	}
+}
@@ -0,0 +1,86 @@
+package probe
+
+import (
+	"sync"
+	"time"
+)
+
+// roundStats accumulates one schedule's probe-phase timings for Status. Ticks
+// that found no due targets are not counted, so per-endpoint intervals do not
+// dilute the average.
+type roundStats struct {
+	mu     sync.Mutex
+	counts roundCounts
+}
+
+// roundCounts is a copy of the counters, which Status merges across
+// schedules.
+type roundCounts struct {
+	total         uint64
+	overrun       uint64
+	totalDuration time.Duration
+	lastDuration  time.Duration
+	lastTargets   int
+	lastAt        time.Time
+}
+
+func (s *roundStats) record(targets int, duration time.Duration, overrun bool) {
+	if targets == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts.total++
+	if overrun {
+		s.counts.overrun++
+	}
+	s.counts.totalDuration += duration
+	s.counts.lastDuration = duration
+	s.counts.lastTargets = targets
+	s.counts.lastAt = time.Now()
+}
+
+func (s *roundStats) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts = roundCounts{}
+}
+
+func (s *roundStats) snapshot() roundCounts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts
+}
+
+func (s *roundStats) fill(status *Status) {
+	s.snapshot().fill(status)
+}
+
+// merge adds other's totals to c. The last round is whichever of the two
+// finished most recently.
+func (c roundCounts) merge(other roundCounts) roundCounts {
+	c.total += other.total
+	c.overrun += other.overrun
+	c.totalDuration += other.totalDuration
+	if other.lastAt.After(c.lastAt) {
+		c.lastDuration = other.lastDuration
+		c.lastTargets = other.lastTargets
+		c.lastAt = other.lastAt
+	}
+	return c
+}
+
+func (c roundCounts) avgDuration() time.Duration {
+	if c.total == 0 {
+		return 0
+	}
+	return c.totalDuration / time.Duration(c.total)
+}
+
+func (c roundCounts) fill(status *Status) {
+	status.TotalRounds = c.total
+	status.OverrunRounds = c.overrun
+	status.LastRoundDuration = c.lastDuration
+	status.LastRoundTargets = c.lastTargets
+	status.AvgRoundDuration = c.avgDuration()
+}
@@ -0,0 +1,32 @@
+package probe
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoundStatsSkipsIdleTicks(t *testing.T) {
+	var stats roundStats
+	stats.record(10, 200*time.Millisecond, false)
+	stats.record(0, time.Millisecond, false)
+	stats.record(12, 1200*time.Millisecond, true)
+
+	var status Status
+	stats.fill(&status)
+	if status.TotalRounds != 2 || status.OverrunRounds != 1 {
+		t.Fatalf("rounds = %d total, %d overrun; want 2 and 1", status.TotalRounds, status.OverrunRounds)
+	}
+	if status.LastRoundDuration != 1200*time.Millisecond || status.LastRoundTargets != 12 {
+		t.Fatalf("last round = %s with %d targets", status.LastRoundDuration, status.LastRoundTargets)
+	}
+	if status.AvgRoundDuration != 700*time.Millisecond {
+		t.Fatalf("AvgRoundDuration = %s, want 700ms", status.AvgRoundDuration)
+	}
+
+	stats.reset()
+	status = Status{}
+	stats.fill(&status)
+	if status.TotalRounds != 0 || status.AvgRoundDuration != 0 {
+		t.Fatalf("reset should clear counters, got %+v", status)
+	}
+}
@@ -0,0 +1,143 @@
+package probe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+func TestApplyEchoRoundStats(t *testing.T) {
+	var result model.PingResult
+	applyEchoRoundStats(&result, []float64{10, 14, 12}, 4)
+	if result.LatencyMs == nil || *result.LatencyMs != 12 {
+		t.Fatalf("avg latency = %v, want 12", result.LatencyMs)
+	}
+	if result.MinLatencyMs == nil || *result.MinLatencyMs != 10 || result.MaxLatencyMs == nil || *result.MaxLatencyMs != 14 {
+		t.Fatalf("min/max = %v/%v, want 10/14", result.MinLatencyMs, result.MaxLatencyMs)
+	}
+	// |14-10| and |12-14| averaged.
+	if result.JitterMs == nil || *result.JitterMs != 3 {
+		t.Fatalf("jitter = %v, want 3", result.JitterMs)
+	}
+	if result.LossPct == nil || *result.LossPct != 25 {
+		t.Fatalf("loss = %v, want 25", result.LossPct)
+	}
+}
+
+func TestApplyEchoRoundStatsSingleEchoKeepsLegacyShape(t *testing.T) {
+	var result model.PingResult
+	applyEchoRoundStats(&result, []float64{8}, 1)
+	if result.LatencyMs == nil || *result.LatencyMs != 8 {
+		t.Fatalf("latency = %v, want 8", result.LatencyMs)
+	}
+	if result.MinLatencyMs != nil || result.MaxLatencyMs != nil || result.JitterMs != nil || result.LossPct != nil {
+		t.Fatalf("expected round fields unset for single echo: %+v", result)
+	}
+
+	var lost model.PingResult
+	applyEchoRoundStats(&lost, nil, 3)
+	if lost.LatencyMs != nil || lost.JitterMs != nil || lost.LossPct == nil || *lost.LossPct != 100 {
+		t.Fatalf("unexpected all-lost round: %+v", lost)
+	}
+}
+
+func TestProbeTargetSendsConfiguredEchoesPerRound(t *testing.T) {
+	conn := newFakePacketConn()
+	conn.autoReply = true
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, conn)
+	cancelReceiver, recvDone := startReceiver(t, engine, conn)
+	defer stopReceiver(t, cancelReceiver, conn, recvDone)
+
+	settings := model.Settings{ICMPTimeoutMs: 200, ICMPPayloadSize: 56, ProbesPerRound: 3}
+	result, canceled := engine.probeTarget(context.Background(), store.ProbeTarget{EndpointID: 1, IP: "10.0.0.1"}, settings)
+	if canceled {
+		t.Fatal("probe unexpectedly canceled")
+	}
+	if conn.WriteCount() != 3 {
+		t.Fatalf("writes = %d, want 3", conn.WriteCount())
+	}
+	if !result.Success || result.LossPct == nil || *result.LossPct != 0 {
+		t.Fatalf("unexpected result: success=%v loss=%v error=%q", result.Success, result.LossPct, result.ErrorCode)
+	}
+	if result.JitterMs == nil || result.MinLatencyMs == nil || result.MaxLatencyMs == nil {
+		t.Fatalf("expected round latency stats, got %+v", result)
+	}
+}
+
+func TestProbeTargetRoundWithoutRepliesFails(t *testing.T) {
+	conn := newFakePacketConn()
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, conn)
+
+	settings := model.Settings{ICMPTimeoutMs: 20, ICMPPayloadSize: 56, ProbesPerRound: 2}
+	result, _ := engine.probeTarget(context.Background(), store.ProbeTarget{EndpointID: 1, IP: "10.0.0.1"}, settings)
+	if result.Success || result.ErrorCode != "Request Timeout" {
+		t.Fatalf("success=%v code=%q, want Request Timeout", result.Success, result.ErrorCode)
+	}
+	if result.LossPct == nil || *result.LossPct != 100 || conn.WriteCount() != 2 {
+		t.Fatalf("loss=%v writes=%d, want 100 and 2", result.LossPct, conn.WriteCount())
+	}
+}
+
+func TestProbeTargetRetriesTimedOutRound(t *testing.T) {
+	conn := newFakePacketConn()
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, conn)
+	cancelReceiver, recvDone := startReceiver(t, engine, conn)
+	defer stopReceiver(t, cancelReceiver, conn, recvDone)
+
+	resultCh := make(chan model.PingResult, 1)
+	go func() {
+		settings := model.Settings{ICMPTimeoutMs: 30, ICMPPayloadSize: 56, ProbeRetries: 3}
+		result, _ := engine.probeTarget(context.Background(), store.ProbeTarget{EndpointID: 1, IP: "10.0.0.1"}, settings)
+		resultCh <- result
+	}()
+
+	waitForWriteCount(t, conn, 2, time.Second)
+	echo := parseEchoRequest(t, conn.Writes()[1])
+	if err := conn.InjectEchoReply(engine.echoID(), echo.Seq, "10.0.0.1"); err != nil {
+		t.Fatalf("inject reply: %v", err)
+	}
+
+	select {
+	case result := <-resultCh:
+		if !result.Success || result.Retries != 1 || conn.WriteCount() != 2 {
+			t.Fatalf("success=%v retries=%d writes=%d, want a success after 1 retry", result.Success, result.Retries, conn.WriteCount())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("probe did not complete after the retried echo was answered")
+	}
+}
+
+func TestProbeTargetFailsAfterExhaustingRetries(t *testing.T) {
+	conn := newFakePacketConn()
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, conn)
+
+	settings := model.Settings{ICMPTimeoutMs: 20, ICMPPayloadSize: 56, ProbeRetries: 2}
+	result, _ := engine.probeTarget(context.Background(), store.ProbeTarget{EndpointID: 1, IP: "10.0.0.1"}, settings)
+	if result.Success || result.ErrorCode != "Request Timeout" || result.Retries != 2 || conn.WriteCount() != 3 {
+		t.Fatalf("success=%v code=%q retries=%d writes=%d, want a timeout after 2 retries", result.Success, result.ErrorCode, result.Retries, conn.WriteCount())
+	}
+}
+
+func TestEffectiveTimeoutMsFitsTick(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings model.Settings
+		tick     time.Duration
+		want     int
+	}{
+		{name: "fits", settings: model.Settings{ICMPTimeoutMs: 500}, tick: time.Second, want: 500},
+		{name: "timeout equal to interval", settings: model.Settings{ICMPTimeoutMs: 1000}, tick: time.Second, want: 900},
+		{name: "split across echoes", settings: model.Settings{ICMPTimeoutMs: 300, ProbesPerRound: 4}, tick: time.Second, want: 225},
+		{name: "split across retries", settings: model.Settings{ICMPTimeoutMs: 500, ProbesPerRound: 2, ProbeRetries: 1}, tick: time.Second, want: 225},
+		{name: "long tick", settings: model.Settings{ICMPTimeoutMs: 1000}, tick: 5 * time.Second, want: 1000},
+		{name: "per-endpoint tick shorter than timeout", settings: model.Settings{ICMPTimeoutMs: 1000}, tick: 500 * time.Millisecond, want: 450},
+	}
+	for _, tc := range tests {
+		if got := effectiveTimeoutMs(tc.settings, tc.tick); got != tc.want {
+			t.Fatalf("%s: effectiveTimeoutMs = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+// probeSchedule is one independently ticking probe loop over a scope. The
+// engine runs any number of them on its shared sockets and result workers;
+// each keeps its own next-due times and round counters. An endpoint listed by
+// two schedules is probed only by the one that owns it (see targetOwners).
+type probeSchedule struct {
+	key       string
+	scope     string
+	groupIDs  []int64
+	startedAt time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	nextDueMu        sync.Mutex
+	nextDue          map[int64]time.Time
+	shortestInterval atomic.Int64
+
+	rounds roundStats
+}
+
+// ScheduleStatus describes one running schedule and its own round counters.
+type ScheduleStatus struct {
+	Scope             string
+	GroupIDs          []int64
+	StartedAt         time.Time
+	TotalRounds       uint64
+	OverrunRounds     uint64
+	LastRoundDuration time.Duration
+	LastRoundTargets  int
+	AvgRoundDuration  time.Duration
+}
+
+func newProbeSchedule(scope string, groupIDs []int64) *probeSchedule {
+	ids := uniqueSortedInt64(groupIDs)
+	return &probeSchedule{
+		key:      scheduleKey(scope, ids),
+		scope:    scope,
+		groupIDs: ids,
+		nextDue:  map[int64]time.Time{},
+	}
+}
+
+// scheduleKey identifies a schedule by what it probes, so starting the same
+// group twice does not run two loops.
+func scheduleKey(scope string, groupIDs []int64) string {
+	if scope != "groups" {
+		return scope
+	}
+	parts := make([]string, len(groupIDs))
+	for i, id := range groupIDs {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return fmt.Sprintf("groups:%s", strings.Join(parts, ","))
+}
+
+func uniqueSortedInt64(values []int64) []int64 {
+	out := make([]int64, 0, len(values))
+	seen := make(map[int64]struct{}, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		out = append(out, value)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func (s *probeSchedule) status() ScheduleStatus {
+	counts := s.rounds.snapshot()
+	status := ScheduleStatus{
+		Scope:             s.scope,
+		GroupIDs:          append([]int64{}, s.groupIDs...),
+		StartedAt:         s.startedAt,
+		TotalRounds:       counts.total,
+		OverrunRounds:     counts.overrun,
+		LastRoundDuration: counts.lastDuration,
+		LastRoundTargets:  counts.lastTargets,
+	}
+	status.AvgRoundDuration = counts.avgDuration()
+	return status
+}
+
+// targetOwners hands each endpoint to exactly one schedule. The first
+// schedule to list an endpoint owns it until that schedule stops or no longer
+// lists it, so an endpoint in two scheduled groups (or in a group and the
+// "all" scope) is probed once per interval instead of once per schedule.
+type targetOwners struct {
+	mu    sync.Mutex
+	owner map[int64]*probeSchedule
+}
+
+// claim filters targets down to the ones schedule owns, taking any that no
+// running schedule owns yet, and gives up endpoints schedule stopped listing.
+func (o *targetOwners) claim(schedule *probeSchedule, targets []store.ProbeTarget) []store.ProbeTarget {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.owner == nil {
+		o.owner = map[int64]*probeSchedule{}
+	}
+
+	listed := make(map[int64]struct{}, len(targets))
+	owned := make([]store.ProbeTarget, 0, len(targets))
+	for _, target := range targets {
+		listed[target.EndpointID] = struct{}{}
+		if owner, ok := o.owner[target.EndpointID]; ok && owner != schedule {
+			continue
+		}
+		o.owner[target.EndpointID] = schedule
+		owned = append(owned, target)
+	}
+	for endpointID, owner := range o.owner {
+		if owner != schedule {
+			continue
+		}
+		if _, ok := listed[endpointID]; !ok {
+			delete(o.owner, endpointID)
+		}
+	}
+	return owned
+}
+
+// owns reports whether any running schedule currently owns endpointID.
+func (o *targetOwners) owns(endpointID int64) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, ok := o.owner[endpointID]
+	return ok
+}
+
+// release frees every endpoint schedule owns once it has stopped.
+func (o *targetOwners) release(schedule *probeSchedule) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for endpointID, owner := range o.owner {
+		if owner == schedule {
+			delete(o.owner, endpointID)
+		}
+	}
+}
+
+func (o *targetOwners) reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.owner = nil
+}
+
+// targetInterval is the probe cadence for one target: its own
+// probe_interval_sec override when set, then its scheduled group's interval,
+// otherwise the global interval.
+func targetInterval(target store.ProbeTarget, settings model.Settings) time.Duration {
+	if target.ProbeIntervalSec > 0 {
+		return time.Duration(target.ProbeIntervalSec) * time.Second
+	}
+	if target.GroupIntervalSec > 0 {
+		return time.Duration(target.GroupIntervalSec) * time.Second
+	}
+	return time.Duration(settings.PingIntervalSec) * time.Second
+}
+
+// tickInterval is how often the schedule starts a round: the shortest interval
+// among the targets seen in the last listing, never longer than the global
+// interval.
+func (s *probeSchedule) tickInterval(settings model.Settings) time.Duration {
+	interval := time.Duration(settings.PingIntervalSec) * time.Second
+	if shortest := time.Duration(s.shortestInterval.Load()); shortest > 0 && shortest < interval {
+		return shortest
+	}
+	return interval
+}
+
+// dueTargets filters targets down to the ones whose next-due time has been
+// reached and advances their next-due time by their own interval. Targets are
+// treated as due up to half a tick early so that round jitter does not push a
+// 30s target to 31s when the tick is 1s. It also records the shortest target
+// interval for the next tick and forgets targets that are no longer listed.
+func (s *probeSchedule) dueTargets(targets []store.ProbeTarget, settings model.Settings, roundStarted time.Time, tick time.Duration) []store.ProbeTarget {
+	shortest := time.Duration(0)
+	for _, target := range targets {
+		if interval := targetInterval(target, settings); shortest == 0 || interval < shortest {
+			shortest = interval
+		}
+	}
+	s.shortestInterval.Store(int64(shortest))
+
+	s.nextDueMu.Lock()
+	defer s.nextDueMu.Unlock()
+
+	listed := make(map[int64]struct{}, len(targets))
+	due := make([]store.ProbeTarget, 0, len(targets))
+	for _, target := range targets {
+		listed[target.EndpointID] = struct{}{}
+		next, scheduled := s.nextDue[target.EndpointID]
+		if scheduled && roundStarted.Before(next.Add(-tick/2)) {
+			continue
+		}
+		s.nextDue[target.EndpointID] = roundStarted.Add(targetInterval(target, settings))
+		due = append(due, target)
+	}
+	for endpointID := range s.nextDue {
+		if _, ok := listed[endpointID]; !ok {
+			delete(s.nextDue, endpointID)
+		}
+	}
+	return due
+}
@@ -0,0 +1,205 @@
+package probe
+
+import (
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+func dueEndpointIDs(targets []store.ProbeTarget) []int64 {
+	ids := make([]int64, 0, len(targets))
+	for _, target := range targets {
+		ids = append(ids, target.EndpointID)
+	}
+	return ids
+}
+
+func TestDueTargetsHonorsPerEndpointInterval(t *testing.T) {
+	schedule := newProbeSchedule("all", nil)
+	settings := model.Settings{PingIntervalSec: 5}
+	targets := []store.ProbeTarget{
+		{EndpointID: 1, IP: "10.0.0.1", ProbeIntervalSec: 1},
+		{EndpointID: 2, IP: "10.0.0.2"},
+		{EndpointID: 3, IP: "10.0.0.3", ProbeIntervalSec: 30},
+	}
+
+	start := time.Unix(1_700_000_000, 0)
+	if got := schedule.tickInterval(settings); got != 5*time.Second {
+		t.Fatalf("expected global tick before first listing, got %s", got)
+	}
+	due := schedule.dueTargets(targets, settings, start, 5*time.Second)
+	if len(due) != 3 {
+		t.Fatalf("expected every target due on first round, got %v", dueEndpointIDs(due))
+	}
+
+	tick := schedule.tickInterval(settings)
+	if tick != time.Second {
+		t.Fatalf("expected tick to follow shortest override, got %s", tick)
+	}
+
+	counts := map[int64]int{}
+	for i := 1; i <= 30; i++ {
+		at := start.Add(time.Duration(i)*tick + time.Duration(i%3)*time.Millisecond)
+		for _, target := range schedule.dueTargets(targets, settings, at, tick) {
+			counts[target.EndpointID]++
+		}
+	}
+	if counts[1] != 30 || counts[2] != 6 || counts[3] != 1 {
+		t.Fatalf("unexpected probe counts over 30s: %v", counts)
+	}
+}
+
+func TestDueTargetsForgetsRemovedTargets(t *testing.T) {
+	schedule := newProbeSchedule("all", nil)
+	settings := model.Settings{PingIntervalSec: 10}
+	start := time.Unix(1_700_000_000, 0)
+
+	schedule.dueTargets([]store.ProbeTarget{{EndpointID: 1}, {EndpointID: 2}}, settings, start, 10*time.Second)
+	schedule.dueTargets([]store.ProbeTarget{{EndpointID: 1}}, settings, start.Add(time.Second), 10*time.Second)
+
+	schedule.nextDueMu.Lock()
+	_, kept := schedule.nextDue[2]
+	schedule.nextDueMu.Unlock()
+	if kept {
+		t.Fatalf("expected removed target to be dropped from schedule")
+	}
+
+	due := schedule.dueTargets([]store.ProbeTarget{{EndpointID: 1}, {EndpointID: 2}}, settings, start.Add(2*time.Second), 10*time.Second)
+	if ids := dueEndpointIDs(due); len(ids) != 1 || ids[0] != 2 {
+		t.Fatalf("expected re-added target to be due immediately, got %v", ids)
+	}
+}
+
+func TestTargetIntervalPrefersEndpointThenGroupInterval(t *testing.T) {
+	settings := model.Settings{PingIntervalSec: 5}
+	tests := []struct {
+		target store.ProbeTarget
+		want   time.Duration
+	}{
+		{target: store.ProbeTarget{}, want: 5 * time.Second},
+		{target: store.ProbeTarget{GroupIntervalSec: 60}, want: time.Minute},
+		{target: store.ProbeTarget{ProbeIntervalSec: 2, GroupIntervalSec: 60}, want: 2 * time.Second},
+	}
+	for _, tc := range tests {
+		if got := targetInterval(tc.target, settings); got != tc.want {
+			t.Fatalf("targetInterval(%+v) = %s, want %s", tc.target, got, tc.want)
+		}
+	}
+}
+
+func TestNewProbeScheduleKeysByScope(t *testing.T) {
+	if key := newProbeSchedule("all", nil).key; key != "all" {
+		t.Fatalf("all scope key = %q", key)
+	}
+	schedule := newProbeSchedule("groups", []int64{3, 1, 3})
+	if schedule.key != "groups:1,3" || len(schedule.groupIDs) != 2 {
+		t.Fatalf("groups schedule = %q %v, want groups:1,3", schedule.key, schedule.groupIDs)
+	}
+}
+
+func TestStartGroupAddsIndependentSchedules(t *testing.T) {
+	conn := newFakePacketConn()
+	conn.autoReply = true
+	st := &fakeProbeStore{targets: []store.ProbeTarget{{EndpointID: 1, IP: "10.0.0.1"}}}
+	engine := newTestEngine(st, defaultTestOptions(), model.Settings{
+		PingIntervalSec: 1,
+		ICMPPayloadSize: 56,
+		ICMPTimeoutMs:   100,
+	}, conn)
+	defer engine.Stop()
+
+	if err := engine.StartGroup(2); err != nil {
+		t.Fatalf("StartGroup(2): %v", err)
+	}
+	if err := engine.StartGroup(1); err != nil {
+		t.Fatalf("StartGroup(1): %v", err)
+	}
+	if err := engine.StartGroup(1); err != nil {
+		t.Fatalf("StartGroup(1) again: %v", err)
+	}
+
+	status := engine.Status()
+	if !status.Running || status.Scope != "groups" || len(status.Schedules) != 2 {
+		t.Fatalf("status = %+v, want two group schedules", status)
+	}
+	if len(status.GroupIDs) != 2 || status.GroupIDs[0] != 1 || status.GroupIDs[1] != 2 {
+		t.Fatalf("GroupIDs = %v, want [1 2]", status.GroupIDs)
+	}
+
+	if !engine.StopGroup(2) {
+		t.Fatalf("StopGroup(2) should stop a running schedule")
+	}
+	if engine.StopGroup(2) {
+		t.Fatalf("StopGroup(2) twice should report nothing stopped")
+	}
+	status = engine.Status()
+	if !status.Running || len(status.Schedules) != 1 || status.Schedules[0].GroupIDs[0] != 1 {
+		t.Fatalf("status after StopGroup(2) = %+v", status)
+	}
+
+	if !engine.StopGroup(1) {
+		t.Fatalf("StopGroup(1) should stop the last schedule")
+	}
+	if engine.IsRunning() {
+		t.Fatalf("engine should stop with its last schedule")
+	}
+}
+
+func TestTargetOwnersGiveEachEndpointOneSchedule(t *testing.T) {
+	var owners targetOwners
+	first := newProbeSchedule("groups", []int64{1})
+	second := newProbeSchedule("groups", []int64{2})
+	shared := []store.ProbeTarget{{EndpointID: 1}, {EndpointID: 2}}
+
+	if ids := dueEndpointIDs(owners.claim(first, shared)); len(ids) != 2 {
+		t.Fatalf("first schedule owns %v, want [1 2]", ids)
+	}
+	owned := owners.claim(second, []store.ProbeTarget{{EndpointID: 2}, {EndpointID: 3}})
+	if ids := dueEndpointIDs(owned); len(ids) != 1 || ids[0] != 3 {
+		t.Fatalf("second schedule owns %v, want [3]", ids)
+	}
+
+	// Endpoint 2 leaves the first schedule's listing and moves to the second.
+	owners.claim(first, []store.ProbeTarget{{EndpointID: 1}})
+	owned = owners.claim(second, []store.ProbeTarget{{EndpointID: 2}, {EndpointID: 3}})
+	if ids := dueEndpointIDs(owned); len(ids) != 2 {
+		t.Fatalf("second schedule owns %v after handoff, want [2 3]", ids)
+	}
+
+	owners.release(first)
+	owned = owners.claim(second, []store.ProbeTarget{{EndpointID: 1}, {EndpointID: 2}, {EndpointID: 3}})
+	if ids := dueEndpointIDs(owned); len(ids) != 3 {
+		t.Fatalf("second schedule owns %v after release, want [1 2 3]", ids)
+	}
+}
+
+func TestStatusMergesRoundsAcrossSchedules(t *testing.T) {
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{PingIntervalSec: 1}, newFakePacketConn())
+	first := newProbeSchedule("groups", []int64{1})
+	second := newProbeSchedule("groups", []int64{2})
+	first.rounds.record(4, 100*time.Millisecond, false)
+	first.rounds.record(4, 300*time.Millisecond, true)
+	second.rounds.record(2, 200*time.Millisecond, false)
+	engine.mu.Lock()
+	engine.running = true
+	engine.schedules = map[string]*probeSchedule{first.key: first, second.key: second}
+	engine.mu.Unlock()
+
+	status := engine.Status()
+	if status.TotalRounds != 3 || status.OverrunRounds != 1 || status.AvgRoundDuration != 200*time.Millisecond {
+		t.Fatalf("status rounds = %+v", status)
+	}
+	if status.LastRoundTargets != 2 || status.LastRoundDuration != 200*time.Millisecond {
+		t.Fatalf("last round = %d targets in %s, want the second schedule's", status.LastRoundTargets, status.LastRoundDuration)
+	}
+	for _, schedule := range status.Schedules {
+		if schedule.GroupIDs[0] == 1 && (schedule.TotalRounds != 2 || schedule.OverrunRounds != 1) {
+			t.Fatalf("group 1 schedule rounds = %+v", schedule)
+		}
+		if schedule.GroupIDs[0] == 2 && schedule.TotalRounds != 1 {
+			t.Fatalf("group 2 schedule rounds = %+v", schedule)
+		}
+	}
+}
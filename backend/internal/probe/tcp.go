@@ -0,0 +1,113 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+func (e *Engine) probeTCPTarget(ctx context.Context, target store.ProbeTarget, settings model.Settings) (model.PingResult, bool) {
+	now := time.Now().UTC()
+	latency, err := e.dialTCPProbe(ctx, target.IP, tcpProbePort(target, settings), settings.ICMPTimeoutMs)
+	if err != nil && errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return model.PingResult{}, true
+	}
+
+	result := model.PingResult{
+		EndpointID: target.EndpointID,
+		Timestamp:  now,
+		Success:    err == nil,
+		LatencyMs:  latency,
+	}
+	if err != nil {
+		result.ErrorCode = mapProbeError(err)
+		result.ErrorDetail = probeErrorDetail(err)
+	}
+	return result, false
+}
+
+func tcpProbePort(target store.ProbeTarget, settings model.Settings) int {
+	if target.TCPPort > 0 {
+		return target.TCPPort
+	}
+	if settings.TCPPort > 0 {
+		return settings.TCPPort
+	}
+	return 443
+}
+
+// dialTCPProbe reports the time taken to complete the TCP handshake; the
+// connection is closed immediately without sending any payload. With a
+// source port range configured, the local port is taken from the range and
+// busy ports are skipped.
+func (e *Engine) dialTCPProbe(ctx context.Context, ip string, port, timeoutMs int) (*float64, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+
+	address := net.JoinHostPort(ip, strconv.Itoa(port))
+	attempts := e.tcpSourcePorts.attempts()
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		dialer := e.tcpSourcePorts.dialer()
+		started := time.Now()
+		var conn net.Conn
+		conn, err = dialer.DialContext(dialCtx, "tcp", address)
+		if err == nil {
+			latency := float64(time.Since(started).Microseconds()) / 1000.0
+			_ = conn.Close()
+			return &latency, nil
+		}
+		if !isSourcePortBusy(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// probeICMPWithTCPFallback confirms an ICMP failure with a TCP connect before
+// recording it. Only failures that look like ICMP being dropped or refused on
+// the way (timeouts and Destination Unreachable) fall back; DNS and local
+// socket errors would fail the connect the same way. When the connect
+// succeeds the round counts as up with the connect latency, the ICMP loss and
+// latency spread cleared, and FallbackProbe set so it stays distinguishable
+// from a plain ICMP reply; when it also fails, the ICMP error code is kept.
+func (e *Engine) probeICMPWithTCPFallback(ctx context.Context, target store.ProbeTarget, settings model.Settings) (model.PingResult, bool) {
+	result, canceled := e.probeICMPTarget(ctx, target, settings)
+	if canceled || result.Success || !icmpFallbackEligible(result.ErrorCode) {
+		return result, canceled
+	}
+
+	latency, err := e.dialTCPProbe(ctx, target.IP, tcpProbePort(target, settings), settings.ICMPTimeoutMs)
+	if err != nil && errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return model.PingResult{}, true
+	}
+	result.FallbackProbe = model.ProbeModeTCP
+	if err != nil {
+		return result, false
+	}
+	result.Success = true
+	result.LatencyMs = latency
+	result.MinLatencyMs = nil
+	result.MaxLatencyMs = nil
+	result.JitterMs = nil
+	result.LossPct = nil
+	result.ErrorCode = ""
+	result.ErrorDetail = ""
+	return result, false
+}
+
+// icmpFallbackEligible reports whether an ICMP error code means the echo was
+// blocked or unreachable rather than never sent.
+func icmpFallbackEligible(errorCode string) bool {
+	switch errorCode {
+	case "Request Timeout", "Network Unreachable", "Host Unreachable", "Port Unreachable",
+		"Administratively Prohibited", "Destination Unreachable":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package probe
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"syscall"
+)
+
+// maxSourcePortAttempts bounds how many busy source ports one probe skips
+// before reporting the bind error.
+const maxSourcePortAttempts = 8
+
+// tcpSourcePorts hands out local ports for TCP probes round-robin from an
+// inclusive range. A nil *tcpSourcePorts leaves port selection to the kernel.
+type tcpSourcePorts struct {
+	min  int
+	size uint64
+	next atomic.Uint64
+}
+
+func newTCPSourcePorts(minPort, maxPort int) *tcpSourcePorts {
+	if minPort < 1 || maxPort < minPort || maxPort > 65535 {
+		return nil
+	}
+	return &tcpSourcePorts{min: minPort, size: uint64(maxPort - minPort + 1)}
+}
+
+func (p *tcpSourcePorts) pick() int {
+	return p.min + int((p.next.Add(1)-1)%p.size)
+}
+
+func (p *tcpSourcePorts) attempts() int {
+	if p == nil {
+		return 1
+	}
+	if p.size < maxSourcePortAttempts {
+		return int(p.size)
+	}
+	return maxSourcePortAttempts
+}
+
+func (p *tcpSourcePorts) dialer() *net.Dialer {
+	if p == nil {
+		return &net.Dialer{}
+	}
+	return &net.Dialer{
+		LocalAddr: &net.TCPAddr{Port: p.pick()},
+		Control:   reuseAddrControl,
+	}
+}
+
+// reuseAddrControl sets SO_REUSEADDR so a source port whose previous probe
+// connection is still in TIME_WAIT can be bound again.
+func reuseAddrControl(_, _ string, c syscall.RawConn) error {
+	var sockErr error
+	if err := c.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
+
+func isSourcePortBusy(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE) || errors.Is(err, syscall.EADDRNOTAVAIL)
+}
@@ -0,0 +1,231 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+func TestProbeTargetTCPConnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	target := store.ProbeTarget{EndpointID: 5, IP: "127.0.0.1", ProbeMode: model.ProbeModeTCP, TCPPort: port}
+	result, canceled := engine.probeTarget(context.Background(), target, model.Settings{ICMPTimeoutMs: 2000, ICMPPayloadSize: 56})
+	if canceled {
+		t.Fatalf("probe unexpectedly canceled")
+	}
+	if !result.Success || result.ErrorCode != "" {
+		t.Fatalf("success=%v code=%q, want success", result.Success, result.ErrorCode)
+	}
+	if result.LatencyMs == nil {
+		t.Fatalf("expected connect latency to be recorded")
+	}
+	if result.PayloadBytes != 0 || result.TTL != nil {
+		t.Fatalf("expected no icmp fields, got payload=%d ttl=%v", result.PayloadBytes, result.TTL)
+	}
+}
+
+func TestProbeTargetTCPConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	// Global tcp mode applies to endpoints left on the icmp default.
+	target := store.ProbeTarget{EndpointID: 6, IP: "127.0.0.1", ProbeMode: model.ProbeModeICMP}
+	settings := model.Settings{ICMPTimeoutMs: 2000, ProbeMode: model.ProbeModeTCP, TCPPort: port}
+	result, _ := engine.probeTarget(context.Background(), target, settings)
+	if result.Success || result.ErrorCode != "Connection Refused" {
+		t.Fatalf("success=%v code=%q, want Connection Refused", result.Success, result.ErrorCode)
+	}
+	if result.LatencyMs != nil {
+		t.Fatalf("expected no latency on refused connect, got %v", *result.LatencyMs)
+	}
+}
+
+func TestTCPProbePort(t *testing.T) {
+	tests := []struct {
+		target   store.ProbeTarget
+		settings model.Settings
+		want     int
+	}{
+		{target: store.ProbeTarget{TCPPort: 22}, settings: model.Settings{TCPPort: 443}, want: 22},
+		{target: store.ProbeTarget{}, settings: model.Settings{TCPPort: 8443}, want: 8443},
+		{target: store.ProbeTarget{}, settings: model.Settings{}, want: 443},
+	}
+	for _, tc := range tests {
+		if got := tcpProbePort(tc.target, tc.settings); got != tc.want {
+			t.Fatalf("tcpProbePort(%d, %d) = %d, want %d", tc.target.TCPPort, tc.settings.TCPPort, got, tc.want)
+		}
+	}
+}
+
+func TestEffectiveProbeMode(t *testing.T) {
+	tests := []struct {
+		endpointMode string
+		globalMode   string
+		want         string
+	}{
+		{endpointMode: model.ProbeModeICMP, globalMode: model.ProbeModeICMP, want: model.ProbeModeICMP},
+		{endpointMode: "", globalMode: "", want: model.ProbeModeICMP},
+		{endpointMode: model.ProbeModeICMP, globalMode: model.ProbeModeTCP, want: model.ProbeModeTCP},
+		{endpointMode: model.ProbeModeHTTPS, globalMode: model.ProbeModeTCP, want: model.ProbeModeHTTPS},
+		{endpointMode: model.ProbeModeTCP, globalMode: model.ProbeModeICMP, want: model.ProbeModeTCP},
+		{endpointMode: model.ProbeModeICMPTCP, globalMode: model.ProbeModeTCP, want: model.ProbeModeICMPTCP},
+	}
+	for _, tc := range tests {
+		got := effectiveProbeMode(store.ProbeTarget{ProbeMode: tc.endpointMode}, model.Settings{ProbeMode: tc.globalMode})
+		if got != tc.want {
+			t.Fatalf("effectiveProbeMode(%q, %q) = %q, want %q", tc.endpointMode, tc.globalMode, got, tc.want)
+		}
+	}
+}
+
+func TestProbeTargetICMPFallbackToTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	// The fake packet conn never replies, so every echo times out.
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	target := store.ProbeTarget{EndpointID: 7, IP: "127.0.0.1", ProbeMode: model.ProbeModeICMPTCP, TCPPort: port}
+	settings := model.Settings{ICMPTimeoutMs: 50, ICMPPayloadSize: 56, ProbesPerRound: 2}
+	result, canceled := engine.probeTarget(context.Background(), target, settings)
+	if canceled {
+		t.Fatalf("probe unexpectedly canceled")
+	}
+	if !result.Success || result.ErrorCode != "" || result.ErrorDetail != "" || result.FallbackProbe != model.ProbeModeTCP {
+		t.Fatalf("success=%v code=%q fallback=%q, want tcp-confirmed success", result.Success, result.ErrorCode, result.FallbackProbe)
+	}
+	if result.LatencyMs == nil {
+		t.Fatalf("expected connect latency to be recorded")
+	}
+	if result.LossPct != nil || result.MinLatencyMs != nil || result.MaxLatencyMs != nil || result.JitterMs != nil {
+		t.Fatalf("expected icmp loss and spread to be cleared, got loss=%v min=%v max=%v jitter=%v", result.LossPct, result.MinLatencyMs, result.MaxLatencyMs, result.JitterMs)
+	}
+
+	_ = listener.Close()
+	result, _ = engine.probeTarget(context.Background(), target, settings)
+	if result.Success || result.ErrorCode != "Request Timeout" || result.FallbackProbe != model.ProbeModeTCP {
+		t.Fatalf("success=%v code=%q fallback=%q, want icmp timeout after failed fallback", result.Success, result.ErrorCode, result.FallbackProbe)
+	}
+}
+
+func TestProbeTargetICMPFallbackSkipsLocalFailures(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	engine.mu.Lock()
+	engine.conn = nil
+	engine.mu.Unlock()
+	target := store.ProbeTarget{EndpointID: 7, IP: "127.0.0.1", ProbeMode: model.ProbeModeICMPTCP, TCPPort: port}
+	result, _ := engine.probeTarget(context.Background(), target, model.Settings{ICMPTimeoutMs: 50, ICMPPayloadSize: 56})
+	if result.Success || result.ErrorCode != "Probe Error" || result.FallbackProbe != "" {
+		t.Fatalf("success=%v code=%q fallback=%q, want the socket error without a fallback", result.Success, result.ErrorCode, result.FallbackProbe)
+	}
+}
+
+func TestICMPFallbackEligible(t *testing.T) {
+	for _, code := range []string{"Request Timeout", "Host Unreachable", "Administratively Prohibited"} {
+		if !icmpFallbackEligible(code) {
+			t.Fatalf("expected %q to fall back", code)
+		}
+	}
+	for _, code := range []string{"", "DNS Failure", "Permission Denied", "Probe Error"} {
+		if icmpFallbackEligible(code) {
+			t.Fatalf("expected %q not to fall back", code)
+		}
+	}
+}
+
+func TestTCPSourcePortsCycleThroughRange(t *testing.T) {
+	if newTCPSourcePorts(0, 0) != nil {
+		t.Fatalf("expected disabled range to return nil")
+	}
+	ports := newTCPSourcePorts(40000, 40002)
+	got := []int{ports.pick(), ports.pick(), ports.pick(), ports.pick()}
+	want := []int{40000, 40001, 40002, 40000}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pick sequence = %v, want %v", got, want)
+		}
+	}
+	if ports.attempts() != 3 {
+		t.Fatalf("expected attempts capped by range size, got %d", ports.attempts())
+	}
+}
+
+func TestProbeTargetTCPBindsSourcePortFromRange(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	remotePorts := make(chan int, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			remotePorts <- conn.RemoteAddr().(*net.TCPAddr).Port
+			_ = conn.Close()
+		}
+	}()
+
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("reserve source port: %v", err)
+	}
+	sourcePort := probe.Addr().(*net.TCPAddr).Port
+	_ = probe.Close()
+
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	engine.tcpSourcePorts = newTCPSourcePorts(sourcePort, sourcePort)
+	target := store.ProbeTarget{EndpointID: 8, IP: "127.0.0.1", ProbeMode: model.ProbeModeTCP, TCPPort: listener.Addr().(*net.TCPAddr).Port}
+	result, _ := engine.probeTarget(context.Background(), target, model.Settings{ICMPTimeoutMs: 2000})
+	if !result.Success {
+		t.Fatalf("expected connect to succeed, got code=%q", result.ErrorCode)
+	}
+	if got := <-remotePorts; got != sourcePort {
+		t.Fatalf("connection came from port %d, want %d", got, sourcePort)
+	}
+}
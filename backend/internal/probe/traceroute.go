@@ -0,0 +1,243 @@
+package probe
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/net/icmp"
+
+	"sonarscope/backend/internal/store"
+)
+
+const (
+	TracerouteDefaultMaxHops    = 30
+	TracerouteMaxHopsLimit      = 64
+	TracerouteDefaultHopTimeout = time.Second
+	TracerouteMaxHopTimeout     = 5 * time.Second
+	// tracerouteMaxDuration bounds a whole run so it finishes inside the
+	// default API request timeout; hops past it are not sent.
+	tracerouteMaxDuration   = 20 * time.Second
+	tracerouteMaxConcurrent = 4
+	traceroutePayloadSize   = 32
+)
+
+var ErrTracerouteBusy = errors.New("too many traceroutes are running")
+
+type TracerouteOptions struct {
+	MaxHops    int
+	HopTimeout time.Duration
+}
+
+type TracerouteHop struct {
+	TTL       int      `json:"ttl"`
+	IP        *string  `json:"ip"`
+	RTTMs     *float64 `json:"rtt_ms"`
+	ErrorCode string   `json:"error_code,omitempty"`
+}
+
+type TracerouteResult struct {
+	EndpointID int64           `json:"endpoint_id"`
+	TargetIP   string          `json:"target_ip"`
+	Reached    bool            `json:"reached"`
+	Truncated  bool            `json:"truncated"`
+	StartedAt  time.Time       `json:"started_at"`
+	DurationMs float64         `json:"duration_ms"`
+	Hops       []TracerouteHop `json:"hops"`
+}
+
+// tracerouteConn is an ICMP socket whose outgoing TTL / hop limit can be
+// changed between sends.
+type tracerouteConn interface {
+	packetConn
+	SetHopLimit(hops int) error
+}
+
+type tracerouteConnFactory func(family icmpFamily) (tracerouteConn, error)
+
+type hopLimitPacketConn struct {
+	*icmp.PacketConn
+}
+
+func (c hopLimitPacketConn) SetHopLimit(hops int) error {
+	if p4 := c.IPv4PacketConn(); p4 != nil {
+		return p4.SetTTL(hops)
+	}
+	return c.IPv6PacketConn().SetHopLimit(hops)
+}
+
+func defaultTracerouteConnFactory(family icmpFamily) (tracerouteConn, error) {
+	network, address := "ip4:icmp", "0.0.0.0"
+	if family.name == icmpV6.name {
+		network, address = "ip6:ipv6-icmp", "::"
+	}
+	conn, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return hopLimitPacketConn{PacketConn: conn}, nil
+}
+
+// Traceroute sends one echo per TTL from 1 up to MaxHops on its own socket,
+// outside the probe rounds, and stops at the first echo reply or Destination
+// Unreachable. Hops are matched on a per-run identifier and the TTL carried
+// as the sequence number. Results are not persisted.
+func (e *Engine) Traceroute(ctx context.Context, target store.ProbeTarget, options TracerouteOptions) (TracerouteResult, error) {
+	select {
+	case e.tracerouteSlots <- struct{}{}:
+		defer func() { <-e.tracerouteSlots }()
+	default:
+		return TracerouteResult{}, ErrTracerouteBusy
+	}
+
+	maxHops := options.MaxHops
+	if maxHops < 1 || maxHops > TracerouteMaxHopsLimit {
+		maxHops = TracerouteDefaultMaxHops
+	}
+	hopTimeout := options.HopTimeout
+	if hopTimeout <= 0 || hopTimeout > TracerouteMaxHopTimeout {
+		hopTimeout = TracerouteDefaultHopTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tracerouteMaxDuration)
+	defer cancel()
+
+	addresses, _, err := e.resolveProbeAddresses(ctx, target, "ip")
+	if err != nil {
+		return TracerouteResult{}, err
+	}
+	targetIP := net.ParseIP(addresses[0])
+	family := icmpFamilyFor(targetIP)
+	conn, err := e.tracerouteConnFactory(family)
+	if err != nil {
+		return TracerouteResult{}, fmt.Errorf("open traceroute socket: %w", err)
+	}
+	defer conn.Close()
+
+	result := TracerouteResult{
+		EndpointID: target.EndpointID,
+		TargetIP:   targetIP.String(),
+		StartedAt:  time.Now().UTC(),
+		Hops:       []TracerouteHop{},
+	}
+	id := e.newTracerouteID()
+	for ttl := 1; ttl <= maxHops; ttl++ {
+		if ctx.Err() != nil {
+			result.Truncated = true
+			break
+		}
+		hop, done, err := sendTracerouteHop(ctx, conn, family, targetIP, id, ttl, hopTimeout)
+		if err != nil {
+			return TracerouteResult{}, err
+		}
+		result.Hops = append(result.Hops, hop)
+		if done {
+			result.Reached = hop.ErrorCode == ""
+			break
+		}
+	}
+	result.DurationMs = float64(time.Since(result.StartedAt).Microseconds()) / 1000
+	return result, nil
+}
+
+// sendTracerouteHop sends the echo for ttl and waits up to hopTimeout for the
+// Time Exceeded, Destination Unreachable, or echo reply that answers it. done
+// reports that the path ended at this hop.
+func sendTracerouteHop(ctx context.Context, conn tracerouteConn, family icmpFamily, targetIP net.IP, id, ttl int, hopTimeout time.Duration) (TracerouteHop, bool, error) {
+	hop := TracerouteHop{TTL: ttl}
+	if err := conn.SetHopLimit(ttl); err != nil {
+		return hop, false, fmt.Errorf("set traceroute hop limit: %w", err)
+	}
+	wire, err := (&icmp.Message{
+		Type: family.echoRequest,
+		Body: &icmp.Echo{ID: id, Seq: ttl, Data: make([]byte, traceroutePayloadSize)},
+	}).Marshal(nil)
+	if err != nil {
+		return hop, false, err
+	}
+
+	sentAt := time.Now()
+	deadline := sentAt.Add(hopTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return hop, false, err
+	}
+	if _, err := conn.WriteTo(wire, &net.IPAddr{IP: targetIP}); err != nil {
+		return hop, false, fmt.Errorf("send traceroute probe: %w", err)
+	}
+
+	buffer := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buffer)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				hop.ErrorCode = "Request Timeout"
+				return hop, false, nil
+			}
+			return hop, false, err
+		}
+		parsed, err := icmp.ParseMessage(family.protocol, buffer[:n])
+		if err != nil {
+			continue
+		}
+
+		done := false
+		switch parsed.Type {
+		case family.echoReply:
+			echo, ok := parsed.Body.(*icmp.Echo)
+			if !ok || echo.ID != id || echo.Seq != ttl {
+				continue
+			}
+			done = true
+		case family.timeExceeded:
+			body, ok := parsed.Body.(*icmp.TimeExceeded)
+			if !ok || !quotesTracerouteProbe(family, body.Data, id, ttl) {
+				continue
+			}
+		case family.destUnreachable:
+			body, ok := parsed.Body.(*icmp.DstUnreach)
+			if !ok || !quotesTracerouteProbe(family, body.Data, id, ttl) {
+				continue
+			}
+			hop.ErrorCode = unreachableReason(family, parsed.Code)
+			done = true
+		default:
+			continue
+		}
+
+		rtt := time.Since(sentAt).Seconds() * 1000
+		hop.RTTMs = &rtt
+		if ipAddr, ok := peer.(*net.IPAddr); ok && ipAddr.IP != nil {
+			from := ipAddr.IP.String()
+			hop.IP = &from
+		}
+		return hop, done, nil
+	}
+}
+
+func quotesTracerouteProbe(family icmpFamily, data []byte, id, ttl int) bool {
+	quotedID, quotedSeq, _, ok := quotedEcho(family, data)
+	return ok && quotedID == id && quotedSeq == ttl
+}
+
+// newTracerouteID picks a random identifier for one run that differs from the
+// probe rounds' echo identifier, so their receiver never mistakes the final
+// echo reply for one of its own.
+func (e *Engine) newTracerouteID() int {
+	for {
+		var raw [2]byte
+		if _, err := rand.Read(raw[:]); err != nil {
+			raw[0], raw[1] = byte(e.echoID()>>8), byte(e.echoID())+1
+		}
+		if id := int(binary.BigEndian.Uint16(raw[:])); id != e.echoID() {
+			return id
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+// fakeTracerouteConn answers each echo from a scripted path: hops below the
+// target reply with Time Exceeded, silent hops never answer, and the target
+// replies with an echo reply.
+type fakeTracerouteConn struct {
+	mu       sync.Mutex
+	hops     int
+	path     map[int]string
+	targetAt int
+	deadline time.Time
+	replies  chan fakeRead
+	closed   bool
+}
+
+func newFakeTracerouteConn(path map[int]string, targetAt int) *fakeTracerouteConn {
+	return &fakeTracerouteConn{path: path, targetAt: targetAt, replies: make(chan fakeRead, 4)}
+}
+
+func (c *fakeTracerouteConn) SetHopLimit(hops int) error {
+	c.mu.Lock()
+	c.hops = hops
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeTracerouteConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.deadline = t
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeTracerouteConn) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeTracerouteConn) WriteTo(b []byte, dst net.Addr) (int, error) {
+	c.mu.Lock()
+	hops := c.hops
+	c.mu.Unlock()
+
+	request := parseEchoRequestWire(b)
+	var msg icmp.Message
+	var from string
+	switch {
+	case hops == c.targetAt:
+		from = dst.(*net.IPAddr).IP.String()
+		msg = icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: request.ID, Seq: request.Seq, Data: request.Data}}
+	case c.path[hops] != "":
+		from = c.path[hops]
+		header := make([]byte, ipv4.HeaderLen)
+		header[0] = 0x45
+		header[9] = byte(icmpV4.protocol)
+		msg = icmp.Message{Type: ipv4.ICMPTypeTimeExceeded, Body: &icmp.TimeExceeded{Data: append(header, b...)}}
+	default:
+		return len(b), nil
+	}
+	wire, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+	c.replies <- fakeRead{payload: wire, peer: &net.IPAddr{IP: net.ParseIP(from)}}
+	return len(b), nil
+}
+
+func (c *fakeTracerouteConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	c.mu.Lock()
+	wait := time.Until(c.deadline)
+	c.mu.Unlock()
+	select {
+	case read := <-c.replies:
+		return copy(b, read.payload), read.peer, nil
+	case <-time.After(wait):
+		return 0, nil, timeoutError{}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestTracerouteReportsHopsUntilTarget(t *testing.T) {
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	conn := newFakeTracerouteConn(map[int]string{1: "192.0.2.1", 3: "192.0.2.3"}, 4)
+	engine.tracerouteConnFactory = func(icmpFamily) (tracerouteConn, error) { return conn, nil }
+
+	result, err := engine.Traceroute(context.Background(), store.ProbeTarget{EndpointID: 9, IP: "10.0.0.9"}, TracerouteOptions{HopTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("traceroute: %v", err)
+	}
+	if !result.Reached || result.Truncated || result.TargetIP != "10.0.0.9" || len(result.Hops) != 4 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if hop := result.Hops[0]; hop.IP == nil || *hop.IP != "192.0.2.1" || hop.RTTMs == nil {
+		t.Fatalf("hop 1 = %+v, want 192.0.2.1 with an rtt", hop)
+	}
+	if hop := result.Hops[1]; hop.IP != nil || hop.ErrorCode != "Request Timeout" {
+		t.Fatalf("hop 2 = %+v, want a timeout", hop)
+	}
+	if hop := result.Hops[3]; hop.TTL != 4 || hop.IP == nil || *hop.IP != "10.0.0.9" {
+		t.Fatalf("hop 4 = %+v, want the target", hop)
+	}
+	if !conn.closed {
+		t.Fatal("expected traceroute socket to be closed")
+	}
+}
+
+func TestTracerouteStopsAtMaxHops(t *testing.T) {
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	engine.tracerouteConnFactory = func(icmpFamily) (tracerouteConn, error) {
+		return newFakeTracerouteConn(map[int]string{1: "192.0.2.1", 2: "192.0.2.2", 3: "192.0.2.3"}, 10), nil
+	}
+
+	result, err := engine.Traceroute(context.Background(), store.ProbeTarget{EndpointID: 9, IP: "10.0.0.9"}, TracerouteOptions{MaxHops: 3})
+	if err != nil {
+		t.Fatalf("traceroute: %v", err)
+	}
+	if result.Reached || len(result.Hops) != 3 {
+		t.Fatalf("expected 3 unreached hops, got %+v", result)
+	}
+}
+
+func TestTracerouteRejectsWhenAllSlotsAreBusy(t *testing.T) {
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+	for i := 0; i < tracerouteMaxConcurrent; i++ {
+		engine.tracerouteSlots <- struct{}{}
+	}
+	if _, err := engine.Traceroute(context.Background(), store.ProbeTarget{IP: "10.0.0.9"}, TracerouteOptions{}); err != ErrTracerouteBusy {
+		t.Fatalf("err = %v, want ErrTracerouteBusy", err)
+	}
+}
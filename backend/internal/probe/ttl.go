@@ -0,0 +1,63 @@
+package probe
+
+import (
+	"log/slog"
+	"net"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// ttlReader is implemented by sockets that can report the IPv4 TTL or IPv6
+// hop limit of each received packet.
+type ttlReader interface {
+	ReadFromTTL(b []byte) (int, net.Addr, *int, error)
+}
+
+type ttlPacketConn struct {
+	*icmp.PacketConn
+}
+
+// newTTLPacketConn enables TTL / hop-limit control messages on conn. If the
+// platform refuses, conn is returned as-is and replies carry no TTL.
+func newTTLPacketConn(conn *icmp.PacketConn) packetConn {
+	var err error
+	if p4 := conn.IPv4PacketConn(); p4 != nil {
+		err = p4.SetControlMessage(ipv4.FlagTTL, true)
+	} else if p6 := conn.IPv6PacketConn(); p6 != nil {
+		err = p6.SetControlMessage(ipv6.FlagHopLimit, true)
+	} else {
+		return conn
+	}
+	if err != nil {
+		slog.Warn("probe reply ttl unavailable", "error", err)
+		return conn
+	}
+	return ttlPacketConn{PacketConn: conn}
+}
+
+func (c ttlPacketConn) ReadFromTTL(b []byte) (int, net.Addr, *int, error) {
+	if p4 := c.IPv4PacketConn(); p4 != nil {
+		n, cm, peer, err := p4.ReadFrom(b)
+		if err != nil || cm == nil {
+			return n, peer, nil, err
+		}
+		ttl := cm.TTL
+		return n, peer, &ttl, nil
+	}
+	n, cm, peer, err := c.IPv6PacketConn().ReadFrom(b)
+	if err != nil || cm == nil {
+		return n, peer, nil, err
+	}
+	hopLimit := cm.HopLimit
+	return n, peer, &hopLimit, nil
+}
+
+func readFromWithTTL(conn packetConn, b []byte) (int, net.Addr, *int, error) {
+	if reader, ok := conn.(ttlReader); ok {
+		return reader.ReadFromTTL(b)
+	}
+	n, peer, err := conn.ReadFrom(b)
+	return n, peer, nil, err
+}
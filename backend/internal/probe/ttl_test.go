@@ -0,0 +1,85 @@
+package probe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+	"sonarscope/backend/internal/store"
+)
+
+func TestProbeTargetLoopbackReportsTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		ip      string
+		family  icmpFamily
+		factory packetConnFactory
+	}{
+		{name: "ipv4", ip: "127.0.0.1", family: icmpV4, factory: defaultPacketConnFactory},
+		{name: "ipv6", ip: "::1", family: icmpV6, factory: defaultPacketConn6Factory},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, err := tc.factory()
+			if err != nil {
+				t.Skipf("raw %s icmp socket unavailable: %v", tc.name, err)
+			}
+			if _, ok := conn.(ttlReader); !ok {
+				_ = conn.Close()
+				t.Skipf("%s socket does not report ttl on this platform", tc.name)
+			}
+
+			engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{}, newFakePacketConn())
+			engine.mu.Lock()
+			if tc.family.name == icmpV4.name {
+				engine.conn = conn
+			} else {
+				engine.conn6 = conn
+			}
+			engine.mu.Unlock()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go engine.receiveReplies(ctx, conn, tc.family, done)
+			defer func() {
+				cancel()
+				_ = conn.Close()
+				<-done
+			}()
+
+			settings := model.Settings{ICMPTimeoutMs: 1000, ICMPPayloadSize: 56}
+			result, canceled := engine.probeTarget(ctx, store.ProbeTarget{EndpointID: 1, IP: tc.ip}, settings)
+			if canceled {
+				t.Fatal("probe unexpectedly canceled")
+			}
+			if !result.Success {
+				if result.ErrorCode == "Request Timeout" {
+					t.Skipf("no echo reply from %s within %s", tc.ip, time.Second)
+				}
+				t.Fatalf("loopback probe failed: %+v", result)
+			}
+			if result.ReplyIP == nil || *result.ReplyIP != tc.ip {
+				t.Fatalf("reply ip = %v, want %s", result.ReplyIP, tc.ip)
+			}
+			if result.TTL == nil || *result.TTL < 1 || *result.TTL > 255 {
+				t.Fatalf("ttl = %v, want value in 1..255", result.TTL)
+			}
+		})
+	}
+}
+
+func TestReadFromWithTTLFallsBackWithoutControlMessages(t *testing.T) {
+	conn := newFakePacketConn()
+	if err := conn.InjectEchoReply(1, 1, "127.0.0.1"); err != nil {
+		t.Fatalf("inject reply: %v", err)
+	}
+	buffer := make([]byte, 1500)
+	n, _, ttl, err := readFromWithTTL(conn, buffer)
+	if err != nil || n == 0 {
+		t.Fatalf("read = %d, %v", n, err)
+	}
+	if ttl != nil {
+		t.Fatalf("ttl = %d, want nil for sockets without control messages", *ttl)
+	}
+}
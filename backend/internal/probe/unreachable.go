@@ -0,0 +1,94 @@
+package probe
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// destinationUnreachableError is returned by sendICMPEcho when a router
+// answers the echo request with an ICMP Destination Unreachable instead of
+// letting it time out. Reason is the error_code stored for the sample.
+type destinationUnreachableError struct {
+	family string
+	code   int
+	reason string
+	from   string
+}
+
+func (e *destinationUnreachableError) Error() string {
+	return fmt.Sprintf("icmp destination unreachable from %s (%s code %d): %s", e.from, e.family, e.code, e.reason)
+}
+
+// unreachableReason maps a Destination Unreachable code to the error code
+// surfaced in the monitor grid. ICMPv4 codes follow RFC 792/1812 and ICMPv6
+// codes follow RFC 4443.
+func unreachableReason(family icmpFamily, code int) string {
+	if family.name == icmpV6.name {
+		switch code {
+		case 0:
+			return "Network Unreachable"
+		case 1, 5, 6:
+			return "Administratively Prohibited"
+		case 3:
+			return "Host Unreachable"
+		case 4:
+			return "Port Unreachable"
+		}
+		return "Destination Unreachable"
+	}
+
+	switch code {
+	case 0, 6, 11:
+		return "Network Unreachable"
+	case 1, 7, 12:
+		return "Host Unreachable"
+	case 3:
+		return "Port Unreachable"
+	case 9, 10, 13:
+		return "Administratively Prohibited"
+	}
+	return "Destination Unreachable"
+}
+
+// quotedEcho extracts the echo ID, sequence and payload from the original
+// packet quoted in a Destination Unreachable body. ok is false when the
+// quoted packet is not one of our echo requests or is truncated before the
+// echo header.
+func quotedEcho(family icmpFamily, data []byte) (id int, seq int, payload []byte, ok bool) {
+	var inner []byte
+	if family.name == icmpV6.name {
+		const ipv6HeaderLen = 40
+		if len(data) < ipv6HeaderLen || data[6] != byte(icmpV6.protocol) {
+			return 0, 0, nil, false
+		}
+		inner = data[ipv6HeaderLen:]
+	} else {
+		if len(data) < 20 || data[0]>>4 != 4 {
+			return 0, 0, nil, false
+		}
+		headerLen := int(data[0]&0x0f) * 4
+		if headerLen < 20 || len(data) < headerLen || data[9] != byte(icmpV4.protocol) {
+			return 0, 0, nil, false
+		}
+		inner = data[headerLen:]
+	}
+
+	if len(inner) < 8 || inner[0] != icmpTypeCode(family.echoRequest) {
+		return 0, 0, nil, false
+	}
+	return int(binary.BigEndian.Uint16(inner[4:6])), int(binary.BigEndian.Uint16(inner[6:8])), inner[8:], true
+}
+
+func icmpTypeCode(typ icmp.Type) byte {
+	switch t := typ.(type) {
+	case ipv4.ICMPType:
+		return byte(t)
+	case ipv6.ICMPType:
+		return byte(t)
+	}
+	return 0
+}
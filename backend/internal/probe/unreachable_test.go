@@ -0,0 +1,142 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+
+	"sonarscope/backend/internal/model"
+)
+
+// injectDestinationUnreachable quotes the echo request wire behind a minimal
+// IPv4 header, the way a router builds a Destination Unreachable.
+func injectDestinationUnreachable(t *testing.T, conn *fakePacketConn, code int, routerIP string, request []byte) {
+	t.Helper()
+	header := make([]byte, ipv4.HeaderLen)
+	header[0] = 0x45
+	header[9] = byte(icmpV4.protocol)
+	msg := icmp.Message{
+		Type: ipv4.ICMPTypeDestinationUnreachable,
+		Code: code,
+		Body: &icmp.DstUnreach{Data: append(header, request...)},
+	}
+	wire, err := msg.Marshal(nil)
+	if err != nil {
+		t.Fatalf("marshal destination unreachable: %v", err)
+	}
+	conn.readCh <- fakeRead{payload: wire, peer: &net.IPAddr{IP: net.ParseIP(routerIP)}}
+}
+
+func TestDestinationUnreachableEndsProbeWithReason(t *testing.T) {
+	conn := newFakePacketConn()
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{
+		PingIntervalSec: 1,
+		ICMPPayloadSize: 56,
+		ICMPTimeoutMs:   2000,
+	}, conn)
+
+	cancelReceiver, recvDone := startReceiver(t, engine, conn)
+	defer stopReceiver(t, cancelReceiver, conn, recvDone)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := engine.sendICMPEcho(context.Background(), 0, "10.0.0.1", 56, "", 2000)
+		errCh <- err
+	}()
+	waitForWriteCount(t, conn, 1, time.Second)
+	injectDestinationUnreachable(t, conn, 1, "192.0.2.1", conn.Writes()[0])
+
+	select {
+	case err := <-errCh:
+		var unreachErr *destinationUnreachableError
+		if !errors.As(err, &unreachErr) {
+			t.Fatalf("expected destination unreachable error, got %v", err)
+		}
+		if unreachErr.from != "192.0.2.1" {
+			t.Fatalf("expected router address in error, got %q", unreachErr.from)
+		}
+		if got := mapProbeError(err); got != "Host Unreachable" {
+			t.Fatalf("expected Host Unreachable, got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("probe did not end on destination unreachable")
+	}
+}
+
+func TestDestinationUnreachableForOtherProbeIsIgnored(t *testing.T) {
+	conn := newFakePacketConn()
+	engine := newTestEngine(&fakeProbeStore{}, defaultTestOptions(), model.Settings{
+		PingIntervalSec: 1,
+		ICMPPayloadSize: 56,
+		ICMPTimeoutMs:   300,
+	}, conn)
+
+	cancelReceiver, recvDone := startReceiver(t, engine, conn)
+	defer stopReceiver(t, cancelReceiver, conn, recvDone)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := engine.sendICMPEcho(context.Background(), 0, "10.0.0.1", 56, "", 300)
+		errCh <- err
+	}()
+	waitForWriteCount(t, conn, 1, time.Second)
+	echo := parseEchoRequest(t, conn.Writes()[0])
+
+	foreign, err := (&icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{ID: engine.echoID() + 1, Seq: echo.Seq, Data: echo.Data},
+	}).Marshal(nil)
+	if err != nil {
+		t.Fatalf("marshal foreign echo: %v", err)
+	}
+	injectDestinationUnreachable(t, conn, 1, "192.0.2.1", foreign)
+
+	select {
+	case err := <-errCh:
+		if got := mapProbeError(err); got != "Request Timeout" {
+			t.Fatalf("expected foreign unreachable to be ignored and probe to time out, got %q (%v)", got, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("probe did not time out")
+	}
+}
+
+func TestUnreachableReasonByCode(t *testing.T) {
+	tests := []struct {
+		family icmpFamily
+		code   int
+		want   string
+	}{
+		{icmpV4, 0, "Network Unreachable"},
+		{icmpV4, 1, "Host Unreachable"},
+		{icmpV4, 3, "Port Unreachable"},
+		{icmpV4, 13, "Administratively Prohibited"},
+		{icmpV4, 4, "Destination Unreachable"},
+		{icmpV6, 0, "Network Unreachable"},
+		{icmpV6, 1, "Administratively Prohibited"},
+		{icmpV6, 3, "Host Unreachable"},
+		{icmpV6, 4, "Port Unreachable"},
+	}
+	for _, tc := range tests {
+		if got := unreachableReason(tc.family, tc.code); got != tc.want {
+			t.Fatalf("%s code %d: expected %q, got %q", tc.family.name, tc.code, tc.want, got)
+		}
+	}
+}
+
+func TestQuotedEchoRejectsTruncatedPacket(t *testing.T) {
+	header := make([]byte, ipv4.HeaderLen)
+	header[0] = 0x45
+	header[9] = byte(icmpV4.protocol)
+	if _, _, _, ok := quotedEcho(icmpV4, append(header, 8, 0, 0, 0)); ok {
+		t.Fatal("expected quoted packet without full echo header to be rejected")
+	}
+	if _, _, _, ok := quotedEcho(icmpV4, nil); ok {
+		t.Fatal("expected empty quote to be rejected")
+	}
+}
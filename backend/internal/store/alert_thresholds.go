@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/model"
+)
+
+func (s *Store) SetGroupAlertThresholds(ctx context.Context, groupID int64, thresholds model.AlertThresholds) (model.Group, error) {
+	cmd, err := s.pool.Exec(ctx, `
+		UPDATE group_def
+		SET alert_consecutive_failed = $2,
+			alert_failed_pct = $3,
+			updated_at = now()
+		WHERE id = $1
+	`, groupID, thresholds.ConsecutiveFailed, thresholds.FailedPct)
+	if err != nil {
+		return model.Group{}, err
+	}
+	if cmd.RowsAffected() == 0 {
+		return model.Group{}, pgx.ErrNoRows
+	}
+	return s.GetGroupByID(ctx, groupID)
+}
+
+func (s *Store) ListEndpointAlertGroups(ctx context.Context, endpointID int64) ([]model.Group, error) {
+	var exists bool
+	if err := s.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM inventory_endpoint WHERE id = $1)`, endpointID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, pgx.ErrNoRows
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT g.id, g.name, g.alert_consecutive_failed, g.alert_failed_pct
+		FROM group_member gm
+		JOIN group_def g ON g.id = gm.group_id
+		WHERE gm.endpoint_id = $1
+		ORDER BY g.id
+	`, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []model.Group{}
+	for rows.Next() {
+		var g model.Group
+		if err := rows.Scan(&g.ID, &g.Name, &g.AlertThresholds.ConsecutiveFailed, &g.AlertThresholds.FailedPct); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
@@ -0,0 +1,218 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/alert"
+	"sonarscope/backend/internal/model"
+)
+
+// AlertSink receives threshold transitions detected while ping results are
+// recorded. Observe is called after the write commits and must not block.
+// While Enabled reports false the store skips the extra state read per result.
+type AlertSink interface {
+	Enabled() bool
+	Observe(transition model.EndpointTransition)
+}
+
+// SetAlertSink registers the receiver for down/recovered transitions.
+func (s *Store) SetAlertSink(sink AlertSink) {
+	s.alertSink = sink
+}
+
+func (s *Store) alertingEnabled() bool {
+	return s.alertSink != nil && s.alertSink.Enabled()
+}
+
+// StillFailingSampleCounts returns total_sent_ping for each of endpointIDs
+// that is active, monitored and still on a failure streak. The webhook
+// notifier uses it to release down alerts it held back during grace.
+func (s *Store) StillFailingSampleCounts(ctx context.Context, endpointIDs []int64) (map[int64]int64, error) {
+	counts := map[int64]int64{}
+	if len(endpointIDs) == 0 {
+		return counts, nil
+	}
+	rows, err := s.pool.Query(ctx, `
+		SELECT ie.id, es.total_sent_ping
+		FROM inventory_endpoint ie
+		JOIN endpoint_stats_current es ON es.endpoint_id = ie.id
+		WHERE ie.id = ANY($1::bigint[])
+		  AND ie.is_active = TRUE
+		  AND ie.monitoring_enabled = TRUE
+		  AND es.consecutive_failed_count > 0
+	`, endpointIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var endpointID, totalSent int64
+		if err := rows.Scan(&endpointID, &totalSent); err != nil {
+			return nil, err
+		}
+		counts[endpointID] = totalSent
+	}
+	return counts, rows.Err()
+}
+
+// alertFailedPctWindow is how many recent probes alert_failed_pct is
+// measured over. Lifetime counters would trip a new endpoint on its first
+// failure and never trip one with months of history, so the check waits for
+// a full window and then follows only the latest probes.
+const alertFailedPctWindow = 20
+
+// selectAlertStateSQL reads the endpoint's counters before the stats upsert,
+// along with its effective thresholds: for each one the lowest group
+// override, otherwise the global setting, as alert.ResolveThresholds does.
+// Zero overrides are ignored, like unset ones. The outcomes of the probes
+// before $2, newest first, feed the failed_pct window; the rows being
+// written in the same batch are already in ping_raw, hence the bound.
+var selectAlertStateSQL = `
+	SELECT
+		COALESCE(es.consecutive_failed_count, 0),
+		COALESCE(es.total_sent_ping, 0),
+		ARRAY(
+			SELECT pr.success
+			FROM ping_raw pr
+			WHERE pr.endpoint_id = ie.id
+			  AND pr.ts < $2
+			  AND NOT pr.maintenance
+			ORDER BY pr.ts DESC
+			LIMIT ` + strconv.Itoa(alertFailedPctWindow) + `
+		),
+		COALESCE(
+			(
+				SELECT MIN(NULLIF(g.alert_consecutive_failed, 0))
+				FROM group_member gm
+				JOIN group_def g ON g.id = gm.group_id
+				WHERE gm.endpoint_id = ie.id
+			),
+			(SELECT alert_consecutive_failed FROM app_settings WHERE id = TRUE),
+			0
+		),
+		COALESCE(
+			(
+				SELECT MIN(NULLIF(g.alert_failed_pct, 0))
+				FROM group_member gm
+				JOIN group_def g ON g.id = gm.group_id
+				WHERE gm.endpoint_id = ie.id
+			),
+			(SELECT alert_failed_pct FROM app_settings WHERE id = TRUE),
+			0
+		)::DOUBLE PRECISION,
+		ie.hostname,
+		COALESCE(host(ie.ip), ''),
+		ie.created_at
+	FROM inventory_endpoint ie
+	LEFT JOIN endpoint_stats_current es ON es.endpoint_id = ie.id
+	WHERE ie.id = $1
+`
+
+type alertState struct {
+	consecutiveFailed int64
+	totalSent         int64
+	// recent holds the outcomes of the probes before this one, newest first,
+	// up to alertFailedPctWindow.
+	recent     []bool
+	thresholds model.EffectiveAlertThresholds
+	hostname   string
+	ipAddress  string
+	createdAt  time.Time
+}
+
+// scanAlertState reports found=false when the endpoint no longer exists.
+func scanAlertState(row pgx.Row) (alertState, bool, error) {
+	var state alertState
+	err := row.Scan(
+		&state.consecutiveFailed,
+		&state.totalSent,
+		&state.recent,
+		&state.thresholds.ConsecutiveFailed,
+		&state.thresholds.FailedPct,
+		&state.hostname,
+		&state.ipAddress,
+		&state.createdAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return alertState{}, false, nil
+	}
+	if err != nil {
+		return alertState{}, false, err
+	}
+	return state, true, nil
+}
+
+// windowFailedPct is the failed share of the latest alertFailedPctWindow
+// outcomes, or false while fewer than that many are known.
+func windowFailedPct(outcomes []bool) (float64, bool) {
+	if len(outcomes) < alertFailedPctWindow {
+		return 0, false
+	}
+	failed := 0
+	for _, success := range outcomes[:alertFailedPctWindow] {
+		if !success {
+			failed++
+		}
+	}
+	return float64(failed) / float64(alertFailedPctWindow) * 100, true
+}
+
+// breached applies alert.Breached, leaving the failed_pct check out until
+// the window is full.
+func breached(thresholds model.EffectiveAlertThresholds, consecutiveFailed int64, outcomes []bool) (bool, float64) {
+	pct, full := windowFailedPct(outcomes)
+	if !full {
+		thresholds.FailedPct = 0
+	}
+	return alert.Breached(thresholds, consecutiveFailed, pct), pct
+}
+
+// transition applies result to the pre-write counters the same way the
+// stats upsert does and reports whether the endpoint crosses either
+// threshold (see alert.Breached). failed_pct is measured over the latest
+// alertFailedPctWindow probes. Only the probe that starts a breach is a
+// down transition and only the one that ends it is a recovery, so probes in
+// between do not repeat them.
+func (st alertState) transition(result model.PingResult) (model.EndpointTransition, bool) {
+	thresholds := st.thresholds
+	if thresholds.ConsecutiveFailed <= 0 && thresholds.FailedPct <= 0 {
+		return model.EndpointTransition{}, false
+	}
+
+	current := int64(0)
+	if !result.Success {
+		current = st.consecutiveFailed + 1
+	}
+	after := append([]bool{result.Success}, st.recent...)
+	wasDown, _ := breached(thresholds, st.consecutiveFailed, st.recent)
+	isDown, failedPct := breached(thresholds, current, after)
+
+	transition := model.EndpointTransition{
+		EndpointID:         result.EndpointID,
+		Hostname:           st.hostname,
+		IPAddress:          st.ipAddress,
+		Timestamp:          result.Timestamp,
+		Threshold:          thresholds.ConsecutiveFailed,
+		FailedPct:          failedPct,
+		FailedPctThreshold: thresholds.FailedPct,
+		ErrorCode:          result.ErrorCode,
+		CreatedAt:          st.createdAt,
+		TotalSentPing:      st.totalSent + 1,
+	}
+	switch {
+	case !wasDown && isDown:
+		transition.State = model.AlertStateDown
+		transition.ConsecutiveFailed = current
+	case wasDown && !isDown:
+		transition.State = model.AlertStateRecovered
+		transition.ConsecutiveFailed = st.consecutiveFailed
+	default:
+		return model.EndpointTransition{}, false
+	}
+	return transition, true
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+func consecutive(threshold int) model.EffectiveAlertThresholds {
+	return model.EffectiveAlertThresholds{ConsecutiveFailed: threshold}
+}
+
+func TestAlertStateTransitionFiresOnceAtThreshold(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	failure := model.PingResult{EndpointID: 7, Success: false, Timestamp: now, ErrorCode: "Request Timeout"}
+	success := model.PingResult{EndpointID: 7, Success: true, Timestamp: now}
+
+	cases := []struct {
+		name     string
+		state    alertState
+		result   model.PingResult
+		want     string
+		wantRuns int64
+	}{
+		{name: "below threshold", state: alertState{consecutiveFailed: 1, thresholds: consecutive(3)}, result: failure},
+		{name: "reaches threshold", state: alertState{consecutiveFailed: 2, thresholds: consecutive(3)}, result: failure, want: model.AlertStateDown, wantRuns: 3},
+		{name: "past threshold", state: alertState{consecutiveFailed: 3, thresholds: consecutive(3)}, result: failure},
+		{name: "threshold of one", state: alertState{thresholds: consecutive(1)}, result: failure, want: model.AlertStateDown, wantRuns: 1},
+		{name: "recovers after down", state: alertState{consecutiveFailed: 5, thresholds: consecutive(3)}, result: success, want: model.AlertStateRecovered, wantRuns: 5},
+		{name: "success below threshold", state: alertState{consecutiveFailed: 2, thresholds: consecutive(3)}, result: success},
+		{name: "disabled threshold", state: alertState{consecutiveFailed: 9}, result: failure},
+	}
+	for _, tc := range cases {
+		transition, ok := tc.state.transition(tc.result)
+		if tc.want == "" {
+			if ok {
+				t.Fatalf("%s: unexpected transition %+v", tc.name, transition)
+			}
+			continue
+		}
+		if !ok || transition.State != tc.want || transition.ConsecutiveFailed != tc.wantRuns {
+			t.Fatalf("%s: transition = %+v ok=%v, want %s with streak %d", tc.name, transition, ok, tc.want, tc.wantRuns)
+		}
+	}
+}
+
+// outcomes lists successes then failures, newest first, as selectAlertStateSQL
+// returns them.
+func outcomes(successes, failures int) []bool {
+	list := make([]bool, 0, successes+failures)
+	for i := 0; i < successes; i++ {
+		list = append(list, true)
+	}
+	for i := 0; i < failures; i++ {
+		list = append(list, false)
+	}
+	return list
+}
+
+func TestAlertStateTransitionFiresOnFailedPctAlone(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pctOnly := model.EffectiveAlertThresholds{FailedPct: 50}
+	failure := model.PingResult{EndpointID: 7, Success: false, Timestamp: now, ErrorCode: "Request Timeout"}
+	success := model.PingResult{EndpointID: 7, Success: true, Timestamp: now}
+
+	// 9 of the last 20 failed, newest first; the next failure pushes out the
+	// oldest success and makes it 10 of 20.
+	down := alertState{totalSent: 500, recent: append(outcomes(0, 9), outcomes(11, 0)...), thresholds: pctOnly}
+	transition, ok := down.transition(failure)
+	if !ok || transition.State != model.AlertStateDown || transition.FailedPct != 50 || transition.FailedPctThreshold != 50 {
+		t.Fatalf("transition = %+v ok=%v, want down at 50%%", transition, ok)
+	}
+
+	// Still at or above 50% after another failure: no repeat.
+	past := alertState{totalSent: 501, recent: append(outcomes(0, 10), outcomes(10, 0)...), consecutiveFailed: 10, thresholds: pctOnly}
+	if transition, ok := past.transition(failure); ok {
+		t.Fatalf("unexpected repeat transition %+v", transition)
+	}
+
+	// 10 of 20 failed, the oldest ones; a success pushes one out.
+	recovering := alertState{totalSent: 501, recent: outcomes(10, 10), thresholds: pctOnly}
+	transition, ok = recovering.transition(success)
+	if !ok || transition.State != model.AlertStateRecovered || transition.FailedPct != 45 {
+		t.Fatalf("transition = %+v ok=%v, want recovered at 45%%", transition, ok)
+	}
+}
+
+func TestAlertStateTransitionFailedPctWaitsForFullWindow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pctOnly := model.EffectiveAlertThresholds{FailedPct: 50}
+	failure := model.PingResult{EndpointID: 7, Success: false, Timestamp: now, ErrorCode: "Request Timeout"}
+
+	// A new endpoint's first failure is 100% of one probe, not an outage.
+	fresh := alertState{thresholds: pctOnly}
+	if transition, ok := fresh.transition(failure); ok {
+		t.Fatalf("first probe should not trip failed_pct: %+v", transition)
+	}
+
+	// The probe that fills the window is the first one judged.
+	filling := alertState{totalSent: alertFailedPctWindow - 1, recent: outcomes(0, alertFailedPctWindow-1), consecutiveFailed: alertFailedPctWindow - 1, thresholds: pctOnly}
+	transition, ok := filling.transition(failure)
+	if !ok || transition.State != model.AlertStateDown || transition.FailedPct != 100 {
+		t.Fatalf("transition = %+v ok=%v, want down at 100%%", transition, ok)
+	}
+	if transition.TotalSentPing != alertFailedPctWindow {
+		t.Fatalf("TotalSentPing = %d, want %d", transition.TotalSentPing, alertFailedPctWindow)
+	}
+}
+
+func TestAlertStateTransitionFailedPctIgnoresLongHistory(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	failure := model.PingResult{EndpointID: 7, Success: false, Timestamp: now, ErrorCode: "Request Timeout"}
+
+	// Months of clean history do not dilute an outage in the latest probes.
+	veteran := alertState{totalSent: 5_000_000, recent: append(outcomes(0, 9), outcomes(11, 0)...), consecutiveFailed: 9, thresholds: model.EffectiveAlertThresholds{FailedPct: 50}}
+	transition, ok := veteran.transition(failure)
+	if !ok || transition.State != model.AlertStateDown {
+		t.Fatalf("transition = %+v ok=%v, want down", transition, ok)
+	}
+}
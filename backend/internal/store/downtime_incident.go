@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/model"
+)
+
+var ErrDowntimeIncidentNotFound = errors.New("downtime incident not found")
+
+const downtimeIncidentColumns = `
+			di.id,
+			di.endpoint_id,
+			ie.hostname,
+			COALESCE(host(ie.ip), ''),
+			di.started_at,
+			di.ended_at,
+			di.duration_sec,
+			di.error_code,
+			di.cause,
+			di.note,
+			di.severity`
+
+func scanDowntimeIncident(row pgx.Row) (model.DowntimeIncident, error) {
+	var item model.DowntimeIncident
+	err := row.Scan(
+		&item.ID,
+		&item.EndpointID,
+		&item.Hostname,
+		&item.IPAddress,
+		&item.StartedAt,
+		&item.EndedAt,
+		&item.DurationSec,
+		&item.ErrorCode,
+		&item.Cause,
+		&item.Note,
+		&item.Severity,
+	)
+	if err != nil {
+		return model.DowntimeIncident{}, err
+	}
+	item.Ongoing = item.EndedAt == nil
+	return item, nil
+}
+
+// ListDowntimeIncidents returns incidents that overlap [start, end], newest
+// first. An empty endpointIDs matches every endpoint. Open incidents overlap
+// any window that ends after they started.
+func (s *Store) ListDowntimeIncidents(ctx context.Context, endpointIDs []int64, start, end time.Time, limit int) ([]model.DowntimeIncident, error) {
+	args := []any{start, end, limit}
+	endpointClause := ""
+	if len(endpointIDs) > 0 {
+		args = append(args, endpointIDs)
+		endpointClause = fmt.Sprintf(" AND di.endpoint_id = ANY($%d)", len(args))
+	}
+
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(`
+		SELECT%s
+		FROM downtime_incident di
+		JOIN inventory_endpoint ie ON ie.id = di.endpoint_id
+		WHERE di.started_at <= $2::timestamptz
+		  AND (di.ended_at IS NULL OR di.ended_at >= $1::timestamptz)%s
+		ORDER BY di.started_at DESC, di.id DESC
+		LIMIT $3
+	`, downtimeIncidentColumns, endpointClause), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.DowntimeIncident{}
+	for rows.Next() {
+		item, err := scanDowntimeIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// AnnotateDowntimeIncident applies a cause/note/severity patch to one
+// incident and returns it. Nil fields keep their current value.
+func (s *Store) AnnotateDowntimeIncident(ctx context.Context, id int64, annotation model.DowntimeIncidentAnnotation) (model.DowntimeIncident, error) {
+	item, err := scanDowntimeIncident(s.pool.QueryRow(ctx, `
+		WITH updated AS (
+			UPDATE downtime_incident
+			SET cause = COALESCE($2, cause),
+				note = COALESCE($3, note),
+				severity = COALESCE($4, severity)
+			WHERE id = $1
+			RETURNING *
+		)
+		SELECT`+downtimeIncidentColumns+`
+		FROM updated di
+		JOIN inventory_endpoint ie ON ie.id = di.endpoint_id
+	`, id, annotation.Cause, annotation.Note, annotation.Severity))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.DowntimeIncident{}, ErrDowntimeIncidentNotFound
+	}
+	return item, err
+}
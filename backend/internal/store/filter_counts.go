@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sonarscope/backend/internal/model"
+)
+
+// ListFilterCounts returns, per dimension (vlan, switch, port, group), each
+// value and the number of active endpoints that carry it under the query's
+// filters. A dimension's own selection is left out of its counts, so the
+// other values show how many endpoints they would add. Every group is
+// listed, even with a zero count; other values appear only when matched.
+func (s *Store) ListFilterCounts(ctx context.Context, query MonitorPageQuery) (map[string][]model.FilterValueCount, error) {
+	out := map[string][]model.FilterValueCount{}
+	for _, dimension := range []struct {
+		key    string
+		column string
+		drop   func(*MonitorFilters)
+	}{
+		{"vlan", "vlan", func(f *MonitorFilters) { f.VLANs = nil }},
+		{"switch", "switch_name", func(f *MonitorFilters) { f.Switches = nil }},
+		{"port", "port", func(f *MonitorFilters) { f.Ports = nil }},
+	} {
+		whereClause, args := filterCountsWhereClause(s.monitorStatus(), query, dimension.drop)
+		counts, err := s.scanFilterCounts(ctx, fmt.Sprintf(`
+			SELECT ie.%[1]s, COUNT(*)::BIGINT
+			FROM inventory_endpoint ie%[2]s
+			  AND ie.%[1]s <> ''
+			GROUP BY ie.%[1]s
+			ORDER BY ie.%[1]s
+		`, dimension.column, whereClause), args)
+		if err != nil {
+			return nil, err
+		}
+		out[dimension.key] = counts
+	}
+
+	whereClause, args := filterCountsWhereClause(s.monitorStatus(), query, func(f *MonitorFilters) { f.GroupNames = nil })
+	counts, err := s.scanFilterCounts(ctx, `
+		SELECT gd.name, COUNT(m.endpoint_id)::BIGINT
+		FROM group_def gd
+		LEFT JOIN (
+			SELECT gm.group_id, gm.endpoint_id
+			FROM group_member gm
+			JOIN inventory_endpoint ie ON ie.id = gm.endpoint_id`+whereClause+`
+		) m ON m.group_id = gd.id
+		GROUP BY gd.name
+		ORDER BY gd.name
+	`, args)
+	if err != nil {
+		return nil, err
+	}
+	out["group"] = counts
+	return out, nil
+}
+
+// filterCountsWhereClause is the monitor WHERE clause for query with one
+// dimension's selection dropped.
+func filterCountsWhereClause(status monitorStatusSQL, query MonitorPageQuery, drop func(*MonitorFilters)) (string, []any) {
+	filters := query.Filters
+	drop(&filters)
+	return buildMonitorWhereClause(
+		status,
+		filters,
+		query.Hostname,
+		query.MAC,
+		query.CustomSearches,
+		query.IPList,
+		query.ExcludeEndpointIDs,
+		query.Search,
+	)
+}
+
+func (s *Store) scanFilterCounts(ctx context.Context, query string, args []any) ([]model.FilterValueCount, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []model.FilterValueCount{}
+	for rows.Next() {
+		var item model.FilterValueCount
+		if err := rows.Scan(&item.Value, &item.Count); err != nil {
+			return nil, err
+		}
+		item.Value = strings.TrimSpace(item.Value)
+		counts = append(counts, item)
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,24 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterCountsWhereClauseDropsOwnDimension(t *testing.T) {
+	query := MonitorPageQuery{
+		Filters:  MonitorFilters{VLANs: []string{"10"}, Switches: []string{"sw-a"}},
+		Hostname: "web",
+	}
+
+	whereClause, args := filterCountsWhereClause(monitorStatusSQL{}, query, func(f *MonitorFilters) { f.VLANs = nil })
+	if strings.Contains(whereClause, "ie.vlan") || !strings.Contains(whereClause, "ie.switch_name = ANY($1)") || !strings.Contains(whereClause, "ie.hostname ILIKE $2") {
+		t.Fatalf("unexpected where clause: %s", whereClause)
+	}
+	if len(args) != 2 {
+		t.Fatalf("args = %v, want switch and hostname", args)
+	}
+	if len(query.Filters.VLANs) != 1 {
+		t.Fatalf("expected the caller's filters to be left alone, got %+v", query.Filters)
+	}
+}
@@ -0,0 +1,26 @@
+package store
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/model"
+)
+
+// SetGroupProbeInterval sets or, with nil, clears the group's probe interval.
+func (s *Store) SetGroupProbeInterval(ctx context.Context, groupID int64, intervalSec *int) (model.Group, error) {
+	cmd, err := s.pool.Exec(ctx, `
+		UPDATE group_def
+		SET probe_interval_sec = $2,
+			updated_at = now()
+		WHERE id = $1
+	`, groupID, intervalSec)
+	if err != nil {
+		return model.Group{}, err
+	}
+	if cmd.RowsAffected() == 0 {
+		return model.Group{}, pgx.ErrNoRows
+	}
+	return s.GetGroupByID(ctx, groupID)
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"sonarscope/backend/internal/model"
+)
+
+// MonitorGroupSummaries returns one live rollup per group over the group
+// members that match the monitor filters. Groups without a matching member
+// are still listed with zero counts; a group filter limits the listing to
+// the named groups. Up and down follow monitorStatusSQL, the same rules as
+// the status filter.
+func (s *Store) MonitorGroupSummaries(ctx context.Context, query MonitorPageQuery) ([]model.MonitorGroupSummary, error) {
+	whereClause, args := buildMonitorWhereClause(
+		s.monitorStatus(),
+		query.Filters,
+		query.Hostname,
+		query.MAC,
+		query.CustomSearches,
+		query.IPList,
+		query.ExcludeEndpointIDs,
+		query.Search,
+	)
+
+	groupNamesPos := 0
+	if len(query.Filters.GroupNames) > 0 {
+		args = append(args, query.Filters.GroupNames)
+		groupNamesPos = len(args)
+	}
+
+	rows, err := s.pool.Query(ctx, buildGroupSummaryQuery(s.monitorStatus(), whereClause, groupNamesPos), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.MonitorGroupSummary{}
+	for rows.Next() {
+		var (
+			item       model.MonitorGroupSummary
+			worstID    *int64
+			worstHost  *string
+			worstIP    *string
+			worstFails *float64
+		)
+		if err := rows.Scan(
+			&item.GroupID,
+			&item.GroupName,
+			&item.IsSystem,
+			&item.TotalEndpoints,
+			&item.UpEndpoints,
+			&item.DownEndpoints,
+			&item.LossPct,
+			&item.AvgLatencyMs,
+			&worstID,
+			&worstHost,
+			&worstIP,
+			&worstFails,
+		); err != nil {
+			return nil, err
+		}
+		if worstID != nil {
+			item.WorstOffender = &model.GroupWorstOffender{EndpointID: *worstID}
+			if worstHost != nil {
+				item.WorstOffender.Hostname = *worstHost
+			}
+			if worstIP != nil {
+				item.WorstOffender.IPAddress = *worstIP
+			}
+			if worstFails != nil {
+				item.WorstOffender.FailedPct = *worstFails
+			}
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// buildGroupSummaryQuery aggregates endpoint_stats_current per group in one
+// pass. The worst offender is the member with the highest failed_pct among
+// those that failed at least once, ties broken by the current streak.
+func buildGroupSummaryQuery(status monitorStatusSQL, whereClause string, groupNamesPos int) string {
+	groupClause := ""
+	if groupNamesPos > 0 {
+		groupClause = fmt.Sprintf("WHERE g.name = ANY($%d)", groupNamesPos)
+	}
+	return fmt.Sprintf(`
+		WITH scoped AS (
+			SELECT
+				gm.group_id,
+				ie.id AS endpoint_id,
+				COALESCE(ie.hostname, '') AS hostname,
+				COALESCE(host(ie.ip), '') AS ip,
+				COALESCE(es.total_sent_ping, 0) AS total_sent_ping,
+				COALESCE(es.failed_count, 0) AS failed_count,
+				COALESCE(es.success_count, 0) AS success_count,
+				COALESCE(es.failed_pct, 0)::DOUBLE PRECISION AS failed_pct,
+				COALESCE(es.consecutive_failed_count, 0) AS consecutive_failed_count,
+				es.average_latency,
+				%s AS is_up,
+				%s AS is_down
+			FROM inventory_endpoint ie
+			INNER JOIN group_member gm ON gm.endpoint_id = ie.id
+			LEFT JOIN endpoint_stats_current es ON es.endpoint_id = ie.id
+			%s
+		),
+		worst AS (
+			SELECT DISTINCT ON (group_id)
+				group_id,
+				endpoint_id,
+				hostname,
+				ip,
+				failed_pct
+			FROM scoped
+			WHERE failed_count > 0
+			ORDER BY group_id, failed_pct DESC, consecutive_failed_count DESC, endpoint_id
+		)
+		SELECT
+			g.id,
+			g.name,
+			g.is_system,
+			COUNT(sc.endpoint_id)::BIGINT,
+			COUNT(*) FILTER (WHERE sc.is_up)::BIGINT,
+			COUNT(*) FILTER (WHERE sc.is_down)::BIGINT,
+			CASE
+				WHEN COALESCE(SUM(sc.success_count + sc.failed_count), 0) > 0
+				THEN SUM(sc.failed_count)::DOUBLE PRECISION * 100 / SUM(sc.success_count + sc.failed_count)
+				ELSE 0
+			END,
+			(SUM(sc.average_latency * sc.success_count) FILTER (WHERE sc.average_latency IS NOT NULL) /
+				NULLIF(SUM(sc.success_count) FILTER (WHERE sc.average_latency IS NOT NULL), 0))::DOUBLE PRECISION,
+			w.endpoint_id,
+			w.hostname,
+			w.ip,
+			w.failed_pct
+		FROM group_def g
+		LEFT JOIN scoped sc ON sc.group_id = g.id
+		LEFT JOIN worst w ON w.group_id = g.id
+		%s
+		GROUP BY g.id, w.endpoint_id, w.hostname, w.ip, w.failed_pct
+		ORDER BY g.is_system DESC, lower(g.name), g.name
+	`, status.isUp("es"), status.isDown("es"), whereClause, groupClause)
+}
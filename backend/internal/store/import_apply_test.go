@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestDryRunImportProjectsWithoutWriting(t *testing.T) {
+	st, endpointID := testPingStore(t)
+	ctx := context.Background()
+
+	var existingIP string
+	if err := st.pool.QueryRow(ctx, `SELECT host(ip) FROM inventory_endpoint WHERE id = $1`, endpointID).Scan(&existingIP); err != nil {
+		t.Fatalf("load endpoint ip: %v", err)
+	}
+	rows := []model.ImportCandidate{
+		{RowID: "row-1", IP: existingIP, Description: "dry run", Action: model.ImportUpdate},
+		// Fails, and must not abort the transaction for the rows after it.
+		{RowID: "row-2", IP: existingIP, Action: model.ImportAdd},
+		{RowID: "row-3", IP: "198.51.100.77", Action: model.ImportAdd},
+	}
+
+	added, updated, errs, failed, err := st.DryRunImport(ctx, rows)
+	if err != nil {
+		t.Fatalf("DryRunImport() error = %v", err)
+	}
+	if added != 1 || updated != 1 || len(errs) != 1 || len(failed) != 1 || failed[0] != "row-2" {
+		t.Fatalf("DryRunImport() = %d added, %d updated, %v, %v", added, updated, errs, failed)
+	}
+
+	var description string
+	var created bool
+	if err := st.pool.QueryRow(ctx, `
+		SELECT
+			(SELECT description FROM inventory_endpoint WHERE id = $1),
+			EXISTS(SELECT 1 FROM inventory_endpoint WHERE ip = '198.51.100.77')
+	`, endpointID).Scan(&description, &created); err != nil {
+		t.Fatalf("check writes: %v", err)
+	}
+	if description == "dry run" || created {
+		t.Fatalf("dry run kept its writes: description %q, created %v", description, created)
+	}
+}
+
+func TestApplyImportWritesCustomFieldValues(t *testing.T) {
+	st, endpointID := testPingStore(t)
+	ctx := context.Background()
+
+	var existingIP string
+	if err := st.pool.QueryRow(ctx, `SELECT host(ip) FROM inventory_endpoint WHERE id = $1`, endpointID).Scan(&existingIP); err != nil {
+		t.Fatalf("load endpoint ip: %v", err)
+	}
+	newIP := "198.51.100.78"
+	t.Cleanup(func() {
+		_, _ = st.pool.Exec(context.Background(), `DELETE FROM inventory_endpoint WHERE ip = $1::inet`, newIP)
+	})
+
+	added, updated, errs, _ := st.ApplyImport(ctx, []model.ImportCandidate{
+		{RowID: "row-1", IP: newIP, CustomField1Value: "alice", CustomField10Value: "rack-9", Action: model.ImportAdd},
+		{RowID: "row-2", IP: existingIP, CustomField2Value: "bob", Action: model.ImportUpdate},
+	})
+	if added != 1 || updated != 1 || len(errs) != 0 {
+		t.Fatalf("ApplyImport() = %d added, %d updated, %v", added, updated, errs)
+	}
+
+	var first, tenth, second string
+	if err := st.pool.QueryRow(ctx, `SELECT custom_field_1_value, custom_field_10_value FROM inventory_endpoint WHERE ip = $1::inet`, newIP).Scan(&first, &tenth); err != nil {
+		t.Fatalf("load added endpoint: %v", err)
+	}
+	if err := st.pool.QueryRow(ctx, `SELECT custom_field_2_value FROM inventory_endpoint WHERE id = $1`, endpointID).Scan(&second); err != nil {
+		t.Fatalf("load updated endpoint: %v", err)
+	}
+	if first != "alice" || tenth != "rack-9" || second != "bob" {
+		t.Fatalf("custom fields = %q, %q, %q; want alice, rack-9, bob", first, tenth, second)
+	}
+}
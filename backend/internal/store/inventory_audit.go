@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"sonarscope/backend/internal/model"
+)
+
+func (s *Store) ListInventoryEndpointHistory(ctx context.Context, endpointID int64, limit int) ([]model.InventoryAuditEntry, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, endpoint_id, action, changed_at, before_data, after_data
+		FROM inventory_audit_log
+		WHERE endpoint_id = $1
+		ORDER BY changed_at DESC, id DESC
+		LIMIT $2
+	`, endpointID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.InventoryAuditEntry{}
+	for rows.Next() {
+		var item model.InventoryAuditEntry
+		var beforeRaw []byte
+		var afterRaw []byte
+		if err := rows.Scan(&item.ID, &item.EndpointID, &item.Action, &item.ChangedAt, &beforeRaw, &afterRaw); err != nil {
+			return nil, err
+		}
+		if item.Before, err = decodeAuditDocument(beforeRaw); err != nil {
+			return nil, err
+		}
+		if item.After, err = decodeAuditDocument(afterRaw); err != nil {
+			return nil, err
+		}
+		item.ChangedFields = auditChangedFields(item.Before, item.After)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func decodeAuditDocument(raw []byte) (map[string]any, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var document map[string]any
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+func auditChangedFields(before map[string]any, after map[string]any) []string {
+	seen := map[string]struct{}{}
+	for key := range before {
+		seen[key] = struct{}{}
+	}
+	for key := range after {
+		seen[key] = struct{}{}
+	}
+	fields := make([]string, 0, len(seen))
+	for key := range seen {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -339,7 +339,7 @@ func (s *Store) ListInventoryEndpointsByIDs(
 		SELECT
 			ie.id,
 				ie.hostname,
-				host(ie.ip) AS ip_address,
+				COALESCE(host(ie.ip), '') AS ip_address,
 				ie.mac,
 				`+customFieldValueColumns("ie")+`,
 				ie.vlan,
@@ -352,15 +352,21 @@ func (s *Store) ListInventoryEndpointsByIDs(
 				ie.speed,
 				ie.duplex,
 				ie.description,
+				ie.probe_mode,
+				ie.probe_http_target,
+				ie.probe_tcp_port,
+				ie.probe_interval_sec,
 			COALESCE(array_remove(array_agg(DISTINCT gd.name), NULL), '{}') AS groups,
 			ie.is_active,
+			ie.monitoring_enabled,
 			ie.updated_at
 		FROM inventory_endpoint ie
 		LEFT JOIN group_member gm ON gm.endpoint_id = ie.id
 		LEFT JOIN group_def gd ON gd.id = gm.group_id
 		WHERE ie.id = ANY($1)
 			GROUP BY ie.id, ie.hostname, ie.ip, ie.mac, ie.vlan, ie.zone, ie.switch_name, ie.port,
-				ie.port_type, ie.gateway, ie.mgmt_ip, ie.speed, ie.duplex, ie.description, ie.is_active, ie.updated_at,
+				ie.port_type, ie.gateway, ie.mgmt_ip, ie.speed, ie.duplex, ie.description, ie.probe_mode, ie.probe_http_target, ie.probe_tcp_port, ie.probe_interval_sec,
+				ie.is_active, ie.monitoring_enabled, ie.updated_at,
 				`+customFieldValueColumns("ie")+`
 		ORDER BY ie.ip
 		LIMIT $2
@@ -391,8 +397,13 @@ func (s *Store) ListInventoryEndpointsByIDs(
 			&item.Speed,
 			&item.Duplex,
 			&item.Description,
+			&item.ProbeMode,
+			&item.ProbeHTTPTarget,
+			&item.ProbeTCPPort,
+			&item.ProbeIntervalSec,
 			&item.Groups,
 			&item.Active,
+			&item.MonitoringEnabled,
 			&item.UpdatedAt,
 		)
 		if err := rows.Scan(scanTargets...); err != nil {
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+
+	"sonarscope/backend/internal/model"
+)
+
+// CreateInventoryEndpoints inserts payloads in one transaction. Each row gets
+// its own savepoint, so a taken IP (ErrEndpointIPExists) or another row error
+// only drops that row. ids[i] is 0 when rowErrs[i] is set; the returned error
+// is reserved for failures that abort the whole batch.
+func (s *Store) CreateInventoryEndpoints(ctx context.Context, payloads []model.InventoryEndpointCreate) ([]int64, []error, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	ids := make([]int64, len(payloads))
+	rowErrs := make([]error, len(payloads))
+	for i, payload := range payloads {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		endpointID, err := insertInventoryEndpointTx(ctx, savepoint, payload)
+		if err != nil {
+			if rollbackErr := savepoint.Rollback(ctx); rollbackErr != nil {
+				return nil, nil, rollbackErr
+			}
+			rowErrs[i] = err
+			continue
+		}
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, nil, err
+		}
+		ids[i] = endpointID
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return ids, rowErrs, nil
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"sonarscope/backend/internal/model"
+)
+
+// lastSeenBucketExpression classifies an endpoint by the age of its last
+// successful probe. Endpoints with no stats row, or that have never
+// succeeded, fall in the never bucket.
+const lastSeenBucketExpression = `
+	CASE
+		WHEN es.last_success_on IS NULL THEN 'never'
+		WHEN es.last_success_on > now() - interval '1 hour' THEN 'lt_1h'
+		WHEN es.last_success_on > now() - interval '1 day' THEN '1h_1d'
+		WHEN es.last_success_on > now() - interval '7 days' THEN '1d_1w'
+		ELSE 'gt_1w'
+	END
+`
+
+func (s *Store) LastSeenBucketCounts(ctx context.Context, query MonitorPageQuery) ([]model.LastSeenBucketCount, error) {
+	whereClause, args := buildMonitorWhereClause(
+		s.monitorStatus(),
+		query.Filters,
+		query.Hostname,
+		query.MAC,
+		query.CustomSearches,
+		query.IPList,
+		query.ExcludeEndpointIDs,
+		query.Search,
+	)
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+lastSeenBucketExpression+` AS bucket, COUNT(*)::BIGINT
+		FROM inventory_endpoint ie
+		LEFT JOIN endpoint_stats_current es ON es.endpoint_id = ie.id
+	`+whereClause+`
+		GROUP BY bucket
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int64{}
+	for rows.Next() {
+		var bucket string
+		var endpoints int64
+		if err := rows.Scan(&bucket, &endpoints); err != nil {
+			return nil, err
+		}
+		counts[bucket] = endpoints
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return lastSeenBucketCountsFromMap(counts), nil
+}
+
+func lastSeenBucketCountsFromMap(counts map[string]int64) []model.LastSeenBucketCount {
+	items := make([]model.LastSeenBucketCount, 0, len(model.LastSeenBuckets))
+	for _, bucket := range model.LastSeenBuckets {
+		items = append(items, model.LastSeenBucketCount{Bucket: bucket, Endpoints: counts[bucket]})
+	}
+	return items
+}
+
+// ListLastSeenBucket returns endpoints in one bucket, oldest success first.
+func (s *Store) ListLastSeenBucket(ctx context.Context, query MonitorPageQuery, bucket string, limit int) ([]model.LastSeenEndpoint, error) {
+	if !model.IsLastSeenBucket(bucket) {
+		return nil, fmt.Errorf("invalid last-seen bucket %q", bucket)
+	}
+	whereClause, args := buildMonitorWhereClause(
+		s.monitorStatus(),
+		query.Filters,
+		query.Hostname,
+		query.MAC,
+		query.CustomSearches,
+		query.IPList,
+		query.ExcludeEndpointIDs,
+		query.Search,
+	)
+	bucketPos := len(args) + 1
+	limitPos := len(args) + 2
+	args = append(args, bucket, limit)
+
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(`
+		SELECT
+			ie.id,
+			ie.hostname,
+			COALESCE(host(ie.ip), ''),
+			es.last_success_on,
+			es.last_failed_on,
+			%s AS last_ping_status,
+			COALESCE(es.total_sent_ping, 0)
+		FROM inventory_endpoint ie
+		LEFT JOIN endpoint_stats_current es ON es.endpoint_id = ie.id
+		%s AND (%s) = $%d
+		ORDER BY es.last_success_on ASC NULLS FIRST, ie.id
+		LIMIT $%d
+	`, s.liveStatusExpression(), whereClause, lastSeenBucketExpression, bucketPos, limitPos), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.LastSeenEndpoint{}
+	for rows.Next() {
+		var item model.LastSeenEndpoint
+		if err := rows.Scan(
+			&item.EndpointID,
+			&item.Hostname,
+			&item.IPAddress,
+			&item.LastSuccessOn,
+			&item.LastFailedOn,
+			&item.LastPingStatus,
+			&item.TotalSentPing,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,30 @@
+package store
+
+import (
+	"testing"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestLastSeenBucketCountsFromMapFillsEveryBucket(t *testing.T) {
+	items := lastSeenBucketCountsFromMap(map[string]int64{
+		model.LastSeenBucketUnder1h: 40,
+		model.LastSeenBucketOver1w:  3,
+		model.LastSeenBucketNever:   2,
+	})
+	want := []model.LastSeenBucketCount{
+		{Bucket: model.LastSeenBucketUnder1h, Endpoints: 40},
+		{Bucket: model.LastSeenBucket1hTo1d, Endpoints: 0},
+		{Bucket: model.LastSeenBucket1dTo1w, Endpoints: 0},
+		{Bucket: model.LastSeenBucketOver1w, Endpoints: 3},
+		{Bucket: model.LastSeenBucketNever, Endpoints: 2},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("bucket count = %d, want %d", len(items), len(want))
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Fatalf("bucket %d = %+v, want %+v", i, items[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/maintenance"
+	"sonarscope/backend/internal/model"
+)
+
+// maintenanceCacheTTL is how long the probe writer reuses the loaded windows.
+// Window writes through the store reset it immediately; group membership
+// changes are picked up on the next reload.
+const maintenanceCacheTTL = 30 * time.Second
+
+var ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+
+const maintenanceWindowColumns = `
+	id, name, scope, endpoint_id, group_id, starts_at, ends_at, recurrence, timezone, created_at, updated_at
+`
+
+func scanMaintenanceWindow(row pgx.Row) (model.MaintenanceWindow, error) {
+	var item model.MaintenanceWindow
+	err := row.Scan(
+		&item.ID,
+		&item.Name,
+		&item.Scope,
+		&item.EndpointID,
+		&item.GroupID,
+		&item.StartsAt,
+		&item.EndsAt,
+		&item.Recurrence,
+		&item.Timezone,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.MaintenanceWindow{}, ErrMaintenanceWindowNotFound
+	}
+	return item, err
+}
+
+func (s *Store) ListMaintenanceWindows(ctx context.Context) ([]model.MaintenanceWindow, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+maintenanceWindowColumns+` FROM maintenance_window ORDER BY starts_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.MaintenanceWindow{}
+	for rows.Next() {
+		item, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (s *Store) GetMaintenanceWindow(ctx context.Context, id int64) (model.MaintenanceWindow, error) {
+	return scanMaintenanceWindow(s.pool.QueryRow(ctx, `SELECT `+maintenanceWindowColumns+` FROM maintenance_window WHERE id = $1`, id))
+}
+
+func (s *Store) CreateMaintenanceWindow(ctx context.Context, input model.MaintenanceWindowInput) (model.MaintenanceWindow, error) {
+	item, err := scanMaintenanceWindow(s.pool.QueryRow(ctx, `
+		INSERT INTO maintenance_window(name, scope, endpoint_id, group_id, starts_at, ends_at, recurrence, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+maintenanceWindowColumns,
+		input.Name, input.Scope, input.EndpointID, input.GroupID, input.StartsAt, input.EndsAt, input.Recurrence, input.Timezone,
+	))
+	if err == nil {
+		s.invalidateMaintenanceCache()
+	}
+	return item, err
+}
+
+func (s *Store) UpdateMaintenanceWindow(ctx context.Context, id int64, input model.MaintenanceWindowInput) (model.MaintenanceWindow, error) {
+	item, err := scanMaintenanceWindow(s.pool.QueryRow(ctx, `
+		UPDATE maintenance_window
+		SET name = $2,
+			scope = $3,
+			endpoint_id = $4,
+			group_id = $5,
+			starts_at = $6,
+			ends_at = $7,
+			recurrence = $8,
+			timezone = $9,
+			updated_at = now()
+		WHERE id = $1
+		RETURNING `+maintenanceWindowColumns,
+		id, input.Name, input.Scope, input.EndpointID, input.GroupID, input.StartsAt, input.EndsAt, input.Recurrence, input.Timezone,
+	))
+	if err == nil {
+		s.invalidateMaintenanceCache()
+	}
+	return item, err
+}
+
+func (s *Store) DeleteMaintenanceWindow(ctx context.Context, id int64) error {
+	cmd, err := s.pool.Exec(ctx, `DELETE FROM maintenance_window WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrMaintenanceWindowNotFound
+	}
+	s.invalidateMaintenanceCache()
+	return nil
+}
+
+func (s *Store) invalidateMaintenanceCache() {
+	s.maintenanceMu.Lock()
+	s.maintenanceSet = nil
+	s.maintenanceMu.Unlock()
+}
+
+// activeMaintenance returns the windows that can still cover a probe, loading
+// them at most once per maintenanceCacheTTL. Group windows are expanded to
+// their members at load time.
+func (s *Store) activeMaintenance(ctx context.Context) (*maintenance.Set, error) {
+	s.maintenanceMu.Lock()
+	defer s.maintenanceMu.Unlock()
+	if s.maintenanceSet != nil && time.Since(s.maintenanceLoadedAt) < maintenanceCacheTTL {
+		return s.maintenanceSet, nil
+	}
+
+	// One-off windows that ended more than an hour ago cannot cover a probe
+	// that is still being written.
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			mw.id,
+			mw.scope,
+			CASE mw.scope
+				WHEN 'endpoint' THEN ARRAY[mw.endpoint_id]
+				WHEN 'group' THEN COALESCE((SELECT array_agg(gm.endpoint_id) FROM group_member gm WHERE gm.group_id = mw.group_id), '{}')
+				ELSE '{}'::bigint[]
+			END,
+			mw.starts_at,
+			mw.ends_at,
+			mw.recurrence,
+			mw.timezone
+		FROM maintenance_window mw
+		WHERE mw.recurrence <> '' OR mw.ends_at > now() - INTERVAL '1 hour'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	set := maintenance.NewSet()
+	for rows.Next() {
+		var (
+			id                   int64
+			scope                string
+			endpointIDs          []int64
+			startsAt, endsAt     time.Time
+			recurrence, timezone string
+		)
+		if err := rows.Scan(&id, &scope, &endpointIDs, &startsAt, &endsAt, &recurrence, &timezone); err != nil {
+			return nil, err
+		}
+		window, err := maintenance.NewWindow(startsAt, endsAt, recurrence, timezone)
+		if err != nil {
+			slog.Warn("maintenance window skipped", "window_id", id, "error", err)
+			continue
+		}
+		if scope == model.MaintenanceScopeAll {
+			set.AddAll(window)
+		} else {
+			set.AddEndpoints(window, endpointIDs)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	s.maintenanceSet = set
+	s.maintenanceLoadedAt = time.Now()
+	return set, nil
+}
+
+// rangeBucketsSQL selects endpoint_id, bucket, sent_count, fail_count, and
+// avg_latency_ms from viewName for buckets in [$startPos, $endPos], limited to
+// the endpoint ids in $endpointIDsPos when it is non-zero. With
+// excludeMaintenance, maintenance-tagged probes still in ping_raw are
+// subtracted from their bucket so they count as neither sent nor failed.
+// Windows older than raw retention can no longer be subtracted.
+func rangeBucketsSQL(viewName string, bucketWidth time.Duration, startPos, endPos, endpointIDsPos int, excludeMaintenance bool) string {
+	endpointClause := func(alias string) string {
+		if endpointIDsPos == 0 {
+			return ""
+		}
+		return fmt.Sprintf(" AND %s.endpoint_id = ANY($%d::bigint[])", alias, endpointIDsPos)
+	}
+	if !excludeMaintenance {
+		return fmt.Sprintf(`
+			SELECT b.endpoint_id, b.bucket, b.sent_count, b.fail_count, b.avg_latency_ms
+			FROM %s b
+			WHERE b.bucket >= $%d AND b.bucket <= $%d%s`, viewName, startPos, endPos, endpointClause("b"))
+	}
+
+	widthSec := int64(bucketWidth / time.Second)
+	return fmt.Sprintf(`
+			SELECT
+				b.endpoint_id,
+				b.bucket,
+				b.sent_count - COALESCE(m.sent_count, 0) AS sent_count,
+				b.fail_count - COALESCE(m.fail_count, 0) AS fail_count,
+				b.avg_latency_ms
+			FROM %s b
+			LEFT JOIN (
+				SELECT
+					pr.endpoint_id,
+					time_bucket(INTERVAL '%d seconds', pr.ts) AS bucket,
+					COUNT(*)::BIGINT AS sent_count,
+					COUNT(*) FILTER (WHERE NOT pr.success)::BIGINT AS fail_count
+				FROM ping_raw pr
+				WHERE pr.maintenance
+				  AND pr.ts >= $%d
+				  AND pr.ts < $%d::timestamptz + INTERVAL '%d seconds'%s
+				GROUP BY 1, 2
+			) m ON m.endpoint_id = b.endpoint_id AND m.bucket = b.bucket
+			WHERE b.bucket >= $%d AND b.bucket <= $%d%s`,
+		viewName, widthSec, startPos, endPos, widthSec, endpointClause("pr"), startPos, endPos, endpointClause("b"))
+}
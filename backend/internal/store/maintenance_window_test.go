@@ -0,0 +1,47 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestBuildPingResultWriteValuesMaintenance(t *testing.T) {
+	result := model.PingResult{EndpointID: 1, Success: false, ErrorCode: "Host Unreachable"}
+
+	values := buildPingResultWriteValues(result, false)
+	if values.status != "Host Unreachable" || values.maintenance || values.statsQuery() != upsertEndpointStatsCurrentSQL {
+		t.Fatalf("unexpected regular values: %+v", values)
+	}
+
+	values = buildPingResultWriteValues(result, true)
+	if values.status != model.PingStatusMaintenance || !values.maintenance || values.statsQuery() != upsertEndpointStatsMaintenanceSQL {
+		t.Fatalf("unexpected maintenance values: %+v", values)
+	}
+	if strings.Contains(upsertEndpointStatsMaintenanceSQL, "failed_count") {
+		t.Fatalf("maintenance upsert must not touch failure counters")
+	}
+}
+
+func TestRangeBucketsSQLWithoutMaintenanceKeepsPlainScan(t *testing.T) {
+	sql := rangeBucketsSQL("ping_1h", time.Hour, 3, 4, 0, false)
+	if !strings.Contains(sql, "FROM ping_1h b") || strings.Contains(sql, "ping_raw") || strings.Contains(sql, "ANY(") {
+		t.Fatalf("unexpected plain bucket scan: %s", sql)
+	}
+
+	sql = rangeBucketsSQL("ping_1h", time.Hour, 3, 4, 0, true)
+	if !strings.Contains(sql, "time_bucket(INTERVAL '3600 seconds', pr.ts)") || !strings.Contains(sql, "pr.ts < $4::timestamptz + INTERVAL '3600 seconds'") {
+		t.Fatalf("unexpected maintenance bucket scan: %s", sql)
+	}
+}
+
+func TestFleetKPIsFromStatusRowsMaintenanceIsNotDown(t *testing.T) {
+	kpis := fleetKPIsFromStatusRows([]fleetKPIStatusRow{
+		{Status: model.PingStatusMaintenance, Endpoints: 2, TotalSent: 20},
+	})
+	if kpis.MonitoredEndpoints != 2 || kpis.DownEndpoints != 0 {
+		t.Fatalf("unexpected counts: %+v", kpis)
+	}
+}
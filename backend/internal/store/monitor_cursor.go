@@ -0,0 +1,275 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"sonarscope/backend/internal/model"
+)
+
+var ErrInvalidMonitorCursor = errors.New("cursor is invalid or does not match the current sort")
+
+// monitorCursor is the decoded next_cursor token: the sort it was issued
+// for and the keys of the last row on the page. A nil SortKey or empty IP
+// stands for SQL NULL.
+type monitorCursor struct {
+	Field   string  `json:"f,omitempty"`
+	Dir     string  `json:"d,omitempty"`
+	SortKey *string `json:"k,omitempty"`
+	IP      string  `json:"ip,omitempty"`
+	ID      int64   `json:"id"`
+}
+
+func encodeMonitorCursor(cursor monitorCursor) string {
+	payload, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func decodeMonitorCursor(token string) (monitorCursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil {
+		return monitorCursor{}, ErrInvalidMonitorCursor
+	}
+	var cursor monitorCursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.ID <= 0 {
+		return monitorCursor{}, ErrInvalidMonitorCursor
+	}
+	return cursor, nil
+}
+
+type monitorKeysetKey struct {
+	expr string
+	cast string
+	// notNull marks an IS NOT NULL key, whose cursor value is inverted.
+	notNull bool
+	// fallback stands in for NULL on both sides of the resume comparison,
+	// so the row comparison never meets a NULL. The IS NULL key in front of
+	// a nullable key keeps NULL rows apart from ones holding the fallback.
+	fallback string
+}
+
+func (k monitorKeysetKey) sortExpr() string {
+	if k.fallback == "" {
+		return k.expr
+	}
+	return "COALESCE(" + k.expr + ", " + k.fallback + ")"
+}
+
+func (k monitorKeysetKey) position(arg int) string {
+	if k.fallback == "" {
+		return fmt.Sprintf("$%d::%s", arg, k.cast)
+	}
+	return fmt.Sprintf("COALESCE($%d::%s, %s)", arg, k.cast, k.fallback)
+}
+
+// monitorKeysetFallbacks are the NULL stand-ins per cursor type. Any value
+// works since only NULL rows use it.
+var monitorKeysetFallbacks = map[string]string{
+	"text":             "''",
+	"inet":             "'0.0.0.0'::inet",
+	"bigint":           "0",
+	"double precision": "0",
+	"timestamptz":      "'-infinity'::timestamptz",
+}
+
+// monitorKeyset orders live monitor rows by an optional sort expression and
+// then ie.ip and ie.id, so every row has a distinct position to resume from.
+// Every key runs in the sort direction, so the resume predicate is a single
+// row comparison the planner can match to an index; NULL placement comes
+// from an IS NULL or IS NOT NULL key picked to land NULLs where the sort
+// wants them. The default order is covered by idx_inventory_monitor_keyset.
+type monitorKeyset struct {
+	field      string
+	dir        string
+	expression string
+	keys       []monitorKeysetKey
+	values     []any
+	limit      int
+
+	// sortKeys and ips collect the cursor columns of each scanned row.
+	sortKeys []*string
+	ips      []*string
+}
+
+// monitorKeysetNullKey returns the boolean key that orders expr's NULLs
+// first or last when sorted in the keyset direction.
+func monitorKeysetNullKey(expr string, desc, nullsFirst bool) monitorKeysetKey {
+	if nullsFirst == desc {
+		return monitorKeysetKey{expr: "((" + expr + ") IS NULL)", cast: "boolean"}
+	}
+	return monitorKeysetKey{expr: "((" + expr + ") IS NOT NULL)", cast: "boolean", notNull: true}
+}
+
+func newMonitorKeyset(criteria []MonitorSortCriterion, expressionForField func(string) (monitorSortDefinition, error), limit int, token string) (*monitorKeyset, error) {
+	keyset := &monitorKeyset{limit: limit, dir: "asc"}
+	normalized := normalizeMonitorSortCriteria(criteria)
+	if len(normalized) > 1 {
+		return nil, errors.New("cursor pagination supports at most one sort column")
+	}
+	var definition monitorSortDefinition
+	for _, criterion := range normalized {
+		var err error
+		definition, err = expressionForField(criterion.Field)
+		if err != nil {
+			return nil, err
+		}
+		if definition.Expression == "" {
+			continue
+		}
+		keyset.field = criterion.Field
+		keyset.dir = criterion.Dir
+		keyset.expression = definition.Expression
+	}
+	desc := keyset.dir == "desc"
+	if keyset.expression != "" {
+		cast := definition.CursorType
+		if cast == "" {
+			cast = "text"
+		}
+		nullsFirst := definition.NullsFirstWhenAsc && !desc
+		keyset.keys = append(keyset.keys,
+			monitorKeysetNullKey(keyset.expression, desc, nullsFirst),
+			monitorKeysetKey{expr: keyset.expression, cast: cast, fallback: monitorKeysetFallbacks[cast]},
+		)
+	} else {
+		keyset.field = ""
+		keyset.dir = ""
+	}
+	keyset.keys = append(keyset.keys,
+		monitorKeysetNullKey("ie.ip", desc, false),
+		monitorKeysetKey{expr: "ie.ip", cast: "inet", fallback: monitorKeysetFallbacks["inet"]},
+		monitorKeysetKey{expr: "ie.id", cast: "bigint"},
+	)
+
+	if token == "" {
+		return keyset, nil
+	}
+	cursor, err := decodeMonitorCursor(token)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.Field != keyset.field || cursor.Dir != keyset.dir {
+		return nil, ErrInvalidMonitorCursor
+	}
+	if keyset.expression != "" {
+		keyset.values = append(keyset.values, monitorKeysetNullValue(keyset.keys[0], cursor.SortKey == nil), cursor.SortKey)
+	}
+	var ip any
+	if cursor.IP != "" {
+		ip = cursor.IP
+	}
+	ipNullKey := keyset.keys[len(keyset.keys)-3]
+	keyset.values = append(keyset.values, monitorKeysetNullValue(ipNullKey, cursor.IP == ""), ip, cursor.ID)
+	return keyset, nil
+}
+
+// monitorKeysetNullValue is the cursor value of a NULL key for a row whose
+// key is NULL or not.
+func monitorKeysetNullValue(key monitorKeysetKey, isNull bool) bool {
+	return isNull != key.notNull
+}
+
+func (k *monitorKeyset) orderClause() string {
+	dir := "ASC"
+	if k.dir == "desc" {
+		dir = "DESC"
+	}
+	parts := make([]string, 0, len(k.keys))
+	for _, key := range k.keys {
+		parts = append(parts, key.sortExpr()+" "+dir)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// whereClause appends the resume predicate to whereClause, which always
+// starts with " WHERE". It is a no-op for the first page.
+func (k *monitorKeyset) whereClause(whereClause string, args []any) (string, []any) {
+	if len(k.values) == 0 {
+		return whereClause, args
+	}
+
+	exprs := make([]string, len(k.keys))
+	positions := make([]string, len(k.keys))
+	for i, key := range k.keys {
+		args = append(args, k.values[i])
+		exprs[i] = key.sortExpr()
+		positions[i] = key.position(len(args))
+	}
+	op := ">"
+	if k.dir == "desc" {
+		op = "<"
+	}
+	return whereClause + " AND (" + strings.Join(exprs, ", ") + ") " + op + " (" + strings.Join(positions, ", ") + ")", args
+}
+
+// selectColumns are appended to the live SELECT list so the last row of a
+// page can be turned back into a cursor.
+func (k *monitorKeyset) selectColumns() string {
+	sortKey := "NULL::text"
+	if k.expression != "" {
+		sortKey = "(" + k.expression + ")::text"
+	}
+	return ",\n\t\t\t\t" + sortKey + " AS cursor_sort_key,\n\t\t\t\tie.ip::text AS cursor_ip"
+}
+
+func (k *monitorKeyset) cursorAt(index int, endpointID int64) string {
+	cursor := monitorCursor{Field: k.field, Dir: k.dir, ID: endpointID}
+	if k.expression != "" {
+		cursor.SortKey = k.sortKeys[index]
+	}
+	if ip := k.ips[index]; ip != nil {
+		cursor.IP = *ip
+	}
+	return encodeMonitorCursor(cursor)
+}
+
+// ListMonitorEndpointsPageCursor returns one live page ordered by keyset
+// instead of OFFSET, plus the total match count and the cursor for the next
+// page ("" on the last page). Only a single sort column is supported.
+func (s *Store) ListMonitorEndpointsPageCursor(ctx context.Context, query MonitorPageQuery) ([]model.MonitorEndpoint, int64, string, error) {
+	if query.StatsScope == "range" {
+		return nil, 0, "", errors.New("cursor pagination is only available for live stats")
+	}
+	keyset, err := newMonitorKeyset(query.SortCriteria, s.monitorSortExpression, query.PageSize+1, query.Cursor)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	whereClause, args := buildMonitorWhereClause(
+		s.monitorStatus(),
+		query.Filters,
+		query.Hostname,
+		query.MAC,
+		query.CustomSearches,
+		query.IPList,
+		query.ExcludeEndpointIDs,
+		query.Search,
+	)
+
+	countSQL := `SELECT COUNT(*) FROM inventory_endpoint ie` + whereClause
+	var totalItems int64
+	if err := s.pool.QueryRow(ctx, countSQL, args...).Scan(&totalItems); err != nil {
+		return nil, 0, "", err
+	}
+
+	items := []model.MonitorEndpoint{}
+	err = s.scanMonitorEndpointsLiveKeyset(ctx, query, whereClause, args, keyset, func(item model.MonitorEndpoint) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	nextCursor := ""
+	if len(items) > query.PageSize {
+		items = items[:query.PageSize]
+		last := len(items) - 1
+		nextCursor = keyset.cursorAt(last, items[last].EndpointID)
+	}
+	return items, totalItems, nextCursor, nil
+}
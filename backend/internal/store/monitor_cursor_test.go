@@ -0,0 +1,83 @@
+package store
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMonitorKeysetOrderMatchesNullsPlacement(t *testing.T) {
+	keyset, err := newMonitorKeyset([]MonitorSortCriterion{{Field: "last_success_on", Dir: "asc"}}, (&Store{}).monitorSortExpression, 51, "")
+	if err != nil {
+		t.Fatalf("newMonitorKeyset error: %v", err)
+	}
+	want := "((es.last_success_on) IS NOT NULL) ASC, COALESCE(es.last_success_on, '-infinity'::timestamptz) ASC, ((ie.ip) IS NULL) ASC, COALESCE(ie.ip, '0.0.0.0'::inet) ASC, ie.id ASC"
+	if got := keyset.orderClause(); got != want {
+		t.Fatalf("orderClause = %q, want %q", got, want)
+	}
+
+	where, args := keyset.whereClause(" WHERE ie.is_active = TRUE", nil)
+	if where != " WHERE ie.is_active = TRUE" || len(args) != 0 {
+		t.Fatalf("first page should not add a resume predicate, got %q %v", where, args)
+	}
+}
+
+func TestMonitorKeysetDescendingKeepsOneDirection(t *testing.T) {
+	keyset, err := newMonitorKeyset([]MonitorSortCriterion{{Field: "last_failed_on", Dir: "desc"}}, (&Store{}).monitorSortExpression, 51, "")
+	if err != nil {
+		t.Fatalf("newMonitorKeyset error: %v", err)
+	}
+	want := "((es.last_failed_on) IS NOT NULL) DESC, COALESCE(es.last_failed_on, '-infinity'::timestamptz) DESC, ((ie.ip) IS NOT NULL) DESC, COALESCE(ie.ip, '0.0.0.0'::inet) DESC, ie.id DESC"
+	if got := keyset.orderClause(); got != want {
+		t.Fatalf("orderClause = %q, want %q", got, want)
+	}
+}
+
+func TestMonitorKeysetResumesAfterCursor(t *testing.T) {
+	key := "7"
+	token := encodeMonitorCursor(monitorCursor{Field: "failed_count", Dir: "desc", SortKey: &key, IP: "10.0.0.9", ID: 42})
+	keyset, err := newMonitorKeyset([]MonitorSortCriterion{{Field: "failed_count", Dir: "desc"}}, (&Store{}).monitorSortExpression, 51, token)
+	if err != nil {
+		t.Fatalf("newMonitorKeyset error: %v", err)
+	}
+
+	where, args := keyset.whereClause(" WHERE ie.is_active = TRUE", []any{"x"})
+	want := " WHERE ie.is_active = TRUE AND (" +
+		"((COALESCE(es.failed_count, 0)) IS NOT NULL), COALESCE(COALESCE(es.failed_count, 0), 0), ((ie.ip) IS NOT NULL), COALESCE(ie.ip, '0.0.0.0'::inet), ie.id" +
+		") < ($2::boolean, COALESCE($3::bigint, 0), $4::boolean, COALESCE($5::inet, '0.0.0.0'::inet), $6::bigint)"
+	if where != want {
+		t.Fatalf("resume predicate:\n got  %s\n want %s", where, want)
+	}
+	if len(args) != 6 || args[1] != true || *(args[2].(*string)) != "7" || args[3] != true || args[4] != "10.0.0.9" || args[5] != int64(42) {
+		t.Fatalf("unexpected resume args: %#v", args)
+	}
+}
+
+func TestMonitorKeysetNullIPCursor(t *testing.T) {
+	token := encodeMonitorCursor(monitorCursor{ID: 3})
+	keyset, err := newMonitorKeyset(nil, (&Store{}).monitorSortExpression, 51, token)
+	if err != nil {
+		t.Fatalf("newMonitorKeyset error: %v", err)
+	}
+	where, args := keyset.whereClause(" WHERE ie.is_active = TRUE", nil)
+	if !strings.Contains(where, "(((ie.ip) IS NULL), COALESCE(ie.ip, '0.0.0.0'::inet), ie.id) > ($1::boolean, COALESCE($2::inet, '0.0.0.0'::inet), $3::bigint)") {
+		t.Fatalf("unexpected default resume predicate: %s", where)
+	}
+	if len(args) != 3 || args[0] != true || args[1] != nil || args[2] != int64(3) {
+		t.Fatalf("unexpected resume args for hostname-only endpoint: %#v", args)
+	}
+}
+
+func TestMonitorKeysetRejectsMismatchedCursor(t *testing.T) {
+	token := encodeMonitorCursor(monitorCursor{Field: "failed_count", Dir: "desc", ID: 42})
+	if _, err := newMonitorKeyset([]MonitorSortCriterion{{Field: "failed_count", Dir: "asc"}}, (&Store{}).monitorSortExpression, 51, token); !errors.Is(err, ErrInvalidMonitorCursor) {
+		t.Fatalf("sort direction change should invalidate the cursor, got %v", err)
+	}
+	if _, err := newMonitorKeyset(nil, (&Store{}).monitorSortExpression, 51, "not-a-cursor"); !errors.Is(err, ErrInvalidMonitorCursor) {
+		t.Fatalf("garbage cursor should be rejected, got %v", err)
+	}
+	criteria := []MonitorSortCriterion{{Field: "failed_count", Dir: "asc"}, {Field: "success_count", Dir: "asc"}}
+	if _, err := newMonitorKeyset(criteria, (&Store{}).monitorSortExpression, 51, ""); err == nil {
+		t.Fatalf("multi-column sort should be rejected")
+	}
+}
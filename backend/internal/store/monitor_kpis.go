@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+type fleetKPIStatusRow struct {
+	Status    string
+	Endpoints int64
+	// Down is how many of Endpoints are down by monitorStatusSQL.
+	Down          int64
+	FailedCount   int64
+	TotalSent     int64
+	LatencySum    *float64
+	LatencyWeight int64
+}
+
+func (s *Store) MonitorFleetKPIs(ctx context.Context, query MonitorPageQuery) (model.MonitorFleetKPIs, error) {
+	whereClause, args := buildMonitorWhereClause(
+		s.monitorStatus(),
+		query.Filters,
+		query.Hostname,
+		query.MAC,
+		query.CustomSearches,
+		query.IPList,
+		query.ExcludeEndpointIDs,
+		query.Search,
+	)
+
+	rows, err := s.pool.Query(ctx, buildFleetKPIQuery(s.liveStatusExpression(), s.monitorStatus().isDown("es"), whereClause), args...)
+	if err != nil {
+		return model.MonitorFleetKPIs{}, err
+	}
+	defer rows.Close()
+
+	statusRows := []fleetKPIStatusRow{}
+	for rows.Next() {
+		var row fleetKPIStatusRow
+		if err := rows.Scan(&row.Status, &row.Endpoints, &row.Down, &row.FailedCount, &row.TotalSent, &row.LatencySum, &row.LatencyWeight); err != nil {
+			return model.MonitorFleetKPIs{}, err
+		}
+		statusRows = append(statusRows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return model.MonitorFleetKPIs{}, err
+	}
+
+	kpis := fleetKPIsFromStatusRows(statusRows)
+	kpis.GeneratedAt = time.Now().UTC()
+	return kpis, nil
+}
+
+func buildFleetKPIQuery(statusExpr string, isDownExpr string, whereClause string) string {
+	return fmt.Sprintf(`
+		WITH scoped AS (
+			SELECT
+				%s AS status,
+				%s AS is_down,
+				COALESCE(es.failed_count, 0) AS failed_count,
+				COALESCE(es.success_count, 0) AS success_count,
+				es.average_latency
+			FROM inventory_endpoint ie
+			LEFT JOIN endpoint_stats_current es ON es.endpoint_id = ie.id
+			%s
+		)
+		SELECT
+			status,
+			COUNT(*)::BIGINT,
+			COUNT(*) FILTER (WHERE is_down)::BIGINT,
+			COALESCE(SUM(failed_count), 0)::BIGINT,
+			COALESCE(SUM(success_count + failed_count), 0)::BIGINT,
+			SUM(average_latency * success_count) FILTER (WHERE average_latency IS NOT NULL),
+			COALESCE(SUM(success_count) FILTER (WHERE average_latency IS NOT NULL), 0)::BIGINT
+		FROM scoped
+		GROUP BY status
+	`, statusExpr, isDownExpr, whereClause)
+}
+
+func fleetKPIsFromStatusRows(rows []fleetKPIStatusRow) model.MonitorFleetKPIs {
+	kpis := model.MonitorFleetKPIs{StatusCounts: map[string]int64{}}
+	var failed, sent, latencyWeight int64
+	var latencySum float64
+	for _, row := range rows {
+		kpis.StatusCounts[row.Status] += row.Endpoints
+		kpis.TotalEndpoints += row.Endpoints
+		failed += row.FailedCount
+		sent += row.TotalSent
+		if row.LatencySum != nil {
+			latencySum += *row.LatencySum
+			latencyWeight += row.LatencyWeight
+		}
+
+		kpis.DownEndpoints += row.Down
+
+		switch row.Status {
+		case model.PingStatusNeverProbed, model.PingStatusStale, model.PingStatusDisabled:
+			continue
+		}
+		kpis.MonitoredEndpoints += row.Endpoints
+	}
+
+	if kpis.TotalEndpoints > 0 {
+		kpis.MonitoredRatio = float64(kpis.MonitoredEndpoints) / float64(kpis.TotalEndpoints)
+	}
+	if sent > 0 {
+		kpis.FleetLossPct = float64(failed) / float64(sent) * 100
+	}
+	if latencyWeight > 0 {
+		avg := latencySum / float64(latencyWeight)
+		kpis.AvgLatencyMs = &avg
+	}
+	return kpis
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestFleetKPIsFromStatusRows(t *testing.T) {
+	latency := 30.0
+	rows := []fleetKPIStatusRow{
+		{Status: "Succeeded", Endpoints: 6, FailedCount: 2, TotalSent: 100, LatencySum: &latency, LatencyWeight: 10},
+		{Status: "Request Timeout", Endpoints: 2, Down: 2, FailedCount: 18, TotalSent: 20},
+		{Status: model.PingStatusStale, Endpoints: 1, FailedCount: 0, TotalSent: 30},
+		{Status: model.PingStatusNeverProbed, Endpoints: 1},
+	}
+
+	kpis := fleetKPIsFromStatusRows(rows)
+	if kpis.TotalEndpoints != 10 || kpis.MonitoredEndpoints != 8 || kpis.DownEndpoints != 2 {
+		t.Fatalf("unexpected counts: %+v", kpis)
+	}
+	if kpis.MonitoredRatio != 0.8 {
+		t.Fatalf("monitored ratio = %v, want 0.8", kpis.MonitoredRatio)
+	}
+	if kpis.FleetLossPct != 20.0/150.0*100 {
+		t.Fatalf("fleet loss = %v", kpis.FleetLossPct)
+	}
+	if kpis.AvgLatencyMs == nil || *kpis.AvgLatencyMs != 3 {
+		t.Fatalf("avg latency = %v, want 3", kpis.AvgLatencyMs)
+	}
+	if kpis.StatusCounts["Request Timeout"] != 2 || kpis.StatusCounts[model.PingStatusNeverProbed] != 1 {
+		t.Fatalf("unexpected status counts: %#v", kpis.StatusCounts)
+	}
+}
+
+func TestFleetKPIsFromStatusRowsCountsDownByThreshold(t *testing.T) {
+	// A failed last probe below down_threshold, and a warming-up endpoint,
+	// are not down; only the rows' is_down count is.
+	kpis := fleetKPIsFromStatusRows([]fleetKPIStatusRow{
+		{Status: "Request Timeout", Endpoints: 3, Down: 1, FailedCount: 3, TotalSent: 30},
+		{Status: model.PingStatusWarmingUp, Endpoints: 3, FailedCount: 3, TotalSent: 3},
+	})
+	if kpis.MonitoredEndpoints != 6 || kpis.DownEndpoints != 1 {
+		t.Fatalf("unexpected counts: %+v", kpis)
+	}
+}
+
+func TestFleetKPIsFromStatusRowsEmpty(t *testing.T) {
+	kpis := fleetKPIsFromStatusRows(nil)
+	if kpis.TotalEndpoints != 0 || kpis.MonitoredRatio != 0 || kpis.AvgLatencyMs != nil || kpis.StatusCounts == nil {
+		t.Fatalf("unexpected empty kpis: %+v", kpis)
+	}
+}
+
+func TestBuildFleetKPIQueryUsesSingleStatsPass(t *testing.T) {
+	sql := buildFleetKPIQuery("es.last_ping_status", monitorStatusSQL{}.isDown("es"), " WHERE ie.is_active = TRUE AND ie.vlan = ANY($1)")
+	if !strings.Contains(sql, "LEFT JOIN endpoint_stats_current es") || !strings.Contains(sql, "GROUP BY status") {
+		t.Fatalf("unexpected kpi query: %s", sql)
+	}
+	if strings.Contains(sql, "ping_raw") {
+		t.Fatalf("kpi query should not scan ping_raw: %s", sql)
+	}
+}
+
+func TestBuildGroupSummaryQuery(t *testing.T) {
+	sql := buildGroupSummaryQuery(monitorStatusSQL{}, " WHERE ie.is_active = TRUE AND ie.vlan = ANY($1)", 0)
+	for _, want := range []string{
+		"FROM group_def g",
+		"INNER JOIN group_member gm ON gm.endpoint_id = ie.id",
+		"WHERE ie.is_active = TRUE AND ie.vlan = ANY($1)",
+		">= " + monitorDownThresholdSQL,
+		"DISTINCT ON (group_id)",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Fatalf("group summary query missing %q: %s", want, sql)
+		}
+	}
+	if strings.Contains(sql, "g.name = ANY") {
+		t.Fatalf("unfiltered group summary should list every group: %s", sql)
+	}
+
+	if sql := buildGroupSummaryQuery(monitorStatusSQL{}, " WHERE ie.is_active = TRUE", 2); !strings.Contains(sql, "WHERE g.name = ANY($2)") {
+		t.Fatalf("group filter should limit listed groups: %s", sql)
+	}
+}
+
+func TestFleetKPIsFromStatusRowsDisabledIsNotMonitored(t *testing.T) {
+	kpis := fleetKPIsFromStatusRows([]fleetKPIStatusRow{
+		{Status: "Succeeded", Endpoints: 4, TotalSent: 40},
+		{Status: model.PingStatusDisabled, Endpoints: 2, FailedCount: 5, TotalSent: 10},
+	})
+	if kpis.TotalEndpoints != 6 || kpis.MonitoredEndpoints != 4 || kpis.DownEndpoints != 0 {
+		t.Fatalf("unexpected counts: %+v", kpis)
+	}
+	if kpis.StatusCounts[model.PingStatusDisabled] != 2 {
+		t.Fatalf("unexpected status counts: %#v", kpis.StatusCounts)
+	}
+}
+
+func TestLiveLastPingStatusExpressionChecksMonitoringEnabledFirst(t *testing.T) {
+	expr := liveLastPingStatusExpression(5*time.Minute, time.Minute, 3)
+	disabled := strings.Index(expr, "WHEN NOT ie.monitoring_enabled THEN 'disabled'")
+	if disabled < 0 {
+		t.Fatalf("expression does not report disabled endpoints: %s", expr)
+	}
+	if never := strings.Index(expr, "'never_probed'"); never >= 0 && never < disabled {
+		t.Fatalf("disabled branch must come before never_probed: %s", expr)
+	}
+}
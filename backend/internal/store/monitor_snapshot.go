@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/model"
+)
+
+var ErrMonitorSnapshotNotFound = errors.New("monitor snapshot not found")
+
+func (s *Store) SaveMonitorSnapshot(ctx context.Context, snapshot model.MonitorSnapshot) error {
+	document, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO monitor_snapshot(id, version, captured_at, document)
+		VALUES ($1, $2, $3, $4::jsonb)
+	`, snapshot.SnapshotID, snapshot.Version, snapshot.CapturedAt, document)
+	return err
+}
+
+func (s *Store) GetMonitorSnapshot(ctx context.Context, snapshotID string) (model.MonitorSnapshot, error) {
+	var document []byte
+	err := s.pool.QueryRow(ctx, `
+		SELECT document
+		FROM monitor_snapshot
+		WHERE id = $1
+	`, snapshotID).Scan(&document)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.MonitorSnapshot{}, ErrMonitorSnapshotNotFound
+		}
+		return model.MonitorSnapshot{}, err
+	}
+
+	var snapshot model.MonitorSnapshot
+	if err := json.Unmarshal(document, &snapshot); err != nil {
+		return model.MonitorSnapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// DeleteMonitorSnapshotsBefore drops snapshots captured before cutoff and
+// returns how many were removed.
+func (s *Store) DeleteMonitorSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM monitor_snapshot
+		WHERE captured_at < $1
+	`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	MonitorStatusUp       = "up"
+	MonitorStatusDown     = "down"
+	MonitorStatusFlapping = "flapping"
+)
+
+// An endpoint is flapping when its last monitorFlappingProbes probes within
+// monitorFlappingWindow switch between success and failure at least
+// monitorFlappingMinTransitions times.
+const (
+	monitorFlappingProbes         = 20
+	monitorFlappingMinTransitions = 4
+	monitorFlappingWindow         = "1 hour"
+)
+
+// monitorDownThresholdSQL is the configured consecutive-failure count at
+// which an endpoint reads as down.
+const monitorDownThresholdSQL = `(SELECT down_threshold FROM app_settings WHERE id = TRUE)`
+
+// monitorStatusSQL is the one definition of up and down shared by the
+// status filter, the is_down column on live and range rows, the group
+// summary and the fleet KPIs. An endpoint is down when monitoring is
+// enabled, its current failure streak has reached down_threshold and it is
+// past the new-endpoint grace; a failing endpoint still in grace is
+// neither. It is up when monitoring is enabled, it has been probed and its
+// streak is below down_threshold. Disabled and never-probed endpoints are
+// neither up nor down.
+type monitorStatusSQL struct {
+	gracePeriod     time.Duration
+	graceMinSamples int
+}
+
+func (s *Store) monitorStatus() monitorStatusSQL {
+	return monitorStatusSQL{gracePeriod: s.downGracePeriod, graceMinSamples: s.downGraceMinSamples}
+}
+
+// failing reports the streak rule alone; es is the endpoint_stats_current
+// alias.
+func (m monitorStatusSQL) failing(es string) string {
+	return fmt.Sprintf(`(ie.monitoring_enabled AND COALESCE(%s.consecutive_failed_count, 0) >= %s)`, es, monitorDownThresholdSQL)
+}
+
+func (m monitorStatusSQL) isDown(es string) string {
+	rules := downGraceRules(es, m.gracePeriod, m.graceMinSamples)
+	if len(rules) == 0 {
+		return m.failing(es)
+	}
+	return fmt.Sprintf(`(%s AND NOT COALESCE(%s, FALSE))`, m.failing(es), strings.Join(rules, " OR "))
+}
+
+func (m monitorStatusSQL) isUp(es string) string {
+	return fmt.Sprintf(`(ie.monitoring_enabled AND COALESCE(%s.total_sent_ping, 0) > 0 AND NOT %s)`, es, m.failing(es))
+}
+
+// downGraceRules are the conditions under which a failing endpoint is
+// still in the new-endpoint grace.
+func downGraceRules(es string, gracePeriod time.Duration, graceMinSamples int) []string {
+	rules := []string{}
+	if graceSec := int64(gracePeriod / time.Second); graceSec > 0 {
+		rules = append(rules, fmt.Sprintf("ie.created_at > now() - make_interval(secs => %d)", graceSec))
+	}
+	if graceMinSamples > 0 {
+		rules = append(rules, fmt.Sprintf("%s.total_sent_ping < %d", es, graceMinSamples))
+	}
+	return rules
+}
+
+// clause returns the WHERE fragment for a status filter. Up and down follow
+// monitorStatusSQL; flapping counts outcome changes over recent raw probes.
+func (m monitorStatusSQL) clause(status string) string {
+	statusRow := func(condition string) string {
+		return fmt.Sprintf(`
+			AND EXISTS (
+				SELECT 1
+				FROM endpoint_stats_current es_status
+				WHERE es_status.endpoint_id = ie.id
+				  AND %s
+			)`, condition)
+	}
+	switch status {
+	case MonitorStatusDown:
+		return statusRow(m.isDown("es_status"))
+	case MonitorStatusUp:
+		return statusRow(m.isUp("es_status"))
+	case MonitorStatusFlapping:
+		return fmt.Sprintf(`
+			AND (
+				SELECT COUNT(*)
+				FROM (
+					SELECT recent.success <> lag(recent.success) OVER (ORDER BY recent.ts) AS changed
+					FROM (
+						SELECT pr.ts, pr.success
+						FROM ping_raw pr
+						WHERE pr.endpoint_id = ie.id
+						  AND pr.ts >= now() - INTERVAL '%s'
+						ORDER BY pr.ts DESC
+						LIMIT %d
+					) recent
+				) transitions
+				WHERE transitions.changed
+			) >= %d`, monitorFlappingWindow, monitorFlappingProbes, monitorFlappingMinTransitions)
+	}
+	return ""
+}
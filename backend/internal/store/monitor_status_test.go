@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+// TestMonitorStatusAgreesAcrossViews seeds one endpoint per state and checks
+// that the status filter, the is_down column on live and range rows, the
+// group summary and the fleet KPIs all count the same endpoints as up and
+// down.
+func TestMonitorStatusAgreesAcrossViews(t *testing.T) {
+	s, _ := testPingStore(t)
+	ctx := context.Background()
+
+	var threshold int64
+	if err := s.pool.QueryRow(ctx, `SELECT down_threshold FROM app_settings WHERE id = TRUE`).Scan(&threshold); err != nil {
+		t.Fatalf("read down_threshold: %v", err)
+	}
+	s.SetDownGrace(10*time.Minute, int(threshold)+1)
+
+	suffix := time.Now().UnixNano()
+	groupName := fmt.Sprintf("status-test-%d", suffix)
+	var groupID int64
+	if err := s.pool.QueryRow(ctx, `INSERT INTO group_def(name) VALUES ($1) RETURNING id`, groupName).Scan(&groupID); err != nil {
+		t.Fatalf("insert group: %v", err)
+	}
+	t.Cleanup(func() { _, _ = s.pool.Exec(context.Background(), `DELETE FROM group_def WHERE id = $1`, groupID) })
+
+	type seed struct {
+		name        string
+		age         time.Duration
+		monitoring  bool
+		consecutive int64
+		totalSent   int64
+		probed      bool
+	}
+	seeds := []seed{
+		{name: "down", age: time.Hour, monitoring: true, consecutive: threshold, totalSent: 100, probed: true},
+		{name: "up", age: time.Hour, monitoring: true, totalSent: 100, probed: true},
+		{name: "young", age: time.Minute, monitoring: true, consecutive: threshold, totalSent: 100, probed: true},
+		{name: "few_samples", age: time.Hour, monitoring: true, consecutive: threshold, totalSent: threshold, probed: true},
+		{name: "disabled", age: time.Hour, consecutive: threshold, totalSent: 100, probed: true},
+		{name: "never_probed", age: time.Hour, monitoring: true},
+	}
+	ids := map[string]int64{}
+	for i, endpoint := range seeds {
+		var id int64
+		ip := fmt.Sprintf("198.19.%d.%d", suffix%250, i+1)
+		if err := s.pool.QueryRow(ctx, `
+			INSERT INTO inventory_endpoint(ip, hostname, monitoring_enabled, created_at)
+			VALUES ($1, $2, $3, now() - make_interval(secs => $4))
+			RETURNING id
+		`, ip, endpoint.name, endpoint.monitoring, endpoint.age.Seconds()).Scan(&id); err != nil {
+			t.Fatalf("insert %s: %v", endpoint.name, err)
+		}
+		t.Cleanup(func() { _, _ = s.pool.Exec(context.Background(), `DELETE FROM inventory_endpoint WHERE id = $1`, id) })
+		if _, err := s.pool.Exec(ctx, `INSERT INTO group_member(group_id, endpoint_id) VALUES ($1, $2)`, groupID, id); err != nil {
+			t.Fatalf("add %s to group: %v", endpoint.name, err)
+		}
+		if endpoint.probed {
+			if _, err := s.pool.Exec(ctx, `
+				INSERT INTO endpoint_stats_current(endpoint_id, consecutive_failed_count, total_sent_ping, failed_count, success_count, last_ping_status)
+				VALUES ($1, $2, $3, $2, $3 - $2, CASE WHEN $2 > 0 THEN 'Request Timeout' ELSE 'Succeeded' END)
+			`, id, endpoint.consecutive, endpoint.totalSent); err != nil {
+				t.Fatalf("insert stats for %s: %v", endpoint.name, err)
+			}
+		}
+		ids[endpoint.name] = id
+	}
+
+	scope := MonitorPageQuery{Filters: MonitorFilters{GroupNames: []string{groupName}}, Page: 1, PageSize: 50}
+	names := func(items []model.MonitorEndpoint, keep func(model.MonitorEndpoint) bool) []string {
+		out := []string{}
+		for _, item := range items {
+			if keep(item) {
+				out = append(out, item.Hostname)
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+	all := func(model.MonitorEndpoint) bool { return true }
+	isDown := func(item model.MonitorEndpoint) bool { return item.IsDown }
+	expect := func(label string, got []string, want ...string) {
+		t.Helper()
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("%s = %v, want %v", label, got, want)
+		}
+	}
+
+	for _, status := range []string{MonitorStatusDown, MonitorStatusUp} {
+		query := scope
+		query.Filters.Status = status
+		items, total, err := s.ListMonitorEndpointsPage(ctx, query)
+		if err != nil {
+			t.Fatalf("ListMonitorEndpointsPage(status=%s) error = %v", status, err)
+		}
+		expect("status="+status, names(items, all), status)
+		if total != 1 {
+			t.Fatalf("status=%s total = %d, want 1", status, total)
+		}
+	}
+
+	live, _, err := s.ListMonitorEndpointsPage(ctx, scope)
+	if err != nil {
+		t.Fatalf("ListMonitorEndpointsPage(live) error = %v", err)
+	}
+	expect("live is_down", names(live, isDown), "down")
+
+	rangeQuery := scope
+	rangeQuery.StatsScope = "range"
+	rangeQuery.End = time.Now()
+	rangeQuery.Start = rangeQuery.End.Add(-time.Hour)
+	ranged, _, err := s.ListMonitorEndpointsPage(ctx, rangeQuery)
+	if err != nil {
+		t.Fatalf("ListMonitorEndpointsPage(range) error = %v", err)
+	}
+	expect("range is_down", names(ranged, isDown), "down")
+
+	summaries, err := s.MonitorGroupSummaries(ctx, scope)
+	if err != nil {
+		t.Fatalf("MonitorGroupSummaries() error = %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].GroupID != groupID {
+		t.Fatalf("MonitorGroupSummaries() = %+v, want only the test group", summaries)
+	}
+	if summary := summaries[0]; summary.TotalEndpoints != int64(len(seeds)) || summary.UpEndpoints != 1 || summary.DownEndpoints != 1 {
+		t.Fatalf("group summary = %+v, want %d endpoints with 1 up and 1 down", summary, len(seeds))
+	}
+
+	kpis, err := s.MonitorFleetKPIs(ctx, scope)
+	if err != nil {
+		t.Fatalf("MonitorFleetKPIs() error = %v", err)
+	}
+	if kpis.TotalEndpoints != int64(len(seeds)) || kpis.DownEndpoints != 1 {
+		t.Fatalf("fleet kpis = %+v, want %d endpoints with 1 down", kpis, len(seeds))
+	}
+}
+
+func TestMonitorStatusClauseEmptyStatus(t *testing.T) {
+	if got := (monitorStatusSQL{}).clause(""); got != "" {
+		t.Fatalf("empty status clause = %q, want none", got)
+	}
+}
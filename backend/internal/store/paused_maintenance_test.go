@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResumePausedMaintenanceJobsAfterAbortedDelete(t *testing.T) {
+	st, _ := testPingStore(t)
+	ctx := context.Background()
+
+	paused, err := st.PauseMaintenanceJobs(ctx)
+	if err != nil {
+		t.Fatalf("PauseMaintenanceJobs() error = %v", err)
+	}
+	t.Cleanup(func() { _ = st.ResumeJobs(context.Background(), paused) })
+	if len(paused) == 0 {
+		t.Skip("no scheduled TimescaleDB policy jobs to pause")
+	}
+
+	// The delete dies here without calling ResumeJobs; a fresh store stands in
+	// for the restarted process.
+	restarted := New(st.pool)
+	resumed, err := restarted.ResumePausedMaintenanceJobs(ctx)
+	if err != nil {
+		t.Fatalf("ResumePausedMaintenanceJobs() error = %v", err)
+	}
+	if len(resumed) != len(paused) {
+		t.Fatalf("resumed = %v, want %v", resumed, paused)
+	}
+
+	var unscheduled int
+	if err := st.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM timescaledb_information.jobs
+		WHERE job_id = ANY($1::INTEGER[]) AND NOT scheduled
+	`, paused).Scan(&unscheduled); err != nil {
+		t.Fatalf("count unscheduled jobs: %v", err)
+	}
+	if unscheduled != 0 {
+		t.Fatalf("%d jobs still unscheduled after resume", unscheduled)
+	}
+	if again, err := restarted.ResumePausedMaintenanceJobs(ctx); err != nil || len(again) != 0 {
+		t.Fatalf("second resume = %v, %v; want nothing left to resume", again, err)
+	}
+}
@@ -0,0 +1,375 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/model"
+)
+
+// The batch path writes a whole result batch with a handful of set-based
+// statements instead of three per probe. Inputs are bound as parallel arrays
+// and expanded with unnest, so the statement text does not depend on the
+// batch size.
+
+const pingRawBatchColumns = `
+	WITH v AS (
+		SELECT * FROM unnest(
+			$1::timestamptz[], $2::bigint[], $3::boolean[], $4::double precision[], $5::text[], $6::int[], $7::text[], $8::int[],
+			$9::int[], $10::text[], $11::double precision[], $12::double precision[], $13::double precision[], $14::double precision[],
+			$15::text[], $16::boolean[], $17::int[], $18::boolean[], $19::int[], $20::int[], $21::double precision[]
+		) AS v(ts, endpoint_id, success, latency_ms, reply_ip, ttl, error_code, payload_bytes, http_status, target_ip,
+			min_latency_ms, max_latency_ms, jitter_ms, loss_pct, fallback_probe, maintenance, duplicate_replies, ip_mismatch, retries, out_of_order_replies, drift_ms)
+	)
+	INSERT INTO ping_raw(ts, endpoint_id, success, latency_ms, reply_ip, ttl, error_code, payload_bytes, http_status, target_ip, min_latency_ms, max_latency_ms, jitter_ms, loss_pct, fallback_probe, maintenance, duplicate_replies, ip_mismatch, retries, out_of_order_replies, drift_ms)
+	SELECT
+		v.ts, v.endpoint_id, v.success, v.latency_ms, NULLIF(v.reply_ip, '')::inet, v.ttl, v.error_code,
+`
+
+const insertPingRawBatchSQL = pingRawBatchColumns + `		v.payload_bytes,
+		v.http_status, NULLIF(v.target_ip, '')::inet, v.min_latency_ms, v.max_latency_ms, v.jitter_ms, v.loss_pct, NULLIF(v.fallback_probe, ''), v.maintenance, v.duplicate_replies, v.ip_mismatch, v.retries, v.out_of_order_replies, v.drift_ms
+	FROM v
+	ON CONFLICT (ts, endpoint_id) DO NOTHING
+`
+
+const insertPingRawBatchOmitSettingsPayloadSQL = pingRawBatchColumns + `		NULLIF(v.payload_bytes, probe_payload_bytes_at(v.ts)),
+		v.http_status, NULLIF(v.target_ip, '')::inet, v.min_latency_ms, v.max_latency_ms, v.jitter_ms, v.loss_pct, NULLIF(v.fallback_probe, ''), v.maintenance, v.duplicate_replies, v.ip_mismatch, v.retries, v.out_of_order_replies, v.drift_ms
+	FROM v
+	ON CONFLICT (ts, endpoint_id) DO NOTHING
+`
+
+// upsertEndpointStatsBatchSQL applies one wave of results, at most one per
+// endpoint, with the same effect as upsertEndpointStatsCurrentSQL and
+// upsertEndpointStatsMaintenanceSQL row by row. The inserted row doubles as
+// the probe's delta: success_count and failed_count are 0/1 increments,
+// last_success_on/last_failed_on carry the timestamp, and total_sent_ping = 0
+// marks a maintenance probe, which leaves every counter alone.
+const upsertEndpointStatsBatchSQL = `
+	INSERT INTO endpoint_stats_current(
+		endpoint_id,
+		last_failed_on,
+		last_success_on,
+		success_count,
+		failed_count,
+		consecutive_failed_count,
+		max_consecutive_failed_count,
+		max_consecutive_failed_count_time,
+		failed_pct,
+		total_sent_ping,
+		last_ping_status,
+		last_ping_latency,
+		average_latency,
+		reply_ip_address,
+		last_ttl,
+		last_error_detail,
+		duplicate_reply_count,
+		last_ip_mismatch,
+		last_fallback_probe,
+		updated_at
+	)
+	SELECT
+		v.endpoint_id,
+		CASE WHEN NOT v.success AND NOT v.maintenance THEN v.ts END,
+		CASE WHEN v.success THEN v.ts END,
+		CASE WHEN v.success AND NOT v.maintenance THEN 1 ELSE 0 END,
+		CASE WHEN NOT v.success AND NOT v.maintenance THEN 1 ELSE 0 END,
+		CASE WHEN NOT v.success AND NOT v.maintenance THEN 1 ELSE 0 END,
+		CASE WHEN NOT v.success AND NOT v.maintenance THEN 1 ELSE 0 END,
+		CASE WHEN NOT v.success AND NOT v.maintenance THEN v.ts END,
+		CASE WHEN NOT v.success AND NOT v.maintenance THEN 100 ELSE 0 END,
+		CASE WHEN v.maintenance THEN 0 ELSE 1 END,
+		v.status,
+		v.latency,
+		CASE WHEN v.maintenance THEN NULL ELSE v.latency END,
+		NULLIF(v.reply_ip, '')::inet,
+		v.ttl,
+		v.error_detail,
+		CASE WHEN v.maintenance THEN 0 ELSE v.duplicate_replies END,
+		v.ip_mismatch,
+		v.fallback_probe,
+		now()
+	FROM unnest($1::bigint[], $2::boolean[], $3::timestamptz[], $4::text[], $5::double precision[], $6::text[], $7::int[], $8::boolean[], $9::text[], $10::int[], $11::boolean[], $12::text[])
+		AS v(endpoint_id, success, ts, status, latency, reply_ip, ttl, maintenance, error_detail, duplicate_replies, ip_mismatch, fallback_probe)
+	ON CONFLICT (endpoint_id) DO UPDATE SET
+		last_failed_on = COALESCE(EXCLUDED.last_failed_on, endpoint_stats_current.last_failed_on),
+		last_success_on = COALESCE(EXCLUDED.last_success_on, endpoint_stats_current.last_success_on),
+		success_count = endpoint_stats_current.success_count + EXCLUDED.success_count,
+		failed_count = endpoint_stats_current.failed_count + EXCLUDED.failed_count,
+		consecutive_failed_count = CASE
+			WHEN EXCLUDED.total_sent_ping = 0 THEN endpoint_stats_current.consecutive_failed_count
+			WHEN EXCLUDED.failed_count = 1 THEN endpoint_stats_current.consecutive_failed_count + 1
+			ELSE 0
+		END,
+		max_consecutive_failed_count = GREATEST(
+			endpoint_stats_current.max_consecutive_failed_count,
+			CASE WHEN EXCLUDED.failed_count = 1 THEN endpoint_stats_current.consecutive_failed_count + 1 ELSE endpoint_stats_current.max_consecutive_failed_count END
+		),
+		max_consecutive_failed_count_time = CASE
+			WHEN EXCLUDED.failed_count = 1 AND endpoint_stats_current.consecutive_failed_count + 1 > endpoint_stats_current.max_consecutive_failed_count THEN EXCLUDED.last_failed_on
+			ELSE endpoint_stats_current.max_consecutive_failed_count_time
+		END,
+		total_sent_ping = endpoint_stats_current.total_sent_ping + EXCLUDED.total_sent_ping,
+		failed_pct = CASE
+			WHEN EXCLUDED.total_sent_ping = 0 THEN endpoint_stats_current.failed_pct
+			ELSE (
+				(endpoint_stats_current.failed_count + EXCLUDED.failed_count)::DOUBLE PRECISION /
+				(endpoint_stats_current.success_count + endpoint_stats_current.failed_count + 1)::DOUBLE PRECISION
+			) * 100
+		END,
+		last_ping_status = EXCLUDED.last_ping_status,
+		last_ping_latency = EXCLUDED.last_ping_latency,
+		average_latency = CASE
+			WHEN EXCLUDED.success_count = 1 AND EXCLUDED.last_ping_latency IS NOT NULL THEN
+				(
+					(COALESCE(endpoint_stats_current.average_latency, 0) * endpoint_stats_current.success_count) + EXCLUDED.last_ping_latency
+				) / (endpoint_stats_current.success_count + 1)
+			ELSE endpoint_stats_current.average_latency
+		END,
+		reply_ip_address = EXCLUDED.reply_ip_address,
+		last_ttl = EXCLUDED.last_ttl,
+		last_error_detail = EXCLUDED.last_error_detail,
+		duplicate_reply_count = endpoint_stats_current.duplicate_reply_count + EXCLUDED.duplicate_reply_count,
+		last_ip_mismatch = EXCLUDED.last_ip_mismatch,
+		last_fallback_probe = EXCLUDED.last_fallback_probe,
+		updated_at = now()
+`
+
+// trackDowntimeIncidentsBatchSQL is trackDowntimeIncidentSQL for one wave.
+// With one row per endpoint a wave either closes or opens an endpoint's
+// incident, never both.
+const trackDowntimeIncidentsBatchSQL = `
+	WITH v AS (
+		SELECT * FROM unnest($1::bigint[], $2::boolean[], $3::timestamptz[], $4::text[], $5::boolean[])
+			AS v(endpoint_id, success, ts, error_code, maintenance)
+	),
+	closed AS (
+		UPDATE downtime_incident di
+		SET
+			ended_at = v.ts,
+			duration_sec = EXTRACT(EPOCH FROM (v.ts - di.started_at))
+		FROM v
+		WHERE v.success
+		  AND di.endpoint_id = v.endpoint_id
+		  AND di.ended_at IS NULL
+		  AND di.started_at <= v.ts
+		RETURNING di.id
+	)
+	INSERT INTO downtime_incident(endpoint_id, started_at, error_code)
+	SELECT v.endpoint_id, v.ts, v.error_code
+	FROM v
+	WHERE NOT v.success AND NOT v.maintenance
+	ON CONFLICT (endpoint_id) WHERE ended_at IS NULL DO NOTHING
+`
+
+func (s *Store) insertPingRawBatchQuery() string {
+	if s.omitSettingsPayloadRaw {
+		return insertPingRawBatchOmitSettingsPayloadSQL
+	}
+	return insertPingRawBatchSQL
+}
+
+// pingResultWaves splits results into consecutive waves that hold at most one
+// result per endpoint, keeping each endpoint's results in order: its first
+// result lands in wave 0, its second in wave 1, and so on. Each wave can then
+// be applied with one set-based upsert without a row being hit twice.
+func pingResultWaves(results []model.PingResult) [][]int {
+	seen := make(map[int64]int, len(results))
+	var waves [][]int
+	for i, result := range results {
+		wave := seen[result.EndpointID]
+		seen[result.EndpointID] = wave + 1
+		if wave == len(waves) {
+			waves = append(waves, nil)
+		}
+		waves[wave] = append(waves[wave], i)
+	}
+	return waves
+}
+
+type pingRawBatchArgs struct {
+	ts            []time.Time
+	endpointIDs   []int64
+	success       []bool
+	latency       []*float64
+	replyIP       []string
+	ttl           []*int
+	errorCode     []string
+	payloadBytes  []int
+	httpStatus    []*int
+	targetIP      []string
+	minLatency    []*float64
+	maxLatency    []*float64
+	jitter        []*float64
+	lossPct       []*float64
+	fallbackProbe []string
+	maintenance   []bool
+	duplicates    []int
+	ipMismatch    []bool
+	retries       []int
+	outOfOrder    []int
+	drift         []*float64
+}
+
+func buildPingRawBatchArgs(results []model.PingResult, inMaintenance []bool) []any {
+	a := pingRawBatchArgs{}
+	for i, result := range results {
+		a.ts = append(a.ts, result.Timestamp)
+		a.endpointIDs = append(a.endpointIDs, result.EndpointID)
+		a.success = append(a.success, result.Success)
+		a.latency = append(a.latency, result.LatencyMs)
+		a.replyIP = append(a.replyIP, derefString(result.ReplyIP))
+		a.ttl = append(a.ttl, result.TTL)
+		a.errorCode = append(a.errorCode, result.ErrorCode)
+		a.payloadBytes = append(a.payloadBytes, result.PayloadBytes)
+		a.httpStatus = append(a.httpStatus, result.HTTPStatus)
+		a.targetIP = append(a.targetIP, derefString(result.TargetIP))
+		a.minLatency = append(a.minLatency, result.MinLatencyMs)
+		a.maxLatency = append(a.maxLatency, result.MaxLatencyMs)
+		a.jitter = append(a.jitter, result.JitterMs)
+		a.lossPct = append(a.lossPct, result.LossPct)
+		a.fallbackProbe = append(a.fallbackProbe, result.FallbackProbe)
+		a.maintenance = append(a.maintenance, inMaintenance[i])
+		a.duplicates = append(a.duplicates, result.DuplicateReplies)
+		a.ipMismatch = append(a.ipMismatch, result.IPMismatch)
+		a.retries = append(a.retries, result.Retries)
+		a.outOfOrder = append(a.outOfOrder, result.OutOfOrderReplies)
+		a.drift = append(a.drift, result.DriftMs)
+	}
+	return []any{
+		a.ts, a.endpointIDs, a.success, a.latency, a.replyIP, a.ttl, a.errorCode, a.payloadBytes,
+		a.httpStatus, a.targetIP, a.minLatency, a.maxLatency, a.jitter, a.lossPct, a.fallbackProbe, a.maintenance, a.duplicates, a.ipMismatch, a.retries, a.outOfOrder, a.drift,
+	}
+}
+
+// buildPingWaveArgs returns the stats upsert and incident arguments for the
+// results at indexes.
+func buildPingWaveArgs(results []model.PingResult, inMaintenance []bool, indexes []int) (statsArgs []any, incidentArgs []any) {
+	n := len(indexes)
+	endpointIDs := make([]int64, 0, n)
+	success := make([]bool, 0, n)
+	ts := make([]time.Time, 0, n)
+	status := make([]string, 0, n)
+	latency := make([]*float64, 0, n)
+	replyIP := make([]string, 0, n)
+	ttl := make([]*int, 0, n)
+	errorCode := make([]string, 0, n)
+	maintenance := make([]bool, 0, n)
+	errorDetail := make([]string, 0, n)
+	duplicates := make([]int, 0, n)
+	ipMismatch := make([]bool, 0, n)
+	fallbackProbe := make([]string, 0, n)
+	for _, i := range indexes {
+		result := results[i]
+		values := buildPingResultWriteValues(result, inMaintenance[i])
+		endpointIDs = append(endpointIDs, result.EndpointID)
+		success = append(success, result.Success)
+		ts = append(ts, result.Timestamp)
+		status = append(status, values.status)
+		latency = append(latency, result.LatencyMs)
+		replyIP = append(replyIP, values.replyIP)
+		ttl = append(ttl, result.TTL)
+		errorCode = append(errorCode, result.ErrorCode)
+		maintenance = append(maintenance, values.maintenance)
+		errorDetail = append(errorDetail, values.errorDetail)
+		duplicates = append(duplicates, result.DuplicateReplies)
+		ipMismatch = append(ipMismatch, result.IPMismatch)
+		fallbackProbe = append(fallbackProbe, result.FallbackProbe)
+	}
+	statsArgs = []any{endpointIDs, success, ts, status, latency, replyIP, ttl, maintenance, errorDetail, duplicates, ipMismatch, fallbackProbe}
+	incidentArgs = []any{endpointIDs, success, ts, errorCode, maintenance}
+	return statsArgs, incidentArgs
+}
+
+// RecordPingResultsBatch writes results in one transaction: a single ping_raw
+// insert for the batch (COPY from pingRawCopyMinRows results), then per wave the alert state reads, one stats upsert,
+// and one incident update, so repeated results for an endpoint still apply in
+// order and alert transitions see the streak as it was before each result.
+func (s *Store) RecordPingResultsBatch(ctx context.Context, results []model.PingResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	windows, err := s.activeMaintenance(ctx)
+	if err != nil {
+		return err
+	}
+
+	inMaintenance := make([]bool, len(results))
+	for i, result := range results {
+		inMaintenance[i] = windows.Covers(result.EndpointID, result.Timestamp)
+	}
+
+	useCopy := len(results) >= pingRawCopyMinRows
+	if useCopy {
+		if _, err := s.copyPingRows(ctx, tx, results, inMaintenance); err != nil {
+			return err
+		}
+	}
+
+	alerting := s.alertingEnabled()
+	waves := pingResultWaves(results)
+	var batch pgx.Batch
+	if !useCopy {
+		batch.Queue(s.insertPingRawBatchQuery(), buildPingRawBatchArgs(results, inMaintenance)...)
+	}
+	for _, wave := range waves {
+		if alerting {
+			for _, i := range wave {
+				batch.Queue(selectAlertStateSQL, results[i].EndpointID, results[i].Timestamp)
+			}
+		}
+		statsArgs, incidentArgs := buildPingWaveArgs(results, inMaintenance, wave)
+		batch.Queue(upsertEndpointStatsBatchSQL, statsArgs...)
+		batch.Queue(trackDowntimeIncidentsBatchSQL, incidentArgs...)
+	}
+
+	br := tx.SendBatch(ctx, &batch)
+	if !useCopy {
+		if _, err := br.Exec(); err != nil {
+			_ = br.Close()
+			return err
+		}
+	}
+	var transitions []model.EndpointTransition
+	for _, wave := range waves {
+		if alerting {
+			for _, i := range wave {
+				state, found, err := scanAlertState(br.QueryRow())
+				if err != nil {
+					_ = br.Close()
+					return err
+				}
+				if found && !inMaintenance[i] {
+					if transition, ok := state.transition(results[i]); ok {
+						transitions = append(transitions, transition)
+					}
+				}
+			}
+		}
+		for j := 0; j < 2; j++ {
+			if _, err := br.Exec(); err != nil {
+				_ = br.Close()
+				return err
+			}
+		}
+	}
+	if err := br.Close(); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	for _, transition := range transitions {
+		s.alertSink.Observe(transition)
+	}
+	return nil
+}
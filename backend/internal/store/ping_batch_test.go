@@ -0,0 +1,111 @@
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestPingResultWavesKeepOneResultPerEndpointInOrder(t *testing.T) {
+	results := []model.PingResult{
+		{EndpointID: 1}, {EndpointID: 2}, {EndpointID: 1}, {EndpointID: 3}, {EndpointID: 1}, {EndpointID: 2},
+	}
+	want := [][]int{{0, 1, 3}, {2, 5}, {4}}
+	if got := pingResultWaves(results); !reflect.DeepEqual(got, want) {
+		t.Fatalf("pingResultWaves = %v, want %v", got, want)
+	}
+	if got := pingResultWaves([]model.PingResult{{EndpointID: 1}, {EndpointID: 2}}); len(got) != 1 {
+		t.Fatalf("distinct endpoints should share one wave, got %v", got)
+	}
+}
+
+func TestBuildPingWaveArgsUsesWriteValues(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	latency := 4.5
+	results := []model.PingResult{
+		{EndpointID: 7, Timestamp: at, Success: true, LatencyMs: &latency, ErrorDetail: "stale", FallbackProbe: model.ProbeModeTCP},
+		{EndpointID: 8, Timestamp: at, ErrorCode: "Destination Unreachable", ErrorDetail: "icmp: host unreachable from 10.0.0.1"},
+		{EndpointID: 9, Timestamp: at},
+	}
+	statsArgs, incidentArgs := buildPingWaveArgs(results, []bool{false, false, true}, []int{0, 1, 2})
+
+	wantStatus := []string{"Succeeded", "Destination Unreachable", model.PingStatusMaintenance}
+	if got := statsArgs[3].([]string); !reflect.DeepEqual(got, wantStatus) {
+		t.Fatalf("status args = %v, want %v", got, wantStatus)
+	}
+	if got := statsArgs[4].([]*float64); got[0] == nil || *got[0] != 4.5 || got[1] != nil {
+		t.Fatalf("latency args = %v", got)
+	}
+	if got := statsArgs[8].([]string); !reflect.DeepEqual(got, []string{"", "icmp: host unreachable from 10.0.0.1", ""}) {
+		t.Fatalf("error detail args = %v", got)
+	}
+	if len(statsArgs) != 12 || !strings.Contains(upsertEndpointStatsBatchSQL, "$12::text[]") {
+		t.Fatalf("stats batch args = %d, want 12 matching the statement", len(statsArgs))
+	}
+	if got := statsArgs[11].([]string); !reflect.DeepEqual(got, []string{model.ProbeModeTCP, "", ""}) {
+		t.Fatalf("fallback probe args = %v", got)
+	}
+	if got := incidentArgs[4].([]bool); !reflect.DeepEqual(got, []bool{false, false, true}) {
+		t.Fatalf("maintenance args = %v", got)
+	}
+	if got := incidentArgs[3].([]string); got[1] != "Destination Unreachable" {
+		t.Fatalf("error code args = %v", got)
+	}
+}
+
+func TestTruncateErrorDetail(t *testing.T) {
+	if got := truncateErrorDetail("  read ip4 0.0.0.0: i/o timeout \n"); got != "read ip4 0.0.0.0: i/o timeout" {
+		t.Fatalf("truncateErrorDetail trimmed = %q", got)
+	}
+	long := strings.Repeat("a", lastErrorDetailMaxLen-1) + "é"
+	got := truncateErrorDetail(long)
+	if len(got) != lastErrorDetailMaxLen-1 || !utf8.ValidString(got) {
+		t.Fatalf("truncateErrorDetail split a rune: len %d", len(got))
+	}
+}
+
+func TestBuildPingRawBatchArgsMatchesStatementArity(t *testing.T) {
+	drift := 1.5
+	args := buildPingRawBatchArgs([]model.PingResult{{EndpointID: 1}, {EndpointID: 2, DuplicateReplies: 3, IPMismatch: true, Retries: 2, OutOfOrderReplies: 1, DriftMs: &drift}}, []bool{false, true})
+	if len(args) != 21 || !strings.Contains(insertPingRawBatchSQL, "$21::double precision[]") {
+		t.Fatalf("ping_raw batch args = %d, want 21 matching the statement", len(args))
+	}
+	if got := args[15].([]bool); !reflect.DeepEqual(got, []bool{false, true}) {
+		t.Fatalf("maintenance args = %v", got)
+	}
+	if got := args[16].([]int); !reflect.DeepEqual(got, []int{0, 3}) {
+		t.Fatalf("duplicate reply args = %v", got)
+	}
+	if got := args[17].([]bool); !reflect.DeepEqual(got, []bool{false, true}) {
+		t.Fatalf("ip mismatch args = %v", got)
+	}
+	if got := args[18].([]int); !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Fatalf("retry args = %v", got)
+	}
+	if got := args[19].([]int); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Fatalf("out-of-order args = %v", got)
+	}
+	if got := args[20].([]*float64); got[0] != nil || got[1] == nil || *got[1] != drift {
+		t.Fatalf("drift args = %v", got)
+	}
+}
+
+func TestPingResultStatsArgsMatchStatementArity(t *testing.T) {
+	result := model.PingResult{EndpointID: 1, Success: true, DuplicateReplies: 2, IPMismatch: true, FallbackProbe: model.ProbeModeTCP}
+
+	live := buildPingResultWriteValues(result, false)
+	args := live.statsArgs(result)
+	if len(args) != 11 || !strings.Contains(live.statsQuery(), "$11::text") || args[8] != 2 || args[9] != true || args[10] != model.ProbeModeTCP {
+		t.Fatalf("live stats args = %v, want 11 ending with the duplicate count, mismatch flag, and fallback probe", args)
+	}
+
+	maintenance := buildPingResultWriteValues(result, true)
+	args = maintenance.statsArgs(result)
+	if len(args) != 10 || !strings.Contains(maintenance.statsQuery(), "$10::text") || strings.Contains(maintenance.statsQuery(), "$11") || args[8] != true {
+		t.Fatalf("maintenance stats args = %v, want 10 matching the statement", args)
+	}
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/model"
+)
+
+// pingRawCopyMinRows is the batch size from which RecordPingResultsBatch
+// loads ping_raw through COPY. COPY adds a round trip and a temp-table write,
+// so smaller batches keep the single unnest INSERT.
+const pingRawCopyMinRows = 256
+
+var pingRawCopyColumns = []string{
+	"ts", "endpoint_id", "success", "latency_ms", "reply_ip", "ttl", "error_code", "payload_bytes", "http_status",
+	"target_ip", "min_latency_ms", "max_latency_ms", "jitter_ms", "loss_pct", "fallback_probe", "maintenance",
+	"duplicate_replies", "ip_mismatch", "retries", "out_of_order_replies", "drift_ms",
+}
+
+// The staging table lives for the pooled connection and is emptied on every
+// commit. It has no key, so COPY never fails on a duplicate (ts, endpoint_id);
+// the move into ping_raw drops those the same way the INSERT path does.
+const createPingRawCopyTableSQL = `
+	CREATE TEMP TABLE IF NOT EXISTS ping_raw_copy (
+		ts TIMESTAMPTZ NOT NULL,
+		endpoint_id BIGINT NOT NULL,
+		success BOOLEAN NOT NULL,
+		latency_ms DOUBLE PRECISION,
+		reply_ip INET,
+		ttl INT,
+		error_code TEXT NOT NULL,
+		payload_bytes INT,
+		http_status INT,
+		target_ip INET,
+		min_latency_ms DOUBLE PRECISION,
+		max_latency_ms DOUBLE PRECISION,
+		jitter_ms DOUBLE PRECISION,
+		loss_pct DOUBLE PRECISION,
+		fallback_probe TEXT,
+		maintenance BOOLEAN NOT NULL,
+		duplicate_replies INT NOT NULL,
+		ip_mismatch BOOLEAN NOT NULL,
+		retries INT NOT NULL,
+		out_of_order_replies INT NOT NULL,
+		drift_ms DOUBLE PRECISION
+	) ON COMMIT DELETE ROWS
+`
+
+const movePingRawCopySQL = `
+	INSERT INTO ping_raw(ts, endpoint_id, success, latency_ms, reply_ip, ttl, error_code, payload_bytes, http_status, target_ip, min_latency_ms, max_latency_ms, jitter_ms, loss_pct, fallback_probe, maintenance, duplicate_replies, ip_mismatch, retries, out_of_order_replies, drift_ms)
+	SELECT ts, endpoint_id, success, latency_ms, reply_ip, ttl, error_code, payload_bytes, http_status, target_ip, min_latency_ms, max_latency_ms, jitter_ms, loss_pct, fallback_probe, maintenance, duplicate_replies, ip_mismatch, retries, out_of_order_replies, drift_ms
+	FROM ping_raw_copy
+	ON CONFLICT (ts, endpoint_id) DO NOTHING
+`
+
+const movePingRawCopyOmitSettingsPayloadSQL = `
+	INSERT INTO ping_raw(ts, endpoint_id, success, latency_ms, reply_ip, ttl, error_code, payload_bytes, http_status, target_ip, min_latency_ms, max_latency_ms, jitter_ms, loss_pct, fallback_probe, maintenance, duplicate_replies, ip_mismatch, retries, out_of_order_replies, drift_ms)
+	SELECT ts, endpoint_id, success, latency_ms, reply_ip, ttl, error_code,
+		NULLIF(payload_bytes, probe_payload_bytes_at(ts)),
+		http_status, target_ip, min_latency_ms, max_latency_ms, jitter_ms, loss_pct, fallback_probe, maintenance, duplicate_replies, ip_mismatch, retries, out_of_order_replies, drift_ms
+	FROM ping_raw_copy
+	ON CONFLICT (ts, endpoint_id) DO NOTHING
+`
+
+func (s *Store) movePingRawCopyQuery() string {
+	if s.omitSettingsPayloadRaw {
+		return movePingRawCopyOmitSettingsPayloadSQL
+	}
+	return movePingRawCopySQL
+}
+
+// CopyPingRows writes results to ping_raw only, through COPY, and returns how
+// many rows were new. Stats, incidents, and alerts are not touched; use
+// RecordPingResultsBatch for probe results.
+func (s *Store) CopyPingRows(ctx context.Context, results []model.PingResult) (int64, error) {
+	if len(results) == 0 {
+		return 0, nil
+	}
+	windows, err := s.activeMaintenance(ctx)
+	if err != nil {
+		return 0, err
+	}
+	inMaintenance := make([]bool, len(results))
+	for i, result := range results {
+		inMaintenance[i] = windows.Covers(result.EndpointID, result.Timestamp)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	inserted, err := s.copyPingRows(ctx, tx, results, inMaintenance)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+func (s *Store) copyPingRows(ctx context.Context, tx pgx.Tx, results []model.PingResult, inMaintenance []bool) (int64, error) {
+	if _, err := tx.Exec(ctx, createPingRawCopyTableSQL); err != nil {
+		return 0, err
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"ping_raw_copy"}, pingRawCopyColumns, pgx.CopyFromSlice(len(results), func(i int) ([]any, error) {
+		return pingRawCopyRow(results[i], inMaintenance[i]), nil
+	})); err != nil {
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, s.movePingRawCopyQuery())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// pingRawCopyRow converts a result to COPY values. COPY binds typed values
+// directly, so the empty-string-to-NULL conversions of the INSERT path
+// happen here.
+func pingRawCopyRow(result model.PingResult, inMaintenance bool) []any {
+	var fallbackProbe any
+	if result.FallbackProbe != "" {
+		fallbackProbe = result.FallbackProbe
+	}
+	return []any{
+		result.Timestamp,
+		result.EndpointID,
+		result.Success,
+		result.LatencyMs,
+		copyInetValue(result.ReplyIP),
+		result.TTL,
+		result.ErrorCode,
+		result.PayloadBytes,
+		result.HTTPStatus,
+		copyInetValue(result.TargetIP),
+		result.MinLatencyMs,
+		result.MaxLatencyMs,
+		result.JitterMs,
+		result.LossPct,
+		fallbackProbe,
+		inMaintenance,
+		result.DuplicateReplies,
+		result.IPMismatch,
+		result.Retries,
+		result.OutOfOrderReplies,
+		result.DriftMs,
+	}
+}
+
+func copyInetValue(value *string) any {
+	addr, err := netip.ParseAddr(derefString(value))
+	if err != nil {
+		return nil
+	}
+	return addr
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/db"
+	"sonarscope/backend/internal/model"
+)
+
+func TestPingRawCopyRowMatchesColumns(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	replyIP := "10.0.0.9"
+	badIP := "not-an-ip"
+	row := pingRawCopyRow(model.PingResult{
+		EndpointID: 7,
+		Timestamp:  at,
+		ReplyIP:    &replyIP,
+		TargetIP:   &badIP,
+		ErrorCode:  "",
+	}, true)
+
+	if len(row) != len(pingRawCopyColumns) {
+		t.Fatalf("row has %d values for %d columns", len(row), len(pingRawCopyColumns))
+	}
+	if got, ok := row[4].(netip.Addr); !ok || got != netip.MustParseAddr(replyIP) {
+		t.Fatalf("reply_ip = %#v, want parsed address", row[4])
+	}
+	if row[9] != nil {
+		t.Fatalf("unparseable target_ip should copy as NULL, got %#v", row[9])
+	}
+	if row[14] != nil {
+		t.Fatalf("empty fallback_probe should copy as NULL, got %#v", row[14])
+	}
+	if row[15] != true {
+		t.Fatalf("maintenance = %#v, want true", row[15])
+	}
+
+	row = pingRawCopyRow(model.PingResult{EndpointID: 7, Timestamp: at, FallbackProbe: "tcp"}, false)
+	if row[4] != nil || row[14] != "tcp" {
+		t.Fatalf("reply_ip = %#v, fallback_probe = %#v", row[4], row[14])
+	}
+}
+
+func TestMovePingRawCopyQueryFollowsPayloadMode(t *testing.T) {
+	s := &Store{}
+	if s.movePingRawCopyQuery() != movePingRawCopySQL {
+		t.Fatalf("default mode should keep payload_bytes")
+	}
+	s.omitSettingsPayloadRaw = true
+	if s.movePingRawCopyQuery() != movePingRawCopyOmitSettingsPayloadSQL {
+		t.Fatalf("omit mode should null the settings payload")
+	}
+}
+
+func testPingStore(tb testing.TB) (*Store, int64) {
+	tb.Helper()
+	databaseURL := os.Getenv("SONARSCOPE_TEST_DATABASE_URL")
+	if databaseURL == "" {
+		tb.Skip("SONARSCOPE_TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	pool, err := db.Connect(ctx, databaseURL)
+	if err != nil {
+		tb.Fatalf("Connect() error = %v", err)
+	}
+	tb.Cleanup(pool.Close)
+
+	var endpointID int64
+	ip := fmt.Sprintf("198.18.%d.%d", time.Now().UnixNano()%250, time.Now().UnixNano()%250+1)
+	if err := pool.QueryRow(ctx, `INSERT INTO inventory_endpoint(ip) VALUES ($1) RETURNING id`, ip).Scan(&endpointID); err != nil {
+		tb.Fatalf("insert endpoint: %v", err)
+	}
+	tb.Cleanup(func() {
+		_, _ = pool.Exec(ctx, `DELETE FROM ping_raw WHERE endpoint_id = $1`, endpointID)
+		_, _ = pool.Exec(ctx, `DELETE FROM inventory_endpoint WHERE id = $1`, endpointID)
+	})
+	return New(pool), endpointID
+}
+
+func testPingResults(endpointID int64, start time.Time, count int) []model.PingResult {
+	results := make([]model.PingResult, count)
+	for i := range results {
+		latency := float64(i%50) + 0.5
+		results[i] = model.PingResult{
+			EndpointID: endpointID,
+			Timestamp:  start.Add(time.Duration(i) * time.Millisecond),
+			Success:    true,
+			LatencyMs:  &latency,
+		}
+	}
+	return results
+}
+
+func TestCopyPingRowsSkipsExistingRows(t *testing.T) {
+	s, endpointID := testPingStore(t)
+	ctx := context.Background()
+	results := testPingResults(endpointID, time.Now().UTC().Truncate(time.Second), 10)
+
+	inserted, err := s.CopyPingRows(ctx, results[:4])
+	if err != nil || inserted != 4 {
+		t.Fatalf("first copy = %d, %v; want 4 rows", inserted, err)
+	}
+	// Rows 0-3 already exist and must be dropped instead of failing the COPY.
+	inserted, err = s.CopyPingRows(ctx, results)
+	if err != nil || inserted != 6 {
+		t.Fatalf("second copy = %d, %v; want 6 new rows", inserted, err)
+	}
+
+	var stored int
+	if err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM ping_raw WHERE endpoint_id = $1`, endpointID).Scan(&stored); err != nil {
+		t.Fatalf("count ping_raw: %v", err)
+	}
+	if stored != len(results) {
+		t.Fatalf("stored %d rows, want %d", stored, len(results))
+	}
+}
+
+func TestCopyPingRowsOmitsPayloadInEffectAtTimestamp(t *testing.T) {
+	s, endpointID := testPingStore(t)
+	ctx := context.Background()
+	s.SetOmitSettingsPayloadBytes(true)
+
+	// Two settings eras from the distant past, so the rows below do not
+	// compare against the payload in effect today.
+	eraStart := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	var historyIDs []int64
+	for _, era := range []struct {
+		from    time.Time
+		payload int
+	}{{eraStart, 777}, {eraStart.Add(time.Hour), 888}} {
+		var id int64
+		if err := s.pool.QueryRow(ctx, `
+			INSERT INTO probe_settings_history(effective_from, ping_interval_sec, icmp_payload_bytes)
+			VALUES ($1, 1, $2) RETURNING id
+		`, era.from, era.payload).Scan(&id); err != nil {
+			t.Fatalf("insert settings history: %v", err)
+		}
+		historyIDs = append(historyIDs, id)
+	}
+	t.Cleanup(func() {
+		_, _ = s.pool.Exec(context.Background(), `DELETE FROM probe_settings_history WHERE id = ANY($1::bigint[])`, historyIDs)
+	})
+
+	results := testPingResults(endpointID, eraStart.Add(30*time.Minute), 2)
+	results[0].PayloadBytes = 777
+	results[1].PayloadBytes = 888
+	if _, err := s.CopyPingRows(ctx, results); err != nil {
+		t.Fatalf("CopyPingRows() error = %v", err)
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT pr.payload_bytes, pr.resolved_payload_bytes
+		FROM ping_raw pr
+		WHERE pr.endpoint_id = $1
+		ORDER BY pr.ts
+	`, endpointID)
+	if err != nil {
+		t.Fatalf("read ping_raw: %v", err)
+	}
+	defer rows.Close()
+	var stored []*int
+	var resolved []int
+	for rows.Next() {
+		var payload *int
+		var value int
+		if err := rows.Scan(&payload, &value); err != nil {
+			t.Fatalf("scan ping_raw: %v", err)
+		}
+		stored = append(stored, payload)
+		resolved = append(resolved, value)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("read ping_raw: %v", err)
+	}
+	if len(stored) != 2 || stored[0] != nil || stored[1] == nil || *stored[1] != 888 {
+		t.Fatalf("stored payload_bytes = %v, want NULL for the era's size and 888 kept", stored)
+	}
+	if resolved[0] != 777 || resolved[1] != 888 {
+		t.Fatalf("resolved payload_bytes = %v, want [777 888]", resolved)
+	}
+}
+
+// BenchmarkPingRawWrite compares loading ping_raw through COPY against the
+// unnest INSERT used for small batches. Run with
+// SONARSCOPE_TEST_DATABASE_URL set and -bench PingRawWrite.
+func BenchmarkPingRawWrite(b *testing.B) {
+	s, endpointID := testPingStore(b)
+	ctx := context.Background()
+
+	for _, size := range []int{64, 256, 1024} {
+		start := time.Now().UTC().Add(-24 * time.Hour)
+		b.Run(fmt.Sprintf("insert/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				results := testPingResults(endpointID, start, size)
+				start = start.Add(time.Duration(size) * time.Millisecond)
+				tx, err := s.pool.Begin(ctx)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := tx.Exec(ctx, s.insertPingRawBatchQuery(), buildPingRawBatchArgs(results, make([]bool, size))...); err != nil {
+					b.Fatal(err)
+				}
+				if err := tx.Commit(ctx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("copy/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				results := testPingResults(endpointID, start, size)
+				start = start.Add(time.Duration(size) * time.Millisecond)
+				if _, err := s.CopyPingRows(ctx, results); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
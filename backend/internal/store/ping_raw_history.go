@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+// ListEndpointRawPings returns the endpoint's ping_raw rows in [start, end],
+// newest first. Rows older than raw retention are gone.
+func (s *Store) ListEndpointRawPings(ctx context.Context, endpointID int64, start, end time.Time, limit int) ([]model.RawPingSample, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT ts, success, latency_ms, host(reply_ip), ttl, error_code, maintenance, COALESCE(fallback_probe, ''), out_of_order_replies, drift_ms
+		FROM ping_raw
+		WHERE endpoint_id = $1
+		  AND ts >= $2::timestamptz
+		  AND ts <= $3::timestamptz
+		ORDER BY ts DESC
+		LIMIT $4
+	`, endpointID, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.RawPingSample{}
+	for rows.Next() {
+		var item model.RawPingSample
+		if err := rows.Scan(
+			&item.Timestamp,
+			&item.Success,
+			&item.LatencyMs,
+			&item.ReplyIP,
+			&item.TTL,
+			&item.ErrorCode,
+			&item.Maintenance,
+			&item.FallbackProbe,
+			&item.OutOfOrderReplies,
+			&item.DriftMs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
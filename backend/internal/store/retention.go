@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+
+	"sonarscope/backend/internal/model"
+)
+
+// retentionTargets maps each Timescale relation with a retention policy to
+// the setting that drives it.
+func retentionTargets(settings model.RetentionSettings) []struct {
+	relation string
+	days     int
+} {
+	return []struct {
+		relation string
+		days     int
+	}{
+		{"ping_raw", settings.PingRawDays},
+		{"ping_10s", settings.PingRawDays},
+		{"ping_jitter_10s", settings.PingRawDays},
+		{"ping_1m", settings.Ping1mDays},
+		{"ping_jitter_1m", settings.Ping1mDays},
+		{"ping_1h", settings.Ping1hDays},
+		{"ping_jitter_1h", settings.Ping1hDays},
+		{"ping_1d", settings.Ping1hDays},
+		{"ping_jitter_1d", settings.Ping1hDays},
+	}
+}
+
+// rollupRetention is the retention in effect, which RollupForRange uses to
+// avoid aggregates that no longer hold the start of a window. It starts at
+// the migration defaults until the saved windows are applied.
+var rollupRetention atomic.Pointer[model.RetentionSettings]
+
+func init() {
+	rollupRetention.Store(&model.RetentionSettings{PingRawDays: 30, Ping1mDays: 365, Ping1hDays: 730})
+}
+
+// retentionDays returns how long the given rollup's aggregate keeps buckets.
+func retentionDays(settings model.RetentionSettings, rollup string) int {
+	switch rollup {
+	case "10s":
+		return settings.PingRawDays
+	case "1m":
+		return settings.Ping1mDays
+	default:
+		return settings.Ping1hDays
+	}
+}
+
+func (s *Store) GetRetentionSettings(ctx context.Context) (model.RetentionSettings, error) {
+	var settings model.RetentionSettings
+	err := s.pool.QueryRow(ctx, `
+		SELECT retention_raw_days, retention_1m_days, retention_1h_days
+		FROM app_settings
+		WHERE id = TRUE
+	`).Scan(&settings.PingRawDays, &settings.Ping1mDays, &settings.Ping1hDays)
+	return settings, err
+}
+
+// UpdateRetentionSettings persists the windows and swaps the Timescale
+// retention policies in the same transaction.
+func (s *Store) UpdateRetentionSettings(ctx context.Context, settings model.RetentionSettings) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE app_settings
+		SET retention_raw_days = $1,
+			retention_1m_days = $2,
+			retention_1h_days = $3,
+			updated_at = now()
+		WHERE id = TRUE
+	`, settings.PingRawDays, settings.Ping1mDays, settings.Ping1hDays); err != nil {
+		return err
+	}
+	if err := applyRetentionPolicies(ctx, tx, settings); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	rollupRetention.Store(&settings)
+	return nil
+}
+
+// ApplyRetentionPolicies re-applies the persisted windows. Startup calls it
+// after migrations so the migration defaults never override a saved choice.
+func (s *Store) ApplyRetentionPolicies(ctx context.Context) error {
+	settings, err := s.GetRetentionSettings(ctx)
+	if err != nil {
+		return err
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if err := applyRetentionPolicies(ctx, tx, settings); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	rollupRetention.Store(&settings)
+	return nil
+}
+
+func applyRetentionPolicies(ctx context.Context, tx pgx.Tx, settings model.RetentionSettings) error {
+	for _, target := range retentionTargets(settings) {
+		if _, err := tx.Exec(ctx, `SELECT remove_retention_policy($1::regclass, if_exists => TRUE)`, target.relation); err != nil {
+			return fmt.Errorf("remove retention policy on %s: %w", target.relation, err)
+		}
+		if _, err := tx.Exec(ctx, `SELECT add_retention_policy($1::regclass, make_interval(days => $2))`, target.relation, target.days); err != nil {
+			return fmt.Errorf("add retention policy on %s: %w", target.relation, err)
+		}
+	}
+	return nil
+}
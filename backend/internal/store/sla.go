@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+// buildEndpointSLAQuery returns the per-endpoint availability query for a
+// window ($1 endpoint ids, $2 start, $3 end) and the aggregate it reads.
+// An outage is a run of adjacent buckets in which every probe failed; a
+// bucket with no samples, such as while probing was stopped or a maintenance
+// window that is excluded, ends the run.
+func buildEndpointSLAQuery(start, end time.Time, excludeMaintenance bool) (string, string) {
+	viewName, bucketWidth := rangeAggregateView(start, end)
+	query := fmt.Sprintf(`
+		WITH buckets AS (
+			SELECT endpoint_id, bucket, sent_count, fail_count
+			FROM (%s) rb
+		),
+		totals AS (
+			SELECT
+				endpoint_id,
+				SUM(sent_count)::BIGINT AS total_sent_ping,
+				SUM(fail_count)::BIGINT AS failed_count
+			FROM buckets
+			GROUP BY endpoint_id
+		),
+		down_runs AS (
+			SELECT
+				endpoint_id,
+				bucket,
+				bucket - (ROW_NUMBER() OVER (PARTITION BY endpoint_id ORDER BY bucket)) * INTERVAL '%d seconds' AS run_key
+			FROM buckets
+			WHERE sent_count > 0 AND fail_count >= sent_count
+		),
+		longest_outage AS (
+			SELECT DISTINCT ON (endpoint_id)
+				endpoint_id,
+				MIN(bucket) AS started_at,
+				COUNT(*)::BIGINT AS bucket_count
+			FROM down_runs
+			GROUP BY endpoint_id, run_key
+			ORDER BY endpoint_id, COUNT(*) DESC, MIN(bucket)
+		)
+		SELECT
+			ie.id,
+			ie.hostname,
+			COALESCE(host(ie.ip), ''),
+			COALESCE(t.total_sent_ping, 0),
+			COALESCE(t.failed_count, 0),
+			lo.started_at,
+			COALESCE(lo.bucket_count, 0)
+		FROM inventory_endpoint ie
+		LEFT JOIN totals t ON t.endpoint_id = ie.id
+		LEFT JOIN longest_outage lo ON lo.endpoint_id = ie.id
+		WHERE ie.id = ANY($1::bigint[])
+		ORDER BY ie.id
+	`, rangeBucketsSQL(viewName, bucketWidth, 2, 3, 1, excludeMaintenance), int64(bucketWidth/time.Second))
+	return query, viewName
+}
+
+// EndpointSLA returns availability for each endpoint over [start, end] and
+// the aggregate (for example "ping_1m") it was computed from. Outage lengths
+// are whole buckets, so they are accurate to the bucket width. With
+// excludeMaintenance, probes tagged as maintenance are left out.
+func (s *Store) EndpointSLA(ctx context.Context, endpointIDs []int64, start, end time.Time, excludeMaintenance bool) ([]model.EndpointSLA, string, error) {
+	query, viewName := buildEndpointSLAQuery(start, end, excludeMaintenance)
+	if len(endpointIDs) == 0 {
+		return []model.EndpointSLA{}, viewName, nil
+	}
+	_, bucketWidth := rangeAggregateView(start, end)
+
+	rows, err := s.pool.Query(ctx, query, endpointIDs, start, end)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	items := []model.EndpointSLA{}
+	for rows.Next() {
+		var item model.EndpointSLA
+		var outageBuckets int64
+		if err := rows.Scan(
+			&item.EndpointID,
+			&item.Hostname,
+			&item.IPAddress,
+			&item.TotalSentPing,
+			&item.FailedCount,
+			&item.LongestOutageStartedAt,
+			&outageBuckets,
+		); err != nil {
+			return nil, "", err
+		}
+		if item.TotalSentPing > 0 {
+			uptime := float64(item.TotalSentPing-item.FailedCount) / float64(item.TotalSentPing) * 100
+			item.UptimePct = &uptime
+		}
+		item.LongestOutageSec = (time.Duration(outageBuckets) * bucketWidth).Seconds()
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	return items, viewName, nil
+}
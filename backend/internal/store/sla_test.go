@@ -0,0 +1,108 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sonarscope/backend/internal/model"
+)
+
+func TestBuildEndpointSLAQuerySelectsAggregateByWindow(t *testing.T) {
+	end := time.Now().Truncate(time.Minute)
+
+	sql, view := buildEndpointSLAQuery(end.Add(-24*time.Hour), end, false)
+	if view != "ping_1m" || !strings.Contains(sql, "FROM ping_1m") || !strings.Contains(sql, "INTERVAL '60 seconds'") {
+		t.Fatalf("expected 24h window to use ping_1m with minute runs, got %s: %s", view, sql)
+	}
+
+	sql, view = buildEndpointSLAQuery(end.Add(-30*24*time.Hour), end, false)
+	if view != "ping_1h" || !strings.Contains(sql, "FROM ping_1h") || !strings.Contains(sql, "INTERVAL '3600 seconds'") {
+		t.Fatalf("expected 30d window to use ping_1h with hour runs, got %s: %s", view, sql)
+	}
+}
+
+func TestRollupForRangeBoundaries(t *testing.T) {
+	end := time.Now().Truncate(time.Minute)
+	cases := []struct {
+		span time.Duration
+		want string
+	}{
+		{time.Hour, "10s"},
+		{6 * time.Hour, "10s"},
+		{6*time.Hour + time.Nanosecond, "1m"},
+		{24 * time.Hour, "1m"},
+		{48 * time.Hour, "1m"},
+		{48*time.Hour + time.Nanosecond, "1h"},
+		{60 * 24 * time.Hour, "1h"},
+		{60*24*time.Hour + time.Nanosecond, "1d"},
+		{180 * 24 * time.Hour, "1d"},
+	}
+	for _, tc := range cases {
+		start := end.Add(-tc.span)
+		view, rollup := RollupForRange(start, end)
+		if rollup != tc.want || view != "ping_"+tc.want {
+			t.Fatalf("RollupForRange(%s) = %q, %q, want ping_%s, %s", tc.span, view, rollup, tc.want, tc.want)
+		}
+		if rangeView, width := rangeAggregateView(start, end); rangeView != view || width != rollupAggregates[rollup].width {
+			t.Fatalf("rangeAggregateView(%s) = %q, %s, want %q", tc.span, rangeView, width, view)
+		}
+		if _, slaView := buildEndpointSLAQuery(start, end, false); slaView != view {
+			t.Fatalf("SLA view for %s = %q, want %q", tc.span, slaView, view)
+		}
+	}
+
+	sql, view := buildEndpointSLAQuery(end.Add(-180*24*time.Hour), end, false)
+	if view != "ping_1d" || !strings.Contains(sql, "FROM ping_1d") || !strings.Contains(sql, "INTERVAL '86400 seconds'") {
+		t.Fatalf("expected 180d window to use ping_1d with day runs, got %s: %s", view, sql)
+	}
+}
+
+func TestRollupForRangeStepsUpPastRetention(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	retention := model.RetentionSettings{PingRawDays: 30, Ping1mDays: 365, Ping1hDays: 730}
+	day := 24 * time.Hour
+	cases := []struct {
+		name  string
+		start time.Time
+		span  time.Duration
+		want  string
+	}{
+		{"6h window at the raw horizon", now.AddDate(0, 0, -30), time.Hour, "10s"},
+		{"6h window past the raw horizon", now.AddDate(0, 0, -30).Add(-time.Second), time.Hour, "1m"},
+		{"6h window past the 1m horizon", now.AddDate(0, 0, -365).Add(-time.Second), time.Hour, "1h"},
+		{"48h window at the 1m horizon", now.AddDate(0, 0, -365), 48 * time.Hour, "1m"},
+		{"48h window past the 1m horizon", now.AddDate(0, 0, -365).Add(-time.Second), 48 * time.Hour, "1h"},
+		{"60d window at the 1h horizon", now.AddDate(0, 0, -730), 60 * day, "1h"},
+		{"60d window past the 1h horizon", now.AddDate(0, 0, -730).Add(-time.Second), 60 * day, "1d"},
+		{"1d window past every horizon", now.AddDate(-5, 0, 0), 180 * day, "1d"},
+	}
+	for _, tc := range cases {
+		view, rollup := rollupForRange(tc.start, tc.start.Add(tc.span), now, retention)
+		if rollup != tc.want || view != "ping_"+tc.want {
+			t.Fatalf("%s: rollupForRange() = %q, %q, want ping_%s", tc.name, view, rollup, tc.want)
+		}
+	}
+
+	short := model.RetentionSettings{PingRawDays: 1, Ping1mDays: 7, Ping1hDays: 30}
+	if _, rollup := rollupForRange(now.Add(-2*day), now.Add(-2*day+time.Hour), now, short); rollup != "1m" {
+		t.Fatalf("rollupForRange() with 1 day raw retention = %q, want 1m", rollup)
+	}
+}
+
+func TestBuildEndpointSLAQueryExcludesMaintenance(t *testing.T) {
+	end := time.Now().Truncate(time.Minute)
+
+	sql, _ := buildEndpointSLAQuery(end.Add(-24*time.Hour), end, false)
+	if strings.Contains(sql, "pr.maintenance") {
+		t.Fatalf("default SLA query should not read ping_raw: %s", sql)
+	}
+
+	sql, _ = buildEndpointSLAQuery(end.Add(-24*time.Hour), end, true)
+	if !strings.Contains(sql, "WHERE pr.maintenance") || !strings.Contains(sql, "b.sent_count - COALESCE(m.sent_count, 0)") {
+		t.Fatalf("expected maintenance probes to be subtracted per bucket: %s", sql)
+	}
+	if !strings.Contains(sql, "pr.endpoint_id = ANY($1::bigint[])") || !strings.Contains(sql, "time_bucket(INTERVAL '60 seconds', pr.ts)") {
+		t.Fatalf("expected the maintenance scan to match the aggregate buckets: %s", sql)
+	}
+}
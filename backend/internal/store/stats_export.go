@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+
+	"sonarscope/backend/internal/model"
+)
+
+// StreamEndpointStats walks endpoint_stats_current rows matching the monitor
+// filters in endpoint id order without buffering the result set.
+func (s *Store) StreamEndpointStats(ctx context.Context, query MonitorPageQuery, fn func(model.EndpointStatsSnapshot) error) error {
+	whereClause, args := buildMonitorWhereClause(
+		s.monitorStatus(),
+		query.Filters,
+		query.Hostname,
+		query.MAC,
+		query.CustomSearches,
+		query.IPList,
+		query.ExcludeEndpointIDs,
+		query.Search,
+	)
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			ie.id,
+			ie.hostname,
+			COALESCE(host(ie.ip), ''),
+			es.last_failed_on,
+			es.last_success_on,
+			es.success_count,
+			es.failed_count,
+			es.consecutive_failed_count,
+			es.max_consecutive_failed_count,
+			es.max_consecutive_failed_count_time,
+			es.failed_pct,
+			es.total_sent_ping,
+			es.last_ping_status,
+			es.last_ping_latency,
+			es.average_latency,
+			host(es.reply_ip_address),
+			es.last_ttl,
+			es.updated_at
+		FROM inventory_endpoint ie
+		JOIN endpoint_stats_current es ON es.endpoint_id = ie.id
+	`+whereClause+`
+		ORDER BY ie.id
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.EndpointStatsSnapshot
+		if err := rows.Scan(
+			&item.EndpointID,
+			&item.Hostname,
+			&item.IPAddress,
+			&item.LastFailedOn,
+			&item.LastSuccessOn,
+			&item.SuccessCount,
+			&item.FailedCount,
+			&item.ConsecutiveFailedCount,
+			&item.MaxConsecutiveFailedCount,
+			&item.MaxConsecutiveFailedCountTime,
+			&item.FailedPct,
+			&item.TotalSentPing,
+			&item.LastPingStatus,
+			&item.LastPingLatency,
+			&item.AverageLatency,
+			&item.ReplyIPAddress,
+			&item.LastTTL,
+			&item.UpdatedAt,
+		); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+)
+
+// recomputeEndpointStatsSQL rebuilds endpoint_stats_current for the endpoints
+// in $1 from their retained ping_raw history. Failure runs are found with the
+// usual gaps-and-islands trick: the difference between an endpoint's overall
+// row number and its row number within the same success value is constant
+// across a run of consecutive results with that value. The trailing run is the
+// current streak when it is a failure run that ends at the latest probe.
+// Probes tagged as maintenance are skipped, matching the live counters.
+// Endpoints without raw history are left alone.
+const recomputeEndpointStatsSQL = `
+	WITH ordered AS (
+		SELECT
+			endpoint_id,
+			ts,
+			success,
+			ROW_NUMBER() OVER (PARTITION BY endpoint_id ORDER BY ts)
+				- ROW_NUMBER() OVER (PARTITION BY endpoint_id, success ORDER BY ts) AS island
+		FROM ping_raw
+		WHERE endpoint_id = ANY($1::bigint[]) AND NOT maintenance
+	),
+	fail_runs AS (
+		SELECT endpoint_id, island, COUNT(*) AS run_len, MAX(ts) AS run_end
+		FROM ordered
+		WHERE NOT success
+		GROUP BY endpoint_id, island
+	),
+	longest_run AS (
+		SELECT DISTINCT ON (endpoint_id) endpoint_id, run_len, run_end
+		FROM fail_runs
+		ORDER BY endpoint_id, run_len DESC, run_end DESC
+	),
+	totals AS (
+		SELECT
+			endpoint_id,
+			COUNT(*) AS total_sent,
+			COUNT(*) FILTER (WHERE success) AS success_count,
+			COUNT(*) FILTER (WHERE NOT success) AS failed_count,
+			MAX(ts) FILTER (WHERE success) AS last_success_on,
+			MAX(ts) FILTER (WHERE NOT success) AS last_failed_on,
+			MAX(ts) AS last_ts,
+			AVG(latency_ms) FILTER (WHERE success AND latency_ms IS NOT NULL) AS average_latency,
+			SUM(duplicate_replies) AS duplicate_reply_count
+		FROM ping_raw
+		WHERE endpoint_id = ANY($1::bigint[]) AND NOT maintenance
+		GROUP BY endpoint_id
+	),
+	latest AS (
+		SELECT DISTINCT ON (endpoint_id) endpoint_id, success, latency_ms, error_code, reply_ip, ttl, ip_mismatch, fallback_probe
+		FROM ping_raw
+		WHERE endpoint_id = ANY($1::bigint[]) AND NOT maintenance
+		ORDER BY endpoint_id, ts DESC
+	)
+	INSERT INTO endpoint_stats_current(
+		endpoint_id,
+		last_failed_on,
+		last_success_on,
+		success_count,
+		failed_count,
+		consecutive_failed_count,
+		max_consecutive_failed_count,
+		max_consecutive_failed_count_time,
+		failed_pct,
+		total_sent_ping,
+		last_ping_status,
+		last_ping_latency,
+		average_latency,
+		reply_ip_address,
+		last_ttl,
+		duplicate_reply_count,
+		last_ip_mismatch,
+		last_fallback_probe,
+		updated_at
+	)
+	SELECT
+		t.endpoint_id,
+		t.last_failed_on,
+		t.last_success_on,
+		t.success_count,
+		t.failed_count,
+		COALESCE(trailing.run_len, 0),
+		COALESCE(lr.run_len, 0),
+		lr.run_end,
+		(t.failed_count::DOUBLE PRECISION / t.total_sent::DOUBLE PRECISION) * 100,
+		t.total_sent,
+		CASE
+			WHEN l.success THEN 'Succeeded'
+			WHEN l.error_code <> '' THEN l.error_code
+			ELSE 'Request Timeout'
+		END,
+		l.latency_ms,
+		t.average_latency,
+		l.reply_ip,
+		l.ttl,
+		t.duplicate_reply_count,
+		l.ip_mismatch,
+		COALESCE(l.fallback_probe, ''),
+		now()
+	FROM totals t
+	JOIN latest l ON l.endpoint_id = t.endpoint_id
+	LEFT JOIN longest_run lr ON lr.endpoint_id = t.endpoint_id
+	LEFT JOIN fail_runs trailing ON trailing.endpoint_id = t.endpoint_id AND trailing.run_end = t.last_ts
+	ON CONFLICT (endpoint_id) DO UPDATE SET
+		last_failed_on = EXCLUDED.last_failed_on,
+		last_success_on = EXCLUDED.last_success_on,
+		success_count = EXCLUDED.success_count,
+		failed_count = EXCLUDED.failed_count,
+		consecutive_failed_count = EXCLUDED.consecutive_failed_count,
+		max_consecutive_failed_count = EXCLUDED.max_consecutive_failed_count,
+		max_consecutive_failed_count_time = EXCLUDED.max_consecutive_failed_count_time,
+		failed_pct = EXCLUDED.failed_pct,
+		total_sent_ping = EXCLUDED.total_sent_ping,
+		last_ping_status = EXCLUDED.last_ping_status,
+		last_ping_latency = EXCLUDED.last_ping_latency,
+		average_latency = EXCLUDED.average_latency,
+		reply_ip_address = EXCLUDED.reply_ip_address,
+		last_ttl = EXCLUDED.last_ttl,
+		duplicate_reply_count = EXCLUDED.duplicate_reply_count,
+		last_ip_mismatch = EXCLUDED.last_ip_mismatch,
+		last_fallback_probe = EXCLUDED.last_fallback_probe,
+		last_error_detail = CASE
+			WHEN EXCLUDED.last_ping_status = 'Succeeded' THEN ''
+			ELSE endpoint_stats_current.last_error_detail
+		END,
+		updated_at = now()
+`
+
+// RecomputeEndpointStats rebuilds endpoint_stats_current for one batch of
+// endpoints from ping_raw and returns how many rows were written. The stats
+// rows are locked first, so a probe that lands mid-recompute waits and is
+// then counted on top of the rebuilt row instead of being lost.
+func (s *Store) RecomputeEndpointStats(ctx context.Context, endpointIDs []int64) (int64, error) {
+	endpointIDs = uniqueInt64(endpointIDs)
+	if len(endpointIDs) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+		SELECT endpoint_id
+		FROM endpoint_stats_current
+		WHERE endpoint_id = ANY($1::bigint[])
+		ORDER BY endpoint_id
+		FOR UPDATE
+	`, endpointIDs); err != nil {
+		return 0, err
+	}
+	cmd, err := tx.Exec(ctx, recomputeEndpointStatsSQL, endpointIDs)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
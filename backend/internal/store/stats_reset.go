@@ -0,0 +1,36 @@
+package store
+
+import (
+	"context"
+)
+
+// ResetEndpointStats zeroes the lifetime counters in endpoint_stats_current
+// for the given endpoints and returns how many rows were reset. ping_raw and
+// the rollups are untouched. The live status, last seen times, and the
+// current failure streak are kept so an endpoint that is still down keeps its
+// alert state, and the streak becomes the new max. total_sent_ping is kept
+// too: it marks probe history for the live status and down grace, while
+// failed_pct is computed over success_count + failed_count.
+func (s *Store) ResetEndpointStats(ctx context.Context, endpointIDs []int64) (int64, error) {
+	endpointIDs = uniqueInt64(endpointIDs)
+	if len(endpointIDs) == 0 {
+		return 0, nil
+	}
+
+	cmd, err := s.pool.Exec(ctx, `
+		UPDATE endpoint_stats_current
+		SET success_count = 0,
+			failed_count = 0,
+			max_consecutive_failed_count = consecutive_failed_count,
+			max_consecutive_failed_count_time = CASE WHEN consecutive_failed_count > 0 THEN last_failed_on ELSE NULL END,
+			failed_pct = 0,
+			average_latency = NULL,
+			duplicate_reply_count = 0,
+			updated_at = now()
+		WHERE endpoint_id = ANY($1::bigint[])
+	`, endpointIDs)
+	if err != nil {
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
@@ -5,20 +5,39 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"sonarscope/backend/internal/maintenance"
 	"sonarscope/backend/internal/model"
 )
 
 type Store struct {
-	pool *pgxpool.Pool
+	pool                   *pgxpool.Pool
+	statusStaleAfter       time.Duration
+	downGracePeriod        time.Duration
+	downGraceMinSamples    int
+	omitSettingsPayloadRaw bool
+	alertSink              AlertSink
+
+	maintenanceMu       sync.Mutex
+	maintenanceSet      *maintenance.Set
+	maintenanceLoadedAt time.Time
 }
 
+const defaultStatusStaleAfter = 5 * time.Minute
+
 const noGroupName = "no group"
 
+// monitorRangeStreakBatch bounds how many endpoints one ping_raw streak lookup
+// covers when range rows are streamed.
+const monitorRangeStreakBatch = 500
+
 var (
 	ErrReservedGroupName       = errors.New(`group name "no group" is reserved`)
 	ErrSystemGroupMutable      = errors.New("system group cannot be modified")
@@ -31,6 +50,11 @@ type MonitorFilters struct {
 	Switches   []string
 	Ports      []string
 	GroupNames []string
+	// CIDRs are normalized network prefixes; an endpoint matches when its IP
+	// falls inside any of them.
+	CIDRs []string
+	// Status is "", "up", "down", or "flapping"; see monitorStatusSQL.
+	Status string
 }
 
 type MonitorPageQuery struct {
@@ -40,6 +64,7 @@ type MonitorPageQuery struct {
 	CustomSearches     []string
 	IPList             []string
 	ExcludeEndpointIDs []int64
+	Search             string
 	Page               int
 	PageSize           int
 	SortBy             string
@@ -49,6 +74,12 @@ type MonitorPageQuery struct {
 	Start              time.Time
 	End                time.Time
 	Lookback           time.Duration
+	// ExcludeMaintenance leaves maintenance-tagged probes out of range stats.
+	ExcludeMaintenance bool
+	// CursorMode pages live rows by keyset instead of OFFSET, starting after
+	// Cursor when it is set. Page is ignored.
+	CursorMode bool
+	Cursor     string
 }
 
 type MonitorSortCriterion struct {
@@ -66,12 +97,24 @@ type InventoryListQuery struct {
 	Filters        MonitorFilters
 	ActivityStates []string
 	CustomSearches []string
+	Search         string
+	Page           int
+	PageSize       int
+	SortBy         string
+	SortDir        string
 }
 
 type ProbeTarget struct {
-	EndpointID int64  `json:"endpoint_id"`
-	IP         string `json:"ip"`
-	Hostname   string `json:"hostname"`
+	EndpointID       int64  `json:"endpoint_id"`
+	IP               string `json:"ip"`
+	Hostname         string `json:"hostname"`
+	ProbeMode        string `json:"probe_mode"`
+	HTTPTarget       string `json:"http_target,omitempty"`
+	TCPPort          int    `json:"tcp_port,omitempty"`
+	ProbeIntervalSec int    `json:"probe_interval_sec,omitempty"`
+	// GroupIntervalSec is the shortest probe_interval_sec among the scheduled
+	// groups the endpoint belongs to; 0 outside groups scope.
+	GroupIntervalSec int `json:"group_interval_sec,omitempty"`
 }
 
 type InventoryDeleteProgress struct {
@@ -84,7 +127,85 @@ type InventoryDeleteProgress struct {
 }
 
 func New(pool *pgxpool.Pool) *Store {
-	return &Store{pool: pool}
+	return &Store{pool: pool, statusStaleAfter: defaultStatusStaleAfter}
+}
+
+// Ping checks that the database answers on a pooled connection.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+func (s *Store) PoolStats() model.DBPoolStats {
+	stat := s.pool.Stat()
+	return model.DBPoolStats{
+		AcquiredConns: stat.AcquiredConns(),
+		IdleConns:     stat.IdleConns(),
+		TotalConns:    stat.TotalConns(),
+		MaxConns:      stat.MaxConns(),
+	}
+}
+
+// SetStatusStaleAfter controls how old an endpoint's last stats update may be
+// before live monitor queries report it as stale instead of its last status.
+func (s *Store) SetStatusStaleAfter(d time.Duration) {
+	if d <= 0 {
+		d = defaultStatusStaleAfter
+	}
+	s.statusStaleAfter = d
+}
+
+// SetDownGrace reports failing endpoints as warming_up instead of their probe
+// error while they are younger than period or have fewer than minSamples
+// probes. Zero values disable each rule.
+func (s *Store) SetDownGrace(period time.Duration, minSamples int) {
+	if period < 0 {
+		period = 0
+	}
+	if minSamples < 0 {
+		minSamples = 0
+	}
+	s.downGracePeriod = period
+	s.downGraceMinSamples = minSamples
+}
+
+// SetOmitSettingsPayloadBytes stores ping_raw.payload_bytes as NULL when it
+// matches the probe_settings_history row in effect at ts, the latest one at
+// or before it. resolved_payload_bytes(ping_raw) reads the value back.
+func (s *Store) SetOmitSettingsPayloadBytes(omit bool) {
+	s.omitSettingsPayloadRaw = omit
+}
+
+func (s *Store) insertPingRawQuery() string {
+	if s.omitSettingsPayloadRaw {
+		return insertPingRawOmitSettingsPayloadSQL
+	}
+	return insertPingRawSQL
+}
+
+func (s *Store) liveStatusExpression() string {
+	return liveLastPingStatusExpression(s.statusStaleAfter, s.downGracePeriod, s.downGraceMinSamples)
+}
+
+func liveLastPingStatusExpression(staleAfter time.Duration, gracePeriod time.Duration, graceMinSamples int) string {
+	staleAfterSec := int64(staleAfter / time.Second)
+	if staleAfterSec < 1 {
+		staleAfterSec = 1
+	}
+
+	graceRules := downGraceRules("es", gracePeriod, graceMinSamples)
+	graceCase := ""
+	if len(graceRules) > 0 {
+		graceCase = fmt.Sprintf(`
+				WHEN COALESCE(btrim(es.last_ping_status), '') <> 'Succeeded' AND (%s) THEN '%s'`, strings.Join(graceRules, " OR "), model.PingStatusWarmingUp)
+	}
+
+	return fmt.Sprintf(`CASE
+				WHEN NOT ie.monitoring_enabled THEN '%s'
+				WHEN COALESCE(es.total_sent_ping, 0) = 0 THEN '%s'
+				WHEN es.updated_at < now() - make_interval(secs => %d) THEN '%s'
+				WHEN es.last_ping_status = '%s' THEN '%s'%s
+				ELSE COALESCE(NULLIF(btrim(es.last_ping_status), ''), '%s')
+			END`, model.PingStatusDisabled, model.PingStatusNeverProbed, staleAfterSec, model.PingStatusStale, model.PingStatusMaintenance, model.PingStatusMaintenance, graceCase, model.PingStatusUnknown)
 }
 
 func customFieldValueColumns(alias string) string {
@@ -157,13 +278,39 @@ func importCandidateCustomFieldValues(row model.ImportCandidate) []any {
 
 func (s *Store) EnsureDefaultSettings(ctx context.Context, defaults model.Settings) error {
 	_, err := s.pool.Exec(ctx, `
-		INSERT INTO app_settings(id, ping_interval_sec, icmp_payload_bytes, icmp_timeout_ms, auto_refresh_sec)
-		VALUES (TRUE, $1, $2, $3, $4)
+		INSERT INTO app_settings(id, ping_interval_sec, icmp_payload_bytes, icmp_timeout_ms, auto_refresh_sec, probe_mode, tcp_port, payload_pattern)
+		VALUES (TRUE, $1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'icmp'), COALESCE(NULLIF($6, 0), 443), COALESCE(NULLIF($7, ''), 'incrementing'))
 		ON CONFLICT (id) DO NOTHING
-	`, defaults.PingIntervalSec, defaults.ICMPPayloadSize, defaults.ICMPTimeoutMs, defaults.AutoRefreshSec)
+	`, defaults.PingIntervalSec, defaults.ICMPPayloadSize, defaults.ICMPTimeoutMs, defaults.AutoRefreshSec, defaults.ProbeMode, defaults.TCPPort, defaults.PayloadPattern)
+	if err != nil {
+		return err
+	}
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO probe_settings_history(effective_from, ping_interval_sec, icmp_payload_bytes)
+		SELECT '-infinity'::timestamptz, ping_interval_sec, icmp_payload_bytes
+		FROM app_settings
+		WHERE id = TRUE
+		  AND NOT EXISTS (SELECT 1 FROM probe_settings_history)
+	`)
 	return err
 }
 
+const insertProbeSettingsHistorySQL = `
+	INSERT INTO probe_settings_history(effective_from, ping_interval_sec, icmp_payload_bytes)
+	SELECT now(), $1::int, $2::int
+	WHERE NOT EXISTS (
+		SELECT 1
+		FROM (
+			SELECT ping_interval_sec, icmp_payload_bytes
+			FROM probe_settings_history
+			ORDER BY effective_from DESC, id DESC
+			LIMIT 1
+		) latest
+		WHERE latest.ping_interval_sec = $1::int
+		  AND latest.icmp_payload_bytes = $2::int
+	)
+`
+
 func (s *Store) GetSettings(ctx context.Context) (model.Settings, error) {
 	settings := model.Settings{}
 	selectColumns := []string{
@@ -171,6 +318,15 @@ func (s *Store) GetSettings(ctx context.Context) (model.Settings, error) {
 		"icmp_payload_bytes",
 		"icmp_timeout_ms",
 		"auto_refresh_sec",
+		"alert_consecutive_failed",
+		"alert_failed_pct",
+		"alert_webhook_url",
+		"probe_mode",
+		"tcp_port",
+		"probes_per_round",
+		"down_threshold",
+		"payload_pattern",
+		"probe_retries",
 	}
 	for slot := 1; slot <= model.MaxCustomFieldSlots; slot++ {
 		selectColumns = append(selectColumns,
@@ -184,6 +340,15 @@ func (s *Store) GetSettings(ctx context.Context) (model.Settings, error) {
 		&settings.ICMPPayloadSize,
 		&settings.ICMPTimeoutMs,
 		&settings.AutoRefreshSec,
+		&settings.AlertConsecutiveFailed,
+		&settings.AlertFailedPct,
+		&settings.AlertWebhookURL,
+		&settings.ProbeMode,
+		&settings.TCPPort,
+		&settings.ProbesPerRound,
+		&settings.DownThreshold,
+		&settings.PayloadPattern,
+		&settings.ProbeRetries,
 	}
 	for slot := 1; slot <= model.MaxCustomFieldSlots; slot++ {
 		customFields[slot-1] = model.CustomFieldConfig{Slot: slot}
@@ -208,12 +373,30 @@ func (s *Store) UpdateSettings(ctx context.Context, settings model.Settings) err
 		"icmp_payload_bytes = $2",
 		"icmp_timeout_ms = $3",
 		"auto_refresh_sec = $4",
+		"alert_consecutive_failed = $5",
+		"alert_failed_pct = $6",
+		"probe_mode = $7",
+		"tcp_port = $8",
+		"probes_per_round = $9",
+		"alert_webhook_url = $10",
+		"down_threshold = $11",
+		"payload_pattern = $12",
+		"probe_retries = $13",
 	}
 	args := []any{
 		settings.PingIntervalSec,
 		settings.ICMPPayloadSize,
 		settings.ICMPTimeoutMs,
 		settings.AutoRefreshSec,
+		settings.AlertConsecutiveFailed,
+		settings.AlertFailedPct,
+		settings.ProbeMode,
+		settings.TCPPort,
+		settings.ProbesPerRound,
+		settings.AlertWebhookURL,
+		settings.DownThreshold,
+		settings.PayloadPattern,
+		settings.ProbeRetries,
 	}
 	for slot := 1; slot <= model.MaxCustomFieldSlots; slot++ {
 		enabledPos := len(args) + 1
@@ -224,7 +407,14 @@ func (s *Store) UpdateSettings(ctx context.Context, settings model.Settings) err
 		setClauses = append(setClauses, fmt.Sprintf("custom_field_%d_name = $%d", slot, namePos))
 	}
 	setClauses = append(setClauses, "updated_at = now()")
-	cmd, err := s.pool.Exec(ctx, `
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	cmd, err := tx.Exec(ctx, `
 			UPDATE app_settings
 			SET `+strings.Join(setClauses, ", ")+`
 			WHERE id = TRUE
@@ -235,7 +425,10 @@ func (s *Store) UpdateSettings(ctx context.Context, settings model.Settings) err
 	if cmd.RowsAffected() == 0 {
 		return errors.New("settings row not found")
 	}
-	return nil
+	if _, err := tx.Exec(ctx, insertProbeSettingsHistorySQL, settings.PingIntervalSec, settings.ICMPPayloadSize); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (s *Store) ListSwitchDirectory(ctx context.Context) ([]model.SwitchDirectoryEntry, error) {
@@ -383,6 +576,7 @@ func (s *Store) InventoryByIP(ctx context.Context) (map[string]model.InventoryEn
 		       vlan, zone, switch_name, port, port_type, COALESCE(host(gateway), ''), COALESCE(host(mgmt_ip), ''),
 		       speed, duplex, description, hostname, is_active, updated_at
 		FROM inventory_endpoint
+		WHERE ip IS NOT NULL
 	`)
 	if err != nil {
 		return nil, err
@@ -421,10 +615,65 @@ func (s *Store) InventoryByIP(ctx context.Context) (map[string]model.InventoryEn
 	return result, rows.Err()
 }
 
-func (s *Store) ApplyImport(ctx context.Context, rows []model.ImportCandidate) (int, int, []string) {
+// importExecer is the pool or a savepointExecer, so ApplyImport and
+// DryRunImport share the row writes.
+type importExecer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// ApplyImport writes add/update rows one at a time. Failures do not stop the
+// import; each is reported in the error strings and its row ID is returned
+// so the caller can retry just those rows.
+func (s *Store) ApplyImport(ctx context.Context, rows []model.ImportCandidate) (int, int, []string, []string) {
+	return applyImportRows(ctx, s.pool, rows)
+}
+
+// DryRunImport runs the same writes as ApplyImport inside a transaction that
+// is always rolled back, so the counts and per-row errors match what an apply
+// would produce at this moment. Each row runs under its own savepoint, so one
+// failing row does not abort the rest.
+func (s *Store) DryRunImport(ctx context.Context, rows []model.ImportCandidate) (int, int, []string, []string, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	added, updated, errorsOut, failedRowIDs := applyImportRows(ctx, savepointExecer{tx: tx}, rows)
+	if err := ctx.Err(); err != nil {
+		return 0, 0, nil, nil, err
+	}
+	return added, updated, errorsOut, failedRowIDs, nil
+}
+
+// savepointExecer wraps each statement in a savepoint so a failed statement
+// leaves the surrounding transaction usable.
+type savepointExecer struct {
+	tx pgx.Tx
+}
+
+func (e savepointExecer) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if _, err := e.tx.Exec(ctx, `SAVEPOINT import_row`); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	cmd, err := e.tx.Exec(ctx, sql, args...)
+	if err != nil {
+		if _, rollbackErr := e.tx.Exec(ctx, `ROLLBACK TO SAVEPOINT import_row`); rollbackErr != nil {
+			return pgconn.CommandTag{}, rollbackErr
+		}
+		return cmd, err
+	}
+	if _, err := e.tx.Exec(ctx, `RELEASE SAVEPOINT import_row`); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return cmd, nil
+}
+
+func applyImportRows(ctx context.Context, exec importExecer, rows []model.ImportCandidate) (int, int, []string, []string) {
 	added := 0
 	updated := 0
 	errorsOut := make([]string, 0)
+	failedRowIDs := make([]string, 0)
 
 	for _, row := range rows {
 		switch row.Action {
@@ -435,7 +684,7 @@ func (s *Store) ApplyImport(ctx context.Context, rows []model.ImportCandidate) (
 				row.VLAN, row.Zone, row.SwitchName, row.Port, row.PortType,
 				row.Gateway, row.MgmtIP, row.Speed, row.Duplex, row.Description, row.Hostname,
 			)
-			cmd, err := s.pool.Exec(ctx, `
+			cmd, err := exec.Exec(ctx, `
 					INSERT INTO inventory_endpoint(
 						ip, mac,
 						custom_field_1_value, custom_field_2_value, custom_field_3_value, custom_field_4_value, custom_field_5_value,
@@ -453,10 +702,12 @@ func (s *Store) ApplyImport(ctx context.Context, rows []model.ImportCandidate) (
 					ON CONFLICT (ip) DO NOTHING
 				`, args...)
 			if err != nil {
+				failedRowIDs = append(failedRowIDs, row.RowID)
 				errorsOut = append(errorsOut, fmt.Sprintf("%s: %v", row.RowID, err))
 				continue
 			}
 			if cmd.RowsAffected() == 0 {
+				failedRowIDs = append(failedRowIDs, row.RowID)
 				errorsOut = append(errorsOut, fmt.Sprintf("%s: endpoint with IP %s already exists", row.RowID, row.IP))
 				continue
 			}
@@ -468,7 +719,7 @@ func (s *Store) ApplyImport(ctx context.Context, rows []model.ImportCandidate) (
 				row.VLAN, row.Zone, row.SwitchName, row.Port, row.PortType,
 				row.Gateway, row.MgmtIP, row.Speed, row.Duplex, row.Description, row.Hostname,
 			)
-			cmd, err := s.pool.Exec(ctx, `
+			cmd, err := exec.Exec(ctx, `
 					UPDATE inventory_endpoint
 					SET mac = COALESCE(NULLIF($2, ''), mac),
 						custom_field_1_value = COALESCE(NULLIF($3, ''), custom_field_1_value),
@@ -496,10 +747,12 @@ func (s *Store) ApplyImport(ctx context.Context, rows []model.ImportCandidate) (
 					WHERE ip = $1::inet
 				`, args...)
 			if err != nil {
+				failedRowIDs = append(failedRowIDs, row.RowID)
 				errorsOut = append(errorsOut, fmt.Sprintf("%s: %v", row.RowID, err))
 				continue
 			}
 			if cmd.RowsAffected() == 0 {
+				failedRowIDs = append(failedRowIDs, row.RowID)
 				errorsOut = append(errorsOut, fmt.Sprintf("%s: endpoint with IP %s not found", row.RowID, row.IP))
 				continue
 			}
@@ -507,7 +760,7 @@ func (s *Store) ApplyImport(ctx context.Context, rows []model.ImportCandidate) (
 		}
 	}
 
-	return added, updated, errorsOut
+	return added, updated, errorsOut, failedRowIDs
 }
 
 func (s *Store) ListGroups(ctx context.Context) ([]model.Group, error) {
@@ -519,7 +772,10 @@ func (s *Store) ListGroups(ctx context.Context) ([]model.Group, error) {
 		       g.created_at,
 		       g.updated_at,
 		       COALESCE(array_agg(gm.endpoint_id) FILTER (WHERE gm.endpoint_id IS NOT NULL), '{}') AS endpoint_ids,
-		       COUNT(*) FILTER (WHERE ie.is_active = TRUE)::BIGINT AS active_endpoint_count
+		       COUNT(*) FILTER (WHERE ie.is_active = TRUE)::BIGINT AS active_endpoint_count,
+		       g.alert_consecutive_failed,
+		       g.alert_failed_pct,
+		       g.probe_interval_sec
 			FROM group_def g
 			LEFT JOIN group_member gm ON gm.group_id = g.id
 			LEFT JOIN inventory_endpoint ie ON ie.id = gm.endpoint_id
@@ -543,6 +799,9 @@ func (s *Store) ListGroups(ctx context.Context) ([]model.Group, error) {
 			&g.UpdatedAt,
 			&g.EndpointIDs,
 			&g.ActiveEndpointCount,
+			&g.AlertThresholds.ConsecutiveFailed,
+			&g.AlertThresholds.FailedPct,
+			&g.ProbeIntervalSec,
 		); err != nil {
 			return nil, err
 		}
@@ -573,16 +832,8 @@ func (s *Store) CreateGroup(ctx context.Context, name string, description string
 	}
 
 	endpointIDs = uniqueInt64(endpointIDs)
-	for _, endpointID := range endpointIDs {
-		if _, err := tx.Exec(ctx, `
-			INSERT INTO group_member(group_id, endpoint_id)
-			VALUES ($1, $2)
-			ON CONFLICT (endpoint_id) DO UPDATE
-			SET group_id = EXCLUDED.group_id
-			WHERE group_member.group_id IS DISTINCT FROM EXCLUDED.group_id
-		`, group.ID, endpointID); err != nil {
-			return model.Group{}, err
-		}
+	if _, err := addGroupMembersTx(ctx, tx, group.ID, endpointIDs); err != nil {
+		return model.Group{}, err
 	}
 	group.EndpointIDs = endpointIDs
 	group.ActiveEndpointCount, err = activeEndpointCountForGroupQuerier(ctx, tx, group.ID)
@@ -653,40 +904,39 @@ func (s *Store) UpdateGroup(ctx context.Context, id int64, name string, descript
 	}
 
 	endpointIDs = uniqueInt64(endpointIDs)
-	for _, endpointID := range endpointIDs {
-		if _, err := tx.Exec(ctx, `
-			INSERT INTO group_member(group_id, endpoint_id)
-			VALUES ($1, $2)
-			ON CONFLICT (endpoint_id) DO UPDATE
-			SET group_id = EXCLUDED.group_id
-			WHERE group_member.group_id IS DISTINCT FROM EXCLUDED.group_id
-		`, id, endpointID); err != nil {
-			return model.Group{}, err
-		}
-	}
-
-	noGroupID, err := getNoGroupIDTx(ctx, tx)
-	if err != nil {
+	if _, err := addGroupMembersTx(ctx, tx, id, endpointIDs); err != nil {
 		return model.Group{}, err
 	}
+
 	removeEndpointIDs := subtractEndpointIDs(currentEndpointIDs, endpointIDs)
-	for _, endpointID := range removeEndpointIDs {
+	if len(removeEndpointIDs) > 0 {
 		if _, err := tx.Exec(ctx, `
-			INSERT INTO group_member(group_id, endpoint_id)
-			VALUES ($1, $2)
-			ON CONFLICT (endpoint_id) DO UPDATE
-			SET group_id = EXCLUDED.group_id
-			WHERE group_member.group_id IS DISTINCT FROM EXCLUDED.group_id
-		`, noGroupID, endpointID); err != nil {
+			DELETE FROM group_member
+			WHERE group_id = $1
+			  AND endpoint_id = ANY($2::bigint[])
+		`, id, removeEndpointIDs); err != nil {
+			return model.Group{}, err
+		}
+		if err := assignUngroupedToNoGroupTx(ctx, tx, removeEndpointIDs); err != nil {
 			return model.Group{}, err
 		}
 	}
 
 	err = tx.QueryRow(ctx, `
-		SELECT id, name, description, is_system, created_at, updated_at
+		SELECT id, name, description, is_system, created_at, updated_at, alert_consecutive_failed, alert_failed_pct, probe_interval_sec
 		FROM group_def
 		WHERE id = $1
-	`, id).Scan(&group.ID, &group.Name, &group.Description, &group.IsSystem, &group.CreatedAt, &group.UpdatedAt)
+	`, id).Scan(
+		&group.ID,
+		&group.Name,
+		&group.Description,
+		&group.IsSystem,
+		&group.CreatedAt,
+		&group.UpdatedAt,
+		&group.AlertThresholds.ConsecutiveFailed,
+		&group.AlertThresholds.FailedPct,
+		&group.ProbeIntervalSec,
+	)
 	if err != nil {
 		return model.Group{}, err
 	}
@@ -706,10 +956,20 @@ func (s *Store) UpdateGroup(ctx context.Context, id int64, name string, descript
 func (s *Store) GetGroupByID(ctx context.Context, id int64) (model.Group, error) {
 	group := model.Group{}
 	err := s.pool.QueryRow(ctx, `
-		SELECT id, name, description, is_system, created_at, updated_at
+		SELECT id, name, description, is_system, created_at, updated_at, alert_consecutive_failed, alert_failed_pct, probe_interval_sec
 		FROM group_def
 		WHERE id = $1
-	`, id).Scan(&group.ID, &group.Name, &group.Description, &group.IsSystem, &group.CreatedAt, &group.UpdatedAt)
+	`, id).Scan(
+		&group.ID,
+		&group.Name,
+		&group.Description,
+		&group.IsSystem,
+		&group.CreatedAt,
+		&group.UpdatedAt,
+		&group.AlertThresholds.ConsecutiveFailed,
+		&group.AlertThresholds.FailedPct,
+		&group.ProbeIntervalSec,
+	)
 	if err != nil {
 		return model.Group{}, err
 	}
@@ -818,19 +1078,93 @@ func (s *Store) AddEndpointsToGroup(ctx context.Context, groupID int64, endpoint
 		return 0, nil
 	}
 
-	cmd, err := s.pool.Exec(ctx, `
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	added, err := addGroupMembersTx(ctx, tx, groupID, endpointIDs)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// addGroupMembersTx adds endpoints to a group without touching their other
+// memberships. Joining a real group drops the "no group" placeholder row, so
+// "no group" only ever holds endpoints that belong nowhere else. It returns
+// the number of memberships created.
+func addGroupMembersTx(ctx context.Context, tx pgx.Tx, groupID int64, endpointIDs []int64) (int64, error) {
+	if len(endpointIDs) == 0 {
+		return 0, nil
+	}
+
+	noGroupID, err := getNoGroupIDTx(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	if groupID == noGroupID {
+		// Endpoints with a real group cannot also sit in "no group".
+		cmd, err := tx.Exec(ctx, `
+			INSERT INTO group_member(group_id, endpoint_id)
+			SELECT $1, candidate.endpoint_id
+			FROM unnest($2::bigint[]) AS candidate(endpoint_id)
+			WHERE NOT EXISTS (
+				SELECT 1 FROM group_member gm WHERE gm.endpoint_id = candidate.endpoint_id
+			)
+			ON CONFLICT DO NOTHING
+		`, groupID, endpointIDs)
+		if err != nil {
+			return 0, err
+		}
+		return cmd.RowsAffected(), nil
+	}
+
+	cmd, err := tx.Exec(ctx, `
 		INSERT INTO group_member(group_id, endpoint_id)
 		SELECT $1, unnest($2::bigint[])
-		ON CONFLICT (endpoint_id) DO UPDATE
-		SET group_id = EXCLUDED.group_id
-		WHERE group_member.group_id IS DISTINCT FROM EXCLUDED.group_id
+		ON CONFLICT (group_id, endpoint_id) DO NOTHING
 	`, groupID, endpointIDs)
 	if err != nil {
 		return 0, err
 	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM group_member
+		WHERE group_id = $1
+		  AND endpoint_id = ANY($2::bigint[])
+	`, noGroupID, endpointIDs); err != nil {
+		return 0, err
+	}
 	return cmd.RowsAffected(), nil
 }
 
+// assignUngroupedToNoGroupTx puts any of endpointIDs that no longer belong to
+// a group into "no group".
+func assignUngroupedToNoGroupTx(ctx context.Context, tx pgx.Tx, endpointIDs []int64) error {
+	if len(endpointIDs) == 0 {
+		return nil
+	}
+	noGroupID, err := getNoGroupIDTx(ctx, tx)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO group_member(group_id, endpoint_id)
+		SELECT $1, ie.id
+		FROM inventory_endpoint ie
+		WHERE ie.id = ANY($2::bigint[])
+		  AND NOT EXISTS (
+			SELECT 1 FROM group_member gm WHERE gm.endpoint_id = ie.id
+		  )
+		ON CONFLICT DO NOTHING
+	`, noGroupID, endpointIDs)
+	return err
+}
+
 func (s *Store) DeleteGroup(ctx context.Context, id int64) error {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -849,11 +1183,25 @@ func (s *Store) DeleteGroup(ctx context.Context, id int64) error {
 		return ErrSystemGroupMutable
 	}
 
-	noGroupID, err := getNoGroupIDTx(ctx, tx)
+	rows, err := tx.Query(ctx, `DELETE FROM group_member WHERE group_id = $1 RETURNING endpoint_id`, id)
 	if err != nil {
 		return err
 	}
-	if _, err := tx.Exec(ctx, `UPDATE group_member SET group_id = $1 WHERE group_id = $2`, noGroupID, id); err != nil {
+	memberIDs := make([]int64, 0)
+	for rows.Next() {
+		var endpointID int64
+		if err := rows.Scan(&endpointID); err != nil {
+			rows.Close()
+			return err
+		}
+		memberIDs = append(memberIDs, endpointID)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+	if err := assignUngroupedToNoGroupTx(ctx, tx, memberIDs); err != nil {
 		return err
 	}
 
@@ -873,22 +1221,29 @@ func (s *Store) DeleteGroup(ctx context.Context, id int64) error {
 
 func (s *Store) ListProbeTargets(ctx context.Context, scope string, groupIDs []int64) ([]ProbeTarget, error) {
 	query := `
-		SELECT DISTINCT ie.id, host(ie.ip), ie.hostname
-		FROM inventory_endpoint ie
+		SELECT ie.id, COALESCE(host(ie.ip), ''), ie.hostname, ie.probe_mode, ie.probe_http_target, ie.probe_tcp_port, COALESCE(ie.probe_interval_sec, 0)
 	`
 	args := []any{}
 
 	switch scope {
 	case "all":
-		query += ` WHERE ie.is_active = TRUE ORDER BY ie.id`
+		query += `, 0
+			FROM inventory_endpoint ie
+			WHERE ie.is_active = TRUE AND ie.monitoring_enabled = TRUE
+			ORDER BY ie.id
+		`
 	case "groups":
 		if len(groupIDs) == 0 {
 			return nil, errors.New("group_ids required for groups scope")
 		}
-		query += `
+		query += `, COALESCE(MIN(g.probe_interval_sec), 0)
+			FROM inventory_endpoint ie
 			JOIN group_member gm ON gm.endpoint_id = ie.id
+			JOIN group_def g ON g.id = gm.group_id
 			WHERE gm.group_id = ANY($1)
 			  AND ie.is_active = TRUE
+			  AND ie.monitoring_enabled = TRUE
+			GROUP BY ie.id
 			ORDER BY ie.id
 		`
 		args = append(args, uniqueInt64(groupIDs))
@@ -905,7 +1260,7 @@ func (s *Store) ListProbeTargets(ctx context.Context, scope string, groupIDs []i
 	targets := []ProbeTarget{}
 	for rows.Next() {
 		var t ProbeTarget
-		if err := rows.Scan(&t.EndpointID, &t.IP, &t.Hostname); err != nil {
+		if err := rows.Scan(&t.EndpointID, &t.IP, &t.Hostname, &t.ProbeMode, &t.HTTPTarget, &t.TCPPort, &t.ProbeIntervalSec, &t.GroupIntervalSec); err != nil {
 			return nil, err
 		}
 		targets = append(targets, t)
@@ -914,11 +1269,50 @@ func (s *Store) ListProbeTargets(ctx context.Context, scope string, groupIDs []i
 }
 
 const insertPingRawSQL = `
-	INSERT INTO ping_raw(ts, endpoint_id, success, latency_ms, reply_ip, ttl, error_code, payload_bytes)
-	VALUES ($1::timestamptz, $2::bigint, $3::boolean, $4::double precision, NULLIF($5, '')::inet, $6::int, $7::text, $8::int)
+	INSERT INTO ping_raw(ts, endpoint_id, success, latency_ms, reply_ip, ttl, error_code, payload_bytes, http_status, target_ip, min_latency_ms, max_latency_ms, jitter_ms, loss_pct, fallback_probe, maintenance, duplicate_replies, ip_mismatch, retries, out_of_order_replies, drift_ms)
+	VALUES (
+		$1::timestamptz, $2::bigint, $3::boolean, $4::double precision, NULLIF($5, '')::inet, $6::int, $7::text, $8::int, $9::int, NULLIF($10, '')::inet,
+		$11::double precision, $12::double precision, $13::double precision, $14::double precision, NULLIF($15, ''), $16::boolean, $17::int, $18::boolean, $19::int, $20::int, $21::double precision
+	)
+	ON CONFLICT (ts, endpoint_id) DO NOTHING
+`
+
+const insertPingRawOmitSettingsPayloadSQL = `
+	INSERT INTO ping_raw(ts, endpoint_id, success, latency_ms, reply_ip, ttl, error_code, payload_bytes, http_status, target_ip, min_latency_ms, max_latency_ms, jitter_ms, loss_pct, fallback_probe, maintenance, duplicate_replies, ip_mismatch, retries, out_of_order_replies, drift_ms)
+	VALUES (
+		$1::timestamptz, $2::bigint, $3::boolean, $4::double precision, NULLIF($5, '')::inet, $6::int, $7::text,
+		NULLIF($8::int, probe_payload_bytes_at($1::timestamptz)),
+		$9::int, NULLIF($10, '')::inet,
+		$11::double precision, $12::double precision, $13::double precision, $14::double precision, NULLIF($15, ''), $16::boolean, $17::int, $18::boolean, $19::int, $20::int, $21::double precision
+	)
 	ON CONFLICT (ts, endpoint_id) DO NOTHING
 `
 
+// trackDowntimeIncidentSQL closes the endpoint's open incident on a success
+// and opens one on a failure. It keys off the open row rather than the
+// previous result, so an incident left open across an engine restart is
+// closed by the next success and a host that was already failing with no
+// open incident gets one on its first recorded failure. A failure inside a
+// maintenance window ($5) never opens one. The data-modifying CTE always runs
+// even though the INSERT does not reference it.
+const trackDowntimeIncidentSQL = `
+	WITH closed AS (
+		UPDATE downtime_incident
+		SET
+			ended_at = $3::timestamptz,
+			duration_sec = EXTRACT(EPOCH FROM ($3::timestamptz - started_at))
+		WHERE $2::boolean = TRUE
+		  AND endpoint_id = $1::bigint
+		  AND ended_at IS NULL
+		  AND started_at <= $3::timestamptz
+		RETURNING id
+	)
+	INSERT INTO downtime_incident(endpoint_id, started_at, error_code)
+	SELECT $1::bigint, $3::timestamptz, $4::text
+	WHERE $2::boolean = FALSE AND $5::boolean = FALSE
+	ON CONFLICT (endpoint_id) WHERE ended_at IS NULL DO NOTHING
+`
+
 const upsertEndpointStatsCurrentSQL = `
 	INSERT INTO endpoint_stats_current(
 		endpoint_id,
@@ -935,6 +1329,11 @@ const upsertEndpointStatsCurrentSQL = `
 		last_ping_latency,
 		average_latency,
 		reply_ip_address,
+		last_ttl,
+		last_error_detail,
+		duplicate_reply_count,
+		last_ip_mismatch,
+		last_fallback_probe,
 		updated_at
 	)
 	VALUES (
@@ -952,6 +1351,11 @@ const upsertEndpointStatsCurrentSQL = `
 		$5::double precision,
 		$5::double precision,
 		NULLIF($6, '')::inet,
+		$7::int,
+		$8::text,
+		$9::int,
+		$10::boolean,
+		$11::text,
 		now()
 	)
 	ON CONFLICT (endpoint_id) DO UPDATE SET
@@ -971,7 +1375,7 @@ const upsertEndpointStatsCurrentSQL = `
 		total_sent_ping = endpoint_stats_current.total_sent_ping + 1,
 		failed_pct = (
 			(endpoint_stats_current.failed_count + CASE WHEN $2::boolean = FALSE THEN 1 ELSE 0 END)::DOUBLE PRECISION /
-			(endpoint_stats_current.total_sent_ping + 1)::DOUBLE PRECISION
+			(endpoint_stats_current.success_count + endpoint_stats_current.failed_count + 1)::DOUBLE PRECISION
 		) * 100,
 		last_ping_status = $4::text,
 		last_ping_latency = $5::double precision,
@@ -983,25 +1387,92 @@ const upsertEndpointStatsCurrentSQL = `
 			ELSE endpoint_stats_current.average_latency
 		END,
 		reply_ip_address = NULLIF($6, '')::inet,
+		last_ttl = $7::int,
+		last_error_detail = $8::text,
+		duplicate_reply_count = endpoint_stats_current.duplicate_reply_count + $9::int,
+		last_ip_mismatch = $10::boolean,
+		last_fallback_probe = $11::text,
 		updated_at = now()
 `
 
+// upsertEndpointStatsMaintenanceSQL takes the arguments of
+// upsertEndpointStatsCurrentSQL, minus the duplicate reply count (so the IP
+// mismatch flag and fallback probe move up to $9 and $10), for a probe
+// inside a maintenance window. It refreshes the last-probe fields and
+// last_success_on but leaves every counter and the failure streak as they
+// were, so the window neither adds failures nor hides a streak that was
+// already open when it started.
+const upsertEndpointStatsMaintenanceSQL = `
+	INSERT INTO endpoint_stats_current(
+		endpoint_id,
+		last_success_on,
+		last_ping_status,
+		last_ping_latency,
+		reply_ip_address,
+		last_ttl,
+		last_error_detail,
+		last_ip_mismatch,
+		last_fallback_probe,
+		updated_at
+	)
+	VALUES (
+		$1::bigint,
+		CASE WHEN $2::boolean = TRUE THEN $3::timestamptz ELSE NULL END,
+		$4::text,
+		$5::double precision,
+		NULLIF($6, '')::inet,
+		$7::int,
+		$8::text,
+		$9::boolean,
+		$10::text,
+		now()
+	)
+	ON CONFLICT (endpoint_id) DO UPDATE SET
+		last_success_on = CASE WHEN $2::boolean = TRUE THEN $3::timestamptz ELSE endpoint_stats_current.last_success_on END,
+		last_ping_status = $4::text,
+		last_ping_latency = $5::double precision,
+		reply_ip_address = NULLIF($6, '')::inet,
+		last_ttl = $7::int,
+		last_error_detail = $8::text,
+		last_ip_mismatch = $9::boolean,
+		last_fallback_probe = $10::text,
+		updated_at = now()
+`
+
+// lastErrorDetailMaxLen caps the raw error text kept per endpoint.
+const lastErrorDetailMaxLen = 512
+
 type pingResultWriteValues struct {
 	status       string
+	errorDetail  string
 	latencyValue any
 	ttlValue     any
+	httpStatus   any
+	minLatency   any
+	maxLatency   any
+	jitter       any
+	lossPct      any
 	replyIP      string
+	targetIP     string
+	maintenance  bool
 }
 
-func buildPingResultWriteValues(result model.PingResult) pingResultWriteValues {
+// buildPingResultWriteValues prepares the shared statement arguments. A probe
+// inside a maintenance window keeps its outcome in ping_raw but reports the
+// Maintenance status.
+func buildPingResultWriteValues(result model.PingResult, inMaintenance bool) pingResultWriteValues {
 	values := pingResultWriteValues{
-		status:  "Request Timeout",
-		replyIP: derefString(result.ReplyIP),
+		status:   "Request Timeout",
+		replyIP:  derefString(result.ReplyIP),
+		targetIP: derefString(result.TargetIP),
 	}
 	if result.Success {
 		values.status = "Succeeded"
-	} else if result.ErrorCode != "" {
-		values.status = result.ErrorCode
+	} else {
+		if result.ErrorCode != "" {
+			values.status = result.ErrorCode
+		}
+		values.errorDetail = truncateErrorDetail(result.ErrorDetail)
 	}
 	if result.LatencyMs != nil {
 		values.latencyValue = *result.LatencyMs
@@ -1009,68 +1480,101 @@ func buildPingResultWriteValues(result model.PingResult) pingResultWriteValues {
 	if result.TTL != nil {
 		values.ttlValue = *result.TTL
 	}
+	if result.HTTPStatus != nil {
+		values.httpStatus = *result.HTTPStatus
+	}
+	if result.MinLatencyMs != nil {
+		values.minLatency = *result.MinLatencyMs
+	}
+	if result.MaxLatencyMs != nil {
+		values.maxLatency = *result.MaxLatencyMs
+	}
+	if result.JitterMs != nil {
+		values.jitter = *result.JitterMs
+	}
+	if result.LossPct != nil {
+		values.lossPct = *result.LossPct
+	}
+	if inMaintenance {
+		values.status = model.PingStatusMaintenance
+		values.maintenance = true
+	}
 	return values
 }
 
-func (s *Store) RecordPingResult(ctx context.Context, result model.PingResult) error {
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		return err
+// truncateErrorDetail trims detail to lastErrorDetailMaxLen bytes without
+// splitting a UTF-8 sequence.
+func truncateErrorDetail(detail string) string {
+	detail = strings.TrimSpace(detail)
+	if len(detail) <= lastErrorDetailMaxLen {
+		return detail
 	}
-	defer func() { _ = tx.Rollback(ctx) }()
-
-	values := buildPingResultWriteValues(result)
-
-	if _, err := tx.Exec(ctx, insertPingRawSQL, result.Timestamp, result.EndpointID, result.Success, values.latencyValue, values.replyIP, values.ttlValue, result.ErrorCode, result.PayloadBytes); err != nil {
-		return err
+	cut := lastErrorDetailMaxLen
+	for cut > 0 && !utf8.RuneStart(detail[cut]) {
+		cut--
 	}
+	return detail[:cut]
+}
 
-	if _, err := tx.Exec(ctx, upsertEndpointStatsCurrentSQL, result.EndpointID, result.Success, result.Timestamp, values.status, values.latencyValue, values.replyIP); err != nil {
-		return err
+func (v pingResultWriteValues) statsQuery() string {
+	if v.maintenance {
+		return upsertEndpointStatsMaintenanceSQL
 	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return err
-	}
-	return nil
+	return upsertEndpointStatsCurrentSQL
 }
 
-func (s *Store) RecordPingResultsBatch(ctx context.Context, results []model.PingResult) error {
-	if len(results) == 0 {
-		return nil
+// statsArgs returns the arguments for statsQuery.
+func (v pingResultWriteValues) statsArgs(result model.PingResult) []any {
+	args := []any{result.EndpointID, result.Success, result.Timestamp, v.status, v.latencyValue, v.replyIP, v.ttlValue, v.errorDetail}
+	if !v.maintenance {
+		args = append(args, result.DuplicateReplies)
 	}
+	return append(args, result.IPMismatch, result.FallbackProbe)
+}
 
+func (s *Store) RecordPingResult(ctx context.Context, result model.PingResult) error {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	var batch pgx.Batch
-	for _, result := range results {
-		values := buildPingResultWriteValues(result)
-		batch.Queue(insertPingRawSQL, result.Timestamp, result.EndpointID, result.Success, values.latencyValue, values.replyIP, values.ttlValue, result.ErrorCode, result.PayloadBytes)
-		batch.Queue(upsertEndpointStatsCurrentSQL, result.EndpointID, result.Success, result.Timestamp, values.status, values.latencyValue, values.replyIP)
+	windows, err := s.activeMaintenance(ctx)
+	if err != nil {
+		return err
+	}
+	values := buildPingResultWriteValues(result, windows.Covers(result.EndpointID, result.Timestamp))
+
+	if _, err := tx.Exec(ctx, s.insertPingRawQuery(), result.Timestamp, result.EndpointID, result.Success, values.latencyValue, values.replyIP, values.ttlValue, result.ErrorCode, result.PayloadBytes, values.httpStatus, values.targetIP, values.minLatency, values.maxLatency, values.jitter, values.lossPct, result.FallbackProbe, values.maintenance, result.DuplicateReplies, result.IPMismatch, result.Retries, result.OutOfOrderReplies, result.DriftMs); err != nil {
+		return err
 	}
 
-	br := tx.SendBatch(ctx, &batch)
-	for range results {
-		if _, err := br.Exec(); err != nil {
-			_ = br.Close()
-			return err
-		}
-		if _, err := br.Exec(); err != nil {
-			_ = br.Close()
+	alerting := s.alertingEnabled()
+	var state alertState
+	stateFound := false
+	if alerting {
+		state, stateFound, err = scanAlertState(tx.QueryRow(ctx, selectAlertStateSQL, result.EndpointID, result.Timestamp))
+		if err != nil {
 			return err
 		}
 	}
-	if err := br.Close(); err != nil {
+
+	if _, err := tx.Exec(ctx, values.statsQuery(), values.statsArgs(result)...); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, trackDowntimeIncidentSQL, result.EndpointID, result.Success, result.Timestamp, result.ErrorCode, values.maintenance); err != nil {
 		return err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
+	if stateFound && !values.maintenance {
+		if transition, ok := state.transition(result); ok {
+			s.alertSink.Observe(transition)
+		}
+	}
 	return nil
 }
 
@@ -1080,10 +1584,11 @@ func (s *Store) ListMonitorEndpoints(ctx context.Context, filters MonitorFilters
 			ie.id,
 				ie.hostname,
 				es.last_failed_on,
-				host(ie.ip) AS ip_address,
+				COALESCE(host(ie.ip), '') AS ip_address,
 				ie.mac,
 				` + customFieldValueColumns("ie") + `,
 				COALESCE(host(es.reply_ip_address), NULL) AS reply_ip_address,
+				es.last_ttl,
 				es.last_success_on,
 			COALESCE(es.success_count, 0) AS success_count,
 			COALESCE(es.failed_count, 0) AS failed_count,
@@ -1092,7 +1597,12 @@ func (s *Store) ListMonitorEndpoints(ctx context.Context, filters MonitorFilters
 			es.max_consecutive_failed_count_time,
 			COALESCE(es.failed_pct, 0) AS failed_pct,
 			COALESCE(es.total_sent_ping, 0) AS total_sent_ping,
-			COALESCE(es.last_ping_status, 'unknown') AS last_ping_status,
+			` + s.liveStatusExpression() + ` AS last_ping_status,
+			COALESCE(es.last_error_detail, '') AS last_error_detail,
+			` + s.monitorStatus().isDown("es") + ` AS is_down,
+			COALESCE(es.duplicate_reply_count, 0) AS duplicate_reply_count,
+			COALESCE(es.last_ip_mismatch, FALSE) AS ip_mismatch,
+			COALESCE(es.last_fallback_probe, '') AS fallback_probe,
 			es.last_ping_latency,
 				es.average_latency,
 				ie.vlan,
@@ -1137,12 +1647,16 @@ func (s *Store) ListMonitorEndpoints(ctx context.Context, filters MonitorFilters
 		`, len(args)+1)
 		args = append(args, filters.GroupNames)
 	}
+	if len(filters.CIDRs) > 0 {
+		query += fmt.Sprintf(" AND ie.ip <<= ANY($%d::cidr[])", len(args)+1)
+		args = append(args, filters.CIDRs)
+	}
 
 	query += `
-		GROUP BY ie.id, ie.hostname, es.last_failed_on, ie.ip, ie.mac, es.reply_ip_address,
+		GROUP BY ie.id, ie.hostname, es.last_failed_on, ie.ip, ie.mac, es.reply_ip_address, es.last_ttl,
 			es.last_success_on, es.success_count, es.failed_count, es.consecutive_failed_count,
 				es.max_consecutive_failed_count, es.max_consecutive_failed_count_time, es.failed_pct,
-				es.total_sent_ping, es.last_ping_status, es.last_ping_latency, es.average_latency,
+				es.total_sent_ping, es.last_ping_status, es.last_error_detail, es.duplicate_reply_count, es.last_ip_mismatch, es.last_fallback_probe, es.last_ping_latency, es.average_latency, es.updated_at,
 				ie.vlan, ie.zone, ie.switch_name, ie.port, ie.port_type, ie.gateway, ie.mgmt_ip, ie.speed, ie.duplex,
 				` + customFieldValueColumns("ie") + `
 		ORDER BY ie.ip
@@ -1167,6 +1681,7 @@ func (s *Store) ListMonitorEndpoints(ctx context.Context, filters MonitorFilters
 		scanTargets = append(scanTargets, monitorEndpointCustomFieldScanTargets(&item)...)
 		scanTargets = append(scanTargets,
 			&item.ReplyIPAddress,
+			&item.LastTTL,
 			&item.LastSuccessOn,
 			&item.SuccessCount,
 			&item.FailedCount,
@@ -1176,6 +1691,11 @@ func (s *Store) ListMonitorEndpoints(ctx context.Context, filters MonitorFilters
 			&item.FailedPct,
 			&item.TotalSentPing,
 			&item.LastPingStatus,
+			&item.LastErrorDetail,
+			&item.IsDown,
+			&item.DuplicateReplyCount,
+			&item.IPMismatch,
+			&item.FallbackProbe,
 			&item.LastPingLatency,
 			&item.AverageLatency,
 			&item.VLAN,
@@ -1199,12 +1719,14 @@ func (s *Store) ListMonitorEndpoints(ctx context.Context, filters MonitorFilters
 
 func (s *Store) ListMonitorEndpointsPage(ctx context.Context, query MonitorPageQuery) ([]model.MonitorEndpoint, int64, error) {
 	whereClause, args := buildMonitorWhereClause(
+		s.monitorStatus(),
 		query.Filters,
 		query.Hostname,
 		query.MAC,
 		query.CustomSearches,
 		query.IPList,
 		query.ExcludeEndpointIDs,
+		query.Search,
 	)
 
 	countSQL := `SELECT COUNT(*) FROM inventory_endpoint ie` + whereClause
@@ -1228,17 +1750,42 @@ func (s *Store) ListMonitorEndpointsPage(ctx context.Context, query MonitorPageQ
 	return items, totalItems, nil
 }
 
+// StreamMonitorEndpoints walks every monitor row matching the query in the
+// page query's sort order, without LIMIT/OFFSET and without buffering the
+// result set. Page and PageSize are ignored.
+func (s *Store) StreamMonitorEndpoints(ctx context.Context, query MonitorPageQuery, fn func(model.MonitorEndpoint) error) error {
+	whereClause, args := buildMonitorWhereClause(
+		s.monitorStatus(),
+		query.Filters,
+		query.Hostname,
+		query.MAC,
+		query.CustomSearches,
+		query.IPList,
+		query.ExcludeEndpointIDs,
+		query.Search,
+	)
+
+	query.Page = 1
+	query.PageSize = 0
+	if query.StatsScope == "range" {
+		return s.scanMonitorEndpointsRange(ctx, query, whereClause, args, fn)
+	}
+	return s.scanMonitorEndpointsLive(ctx, query, whereClause, args, fn)
+}
+
 func (s *Store) DashboardUnreachableSummary(
 	ctx context.Context,
 	query MonitorPageQuery,
 ) (model.DashboardUnreachableSummary, error) {
 	whereClause, args := buildMonitorWhereClause(
+		s.monitorStatus(),
 		query.Filters,
 		query.Hostname,
 		query.MAC,
 		query.CustomSearches,
 		query.IPList,
 		query.ExcludeEndpointIDs,
+		query.Search,
 	)
 
 	summaryQuery, summaryArgs := buildDashboardSummaryQuery(query, whereClause, args)
@@ -1284,10 +1831,7 @@ func buildDashboardSummaryQuery(query MonitorPageQuery, whereClause string, args
 	var unreachableCTE string
 
 	if query.StatsScope == "range" {
-		viewName := "ping_1m"
-		if query.End.Sub(query.Start) > 48*time.Hour {
-			viewName = "ping_1h"
-		}
+		viewName, bucketWidth := rangeAggregateView(query.Start, query.End)
 
 		startPos := len(baseArgs) + 1
 		endPos := len(baseArgs) + 2
@@ -1298,8 +1842,7 @@ func buildDashboardSummaryQuery(query MonitorPageQuery, whereClause string, args
 				SELECT
 					endpoint_id,
 					SUM(fail_count)::BIGINT AS failed_count
-				FROM %s
-				WHERE bucket >= $%d AND bucket <= $%d
+				FROM (%s) rb
 				GROUP BY endpoint_id
 			),
 			unreachable AS (
@@ -1311,7 +1854,7 @@ func buildDashboardSummaryQuery(query MonitorPageQuery, whereClause string, args
 				%s
 				  AND COALESCE(rs.failed_count, 0) > 0
 			)
-		`, viewName, startPos, endPos, switchLabelExpr, whereClause)
+		`, rangeBucketsSQL(viewName, bucketWidth, startPos, endPos, 0, query.ExcludeMaintenance), switchLabelExpr, whereClause)
 	} else if query.Lookback > 0 {
 		intervalPos := len(baseArgs) + 1
 		baseArgs = append(baseArgs, fmt.Sprintf("%d seconds", int(query.Lookback.Seconds())))
@@ -1342,7 +1885,7 @@ func buildDashboardSummaryQuery(query MonitorPageQuery, whereClause string, args
 					COALESCE(es.consecutive_failed_count, 0) > 0 OR
 					(
 						NULLIF(lower(btrim(COALESCE(es.last_ping_status, ''))), '') IS NOT NULL AND
-						lower(btrim(COALESCE(es.last_ping_status, ''))) <> 'succeeded'
+						lower(btrim(COALESCE(es.last_ping_status, ''))) NOT IN ('succeeded', 'maintenance')
 					)
 				  )
 			)
@@ -1416,30 +1959,78 @@ func dashboardSummaryFromQueryRows(rows []dashboardSummaryQueryRow) (model.Dashb
 }
 
 func (s *Store) listMonitorEndpointsPageLive(ctx context.Context, query MonitorPageQuery, whereClause string, args []any) ([]model.MonitorEndpoint, error) {
-	orderClause, err := buildMonitorOrderClause(query.SortCriteria, monitorSortExpression)
+	items := []model.MonitorEndpoint{}
+	err := s.scanMonitorEndpointsLive(ctx, query, whereClause, args, func(item model.MonitorEndpoint) error {
+		items = append(items, item)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return items, nil
+}
+
+// scanMonitorEndpointsLive runs the live monitor query and hands each row to
+// fn as it is read. A PageSize of 0 drops LIMIT/OFFSET.
+func (s *Store) scanMonitorEndpointsLive(ctx context.Context, query MonitorPageQuery, whereClause string, args []any, fn func(model.MonitorEndpoint) error) error {
+	return s.scanMonitorEndpointsLiveKeyset(ctx, query, whereClause, args, nil, fn)
+}
+
+// scanMonitorEndpointsLiveKeyset is scanMonitorEndpointsLive with optional
+// keyset paging: with a non-nil keyset the rows are ordered by its keys,
+// start after its cursor, skip OFFSET, and report each row's sort key back.
+func (s *Store) scanMonitorEndpointsLiveKeyset(ctx context.Context, query MonitorPageQuery, whereClause string, args []any, keyset *monitorKeyset, fn func(model.MonitorEndpoint) error) error {
+	orderClause, err := buildMonitorOrderClause(query.SortCriteria, s.monitorSortExpression)
+	if err != nil {
+		return err
+	}
+	cursorColumns := ""
+	if keyset != nil {
+		orderClause = keyset.orderClause()
+		whereClause, args = keyset.whereClause(whereClause, args)
+		cursorColumns = keyset.selectColumns()
+	}
+
+	itemsArgs := append([]any{}, args...)
+	limitClause := ""
+	if keyset != nil {
+		limitClause = fmt.Sprintf("LIMIT $%d", len(args)+1)
+		itemsArgs = append(itemsArgs, keyset.limit)
+	} else if query.PageSize > 0 {
+		limitClause = fmt.Sprintf("LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+		itemsArgs = append(itemsArgs, query.PageSize, (query.Page-1)*query.PageSize)
+	}
 
+	// The page is cut before group names are collected, so the aggregate
+	// only runs for the rows returned. page_pos carries the order out of the
+	// subquery.
 	itemsSQL := `
-		SELECT
-			ie.id,
+		SELECT page.*, COALESCE(g.groups, '{}') AS groups
+		FROM (
+			SELECT
+				ie.id,
 				ie.hostname,
 				es.last_failed_on,
-				host(ie.ip) AS ip_address,
+				COALESCE(host(ie.ip), '') AS ip_address,
 				ie.mac,
 				` + customFieldValueColumns("ie") + `,
 				COALESCE(host(es.reply_ip_address), NULL) AS reply_ip_address,
-			es.last_success_on,
-			COALESCE(es.success_count, 0) AS success_count,
-			COALESCE(es.failed_count, 0) AS failed_count,
-			COALESCE(es.consecutive_failed_count, 0) AS consecutive_failed_count,
-			COALESCE(es.max_consecutive_failed_count, 0) AS max_consecutive_failed_count,
-			es.max_consecutive_failed_count_time,
-			COALESCE(es.failed_pct, 0) AS failed_pct,
-			COALESCE(es.total_sent_ping, 0) AS total_sent_ping,
-			COALESCE(es.last_ping_status, 'unknown') AS last_ping_status,
-			es.last_ping_latency,
+				es.last_ttl,
+				es.last_success_on,
+				COALESCE(es.success_count, 0) AS success_count,
+				COALESCE(es.failed_count, 0) AS failed_count,
+				COALESCE(es.consecutive_failed_count, 0) AS consecutive_failed_count,
+				COALESCE(es.max_consecutive_failed_count, 0) AS max_consecutive_failed_count,
+				es.max_consecutive_failed_count_time,
+				COALESCE(es.failed_pct, 0) AS failed_pct,
+				COALESCE(es.total_sent_ping, 0) AS total_sent_ping,
+				` + s.liveStatusExpression() + ` AS last_ping_status,
+				COALESCE(es.last_error_detail, '') AS last_error_detail,
+				` + s.monitorStatus().isDown("es") + ` AS is_down,
+				COALESCE(es.duplicate_reply_count, 0) AS duplicate_reply_count,
+				COALESCE(es.last_ip_mismatch, FALSE) AS ip_mismatch,
+				COALESCE(es.last_fallback_probe, '') AS fallback_probe,
+				es.last_ping_latency,
 				es.average_latency,
 				ie.vlan,
 				ie.zone,
@@ -1449,37 +2040,33 @@ func (s *Store) listMonitorEndpointsPageLive(ctx context.Context, query MonitorP
 				COALESCE(host(ie.gateway), '') AS gateway,
 				COALESCE(host(ie.mgmt_ip), '') AS mgmt_ip,
 				ie.speed,
-				ie.duplex,
-				COALESCE(array_remove(array_agg(DISTINCT gd.name), NULL), '{}') AS groups
-		FROM inventory_endpoint ie
-		LEFT JOIN endpoint_stats_current es ON es.endpoint_id = ie.id
-		LEFT JOIN group_member gm ON gm.endpoint_id = ie.id
-		LEFT JOIN group_def gd ON gd.id = gm.group_id
-	` + whereClause + `
-		GROUP BY ie.id, ie.hostname, es.last_failed_on, ie.ip, ie.mac, es.reply_ip_address,
-			es.last_success_on, es.success_count, es.failed_count, es.consecutive_failed_count,
-				es.max_consecutive_failed_count, es.max_consecutive_failed_count_time, es.failed_pct,
-				es.total_sent_ping, es.last_ping_status, es.last_ping_latency, es.average_latency,
-				ie.vlan, ie.zone, ie.switch_name, ie.port, ie.port_type, ie.gateway, ie.mgmt_ip, ie.speed, ie.duplex,
-				` + customFieldValueColumns("ie") + `
-		ORDER BY ` + orderClause + `
-		LIMIT $%d OFFSET $%d
+				ie.duplex` + cursorColumns + `,
+				ROW_NUMBER() OVER (ORDER BY ` + orderClause + `) AS page_pos
+			FROM inventory_endpoint ie
+			LEFT JOIN endpoint_stats_current es ON es.endpoint_id = ie.id
+		` + whereClause + `
+			ORDER BY ` + orderClause + `
+			` + limitClause + `
+		) page
+		LEFT JOIN LATERAL (
+			SELECT array_agg(DISTINCT gd.name) AS groups
+			FROM group_member gm
+			JOIN group_def gd ON gd.id = gm.group_id
+			WHERE gm.endpoint_id = page.id
+		) g ON TRUE
+		ORDER BY page.page_pos
 	`
 
-	limitPos := len(args) + 1
-	offsetPos := len(args) + 2
-	itemsSQL = fmt.Sprintf(itemsSQL, limitPos, offsetPos)
-	itemsArgs := append(append([]any{}, args...), query.PageSize, (query.Page-1)*query.PageSize)
-
 	rows, err := s.pool.Query(ctx, itemsSQL, itemsArgs...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	items := []model.MonitorEndpoint{}
 	for rows.Next() {
 		var item model.MonitorEndpoint
+		var sortKey, cursorIP *string
+		var pagePos int64
 		scanTargets := []any{
 			&item.EndpointID,
 			&item.Hostname,
@@ -1490,6 +2077,7 @@ func (s *Store) listMonitorEndpointsPageLive(ctx context.Context, query MonitorP
 		scanTargets = append(scanTargets, monitorEndpointCustomFieldScanTargets(&item)...)
 		scanTargets = append(scanTargets,
 			&item.ReplyIPAddress,
+			&item.LastTTL,
 			&item.LastSuccessOn,
 			&item.SuccessCount,
 			&item.FailedCount,
@@ -1499,6 +2087,11 @@ func (s *Store) listMonitorEndpointsPageLive(ctx context.Context, query MonitorP
 			&item.FailedPct,
 			&item.TotalSentPing,
 			&item.LastPingStatus,
+			&item.LastErrorDetail,
+			&item.IsDown,
+			&item.DuplicateReplyCount,
+			&item.IPMismatch,
+			&item.FallbackProbe,
 			&item.LastPingLatency,
 			&item.AverageLatency,
 			&item.VLAN,
@@ -1510,32 +2103,114 @@ func (s *Store) listMonitorEndpointsPageLive(ctx context.Context, query MonitorP
 			&item.MgmtIP,
 			&item.Speed,
 			&item.Duplex,
-			&item.Groups,
 		)
+		if cursorColumns != "" {
+			scanTargets = append(scanTargets, &sortKey, &cursorIP)
+		}
+		scanTargets = append(scanTargets, &pagePos, &item.Groups)
 		if err := rows.Scan(scanTargets...); err != nil {
-			return nil, err
+			return err
+		}
+		if keyset != nil {
+			keyset.sortKeys = append(keyset.sortKeys, sortKey)
+			keyset.ips = append(keyset.ips, cursorIP)
+		}
+		if err := fn(item); err != nil {
+			return err
 		}
-		items = append(items, item)
 	}
 
-	return items, rows.Err()
+	return rows.Err()
 }
 
 func (s *Store) listMonitorEndpointsPageRange(ctx context.Context, query MonitorPageQuery, whereClause string, args []any) ([]model.MonitorEndpoint, error) {
-	orderClause, err := buildMonitorOrderClause(query.SortCriteria, monitorRangeSortExpression)
+	items := []model.MonitorEndpoint{}
+	err := s.scanMonitorEndpointsRange(ctx, query, whereClause, args, func(item model.MonitorEndpoint) error {
+		items = append(items, item)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return items, nil
+}
 
-	viewName := "ping_1m"
-	if query.End.Sub(query.Start) > 48*time.Hour {
-		viewName = "ping_1h"
+// rollupAggregate is one continuous aggregate over ping_raw and its jitter
+// companion.
+type rollupAggregate struct {
+	view       string
+	jitterView string
+	width      time.Duration
+}
+
+var rollupAggregates = map[string]rollupAggregate{
+	"10s": {view: "ping_10s", jitterView: "ping_jitter_10s", width: 10 * time.Second},
+	"1m":  {view: "ping_1m", jitterView: "ping_jitter_1m", width: time.Minute},
+	"1h":  {view: "ping_1h", jitterView: "ping_jitter_1h", width: time.Hour},
+	"1d":  {view: "ping_1d", jitterView: "ping_jitter_1d", width: 24 * time.Hour},
+}
+
+// rollupOrder lists the aggregates from finest to coarsest.
+var rollupOrder = []string{"10s", "1m", "1h", "1d"}
+
+// RollupForRange picks the aggregate for a window from its length and
+// returns its view and rollup name: 10s up to 6 hours, 1m up to 48 hours, 1h
+// up to 60 days, and 1d beyond. A window starting before the picked
+// aggregate's retention horizon steps up to the next coarser one that still
+// holds it. Every range reader and every reported rollup goes through it, so
+// the two cannot disagree.
+func RollupForRange(start, end time.Time) (view string, rollup string) {
+	return rollupForRange(start, end, time.Now(), *rollupRetention.Load())
+}
+
+func rollupForRange(start, end, now time.Time, retention model.RetentionSettings) (string, string) {
+	span := end.Sub(start)
+	index := 3
+	switch {
+	case span <= 6*time.Hour:
+		index = 0
+	case span <= 48*time.Hour:
+		index = 1
+	case span <= 60*24*time.Hour:
+		index = 2
+	}
+	for index < len(rollupOrder)-1 {
+		horizon := now.AddDate(0, 0, -retentionDays(retention, rollupOrder[index]))
+		if !start.Before(horizon) {
+			break
+		}
+		index++
 	}
+	rollup := rollupOrder[index]
+	return rollupAggregates[rollup].view, rollup
+}
+
+// rangeAggregateView returns the view RollupForRange picks and its bucket
+// width.
+func rangeAggregateView(start, end time.Time) (string, time.Duration) {
+	view, rollup := RollupForRange(start, end)
+	return view, rollupAggregates[rollup].width
+}
+
+// scanMonitorEndpointsRange runs the range monitor query and hands rows to fn
+// in batches of at most monitorRangeStreakBatch, filling in failure streaks
+// from ping_raw per batch. is_down is the endpoint's current state, the
+// same one status=down filters on. A PageSize of 0 drops LIMIT/OFFSET.
+func (s *Store) scanMonitorEndpointsRange(ctx context.Context, query MonitorPageQuery, whereClause string, args []any, fn func(model.MonitorEndpoint) error) error {
+	orderClause, err := buildMonitorOrderClause(query.SortCriteria, monitorRangeSortExpression)
+	if err != nil {
+		return err
+	}
+
+	viewName, bucketWidth := rangeAggregateView(query.Start, query.End)
 
 	startPos := len(args) + 1
 	endPos := len(args) + 2
-	limitPos := len(args) + 3
-	offsetPos := len(args) + 4
+
+	latencyMaintenanceClause := ""
+	if query.ExcludeMaintenance {
+		latencyMaintenanceClause = "\n\t\t\t  AND NOT pr.maintenance"
+	}
 
 	itemsSQL := fmt.Sprintf(`
 		WITH range_stats AS (
@@ -1558,18 +2233,32 @@ func (s *Store) listMonitorEndpointsPageRange(ctx context.Context, query Monitor
 							NULLIF(SUM(GREATEST(sent_count - fail_count, 0)), 0)::DOUBLE PRECISION
 					ELSE NULL
 				END AS average_latency
-			FROM %s
-			WHERE bucket >= $%d AND bucket <= $%d
+			FROM (%s) rb
 			GROUP BY endpoint_id
+		),
+		range_latency AS (
+			SELECT
+				pr.endpoint_id,
+				percentile_cont(0.50) WITHIN GROUP (ORDER BY pr.latency_ms) AS p50_latency,
+				percentile_cont(0.95) WITHIN GROUP (ORDER BY pr.latency_ms) AS p95_latency,
+				percentile_cont(0.99) WITHIN GROUP (ORDER BY pr.latency_ms) AS p99_latency,
+				COUNT(*) FILTER (WHERE pr.ip_mismatch)::BIGINT AS ip_mismatch_count
+			FROM ping_raw pr
+			WHERE pr.ts >= $%d AND pr.ts <= $%d
+			  AND pr.success = TRUE
+			  AND pr.latency_ms IS NOT NULL%s
+			  AND pr.endpoint_id IN (SELECT ie.id FROM inventory_endpoint ie %s)
+			GROUP BY pr.endpoint_id
 		)
 		SELECT
 			ie.id,
 				ie.hostname,
 				rs.last_failed_on,
-				host(ie.ip) AS ip_address,
+				COALESCE(host(ie.ip), '') AS ip_address,
 				ie.mac,
 				`+customFieldValueColumns("ie")+`,
 				NULL::text AS reply_ip_address,
+				NULL::int AS last_ttl,
 			rs.last_success_on,
 			COALESCE(rs.success_count, 0) AS success_count,
 			COALESCE(rs.failed_count, 0) AS failed_count,
@@ -1582,8 +2271,13 @@ func (s *Store) listMonitorEndpointsPageRange(ctx context.Context, query Monitor
 				WHEN COALESCE(rs.total_sent_ping, 0) > 0 THEN 'Range Aggregate'
 				ELSE 'No Data'
 			END AS last_ping_status,
+			`+s.monitorStatus().isDown("es")+` AS is_down,
 			NULL::double precision AS last_ping_latency,
 				rs.average_latency,
+				rl.p50_latency,
+				rl.p95_latency,
+				rl.p99_latency,
+				rl.ip_mismatch_count,
 				ie.vlan,
 				ie.zone,
 				ie.switch_name,
@@ -1596,26 +2290,44 @@ func (s *Store) listMonitorEndpointsPageRange(ctx context.Context, query Monitor
 				COALESCE(array_remove(array_agg(DISTINCT gd.name), NULL), '{}') AS groups
 		FROM inventory_endpoint ie
 		LEFT JOIN range_stats rs ON rs.endpoint_id = ie.id
+		LEFT JOIN range_latency rl ON rl.endpoint_id = ie.id
+		LEFT JOIN endpoint_stats_current es ON es.endpoint_id = ie.id
 		LEFT JOIN group_member gm ON gm.endpoint_id = ie.id
 		LEFT JOIN group_def gd ON gd.id = gm.group_id
 		%s
 			GROUP BY ie.id, ie.hostname, ie.ip, ie.mac, ie.vlan, ie.zone, ie.switch_name, ie.port, ie.port_type,
 				ie.gateway, ie.mgmt_ip, ie.speed, ie.duplex, `+customFieldValueColumns("ie")+`,
 				rs.last_failed_on, rs.last_success_on, rs.success_count, rs.failed_count, rs.failed_pct,
-			rs.total_sent_ping, rs.average_latency
+			rs.total_sent_ping, rs.average_latency, rl.p50_latency, rl.p95_latency, rl.p99_latency, rl.ip_mismatch_count,
+			es.consecutive_failed_count, es.total_sent_ping
 		ORDER BY %s
-		LIMIT $%d OFFSET $%d
-	`, viewName, startPos, endPos, whereClause, orderClause, limitPos, offsetPos)
+	`, rangeBucketsSQL(viewName, bucketWidth, startPos, endPos, 0, query.ExcludeMaintenance), startPos, endPos, latencyMaintenanceClause, whereClause, whereClause, orderClause)
 
-	itemsArgs := append(append([]any{}, args...), query.Start, query.End, query.PageSize, (query.Page-1)*query.PageSize)
+	itemsArgs := append(append([]any{}, args...), query.Start, query.End)
+	if query.PageSize > 0 {
+		itemsSQL += fmt.Sprintf("LIMIT $%d OFFSET $%d", len(args)+3, len(args)+4)
+		itemsArgs = append(itemsArgs, query.PageSize, (query.Page-1)*query.PageSize)
+	}
 
 	rows, err := s.pool.Query(ctx, itemsSQL, itemsArgs...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	items := []model.MonitorEndpoint{}
+	batch := make([]model.MonitorEndpoint, 0, monitorRangeStreakBatch)
+	flush := func() error {
+		if err := s.applyRangeFailureStreaks(ctx, batch, query.Start, query.End, query.ExcludeMaintenance); err != nil {
+			return err
+		}
+		for _, item := range batch {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
 	for rows.Next() {
 		var item model.MonitorEndpoint
 		scanTargets := []any{
@@ -1628,6 +2340,7 @@ func (s *Store) listMonitorEndpointsPageRange(ctx context.Context, query Monitor
 		scanTargets = append(scanTargets, monitorEndpointCustomFieldScanTargets(&item)...)
 		scanTargets = append(scanTargets,
 			&item.ReplyIPAddress,
+			&item.LastTTL,
 			&item.LastSuccessOn,
 			&item.SuccessCount,
 			&item.FailedCount,
@@ -1637,8 +2350,13 @@ func (s *Store) listMonitorEndpointsPageRange(ctx context.Context, query Monitor
 			&item.FailedPct,
 			&item.TotalSentPing,
 			&item.LastPingStatus,
+			&item.IsDown,
 			&item.LastPingLatency,
 			&item.AverageLatency,
+			&item.P50LatencyMs,
+			&item.P95LatencyMs,
+			&item.P99LatencyMs,
+			&item.IPMismatchCount,
 			&item.VLAN,
 			&item.Zone,
 			&item.Switch,
@@ -1651,17 +2369,25 @@ func (s *Store) listMonitorEndpointsPageRange(ctx context.Context, query Monitor
 			&item.Groups,
 		)
 		if err := rows.Scan(scanTargets...); err != nil {
-			return nil, err
+			return err
+		}
+		batch = append(batch, item)
+		if len(batch) == monitorRangeStreakBatch {
+			if err := flush(); err != nil {
+				return err
+			}
 		}
-		items = append(items, item)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return err
 	}
+	return flush()
+}
 
+func (s *Store) applyRangeFailureStreaks(ctx context.Context, items []model.MonitorEndpoint, start, end time.Time, excludeMaintenance bool) error {
 	if len(items) == 0 {
-		return items, nil
+		return nil
 	}
 
 	endpointIDs := make([]int64, 0, len(items))
@@ -1669,9 +2395,9 @@ func (s *Store) listMonitorEndpointsPageRange(ctx context.Context, query Monitor
 		endpointIDs = append(endpointIDs, item.EndpointID)
 	}
 
-	streakByEndpoint, err := s.loadRangeFailureStreakStats(ctx, endpointIDs, query.Start, query.End)
+	streakByEndpoint, err := s.loadRangeFailureStreakStats(ctx, endpointIDs, start, end, excludeMaintenance)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	for index := range items {
@@ -1697,7 +2423,7 @@ func (s *Store) listMonitorEndpointsPageRange(ctx context.Context, query Monitor
 		items[index].MaxConsecutiveFailedAt = nil
 	}
 
-	return items, nil
+	return nil
 }
 
 func (s *Store) loadRangeFailureStreakStats(
@@ -1705,6 +2431,7 @@ func (s *Store) loadRangeFailureStreakStats(
 	endpointIDs []int64,
 	start time.Time,
 	end time.Time,
+	excludeMaintenance bool,
 ) (map[int64]rangeFailureStreakStats, error) {
 	if len(endpointIDs) == 0 {
 		return map[int64]rangeFailureStreakStats{}, nil
@@ -1720,6 +2447,7 @@ func (s *Store) loadRangeFailureStreakStats(
 			WHERE endpoint_id = ANY($1::bigint[])
 			  AND ts >= $2::timestamptz
 			  AND ts <= $3::timestamptz
+			  AND (NOT $4::boolean OR NOT maintenance)
 		),
 		last_markers AS (
 			SELECT
@@ -1790,7 +2518,7 @@ func (s *Store) loadRangeFailureStreakStats(
 			mr.max_consecutive_failed_count_time
 		FROM current_streak cs
 		LEFT JOIN max_runs mr ON mr.endpoint_id = cs.endpoint_id
-	`, endpointIDs, start, end)
+	`, endpointIDs, start, end, excludeMaintenance)
 	if err != nil {
 		return nil, err
 	}
@@ -1818,52 +2546,56 @@ func (s *Store) loadRangeFailureStreakStats(
 }
 
 func (s *Store) ListInventoryEndpoints(ctx context.Context, listQuery InventoryListQuery) ([]model.InventoryEndpointView, error) {
-	sql := `
-		SELECT
-			ie.id,
-				ie.hostname,
-				host(ie.ip) AS ip_address,
-				ie.mac,
-				` + customFieldValueColumns("ie") + `,
-				ie.vlan,
-				ie.zone,
-				ie.switch_name,
-				ie.port,
-				ie.port_type,
-				COALESCE(host(ie.gateway), '') AS gateway,
-				COALESCE(host(ie.mgmt_ip), '') AS mgmt_ip,
-				ie.speed,
-				ie.duplex,
-				ie.description,
-				COALESCE(array_remove(array_agg(DISTINCT gd.name), NULL), '{}') AS groups,
-			ie.is_active,
-			ie.updated_at
-		FROM inventory_endpoint ie
-		LEFT JOIN group_member gm ON gm.endpoint_id = ie.id
-		LEFT JOIN group_def gd ON gd.id = gm.group_id
-		WHERE 1=1
-	`
+	whereClause, args := buildInventoryListWhereClause(listQuery)
+	return s.queryInventoryEndpoints(ctx, whereClause, args, "ie.ip", "")
+}
+
+// ListInventoryEndpointsPage is the paginated, sortable form of
+// ListInventoryEndpoints. It returns one page of rows and the total count of
+// rows matching the filters.
+func (s *Store) ListInventoryEndpointsPage(ctx context.Context, listQuery InventoryListQuery) ([]model.InventoryEndpointView, int64, error) {
+	orderClause, err := buildInventoryOrderClause(listQuery.SortBy, listQuery.SortDir)
+	if err != nil {
+		return nil, 0, err
+	}
+	whereClause, args := buildInventoryListWhereClause(listQuery)
+
+	var totalItems int64
+	if err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM inventory_endpoint ie`+whereClause, args...).Scan(&totalItems); err != nil {
+		return nil, 0, err
+	}
+
+	limitClause := fmt.Sprintf("LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	pageArgs := append(append([]any{}, args...), listQuery.PageSize, (listQuery.Page-1)*listQuery.PageSize)
+	items, err := s.queryInventoryEndpoints(ctx, whereClause, pageArgs, orderClause, limitClause)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, totalItems, nil
+}
 
+func buildInventoryListWhereClause(listQuery InventoryListQuery) (string, []any) {
+	where := " WHERE 1=1"
 	args := []any{}
 	includeActive, includeInactive := normalizeInventoryActivityStates(listQuery.ActivityStates)
 	if includeActive != includeInactive {
-		sql += fmt.Sprintf(" AND ie.is_active = $%d", len(args)+1)
+		where += fmt.Sprintf(" AND ie.is_active = $%d", len(args)+1)
 		args = append(args, includeActive)
 	}
 	if len(listQuery.Filters.VLANs) > 0 {
-		sql += fmt.Sprintf(" AND ie.vlan = ANY($%d)", len(args)+1)
+		where += fmt.Sprintf(" AND ie.vlan = ANY($%d)", len(args)+1)
 		args = append(args, listQuery.Filters.VLANs)
 	}
 	if len(listQuery.Filters.Switches) > 0 {
-		sql += fmt.Sprintf(" AND ie.switch_name = ANY($%d)", len(args)+1)
+		where += fmt.Sprintf(" AND ie.switch_name = ANY($%d)", len(args)+1)
 		args = append(args, listQuery.Filters.Switches)
 	}
 	if len(listQuery.Filters.Ports) > 0 {
-		sql += fmt.Sprintf(" AND ie.port = ANY($%d)", len(args)+1)
+		where += fmt.Sprintf(" AND ie.port = ANY($%d)", len(args)+1)
 		args = append(args, listQuery.Filters.Ports)
 	}
 	if len(listQuery.Filters.GroupNames) > 0 {
-		sql += fmt.Sprintf(`
+		where += fmt.Sprintf(`
 			AND EXISTS (
 				SELECT 1
 				FROM group_member gm2
@@ -1874,19 +2606,91 @@ func (s *Store) ListInventoryEndpoints(ctx context.Context, listQuery InventoryL
 		`, len(args)+1)
 		args = append(args, listQuery.Filters.GroupNames)
 	}
+	if len(listQuery.Filters.CIDRs) > 0 {
+		where += fmt.Sprintf(" AND ie.ip <<= ANY($%d::cidr[])", len(args)+1)
+		args = append(args, listQuery.Filters.CIDRs)
+	}
 	for slot, search := range normalizeCustomSearches(listQuery.CustomSearches) {
 		if search == "" {
 			continue
 		}
-		sql += fmt.Sprintf(" AND ie.custom_field_%d_value ILIKE $%d", slot+1, len(args)+1)
+		where += fmt.Sprintf(" AND ie.custom_field_%d_value ILIKE $%d", slot+1, len(args)+1)
 		args = append(args, "%"+search+"%")
 	}
+	searchClause, args := freeTextSearchClause(listQuery.Search, args)
+	return where + searchClause, args
+}
+
+func inventorySortExpression(sortBy string) (string, error) {
+	switch sortBy {
+	case "hostname":
+		return "lower(ie.hostname)", nil
+	case "ip":
+		return "ie.ip", nil
+	case "vlan":
+		return "ie.vlan", nil
+	case "switch":
+		return "lower(ie.switch_name)", nil
+	case "updated_at":
+		return "ie.updated_at", nil
+	default:
+		return "", fmt.Errorf("invalid sort_by")
+	}
+}
+
+// buildInventoryOrderClause orders by the requested column and breaks ties on
+// id so pages stay stable. No sort_by keeps the unpaginated IP order.
+func buildInventoryOrderClause(sortBy string, sortDir string) (string, error) {
+	if sortBy == "" {
+		return "ie.ip ASC NULLS LAST, ie.id ASC", nil
+	}
+	expression, err := inventorySortExpression(sortBy)
+	if err != nil {
+		return "", err
+	}
+	direction := "ASC"
+	if strings.EqualFold(sortDir, "desc") {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("%s %s NULLS LAST, ie.id ASC", expression, direction), nil
+}
 
-	sql += `
+func (s *Store) queryInventoryEndpoints(ctx context.Context, whereClause string, args []any, orderClause string, limitClause string) ([]model.InventoryEndpointView, error) {
+	sql := `
+		SELECT
+			ie.id,
+				ie.hostname,
+				COALESCE(host(ie.ip), '') AS ip_address,
+				ie.mac,
+				` + customFieldValueColumns("ie") + `,
+				ie.vlan,
+				ie.zone,
+				ie.switch_name,
+				ie.port,
+				ie.port_type,
+				COALESCE(host(ie.gateway), '') AS gateway,
+				COALESCE(host(ie.mgmt_ip), '') AS mgmt_ip,
+				ie.speed,
+				ie.duplex,
+				ie.description,
+				ie.probe_mode,
+				ie.probe_http_target,
+				ie.probe_tcp_port,
+				ie.probe_interval_sec,
+				COALESCE(array_remove(array_agg(DISTINCT gd.name), NULL), '{}') AS groups,
+			ie.is_active,
+			ie.monitoring_enabled,
+			ie.updated_at
+		FROM inventory_endpoint ie
+		LEFT JOIN group_member gm ON gm.endpoint_id = ie.id
+		LEFT JOIN group_def gd ON gd.id = gm.group_id
+	` + whereClause + `
 			GROUP BY ie.id, ie.hostname, ie.ip, ie.mac, ie.vlan, ie.zone, ie.switch_name, ie.port,
-				ie.port_type, ie.gateway, ie.mgmt_ip, ie.speed, ie.duplex, ie.description, ie.is_active, ie.updated_at,
+				ie.port_type, ie.gateway, ie.mgmt_ip, ie.speed, ie.duplex, ie.description, ie.probe_mode, ie.probe_http_target, ie.probe_tcp_port, ie.probe_interval_sec,
+				ie.is_active, ie.monitoring_enabled, ie.updated_at,
 				` + customFieldValueColumns("ie") + `
-		ORDER BY ie.ip
+		ORDER BY ` + orderClause + `
+		` + limitClause + `
 	`
 
 	rows, err := s.pool.Query(ctx, sql, args...)
@@ -1916,8 +2720,13 @@ func (s *Store) ListInventoryEndpoints(ctx context.Context, listQuery InventoryL
 			&item.Speed,
 			&item.Duplex,
 			&item.Description,
+			&item.ProbeMode,
+			&item.ProbeHTTPTarget,
+			&item.ProbeTCPPort,
+			&item.ProbeIntervalSec,
 			&item.Groups,
 			&item.Active,
+			&item.MonitoringEnabled,
 			&item.UpdatedAt,
 		)
 		if err := rows.Scan(scanTargets...); err != nil {
@@ -1951,6 +2760,11 @@ func (s *Store) UpdateInventoryEndpoint(ctx context.Context, endpointID int64, p
 		patch.Speed,
 		patch.Duplex,
 		patch.Description,
+		patch.ProbeMode,
+		patch.ProbeHTTPTarget,
+		patch.ProbeTCPPort,
+		patch.ProbeIntervalSec,
+		patch.MonitoringEnabled,
 	)
 	cmd, err := s.pool.Exec(ctx, `
 			UPDATE inventory_endpoint
@@ -1976,6 +2790,11 @@ func (s *Store) UpdateInventoryEndpoint(ctx context.Context, endpointID int64, p
 				speed = $21,
 				duplex = $22,
 				description = $23,
+				probe_mode = COALESCE($24::text, probe_mode),
+				probe_http_target = COALESCE($25::text, probe_http_target),
+				probe_tcp_port = COALESCE($26::int, probe_tcp_port),
+				probe_interval_sec = CASE WHEN $27::int IS NULL THEN probe_interval_sec ELSE NULLIF($27::int, 0) END,
+				monitoring_enabled = COALESCE($28::boolean, monitoring_enabled),
 				updated_at = now()
 			WHERE id = $1
 		`, args...)
@@ -1994,7 +2813,7 @@ func (s *Store) GetInventoryEndpointByID(ctx context.Context, endpointID int64)
 		SELECT
 			ie.id,
 				ie.hostname,
-				host(ie.ip) AS ip_address,
+				COALESCE(host(ie.ip), '') AS ip_address,
 				ie.mac,
 				`+customFieldValueColumns("ie")+`,
 				ie.vlan,
@@ -2007,15 +2826,21 @@ func (s *Store) GetInventoryEndpointByID(ctx context.Context, endpointID int64)
 				ie.speed,
 				ie.duplex,
 				ie.description,
+				ie.probe_mode,
+				ie.probe_http_target,
+				ie.probe_tcp_port,
+				ie.probe_interval_sec,
 				COALESCE(array_remove(array_agg(DISTINCT gd.name), NULL), '{}') AS groups,
 			ie.is_active,
+			ie.monitoring_enabled,
 			ie.updated_at
 		FROM inventory_endpoint ie
 		LEFT JOIN group_member gm ON gm.endpoint_id = ie.id
 		LEFT JOIN group_def gd ON gd.id = gm.group_id
 		WHERE ie.id = $1
 			GROUP BY ie.id, ie.hostname, ie.ip, ie.mac, ie.vlan, ie.zone, ie.switch_name, ie.port,
-				ie.port_type, ie.gateway, ie.mgmt_ip, ie.speed, ie.duplex, ie.description, ie.is_active, ie.updated_at,
+				ie.port_type, ie.gateway, ie.mgmt_ip, ie.speed, ie.duplex, ie.description, ie.probe_mode, ie.probe_http_target, ie.probe_tcp_port, ie.probe_interval_sec,
+				ie.is_active, ie.monitoring_enabled, ie.updated_at,
 				`+customFieldValueColumns("ie")+`
 	`, endpointID)
 
@@ -2038,8 +2863,13 @@ func (s *Store) GetInventoryEndpointByID(ctx context.Context, endpointID int64)
 		&item.Speed,
 		&item.Duplex,
 		&item.Description,
+		&item.ProbeMode,
+		&item.ProbeHTTPTarget,
+		&item.ProbeTCPPort,
+		&item.ProbeIntervalSec,
 		&item.Groups,
 		&item.Active,
+		&item.MonitoringEnabled,
 		&item.UpdatedAt,
 	)
 	if err := row.Scan(scanTargets...); err != nil {
@@ -2074,6 +2904,21 @@ func (s *Store) CreateInventoryEndpoint(ctx context.Context, payload model.Inven
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
+	endpointID, err := insertInventoryEndpointTx(ctx, tx, payload)
+	if err != nil {
+		return model.InventoryEndpointView{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.InventoryEndpointView{}, err
+	}
+
+	return s.GetInventoryEndpointByID(ctx, endpointID)
+}
+
+// insertInventoryEndpointTx inserts one endpoint and its group membership,
+// returning ErrEndpointIPExists when the IP is taken.
+func insertInventoryEndpointTx(ctx context.Context, tx pgx.Tx, payload model.InventoryEndpointCreate) (int64, error) {
 	var endpointID int64
 	args := []any{
 		payload.IPAddress,
@@ -2099,8 +2944,11 @@ func (s *Store) CreateInventoryEndpoint(ctx context.Context, payload model.Inven
 		payload.Speed,
 		payload.Duplex,
 		payload.Description,
+		payload.ProbeMode,
+		payload.ProbeHTTPTarget,
+		payload.ProbeTCPPort,
 	}
-	err = tx.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 			INSERT INTO inventory_endpoint(
 				ip,
 				hostname,
@@ -2125,12 +2973,16 @@ func (s *Store) CreateInventoryEndpoint(ctx context.Context, payload model.Inven
 				speed,
 				duplex,
 				description,
+				probe_mode,
+				probe_http_target,
+				probe_tcp_port,
 				updated_at
 			)
 			VALUES (
-				$1::inet, $2, $3,
+				NULLIF($1, '')::inet, $2, $3,
 				$4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
 				$14, $15, $16, $17, $18, NULLIF($19, '')::inet, NULLIF($20, '')::inet, $21, $22, $23,
+				COALESCE(NULLIF($24, ''), 'icmp'), $25, $26,
 				now()
 			)
 			ON CONFLICT (ip) DO NOTHING
@@ -2138,28 +2990,18 @@ func (s *Store) CreateInventoryEndpoint(ctx context.Context, payload model.Inven
 		`, args...).Scan(&endpointID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return model.InventoryEndpointView{}, ErrEndpointIPExists
+			return 0, ErrEndpointIPExists
 		}
-		return model.InventoryEndpointView{}, err
+		return 0, err
 	}
 
 	if payload.GroupID != nil {
-		if _, err := tx.Exec(ctx, `
-			INSERT INTO group_member(group_id, endpoint_id)
-			VALUES ($1, $2)
-			ON CONFLICT (endpoint_id) DO UPDATE
-			SET group_id = EXCLUDED.group_id
-			WHERE group_member.group_id IS DISTINCT FROM EXCLUDED.group_id
-		`, *payload.GroupID, endpointID); err != nil {
-			return model.InventoryEndpointView{}, err
+		if _, err := addGroupMembersTx(ctx, tx, *payload.GroupID, []int64{endpointID}); err != nil {
+			return 0, err
 		}
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return model.InventoryEndpointView{}, err
-	}
-
-	return s.GetInventoryEndpointByID(ctx, endpointID)
+	return endpointID, nil
 }
 
 func (s *Store) ListEndpointIDsByGroup(ctx context.Context, groupID int64) ([]int64, error) {
@@ -2409,20 +3251,27 @@ func (s *Store) DeleteAllInventoryEndpointsFast(ctx context.Context) (int64, err
 		return 0, err
 	}
 
-	var matchedEndpoints int64
-	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM inventory_endpoint`).Scan(&matchedEndpoints); err != nil {
+	if _, err := tx.Exec(ctx, `SET LOCAL statement_timeout = 0`); err != nil {
 		_ = tx.Rollback(ctx)
 		return 0, err
 	}
-
-	if _, err := tx.Exec(ctx, `SET LOCAL statement_timeout = 0`); err != nil {
+	if _, err := tx.Exec(ctx, `SET LOCAL synchronous_commit = OFF`); err != nil {
 		_ = tx.Rollback(ctx)
 		return 0, err
 	}
-	if _, err := tx.Exec(ctx, `SET LOCAL synchronous_commit = OFF`); err != nil {
+
+	// TRUNCATE skips the row-level audit trigger, so write the rows it would
+	// have written for each endpoint first.
+	audited, err := tx.Exec(ctx, `
+		INSERT INTO inventory_audit_log(endpoint_id, action, before_data, after_data)
+		SELECT ie.id, 'delete', to_jsonb(ie) - 'updated_at', NULL
+		FROM inventory_endpoint ie
+	`)
+	if err != nil {
 		_ = tx.Rollback(ctx)
 		return 0, err
 	}
+	matchedEndpoints := audited.RowsAffected()
 
 	if _, err := tx.Exec(ctx, `
 		TRUNCATE TABLE
@@ -2473,6 +3322,15 @@ func (s *Store) PauseMaintenanceJobs(ctx context.Context) ([]int64, error) {
 		return nil, err
 	}
 
+	// Record the jobs before disabling them so they can still be resumed if
+	// the process dies before the delete finishes.
+	if _, err := s.pool.Exec(ctx, `
+		INSERT INTO paused_maintenance_job(job_id)
+		SELECT unnest($1::INTEGER[])
+		ON CONFLICT (job_id) DO NOTHING
+	`, jobIDs); err != nil {
+		return nil, err
+	}
 	for _, jobID := range jobIDs {
 		if _, err := s.pool.Exec(ctx, `SELECT alter_job($1, scheduled => false)`, jobID); err != nil {
 			return nil, err
@@ -2500,12 +3358,51 @@ func (s *Store) PauseMaintenanceJobs(ctx context.Context) ([]int64, error) {
 
 func (s *Store) ResumeJobs(ctx context.Context, jobIDs []int64) error {
 	jobIDs = uniqueInt64(jobIDs)
+	if len(jobIDs) == 0 {
+		return nil
+	}
 	for _, jobID := range jobIDs {
 		if _, err := s.pool.Exec(ctx, `SELECT alter_job($1, scheduled => true)`, jobID); err != nil {
 			return err
 		}
 	}
-	return nil
+	_, err := s.pool.Exec(ctx, `DELETE FROM paused_maintenance_job WHERE job_id = ANY($1::INTEGER[])`, jobIDs)
+	return err
+}
+
+// ResumePausedMaintenanceJobs re-enables every job PauseMaintenanceJobs
+// recorded and that was never resumed, e.g. because the process died during
+// a delete. Jobs that no longer exist are forgotten.
+func (s *Store) ResumePausedMaintenanceJobs(ctx context.Context) ([]int64, error) {
+	if _, err := s.pool.Exec(ctx, `
+		DELETE FROM paused_maintenance_job p
+		WHERE NOT EXISTS (SELECT 1 FROM timescaledb_information.jobs j WHERE j.job_id = p.job_id)
+	`); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, `SELECT job_id::BIGINT FROM paused_maintenance_job ORDER BY job_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobIDs := []int64{}
+	for rows.Next() {
+		var jobID int64
+		if err := rows.Scan(&jobID); err != nil {
+			return nil, err
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := s.ResumeJobs(ctx, jobIDs); err != nil {
+		return nil, err
+	}
+	return jobIDs, nil
 }
 
 func (s *Store) DeleteInventoryEndpointsByGroup(ctx context.Context, groupID int64) (int64, int64, error) {
@@ -2530,50 +3427,54 @@ func (s *Store) DeleteAllInventoryEndpoints(ctx context.Context) (int64, error)
 	return s.DeleteAllInventoryEndpointsFast(ctx)
 }
 
-func (s *Store) QueryTimeSeries(ctx context.Context, endpointIDs []int64, start time.Time, end time.Time, rollup string) ([]model.TimeSeriesPoint, error) {
+// QueryTimeSeries returns the buckets of the aggregate RollupForRange picks
+// for the window, and that rollup's name.
+func (s *Store) QueryTimeSeries(ctx context.Context, endpointIDs []int64, start time.Time, end time.Time) ([]model.TimeSeriesPoint, string, error) {
+	_, rollup := RollupForRange(start, end)
 	if len(endpointIDs) == 0 {
-		return []model.TimeSeriesPoint{}, nil
-	}
-	view := "ping_1m"
-	if rollup == "1h" {
-		view = "ping_1h"
+		return []model.TimeSeriesPoint{}, rollup, nil
 	}
+	aggregate := rollupAggregates[rollup]
 
+	// Jitter comes from a companion aggregate that only has buckets from
+	// after it was created; older buckets read jitter_ms as NULL.
 	query := fmt.Sprintf(`
-		SELECT endpoint_id, bucket, loss_rate, avg_latency_ms, max_latency_ms, sent_count, fail_count
-		FROM %s
-		WHERE endpoint_id = ANY($1)
-		  AND bucket BETWEEN $2 AND $3
-		ORDER BY bucket
-	`, view)
+		SELECT p.endpoint_id, p.bucket, p.loss_rate, p.avg_latency_ms, p.max_latency_ms, j.jitter_ms, p.sent_count, p.fail_count
+		FROM %s p
+		LEFT JOIN %s j ON j.endpoint_id = p.endpoint_id AND j.bucket = p.bucket
+		WHERE p.endpoint_id = ANY($1)
+		  AND p.bucket BETWEEN $2 AND $3
+		ORDER BY p.bucket
+	`, aggregate.view, aggregate.jitterView)
 
 	rows, err := s.pool.Query(ctx, query, endpointIDs, start, end)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
 	series := []model.TimeSeriesPoint{}
 	for rows.Next() {
 		var p model.TimeSeriesPoint
-		if err := rows.Scan(&p.EndpointID, &p.Bucket, &p.LossRate, &p.AvgLatencyMs, &p.MaxLatencyMs, &p.SentCount, &p.FailCount); err != nil {
-			return nil, err
+		if err := rows.Scan(&p.EndpointID, &p.Bucket, &p.LossRate, &p.AvgLatencyMs, &p.MaxLatencyMs, &p.JitterMs, &p.SentCount, &p.FailCount); err != nil {
+			return nil, "", err
 		}
 		series = append(series, p)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// The monitor chart reads a single selected endpoint at a time. If the
 	// real-time aggregate is momentarily behind, fall back to an on-the-fly
 	// raw aggregation so the live chart does not appear blank while the table
 	// already shows current endpoint stats.
-	if len(series) == 0 && len(endpointIDs) == 1 && rollup == "1m" {
-		return s.queryTimeSeriesFromRaw(ctx, endpointIDs, start, end, "1 minute")
+	if len(series) == 0 && len(endpointIDs) == 1 && aggregate.width <= time.Minute {
+		series, err = s.queryTimeSeriesFromRaw(ctx, endpointIDs, start, end, fmt.Sprintf("%d seconds", int64(aggregate.width/time.Second)))
+		return series, rollup, err
 	}
 
-	return series, nil
+	return series, rollup, nil
 }
 
 func (s *Store) queryTimeSeriesFromRaw(ctx context.Context, endpointIDs []int64, start time.Time, end time.Time, bucketInterval string) ([]model.TimeSeriesPoint, error) {
@@ -2584,6 +3485,7 @@ func (s *Store) queryTimeSeriesFromRaw(ctx context.Context, endpointIDs []int64,
 			(COUNT(*) FILTER (WHERE NOT success)::DOUBLE PRECISION / NULLIF(COUNT(*), 0)::DOUBLE PRECISION) * 100 AS loss_rate,
 			AVG(latency_ms) FILTER (WHERE success) AS avg_latency_ms,
 			MAX(latency_ms) FILTER (WHERE success) AS max_latency_ms,
+			STDDEV_SAMP(latency_ms) FILTER (WHERE success) AS jitter_ms,
 			COUNT(*)::BIGINT AS sent_count,
 			COUNT(*) FILTER (WHERE NOT success)::BIGINT AS fail_count
 		FROM ping_raw
@@ -2602,7 +3504,7 @@ func (s *Store) queryTimeSeriesFromRaw(ctx context.Context, endpointIDs []int64,
 	series := []model.TimeSeriesPoint{}
 	for rows.Next() {
 		var p model.TimeSeriesPoint
-		if err := rows.Scan(&p.EndpointID, &p.Bucket, &p.LossRate, &p.AvgLatencyMs, &p.MaxLatencyMs, &p.SentCount, &p.FailCount); err != nil {
+		if err := rows.Scan(&p.EndpointID, &p.Bucket, &p.LossRate, &p.AvgLatencyMs, &p.MaxLatencyMs, &p.JitterMs, &p.SentCount, &p.FailCount); err != nil {
 			return nil, err
 		}
 		series = append(series, p)
@@ -2744,12 +3646,14 @@ func normalizeCustomSearches(searches []string) []string {
 }
 
 func buildMonitorWhereClause(
+	status monitorStatusSQL,
 	filters MonitorFilters,
 	hostname string,
 	mac string,
 	customSearches []string,
 	ipList []string,
 	excludeEndpointIDs []int64,
+	search string,
 ) (string, []any) {
 	var query strings.Builder
 	query.WriteString(" WHERE ie.is_active = TRUE")
@@ -2779,6 +3683,10 @@ func buildMonitorWhereClause(
 		`, len(args)+1))
 		args = append(args, filters.GroupNames)
 	}
+	if len(filters.CIDRs) > 0 {
+		query.WriteString(fmt.Sprintf(" AND ie.ip <<= ANY($%d::cidr[])", len(args)+1))
+		args = append(args, filters.CIDRs)
+	}
 
 	if len(ipList) > 0 {
 		query.WriteString(fmt.Sprintf(" AND ie.ip = ANY($%d::inet[])", len(args)+1))
@@ -2799,7 +3707,11 @@ func buildMonitorWhereClause(
 			query.WriteString(fmt.Sprintf(" AND ie.custom_field_%d_value ILIKE $%d", slot+1, len(args)+1))
 			args = append(args, "%"+search+"%")
 		}
+		var searchClause string
+		searchClause, args = freeTextSearchClause(search, args)
+		query.WriteString(searchClause)
 	}
+	query.WriteString(status.clause(filters.Status))
 
 	if len(excludeEndpointIDs) > 0 {
 		query.WriteString(fmt.Sprintf(" AND NOT (ie.id = ANY($%d::bigint[]))", len(args)+1))
@@ -2812,40 +3724,71 @@ func buildMonitorWhereClause(
 type monitorSortDefinition struct {
 	Expression        string
 	NullsFirstWhenAsc bool
+	// CursorType is the SQL type a cursor key for Expression is cast back to.
+	CursorType string
 }
 
-func monitorSortExpression(sortBy string) (monitorSortDefinition, error) {
+// monitorInventorySortExpression covers the inventory columns both monitor
+// scopes can sort by. They are grouped on ie.id, so they are valid in either
+// GROUP BY query. The IP sorts as inet, i.e. numerically.
+func monitorInventorySortExpression(sortBy string) (monitorSortDefinition, bool) {
+	switch sortBy {
+	case "hostname":
+		return monitorSortDefinition{Expression: "lower(ie.hostname)", CursorType: "text"}, true
+	case "ip":
+		return monitorSortDefinition{Expression: "ie.ip", CursorType: "inet"}, true
+	case "vlan":
+		return monitorSortDefinition{Expression: "ie.vlan", CursorType: "text"}, true
+	case "switch":
+		return monitorSortDefinition{Expression: "lower(ie.switch_name)", CursorType: "text"}, true
+	case "port":
+		return monitorSortDefinition{Expression: "ie.port", CursorType: "text"}, true
+	case "mac":
+		return monitorSortDefinition{Expression: "lower(ie.mac)", CursorType: "text"}, true
+	}
+	return monitorSortDefinition{}, false
+}
+
+// monitorSortExpression maps a live sort field to its expression. Status
+// sorts on the live status shown in the row, not the stored one.
+func (s *Store) monitorSortExpression(sortBy string) (monitorSortDefinition, error) {
+	if definition, ok := monitorInventorySortExpression(sortBy); ok {
+		return definition, nil
+	}
 	switch sortBy {
 	case "":
 		return monitorSortDefinition{}, nil
 	case "last_failed_on":
-		return monitorSortDefinition{Expression: "es.last_failed_on"}, nil
+		return monitorSortDefinition{Expression: "es.last_failed_on", CursorType: "timestamptz"}, nil
 	case "last_success_on":
-		return monitorSortDefinition{Expression: "es.last_success_on", NullsFirstWhenAsc: true}, nil
+		return monitorSortDefinition{Expression: "es.last_success_on", NullsFirstWhenAsc: true, CursorType: "timestamptz"}, nil
 	case "success_count":
-		return monitorSortDefinition{Expression: "COALESCE(es.success_count, 0)"}, nil
+		return monitorSortDefinition{Expression: "COALESCE(es.success_count, 0)", CursorType: "bigint"}, nil
 	case "failed_count":
-		return monitorSortDefinition{Expression: "COALESCE(es.failed_count, 0)"}, nil
+		return monitorSortDefinition{Expression: "COALESCE(es.failed_count, 0)", CursorType: "bigint"}, nil
 	case "consecutive_failed_count":
-		return monitorSortDefinition{Expression: "COALESCE(es.consecutive_failed_count, 0)"}, nil
+		return monitorSortDefinition{Expression: "COALESCE(es.consecutive_failed_count, 0)", CursorType: "bigint"}, nil
 	case "max_consecutive_failed_count":
-		return monitorSortDefinition{Expression: "COALESCE(es.max_consecutive_failed_count, 0)"}, nil
+		return monitorSortDefinition{Expression: "COALESCE(es.max_consecutive_failed_count, 0)", CursorType: "bigint"}, nil
 	case "max_consecutive_failed_count_time":
-		return monitorSortDefinition{Expression: "es.max_consecutive_failed_count_time"}, nil
+		return monitorSortDefinition{Expression: "es.max_consecutive_failed_count_time", CursorType: "timestamptz"}, nil
 	case "failed_pct":
-		return monitorSortDefinition{Expression: "COALESCE(es.failed_pct, 0)"}, nil
+		return monitorSortDefinition{Expression: "COALESCE(es.failed_pct, 0)", CursorType: "double precision"}, nil
 	case "last_ping_status":
-		return monitorSortDefinition{Expression: "lower(COALESCE(es.last_ping_status, 'unknown'))"}, nil
+		return monitorSortDefinition{Expression: "lower(" + s.liveStatusExpression() + ")", CursorType: "text"}, nil
 	case "last_ping_latency":
-		return monitorSortDefinition{Expression: "es.last_ping_latency"}, nil
+		return monitorSortDefinition{Expression: "es.last_ping_latency", CursorType: "double precision"}, nil
 	case "average_latency":
-		return monitorSortDefinition{Expression: "es.average_latency"}, nil
+		return monitorSortDefinition{Expression: "es.average_latency", CursorType: "double precision"}, nil
 	default:
 		return monitorSortDefinition{}, fmt.Errorf("invalid sort_by")
 	}
 }
 
 func monitorRangeSortExpression(sortBy string) (monitorSortDefinition, error) {
+	if definition, ok := monitorInventorySortExpression(sortBy); ok {
+		return definition, nil
+	}
 	switch sortBy {
 	case "":
 		return monitorSortDefinition{}, nil
@@ -2855,7 +3798,10 @@ func monitorRangeSortExpression(sortBy string) (monitorSortDefinition, error) {
 		"failed_count",
 		"last_ping_status",
 		"failed_pct",
-		"average_latency":
+		"average_latency",
+		"p95_latency",
+		"p99_latency",
+		"ip_mismatch_count":
 		definition := monitorSortDefinition{Expression: sortBy}
 		if sortBy == "last_success_on" {
 			definition.NullsFirstWhenAsc = true
@@ -2926,6 +3872,32 @@ func buildMonitorOrderClause(criteria []MonitorSortCriterion, expressionForField
 	return strings.Join(parts, ", "), nil
 }
 
+// freeTextSearchClause matches search against hostname, IP, MAC (as stored
+// and with separators stripped), description, switch, and port. The term is
+// bound as a parameter; the returned clause starts with " AND" or is empty.
+func freeTextSearchClause(search string, args []any) (string, []any) {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return "", args
+	}
+
+	termPos := len(args) + 1
+	args = append(args, "%"+search+"%")
+	clauses := []string{
+		fmt.Sprintf("ie.hostname ILIKE $%d", termPos),
+		fmt.Sprintf("COALESCE(host(ie.ip), '') ILIKE $%d", termPos),
+		fmt.Sprintf("ie.mac ILIKE $%d", termPos),
+		fmt.Sprintf("ie.description ILIKE $%d", termPos),
+		fmt.Sprintf("ie.switch_name ILIKE $%d", termPos),
+		fmt.Sprintf("ie.port ILIKE $%d", termPos),
+	}
+	if mac := normalizeMACSearchTerm(search); mac != "" {
+		clauses = append(clauses, fmt.Sprintf("replace(replace(replace(lower(ie.mac), ':', ''), '-', ''), ' ', '') LIKE $%d", len(args)+1))
+		args = append(args, "%"+mac+"%")
+	}
+	return " AND (" + strings.Join(clauses, " OR ") + ")", args
+}
+
 func normalizeMACSearchTerm(value string) string {
 	replacer := strings.NewReplacer(":", "", "-", "", " ", "", "\t", "", "\n", "", "\r", "")
 	return replacer.Replace(strings.ToLower(strings.TrimSpace(value)))
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuildMonitorOrderClause(t *testing.T) {
@@ -51,13 +52,13 @@ func TestBuildMonitorOrderClause(t *testing.T) {
 				{Field: "failed_count", Dir: "desc"},
 				{Field: "last_ping_status", Dir: "asc"},
 			},
-			want: "COALESCE(es.failed_count, 0) DESC NULLS LAST, lower(COALESCE(es.last_ping_status, 'unknown')) ASC NULLS LAST, ie.ip ASC",
+			want: "COALESCE(es.failed_count, 0) DESC NULLS LAST, lower(" + (&Store{}).liveStatusExpression() + ") ASC NULLS LAST, ie.ip ASC",
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := buildMonitorOrderClause(tc.criteria, monitorSortExpression)
+			got, err := buildMonitorOrderClause(tc.criteria, (&Store{}).monitorSortExpression)
 			if err != nil {
 				t.Fatalf("buildMonitorOrderClause returned error: %v", err)
 			}
@@ -68,8 +69,73 @@ func TestBuildMonitorOrderClause(t *testing.T) {
 	}
 }
 
+func TestBuildMonitorRangeOrderClauseSortsByTailLatency(t *testing.T) {
+	got, err := buildMonitorOrderClause([]MonitorSortCriterion{
+		{Field: "p95_latency", Dir: "desc"},
+		{Field: "p99_latency", Dir: "asc"},
+	}, monitorRangeSortExpression)
+	if err != nil {
+		t.Fatalf("buildMonitorOrderClause returned error: %v", err)
+	}
+	want := "p95_latency DESC NULLS LAST, p99_latency ASC NULLS LAST, ie.ip ASC"
+	if got != want {
+		t.Fatalf("unexpected order clause: got %q want %q", got, want)
+	}
+
+	if _, err := buildMonitorOrderClause([]MonitorSortCriterion{{Field: "p95_latency", Dir: "asc"}}, (&Store{}).monitorSortExpression); err == nil {
+		t.Fatalf("expected p95_latency to be rejected in live scope")
+	}
+}
+
+func TestBuildMonitorOrderClauseSortsByInventoryColumns(t *testing.T) {
+	for _, expression := range []func(string) (monitorSortDefinition, error){(&Store{}).monitorSortExpression, monitorRangeSortExpression} {
+		got, err := buildMonitorOrderClause([]MonitorSortCriterion{
+			{Field: "switch", Dir: "asc"},
+			{Field: "ip", Dir: "desc"},
+		}, expression)
+		if err != nil {
+			t.Fatalf("buildMonitorOrderClause returned error: %v", err)
+		}
+		want := "lower(ie.switch_name) ASC NULLS LAST, ie.ip DESC NULLS LAST, ie.ip ASC"
+		if got != want {
+			t.Fatalf("unexpected order clause: got %q want %q", got, want)
+		}
+	}
+
+	definition, err := (&Store{}).monitorSortExpression("ip")
+	if err != nil || definition.CursorType != "inet" {
+		t.Fatalf("ip sort should compare as inet, got %+v (%v)", definition, err)
+	}
+}
+
+func TestBuildInventoryOrderClause(t *testing.T) {
+	tests := []struct {
+		sortBy  string
+		sortDir string
+		want    string
+	}{
+		{"", "", "ie.ip ASC NULLS LAST, ie.id ASC"},
+		{"hostname", "desc", "lower(ie.hostname) DESC NULLS LAST, ie.id ASC"},
+		{"switch", "asc", "lower(ie.switch_name) ASC NULLS LAST, ie.id ASC"},
+		{"updated_at", "desc", "ie.updated_at DESC NULLS LAST, ie.id ASC"},
+	}
+	for _, tc := range tests {
+		got, err := buildInventoryOrderClause(tc.sortBy, tc.sortDir)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.sortBy, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s %s: got %q want %q", tc.sortBy, tc.sortDir, got, tc.want)
+		}
+	}
+	if _, err := buildInventoryOrderClause("mac", "asc"); err == nil {
+		t.Fatalf("expected unsupported sort column to be rejected")
+	}
+}
+
 func TestBuildMonitorWhereClauseWithIPListOverridesTextSearches(t *testing.T) {
 	whereClause, args := buildMonitorWhereClause(
+		monitorStatusSQL{},
 		MonitorFilters{
 			VLANs:      []string{"10"},
 			Switches:   []string{"core-1"},
@@ -81,6 +147,7 @@ func TestBuildMonitorWhereClauseWithIPListOverridesTextSearches(t *testing.T) {
 		[]string{"custom-one", "custom-two", "custom-three"},
 		[]string{"10.0.0.1", "10.0.0.2"},
 		nil,
+		"",
 	)
 
 	if contains(whereClause, "ie.hostname ILIKE") || contains(whereClause, "ie.custom_field_1_value ILIKE") || contains(whereClause, "ie.ip = ANY") == false {
@@ -101,12 +168,14 @@ func TestBuildMonitorWhereClauseWithIPListOverridesTextSearches(t *testing.T) {
 
 func TestBuildMonitorWhereClauseUsesTextSearchesWithoutIPList(t *testing.T) {
 	whereClause, args := buildMonitorWhereClause(
+		monitorStatusSQL{},
 		MonitorFilters{},
 		"host-a",
 		"aa-bb",
 		[]string{"custom-one"},
 		nil,
 		nil,
+		"",
 	)
 
 	if !contains(whereClause, "ie.hostname ILIKE") || !contains(whereClause, "replace(replace(replace(lower(ie.mac)") || !contains(whereClause, "ie.custom_field_1_value ILIKE") {
@@ -119,14 +188,57 @@ func TestBuildMonitorWhereClauseUsesTextSearchesWithoutIPList(t *testing.T) {
 	}
 }
 
+func TestBuildMonitorWhereClauseFreeTextSearchIsParameterized(t *testing.T) {
+	whereClause, args := buildMonitorWhereClause(
+		monitorStatusSQL{},
+		MonitorFilters{VLANs: []string{"10"}},
+		"",
+		"",
+		nil,
+		nil,
+		nil,
+		"10.0.0.5' OR 1=1 --",
+	)
+
+	for _, column := range []string{"ie.hostname ILIKE $2", "COALESCE(host(ie.ip), '') ILIKE $2", "ie.description ILIKE $2", "ie.switch_name ILIKE $2", "ie.port ILIKE $2", "lower(ie.mac)"} {
+		if !contains(whereClause, column) {
+			t.Fatalf("expected %q in where clause: %s", column, whereClause)
+		}
+	}
+	if contains(whereClause, "OR 1=1") {
+		t.Fatalf("search term leaked into SQL: %s", whereClause)
+	}
+	wantArgs := []any{[]string{"10"}, "%10.0.0.5' OR 1=1 --%", "%10.0.0.5'or1=1%"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestBuildInventoryListWhereClauseAddsFreeTextSearch(t *testing.T) {
+	whereClause, args := buildInventoryListWhereClause(InventoryListQuery{Search: "  aa:bb  "})
+	if !contains(whereClause, "ie.hostname ILIKE $1") || !contains(whereClause, "LIKE $2") {
+		t.Fatalf("unexpected where clause: %s", whereClause)
+	}
+	if !reflect.DeepEqual(args, []any{"%aa:bb%", "%aabb%"}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	whereClause, args = buildInventoryListWhereClause(InventoryListQuery{})
+	if contains(whereClause, "ILIKE") || len(args) != 0 {
+		t.Fatalf("expected no search clause for empty q: %s %#v", whereClause, args)
+	}
+}
+
 func TestBuildMonitorWhereClauseExcludesEndpointIDs(t *testing.T) {
 	whereClause, args := buildMonitorWhereClause(
+		monitorStatusSQL{},
 		MonitorFilters{},
 		"",
 		"",
 		nil,
 		nil,
 		[]int64{10, 12},
+		"",
 	)
 
 	if !contains(whereClause, "NOT (ie.id = ANY($1::bigint[]))") {
@@ -139,6 +251,48 @@ func TestBuildMonitorWhereClauseExcludesEndpointIDs(t *testing.T) {
 	}
 }
 
+func TestLiveLastPingStatusExpressionDistinguishesNeverProbedAndStale(t *testing.T) {
+	expr := liveLastPingStatusExpression(90*time.Second, 0, 0)
+
+	for _, fragment := range []string{
+		"WHEN COALESCE(es.total_sent_ping, 0) = 0 THEN 'never_probed'",
+		"WHEN es.updated_at < now() - make_interval(secs => 90) THEN 'stale'",
+		"ELSE COALESCE(NULLIF(btrim(es.last_ping_status), ''), 'unknown')",
+	} {
+		if !contains(expr, fragment) {
+			t.Fatalf("expected status expression to contain %q: %s", fragment, expr)
+		}
+	}
+}
+
+func TestLiveLastPingStatusExpressionAppliesDownGrace(t *testing.T) {
+	expr := liveLastPingStatusExpression(90*time.Second, 2*time.Minute, 3)
+	want := "WHEN COALESCE(btrim(es.last_ping_status), '') <> 'Succeeded' AND (ie.created_at > now() - make_interval(secs => 120) OR es.total_sent_ping < 3) THEN 'warming_up'"
+	if !contains(expr, want) {
+		t.Fatalf("expected status expression to contain %q: %s", want, expr)
+	}
+	if strings.Index(expr, "'stale'") > strings.Index(expr, "'warming_up'") {
+		t.Fatalf("stale must take precedence over warming_up: %s", expr)
+	}
+
+	if expr := liveLastPingStatusExpression(90*time.Second, 0, 0); contains(expr, "warming_up") {
+		t.Fatalf("grace disabled should not emit warming_up: %s", expr)
+	}
+}
+
 func contains(value string, fragment string) bool {
 	return strings.Contains(value, fragment)
 }
+
+func TestWhereClausesFilterByCIDR(t *testing.T) {
+	cidrs := []string{"10.20.0.0/16", "2001:db8::/32"}
+	whereClause, args := buildMonitorWhereClause(monitorStatusSQL{}, MonitorFilters{CIDRs: cidrs}, "", "", nil, []string{"10.20.0.1"}, nil, "")
+	if !contains(whereClause, "ie.ip <<= ANY($1::cidr[])") || !reflect.DeepEqual(args[0], cidrs) {
+		t.Fatalf("monitor where clause = %s, args %v", whereClause, args)
+	}
+
+	whereClause, args = buildInventoryListWhereClause(InventoryListQuery{Filters: MonitorFilters{CIDRs: cidrs}})
+	if !contains(whereClause, "ie.ip <<= ANY($1::cidr[])") || !reflect.DeepEqual(args, []any{cidrs}) {
+		t.Fatalf("inventory where clause = %s, args %v", whereClause, args)
+	}
+}
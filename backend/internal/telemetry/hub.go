@@ -1,9 +1,16 @@
 package telemetry
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -14,35 +21,95 @@ const (
 	defaultClientWriteTimeout  = 10 * time.Second
 	defaultPingInterval        = 30 * time.Second
 	defaultPongWait            = 45 * time.Second
+	defaultMaxClientDrops      = 64
+	connectSnapshotTimeout     = 5 * time.Second
 )
 
+// SnapshotFunc builds the state frame sent to a client right after it
+// connects, before any live deltas.
+type SnapshotFunc func(ctx context.Context) (any, error)
+
 type hubConfig struct {
 	clientSendQueueSize int
 	clientWriteTimeout  time.Duration
 	pingInterval        time.Duration
 	pongWait            time.Duration
+	// maxClientDrops is how many events in a row may be dropped for a client
+	// with a full send queue before it is disconnected.
+	maxClientDrops int
+	// allowedOrigins are the browser origins that may open a socket besides
+	// the server's own; a "*" entry admits any origin.
+	allowedOrigins []string
 }
 
 type client struct {
-	conn      *websocket.Conn
-	send      chan []byte
-	done      chan struct{}
-	closeOnce sync.Once
+	id          uint64
+	remoteAddr  string
+	userAgent   string
+	connectedAt time.Time
+	conn        *websocket.Conn
+	send        chan []byte
+	done        chan struct{}
+	closeOnce   sync.Once
+
+	dropped          atomic.Uint64
+	consecutiveDrops atomic.Int64
+
+	// filterMu guards filter, the endpoint ids the client subscribed to.
+	// A nil filter means the client receives every probe_update.
+	filterMu sync.RWMutex
+	filter   map[int64]struct{}
+}
+
+// clientMessage is a frame sent by a client. {"type":"subscribe",
+// "endpoint_ids":[...]} limits probe_update delivery to those endpoints; an
+// empty list or {"type":"unsubscribe"} restores the full stream.
+type clientMessage struct {
+	Type        string  `json:"type"`
+	EndpointIDs []int64 `json:"endpoint_ids"`
+}
+
+type probeUpdateEvent struct {
+	Type        string    `json:"type"`
+	Count       int       `json:"count"`
+	EndpointIDs []int64   `json:"endpoint_ids"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type ClientInfo struct {
+	ID                uint64    `json:"id"`
+	RemoteAddr        string    `json:"remote_addr"`
+	UserAgent         string    `json:"user_agent,omitempty"`
+	ConnectedAt       time.Time `json:"connected_at"`
+	Subscription      string    `json:"subscription"`
+	QueuedMessages    int       `json:"queued_messages"`
+	SendQueueCapacity int       `json:"send_queue_capacity"`
+	DroppedMessages   uint64    `json:"dropped_messages"`
 }
 
 type Hub struct {
-	mu       sync.RWMutex
-	clients  map[*client]struct{}
-	upgrader websocket.Upgrader
-	config   hubConfig
+	mu           sync.RWMutex
+	clients      map[*client]struct{}
+	nextClientID atomic.Uint64
+	upgrader     websocket.Upgrader
+	config       hubConfig
+	snapshotMu   sync.RWMutex
+	snapshot     SnapshotFunc
+
+	droppedMessages atomic.Uint64
+	evictedClients  atomic.Uint64
 }
 
-func NewHub() *Hub {
+// NewHub builds a hub that accepts upgrades from allowedOrigins (the CORS
+// list), from its own origin, and from clients that send no Origin header.
+func NewHub(allowedOrigins []string) *Hub {
 	return newHubWithConfig(hubConfig{
 		clientSendQueueSize: defaultClientSendQueueSize,
 		clientWriteTimeout:  defaultClientWriteTimeout,
 		pingInterval:        defaultPingInterval,
 		pongWait:            defaultPongWait,
+		maxClientDrops:      defaultMaxClientDrops,
+		allowedOrigins:      allowedOrigins,
 	})
 }
 
@@ -59,19 +126,48 @@ func newHubWithConfig(cfg hubConfig) *Hub {
 	if cfg.pongWait <= 0 {
 		cfg.pongWait = defaultPongWait
 	}
+	if cfg.maxClientDrops <= 0 {
+		cfg.maxClientDrops = defaultMaxClientDrops
+	}
 	return &Hub{
 		clients: map[*client]struct{}{},
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  8192,
 			WriteBufferSize: 8192,
-			CheckOrigin: func(r *http.Request) bool {
-				return true
-			},
+			CheckOrigin:     originChecker(cfg.allowedOrigins),
 		},
 		config: cfg,
 	}
 }
 
+// originChecker rejects cross-site upgrades so a page on another origin
+// cannot ride a user's browser session into the monitor stream.
+func originChecker(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := map[string]struct{}{}
+	allowAll := false
+	for _, origin := range allowedOrigins {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			allowAll = true
+		}
+		if origin != "" {
+			allowed[origin] = struct{}{}
+		}
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || allowAll {
+			return true
+		}
+		if _, ok := allowed[origin]; ok {
+			return true
+		}
+		parsed, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(parsed.Host, r.Host)
+	}
+}
+
 func newClient(conn *websocket.Conn, sendQueueSize int) *client {
 	return &client{
 		conn: conn,
@@ -81,6 +177,12 @@ func newClient(conn *websocket.Conn, sendQueueSize int) *client {
 }
 
 func (h *Hub) registerClient(c *client) {
+	if c.id == 0 {
+		c.id = h.nextClientID.Add(1)
+	}
+	if c.connectedAt.IsZero() {
+		c.connectedAt = time.Now().UTC()
+	}
 	h.mu.Lock()
 	h.clients[c] = struct{}{}
 	h.mu.Unlock()
@@ -120,12 +222,48 @@ func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
 	_ = conn.SetWriteDeadline(time.Time{})
 
 	client := newClient(conn, h.config.clientSendQueueSize)
+	client.remoteAddr = clientRemoteAddr(r)
+	client.userAgent = r.UserAgent()
+	h.enqueueConnectSnapshot(client)
 	h.registerClient(client)
 
 	go h.writePump(client)
 	go h.readPump(client)
 }
 
+func (h *Hub) SetConnectSnapshot(fn SnapshotFunc) {
+	h.snapshotMu.Lock()
+	h.snapshot = fn
+	h.snapshotMu.Unlock()
+}
+
+// enqueueConnectSnapshot runs before the client is registered so the snapshot
+// is always the first frame in its send queue.
+func (h *Hub) enqueueConnectSnapshot(c *client) {
+	h.snapshotMu.RLock()
+	fn := h.snapshot
+	h.snapshotMu.RUnlock()
+	if fn == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectSnapshotTimeout)
+	defer cancel()
+	event, err := fn(ctx)
+	if err != nil {
+		slog.Warn("websocket connect snapshot failed", "remote_addr", c.remoteAddr, "error", err)
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	select {
+	case c.send <- payload:
+	default:
+	}
+}
+
 func (h *Hub) readPump(c *client) {
 	defer h.unregisterClient(c)
 
@@ -137,10 +275,68 @@ func (h *Hub) readPump(c *client) {
 	})
 
 	for {
-		if _, _, err := c.conn.ReadMessage(); err != nil {
+		messageType, message, err := c.conn.ReadMessage()
+		if err != nil {
 			return
 		}
+		if messageType == websocket.TextMessage {
+			c.handleMessage(message)
+		}
+	}
+}
+
+// handleMessage applies subscription frames and ignores anything else, so
+// older clients that never send one keep receiving every event.
+func (c *client) handleMessage(message []byte) {
+	var msg clientMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+	switch msg.Type {
+	case "subscribe":
+		c.setFilter(msg.EndpointIDs)
+	case "unsubscribe":
+		c.setFilter(nil)
+	}
+}
+
+func (c *client) setFilter(endpointIDs []int64) {
+	var filter map[int64]struct{}
+	if len(endpointIDs) > 0 {
+		filter = make(map[int64]struct{}, len(endpointIDs))
+		for _, endpointID := range endpointIDs {
+			filter[endpointID] = struct{}{}
+		}
+	}
+	c.filterMu.Lock()
+	c.filter = filter
+	c.filterMu.Unlock()
+}
+
+// matchingEndpointIDs returns the subset of endpointIDs the client subscribed
+// to and whether the client is subscribed at all.
+func (c *client) matchingEndpointIDs(endpointIDs []int64) ([]int64, bool) {
+	c.filterMu.RLock()
+	defer c.filterMu.RUnlock()
+	if c.filter == nil {
+		return nil, false
+	}
+	matched := []int64{}
+	for _, endpointID := range endpointIDs {
+		if _, ok := c.filter[endpointID]; ok {
+			matched = append(matched, endpointID)
+		}
+	}
+	return matched, true
+}
+
+func (c *client) subscriptionLabel() string {
+	c.filterMu.RLock()
+	defer c.filterMu.RUnlock()
+	if c.filter == nil {
+		return "all"
 	}
+	return fmt.Sprintf("%d endpoints", len(c.filter))
 }
 
 func (h *Hub) writePump(c *client) {
@@ -187,20 +383,75 @@ func (h *Hub) Broadcast(event any) {
 	}
 
 	for _, c := range h.snapshotClients() {
-		select {
-		case <-c.done:
+		h.enqueue(c, payload)
+	}
+}
+
+// BroadcastProbeUpdate sends a probe_update for the persisted endpointIDs.
+// Unsubscribed clients get the full list; subscribed clients get only their
+// endpoints and nothing when none of them are in the batch.
+func (h *Hub) BroadcastProbeUpdate(endpointIDs []int64, timestamp time.Time) {
+	var fullPayload []byte
+	for _, c := range h.snapshotClients() {
+		matched, subscribed := c.matchingEndpointIDs(endpointIDs)
+		if !subscribed {
+			if fullPayload == nil {
+				payload, err := json.Marshal(probeUpdateEvent{Type: "probe_update", Count: len(endpointIDs), EndpointIDs: endpointIDs, Timestamp: timestamp})
+				if err != nil {
+					return
+				}
+				fullPayload = payload
+			}
+			h.enqueue(c, fullPayload)
 			continue
-		default:
 		}
+		if len(matched) == 0 {
+			continue
+		}
+		payload, err := json.Marshal(probeUpdateEvent{Type: "probe_update", Count: len(matched), EndpointIDs: matched, Timestamp: timestamp})
+		if err != nil {
+			continue
+		}
+		h.enqueue(c, payload)
+	}
+}
 
-		select {
-		case c.send <- payload:
-		default:
-			h.unregisterClient(c)
+// enqueue never blocks the fan-out. A client whose queue is full misses the
+// event; one that stays full for maxClientDrops events in a row is
+// disconnected so it can reconnect and resync from the connect snapshot.
+func (h *Hub) enqueue(c *client, payload []byte) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.send <- payload:
+		c.consecutiveDrops.Store(0)
+	default:
+		c.dropped.Add(1)
+		h.droppedMessages.Add(1)
+		if c.consecutiveDrops.Add(1) < int64(h.config.maxClientDrops) {
+			return
 		}
+		h.evictedClients.Add(1)
+		slog.Warn("websocket slow client evicted", "client_id", c.id, "remote_addr", c.remoteAddr, "dropped", c.dropped.Load())
+		h.unregisterClient(c)
 	}
 }
 
+// DroppedMessages is the number of events dropped for full client queues
+// since the hub started.
+func (h *Hub) DroppedMessages() uint64 {
+	return h.droppedMessages.Load()
+}
+
+// EvictedClients is the number of clients disconnected as slow consumers.
+func (h *Hub) EvictedClients() uint64 {
+	return h.evictedClients.Load()
+}
+
 func (h *Hub) Close() {
 	for _, c := range h.snapshotClients() {
 		h.unregisterClient(c)
@@ -212,3 +463,34 @@ func (h *Hub) ClientCount() int {
 	defer h.mu.RUnlock()
 	return len(h.clients)
 }
+
+func (h *Hub) Clients() []ClientInfo {
+	clients := h.snapshotClients()
+	items := make([]ClientInfo, 0, len(clients))
+	for _, c := range clients {
+		items = append(items, ClientInfo{
+			ID:                c.id,
+			RemoteAddr:        c.remoteAddr,
+			UserAgent:         c.userAgent,
+			ConnectedAt:       c.connectedAt,
+			Subscription:      c.subscriptionLabel(),
+			QueuedMessages:    len(c.send),
+			SendQueueCapacity: cap(c.send),
+			DroppedMessages:   c.dropped.Load(),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+	return items
+}
+
+func clientRemoteAddr(r *http.Request) string {
+	if forwarded := strings.TrimSpace(r.Header.Get("X-Forwarded-For")); forwarded != "" {
+		if first, _, ok := strings.Cut(forwarded, ","); ok {
+			return strings.TrimSpace(first)
+		}
+		return forwarded
+	}
+	return r.RemoteAddr
+}
@@ -2,13 +2,16 @@ package telemetry
 
 import (
 	"bufio"
+	"context"
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
@@ -18,7 +21,7 @@ import (
 )
 
 func TestNewHubUsesHardenedDefaults(t *testing.T) {
-	hub := NewHub()
+	hub := NewHub(nil)
 
 	if got := hub.config.clientSendQueueSize; got != 512 {
 		t.Fatalf("client send queue size = %d, want 512", got)
@@ -26,6 +29,9 @@ func TestNewHubUsesHardenedDefaults(t *testing.T) {
 	if got := hub.config.clientWriteTimeout; got != 10*time.Second {
 		t.Fatalf("client write timeout = %s, want 10s", got)
 	}
+	if got := hub.config.maxClientDrops; got != 64 {
+		t.Fatalf("max client drops = %d, want 64", got)
+	}
 	if got := hub.upgrader.ReadBufferSize; got != 8192 {
 		t.Fatalf("read buffer size = %d, want 8192", got)
 	}
@@ -35,7 +41,7 @@ func TestNewHubUsesHardenedDefaults(t *testing.T) {
 }
 
 func TestHubBroadcastReturnsPromptlyWithFullQueue(t *testing.T) {
-	hub := NewHub()
+	hub := newHubWithConfig(hubConfig{maxClientDrops: 1})
 	slow := &client{send: make(chan []byte, 1), done: make(chan struct{})}
 	healthy := &client{send: make(chan []byte, 1), done: make(chan struct{})}
 
@@ -79,7 +85,7 @@ func TestHubBroadcastReturnsPromptlyWithFullQueue(t *testing.T) {
 }
 
 func TestHubBroadcastRemovesSlowClientWithFullQueue(t *testing.T) {
-	hub := NewHub()
+	hub := newHubWithConfig(hubConfig{maxClientDrops: 1})
 	full := &client{send: make(chan []byte, 1), done: make(chan struct{})}
 	full.send <- []byte(`"busy"`)
 	hub.registerClient(full)
@@ -102,8 +108,42 @@ func TestHubBroadcastRemovesSlowClientWithFullQueue(t *testing.T) {
 	}
 }
 
+func TestHubBroadcastDropsForSlowClientBeforeEvicting(t *testing.T) {
+	hub := newHubWithConfig(hubConfig{maxClientDrops: 3})
+	slow := &client{send: make(chan []byte, 1), done: make(chan struct{})}
+	hub.registerClient(slow)
+
+	hub.Broadcast(map[string]any{"type": "probe_update"})
+	hub.Broadcast(map[string]any{"type": "probe_update"})
+	hub.Broadcast(map[string]any{"type": "probe_update"})
+	if got := hub.ClientCount(); got != 1 {
+		t.Fatalf("client count after two drops = %d, want 1", got)
+	}
+	if got := hub.Clients()[0].DroppedMessages; got != 2 {
+		t.Fatalf("client dropped messages = %d, want 2", got)
+	}
+
+	<-slow.send
+	hub.Broadcast(map[string]any{"type": "probe_update"})
+	hub.Broadcast(map[string]any{"type": "probe_update"})
+	hub.Broadcast(map[string]any{"type": "probe_update"})
+	if got := hub.ClientCount(); got != 1 {
+		t.Fatalf("expected a delivered event to reset the drop streak, client count = %d", got)
+	}
+
+	hub.Broadcast(map[string]any{"type": "probe_update"})
+	select {
+	case <-slow.done:
+	default:
+		t.Fatal("slow client was not evicted after consecutive drops")
+	}
+	if hub.DroppedMessages() != 5 || hub.EvictedClients() != 1 {
+		t.Fatalf("hub counters dropped=%d evicted=%d, want 5 and 1", hub.DroppedMessages(), hub.EvictedClients())
+	}
+}
+
 func TestHubWriteFailureRemovesOnlyFailingClient(t *testing.T) {
-	hub := NewHub()
+	hub := NewHub(nil)
 
 	failingConn, failingPeer := newPipeWebSocketConn(t)
 	healthyConn, healthyPeer := newPipeWebSocketConn(t)
@@ -149,7 +189,7 @@ func TestHubWriteFailureRemovesOnlyFailingClient(t *testing.T) {
 }
 
 func TestHubReadPumpDisconnectAndClose(t *testing.T) {
-	hub := NewHub()
+	hub := NewHub(nil)
 
 	clientAConn, peerA := newPipeWebSocketConn(t)
 	clientBConn, peerB := newPipeWebSocketConn(t)
@@ -178,7 +218,7 @@ func TestHubReadPumpDisconnectAndClose(t *testing.T) {
 }
 
 func TestHubWriteClientPayloadAttemptsWriteAfterDoneClosed(t *testing.T) {
-	hub := NewHub()
+	hub := NewHub(nil)
 
 	conn, peer := newPipeWebSocketConn(t)
 	defer peer.Close()
@@ -253,6 +293,87 @@ func TestHubKeepaliveRemovesClientWithoutPong(t *testing.T) {
 	waitForSignal(t, clientDone, "no-pong client reader exit")
 }
 
+func TestHubClientsReportsConnectionMetadata(t *testing.T) {
+	hub := NewHub(nil)
+	first := &client{remoteAddr: "10.0.0.5:51000", userAgent: "browser", send: make(chan []byte, 4), done: make(chan struct{})}
+	second := &client{remoteAddr: "10.0.0.6:52000", send: make(chan []byte, 4), done: make(chan struct{})}
+	hub.registerClient(first)
+	hub.registerClient(second)
+	first.send <- []byte(`"queued"`)
+
+	clients := hub.Clients()
+	if len(clients) != 2 {
+		t.Fatalf("clients len = %d, want 2", len(clients))
+	}
+	if clients[0].ID >= clients[1].ID {
+		t.Fatalf("expected clients ordered by id, got %d then %d", clients[0].ID, clients[1].ID)
+	}
+	if clients[0].RemoteAddr != "10.0.0.5:51000" || clients[0].UserAgent != "browser" {
+		t.Fatalf("unexpected first client metadata: %+v", clients[0])
+	}
+	if clients[0].ConnectedAt.IsZero() {
+		t.Fatal("expected connected_at to be recorded on register")
+	}
+	if clients[0].QueuedMessages != 1 || clients[0].SendQueueCapacity != 4 {
+		t.Fatalf("unexpected queue stats: %+v", clients[0])
+	}
+
+	hub.unregisterClient(first)
+	if got := len(hub.Clients()); got != 1 {
+		t.Fatalf("clients len after unregister = %d, want 1", got)
+	}
+}
+
+func TestHubProbeUpdateHonorsClientSubscription(t *testing.T) {
+	hub := NewHub(nil)
+	everyone := &client{send: make(chan []byte, 4), done: make(chan struct{})}
+	subscribed := &client{send: make(chan []byte, 4), done: make(chan struct{})}
+	hub.registerClient(everyone)
+	hub.registerClient(subscribed)
+	subscribed.handleMessage([]byte(`{"type":"subscribe","endpoint_ids":[2,9]}`))
+
+	hub.BroadcastProbeUpdate([]int64{1, 2, 3}, time.Now().UTC())
+	hub.BroadcastProbeUpdate([]int64{4, 5}, time.Now().UTC())
+	hub.Broadcast(map[string]any{"type": "probe_error", "endpoint_id": 4})
+
+	if got := len(everyone.send); got != 3 {
+		t.Fatalf("unsubscribed client queue len = %d, want 3", got)
+	}
+	if got := len(subscribed.send); got != 2 {
+		t.Fatalf("subscribed client queue len = %d, want 2", got)
+	}
+	var event probeUpdateEvent
+	if err := json.Unmarshal(<-subscribed.send, &event); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if event.Count != 1 || len(event.EndpointIDs) != 1 || event.EndpointIDs[0] != 2 {
+		t.Fatalf("expected only subscribed endpoint in update, got %+v", event)
+	}
+	if got := hub.Clients()[1].Subscription; got != "2 endpoints" {
+		t.Fatalf("subscription label = %q, want 2 endpoints", got)
+	}
+
+	subscribed.handleMessage([]byte(`{"type":"unsubscribe"}`))
+	<-subscribed.send
+	hub.BroadcastProbeUpdate([]int64{4}, time.Now().UTC())
+	if got := len(subscribed.send); got != 1 {
+		t.Fatalf("expected unsubscribe to restore full stream, queue len = %d", got)
+	}
+}
+
+func TestClientRemoteAddrPrefersForwardedFor(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodGet, "http://example/ws/monitor", nil)
+	r.RemoteAddr = "172.16.0.2:4000"
+	if got := clientRemoteAddr(r); got != "172.16.0.2:4000" {
+		t.Fatalf("remote addr = %q, want socket address", got)
+	}
+
+	r.Header.Set("X-Forwarded-For", "198.51.100.7, 172.16.0.1")
+	if got := clientRemoteAddr(r); got != "198.51.100.7" {
+		t.Fatalf("remote addr = %q, want first forwarded address", got)
+	}
+}
+
 func newPipeWebSocketConn(t *testing.T) (*websocket.Conn, net.Conn) {
 	t.Helper()
 
@@ -425,3 +546,84 @@ func waitForClientCount(t *testing.T, hub *Hub, want int) {
 	}
 	t.Fatalf("client count = %d, want %d", hub.ClientCount(), want)
 }
+
+func TestHubConnectSnapshotIsFirstQueuedFrame(t *testing.T) {
+	hub := NewHub(nil)
+	hub.SetConnectSnapshot(func(context.Context) (any, error) {
+		return map[string]any{"type": "state_snapshot", "count": 2}, nil
+	})
+	c := &client{send: make(chan []byte, 4), done: make(chan struct{})}
+
+	hub.enqueueConnectSnapshot(c)
+	hub.registerClient(c)
+	hub.Broadcast(map[string]any{"type": "probe_update", "count": 1})
+
+	var first map[string]any
+	if err := json.Unmarshal(<-c.send, &first); err != nil {
+		t.Fatalf("decode snapshot frame: %v", err)
+	}
+	if first["type"] != "state_snapshot" {
+		t.Fatalf("first frame type = %v, want state_snapshot", first["type"])
+	}
+	var second map[string]any
+	if err := json.Unmarshal(<-c.send, &second); err != nil {
+		t.Fatalf("decode delta frame: %v", err)
+	}
+	if second["type"] != "probe_update" {
+		t.Fatalf("second frame type = %v, want probe_update", second["type"])
+	}
+}
+
+func TestHubConnectSnapshotErrorSendsNothing(t *testing.T) {
+	hub := NewHub(nil)
+	hub.SetConnectSnapshot(func(context.Context) (any, error) {
+		return nil, errors.New("store unavailable")
+	})
+	c := &client{send: make(chan []byte, 4), done: make(chan struct{})}
+
+	hub.enqueueConnectSnapshot(c)
+	if got := len(c.send); got != 0 {
+		t.Fatalf("queued frames = %d, want 0 after snapshot error", got)
+	}
+}
+
+func TestHubCheckOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{name: "no origin header", allowed: []string{"http://localhost:5173"}, origin: "", want: true},
+		{name: "listed origin", allowed: []string{"http://localhost:5173"}, origin: "http://localhost:5173", want: true},
+		{name: "same origin", allowed: nil, origin: "http://sonarscope.example:8080", want: true},
+		{name: "cross-site origin", allowed: []string{"http://localhost:5173"}, origin: "https://evil.example", want: false},
+		{name: "wildcard", allowed: []string{"*"}, origin: "https://anything.example", want: true},
+	}
+	for _, tc := range tests {
+		hub := NewHub(tc.allowed)
+		req := httptest.NewRequest(http.MethodGet, "http://sonarscope.example:8080/ws/monitor", nil)
+		if tc.origin != "" {
+			req.Header.Set("Origin", tc.origin)
+		}
+		if got := hub.upgrader.CheckOrigin(req); got != tc.want {
+			t.Fatalf("%s: CheckOrigin = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestHubRejectsCrossSiteUpgrade(t *testing.T) {
+	hub := NewHub([]string{"http://localhost:5173"})
+	server := httptest.NewServer(http.HandlerFunc(hub.ServeWS))
+	defer server.Close()
+	defer hub.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": []string{"https://evil.example"}})
+	if err == nil {
+		t.Fatalf("cross-site upgrade should fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %+v", resp)
+	}
+}
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS monitor_snapshot (
+    id TEXT PRIMARY KEY,
+    version INT NOT NULL,
+    captured_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    document JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_monitor_snapshot_captured_at ON monitor_snapshot(captured_at DESC);
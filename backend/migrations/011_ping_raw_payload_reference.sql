@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS probe_settings_history (
+    id BIGSERIAL PRIMARY KEY,
+    effective_from TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ping_interval_sec INT NOT NULL,
+    icmp_payload_bytes INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_probe_settings_history_effective_from ON probe_settings_history(effective_from DESC);
+
+INSERT INTO probe_settings_history(effective_from, ping_interval_sec, icmp_payload_bytes)
+SELECT '-infinity'::timestamptz, ping_interval_sec, icmp_payload_bytes
+FROM app_settings
+WHERE id = TRUE
+  AND NOT EXISTS (SELECT 1 FROM probe_settings_history);
+
+ALTER TABLE ping_raw
+ALTER COLUMN payload_bytes DROP NOT NULL;
+
+CREATE OR REPLACE VIEW ping_raw_resolved AS
+SELECT
+    pr.ts,
+    pr.endpoint_id,
+    pr.success,
+    pr.latency_ms,
+    pr.reply_ip,
+    pr.ttl,
+    pr.error_code,
+    COALESCE(pr.payload_bytes, psh.icmp_payload_bytes) AS payload_bytes
+FROM ping_raw pr
+LEFT JOIN LATERAL (
+    SELECT h.icmp_payload_bytes
+    FROM probe_settings_history h
+    WHERE h.effective_from <= pr.ts
+    ORDER BY h.effective_from DESC, h.id DESC
+    LIMIT 1
+) psh ON TRUE;
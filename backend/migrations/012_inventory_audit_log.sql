@@ -0,0 +1,54 @@
+CREATE TABLE IF NOT EXISTS inventory_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint_id BIGINT NOT NULL,
+    action TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    before_data JSONB,
+    after_data JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_audit_log_endpoint_changed
+ON inventory_audit_log(endpoint_id, changed_at DESC, id DESC);
+
+CREATE OR REPLACE FUNCTION inventory_audit_log_capture() RETURNS trigger AS $$
+DECLARE
+    before_row JSONB;
+    after_row JSONB;
+    changed_before JSONB;
+    changed_after JSONB;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO inventory_audit_log(endpoint_id, action, before_data, after_data)
+        VALUES (NEW.id, 'create', NULL, to_jsonb(NEW) - 'updated_at');
+        RETURN NEW;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO inventory_audit_log(endpoint_id, action, before_data, after_data)
+        VALUES (OLD.id, 'delete', to_jsonb(OLD) - 'updated_at', NULL);
+        RETURN OLD;
+    END IF;
+
+    before_row := to_jsonb(OLD) - 'updated_at';
+    after_row := to_jsonb(NEW) - 'updated_at';
+    IF before_row = after_row THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT
+        COALESCE(jsonb_object_agg(b.key, b.value), '{}'::jsonb),
+        COALESCE(jsonb_object_agg(b.key, after_row -> b.key), '{}'::jsonb)
+    INTO changed_before, changed_after
+    FROM jsonb_each(before_row) b
+    WHERE after_row -> b.key IS DISTINCT FROM b.value;
+
+    INSERT INTO inventory_audit_log(endpoint_id, action, before_data, after_data)
+    VALUES (NEW.id, 'update', changed_before, changed_after);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_inventory_audit_log ON inventory_endpoint;
+CREATE TRIGGER trg_inventory_audit_log
+AFTER INSERT OR UPDATE OR DELETE ON inventory_endpoint
+FOR EACH ROW EXECUTE FUNCTION inventory_audit_log_capture();
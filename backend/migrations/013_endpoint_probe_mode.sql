@@ -0,0 +1,38 @@
+ALTER TABLE inventory_endpoint
+ADD COLUMN IF NOT EXISTS probe_mode TEXT NOT NULL DEFAULT 'icmp',
+ADD COLUMN IF NOT EXISTS probe_http_target TEXT NOT NULL DEFAULT '';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'inventory_endpoint_probe_mode_check'
+    ) THEN
+        ALTER TABLE inventory_endpoint
+        ADD CONSTRAINT inventory_endpoint_probe_mode_check CHECK (probe_mode IN ('icmp', 'http', 'https'));
+    END IF;
+END $$;
+
+ALTER TABLE ping_raw
+ADD COLUMN IF NOT EXISTS http_status INT;
+
+CREATE OR REPLACE VIEW ping_raw_resolved AS
+SELECT
+    pr.ts,
+    pr.endpoint_id,
+    pr.success,
+    pr.latency_ms,
+    pr.reply_ip,
+    pr.ttl,
+    pr.error_code,
+    COALESCE(pr.payload_bytes, psh.icmp_payload_bytes) AS payload_bytes,
+    pr.http_status
+FROM ping_raw pr
+LEFT JOIN LATERAL (
+    SELECT h.icmp_payload_bytes
+    FROM probe_settings_history h
+    WHERE h.effective_from <= pr.ts
+    ORDER BY h.effective_from DESC, h.id DESC
+    LIMIT 1
+) psh ON TRUE;
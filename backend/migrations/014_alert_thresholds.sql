@@ -0,0 +1,7 @@
+ALTER TABLE app_settings
+ADD COLUMN IF NOT EXISTS alert_consecutive_failed INT NOT NULL DEFAULT 3,
+ADD COLUMN IF NOT EXISTS alert_failed_pct DOUBLE PRECISION NOT NULL DEFAULT 50;
+
+ALTER TABLE group_def
+ADD COLUMN IF NOT EXISTS alert_consecutive_failed INT,
+ADD COLUMN IF NOT EXISTS alert_failed_pct DOUBLE PRECISION;
@@ -0,0 +1,23 @@
+ALTER TABLE ping_raw
+ADD COLUMN IF NOT EXISTS target_ip INET;
+
+CREATE OR REPLACE VIEW ping_raw_resolved AS
+SELECT
+    pr.ts,
+    pr.endpoint_id,
+    pr.success,
+    pr.latency_ms,
+    pr.reply_ip,
+    pr.ttl,
+    pr.error_code,
+    COALESCE(pr.payload_bytes, psh.icmp_payload_bytes) AS payload_bytes,
+    pr.http_status,
+    pr.target_ip
+FROM ping_raw pr
+LEFT JOIN LATERAL (
+    SELECT h.icmp_payload_bytes
+    FROM probe_settings_history h
+    WHERE h.effective_from <= pr.ts
+    ORDER BY h.effective_from DESC, h.id DESC
+    LIMIT 1
+) psh ON TRUE;
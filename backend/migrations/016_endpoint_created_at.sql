@@ -0,0 +1,6 @@
+-- Existing endpoints get the epoch so they are never treated as newly imported.
+ALTER TABLE inventory_endpoint
+ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT 'epoch';
+
+ALTER TABLE inventory_endpoint
+ALTER COLUMN created_at SET DEFAULT now();
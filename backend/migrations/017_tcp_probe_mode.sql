@@ -0,0 +1,24 @@
+ALTER TABLE app_settings
+ADD COLUMN IF NOT EXISTS probe_mode TEXT NOT NULL DEFAULT 'icmp',
+ADD COLUMN IF NOT EXISTS tcp_port INT NOT NULL DEFAULT 443;
+
+ALTER TABLE inventory_endpoint
+ADD COLUMN IF NOT EXISTS probe_tcp_port INT NOT NULL DEFAULT 0;
+
+ALTER TABLE inventory_endpoint
+DROP CONSTRAINT IF EXISTS inventory_endpoint_probe_mode_check;
+
+ALTER TABLE inventory_endpoint
+ADD CONSTRAINT inventory_endpoint_probe_mode_check CHECK (probe_mode IN ('icmp', 'http', 'https', 'tcp'));
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'inventory_endpoint_probe_tcp_port_check'
+    ) THEN
+        ALTER TABLE inventory_endpoint
+        ADD CONSTRAINT inventory_endpoint_probe_tcp_port_check CHECK (probe_tcp_port BETWEEN 0 AND 65535);
+    END IF;
+END $$;
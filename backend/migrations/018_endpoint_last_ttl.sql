@@ -0,0 +1,2 @@
+ALTER TABLE endpoint_stats_current
+ADD COLUMN IF NOT EXISTS last_ttl INT;
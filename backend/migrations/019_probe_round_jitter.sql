@@ -0,0 +1,45 @@
+ALTER TABLE app_settings
+ADD COLUMN IF NOT EXISTS probes_per_round INT NOT NULL DEFAULT 1;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'app_settings_probes_per_round_check'
+    ) THEN
+        ALTER TABLE app_settings
+        ADD CONSTRAINT app_settings_probes_per_round_check CHECK (probes_per_round BETWEEN 1 AND 10);
+    END IF;
+END $$;
+
+ALTER TABLE ping_raw
+ADD COLUMN IF NOT EXISTS min_latency_ms DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS max_latency_ms DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS jitter_ms DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS loss_pct DOUBLE PRECISION;
+
+CREATE OR REPLACE VIEW ping_raw_resolved AS
+SELECT
+    pr.ts,
+    pr.endpoint_id,
+    pr.success,
+    pr.latency_ms,
+    pr.reply_ip,
+    pr.ttl,
+    pr.error_code,
+    COALESCE(pr.payload_bytes, psh.icmp_payload_bytes) AS payload_bytes,
+    pr.http_status,
+    pr.target_ip,
+    pr.min_latency_ms,
+    pr.max_latency_ms,
+    pr.jitter_ms,
+    pr.loss_pct
+FROM ping_raw pr
+LEFT JOIN LATERAL (
+    SELECT h.icmp_payload_bytes
+    FROM probe_settings_history h
+    WHERE h.effective_from <= pr.ts
+    ORDER BY h.effective_from DESC, h.id DESC
+    LIMIT 1
+) psh ON TRUE;
@@ -0,0 +1,14 @@
+ALTER TABLE inventory_endpoint
+ADD COLUMN IF NOT EXISTS probe_interval_sec INT;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'inventory_endpoint_probe_interval_sec_check'
+    ) THEN
+        ALTER TABLE inventory_endpoint
+        ADD CONSTRAINT inventory_endpoint_probe_interval_sec_check CHECK (probe_interval_sec IS NULL OR probe_interval_sec BETWEEN 1 AND 3600);
+    END IF;
+END $$;
@@ -0,0 +1,14 @@
+ALTER TABLE inventory_endpoint
+ALTER COLUMN ip DROP NOT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'inventory_endpoint_ip_or_hostname_check'
+    ) THEN
+        ALTER TABLE inventory_endpoint
+        ADD CONSTRAINT inventory_endpoint_ip_or_hostname_check CHECK (ip IS NOT NULL OR btrim(hostname) <> '');
+    END IF;
+END $$;
@@ -0,0 +1,42 @@
+ALTER TABLE group_member
+DROP CONSTRAINT IF EXISTS group_member_endpoint_unique;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conrelid = 'group_member'::regclass
+          AND contype = 'p'
+    ) THEN
+        ALTER TABLE group_member
+        ADD CONSTRAINT group_member_pkey PRIMARY KEY (group_id, endpoint_id);
+    END IF;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION assign_inventory_endpoint_to_default_group()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    v_no_group_id BIGINT;
+BEGIN
+    SELECT id
+    INTO v_no_group_id
+    FROM group_def
+    WHERE lower(name) = 'no group'
+    ORDER BY id
+    LIMIT 1;
+
+    IF v_no_group_id IS NULL THEN
+        RAISE EXCEPTION 'system group "no group" is missing';
+    END IF;
+
+    INSERT INTO group_member(group_id, endpoint_id)
+    VALUES (v_no_group_id, NEW.id)
+    ON CONFLICT DO NOTHING;
+
+    RETURN NEW;
+END
+$$;
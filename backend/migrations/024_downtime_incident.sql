@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS downtime_incident (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint_id BIGINT NOT NULL REFERENCES inventory_endpoint(id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ,
+    duration_sec DOUBLE PRECISION,
+    error_code TEXT NOT NULL DEFAULT ''
+);
+
+-- At most one open incident per endpoint; the probe writer relies on this to
+-- open incidents idempotently.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_downtime_incident_open ON downtime_incident(endpoint_id) WHERE ended_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_downtime_incident_endpoint_started ON downtime_incident(endpoint_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_downtime_incident_started ON downtime_incident(started_at DESC);
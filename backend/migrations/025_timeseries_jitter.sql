@@ -0,0 +1,48 @@
+-- Continuous aggregates cannot gain columns in place, and recreating ping_1m
+-- and ping_1h would drop every bucket older than ping_raw retention. Jitter
+-- lives in companion aggregates instead; readers LEFT JOIN them, so buckets
+-- from before this migration read jitter as NULL.
+CREATE MATERIALIZED VIEW IF NOT EXISTS ping_jitter_1m
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket(INTERVAL '1 minute', ts) AS bucket,
+    endpoint_id,
+    STDDEV_SAMP(latency_ms) FILTER (WHERE success) AS jitter_ms
+FROM ping_raw
+GROUP BY bucket, endpoint_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS ping_jitter_1h
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket(INTERVAL '1 hour', ts) AS bucket,
+    endpoint_id,
+    STDDEV_SAMP(latency_ms) FILTER (WHERE success) AS jitter_ms
+FROM ping_raw
+GROUP BY bucket, endpoint_id
+WITH NO DATA;
+
+ALTER MATERIALIZED VIEW ping_jitter_1m
+SET (timescaledb.materialized_only = false);
+
+ALTER MATERIALIZED VIEW ping_jitter_1h
+SET (timescaledb.materialized_only = false);
+
+SELECT add_continuous_aggregate_policy(
+    'ping_jitter_1m',
+    start_offset => INTERVAL '7 days',
+    end_offset => INTERVAL '1 minute',
+    schedule_interval => INTERVAL '1 minute',
+    if_not_exists => TRUE
+);
+
+SELECT add_continuous_aggregate_policy(
+    'ping_jitter_1h',
+    start_offset => INTERVAL '90 days',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour',
+    if_not_exists => TRUE
+);
+
+SELECT add_retention_policy('ping_jitter_1m', INTERVAL '12 months', if_not_exists => TRUE);
+SELECT add_retention_policy('ping_jitter_1h', INTERVAL '24 months', if_not_exists => TRUE);
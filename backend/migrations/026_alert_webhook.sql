@@ -0,0 +1,2 @@
+ALTER TABLE app_settings
+ADD COLUMN IF NOT EXISTS alert_webhook_url TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+ALTER TABLE app_settings
+ADD COLUMN IF NOT EXISTS retention_raw_days INT NOT NULL DEFAULT 30,
+ADD COLUMN IF NOT EXISTS retention_1m_days INT NOT NULL DEFAULT 365,
+ADD COLUMN IF NOT EXISTS retention_1h_days INT NOT NULL DEFAULT 730;
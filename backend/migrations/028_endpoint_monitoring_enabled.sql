@@ -0,0 +1,2 @@
+ALTER TABLE inventory_endpoint
+ADD COLUMN IF NOT EXISTS monitoring_enabled BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS maintenance_window (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    scope TEXT NOT NULL CHECK (scope IN ('all', 'group', 'endpoint')),
+    endpoint_id BIGINT REFERENCES inventory_endpoint(id) ON DELETE CASCADE,
+    group_id BIGINT REFERENCES group_def(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    recurrence TEXT NOT NULL DEFAULT '',
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (ends_at > starts_at),
+    CHECK (
+        (scope = 'all' AND endpoint_id IS NULL AND group_id IS NULL) OR
+        (scope = 'group' AND group_id IS NOT NULL AND endpoint_id IS NULL) OR
+        (scope = 'endpoint' AND endpoint_id IS NOT NULL AND group_id IS NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_window_ends_at ON maintenance_window(ends_at);
+
+-- Probes recorded inside a maintenance window keep their real outcome but are
+-- tagged so counters, alerts, and range/SLA queries can leave them out.
+ALTER TABLE ping_raw
+ADD COLUMN IF NOT EXISTS maintenance BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE OR REPLACE VIEW ping_raw_resolved AS
+SELECT
+    pr.ts,
+    pr.endpoint_id,
+    pr.success,
+    pr.latency_ms,
+    pr.reply_ip,
+    pr.ttl,
+    pr.error_code,
+    COALESCE(pr.payload_bytes, psh.icmp_payload_bytes) AS payload_bytes,
+    pr.http_status,
+    pr.target_ip,
+    pr.min_latency_ms,
+    pr.max_latency_ms,
+    pr.jitter_ms,
+    pr.loss_pct,
+    pr.fallback_probe,
+    pr.maintenance
+FROM ping_raw pr
+LEFT JOIN LATERAL (
+    SELECT h.icmp_payload_bytes
+    FROM probe_settings_history h
+    WHERE h.effective_from <= pr.ts
+    ORDER BY h.effective_from DESC, h.id DESC
+    LIMIT 1
+) psh ON TRUE;
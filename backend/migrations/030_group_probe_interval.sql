@@ -0,0 +1,16 @@
+-- A group's own probe cadence, used when the group is probed through a
+-- group-scoped schedule. Endpoint overrides still win.
+ALTER TABLE group_def
+ADD COLUMN IF NOT EXISTS probe_interval_sec INT;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'group_def_probe_interval_sec_check'
+    ) THEN
+        ALTER TABLE group_def
+        ADD CONSTRAINT group_def_probe_interval_sec_check CHECK (probe_interval_sec IS NULL OR probe_interval_sec BETWEEN 1 AND 3600);
+    END IF;
+END $$;
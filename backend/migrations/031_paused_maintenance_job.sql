@@ -0,0 +1,7 @@
+-- TimescaleDB policy jobs disabled by an inventory delete. Rows are removed
+-- when the delete resumes them, so anything left here after a crash is
+-- re-enabled at startup or through POST /api/admin/resume-maintenance.
+CREATE TABLE IF NOT EXISTS paused_maintenance_job (
+    job_id INTEGER PRIMARY KEY,
+    paused_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
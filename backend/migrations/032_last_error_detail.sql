@@ -0,0 +1,4 @@
+-- Raw error text of the latest failed probe, kept next to the categorized
+-- last_ping_status so operators can see why a probe failed.
+ALTER TABLE endpoint_stats_current
+ADD COLUMN IF NOT EXISTS last_error_detail TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+-- Consecutive failed probes before the monitor reports an endpoint as down.
+ALTER TABLE app_settings
+ADD COLUMN IF NOT EXISTS down_threshold INT NOT NULL DEFAULT 1;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'app_settings_down_threshold_check'
+    ) THEN
+        ALTER TABLE app_settings
+        ADD CONSTRAINT app_settings_down_threshold_check CHECK (down_threshold BETWEEN 1 AND 1000);
+    END IF;
+END $$;
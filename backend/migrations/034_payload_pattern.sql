@@ -0,0 +1,15 @@
+-- Filler for the ICMP echo payload after the send stamp.
+ALTER TABLE app_settings
+ADD COLUMN IF NOT EXISTS payload_pattern TEXT NOT NULL DEFAULT 'incrementing';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'app_settings_payload_pattern_check'
+    ) THEN
+        ALTER TABLE app_settings
+        ADD CONSTRAINT app_settings_payload_pattern_check CHECK (payload_pattern IN ('zeros', 'random', 'incrementing', 'timestamp'));
+    END IF;
+END $$;
@@ -0,0 +1,35 @@
+-- Extra echo replies that matched an already answered probe, per round in
+-- ping_raw and as a resettable counter in endpoint_stats_current.
+ALTER TABLE ping_raw
+ADD COLUMN IF NOT EXISTS duplicate_replies INT NOT NULL DEFAULT 0;
+
+ALTER TABLE endpoint_stats_current
+ADD COLUMN IF NOT EXISTS duplicate_reply_count BIGINT NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE VIEW ping_raw_resolved AS
+SELECT
+    pr.ts,
+    pr.endpoint_id,
+    pr.success,
+    pr.latency_ms,
+    pr.reply_ip,
+    pr.ttl,
+    pr.error_code,
+    COALESCE(pr.payload_bytes, psh.icmp_payload_bytes) AS payload_bytes,
+    pr.http_status,
+    pr.target_ip,
+    pr.min_latency_ms,
+    pr.max_latency_ms,
+    pr.jitter_ms,
+    pr.loss_pct,
+    pr.fallback_probe,
+    pr.maintenance,
+    pr.duplicate_replies
+FROM ping_raw pr
+LEFT JOIN LATERAL (
+    SELECT h.icmp_payload_bytes
+    FROM probe_settings_history h
+    WHERE h.effective_from <= pr.ts
+    ORDER BY h.effective_from DESC, h.id DESC
+    LIMIT 1
+) psh ON TRUE;
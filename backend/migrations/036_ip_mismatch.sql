@@ -0,0 +1,37 @@
+-- Flags echo replies that came from a different address than the probed
+-- target, per probe in ping_raw and for the latest probe in
+-- endpoint_stats_current.
+ALTER TABLE ping_raw
+ADD COLUMN IF NOT EXISTS ip_mismatch BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE endpoint_stats_current
+ADD COLUMN IF NOT EXISTS last_ip_mismatch BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE OR REPLACE VIEW ping_raw_resolved AS
+SELECT
+    pr.ts,
+    pr.endpoint_id,
+    pr.success,
+    pr.latency_ms,
+    pr.reply_ip,
+    pr.ttl,
+    pr.error_code,
+    COALESCE(pr.payload_bytes, psh.icmp_payload_bytes) AS payload_bytes,
+    pr.http_status,
+    pr.target_ip,
+    pr.min_latency_ms,
+    pr.max_latency_ms,
+    pr.jitter_ms,
+    pr.loss_pct,
+    pr.fallback_probe,
+    pr.maintenance,
+    pr.duplicate_replies,
+    pr.ip_mismatch
+FROM ping_raw pr
+LEFT JOIN LATERAL (
+    SELECT h.icmp_payload_bytes
+    FROM probe_settings_history h
+    WHERE h.effective_from <= pr.ts
+    ORDER BY h.effective_from DESC, h.id DESC
+    LIMIT 1
+) psh ON TRUE;
@@ -0,0 +1,49 @@
+-- Timed-out rounds can be re-sent up to probe_retries times before they
+-- count as failed; ping_raw.retries records how many re-sends a round took.
+ALTER TABLE app_settings
+ADD COLUMN IF NOT EXISTS probe_retries INT NOT NULL DEFAULT 0;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'app_settings_probe_retries_check'
+    ) THEN
+        ALTER TABLE app_settings
+        ADD CONSTRAINT app_settings_probe_retries_check CHECK (probe_retries BETWEEN 0 AND 5);
+    END IF;
+END $$;
+
+ALTER TABLE ping_raw
+ADD COLUMN IF NOT EXISTS retries INT NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE VIEW ping_raw_resolved AS
+SELECT
+    pr.ts,
+    pr.endpoint_id,
+    pr.success,
+    pr.latency_ms,
+    pr.reply_ip,
+    pr.ttl,
+    pr.error_code,
+    COALESCE(pr.payload_bytes, psh.icmp_payload_bytes) AS payload_bytes,
+    pr.http_status,
+    pr.target_ip,
+    pr.min_latency_ms,
+    pr.max_latency_ms,
+    pr.jitter_ms,
+    pr.loss_pct,
+    pr.fallback_probe,
+    pr.maintenance,
+    pr.duplicate_replies,
+    pr.ip_mismatch,
+    pr.retries
+FROM ping_raw pr
+LEFT JOIN LATERAL (
+    SELECT h.icmp_payload_bytes
+    FROM probe_settings_history h
+    WHERE h.effective_from <= pr.ts
+    ORDER BY h.effective_from DESC, h.id DESC
+    LIMIT 1
+) psh ON TRUE;
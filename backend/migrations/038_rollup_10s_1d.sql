@@ -0,0 +1,101 @@
+-- A 10-second rollup for short high-resolution windows and a 1-day rollup for
+-- multi-month views. Both start empty; buckets past ping_raw retention cannot
+-- be rebuilt, so ping_1d only covers history from the raw rows still kept.
+CREATE MATERIALIZED VIEW IF NOT EXISTS ping_10s
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket(INTERVAL '10 seconds', ts) AS bucket,
+    endpoint_id,
+    COUNT(*)::BIGINT AS sent_count,
+    COUNT(*) FILTER (WHERE NOT success)::BIGINT AS fail_count,
+    (COUNT(*) FILTER (WHERE NOT success)::DOUBLE PRECISION / NULLIF(COUNT(*), 0)::DOUBLE PRECISION) * 100 AS loss_rate,
+    AVG(latency_ms) FILTER (WHERE success) AS avg_latency_ms,
+    MAX(latency_ms) FILTER (WHERE success) AS max_latency_ms
+FROM ping_raw
+GROUP BY bucket, endpoint_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS ping_1d
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket(INTERVAL '1 day', ts) AS bucket,
+    endpoint_id,
+    COUNT(*)::BIGINT AS sent_count,
+    COUNT(*) FILTER (WHERE NOT success)::BIGINT AS fail_count,
+    (COUNT(*) FILTER (WHERE NOT success)::DOUBLE PRECISION / NULLIF(COUNT(*), 0)::DOUBLE PRECISION) * 100 AS loss_rate,
+    AVG(latency_ms) FILTER (WHERE success) AS avg_latency_ms,
+    MAX(latency_ms) FILTER (WHERE success) AS max_latency_ms
+FROM ping_raw
+GROUP BY bucket, endpoint_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS ping_jitter_10s
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket(INTERVAL '10 seconds', ts) AS bucket,
+    endpoint_id,
+    STDDEV_SAMP(latency_ms) FILTER (WHERE success) AS jitter_ms
+FROM ping_raw
+GROUP BY bucket, endpoint_id
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS ping_jitter_1d
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket(INTERVAL '1 day', ts) AS bucket,
+    endpoint_id,
+    STDDEV_SAMP(latency_ms) FILTER (WHERE success) AS jitter_ms
+FROM ping_raw
+GROUP BY bucket, endpoint_id
+WITH NO DATA;
+
+ALTER MATERIALIZED VIEW ping_10s
+SET (timescaledb.materialized_only = false);
+
+ALTER MATERIALIZED VIEW ping_1d
+SET (timescaledb.materialized_only = false);
+
+ALTER MATERIALIZED VIEW ping_jitter_10s
+SET (timescaledb.materialized_only = false);
+
+ALTER MATERIALIZED VIEW ping_jitter_1d
+SET (timescaledb.materialized_only = false);
+
+SELECT add_continuous_aggregate_policy(
+    'ping_10s',
+    start_offset => INTERVAL '1 day',
+    end_offset => INTERVAL '10 seconds',
+    schedule_interval => INTERVAL '30 seconds',
+    if_not_exists => TRUE
+);
+
+SELECT add_continuous_aggregate_policy(
+    'ping_1d',
+    start_offset => INTERVAL '90 days',
+    end_offset => INTERVAL '1 day',
+    schedule_interval => INTERVAL '1 hour',
+    if_not_exists => TRUE
+);
+
+SELECT add_continuous_aggregate_policy(
+    'ping_jitter_10s',
+    start_offset => INTERVAL '1 day',
+    end_offset => INTERVAL '10 seconds',
+    schedule_interval => INTERVAL '30 seconds',
+    if_not_exists => TRUE
+);
+
+SELECT add_continuous_aggregate_policy(
+    'ping_jitter_1d',
+    start_offset => INTERVAL '90 days',
+    end_offset => INTERVAL '1 day',
+    schedule_interval => INTERVAL '1 hour',
+    if_not_exists => TRUE
+);
+
+-- ping_10s follows the raw retention window and ping_1d the 1h one; the
+-- store reapplies the saved settings at startup.
+SELECT add_retention_policy('ping_10s', INTERVAL '30 days', if_not_exists => TRUE);
+SELECT add_retention_policy('ping_jitter_10s', INTERVAL '30 days', if_not_exists => TRUE);
+SELECT add_retention_policy('ping_1d', INTERVAL '24 months', if_not_exists => TRUE);
+SELECT add_retention_policy('ping_jitter_1d', INTERVAL '24 months', if_not_exists => TRUE);
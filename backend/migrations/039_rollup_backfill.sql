@@ -0,0 +1,57 @@
+-- ping_1d was built from ping_raw, so it could never hold days older than raw
+-- retention while ping_1h keeps them for up to 24 months. Rebuild it on top of
+-- ping_1h: everything it held is still in ping_1h, and its refresh policy
+-- covers the longest 1h retention so the first run backfills the full history.
+-- ping_jitter_1d stays on ping_raw (a standard deviation cannot be rolled up
+-- from hourly ones); like the other jitter views, days before it read NULL.
+DROP MATERIALIZED VIEW IF EXISTS ping_1d;
+
+CREATE MATERIALIZED VIEW ping_1d
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket(INTERVAL '1 day', bucket) AS bucket,
+    endpoint_id,
+    SUM(sent_count)::BIGINT AS sent_count,
+    SUM(fail_count)::BIGINT AS fail_count,
+    (SUM(fail_count)::DOUBLE PRECISION / NULLIF(SUM(sent_count), 0)::DOUBLE PRECISION) * 100 AS loss_rate,
+    SUM(avg_latency_ms * (sent_count - fail_count)) /
+        NULLIF(SUM(sent_count - fail_count) FILTER (WHERE avg_latency_ms IS NOT NULL), 0)::DOUBLE PRECISION AS avg_latency_ms,
+    MAX(max_latency_ms) AS max_latency_ms
+FROM ping_1h
+GROUP BY time_bucket(INTERVAL '1 day', bucket), endpoint_id
+WITH NO DATA;
+
+ALTER MATERIALIZED VIEW ping_1d
+SET (timescaledb.materialized_only = false);
+
+SELECT add_continuous_aggregate_policy(
+    'ping_1d',
+    start_offset => INTERVAL '730 days',
+    end_offset => INTERVAL '1 day',
+    schedule_interval => INTERVAL '1 hour',
+    if_not_exists => TRUE
+);
+
+SELECT add_retention_policy('ping_1d', INTERVAL '24 months', if_not_exists => TRUE);
+
+-- ping_10s and ping_jitter_10s only refreshed the last day, so windows further
+-- back read nothing even while ping_raw still had the rows. Widen their
+-- refresh to the longest raw retention; the first run backfills it and later
+-- runs only redo invalidated buckets.
+SELECT remove_continuous_aggregate_policy('ping_10s', if_exists => TRUE);
+SELECT add_continuous_aggregate_policy(
+    'ping_10s',
+    start_offset => INTERVAL '90 days',
+    end_offset => INTERVAL '10 seconds',
+    schedule_interval => INTERVAL '30 seconds',
+    if_not_exists => TRUE
+);
+
+SELECT remove_continuous_aggregate_policy('ping_jitter_10s', if_exists => TRUE);
+SELECT add_continuous_aggregate_policy(
+    'ping_jitter_10s',
+    start_offset => INTERVAL '90 days',
+    end_offset => INTERVAL '10 seconds',
+    schedule_interval => INTERVAL '30 seconds',
+    if_not_exists => TRUE
+);
@@ -0,0 +1,10 @@
+-- Operators annotate incidents for post-incident reports. Empty means not set.
+ALTER TABLE downtime_incident
+    ADD COLUMN IF NOT EXISTS cause TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS severity TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE downtime_incident
+    DROP CONSTRAINT IF EXISTS downtime_incident_severity_check;
+ALTER TABLE downtime_incident
+    ADD CONSTRAINT downtime_incident_severity_check CHECK (severity IN ('', 'minor', 'major', 'critical'));
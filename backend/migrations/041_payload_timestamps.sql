@@ -0,0 +1,17 @@
+-- Restore the constant 0x42 filler the probe sent before payload_pattern
+-- existed, as a selectable pattern and as the default.
+ALTER TABLE app_settings
+DROP CONSTRAINT IF EXISTS app_settings_payload_pattern_check;
+
+ALTER TABLE app_settings
+ADD CONSTRAINT app_settings_payload_pattern_check CHECK (payload_pattern IN ('filler', 'zeros', 'random', 'incrementing', 'timestamp'));
+
+ALTER TABLE app_settings
+ALTER COLUMN payload_pattern SET DEFAULT 'filler';
+
+-- What the timestamp pattern's replies tell us, per round.
+ALTER TABLE ping_raw
+ADD COLUMN IF NOT EXISTS out_of_order_replies INT NOT NULL DEFAULT 0;
+
+ALTER TABLE ping_raw
+ADD COLUMN IF NOT EXISTS drift_ms DOUBLE PRECISION;
@@ -0,0 +1,5 @@
+-- Which probe answered the latest round when it was not the endpoint's
+-- primary one ('tcp' for an icmp_tcp round confirmed by a connect), so the
+-- monitor grid can tell a TCP-confirmed success from a plain ICMP reply.
+ALTER TABLE endpoint_stats_current
+ADD COLUMN IF NOT EXISTS last_fallback_probe TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Matches the default keyset order of the live monitor page, so resuming
+-- from a cursor is an index range scan instead of a sort of every endpoint.
+CREATE INDEX IF NOT EXISTS idx_inventory_monitor_keyset
+ON inventory_endpoint ((ip IS NULL), COALESCE(ip, '0.0.0.0'::inet), id)
+WHERE is_active = TRUE;
@@ -0,0 +1,4 @@
+-- ping_raw_resolved had no readers and every ping_raw column added since had
+-- to recreate it. An omitted payload_bytes is still recoverable by joining
+-- probe_settings_history on the latest effective_from <= ts.
+DROP VIEW IF EXISTS ping_raw_resolved;
@@ -0,0 +1,4 @@
+-- alert_failed_pct is now measured over the latest probes instead of the
+-- lifetime counters, and is off unless an operator picks a value.
+ALTER TABLE app_settings
+ALTER COLUMN alert_failed_pct SET DEFAULT 0;
@@ -0,0 +1,25 @@
+-- PING_RAW_OMIT_SETTINGS_PAYLOAD stores payload_bytes as NULL when it equals
+-- the probe_settings_history row in effect at ts. probe_payload_bytes_at
+-- returns that row's size, and resolved_payload_bytes(ping_raw) restores the
+-- column, also as pr.resolved_payload_bytes. Unlike the dropped
+-- ping_raw_resolved view, neither needs recreating when ping_raw gains a
+-- column.
+CREATE OR REPLACE FUNCTION probe_payload_bytes_at(probe_ts TIMESTAMPTZ)
+RETURNS INT
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT h.icmp_payload_bytes
+    FROM probe_settings_history h
+    WHERE h.effective_from <= probe_ts
+    ORDER BY h.effective_from DESC, h.id DESC
+    LIMIT 1
+$$;
+
+CREATE OR REPLACE FUNCTION resolved_payload_bytes(pr ping_raw)
+RETURNS INT
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT COALESCE(pr.payload_bytes, probe_payload_bytes_at(pr.ts))
+$$;
@@ -101,11 +101,44 @@ function createTimeoutSignal(timeoutMs: number, existingSignal?: AbortSignal | n
   };
 }
 
-async function fetchWithTimeout(path: string, init?: RequestInit, timeoutMs = DEFAULT_REQUEST_TIMEOUT_MS): Promise<Response> {
+let pendingSession: Promise<boolean> | null = null;
+
+// ensureSession asks for an API key once and trades it for an HttpOnly
+// session cookie, so the key is never kept in the page or the bundle.
+// Concurrent 401s share one prompt.
+function ensureSession(): Promise<boolean> {
+  if (!pendingSession) {
+    pendingSession = (async () => {
+      const apiKey = window.prompt("This SonarScope server requires an API key. Enter one to continue:")?.trim();
+      if (!apiKey) {
+        return false;
+      }
+      const response = await fetch(buildURL("/api/auth/session"), {
+        method: "POST",
+        credentials: "include",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ api_key: apiKey })
+      });
+      return response.ok;
+    })().finally(() => {
+      pendingSession = null;
+    });
+  }
+  return pendingSession;
+}
+
+async function fetchWithTimeout(
+  path: string,
+  init?: RequestInit,
+  timeoutMs = DEFAULT_REQUEST_TIMEOUT_MS,
+  retryAuth = true
+): Promise<Response> {
   const { signal, didTimeout, cleanup } = createTimeoutSignal(timeoutMs, init?.signal);
+  let response: Response;
   try {
-    return await fetch(buildURL(path), {
+    response = await fetch(buildURL(path), {
       ...init,
+      credentials: "include",
       signal
     });
   } catch (error) {
@@ -116,6 +149,10 @@ async function fetchWithTimeout(path: string, init?: RequestInit, timeoutMs = DE
   } finally {
     cleanup();
   }
+  if (response.status === 401 && retryAuth && (await ensureSession())) {
+    return fetchWithTimeout(path, init, timeoutMs, false);
+  }
+  return response;
 }
 
 async function request<T>(path: string, init?: RequestInit): Promise<T> {
@@ -117,7 +117,16 @@ function metricMeta(metric: Metric): "loss" | "latency" {
 }
 
 function toStepMs(rollup: TimeSeriesResponse["rollup"]): number {
-  return rollup === "1h" ? 60 * 60 * 1000 : 60 * 1000;
+  switch (rollup) {
+    case "10s":
+      return 10 * 1000;
+    case "1h":
+      return 60 * 60 * 1000;
+    case "1d":
+      return 24 * 60 * 60 * 1000;
+    default:
+      return 60 * 1000;
+  }
 }
 
 function alignToStep(timestampMs: number, stepMs: number): number {
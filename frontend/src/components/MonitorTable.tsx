@@ -51,7 +51,7 @@ type MonitorColumn = {
   render: (row: MonitorEndpoint) => ReactNode;
 };
 
-type EndpointHealth = "healthy" | "unhealthy" | "no_data";
+type EndpointHealth = "healthy" | "unhealthy" | "no_data" | "disabled";
 type LiveProbeContext = {
   probeRunning: boolean;
   probeScope: "all" | "groups" | "";
@@ -137,6 +137,9 @@ function endpointHealth(
   dataScope: MonitorDataScope,
   liveProbeContext: LiveProbeContext
 ): EndpointHealth {
+  if (dataScope !== "range" && (row.last_ping_status || "").trim().toLowerCase() === "disabled") {
+    return "disabled";
+  }
   if (row.total_sent_ping <= 0) {
     return "no_data";
   }
@@ -150,6 +153,9 @@ function endpointHealth(
   }
 
   const status = (row.last_ping_status || "").trim().toLowerCase();
+  if (status === "warming_up" || status === "maintenance") {
+    return "no_data";
+  }
   const liveFailure = row.consecutive_failed_count > 0 || (status.length > 0 && status !== "succeeded");
   return liveFailure ? "unhealthy" : "healthy";
 }
@@ -161,6 +167,9 @@ function rowHealthClassName(health: EndpointHealth): string {
   if (health === "unhealthy") {
     return "monitor-row-health-unhealthy";
   }
+  if (health === "disabled") {
+    return "monitor-row-health-disabled";
+  }
   return "monitor-row-health-no-data";
 }
 
@@ -190,7 +199,38 @@ const baseColumnDefinitions: BaseColumnDefinition[] = [
     render: (row) => formatDate(row.last_failed_on)
   },
   { key: "mac_address", menuLabel: "MAC Address", header: "MAC Address", render: (row) => row.mac_address || "-" },
-  { key: "reply_ip_address", menuLabel: "Reply IP", header: "Reply IP", render: (row) => row.reply_ip_address || "-" },
+  {
+    key: "reply_ip_address",
+    menuLabel: "Reply IP",
+    header: "Reply IP",
+    render: (row) =>
+      row.ip_mismatch ? (
+        <span className="badge badge-invalid" title="Reply came from a different address than the target">
+          {row.reply_ip_address || "-"}
+        </span>
+      ) : (
+        row.reply_ip_address || "-"
+      )
+  },
+  {
+    key: "last_ttl",
+    menuLabel: "Reply TTL",
+    header: "TTL",
+    render: (row) => (row.last_ttl === null ? "-" : String(row.last_ttl))
+  },
+  {
+    key: "duplicate_reply_count",
+    menuLabel: "Duplicate Replies",
+    header: "Dup. Replies",
+    render: (row) =>
+      row.duplicate_reply_count ? (
+        <span className="badge badge-invalid" title="Extra echo replies matched an answered probe">
+          {row.duplicate_reply_count}
+        </span>
+      ) : (
+        "0"
+      )
+  },
   {
     key: "success_count",
     menuLabel: "Success Count",
@@ -239,7 +279,7 @@ const baseColumnDefinitions: BaseColumnDefinition[] = [
     menuLabel: "Last Ping Status",
     header: "Last Status",
     sortable: "last_ping_status",
-    render: (row) => row.last_ping_status || "-"
+    render: (row) => (row.last_ping_status === "disabled" ? "Disabled" : row.last_ping_status || "-")
   },
   {
     key: "last_ping_latency",
@@ -302,7 +302,7 @@ export function GroupsPage() {
       setBatchGroupPreview(null);
       setGroupUpdateNotice({
         tone: "success",
-        message: `Moved ${preview.would_assign} endpoint(s) into "${nextGroup.name}".${
+        message: `Added ${preview.would_assign} endpoint(s) to "${nextGroup.name}".${
           preview.already_in_group > 0 ? ` ${preview.already_in_group} already matched that group.` : ""
         }${preview.used_existing_by_name ? " Existing group reused by name." : ""}`
       });
@@ -346,7 +346,7 @@ export function GroupsPage() {
       setBatchGroupRemovePreview(null);
       setGroupUpdateNotice({
         tone: "success",
-        message: `Removed ${preview.would_remove} endpoint(s) from "${nextGroup.name}" and moved them to "No Group".`
+        message: `Removed ${preview.would_remove} endpoint(s) from "${nextGroup.name}". Endpoints left without a group now show in "No Group".`
       });
     }
   });
@@ -414,16 +414,6 @@ export function GroupsPage() {
     return map;
   }, [endpointOptions]);
 
-  const endpointCurrentGroupByID = useMemo(() => {
-    const map = new Map<number, { groupID: number; groupName: string }>();
-    (groupsQuery.data || []).forEach((group) => {
-      (group.endpoint_ids || []).forEach((endpointID) => {
-        map.set(endpointID, { groupID: group.id, groupName: group.name });
-      });
-    });
-    return map;
-  }, [groupsQuery.data]);
-
   const manualIPs = useMemo(() => parseManualIPList(manualIPList), [manualIPList]);
   const currentGroupEndpointIDSet = useMemo(() => new Set(endpointIDs), [endpointIDs]);
   const currentGroupEndpointOptions = useMemo(
@@ -547,7 +537,7 @@ export function GroupsPage() {
       const removalSet = new Set(matchedRemovalIDs);
       removalMatchedCount = matchedRemovalIDs.length;
       resolvedEndpointIDs = currentEndpointIDs.filter((endpointID) => !removalSet.has(endpointID));
-      successMessage = `Removed ${removalMatchedCount} endpoint(s) from "${trimmedName || "this group"}" and moved them to "No Group".`;
+      successMessage = `Removed ${removalMatchedCount} endpoint(s) from "${trimmedName || "this group"}". Endpoints left without a group now show in "No Group".`;
 
       return {
         groupID,
@@ -607,51 +597,6 @@ export function GroupsPage() {
     }))
     .sort((a, b) => a.label.localeCompare(b.label, undefined, { sensitivity: "base" }));
 
-  function summarizeReassignment(endpointIDsToAssign: number[], targetGroupID: number | null) {
-    const impactByGroup = new Map<string, { groupName: string; count: number }>();
-    endpointIDsToAssign.forEach((endpointID) => {
-      const currentGroup = endpointCurrentGroupByID.get(endpointID);
-      if (!currentGroup) {
-        return;
-      }
-      if (targetGroupID !== null && currentGroup.groupID === targetGroupID) {
-        return;
-      }
-      if (isReservedGroupName(currentGroup.groupName)) {
-        return;
-      }
-      const key = `${currentGroup.groupID}:${currentGroup.groupName}`;
-      const existing = impactByGroup.get(key);
-      if (existing) {
-        existing.count += 1;
-        return;
-      }
-      impactByGroup.set(key, { groupName: currentGroup.groupName, count: 1 });
-    });
-
-    const impact = Array.from(impactByGroup.values()).sort((a, b) =>
-      a.groupName.localeCompare(b.groupName, undefined, { sensitivity: "base" })
-    );
-    return {
-      impact,
-      count: impact.reduce((total, item) => total + item.count, 0)
-    };
-  }
-
-  const manualReassignment = summarizeReassignment(saveRequest.endpointIDs, editingID);
-  const regexTargetGroupID =
-    editingID !== null ? editingID : batchGroupPreview?.used_existing_by_name ? (batchGroupPreview.group_id ?? null) : null;
-  const regexReassignment = summarizeReassignment(batchGroupPreview?.preview.endpoint_ids || [], regexTargetGroupID);
-  const emptyReassignment = { impact: [] as Array<{ groupName: string; count: number }>, count: 0 };
-  const activeReassignment =
-    membershipAction === "assign" ? (membershipMode === "regex" ? regexReassignment : manualReassignment) : emptyReassignment;
-  const targetGroupLabel =
-    membershipMode === "regex"
-      ? batchGroupPreview?.group_name || name.trim() || "the selected group"
-      : editingID
-        ? (name.trim() || "this group")
-        : "the new group";
-
   const reservedGroupName = isReservedGroupName(name);
   const regexTargetInvalid = !name.trim() || reservedGroupName || Boolean(editingGroup?.is_system);
   const regexRemovalInvalid = editingID === null || Boolean(editingGroup?.is_system);
@@ -816,7 +761,7 @@ export function GroupsPage() {
               </div>
               <span className="field-help">
                 {membershipAction === "remove"
-                  ? 'Remove mode only affects existing members of this group. Removed endpoints are reassigned to "No Group".'
+                  ? 'Remove mode only affects existing members of this group. Endpoints keep their other groups; those left without one fall back to "No Group".'
                   : 'Add mode only appends endpoints to the group. Existing members stay in place unless you switch to "Remove".'}
               </span>
             </div>
@@ -920,8 +865,8 @@ export function GroupsPage() {
                 />
                 <div className="field-help">
                   {membershipAction === "remove"
-                    ? 'Regex matching is evaluated only against the current group membership. Matched endpoints will be moved to "No Group".'
-                    : "Regex matching is evaluated against the full inventory, not the current Inventory page filters. Matching endpoints already in this group stay where they are; the rest will be moved into it."}
+                    ? 'Regex matching is evaluated only against the current group membership. Matched endpoints without another group fall back to "No Group".'
+                    : "Regex matching is evaluated against the full inventory, not the current Inventory page filters. Matching endpoints already in this group stay where they are; the rest are added to it and keep their other groups."}
                 </div>
                 {(membershipAction === "remove" ? previewRemovalRegexMutation.error : previewRegexMutation.error) ? (
                   <div className="error-banner" role="alert" aria-live="assertive">
@@ -992,18 +937,9 @@ export function GroupsPage() {
               </>
             )}
 
-            {activeReassignment.count > 0 && (
-              <div className="info-banner group-impact-warning" role="status" aria-live="polite">
-                Warning: {activeReassignment.count} selected endpoint{activeReassignment.count === 1 ? "" : "s"} currently
-                belong{activeReassignment.count === 1 ? "s" : ""} to other groups and will be moved to "{targetGroupLabel}
-                ". Each endpoint can belong to only one group. Affected groups:{" "}
-                {activeReassignment.impact.map((item) => `${item.groupName} (${item.count})`).join(", ")}.
-              </div>
-              )}
-
             {membershipAction === "remove" && removalInfoCount > 0 ? (
               <div className="info-banner" role="status" aria-live="polite">
-                Matched endpoints will be moved to "No Group". Will remove: {removalInfoCount}. Target after removal: No Group.
+                Matched endpoints will be removed from this group and keep their other groups. Will remove: {removalInfoCount}.
               </div>
             ) : null}
 
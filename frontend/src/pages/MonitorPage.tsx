@@ -360,6 +360,11 @@ export function MonitorPage({ dashboardMode, onDashboardModeChange, probeStatus,
       return;
     }
     const event = message as { type?: string; endpoint_id?: number };
+    if (event.type === "stats_reset") {
+      lastRealtimeRefreshRef.current = Date.now();
+      queryClient.invalidateQueries({ queryKey: ["monitor-endpoints-page"] });
+      return;
+    }
     if (event.type !== "probe_update") {
       return;
     }
@@ -15,6 +15,7 @@ export type MonitorEndpoint = {
   custom_field_9_value: string;
   custom_field_10_value: string;
   reply_ip_address: string | null;
+  last_ttl: number | null;
   last_success_on: string | null;
   success_count: number;
   failed_count: number;
@@ -24,8 +25,17 @@ export type MonitorEndpoint = {
   failed_pct: number;
   total_sent_ping: number;
   last_ping_status: string;
+  last_error_detail?: string;
+  is_down?: boolean;
+  duplicate_reply_count?: number;
+  ip_mismatch?: boolean;
+  fallback_probe?: string;
+  ip_mismatch_count?: number | null;
   last_ping_latency: number | null;
   average_latency: number | null;
+  p50_latency?: number | null;
+  p95_latency?: number | null;
+  p99_latency?: number | null;
   vlan: string;
   zone: string;
   switch: string;
@@ -49,7 +59,32 @@ export type MonitorSortField =
   | "failed_pct"
   | "last_ping_status"
   | "last_ping_latency"
-  | "average_latency";
+  | "average_latency"
+  | "hostname"
+  | "ip"
+  | "vlan"
+  | "switch"
+  | "port"
+  | "mac";
+
+export type GroupWorstOffender = {
+  endpoint_id: number;
+  hostname: string;
+  ip_address: string;
+  failed_pct: number;
+};
+
+export type MonitorGroupSummary = {
+  group_id: number;
+  group_name: string;
+  is_system: boolean;
+  total_endpoints: number;
+  up_endpoints: number;
+  down_endpoints: number;
+  loss_pct: number;
+  avg_latency_ms: number | null;
+  worst_offender: GroupWorstOffender | null;
+};
 
 export type MonitorSortCriterion = {
   field: MonitorSortField;
@@ -67,7 +102,8 @@ export type MonitorEndpointPageResponse = {
   sort_by?: string;
   sort_dir?: "asc" | "desc";
   stats_scope?: MonitorDataScope;
-  range_rollup?: "1m" | "1h";
+  range_rollup?: "10s" | "1m" | "1h" | "1d";
+  next_cursor?: string;
 };
 
 export type InventoryEndpoint = {
@@ -97,6 +133,7 @@ export type InventoryEndpoint = {
   description: string;
   group: string[];
   active: boolean;
+  monitoring_enabled: boolean;
   updated_at: string;
 };
 
@@ -128,6 +165,19 @@ export type InventoryEndpointCreateRequest = {
   [key: `custom_field_${number}_value`]: string | undefined;
 };
 
+export type InventoryEndpointBulkCreateResult = {
+  index: number;
+  endpoint_id?: number;
+  error?: string;
+};
+
+export type InventoryEndpointBulkCreateResponse = {
+  items: InventoryEndpoint[];
+  results: InventoryEndpointBulkCreateResult[];
+  created_count: number;
+  failed_count: number;
+};
+
 export type Group = {
   id: number;
   name: string;
@@ -137,6 +187,7 @@ export type Group = {
   updated_at: string;
   endpoint_ids?: number[];
   active_endpoint_count?: number;
+  probe_interval_sec?: number | null;
 };
 
 export type InventoryEndpointActivityUpdateResponse = {
@@ -150,11 +201,16 @@ export type CustomFieldConfig = {
   name: string;
 };
 
+export type PayloadPattern = "filler" | "zeros" | "random" | "incrementing" | "timestamp";
+
 export type Settings = {
   ping_interval_sec: number;
   icmp_payload_bytes: number;
   icmp_timeout_ms: number;
   auto_refresh_sec: number;
+  down_threshold?: number;
+  probe_retries?: number;
+  payload_pattern?: PayloadPattern;
   custom_fields: CustomFieldConfig[];
 };
 
@@ -199,10 +255,67 @@ export type DashboardUnreachableSummary = {
   total_switch_count: number;
 };
 
+export type ProbeSchedule = {
+  scope: "all" | "groups";
+  group_ids: number[];
+  started_at: string;
+  total_rounds?: number;
+  overrun_rounds?: number;
+  last_round_duration_ms?: number;
+  last_round_targets?: number;
+  avg_round_duration_ms?: number;
+};
+
 export type ProbeStatus = {
   running: boolean;
   scope: "all" | "groups" | "";
   group_ids: number[];
+  schedules?: ProbeSchedule[];
+  total_rounds?: number;
+  overrun_rounds?: number;
+  last_round_duration_ms?: number;
+  last_round_targets?: number;
+  avg_round_duration_ms?: number;
+  effective_timeout_ms?: number;
+};
+
+export type RawPingSample = {
+  ts: string;
+  success: boolean;
+  latency_ms: number | null;
+  reply_ip: string | null;
+  ttl: number | null;
+  error_code: string;
+  maintenance: boolean;
+  fallback_probe: string;
+  out_of_order_replies: number;
+  drift_ms: number | null;
+};
+
+export type RawPingHistoryResponse = {
+  endpoint_id: number;
+  start: string;
+  end: string;
+  limit: number;
+  truncated: boolean;
+  items: RawPingSample[];
+};
+
+export type TracerouteHop = {
+  ttl: number;
+  ip: string | null;
+  rtt_ms: number | null;
+  error_code?: string;
+};
+
+export type TracerouteResult = {
+  endpoint_id: number;
+  target_ip: string;
+  reached: boolean;
+  truncated: boolean;
+  started_at: string;
+  duration_ms: number;
+  hops: TracerouteHop[];
 };
 
 export type ImportCandidate = {
@@ -267,6 +380,7 @@ export type ImportApplyResponse = {
   updated: number;
   errors: string[];
   group_assignment?: ImportGroupAssignmentResult;
+  dry_run?: boolean;
 };
 
 export type DeleteInventoryByGroupResponse = {
@@ -369,7 +483,7 @@ export type DeleteAllInventoryResponse = {
 };
 
 export type InventoryDeleteJobMode = "endpoint" | "by_group" | "all" | "match";
-export type InventoryDeleteJobState = "running" | "completed" | "failed";
+export type InventoryDeleteJobState = "running" | "completed" | "failed" | "canceled";
 
 export type InventoryDeleteJobStatus = {
   active: boolean;
@@ -398,12 +512,13 @@ export type TimeSeriesPoint = {
   loss_rate: number;
   avg_latency_ms: number | null;
   max_latency_ms: number | null;
+  jitter_ms?: number | null;
   sent_count: number;
   fail_count: number;
 };
 
 export type TimeSeriesResponse = {
-  rollup: "1m" | "1h";
+  rollup: "10s" | "1m" | "1h" | "1d";
   series: TimeSeriesPoint[];
 };
 
@@ -413,3 +528,15 @@ export type FilterOptions = {
   port: string[];
   group: string[];
 };
+
+export type FilterValueCount = {
+  value: string;
+  count: number;
+};
+
+export type FilterCounts = {
+  vlan: FilterValueCount[];
+  switch: FilterValueCount[];
+  port: FilterValueCount[];
+  group: FilterValueCount[];
+};